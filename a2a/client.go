@@ -76,9 +76,15 @@ type Message struct {
 	Parts     []Part `json:"parts"`
 }
 
+// Part carries exactly one of Text, Raw (inline file bytes), URL (file
+// reference) or Data (structured JSON).
 type Part struct {
-	Text      string `json:"text"`
-	MediaType string `json:"mediaType,omitempty"`
+	Text      string          `json:"text,omitempty"`
+	Raw       []byte          `json:"raw,omitempty"`
+	URL       string          `json:"url,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Filename  string          `json:"filename,omitempty"`
+	MediaType string          `json:"mediaType,omitempty"`
 }
 
 type SendMessageParams struct {
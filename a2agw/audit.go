@@ -33,6 +33,12 @@ func (g *Gateway) audit(event AuditEvent) {
 	g.auditSink.RecordA2A(event)
 }
 
+// recordTaskPersistError reports a task state change the TaskStore did not
+// accept. The in-memory task stays authoritative until a later save lands.
+func (g *Gateway) recordTaskPersistError(record *TaskRecord, err error) {
+	g.audit(AuditEvent{Stage: "task_persist", RouteID: record.RouteID, TaskID: record.ID, CallerScopeClass: callerScopeClass(record.CallerScope), Outcome: "error", Code: "persist_failed"})
+}
+
 func auditHash(value string) string {
 	value = strings.TrimSpace(value)
 	if value == "" {
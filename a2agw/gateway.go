@@ -28,6 +28,15 @@ type Config struct {
 	Bridge              Bridge
 	Audit               AuditSink
 	AcceptNewTasksUntil time.Time
+	// TaskStore, when set, persists task records so a restarted gateway
+	// keeps serving tasks it accepted earlier. Rebuilds through
+	// NewPreservingRuntime keep the previous gateway's tasks instead.
+	TaskStore TaskStore
+	// AllowLoopbackPushURLs lets push notification webhooks target the
+	// gateway's own host, over plain http too. It is off by default so
+	// callers cannot reach services bound only to loopback; enable it for
+	// local development and tests.
+	AllowLoopbackPushURLs bool
 }
 
 type Route struct {
@@ -93,17 +102,19 @@ type Gateway struct {
 	bridge        Bridge
 	tasks         *taskStore
 	rateLimiter   *rateLimiter
+	push          *pushNotifier
 	acceptUntil   atomic.Int64
 	taskExecution bool
 	auditSink     AuditSink
 }
 
 type Health struct {
-	Status        string       `json:"status"`
-	Host          string       `json:"host"`
-	RootCardMode  RootCardMode `json:"root_card_mode"`
-	Routes        int          `json:"routes"`
-	TaskExecution bool         `json:"task_execution"`
+	Status        string        `json:"status"`
+	Host          string        `json:"host"`
+	RootCardMode  RootCardMode  `json:"root_card_mode"`
+	Routes        int           `json:"routes"`
+	TaskExecution bool          `json:"task_execution"`
+	ReplyPickup   *ReplyMetrics `json:"reply_pickup,omitempty"`
 }
 
 type Diagnostics struct {
@@ -248,17 +259,29 @@ func newGateway(config Config, previous *Gateway) (*Gateway, error) {
 		bridge = notReadyBridge{}
 		taskExecution = false
 	}
-	tasks := newTaskStore(time.Now)
+	var tasks *taskStore
 	rateLimiter := newRateLimiter(time.Now)
+	push := newPushNotifier()
 	if previous != nil {
-		if previous.tasks != nil {
-			tasks = previous.tasks
-		}
+		tasks = previous.tasks
 		if previous.rateLimiter != nil {
 			rateLimiter = previous.rateLimiter
 		}
+		if previous.push != nil {
+			push = previous.push
+		}
 	}
-	gateway := &Gateway{config: config, rootCard: rootCard, routeCards: routeCards, routeConfigs: routeConfigs, bridge: bridge, tasks: tasks, rateLimiter: rateLimiter, taskExecution: taskExecution, auditSink: config.Audit}
+	if tasks == nil {
+		loaded, err := loadTaskStore(time.Now, config.TaskStore)
+		if err != nil {
+			return nil, err
+		}
+		tasks = loaded
+	}
+	push.configure(config.Audit, config.AllowLoopbackPushURLs)
+	tasks.setPusher(push)
+	gateway := &Gateway{config: config, rootCard: rootCard, routeCards: routeCards, routeConfigs: routeConfigs, bridge: bridge, tasks: tasks, rateLimiter: rateLimiter, push: push, taskExecution: taskExecution, auditSink: config.Audit}
+	tasks.setPersistErrorHandler(gateway.recordTaskPersistError)
 	gateway.SetAcceptNewTasksUntil(config.AcceptNewTasksUntil)
 	return gateway, nil
 }
@@ -340,7 +363,12 @@ func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (g *Gateway) Health() Health {
-	return Health{Status: "ok", Host: g.config.Host, RootCardMode: g.config.RootCardMode, Routes: len(g.routeCards), TaskExecution: g.taskExecution}
+	health := Health{Status: "ok", Host: g.config.Host, RootCardMode: g.config.RootCardMode, Routes: len(g.routeCards), TaskExecution: g.taskExecution}
+	if source, ok := g.bridge.(interface{ ReplyMetrics() ReplyMetrics }); ok {
+		metrics := source.ReplyMetrics()
+		health.ReplyPickup = &metrics
+	}
+	return health
 }
 
 func (g *Gateway) Diagnostics() Diagnostics {
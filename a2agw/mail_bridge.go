@@ -40,6 +40,13 @@ type MailBridgeConfig struct {
 	AllowUnverifiedLocalReply bool
 	AllowQuestionReply        bool
 	Audit                     AuditSink
+	// Store, when set, persists thread state (conversation IDs, seen
+	// message IDs) so reply polling resumes after a restart.
+	Store TaskStore
+	// EventStream, when set, subscribes to the gateway identity's event
+	// stream so replies are picked up as they arrive. Polling only runs
+	// while the stream is down.
+	EventStream EventStreamOpener
 }
 
 type MailBridge struct {
@@ -52,24 +59,35 @@ type MailBridge struct {
 	allowUnverifiedLocalReply bool
 	allowQuestionReply        bool
 	audit                     AuditSink
-
-	mu      sync.Mutex
-	threads map[string]*mailBridgeThread
-	applier ReplyApplier
-}
-
-type mailBridgeThread struct {
-	TaskID         string
-	ContextID      string
-	RequestID      string
-	RouteID        string
-	TargetAddress  string
-	CallerScope    string
-	ConversationID string
-	MessageID      string
-	SeenMessages   map[string]bool
-	CreatedAt      time.Time
-	ReplyDeadline  time.Time
+	store                     TaskStore
+	openEvents                EventStreamOpener
+
+	mu            sync.Mutex
+	threads       map[string]*BridgeThread
+	polling       map[string]bool
+	fetchLocks    map[string]*sync.Mutex
+	applier       ReplyApplier
+	stopEvents    context.CancelFunc
+	metrics       ReplyMetrics
+	streamWasLive bool
+}
+
+// BridgeThread ties a gateway task to the mail conversation carrying it.
+// Settled marks threads whose reply polling already finished, so a restart
+// does not poll them again.
+type BridgeThread struct {
+	TaskID         string          `json:"task_id"`
+	ContextID      string          `json:"context_id,omitempty"`
+	RequestID      string          `json:"request_id,omitempty"`
+	RouteID        string          `json:"route_id"`
+	TargetAddress  string          `json:"target_address"`
+	CallerScope    string          `json:"caller_scope,omitempty"`
+	ConversationID string          `json:"conversation_id"`
+	MessageID      string          `json:"message_id,omitempty"`
+	SeenMessages   map[string]bool `json:"seen_messages,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	ReplyDeadline  time.Time       `json:"reply_deadline"`
+	Settled        bool            `json:"settled,omitempty"`
 }
 
 type a2aTaskEnvelope struct {
@@ -84,14 +102,23 @@ type a2aTaskEnvelope struct {
 }
 
 type a2aReplyEnvelope struct {
-	TaskID    string `json:"task_id"`
-	ContextID string `json:"context_id,omitempty"`
-	State     string `json:"state"`
-	Artifacts []struct {
-		Type string `json:"type"`
-		Text string `json:"text"`
-	} `json:"artifacts,omitempty"`
-	Text string `json:"text,omitempty"`
+	TaskID    string             `json:"task_id"`
+	ContextID string             `json:"context_id,omitempty"`
+	State     string             `json:"state"`
+	Artifacts []a2aReplyArtifact `json:"artifacts,omitempty"`
+	Text      string             `json:"text,omitempty"`
+}
+
+// a2aReplyArtifact is one artifact in an agent's a2a-reply block: a text
+// answer, structured data, or a file given by https URL or base64 bytes.
+type a2aReplyArtifact struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	MediaType string          `json:"media_type,omitempty"`
+	Filename  string          `json:"filename,omitempty"`
+	URL       string          `json:"url,omitempty"`
+	Bytes     []byte          `json:"bytes,omitempty"`
 }
 
 func NewMailBridge(config MailBridgeConfig) (*MailBridge, error) {
@@ -102,7 +129,7 @@ func NewMailBridge(config MailBridgeConfig) (*MailBridge, error) {
 	if pollInterval <= 0 {
 		pollInterval = 500 * time.Millisecond
 	}
-	return &MailBridge{
+	bridge := &MailBridge{
 		client:                    config.Client,
 		gatewayIdentity:           strings.TrimSpace(config.GatewayIdentity),
 		useIdentityAuth:           config.UseIdentityAuth,
@@ -112,14 +139,70 @@ func NewMailBridge(config MailBridgeConfig) (*MailBridge, error) {
 		allowUnverifiedLocalReply: config.AllowUnverifiedLocalReply,
 		allowQuestionReply:        config.AllowQuestionReply,
 		audit:                     config.Audit,
-		threads:                   map[string]*mailBridgeThread{},
-	}, nil
+		store:                     config.Store,
+		openEvents:                config.EventStream,
+		threads:                   map[string]*BridgeThread{},
+		polling:                   map[string]bool{},
+		fetchLocks:                map[string]*sync.Mutex{},
+		metrics:                   ReplyMetrics{EventStream: EventStreamDisabled},
+	}
+	if err := bridge.loadThreads(); err != nil {
+		return nil, err
+	}
+	return bridge, nil
+}
+
+// loadThreads restores persisted threads whose reply window is still open
+// and prunes the rest from the store.
+func (b *MailBridge) loadThreads() error {
+	if b.store == nil {
+		return nil
+	}
+	threads, err := b.store.LoadThreads()
+	if err != nil {
+		return fmt.Errorf("load mail bridge threads: %w", err)
+	}
+	now := time.Now()
+	for _, thread := range threads {
+		if thread.TaskID == "" {
+			continue
+		}
+		if now.After(thread.ReplyDeadline) {
+			if err := b.store.DeleteThread(thread.TaskID); err != nil {
+				return fmt.Errorf("prune mail bridge thread %s: %w", thread.TaskID, err)
+			}
+			continue
+		}
+		thread := thread
+		if thread.SeenMessages == nil {
+			thread.SeenMessages = map[string]bool{}
+		}
+		b.threads[thread.TaskID] = &thread
+	}
+	return nil
 }
 
+// SetReplyApplier installs the gateway that receives parsed replies, starts
+// the event stream when one is configured, and starts reply polling for
+// restored threads that are not settled yet.
 func (b *MailBridge) SetReplyApplier(applier ReplyApplier) {
 	b.mu.Lock()
-	defer b.mu.Unlock()
 	b.applier = applier
+	var resume []string
+	if applier != nil {
+		b.startEventStreamLocked()
+		for taskID, thread := range b.threads {
+			if thread.Settled || thread.ConversationID == "" || b.polling[taskID] {
+				continue
+			}
+			b.polling[taskID] = true
+			resume = append(resume, taskID)
+		}
+	}
+	b.mu.Unlock()
+	for _, taskID := range resume {
+		go b.pollTaskReplies(taskID)
+	}
 }
 
 func (b *MailBridge) SetGatewayIdentity(identity string) {
@@ -146,7 +229,7 @@ func (b *MailBridge) SendTask(ctx context.Context, task BridgeTask) error {
 		CallerScope:     bridgeVisibleCallerScope(task.CallerScope),
 		State:           TaskStateWorking,
 		RequestID:       task.RequestID,
-	}, task.Text)
+	}, task.Text, task.Attachments...)
 	if err != nil {
 		b.recordAudit(AuditEvent{Stage: "bridge_send", RequestID: task.RequestID, RouteID: task.RouteID, TaskID: task.TaskID, CallerScopeClass: callerScopeClass(task.CallerScope), GatewayIdentityHash: auditHash(gatewayIdentity), TargetAddressHash: auditHash(task.Address), Outcome: "error", Code: "format_failed", LatencyMS: latencyMS(start), VerificationTier: "unsigned"})
 		return err
@@ -163,7 +246,7 @@ func (b *MailBridge) SendTask(ctx context.Context, task BridgeTask) error {
 		b.recordAudit(AuditEvent{Stage: "bridge_send", RequestID: task.RequestID, RouteID: task.RouteID, TaskID: task.TaskID, CallerScopeClass: callerScopeClass(task.CallerScope), GatewayIdentityHash: auditHash(gatewayIdentity), TargetAddressHash: auditHash(task.Address), Outcome: "error", Code: "send_failed", LatencyMS: latencyMS(start), VerificationTier: "unsigned"})
 		return err
 	}
-	thread := &mailBridgeThread{
+	thread := &BridgeThread{
 		TaskID:         task.TaskID,
 		ContextID:      task.ContextID,
 		RequestID:      task.RequestID,
@@ -181,8 +264,16 @@ func (b *MailBridge) SendTask(ctx context.Context, task BridgeTask) error {
 		return errors.New("mail bridge send response missing conversation_id")
 	}
 	b.mu.Lock()
+	if err := b.saveThreadLocked(thread); err != nil {
+		b.mu.Unlock()
+		b.recordAudit(AuditEvent{Stage: "bridge_send", RequestID: task.RequestID, RouteID: task.RouteID, TaskID: task.TaskID, CallerScopeClass: callerScopeClass(task.CallerScope), GatewayIdentityHash: auditHash(gatewayIdentity), TargetAddressHash: auditHash(task.Address), Outcome: "error", Code: "thread_persist_failed", LatencyMS: latencyMS(start), VerificationTier: "unsigned"})
+		return fmt.Errorf("persist mail bridge thread: %w", err)
+	}
 	b.threads[task.TaskID] = thread
 	shouldPoll := b.applier != nil
+	if shouldPoll {
+		b.polling[task.TaskID] = true
+	}
 	b.mu.Unlock()
 	b.recordAudit(AuditEvent{Stage: "bridge_send", RequestID: task.RequestID, RouteID: task.RouteID, TaskID: task.TaskID, CallerScopeClass: callerScopeClass(task.CallerScope), GatewayIdentityHash: auditHash(gatewayIdentity), TargetAddressHash: auditHash(task.Address), Outcome: "ok", LatencyMS: latencyMS(start), VerificationTier: "unsigned"})
 	if shouldPoll {
@@ -301,7 +392,7 @@ func bridgeVisibleCallerScope(scope string) string {
 	return scope
 }
 
-func (b *MailBridge) thread(taskID string) *mailBridgeThread {
+func (b *MailBridge) thread(taskID string) *BridgeThread {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	thread := b.threads[taskID]
@@ -316,7 +407,7 @@ func (b *MailBridge) thread(taskID string) *mailBridgeThread {
 	return &copy
 }
 
-func (b *MailBridge) threadByConversation(conversationID string) *mailBridgeThread {
+func (b *MailBridge) threadByConversation(conversationID string) *BridgeThread {
 	conversationID = strings.TrimSpace(conversationID)
 	if conversationID == "" {
 		return nil
@@ -345,25 +436,86 @@ func (b *MailBridge) markSeenLocked(taskID, messageID string) {
 		thread.SeenMessages = map[string]bool{}
 	}
 	thread.SeenMessages[messageID] = true
+	// Seen IDs only deduplicate replies the gateway already refuses to
+	// apply twice, so a failed write here is safe to drop.
+	_ = b.saveThreadLocked(thread)
+}
+
+func (b *MailBridge) saveThreadLocked(thread *BridgeThread) error {
+	if b.store == nil {
+		return nil
+	}
+	copy := *thread
+	copy.SeenMessages = make(map[string]bool, len(thread.SeenMessages))
+	for k, v := range thread.SeenMessages {
+		copy.SeenMessages[k] = v
+	}
+	return b.store.SaveThread(copy)
+}
+
+// finishPolling clears the polling mark for taskID. A settled thread keeps
+// its persisted state for conversation lookups; a thread whose reply window
+// closed is dropped from the store.
+func (b *MailBridge) finishPolling(taskID string, settled bool) {
+	b.mu.Lock()
+	delete(b.polling, taskID)
+	delete(b.fetchLocks, taskID)
+	b.mu.Unlock()
+	if settled {
+		b.settleThread(taskID)
+		return
+	}
+	if b.store != nil {
+		_ = b.store.DeleteThread(taskID)
+	}
+}
+
+func (b *MailBridge) settleThread(taskID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	thread := b.threads[taskID]
+	if thread == nil || thread.Settled {
+		return
+	}
+	thread.Settled = true
+	_ = b.saveThreadLocked(thread)
 }
 
+func (b *MailBridge) threadSettled(taskID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	thread := b.threads[taskID]
+	return thread != nil && thread.Settled
+}
+
+// pollTaskReplies watches one thread until it settles or its reply window
+// closes. It fetches the conversation on every tick while the event stream
+// is down, and once more each time the stream comes back to catch up on
+// replies that arrived in the gap.
 func (b *MailBridge) pollTaskReplies(taskID string) {
 	thread := b.thread(taskID)
 	if thread == nil || thread.ConversationID == "" {
+		b.finishPolling(taskID, false)
 		return
 	}
+	caughtUp := false
 	for {
+		if b.threadSettled(taskID) {
+			b.finishPolling(taskID, true)
+			return
+		}
 		if time.Now().After(thread.ReplyDeadline) {
+			b.finishPolling(taskID, false)
 			return
 		}
-		resp, err := b.mailConversationForThread(context.Background(), thread, 20)
-		if err == nil && resp != nil {
-			for _, msg := range resp.Messages {
-				if _, ok, _ := b.IngestInboxMessage(context.Background(), msg); ok {
-					return
-				}
+		live := b.eventStreamLive()
+		if !live || !caughtUp {
+			if b.fetchThreadReplies(context.Background(), thread, replySourcePoll) {
+				b.finishPolling(taskID, true)
+				return
 			}
 		}
+		caughtUp = live
 		time.Sleep(replyPollInterval(b.pollInterval, time.Since(thread.CreatedAt)))
 	}
 }
@@ -394,7 +546,7 @@ func replyPollInterval(base time.Duration, elapsed time.Duration) time.Duration
 	return backoff
 }
 
-func (b *MailBridge) mailConversationForThread(ctx context.Context, thread *mailBridgeThread, limit int) (*awid.InboxResponse, error) {
+func (b *MailBridge) mailConversationForThread(ctx context.Context, thread *BridgeThread, limit int) (*awid.InboxResponse, error) {
 	if scoped, ok := b.client.(RouteScopedMailTransport); ok {
 		return scoped.MailConversationForRoute(ctx, thread.RouteID, thread.TargetAddress, thread.ConversationID, limit)
 	}
@@ -420,7 +572,10 @@ func (b *MailBridge) recordAudit(event AuditEvent) {
 	b.audit.RecordA2A(event)
 }
 
-func FormatA2ATaskMessage(env a2aTaskEnvelope, text string) (string, error) {
+// FormatA2ATaskMessage renders the bridged mail body: the task envelope,
+// reply instructions, then the untrusted customer text and any file or data
+// attachments.
+func FormatA2ATaskMessage(env a2aTaskEnvelope, text string, attachments ...A2APart) (string, error) {
 	body, err := json.MarshalIndent(env, "", "  ")
 	if err != nil {
 		return "", err
@@ -443,7 +598,15 @@ func FormatA2ATaskMessage(env a2aTaskEnvelope, text string) (string, error) {
 		"```a2a-reply\n" + string(replyBody) + "\n```\n\n" +
 		"Allowed state values: completed, input_required, failed, rejected. " +
 		"Replies without a valid a2a-reply block do not change the task.\n"
-	return "```a2a-task\n" + string(body) + "\n```\n\n" + instructions + "\nCustomer message (untrusted):\n\n" + text, nil
+	if len(attachments) > 0 {
+		instructions += "Artifacts may also be {\"type\":\"data\",\"data\":{...}} or {\"type\":\"file\",\"filename\":\"...\",\"media_type\":\"...\",\"url\":\"https://...\"} " +
+			"(or base64 \"bytes\" instead of \"url\").\n"
+	}
+	rendered, err := formatA2AAttachments(attachments)
+	if err != nil {
+		return "", err
+	}
+	return "```a2a-task\n" + string(body) + "\n```\n\n" + instructions + "\nCustomer message (untrusted):\n\n" + text + rendered, nil
 }
 
 func FormatA2ACancelMessage(taskID, contextID, requestID string) string {
@@ -473,16 +636,23 @@ func ParseA2AReply(body string, allowQuestion bool) (BridgeReply, bool, error) {
 	}
 	reply := BridgeReply{TaskID: strings.TrimSpace(env.TaskID), ContextID: strings.TrimSpace(env.ContextID), State: strings.TrimSpace(env.State), Text: strings.TrimSpace(env.Text)}
 	for _, artifact := range env.Artifacts {
-		if strings.TrimSpace(artifact.Type) != "" && strings.TrimSpace(artifact.Type) != "text" {
-			continue
+		part, ok, err := replyArtifactPart(artifact)
+		if err != nil {
+			return BridgeReply{}, true, err
 		}
-		text := strings.TrimSpace(artifact.Text)
-		if text == "" {
+		if !ok {
 			continue
 		}
-		reply.Artifacts = append(reply.Artifacts, Artifact{Name: "answer", Parts: []A2APart{{Text: text, MediaType: "text/plain"}}})
+		name := "answer"
+		if part.kind() != partKindText {
+			name = part.kind()
+			if part.Filename != "" {
+				name = part.Filename
+			}
+		}
+		reply.Artifacts = append(reply.Artifacts, Artifact{Name: name, Parts: []A2APart{part}})
 		if reply.Text == "" {
-			reply.Text = text
+			reply.Text = part.Text
 		}
 	}
 	if strings.TrimSpace(reply.TaskID) == "" {
@@ -0,0 +1,245 @@
+package a2agw
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+const (
+	// eventStreamTTL is the deadline requested for each /v1/events/stream
+	// connection; the bridge reconnects when it passes.
+	eventStreamTTL        = 10 * time.Minute
+	eventStreamMinBackoff = 250 * time.Millisecond
+	eventStreamMaxBackoff = 5 * time.Second
+
+	EventStreamDisabled   = "disabled"
+	EventStreamConnecting = "connecting"
+	EventStreamLive       = "live"
+	EventStreamDown       = "down"
+
+	replySourceStream = "stream"
+	replySourcePoll   = "poll"
+)
+
+// EventStreamOpener opens one /v1/events/stream connection for the gateway
+// identity. *awid.Client.EventStream satisfies it through a small adapter.
+type EventStreamOpener func(ctx context.Context, deadline time.Time) (awid.EventSource, error)
+
+// ReplyMetrics reports how the bridge picks up agent replies: the event
+// stream state, and per pickup path the number of replies and the time from
+// task send to reply ingestion.
+type ReplyMetrics struct {
+	EventStream      string       `json:"event_stream"`
+	StreamReconnects int64        `json:"stream_reconnects"`
+	Stream           ReplyLatency `json:"stream"`
+	Poll             ReplyLatency `json:"poll"`
+}
+
+type ReplyLatency struct {
+	Replies int64 `json:"replies"`
+	TotalMS int64 `json:"total_ms"`
+	MaxMS   int64 `json:"max_ms"`
+}
+
+func (l *ReplyLatency) observe(latency time.Duration) {
+	ms := latency.Milliseconds()
+	l.Replies++
+	l.TotalMS += ms
+	if ms > l.MaxMS {
+		l.MaxMS = ms
+	}
+}
+
+// ReplyMetrics returns a snapshot of the reply pickup metrics.
+func (b *MailBridge) ReplyMetrics() ReplyMetrics {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.metrics
+}
+
+func (b *MailBridge) eventStreamLive() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.metrics.EventStream == EventStreamLive
+}
+
+func (b *MailBridge) setEventStreamState(state string) {
+	b.mu.Lock()
+	previous := b.metrics.EventStream
+	b.metrics.EventStream = state
+	if state == EventStreamLive && b.streamWasLive {
+		b.metrics.StreamReconnects++
+	}
+	if state == EventStreamLive {
+		b.streamWasLive = true
+	}
+	b.mu.Unlock()
+	if previous == state {
+		return
+	}
+	switch state {
+	case EventStreamLive:
+		b.recordAudit(AuditEvent{Stage: "event_stream", GatewayIdentityHash: auditHash(b.gatewayIdentitySnapshot()), Outcome: "ok", VerificationTier: "unsigned"})
+	case EventStreamDown:
+		if previous == EventStreamLive {
+			b.recordAudit(AuditEvent{Stage: "event_stream", GatewayIdentityHash: auditHash(b.gatewayIdentitySnapshot()), Outcome: "error", Code: "stream_lost", VerificationTier: "unsigned"})
+		}
+	}
+}
+
+func (b *MailBridge) recordReplyLatency(source string, latency time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if source == replySourceStream {
+		b.metrics.Stream.observe(latency)
+		return
+	}
+	b.metrics.Poll.observe(latency)
+}
+
+// startEventStream runs the event stream once per bridge. Callers hold b.mu.
+func (b *MailBridge) startEventStreamLocked() {
+	if b.openEvents == nil || b.stopEvents != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	b.stopEvents = cancel
+	b.metrics.EventStream = EventStreamConnecting
+	go b.runEventStream(ctx)
+}
+
+// Close stops the event stream. Reply polling goroutines end on their own
+// at the reply deadline.
+func (b *MailBridge) Close() {
+	b.mu.Lock()
+	stop := b.stopEvents
+	b.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+}
+
+// runEventStream keeps a /v1/events/stream connection open and routes
+// actionable mail to bridge threads. While it is live, reply polling stands
+// down; any failure marks it down so polling resumes until it reconnects.
+// A 4xx on connect disables the stream for the life of the bridge.
+func (b *MailBridge) runEventStream(ctx context.Context) {
+	delay := eventStreamMinBackoff
+	for ctx.Err() == nil {
+		deadline := time.Now().Add(eventStreamTTL)
+		streamCtx, cancel := context.WithDeadline(ctx, deadline)
+		source, err := b.openEvents(streamCtx, deadline)
+		if err != nil {
+			cancel()
+			if code, ok := awid.HTTPStatusCode(err); ok && code >= 400 && code < 500 {
+				b.setEventStreamState(EventStreamDisabled)
+				b.recordAudit(AuditEvent{Stage: "event_stream", GatewayIdentityHash: auditHash(b.gatewayIdentitySnapshot()), Outcome: "error", Code: "http_" + strconv.Itoa(code), VerificationTier: "unsigned"})
+				return
+			}
+			b.setEventStreamState(EventStreamDown)
+			if !sleepContext(ctx, delay) {
+				return
+			}
+			delay = min(delay*2, eventStreamMaxBackoff)
+			continue
+		}
+		err = b.consumeEvents(streamCtx, source, func() { delay = eventStreamMinBackoff })
+		expired := streamCtx.Err() != nil
+		_ = source.Close()
+		cancel()
+		if ctx.Err() != nil {
+			break
+		}
+		b.setEventStreamState(EventStreamDown)
+		if err != nil && !expired {
+			if !sleepContext(ctx, delay) {
+				return
+			}
+			delay = min(delay*2, eventStreamMaxBackoff)
+		}
+	}
+	b.setEventStreamState(EventStreamDown)
+}
+
+// consumeEvents reads until the stream fails. The stream only counts as
+// live once it yields an event, since a proxy can accept the request and
+// close the body at once.
+func (b *MailBridge) consumeEvents(ctx context.Context, source awid.EventSource, confirmed func()) error {
+	live := false
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for ctx.Err() == nil {
+		evt, err := source.Next(ctx)
+		if err != nil {
+			return err
+		}
+		if !live {
+			live = true
+			confirmed()
+			b.setEventStreamState(EventStreamLive)
+		}
+		if evt.Type != awid.AgentEventActionableMail {
+			continue
+		}
+		thread := b.threadByConversation(evt.ConversationID)
+		if thread == nil || thread.Settled || (evt.MessageID != "" && thread.SeenMessages[evt.MessageID]) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.fetchThreadReplies(ctx, thread, replySourceStream)
+		}()
+	}
+	return ctx.Err()
+}
+
+// fetchThreadReplies reads the thread's conversation and ingests the first
+// reply that applies, settling the thread. Fetches for one thread are
+// serialized so the stream and the poller cannot apply one reply twice.
+func (b *MailBridge) fetchThreadReplies(ctx context.Context, thread *BridgeThread, source string) bool {
+	lock := b.fetchLock(thread.TaskID)
+	lock.Lock()
+	defer lock.Unlock()
+	if b.threadSettled(thread.TaskID) {
+		return true
+	}
+	resp, err := b.mailConversationForThread(ctx, thread, 20)
+	if err != nil || resp == nil {
+		return false
+	}
+	for _, msg := range resp.Messages {
+		if _, ok, _ := b.IngestInboxMessage(ctx, msg); ok {
+			b.recordReplyLatency(source, time.Since(thread.CreatedAt))
+			b.settleThread(thread.TaskID)
+			return true
+		}
+	}
+	return false
+}
+
+func (b *MailBridge) fetchLock(taskID string) *sync.Mutex {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	lock := b.fetchLocks[taskID]
+	if lock == nil {
+		lock = &sync.Mutex{}
+		b.fetchLocks[taskID] = lock
+	}
+	return lock
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,189 @@
+package a2agw
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+func TestMailBridgePicksUpRepliesFromEventStreamWithoutPolling(t *testing.T) {
+	transport := &gatedReplyTransport{}
+	events := &fakeEventSource{events: make(chan awid.AgentEvent, 4)}
+	bridge, err := NewMailBridge(MailBridgeConfig{
+		Client:          transport,
+		GatewayIdentity: "did:aw:gateway",
+		PollInterval:    5 * time.Millisecond,
+		EventStream: func(context.Context, time.Time) (awid.EventSource, error) {
+			return events, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bridge.Close()
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", Bridge: bridge, Routes: []Route{supportRoute("r_support")}})
+	bridge.SetReplyApplier(gw)
+	events.events <- awid.AgentEvent{Type: awid.AgentEventConnected}
+	waitFor(t, "event stream live", func() bool { return bridge.ReplyMetrics().EventStream == EventStreamLive })
+
+	taskID, token := sendTestA2ATaskWithToken(t, gw)
+	transport.replyTo(taskID)
+	// The new thread catches up once; after that the live stream carries it.
+	waitFor(t, "catch-up fetch", func() bool { return transport.fetches() == 1 })
+	time.Sleep(30 * time.Millisecond)
+	if got := transport.fetches(); got != 1 {
+		t.Fatalf("bridge polled %d times while the stream was live", got)
+	}
+
+	transport.release()
+	events.events <- awid.AgentEvent{Type: awid.AgentEventActionableMail, ConversationID: "conv-1", MessageID: "reply-1"}
+	waitFor(t, "task completed", func() bool {
+		get := postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req-get", "GetTask", map[string]any{"id": taskID}), map[string]string{"X-A2A-Task-Token": token}, http.StatusOK)
+		return taskStatus(rpcTaskResult(t, get, "")) == TaskStateCompleted
+	})
+	metrics := bridge.ReplyMetrics()
+	if metrics.Stream.Replies != 1 || metrics.Poll.Replies != 0 {
+		t.Fatalf("metrics=%#v", metrics)
+	}
+	if health := gw.Health(); health.ReplyPickup == nil || health.ReplyPickup.Stream.Replies != 1 {
+		t.Fatalf("health reply pickup=%#v", health.ReplyPickup)
+	}
+}
+
+func TestMailBridgeFallsBackToPollingWhenEventStreamIsLost(t *testing.T) {
+	transport := &gatedReplyTransport{}
+	audit := &memoryAuditSink{}
+	events := &fakeEventSource{events: make(chan awid.AgentEvent, 4)}
+	var mu sync.Mutex
+	opens := 0
+	bridge, err := NewMailBridge(MailBridgeConfig{
+		Client:          transport,
+		GatewayIdentity: "did:aw:gateway",
+		Audit:           audit,
+		PollInterval:    5 * time.Millisecond,
+		EventStream: func(context.Context, time.Time) (awid.EventSource, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			opens++
+			if opens == 1 {
+				return events, nil
+			}
+			return nil, &awid.APIError{StatusCode: http.StatusUnauthorized}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bridge.Close()
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", Bridge: bridge, Routes: []Route{supportRoute("r_support")}})
+	bridge.SetReplyApplier(gw)
+	events.events <- awid.AgentEvent{Type: awid.AgentEventConnected}
+	waitFor(t, "event stream live", func() bool { return bridge.ReplyMetrics().EventStream == EventStreamLive })
+
+	taskID, _ := sendTestA2ATaskWithToken(t, gw)
+	transport.replyTo(taskID)
+	transport.release()
+	events.fail(errors.New("connection reset"))
+
+	waitFor(t, "poll pickup", func() bool { return bridge.ReplyMetrics().Poll.Replies == 1 })
+	waitFor(t, "stream disabled after 401 on reconnect", func() bool { return bridge.ReplyMetrics().EventStream == EventStreamDisabled })
+	if event := waitForAuditStage(t, audit, "event_stream", "error"); event.Code != "stream_lost" {
+		t.Fatalf("first stream error code=%q", event.Code)
+	}
+}
+
+// gatedReplyTransport serves an empty conversation until release, then the
+// agent's reply for replyTaskID.
+type gatedReplyTransport struct {
+	fakeMailTransport
+
+	gate        sync.Mutex
+	replyTaskID string
+	released    bool
+	calls       int
+}
+
+func (f *gatedReplyTransport) MailConversation(_ context.Context, conversationID string, _ int) (*awid.InboxResponse, error) {
+	f.gate.Lock()
+	defer f.gate.Unlock()
+	f.calls++
+	if !f.released {
+		return &awid.InboxResponse{}, nil
+	}
+	return &awid.InboxResponse{Messages: []awid.InboxMessage{{
+		MessageID:          "reply-1",
+		ConversationID:     conversationID,
+		Body:               "```a2a-reply\n{\"task_id\":\"" + f.replyTaskID + "\",\"context_id\":\"ctx-1\",\"state\":\"completed\",\"artifacts\":[{\"type\":\"text\",\"text\":\"done\"}]}\n```",
+		VerificationStatus: awid.Verified,
+	}}}, nil
+}
+
+func (f *gatedReplyTransport) replyTo(taskID string) {
+	f.gate.Lock()
+	defer f.gate.Unlock()
+	f.replyTaskID = taskID
+}
+
+func (f *gatedReplyTransport) release() {
+	f.gate.Lock()
+	defer f.gate.Unlock()
+	f.released = true
+}
+
+func (f *gatedReplyTransport) fetches() int {
+	f.gate.Lock()
+	defer f.gate.Unlock()
+	return f.calls
+}
+
+type fakeEventSource struct {
+	events chan awid.AgentEvent
+	mu     sync.Mutex
+	err    error
+	failed chan struct{}
+}
+
+func (s *fakeEventSource) Next(ctx context.Context) (*awid.AgentEvent, error) {
+	s.mu.Lock()
+	if s.failed == nil {
+		s.failed = make(chan struct{})
+	}
+	failed := s.failed
+	s.mu.Unlock()
+	select {
+	case evt := <-s.events:
+		return &evt, nil
+	case <-failed:
+		return nil, s.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *fakeEventSource) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failed == nil {
+		s.failed = make(chan struct{})
+	}
+	s.err = err
+	close(s.failed)
+}
+
+func (s *fakeEventSource) Close() error { return nil }
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
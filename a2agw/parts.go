@@ -0,0 +1,196 @@
+package a2agw
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+const (
+	partKindText = "text"
+	partKindFile = "file"
+	partKindData = "data"
+)
+
+// kind reports which content field the part carries, or "" when it carries
+// none or more than one.
+func (p A2APart) kind() string {
+	kind, count := "", 0
+	if p.Text != "" {
+		kind, count = partKindText, count+1
+	}
+	if len(p.Raw) > 0 {
+		kind, count = partKindFile, count+1
+	}
+	if strings.TrimSpace(p.URL) != "" {
+		kind, count = partKindFile, count+1
+	}
+	if len(p.Data) > 0 {
+		kind, count = partKindData, count+1
+	}
+	if count != 1 {
+		return ""
+	}
+	return kind
+}
+
+// validateInboundPart checks one caller part. Text parts are always
+// text/plain; file and data parts must use a media type the route lists in
+// its card input modes.
+func validateInboundPart(part A2APart, inputModes []string) error {
+	kind := part.kind()
+	if kind == "" {
+		if part.Text == "" && len(part.Raw) == 0 && part.URL == "" && len(part.Data) == 0 {
+			return fmt.Errorf("part must not be empty")
+		}
+		return fmt.Errorf("part must carry exactly one of text, raw, url or data")
+	}
+	mediaType, err := normalizedPartMediaType(part)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case partKindText:
+		if mediaType != "text/plain" {
+			return fmt.Errorf("unsupported part mediaType %q", part.MediaType)
+		}
+		return nil
+	case partKindData:
+		if bytes.Equal(bytes.TrimSpace(part.Data), []byte("null")) {
+			return fmt.Errorf("data part must not be null")
+		}
+	case partKindFile:
+		if part.URL != "" && !isHTTPSURL(part.URL) {
+			return fmt.Errorf("file part url must be an absolute https URL")
+		}
+	}
+	if !mediaTypeAccepted(mediaType, inputModes) {
+		return fmt.Errorf("part mediaType %q is not accepted by this agent", mediaType)
+	}
+	return nil
+}
+
+// normalizedPartMediaType returns the part's bare, lower-case media type,
+// defaulting by kind when the caller left it empty.
+func normalizedPartMediaType(part A2APart) (string, error) {
+	raw := strings.TrimSpace(part.MediaType)
+	if raw == "" {
+		switch part.kind() {
+		case partKindData:
+			return "application/json", nil
+		case partKindFile:
+			return "application/octet-stream", nil
+		default:
+			return "text/plain", nil
+		}
+	}
+	mediaType, _, err := mime.ParseMediaType(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid part mediaType %q", part.MediaType)
+	}
+	return mediaType, nil
+}
+
+// mediaTypeAccepted matches against card input modes, honouring "*/*" and
+// "type/*" wildcards. A card without input modes accepts only text/plain.
+func mediaTypeAccepted(mediaType string, inputModes []string) bool {
+	if len(inputModes) == 0 {
+		inputModes = []string{"text/plain"}
+	}
+	for _, mode := range inputModes {
+		mode = strings.ToLower(strings.TrimSpace(mode))
+		if mode == "*/*" || mode == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(mode, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func isHTTPSURL(raw string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	return err == nil && parsed.Scheme == "https" && parsed.Host != ""
+}
+
+func attachmentsFromMessage(message A2AMessage) []A2APart {
+	var out []A2APart
+	for _, part := range message.Parts {
+		if part.kind() == partKindText {
+			continue
+		}
+		part.MediaType, _ = normalizedPartMediaType(part)
+		out = append(out, part)
+	}
+	return out
+}
+
+// a2aAttachment is how a caller file or data part appears in the bridged
+// mail body, one fenced a2a-attachment block per part.
+type a2aAttachment struct {
+	Type      string          `json:"type"`
+	MediaType string          `json:"media_type"`
+	Filename  string          `json:"filename,omitempty"`
+	URL       string          `json:"url,omitempty"`
+	Bytes     []byte          `json:"bytes,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// formatA2AAttachments renders caller attachments for the mail body.
+// Backticks are escaped so untrusted content cannot close the fence.
+func formatA2AAttachments(parts []A2APart) (string, error) {
+	if len(parts) == 0 {
+		return "", nil
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\nCustomer attachments (untrusted):\n")
+	for _, part := range parts {
+		attachment := a2aAttachment{Type: part.kind(), MediaType: part.MediaType, Filename: strings.TrimSpace(part.Filename), URL: strings.TrimSpace(part.URL), Bytes: part.Raw, Data: part.Data}
+		body, err := json.MarshalIndent(attachment, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString("\n```a2a-attachment\n")
+		sb.WriteString(strings.ReplaceAll(string(body), "`", `\u0060`))
+		sb.WriteString("\n```\n")
+	}
+	return sb.String(), nil
+}
+
+// replyArtifactPart converts one artifact from an agent's a2a-reply block
+// into an A2A part. ok is false for artifacts with no usable content.
+func replyArtifactPart(artifact a2aReplyArtifact) (A2APart, bool, error) {
+	switch strings.TrimSpace(artifact.Type) {
+	case "", partKindText:
+		text := strings.TrimSpace(artifact.Text)
+		return A2APart{Text: text, MediaType: "text/plain"}, text != "", nil
+	case partKindData:
+		if len(artifact.Data) == 0 || bytes.Equal(bytes.TrimSpace(artifact.Data), []byte("null")) {
+			return A2APart{}, false, nil
+		}
+		part := A2APart{Data: artifact.Data, MediaType: strings.TrimSpace(artifact.MediaType)}
+		if part.MediaType == "" {
+			part.MediaType = "application/json"
+		}
+		return part, true, nil
+	case partKindFile:
+		part := A2APart{Raw: artifact.Bytes, URL: strings.TrimSpace(artifact.URL), Filename: strings.TrimSpace(artifact.Filename), MediaType: strings.TrimSpace(artifact.MediaType)}
+		if part.kind() != partKindFile {
+			return A2APart{}, false, errors.New("a2a-reply file artifact needs exactly one of url or bytes")
+		}
+		if part.URL != "" && !isHTTPSURL(part.URL) {
+			return A2APart{}, false, errors.New("a2a-reply file artifact url must be an absolute https URL")
+		}
+		if part.MediaType == "" {
+			part.MediaType = "application/octet-stream"
+		}
+		return part, true, nil
+	default:
+		return A2APart{}, false, nil
+	}
+}
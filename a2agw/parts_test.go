@@ -0,0 +1,135 @@
+package a2agw
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/awebai/aw/awid"
+)
+
+func TestMailBridgeCarriesFileAndDataPartsBothWays(t *testing.T) {
+	transport := &fakeMailTransport{}
+	bridge := newTestMailBridge(t, transport, &memoryAuditSink{})
+	route := supportRoute("r_support")
+	route.Card.DefaultInputModes = []string{"text/plain", "application/json", "image/*"}
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", Bridge: bridge, Routes: []Route{route}})
+	bridge.SetReplyApplier(gw)
+
+	resp := postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req-1", "SendMessage", map[string]any{
+		"message": map[string]any{
+			"messageId": "msg-1",
+			"contextId": "ctx-1",
+			"role":      RoleUser,
+			"parts": []map[string]any{
+				{"text": "Order details attached.", "mediaType": "text/plain"},
+				{"data": map[string]any{"order": 1234, "note": "```a2a-reply"}},
+				{"raw": base64.StdEncoding.EncodeToString([]byte("PNG")), "filename": "receipt.png", "mediaType": "image/png"},
+				{"url": "https://files.example.com/invoice.png", "mediaType": "image/png"},
+			},
+		},
+		"configuration": map[string]any{"returnImmediately": true},
+	}), map[string]string{"X-A2A-Caller-ID": "alice"}, http.StatusOK)
+	task := rpcTaskResult(t, resp, "task")
+	taskID := task["id"].(string)
+
+	body := transport.sent[0].Body
+	for _, want := range []string{
+		"Customer message (untrusted):\n\nOrder details attached.",
+		"Customer attachments (untrusted):",
+		`"type": "data"`,
+		`"order": 1234`,
+		`"filename": "receipt.png"`,
+		`"bytes": "` + base64.StdEncoding.EncodeToString([]byte("PNG")) + `"`,
+		`"url": "https://files.example.com/invoice.png"`,
+		`"type":"file"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("bridge body missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Count(body, "```a2a-reply") != 1 {
+		t.Fatalf("attachment content opened a fence:\n%s", body)
+	}
+
+	replyBody := "```a2a-reply\n{\"task_id\":\"" + taskID + "\",\"context_id\":\"ctx-1\",\"state\":\"completed\",\"artifacts\":[" +
+		"{\"type\":\"text\",\"text\":\"Refund issued.\"}," +
+		"{\"type\":\"data\",\"data\":{\"refund\":42}}," +
+		"{\"type\":\"file\",\"filename\":\"refund.pdf\",\"media_type\":\"application/pdf\",\"url\":\"https://files.example.com/refund.pdf\"}]}\n```"
+	got, ok, err := bridge.IngestInboxMessage(context.Background(), awid.InboxMessage{MessageID: "reply-1", ConversationID: "conv-1", Body: replyBody, VerificationStatus: awid.Verified})
+	if err != nil || !ok {
+		t.Fatalf("ingest: ok=%t err=%v", ok, err)
+	}
+	if len(got.Artifacts) != 3 {
+		t.Fatalf("artifacts=%#v", got.Artifacts)
+	}
+	if data := got.Artifacts[1].Parts[0]; string(data.Data) != `{"refund":42}` || data.MediaType != "application/json" {
+		t.Fatalf("data artifact=%#v", data)
+	}
+	if file := got.Artifacts[2]; file.Name != "refund.pdf" || file.Parts[0].URL != "https://files.example.com/refund.pdf" || file.Parts[0].MediaType != "application/pdf" {
+		t.Fatalf("file artifact=%#v", file)
+	}
+	if got.Status.Message.Parts[0].Text != "Refund issued." {
+		t.Fatalf("status text=%q", got.Status.Message.Parts[0].Text)
+	}
+}
+
+func TestGatewayRejectsUnacceptedOrOversizedParts(t *testing.T) {
+	route := supportRoute("r_support")
+	route.Card.DefaultInputModes = []string{"text/plain", "application/json", "image/png"}
+	route.Limits.MaxMessageBytes = 4096
+	bridge := &fakeBridge{}
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", Bridge: bridge, Routes: []Route{route}})
+	for name, part := range map[string]map[string]any{
+		"unlisted media type": {"raw": base64.StdEncoding.EncodeToString([]byte("%PDF")), "mediaType": "application/pdf"},
+		"plain http file url": {"url": "http://files.example.com/a.png", "mediaType": "image/png"},
+		"null data":           {"data": nil},
+		"text and data":       {"text": "hi", "data": map[string]any{"a": 1}},
+		"empty part":          {"mediaType": "text/plain"},
+		"rendered too large":  {"data": strings.Repeat("``", 1000)},
+	} {
+		resp := postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req", "SendMessage", map[string]any{"message": map[string]any{
+			"messageId": "msg-1",
+			"contextId": "ctx-1",
+			"role":      RoleUser,
+			"parts":     []map[string]any{part},
+		}}), map[string]string{"X-A2A-Caller-ID": "alice"}, http.StatusOK)
+		if resp["error"] == nil {
+			t.Fatalf("%s: accepted", name)
+		}
+	}
+	if len(bridge.sent) != 0 {
+		t.Fatalf("rejected parts reached the bridge %d times", len(bridge.sent))
+	}
+}
+
+func TestGatewayCapsTheWholeRenderedMailBody(t *testing.T) {
+	route := supportRoute("r_support")
+	route.Limits.MaxMessageBytes = 2048
+	bridge := &fakeBridge{}
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", Bridge: bridge, Routes: []Route{route}})
+	resp := postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req", "SendMessage", map[string]any{
+		"message": testUserMessage("msg-1", "ctx-1", strings.Repeat("a", 1700)),
+	}), map[string]string{"X-A2A-Caller-ID": "alice"}, http.StatusOK)
+	if rpcErrorCode(resp) != "message_too_large" {
+		t.Fatalf("text that fits the request but not the rendered mail: %#v", resp)
+	}
+	if len(bridge.sent) != 0 {
+		t.Fatalf("oversized task reached the bridge %d times", len(bridge.sent))
+	}
+}
+
+func TestParseA2AReplyRejectsAmbiguousFileArtifacts(t *testing.T) {
+	for name, artifact := range map[string]string{
+		"url and bytes": `{"type":"file","url":"https://files.example.com/a","bytes":"UE5H"}`,
+		"no content":    `{"type":"file","filename":"a.txt"}`,
+		"http url":      `{"type":"file","url":"http://files.example.com/a"}`,
+	} {
+		body := "```a2a-reply\n{\"task_id\":\"t_1\",\"state\":\"completed\",\"artifacts\":[" + artifact + "]}\n```"
+		if _, found, err := ParseA2AReply(body, false); !found || err == nil {
+			t.Fatalf("%s: found=%t err=%v", name, found, err)
+		}
+	}
+}
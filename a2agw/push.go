@@ -0,0 +1,384 @@
+package a2agw
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultPushMaxAttempts = 4
+	defaultPushBackoff     = time.Second
+	pushRequestTimeout     = 10 * time.Second
+)
+
+// PushNotificationConfig is where the gateway POSTs task updates for a
+// caller that does not hold a stream open. Token is echoed in
+// X-A2A-Notification-Token and keys the X-A2A-Signature HMAC so receivers
+// can check both origin and integrity. Token and the bearer credentials are
+// held in memory only, like the task token: a TaskStore never sees them.
+type PushNotificationConfig struct {
+	ID             string                  `json:"id,omitempty"`
+	URL            string                  `json:"url"`
+	Token          string                  `json:"token,omitempty"`
+	Authentication *PushAuthenticationInfo `json:"authentication,omitempty"`
+}
+
+// PushAuthenticationInfo selects how the gateway authenticates to the
+// webhook. Only the bearer scheme is supported.
+type PushAuthenticationInfo struct {
+	Schemes     []string `json:"schemes"`
+	Credentials string   `json:"credentials,omitempty"`
+}
+
+type TaskPushNotificationConfig struct {
+	TaskID                 string                 `json:"taskId"`
+	PushNotificationConfig PushNotificationConfig `json:"pushNotificationConfig"`
+}
+
+type getTaskPushNotificationConfigParams struct {
+	ID                       string `json:"id"`
+	PushNotificationConfigID string `json:"pushNotificationConfigId,omitempty"`
+}
+
+func (g *Gateway) rpcSetTaskPushNotificationConfig(route Route, raw json.RawMessage, requestID string, caller callerScope) (any, *rpcError) {
+	if !route.Card.PushNotifications {
+		return nil, jsonRPCError(-32003, "push notifications are not enabled for this route", requestID, map[string]any{"code": "push_notifications_not_supported"})
+	}
+	var params TaskPushNotificationConfig
+	if err := parseRawObject(raw, &params); err != nil {
+		return nil, jsonRPCError(-32602, "invalid params", requestID, map[string]any{"detail": err.Error()})
+	}
+	config, err := normalizePushConfig(params.PushNotificationConfig, g.config.AllowLoopbackPushURLs)
+	if err != nil {
+		return nil, jsonRPCError(-32602, "invalid push notification config", requestID, map[string]any{"detail": err.Error()})
+	}
+	record, ok := g.tasks.setPushConfig(route.RouteID, caller.Value, caller.TaskToken, strings.TrimSpace(params.TaskID), config)
+	if !ok {
+		return nil, jsonRPCError(-32004, "task not found", requestID, map[string]any{"code": "task_not_found"})
+	}
+	return TaskPushNotificationConfig{TaskID: record.ID, PushNotificationConfig: *record.PushNotification}, nil
+}
+
+func (g *Gateway) rpcGetTaskPushNotificationConfig(route Route, raw json.RawMessage, requestID string, caller callerScope) (any, *rpcError) {
+	if !route.Card.PushNotifications {
+		return nil, jsonRPCError(-32003, "push notifications are not enabled for this route", requestID, map[string]any{"code": "push_notifications_not_supported"})
+	}
+	var params getTaskPushNotificationConfigParams
+	if err := parseRawObject(raw, &params); err != nil {
+		return nil, jsonRPCError(-32602, "invalid params", requestID, map[string]any{"detail": err.Error()})
+	}
+	record, ok := g.tasks.getVisible(route.RouteID, caller.Value, caller.TaskToken, strings.TrimSpace(params.ID))
+	if !ok {
+		return nil, jsonRPCError(-32004, "task not found", requestID, map[string]any{"code": "task_not_found"})
+	}
+	configID := strings.TrimSpace(params.PushNotificationConfigID)
+	if record.PushNotification == nil || (configID != "" && configID != record.PushNotification.ID) {
+		return nil, jsonRPCError(-32004, "push notification config not found", requestID, map[string]any{"code": "push_notification_config_not_found"})
+	}
+	return TaskPushNotificationConfig{TaskID: record.ID, PushNotificationConfig: *record.PushNotification}, nil
+}
+
+// normalizePushConfig validates a caller-supplied webhook. It must use
+// https. Internal and loopback addresses are refused here when given as
+// literals, and for every resolved address at dial time. allowLoopback is
+// Config.AllowLoopbackPushURLs: it admits receivers on the gateway's own
+// host, over http as well, for local development and tests.
+func normalizePushConfig(config PushNotificationConfig, allowLoopback bool) (PushNotificationConfig, error) {
+	config.ID = strings.TrimSpace(config.ID)
+	config.URL = strings.TrimSpace(config.URL)
+	if config.URL == "" {
+		return config, fmt.Errorf("pushNotificationConfig.url is required")
+	}
+	parsed, err := url.Parse(config.URL)
+	if err != nil || parsed.Host == "" {
+		return config, fmt.Errorf("pushNotificationConfig.url must be an absolute URL")
+	}
+	if ip, err := netip.ParseAddr(parsed.Hostname()); err == nil && pushAddressBlocked(ip, allowLoopback) {
+		return config, fmt.Errorf("pushNotificationConfig.url must not point at a private, loopback or link-local address")
+	}
+	if strings.EqualFold(parsed.Hostname(), "localhost") && !allowLoopback {
+		return config, fmt.Errorf("pushNotificationConfig.url must not point at a private, loopback or link-local address")
+	}
+	switch parsed.Scheme {
+	case "https":
+	case "http":
+		if !allowLoopback || !isLoopbackHost(parsed.Hostname()) {
+			return config, fmt.Errorf("pushNotificationConfig.url must use https")
+		}
+	default:
+		return config, fmt.Errorf("pushNotificationConfig.url must use https")
+	}
+	if auth := config.Authentication; auth != nil {
+		for _, scheme := range auth.Schemes {
+			if !strings.EqualFold(strings.TrimSpace(scheme), "bearer") {
+				return config, fmt.Errorf("unsupported push authentication scheme %q", scheme)
+			}
+		}
+		if len(auth.Schemes) > 0 && strings.TrimSpace(auth.Credentials) == "" {
+			return config, fmt.Errorf("bearer push authentication requires credentials")
+		}
+	}
+	if config.ID == "" {
+		id, err := newUUIDv4()
+		if err != nil {
+			return config, err
+		}
+		config.ID = id
+	}
+	return config, nil
+}
+
+// pushConfigHasSecrets reports whether config carries a notification token
+// or bearer credentials, which a TaskStore must not receive.
+func pushConfigHasSecrets(config *PushNotificationConfig) bool {
+	if config == nil {
+		return false
+	}
+	return config.Token != "" || (config.Authentication != nil && config.Authentication.Credentials != "")
+}
+
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// sharedAddressSpace is 100.64.0.0/10, used for carrier-grade NAT and by
+// some clouds for their metadata services.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// pushAddressBlocked reports whether a webhook must not be delivered to ip:
+// loopback, private, link-local (including the 169.254.169.254 metadata
+// service), shared, unspecified and multicast addresses. Loopback is only
+// reachable when the operator set Config.AllowLoopbackPushURLs.
+func pushAddressBlocked(ip netip.Addr, allowLoopback bool) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() {
+		return !allowLoopback
+	}
+	return ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// newPushHTTPClient checks each address when it is dialed, after DNS, so a
+// hostname that resolves to an internal or loopback address is refused as
+// well as an IP literal. Redirects are not followed: a 3xx is the final
+// response.
+func newPushHTTPClient(allowLoopback bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: pushRequestTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil {
+				return fmt.Errorf("push notification dial to %q: %w", address, err)
+			}
+			if pushAddressBlocked(ip, allowLoopback) {
+				return fmt.Errorf("push notification address %s is not publicly routable", ip)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would dial on the gateway's behalf and bypass the check.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   pushRequestTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (s *taskStore) setPushConfig(routeID, callerScope, token, taskID string, config PushNotificationConfig) (*TaskRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.tasks[taskID]
+	if !ok || record.RouteID != routeID || s.expiredLocked(record) || !record.visibleTo(callerScope, token) {
+		return nil, false
+	}
+	record.PushNotification = &config
+	record.UpdatedAt = s.now().UTC()
+	s.persistBestEffortLocked(record)
+	return cloneRecord(record), true
+}
+
+func (s *taskStore) setPusher(pusher *pushNotifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pusher = pusher
+}
+
+// pushNotifier delivers task updates to caller webhooks. Deliveries for one
+// task go out in order from a single worker; different tasks do not wait on
+// each other. Like the task store it survives gateway rebuilds.
+type pushNotifier struct {
+	maxAttempts int
+	backoff     time.Duration
+
+	mu            sync.Mutex
+	client        *http.Client
+	allowLoopback bool
+	audit         AuditSink
+	queues        map[string][]pushDelivery
+}
+
+type pushDelivery struct {
+	RequestID string
+	RouteID   string
+	TaskID    string
+	Config    PushNotificationConfig
+	Events    []streamResponse
+}
+
+func newPushNotifier() *pushNotifier {
+	return &pushNotifier{
+		client:      newPushHTTPClient(false),
+		maxAttempts: defaultPushMaxAttempts,
+		backoff:     defaultPushBackoff,
+		queues:      map[string][]pushDelivery{},
+	}
+}
+
+// configure applies the gateway config the notifier serves. A rebuilt
+// gateway keeps its notifier, so this runs on every build.
+func (p *pushNotifier) configure(audit AuditSink, allowLoopback bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.audit = audit
+	if allowLoopback != p.allowLoopback {
+		p.allowLoopback = allowLoopback
+		p.client = newPushHTTPClient(allowLoopback)
+	}
+}
+
+// enqueue is called with the task store lock held, so it only queues.
+func (p *pushNotifier) enqueue(record *TaskRecord, update taskUpdate) {
+	delivery := pushDelivery{RequestID: record.RequestID, RouteID: record.RouteID, TaskID: record.ID, Config: *record.PushNotification, Events: updateEvents(update)}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.queues[record.ID] = append(p.queues[record.ID], delivery)
+	if len(p.queues[record.ID]) == 1 {
+		go p.drain(record.ID)
+	}
+}
+
+func (p *pushNotifier) drain(taskID string) {
+	for {
+		p.mu.Lock()
+		queue := p.queues[taskID]
+		if len(queue) == 0 {
+			delete(p.queues, taskID)
+			p.mu.Unlock()
+			return
+		}
+		delivery := queue[0]
+		p.mu.Unlock()
+
+		for _, event := range delivery.Events {
+			p.deliver(delivery, event)
+		}
+
+		p.mu.Lock()
+		p.queues[taskID] = p.queues[taskID][1:]
+		p.mu.Unlock()
+	}
+}
+
+// deliver POSTs one event, retrying transport errors, 429 and 5xx with
+// exponential backoff up to maxAttempts. Other statuses are final.
+func (p *pushNotifier) deliver(delivery pushDelivery, event streamResponse) {
+	start := time.Now()
+	body, err := json.Marshal(event)
+	if err != nil {
+		p.recordAudit(delivery, "error", "marshal_failed", start)
+		return
+	}
+	code := ""
+	for attempt := 0; attempt < p.maxAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(p.backoff << (attempt - 1))
+		}
+		var retry bool
+		code, retry = p.post(delivery.Config, body)
+		if code == "" {
+			p.recordAudit(delivery, "ok", "", start)
+			return
+		}
+		if !retry {
+			break
+		}
+	}
+	p.recordAudit(delivery, "error", code, start)
+}
+
+func (p *pushNotifier) post(config PushNotificationConfig, body []byte) (code string, retry bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), pushRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.URL, bytes.NewReader(body))
+	if err != nil {
+		return "invalid_url", false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if config.Token != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-A2A-Notification-Token", config.Token)
+		req.Header.Set("X-A2A-Signature", "t="+timestamp+",v1="+pushSignature(config.Token, timestamp, body))
+	}
+	if auth := config.Authentication; auth != nil && len(auth.Schemes) > 0 {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(auth.Credentials))
+	}
+	p.mu.Lock()
+	client := p.client
+	p.mu.Unlock()
+	resp, err := client.Do(req)
+	if err != nil {
+		return "transport_error", true
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return "", false
+	}
+	return "http_" + strconv.Itoa(resp.StatusCode), resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// pushSignature is HMAC-SHA256 over "<timestamp>.<body>" keyed by the
+// caller's notification token.
+func pushSignature(token, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (p *pushNotifier) recordAudit(delivery pushDelivery, outcome, code string, start time.Time) {
+	p.mu.Lock()
+	audit := p.audit
+	p.mu.Unlock()
+	if audit == nil {
+		return
+	}
+	audit.RecordA2A(AuditEvent{Stage: "push_notification", RequestID: delivery.RequestID, RouteID: delivery.RouteID, TaskID: delivery.TaskID, TargetAddressHash: auditHash(delivery.Config.URL), Outcome: outcome, Code: code, LatencyMS: latencyMS(start), VerificationTier: "unsigned"})
+}
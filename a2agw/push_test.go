@@ -0,0 +1,289 @@
+package a2agw
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGatewayPushNotificationsDeliverSignedTaskUpdates(t *testing.T) {
+	receiver := newPushReceiver(t, nil)
+	audit := &memoryAuditSink{}
+	route := supportRoute("r_support")
+	route.Card.PushNotifications = true
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", Bridge: &fakeBridge{}, Audit: audit, Routes: []Route{route}, AllowLoopbackPushURLs: true})
+	taskID, token := sendTestA2ATaskWithToken(t, gw)
+
+	set := postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req-set", "SetTaskPushNotificationConfig", map[string]any{
+		"taskId": taskID,
+		"pushNotificationConfig": map[string]any{
+			"url":            receiver.server.URL + "/hook",
+			"token":          "notify-secret",
+			"authentication": map[string]any{"schemes": []string{"Bearer"}, "credentials": "hook-jwt"},
+		},
+	}), map[string]string{"X-A2A-Task-Token": token}, http.StatusOK)
+	configID := rpcTaskResult(t, set, "pushNotificationConfig")["id"].(string)
+	if configID == "" {
+		t.Fatalf("set result missing config id: %#v", set)
+	}
+	get := postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req-get", "GetTaskPushNotificationConfig", map[string]any{"id": taskID, "pushNotificationConfigId": configID}), map[string]string{"X-A2A-Task-Token": token}, http.StatusOK)
+	if got := rpcTaskResult(t, get, "pushNotificationConfig")["url"]; got != receiver.server.URL+"/hook" {
+		t.Fatalf("get url=%v", got)
+	}
+
+	if _, ok, err := gw.ApplyBridgeReply(BridgeReply{TaskID: taskID, ContextID: "ctx-1", State: "completed", Text: "Shipped Tuesday."}); err != nil || !ok {
+		t.Fatalf("apply: ok=%t err=%v", ok, err)
+	}
+	requests := receiver.wait(t, 2)
+	if _, ok := requests[0].body["artifactUpdate"]; !ok {
+		t.Fatalf("first push=%s", requests[0].raw)
+	}
+	status := requests[1].body["statusUpdate"].(map[string]any)
+	if status["final"] != true || taskStatus(status) != TaskStateCompleted {
+		t.Fatalf("status push=%s", requests[1].raw)
+	}
+	for _, req := range requests {
+		if req.header.Get("Authorization") != "Bearer hook-jwt" || req.header.Get("X-A2A-Notification-Token") != "notify-secret" {
+			t.Fatalf("push headers=%v", req.header)
+		}
+		timestamp, signature, _ := strings.Cut(strings.TrimPrefix(req.header.Get("X-A2A-Signature"), "t="), ",v1=")
+		if signature != pushSignature("notify-secret", timestamp, req.raw) {
+			t.Fatalf("push signature %q does not match body", req.header.Get("X-A2A-Signature"))
+		}
+	}
+	waitForAuditStage(t, audit, "push_notification", "ok")
+}
+
+func TestGatewayPushNotificationsRetryTransientFailures(t *testing.T) {
+	var mu sync.Mutex
+	failures := 2
+	receiver := newPushReceiver(t, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures > 0 {
+			failures--
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	})
+	audit := &memoryAuditSink{}
+	route := supportRoute("r_support")
+	route.Card.PushNotifications = true
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", Bridge: &fakeBridge{}, Audit: audit, Routes: []Route{route}, AllowLoopbackPushURLs: true})
+	gw.push.backoff = time.Millisecond
+
+	resp := postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req-send", "SendMessage", map[string]any{
+		"message": testUserMessage("msg-1", "ctx-1", "hello"),
+		"configuration": map[string]any{
+			"returnImmediately":      true,
+			"pushNotificationConfig": map[string]any{"url": receiver.server.URL},
+		},
+	}), nil, http.StatusOK)
+	taskID := rpcTaskResult(t, resp, "task")["id"].(string)
+	postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req-cancel", "CancelTask", map[string]any{"id": taskID}), map[string]string{"X-A2A-Task-Token": taskBearerToken(t, rpcTaskResult(t, resp, "task"))}, http.StatusOK)
+
+	// The WORKING transition is retried past two 503s before the
+	// cancellation goes out, keeping per-task order.
+	requests := receiver.wait(t, 4)
+	for i, want := range []string{TaskStateWorking, TaskStateWorking, TaskStateWorking, TaskStateCanceled} {
+		if state := taskStatus(requests[i].body["statusUpdate"].(map[string]any)); state != want {
+			t.Fatalf("push %d state=%s, want %s", i, state, want)
+		}
+	}
+	waitForAuditStage(t, audit, "push_notification", "ok")
+}
+
+func TestGatewayPushNotificationsGiveUpOnClientErrors(t *testing.T) {
+	receiver := newPushReceiver(t, func() int { return http.StatusGone })
+	audit := &memoryAuditSink{}
+	route := supportRoute("r_support")
+	route.Card.PushNotifications = true
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", Bridge: &fakeBridge{}, Audit: audit, Routes: []Route{route}, AllowLoopbackPushURLs: true})
+	gw.push.backoff = time.Millisecond
+	taskID, token := sendTestA2ATaskWithToken(t, gw)
+	postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req-set", "SetTaskPushNotificationConfig", map[string]any{
+		"taskId":                 taskID,
+		"pushNotificationConfig": map[string]any{"url": receiver.server.URL},
+	}), map[string]string{"X-A2A-Task-Token": token}, http.StatusOK)
+	if _, ok, err := gw.ApplyBridgeReply(BridgeReply{TaskID: taskID, ContextID: "ctx-1", State: "failed"}); err != nil || !ok {
+		t.Fatalf("apply: ok=%t err=%v", ok, err)
+	}
+	event := waitForAuditStage(t, audit, "push_notification", "error")
+	if event.Code != "http_410" {
+		t.Fatalf("audit code=%q", event.Code)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := len(receiver.wait(t, 1)); got != 1 {
+		t.Fatalf("client error retried: %d requests", got)
+	}
+}
+
+func TestGatewayPushNotificationConfigValidation(t *testing.T) {
+	route := supportRoute("r_support")
+	route.Card.PushNotifications = true
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", RootCardMode: RootCardDefaultAgent, DefaultRouteID: "r_support", Bridge: &fakeBridge{}, Routes: []Route{route, helpRoute("r_help")}})
+	taskID, token := sendTestA2ATaskWithToken(t, gw)
+	headers := map[string]string{"X-A2A-Task-Token": token}
+	for name, config := range map[string]map[string]any{
+		"plain http":     {"url": "http://hooks.example.com/a2a"},
+		"relative":       {"url": "/a2a"},
+		"unknown scheme": {"url": "https://hooks.example.com", "authentication": map[string]any{"schemes": []string{"Basic"}, "credentials": "x"}},
+		"no credentials": {"url": "https://hooks.example.com", "authentication": map[string]any{"schemes": []string{"Bearer"}}},
+		"private ip":     {"url": "https://10.0.0.5/a2a"},
+		"metadata":       {"url": "https://169.254.169.254/latest/meta-data"},
+		"ipv6 ula":       {"url": "https://[fd00::1]/a2a"},
+		"loopback":       {"url": "https://127.0.0.1:8080/a2a"},
+		"loopback http":  {"url": "http://127.0.0.1:8080/a2a"},
+		"ipv6 loopback":  {"url": "https://[::1]/a2a"},
+		"localhost":      {"url": "http://localhost:8080/a2a"},
+	} {
+		resp := postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req", "SetTaskPushNotificationConfig", map[string]any{"taskId": taskID, "pushNotificationConfig": config}), headers, http.StatusOK)
+		if resp["error"] == nil {
+			t.Fatalf("%s: accepted %#v", name, config)
+		}
+	}
+	resp := postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req", "SetTaskPushNotificationConfig", map[string]any{"taskId": taskID, "pushNotificationConfig": map[string]any{"url": "https://hooks.example.com"}}), nil, http.StatusOK)
+	if rpcErrorCode(resp) != "task_not_found" {
+		t.Fatalf("set without task token: %#v", resp)
+	}
+	resp = postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req", "GetTaskPushNotificationConfig", map[string]any{"id": taskID}), headers, http.StatusOK)
+	if rpcErrorCode(resp) != "push_notification_config_not_found" {
+		t.Fatalf("get before set: %#v", resp)
+	}
+	resp = postRPC(t, gw, "/a2a/agents/r_help/rpc", rpcEnvelope("req", "GetTaskPushNotificationConfig", map[string]any{"id": taskID}), headers, http.StatusOK)
+	if rpcErrorCode(resp) != "push_notifications_not_supported" {
+		t.Fatalf("route without push: %#v", resp)
+	}
+}
+
+func TestPushHTTPClientRefusesInternalAddressesAtDialTime(t *testing.T) {
+	client := newPushHTTPClient(false)
+	for _, target := range []string{"https://10.255.255.1:9/a2a", "https://192.168.0.1:9/a2a", "https://169.254.169.254/latest", "https://[fd00::1]:9/a2a", "https://127.0.0.1:9/a2a", "https://[::1]:9/a2a"} {
+		resp, err := client.Post(target, "application/json", strings.NewReader("{}"))
+		if err == nil {
+			resp.Body.Close()
+			t.Fatalf("%s: request sent", target)
+		}
+		if !strings.Contains(err.Error(), "not publicly routable") {
+			t.Fatalf("%s: err=%v", target, err)
+		}
+	}
+	for _, addr := range []string{"203.0.113.7", "2001:db8::1"} {
+		if pushAddressBlocked(netip.MustParseAddr(addr), false) {
+			t.Fatalf("%s blocked", addr)
+		}
+	}
+	for _, addr := range []string{"127.0.0.1", "127.8.9.10", "::1", "::ffff:127.0.0.1", "172.16.3.4", "100.100.100.200", "::ffff:10.0.0.1", "0.0.0.0", "fe80::1"} {
+		if !pushAddressBlocked(netip.MustParseAddr(addr), false) {
+			t.Fatalf("%s allowed", addr)
+		}
+	}
+	for _, addr := range []string{"127.0.0.1", "::1"} {
+		if pushAddressBlocked(netip.MustParseAddr(addr), true) {
+			t.Fatalf("%s blocked with loopback allowed", addr)
+		}
+	}
+	if !pushAddressBlocked(netip.MustParseAddr("10.0.0.1"), true) {
+		t.Fatal("private address allowed with loopback allowed")
+	}
+}
+
+func TestGatewayPushNotificationsDoNotFollowRedirects(t *testing.T) {
+	target := newPushReceiver(t, nil)
+	redirect := httptest.NewServer(http.RedirectHandler(target.server.URL, http.StatusTemporaryRedirect))
+	t.Cleanup(redirect.Close)
+	audit := &memoryAuditSink{}
+	route := supportRoute("r_support")
+	route.Card.PushNotifications = true
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", Bridge: &fakeBridge{}, Audit: audit, Routes: []Route{route}, AllowLoopbackPushURLs: true})
+	gw.push.backoff = time.Millisecond
+	taskID, token := sendTestA2ATaskWithToken(t, gw)
+	postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req-set", "SetTaskPushNotificationConfig", map[string]any{
+		"taskId":                 taskID,
+		"pushNotificationConfig": map[string]any{"url": redirect.URL},
+	}), map[string]string{"X-A2A-Task-Token": token}, http.StatusOK)
+	if _, ok, err := gw.ApplyBridgeReply(BridgeReply{TaskID: taskID, ContextID: "ctx-1", State: "completed"}); err != nil || !ok {
+		t.Fatalf("apply: ok=%t err=%v", ok, err)
+	}
+	event := waitForAuditStage(t, audit, "push_notification", "error")
+	if event.Code != "http_307" {
+		t.Fatalf("audit code=%q", event.Code)
+	}
+	target.mu.Lock()
+	defer target.mu.Unlock()
+	if len(target.requests) != 0 {
+		t.Fatalf("redirect followed: %d requests", len(target.requests))
+	}
+}
+
+type pushRequest struct {
+	header http.Header
+	raw    []byte
+	body   map[string]any
+}
+
+type pushReceiver struct {
+	server   *httptest.Server
+	mu       sync.Mutex
+	requests []pushRequest
+}
+
+func newPushReceiver(t *testing.T, status func() int) *pushReceiver {
+	t.Helper()
+	receiver := &pushReceiver{}
+	receiver.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(raw, &body)
+		receiver.mu.Lock()
+		receiver.requests = append(receiver.requests, pushRequest{header: r.Header.Clone(), raw: raw, body: body})
+		receiver.mu.Unlock()
+		if status != nil {
+			w.WriteHeader(status())
+		}
+	}))
+	t.Cleanup(receiver.server.Close)
+	return receiver
+}
+
+func (r *pushReceiver) wait(t *testing.T, n int) []pushRequest {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		r.mu.Lock()
+		got := append([]pushRequest(nil), r.requests...)
+		r.mu.Unlock()
+		if len(got) >= n {
+			return got
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d push requests, want %d", len(got), n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func waitForAuditStage(t *testing.T, audit *memoryAuditSink, stage, outcome string) AuditEvent {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		audit.mu.Lock()
+		for _, event := range audit.events {
+			if event.Stage == stage && event.Outcome == outcome {
+				audit.mu.Unlock()
+				return event
+			}
+		}
+		audit.mu.Unlock()
+		if time.Now().After(deadline) {
+			t.Fatalf("audit never recorded %s/%s", stage, outcome)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
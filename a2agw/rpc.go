@@ -42,8 +42,9 @@ type sendMessageParams struct {
 }
 
 type sendMessageConfig struct {
-	ReturnImmediately   bool     `json:"returnImmediately,omitempty"`
-	AcceptedOutputModes []string `json:"acceptedOutputModes,omitempty"`
+	ReturnImmediately      bool                    `json:"returnImmediately,omitempty"`
+	AcceptedOutputModes    []string                `json:"acceptedOutputModes,omitempty"`
+	PushNotificationConfig *PushNotificationConfig `json:"pushNotificationConfig,omitempty"`
 }
 
 type getTaskParams struct {
@@ -118,6 +119,10 @@ func (g *Gateway) serveRPC(w http.ResponseWriter, r *http.Request, routeID strin
 		writeRPC(w, http.StatusOK, rpcResponse{JSONRPC: jsonRPCVersion, ID: normalizedID(req.ID), Error: jsonRPCError(-32029, "rate limited", requestID, map[string]any{"code": "rate_limited"})})
 		return
 	}
+	if isStreamingMethod(req.Method) {
+		g.serveRPCStream(w, r, route, req, requestID, caller, start)
+		return
+	}
 	result, rpcErr := g.handleRPCMethod(r.Context(), route, req, requestID, caller)
 	resp := rpcResponse{JSONRPC: jsonRPCVersion, ID: normalizedID(req.ID)}
 	if rpcErr != nil {
@@ -140,38 +145,83 @@ func (g *Gateway) handleRPCMethod(ctx context.Context, route Route, req rpcReque
 		return g.rpcListTasks(route, req.Params, requestID, caller)
 	case "CancelTask":
 		return g.rpcCancelTask(ctx, route, req.Params, requestID, caller)
+	case "SetTaskPushNotificationConfig":
+		return g.rpcSetTaskPushNotificationConfig(route, req.Params, requestID, caller)
+	case "GetTaskPushNotificationConfig":
+		return g.rpcGetTaskPushNotificationConfig(route, req.Params, requestID, caller)
 	default:
 		return nil, jsonRPCError(-32601, "method not found", requestID, map[string]any{"method": req.Method})
 	}
 }
 
 func (g *Gateway) rpcSendMessage(ctx context.Context, route Route, raw json.RawMessage, requestID string, caller callerScope) (any, *rpcError) {
+	record, config, rpcErr := g.startTask(ctx, route, raw, requestID, caller)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if record.Status.State == TaskStateAuthRequired || config.ReturnImmediately {
+		return map[string]any{"task": record.Task}, nil
+	}
+	waited, ok := g.tasks.wait(record.ID, effectiveResponseTimeout(route))
+	if !ok {
+		return nil, jsonRPCError(-32000, "task unavailable", requestID, nil)
+	}
+	return map[string]any{"task": waited.Task}, nil
+}
+
+// startTask validates a SendMessage-shaped request, creates the task and
+// hands it to the bridge. The returned record is WORKING, or AUTH_REQUIRED
+// when the route needs credentials the caller did not present.
+func (g *Gateway) startTask(ctx context.Context, route Route, raw json.RawMessage, requestID string, caller callerScope) (*TaskRecord, sendMessageConfig, *rpcError) {
 	if !g.taskExecution {
-		return nil, jsonRPCError(-32000, "aweb bridge not configured", requestID, map[string]any{"code": "bridge_not_configured"})
+		return nil, sendMessageConfig{}, jsonRPCError(-32000, "aweb bridge not configured", requestID, map[string]any{"code": "bridge_not_configured"})
 	}
 	if acceptUntil := g.AcceptNewTasksUntil(); !acceptUntil.IsZero() && time.Now().After(acceptUntil) {
-		return nil, jsonRPCError(-32003, "A2A gateway config is expired", requestID, map[string]any{"code": "managed_config_expired"})
+		return nil, sendMessageConfig{}, jsonRPCError(-32003, "A2A gateway config is expired", requestID, map[string]any{"code": "managed_config_expired"})
 	}
 	var params sendMessageParams
 	if err := parseRawObject(raw, &params); err != nil {
-		return nil, jsonRPCError(-32602, "invalid params", requestID, map[string]any{"detail": err.Error()})
+		return nil, sendMessageConfig{}, jsonRPCError(-32602, "invalid params", requestID, map[string]any{"detail": err.Error()})
 	}
-	if err := validateInboundMessage(params.Message); err != nil {
-		return nil, jsonRPCError(-32602, "invalid message", requestID, map[string]any{"detail": err.Error()})
+	if err := validateInboundMessage(params.Message, route.Card.DefaultInputModes); err != nil {
+		return nil, sendMessageConfig{}, jsonRPCError(-32602, "invalid message", requestID, map[string]any{"detail": err.Error()})
+	}
+	// The request body is already capped, but the bridged mail adds the
+	// task envelope and reply instructions and attachments grow when
+	// re-rendered, so the whole rendered mail body is capped too.
+	text := textFromMessage(params.Message)
+	attachments := attachmentsFromMessage(params.Message)
+	if size, err := renderedTaskMessageSize(route, caller, requestID, params.Message.ContextID, text, attachments); err != nil || size > effectiveMaxMessageBytes(route) {
+		return nil, sendMessageConfig{}, jsonRPCError(-32602, "message too large", requestID, map[string]any{"code": "message_too_large"})
+	}
+	var pushConfig *PushNotificationConfig
+	if params.Configuration.PushNotificationConfig != nil {
+		if !route.Card.PushNotifications {
+			return nil, sendMessageConfig{}, jsonRPCError(-32003, "push notifications are not enabled for this route", requestID, map[string]any{"code": "push_notifications_not_supported"})
+		}
+		config, err := normalizePushConfig(*params.Configuration.PushNotificationConfig, g.config.AllowLoopbackPushURLs)
+		if err != nil {
+			return nil, sendMessageConfig{}, jsonRPCError(-32602, "invalid push notification config", requestID, map[string]any{"detail": err.Error()})
+		}
+		pushConfig = &config
 	}
 	if !authRequired(route, caller) && route.Limits.MaxConcurrentTasks > 0 && g.tasks.activeCount(route.RouteID, caller.Value) >= route.Limits.MaxConcurrentTasks {
-		return nil, jsonRPCError(-32003, "too many active tasks", requestID, map[string]any{"code": "max_concurrent_tasks"})
+		return nil, sendMessageConfig{}, jsonRPCError(-32003, "too many active tasks", requestID, map[string]any{"code": "max_concurrent_tasks"})
 	}
 	record, err := g.tasks.create(route.RouteID, caller.Value, requestID, params.Message, effectiveTaskTTL(route))
 	if err != nil {
-		return nil, jsonRPCError(-32000, "task id generation failed", requestID, nil)
+		return nil, sendMessageConfig{}, jsonRPCError(-32000, "task id generation failed", requestID, nil)
+	}
+	if pushConfig != nil {
+		if withPush, ok := g.tasks.setPushConfig(route.RouteID, caller.Value, record.taskToken, record.ID, *pushConfig); ok {
+			record = withPush
+		}
 	}
 	if authRequired(route, caller) {
 		msg := statusMessage(record.ID, record.ContextID, TaskStateAuthRequired, "This route requires caller authentication.")
 		record, _ = g.tasks.updateState(record.ID, TaskStateAuthRequired, &msg, nil)
-		return map[string]any{"task": record.Task}, nil
+		return record, params.Configuration, nil
 	}
-	text := textFromMessage(params.Message)
 	if err := g.bridge.SendTask(ctx, BridgeTask{
 		RequestID:   requestID,
 		RouteID:     route.RouteID,
@@ -181,27 +231,20 @@ func (g *Gateway) rpcSendMessage(ctx context.Context, route Route, raw json.RawM
 		MessageID:   params.Message.MessageID,
 		CallerScope: caller.Value,
 		Text:        text,
+		Attachments: attachments,
 		TaskTTL:     effectiveTaskTTL(route),
 	}); err != nil {
 		if errors.Is(err, errBridgeNotConfigured) {
-			return nil, jsonRPCError(-32000, "aweb bridge not configured", requestID, map[string]any{"code": "bridge_not_configured"})
+			return nil, sendMessageConfig{}, jsonRPCError(-32000, "aweb bridge not configured", requestID, map[string]any{"code": "bridge_not_configured"})
 		}
 		g.tasks.failTask(record.ID, "A2A gateway failed to send the durable bridge message.")
-		return nil, jsonRPCError(-32000, "bridge send failed", requestID, map[string]any{"detail": err.Error()})
-	}
-	if updated, ok := g.tasks.updateWorking(record.ID); ok {
-		record = updated
-	} else {
-		return nil, jsonRPCError(-32000, "task unavailable", requestID, nil)
-	}
-	if params.Configuration.ReturnImmediately {
-		return map[string]any{"task": record.Task}, nil
+		return nil, sendMessageConfig{}, jsonRPCError(-32000, "bridge send failed", requestID, map[string]any{"detail": err.Error()})
 	}
-	waited, ok := g.tasks.wait(record.ID, effectiveResponseTimeout(route))
+	updated, ok := g.tasks.updateWorking(record.ID)
 	if !ok {
-		return nil, jsonRPCError(-32000, "task unavailable", requestID, nil)
+		return nil, sendMessageConfig{}, jsonRPCError(-32000, "task unavailable", requestID, nil)
 	}
-	return map[string]any{"task": waited.Task}, nil
+	return updated, params.Configuration, nil
 }
 
 func (g *Gateway) rpcGetTask(route Route, raw json.RawMessage, requestID string, caller callerScope) (any, *rpcError) {
@@ -259,7 +302,7 @@ func (g *Gateway) rpcCancelTask(ctx context.Context, route Route, raw json.RawMe
 	return record.Task, nil
 }
 
-func validateInboundMessage(message A2AMessage) error {
+func validateInboundMessage(message A2AMessage, inputModes []string) error {
 	if strings.TrimSpace(message.MessageID) == "" {
 		return fmt.Errorf("message.messageId is required")
 	}
@@ -270,15 +313,8 @@ func validateInboundMessage(message A2AMessage) error {
 		return fmt.Errorf("message.parts must be non-empty")
 	}
 	for _, part := range message.Parts {
-		mediaType := strings.TrimSpace(part.MediaType)
-		if mediaType == "" {
-			mediaType = "text/plain"
-		}
-		if mediaType != "text/plain" {
-			return fmt.Errorf("unsupported part mediaType %q", part.MediaType)
-		}
-		if part.Text == "" {
-			return fmt.Errorf("text part must not be empty")
+		if err := validateInboundPart(part, inputModes); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -287,7 +323,9 @@ func validateInboundMessage(message A2AMessage) error {
 func textFromMessage(message A2AMessage) string {
 	parts := make([]string, 0, len(message.Parts))
 	for _, part := range message.Parts {
-		parts = append(parts, part.Text)
+		if part.kind() == partKindText {
+			parts = append(parts, part.Text)
+		}
 	}
 	return strings.Join(parts, "\n\n")
 }
@@ -364,6 +402,22 @@ func requestIDFromHeader(r *http.Request) string {
 	return mustNewUUIDv4()
 }
 
+// renderedTaskMessageSize is the length of the mail body the bridge will
+// send for a new task. The task ID is not assigned yet, so a placeholder of
+// the same length stands in for it.
+func renderedTaskMessageSize(route Route, caller callerScope, requestID, contextID, text string, attachments []A2APart) (int, error) {
+	body, err := FormatA2ATaskMessage(a2aTaskEnvelope{
+		TaskID:        "00000000-0000-4000-8000-000000000000",
+		ContextID:     contextID,
+		RouteID:       route.RouteID,
+		TargetAddress: route.Address,
+		CallerScope:   bridgeVisibleCallerScope(caller.Value),
+		State:         TaskStateWorking,
+		RequestID:     requestID,
+	}, text, attachments...)
+	return len(body), err
+}
+
 func readLimitedBody(body io.ReadCloser, maxBytes int) ([]byte, error) {
 	defer body.Close()
 	reader := io.LimitReader(body, int64(maxBytes)+1)
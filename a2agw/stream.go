@@ -0,0 +1,243 @@
+package a2agw
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// taskWatcherBuffer bounds the updates queued for one stream. Bridged tasks
+// make a handful of transitions, so a full buffer means the reader is slow;
+// the oldest queued update is then folded into the new one rather than
+// dropping the new one, so the final state always arrives.
+const taskWatcherBuffer = 16
+
+// TaskStatusUpdateEvent is the A2A streaming event for a task state change.
+// Final marks the last event of a stream.
+type TaskStatusUpdateEvent struct {
+	TaskID    string         `json:"taskId"`
+	ContextID string         `json:"contextId,omitempty"`
+	Status    TaskStatus     `json:"status"`
+	Final     bool           `json:"final,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+}
+
+// TaskArtifactUpdateEvent is the A2A streaming event for an artifact the
+// bridged agent produced. Gateway artifacts are always whole, so LastChunk
+// is set on every event.
+type TaskArtifactUpdateEvent struct {
+	TaskID    string   `json:"taskId"`
+	ContextID string   `json:"contextId,omitempty"`
+	Artifact  Artifact `json:"artifact"`
+	Append    bool     `json:"append,omitempty"`
+	LastChunk bool     `json:"lastChunk,omitempty"`
+}
+
+type streamResponse struct {
+	Task           *Task                    `json:"task,omitempty"`
+	StatusUpdate   *TaskStatusUpdateEvent   `json:"statusUpdate,omitempty"`
+	ArtifactUpdate *TaskArtifactUpdateEvent `json:"artifactUpdate,omitempty"`
+}
+
+type subscribeToTaskParams struct {
+	ID string `json:"id"`
+}
+
+// taskUpdate is one state transition delivered to task watchers. Artifacts
+// holds only the artifacts produced by that transition.
+type taskUpdate struct {
+	Task      Task
+	Artifacts []Artifact
+}
+
+func (s *taskStore) subscribe(taskID string) (<-chan taskUpdate, func()) {
+	ch := make(chan taskUpdate, taskWatcherBuffer)
+	s.mu.Lock()
+	if s.watchers == nil {
+		s.watchers = map[string]map[chan taskUpdate]struct{}{}
+	}
+	if s.watchers[taskID] == nil {
+		s.watchers[taskID] = map[chan taskUpdate]struct{}{}
+	}
+	s.watchers[taskID][ch] = struct{}{}
+	s.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.watchers[taskID], ch)
+			if len(s.watchers[taskID]) == 0 {
+				delete(s.watchers, taskID)
+			}
+		})
+	}
+}
+
+func (s *taskStore) notifyLocked(record *TaskRecord, artifacts []Artifact) {
+	watchers := s.watchers[record.ID]
+	push := record.PushNotification != nil && s.pusher != nil
+	if len(watchers) == 0 && !push {
+		return
+	}
+	update := taskUpdate{Task: cloneTask(record.Task), Artifacts: cloneArtifacts(artifacts)}
+	if push {
+		s.pusher.enqueue(record, update)
+	}
+	for ch := range watchers {
+		deliverTaskUpdate(ch, update)
+	}
+}
+
+// deliverTaskUpdate queues update without blocking. When ch is full, the
+// oldest queued update is taken back and its artifacts carried in update, so
+// only its intermediate status is lost. Callers hold the store lock, so no
+// other sender can refill the slot in between.
+func deliverTaskUpdate(ch chan taskUpdate, update taskUpdate) {
+	for {
+		select {
+		case ch <- update:
+			return
+		default:
+		}
+		select {
+		case oldest := <-ch:
+			update.Artifacts = append(oldest.Artifacts, update.Artifacts...)
+		default:
+		}
+	}
+}
+
+func (s *taskStore) snapshot(taskID string) (*TaskRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.tasks[taskID]
+	if !ok || s.expiredLocked(record) {
+		return nil, false
+	}
+	return cloneRecord(record), true
+}
+
+func isStreamingMethod(method string) bool {
+	return method == "SendStreamingMessage" || method == "SubscribeToTask"
+}
+
+// serveRPCStream answers SendStreamingMessage and SubscribeToTask with an
+// SSE stream of JSON-RPC responses: the current task first, then status and
+// artifact updates as bridge replies arrive. The stream ends when the task
+// reaches a terminal or interrupted state, expires, or the caller leaves.
+// Errors before the first event use the ordinary JSON-RPC response.
+func (g *Gateway) serveRPCStream(w http.ResponseWriter, r *http.Request, route Route, req rpcRequest, requestID string, caller callerScope, start time.Time) {
+	id := normalizedID(req.ID)
+	record, rpcErr := g.openTaskStream(r.Context(), route, req, requestID, caller)
+	if rpcErr != nil {
+		g.audit(AuditEvent{Stage: "gateway_response", RequestID: requestID, RouteID: route.RouteID, CallerScopeClass: callerScopeClass(caller.Value), TargetAddressHash: auditHash(route.Address), Outcome: "error", Code: rpcErrCode(rpcErr), LatencyMS: latencyMS(start), VerificationTier: "unsigned"})
+		writeRPC(w, http.StatusOK, rpcResponse{JSONRPC: jsonRPCVersion, ID: id, Error: rpcErr})
+		return
+	}
+	updates, unsubscribe := g.tasks.subscribe(record.ID)
+	defer unsubscribe()
+	// Re-read after subscribing so a transition that landed between task
+	// start and subscribe is reflected in the first event.
+	if current, ok := g.tasks.snapshot(record.ID); ok {
+		record = current
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	code := g.streamTaskEvents(r.Context(), w, id, record, updates)
+	g.audit(AuditEvent{Stage: "gateway_response", RequestID: requestID, RouteID: route.RouteID, TaskID: record.ID, CallerScopeClass: callerScopeClass(caller.Value), TargetAddressHash: auditHash(route.Address), Outcome: "ok", Code: code, LatencyMS: latencyMS(start), VerificationTier: "unsigned"})
+}
+
+func (g *Gateway) openTaskStream(ctx context.Context, route Route, req rpcRequest, requestID string, caller callerScope) (*TaskRecord, *rpcError) {
+	if !route.Card.Streaming {
+		return nil, jsonRPCError(-32003, "streaming is not enabled for this route", requestID, map[string]any{"code": "streaming_not_supported"})
+	}
+	if req.Method == "SendStreamingMessage" {
+		record, _, rpcErr := g.startTask(ctx, route, req.Params, requestID, caller)
+		return record, rpcErr
+	}
+	var params subscribeToTaskParams
+	if err := parseRawObject(req.Params, &params); err != nil {
+		return nil, jsonRPCError(-32602, "invalid params", requestID, map[string]any{"detail": err.Error()})
+	}
+	taskID := strings.TrimSpace(params.ID)
+	if taskID == "" {
+		return nil, jsonRPCError(-32602, "task id is required", requestID, nil)
+	}
+	record, ok := g.tasks.getVisible(route.RouteID, caller.Value, caller.TaskToken, taskID)
+	if !ok && publicAnonymousTaskLookupAllowed(route, caller) {
+		record, ok = g.tasks.getPublicAnonymous(route.RouteID, taskID)
+	}
+	if !ok {
+		return nil, jsonRPCError(-32004, "task not found", requestID, map[string]any{"code": "task_not_found"})
+	}
+	return record, nil
+}
+
+// streamTaskEvents writes events until the stream ends and returns the
+// audit code describing why it ended.
+func (g *Gateway) streamTaskEvents(ctx context.Context, w http.ResponseWriter, id json.RawMessage, record *TaskRecord, updates <-chan taskUpdate) string {
+	task := record.Task
+	if err := writeStreamEvent(w, id, streamResponse{Task: &task}); err != nil {
+		return "stream_write_failed"
+	}
+	if streamFinished(task.Status.State) {
+		return ""
+	}
+	expiry := time.NewTimer(time.Until(record.ExpiresAt))
+	defer expiry.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return "stream_client_closed"
+		case <-expiry.C:
+			return "stream_task_expired"
+		case update := <-updates:
+			for _, event := range updateEvents(update) {
+				if err := writeStreamEvent(w, id, event); err != nil {
+					return "stream_write_failed"
+				}
+			}
+			if streamFinished(update.Task.Status.State) {
+				return ""
+			}
+		}
+	}
+}
+
+// updateEvents renders one task transition as A2A stream responses: an
+// artifact event per artifact the transition produced, then the status
+// event.
+func updateEvents(update taskUpdate) []streamResponse {
+	out := make([]streamResponse, 0, len(update.Artifacts)+1)
+	for _, artifact := range update.Artifacts {
+		out = append(out, streamResponse{ArtifactUpdate: &TaskArtifactUpdateEvent{TaskID: update.Task.ID, ContextID: update.Task.ContextID, Artifact: artifact, LastChunk: true}})
+	}
+	out = append(out, streamResponse{StatusUpdate: &TaskStatusUpdateEvent{TaskID: update.Task.ID, ContextID: update.Task.ContextID, Status: update.Task.Status, Final: streamFinished(update.Task.Status.State)}})
+	return out
+}
+
+func streamFinished(state string) bool {
+	return isTerminalState(state) || isInterruptedState(state)
+}
+
+func writeStreamEvent(w http.ResponseWriter, id json.RawMessage, result streamResponse) error {
+	data, err := json.Marshal(rpcResponse{JSONRPC: jsonRPCVersion, ID: id, Result: result})
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+		return err
+	}
+	if err := http.NewResponseController(w).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package a2agw
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/awebai/aw/awid"
+)
+
+func TestGatewaySendStreamingMessagePushesBridgeReply(t *testing.T) {
+	transport := &fakeMailTransport{}
+	audit := &memoryAuditSink{}
+	bridge := newTestMailBridge(t, transport, audit)
+	route := supportRoute("r_support")
+	route.Card.Streaming = true
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", Bridge: bridge, Audit: audit, Routes: []Route{route}})
+	bridge.SetReplyApplier(gw)
+	server := httptest.NewServer(gw)
+	defer server.Close()
+
+	events := openRPCStream(t, server.URL+"/a2a/agents/r_support/rpc", rpcEnvelope("req-stream", "SendStreamingMessage", map[string]any{
+		"message": testUserMessage("msg-1", "ctx-1", "Where is order 1234?"),
+	}), nil)
+	first := nextStreamResult(t, events)
+	task := first["task"].(map[string]any)
+	if taskStatus(task) != TaskStateWorking {
+		t.Fatalf("first event state=%s", taskStatus(task))
+	}
+	taskID := task["id"].(string)
+
+	replyBody := "```a2a-reply\n{\"task_id\":\"" + taskID + "\",\"context_id\":\"ctx-1\",\"state\":\"completed\",\"artifacts\":[{\"type\":\"text\",\"text\":\"Shipped Tuesday.\"}]}\n```"
+	if _, ok, err := bridge.IngestInboxMessage(context.Background(), awid.InboxMessage{MessageID: "reply-1", ConversationID: "conv-1", Body: replyBody, VerificationStatus: awid.Verified}); err != nil || !ok {
+		t.Fatalf("ingest: ok=%t err=%v", ok, err)
+	}
+
+	artifact := nextStreamResult(t, events)["artifactUpdate"].(map[string]any)
+	if artifact["taskId"] != taskID || artifact["lastChunk"] != true {
+		t.Fatalf("artifact event=%#v", artifact)
+	}
+	part := artifact["artifact"].(map[string]any)["parts"].([]any)[0].(map[string]any)
+	if part["text"] != "Shipped Tuesday." {
+		t.Fatalf("artifact text=%v", part["text"])
+	}
+	status := nextStreamResult(t, events)["statusUpdate"].(map[string]any)
+	if status["final"] != true || taskStatus(status) != TaskStateCompleted {
+		t.Fatalf("status event=%#v", status)
+	}
+	if _, ok := <-events; ok {
+		t.Fatal("stream stayed open after the final event")
+	}
+}
+
+func TestGatewaySubscribeToTaskStreamsCancellation(t *testing.T) {
+	bridge := &fakeBridge{}
+	route := supportRoute("r_support")
+	route.Card.Streaming = true
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", Bridge: bridge, Routes: []Route{route}})
+	server := httptest.NewServer(gw)
+	defer server.Close()
+	taskID, token := sendTestA2ATaskWithToken(t, gw)
+
+	events := openRPCStream(t, server.URL+"/a2a/agents/r_support/rpc", rpcEnvelope("req-sub", "SubscribeToTask", map[string]any{"id": taskID}), map[string]string{"X-A2A-Task-Token": token})
+	if state := taskStatus(nextStreamResult(t, events)["task"].(map[string]any)); state != TaskStateWorking {
+		t.Fatalf("first event state=%s", state)
+	}
+	postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req-cancel", "CancelTask", map[string]any{"id": taskID}), map[string]string{"X-A2A-Task-Token": token}, http.StatusOK)
+	status := nextStreamResult(t, events)["statusUpdate"].(map[string]any)
+	if status["final"] != true || taskStatus(status) != TaskStateCanceled {
+		t.Fatalf("status event=%#v", status)
+	}
+}
+
+func TestGatewayStreamingMethodsRequireStreamingRouteAndVisibility(t *testing.T) {
+	bridge := &fakeBridge{}
+	streaming := supportRoute("r_support")
+	streaming.Card.Streaming = true
+	plain := helpRoute("r_help")
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", RootCardMode: RootCardDefaultAgent, DefaultRouteID: "r_support", Bridge: bridge, Routes: []Route{streaming, plain}})
+
+	resp := postRPC(t, gw, "/a2a/agents/r_help/rpc", rpcEnvelope("req-1", "SendStreamingMessage", map[string]any{"message": testUserMessage("msg-1", "ctx-1", "hi")}), nil, http.StatusOK)
+	if rpcErrorCode(resp) != "streaming_not_supported" {
+		t.Fatalf("non-streaming route: %#v", resp)
+	}
+	if len(bridge.sent) != 0 {
+		t.Fatalf("rejected stream still sent %d bridge tasks", len(bridge.sent))
+	}
+	resp = postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req-2", "SubscribeToTask", map[string]any{"id": "00000000-0000-4000-8000-000000000000"}), nil, http.StatusOK)
+	if rpcErrorCode(resp) != "task_not_found" {
+		t.Fatalf("unknown task: %#v", resp)
+	}
+}
+
+// openRPCStream posts a streaming JSON-RPC request and returns the decoded
+// data payloads; the channel closes when the server ends the stream.
+func openRPCStream(t *testing.T, url string, payload any, headers map[string]string) <-chan map[string]any {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("stream content type=%q", got)
+	}
+	out := make(chan map[string]any, 16)
+	go func() {
+		defer close(out)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			var event map[string]any
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+				return
+			}
+			out <- event
+		}
+	}()
+	return out
+}
+
+func nextStreamResult(t *testing.T, events <-chan map[string]any) map[string]any {
+	t.Helper()
+	event, ok := <-events
+	if !ok {
+		t.Fatal("stream closed early")
+	}
+	return rpcTaskResult(t, event, "")
+}
+
+func TestTaskWatcherKeepsFinalUpdateWhenBufferIsFull(t *testing.T) {
+	store := newTaskStore(nil)
+	updates, unsubscribe := store.subscribe("task-1")
+	defer unsubscribe()
+
+	record := &TaskRecord{Task: Task{ID: "task-1"}}
+	for i := 0; i < taskWatcherBuffer+4; i++ {
+		record.Status = TaskStatus{State: TaskStateWorking}
+		store.notifyLocked(record, []Artifact{{ArtifactID: "a-" + strconv.Itoa(i)}})
+	}
+	record.Status = TaskStatus{State: TaskStateCompleted}
+	store.notifyLocked(record, nil)
+
+	var artifacts int
+	var last taskUpdate
+	for len(updates) > 0 {
+		last = <-updates
+		artifacts += len(last.Artifacts)
+	}
+	if last.Task.Status.State != TaskStateCompleted {
+		t.Fatalf("last queued state=%s", last.Task.Status.State)
+	}
+	if artifacts != taskWatcherBuffer+4 {
+		t.Fatalf("artifacts delivered=%d", artifacts)
+	}
+}
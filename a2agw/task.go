@@ -3,10 +3,13 @@ package a2agw
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -48,6 +51,9 @@ type BridgeTask struct {
 	MessageID   string
 	CallerScope string
 	Text        string
+	// Attachments are the caller's file and data parts, rendered into the
+	// mail body after Text.
+	Attachments []A2APart
 	// TaskTTL bounds how long the bridge keeps polling for the agent reply.
 	TaskTTL time.Duration
 }
@@ -91,9 +97,16 @@ type A2AMessage struct {
 	Parts     []A2APart `json:"parts"`
 }
 
+// A2APart is one piece of message or artifact content. Exactly one of
+// Text, Raw, URL or Data is set: Raw and URL are file parts carrying inline
+// bytes or a reference, and Data is structured JSON.
 type A2APart struct {
-	Text      string `json:"text"`
-	MediaType string `json:"mediaType"`
+	Text      string          `json:"text,omitempty"`
+	Raw       []byte          `json:"raw,omitempty"`
+	URL       string          `json:"url,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Filename  string          `json:"filename,omitempty"`
+	MediaType string          `json:"mediaType"`
 }
 
 type Artifact struct {
@@ -102,35 +115,100 @@ type Artifact struct {
 	Parts      []A2APart `json:"parts"`
 }
 
-type taskRecord struct {
-	Task
-	RouteID      string
-	CallerScope  string
-	TaskToken    string
-	RequestID    string
-	MessageID    string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-	ExpiresAt    time.Time
-	Terminal     bool
-	Cancellation bool
+// TaskRecord is the gateway's view of an A2A task: the public task plus the
+// routing, caller scope and expiry state a TaskStore persists.
+type TaskRecord struct {
+	Task        `json:"task"`
+	RouteID     string `json:"route_id"`
+	CallerScope string `json:"caller_scope,omitempty"`
+	// TaskTokenHash identifies the caller's task bearer token; the token
+	// itself is never persisted.
+	TaskTokenHash string    `json:"task_token_hash"`
+	RequestID     string    `json:"request_id,omitempty"`
+	MessageID     string    `json:"message_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	Terminal      bool      `json:"terminal,omitempty"`
+	Cancellation  bool      `json:"cancellation,omitempty"`
+	// PushNotification is the caller's webhook for state changes, if any.
+	// A webhook with a token or bearer credentials is not persisted: like
+	// the task token it lives in memory, and after a restart the caller
+	// sets it again.
+	PushNotification *PushNotificationConfig `json:"push_notification,omitempty"`
+
+	// taskToken is the bearer token, held in memory by the gateway that
+	// issued it and never written to a TaskStore.
+	taskToken string
 }
 
 type taskStore struct {
-	mu    sync.Mutex
-	tasks map[string]*taskRecord
-	order []string
-	now   func() time.Time
+	mu      sync.Mutex
+	tasks   map[string]*TaskRecord
+	order   []string
+	now     func() time.Time
+	backend TaskStore
+	// watchers receive every state transition of a task; see subscribe.
+	watchers map[string]map[chan taskUpdate]struct{}
+	pusher   *pushNotifier
+	// onPersistError reports a write-through that failed on a path with no
+	// caller to return the error to.
+	onPersistError func(*TaskRecord, error)
 }
 
 func newTaskStore(now func() time.Time) *taskStore {
 	if now == nil {
 		now = time.Now
 	}
-	return &taskStore{tasks: map[string]*taskRecord{}, now: now}
+	return &taskStore{tasks: map[string]*TaskRecord{}, now: now}
 }
 
-func (s *taskStore) create(routeID, callerScope, requestID string, message A2AMessage, ttl time.Duration) (*taskRecord, error) {
+// loadTaskStore restores unexpired records from backend and writes every
+// later mutation through to it. Expired records are pruned on load.
+func loadTaskStore(now func() time.Time, backend TaskStore) (*taskStore, error) {
+	s := newTaskStore(now)
+	s.backend = backend
+	if backend == nil {
+		return s, nil
+	}
+	records, err := backend.LoadTasks()
+	if err != nil {
+		return nil, fmt.Errorf("load tasks: %w", err)
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].CreatedAt.Before(records[j].CreatedAt) })
+	for i := range records {
+		record := cloneRecord(&records[i])
+		if record.ID == "" {
+			continue
+		}
+		if s.expiredLocked(record) {
+			if err := backend.DeleteTask(record.ID); err != nil {
+				return nil, fmt.Errorf("prune expired task %s: %w", record.ID, err)
+			}
+			continue
+		}
+		if _, exists := s.tasks[record.ID]; !exists {
+			s.order = append(s.order, record.ID)
+		}
+		s.tasks[record.ID] = record
+	}
+	return s, nil
+}
+
+func (s *taskStore) persistLocked(record *TaskRecord) error {
+	if s.backend == nil {
+		return nil
+	}
+	stored := cloneRecord(record)
+	stored.taskToken = ""
+	delete(stored.Metadata, "task_bearer_token")
+	if pushConfigHasSecrets(stored.PushNotification) {
+		stored.PushNotification = nil
+	}
+	return s.backend.SaveTask(*stored)
+}
+
+func (s *taskStore) create(routeID, callerScope, requestID string, message A2AMessage, ttl time.Duration) (*TaskRecord, error) {
 	taskID, err := newUUIDv4()
 	if err != nil {
 		return nil, err
@@ -143,7 +221,7 @@ func (s *taskStore) create(routeID, callerScope, requestID string, message A2AMe
 	if ttl <= 0 {
 		ttl = defaultTaskTTL
 	}
-	record := &taskRecord{
+	record := &TaskRecord{
 		Task: Task{
 			ID:        taskID,
 			ContextID: message.ContextID,
@@ -151,26 +229,30 @@ func (s *taskStore) create(routeID, callerScope, requestID string, message A2AMe
 			History:   []A2AMessage{message},
 			Metadata:  map[string]any{"request_id": requestID},
 		},
-		RouteID:     routeID,
-		CallerScope: callerScope,
-		TaskToken:   token,
-		RequestID:   requestID,
-		MessageID:   message.MessageID,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-		ExpiresAt:   now.Add(ttl),
+		RouteID:       routeID,
+		CallerScope:   callerScope,
+		TaskTokenHash: hashTaskToken(token),
+		taskToken:     token,
+		RequestID:     requestID,
+		MessageID:     message.MessageID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		ExpiresAt:     now.Add(ttl),
 	}
 	if callerScope == "" || callerScope == "anonymous:unscoped" {
 		record.Task.Metadata["task_bearer_token"] = token
 	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if err := s.persistLocked(record); err != nil {
+		return nil, fmt.Errorf("persist task: %w", err)
+	}
 	s.tasks[taskID] = record
 	s.order = append(s.order, taskID)
 	return cloneRecord(record), nil
 }
 
-func (s *taskStore) getVisible(routeID, callerScope, token, taskID string) (*taskRecord, bool) {
+func (s *taskStore) getVisible(routeID, callerScope, token, taskID string) (*TaskRecord, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	record, ok := s.tasks[taskID]
@@ -183,7 +265,7 @@ func (s *taskStore) getVisible(routeID, callerScope, token, taskID string) (*tas
 	return cloneRecord(record), true
 }
 
-func (s *taskStore) getPublicAnonymous(routeID, taskID string) (*taskRecord, bool) {
+func (s *taskStore) getPublicAnonymous(routeID, taskID string) (*TaskRecord, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	record, ok := s.tasks[taskID]
@@ -241,11 +323,11 @@ func (s *taskStore) activeCount(routeID, callerScope string) int {
 	return count
 }
 
-func (s *taskStore) updateWorking(taskID string) (*taskRecord, bool) {
+func (s *taskStore) updateWorking(taskID string) (*TaskRecord, bool) {
 	return s.updateState(taskID, TaskStateWorking, nil, nil)
 }
 
-func (s *taskStore) failTask(taskID, text string) (*taskRecord, bool) {
+func (s *taskStore) failTask(taskID, text string) (*TaskRecord, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	record, ok := s.tasks[taskID]
@@ -257,10 +339,12 @@ func (s *taskStore) failTask(taskID, text string) (*taskRecord, bool) {
 	record.Status = TaskStatus{State: TaskStateFailed, Timestamp: formatTime(now), Message: &msg}
 	record.UpdatedAt = now
 	record.Terminal = true
+	s.persistBestEffortLocked(record)
+	s.notifyLocked(record, nil)
 	return cloneRecord(record), true
 }
 
-func (s *taskStore) cancelTask(routeID, callerScope, token, taskID string) (*taskRecord, bool) {
+func (s *taskStore) cancelTask(routeID, callerScope, token, taskID string) (*TaskRecord, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	record, ok := s.tasks[taskID]
@@ -272,10 +356,12 @@ func (s *taskStore) cancelTask(routeID, callerScope, token, taskID string) (*tas
 	record.UpdatedAt = now
 	record.Terminal = true
 	record.Cancellation = true
+	s.persistBestEffortLocked(record)
+	s.notifyLocked(record, nil)
 	return cloneRecord(record), true
 }
 
-func (s *taskStore) applyReply(reply BridgeReply) (*taskRecord, bool, error) {
+func (s *taskStore) applyReply(reply BridgeReply) (*TaskRecord, bool, error) {
 	state, err := normalizeReplyState(reply.State)
 	if err != nil {
 		return nil, false, err
@@ -298,15 +384,23 @@ func (s *taskStore) applyReply(reply BridgeReply) (*taskRecord, bool, error) {
 	record.Status = TaskStatus{State: state, Timestamp: formatTime(now), Message: &msg}
 	record.UpdatedAt = now
 	record.Terminal = isTerminalState(state)
+	var artifacts []Artifact
 	if len(reply.Artifacts) > 0 {
-		record.Artifacts = cloneArtifacts(reply.Artifacts)
+		artifacts = cloneArtifacts(reply.Artifacts)
 	} else if messageText != "" {
-		record.Artifacts = []Artifact{{ArtifactID: mustNewUUIDv4(), Name: "answer", Parts: []A2APart{{Text: messageText, MediaType: "text/plain"}}}}
+		artifacts = []Artifact{{ArtifactID: mustNewUUIDv4(), Name: "answer", Parts: []A2APart{{Text: messageText, MediaType: "text/plain"}}}}
+	}
+	if artifacts != nil {
+		record.Artifacts = artifacts
+	}
+	s.notifyLocked(record, artifacts)
+	if err := s.persistLocked(record); err != nil {
+		return cloneRecord(record), true, fmt.Errorf("persist task: %w", err)
 	}
 	return cloneRecord(record), true, nil
 }
 
-func (s *taskStore) wait(taskID string, timeout time.Duration) (*taskRecord, bool) {
+func (s *taskStore) wait(taskID string, timeout time.Duration) (*TaskRecord, bool) {
 	deadline := s.now().Add(timeout)
 	for {
 		s.mu.Lock()
@@ -334,7 +428,7 @@ func (s *taskStore) wait(taskID string, timeout time.Duration) (*taskRecord, boo
 	}
 }
 
-func (s *taskStore) updateState(taskID, state string, message *A2AMessage, artifacts []Artifact) (*taskRecord, bool) {
+func (s *taskStore) updateState(taskID, state string, message *A2AMessage, artifacts []Artifact) (*TaskRecord, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	record, ok := s.tasks[taskID]
@@ -348,15 +442,37 @@ func (s *taskStore) updateState(taskID, state string, message *A2AMessage, artif
 	if artifacts != nil {
 		record.Artifacts = cloneArtifacts(artifacts)
 	}
+	s.persistBestEffortLocked(record)
+	s.notifyLocked(record, artifacts)
 	return cloneRecord(record), true
 }
 
-func (s *taskStore) expiredLocked(record *taskRecord) bool {
+// persistBestEffortLocked writes through transitions whose callers have no
+// error path. The in-memory record stays authoritative, and each save
+// carries the full record, so the next successful write repairs the store.
+func (s *taskStore) persistBestEffortLocked(record *TaskRecord) {
+	if err := s.persistLocked(record); err != nil && s.onPersistError != nil {
+		s.onPersistError(record, err)
+	}
+}
+
+func (s *taskStore) setPersistErrorHandler(fn func(*TaskRecord, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onPersistError = fn
+}
+
+func hashTaskToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (s *taskStore) expiredLocked(record *TaskRecord) bool {
 	return !record.ExpiresAt.IsZero() && !s.now().Before(record.ExpiresAt)
 }
 
-func (r *taskRecord) visibleTo(callerScope, token string) bool {
-	if token != "" && token == r.TaskToken {
+func (r *TaskRecord) visibleTo(callerScope, token string) bool {
+	if token != "" && r.TaskTokenHash != "" && subtle.ConstantTimeCompare([]byte(hashTaskToken(token)), []byte(r.TaskTokenHash)) == 1 {
 		return true
 	}
 	return callerScope != "" && callerScope == r.CallerScope && callerScope != "anonymous:unscoped"
@@ -413,12 +529,21 @@ func statusMessage(taskID, contextID, state, text string) A2AMessage {
 	}
 }
 
-func cloneRecord(record *taskRecord) *taskRecord {
+func cloneRecord(record *TaskRecord) *TaskRecord {
 	if record == nil {
 		return nil
 	}
 	clone := *record
 	clone.Task = cloneTask(record.Task)
+	if record.PushNotification != nil {
+		push := *record.PushNotification
+		if push.Authentication != nil {
+			auth := *push.Authentication
+			auth.Schemes = append([]string(nil), auth.Schemes...)
+			push.Authentication = &auth
+		}
+		clone.PushNotification = &push
+	}
 	return &clone
 }
 
@@ -472,7 +597,11 @@ func cloneParts(values []A2APart) []A2APart {
 		return nil
 	}
 	out := make([]A2APart, len(values))
-	copy(out, values)
+	for i, value := range values {
+		out[i] = value
+		out[i].Raw = append([]byte(nil), value.Raw...)
+		out[i].Data = append(json.RawMessage(nil), value.Data...)
+	}
 	return out
 }
 
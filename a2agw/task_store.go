@@ -0,0 +1,199 @@
+package a2agw
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TaskStore persists gateway task records and mail bridge thread state so
+// in-flight A2A tasks survive a gateway restart. Implementations must be
+// safe for concurrent use. Saves carry the full record and replace any
+// previous version.
+type TaskStore interface {
+	LoadTasks() ([]TaskRecord, error)
+	SaveTask(TaskRecord) error
+	DeleteTask(taskID string) error
+	LoadThreads() ([]BridgeThread, error)
+	SaveThread(BridgeThread) error
+	DeleteThread(taskID string) error
+}
+
+// FileTaskStore keeps one JSON document per task and per bridge thread under
+// a state directory. Writes go through a temp file and rename, so a crash
+// leaves either the previous or the next version on disk.
+type FileTaskStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+const (
+	fileTaskStoreTasksDir   = "tasks"
+	fileTaskStoreThreadsDir = "threads"
+)
+
+func NewFileTaskStore(dir string) (*FileTaskStore, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return nil, errors.New("task store directory is required")
+	}
+	for _, sub := range []string{fileTaskStoreTasksDir, fileTaskStoreThreadsDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return nil, err
+		}
+	}
+	return &FileTaskStore{dir: dir}, nil
+}
+
+func (s *FileTaskStore) LoadTasks() ([]TaskRecord, error) {
+	var out []TaskRecord
+	err := s.loadAll(fileTaskStoreTasksDir, func(data []byte) error {
+		var stored struct {
+			TaskRecord
+			// LegacyTaskToken is the plaintext token older gateways wrote;
+			// it is hashed on load and dropped on the next save.
+			LegacyTaskToken string `json:"task_token"`
+		}
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return err
+		}
+		record := stored.TaskRecord
+		if record.TaskTokenHash == "" && stored.LegacyTaskToken != "" {
+			record.TaskTokenHash = hashTaskToken(stored.LegacyTaskToken)
+		}
+		delete(record.Metadata, "task_bearer_token")
+		if pushConfigHasSecrets(record.PushNotification) {
+			// Older gateways wrote webhook secrets in plaintext; the caller
+			// sets the webhook again.
+			record.PushNotification = nil
+		}
+		out = append(out, record)
+		return nil
+	})
+	return out, err
+}
+
+func (s *FileTaskStore) SaveTask(record TaskRecord) error {
+	return s.save(fileTaskStoreTasksDir, record.ID, record)
+}
+
+func (s *FileTaskStore) DeleteTask(taskID string) error {
+	return s.remove(fileTaskStoreTasksDir, taskID)
+}
+
+func (s *FileTaskStore) LoadThreads() ([]BridgeThread, error) {
+	var out []BridgeThread
+	err := s.loadAll(fileTaskStoreThreadsDir, func(data []byte) error {
+		var thread BridgeThread
+		if err := json.Unmarshal(data, &thread); err != nil {
+			return err
+		}
+		out = append(out, thread)
+		return nil
+	})
+	return out, err
+}
+
+func (s *FileTaskStore) SaveThread(thread BridgeThread) error {
+	return s.save(fileTaskStoreThreadsDir, thread.TaskID, thread)
+}
+
+func (s *FileTaskStore) DeleteThread(taskID string) error {
+	return s.remove(fileTaskStoreThreadsDir, taskID)
+}
+
+func (s *FileTaskStore) loadAll(sub string, decode func([]byte) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := os.ReadDir(filepath.Join(s.dir, sub))
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(s.dir, sub, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := decode(data); err != nil {
+			return fmt.Errorf("decode %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func (s *FileTaskStore) save(sub, id string, value any) error {
+	path, err := s.path(sub, id)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
+
+func (s *FileTaskStore) remove(sub, id string) error {
+	path, err := s.path(sub, id)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path maps a task ID to its file, refusing IDs that could escape the state
+// directory. Gateway task IDs are UUIDs, so the allowed alphabet is narrow.
+func (s *FileTaskStore) path(sub, id string) (string, error) {
+	if id == "" {
+		return "", errors.New("task store id is required")
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return "", fmt.Errorf("task store id %q contains unsupported characters", id)
+		}
+	}
+	return filepath.Join(s.dir, sub, id+".json"), nil
+}
@@ -0,0 +1,239 @@
+package a2agw
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileTaskStoreSurvivesRestartAndResumesReplyPolling(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestFileTaskStore(t, dir)
+	bridge, err := NewMailBridge(MailBridgeConfig{Client: &fakeMailTransport{}, GatewayIdentity: "did:aw:gateway", Store: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", Bridge: bridge, TaskStore: store, Routes: []Route{supportRoute("r_support")}})
+	taskID, token := sendTestA2ATaskWithToken(t, gw)
+
+	restartedStore := newTestFileTaskStore(t, dir)
+	transport := &lateReplyTransport{replyAfter: 1, replyTaskID: taskID}
+	restartedBridge, err := NewMailBridge(MailBridgeConfig{Client: transport, GatewayIdentity: "did:aw:gateway", PollInterval: 10 * time.Millisecond, Store: restartedStore})
+	if err != nil {
+		t.Fatal(err)
+	}
+	thread := restartedBridge.thread(taskID)
+	if thread == nil || thread.ConversationID != "conv-1" || !thread.SeenMessages["bridge-msg"] {
+		t.Fatalf("restored thread=%#v", thread)
+	}
+	restarted := newTestGateway(t, Config{Host: "team.aweb.ai", Bridge: restartedBridge, TaskStore: restartedStore, Routes: []Route{supportRoute("r_support")}})
+	get := postRPC(t, restarted, "/a2a/agents/r_support/rpc", rpcEnvelope("req-get", "GetTask", map[string]any{"id": taskID}), map[string]string{"X-A2A-Task-Token": token}, 200)
+	if state := taskStatus(rpcTaskResult(t, get, "")); state != TaskStateWorking {
+		t.Fatalf("restored state=%s", state)
+	}
+
+	restartedBridge.SetReplyApplier(restarted)
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		get := postRPC(t, restarted, "/a2a/agents/r_support/rpc", rpcEnvelope("req-get", "GetTask", map[string]any{"id": taskID}), map[string]string{"X-A2A-Task-Token": token}, 200)
+		if taskStatus(rpcTaskResult(t, get, "")) == TaskStateCompleted {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("restored thread never resumed reply polling")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	deadline = time.Now().Add(time.Second)
+	for {
+		threads, err := restartedStore.LoadThreads()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(threads) == 1 && threads[0].Settled && threads[0].SeenMessages["late-reply-1"] {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("persisted thread not settled: %#v", threads)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	records, err := restartedStore.LoadTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Status.State != TaskStateCompleted || records[0].TaskTokenHash != hashTaskToken(token) {
+		t.Fatalf("persisted records=%#v", records)
+	}
+}
+
+func TestFileTaskStoreKeepsOnlyTheTaskTokenHash(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestFileTaskStore(t, dir)
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", Bridge: &fakeBridge{}, TaskStore: store, Routes: []Route{supportRoute("r_support")}})
+	resp := postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req-1", "SendMessage", map[string]any{
+		"message":       testUserMessage("msg-1", "ctx-1", "No caller header"),
+		"configuration": map[string]any{"returnImmediately": true},
+	}), nil, 200)
+	task := rpcTaskResult(t, resp, "task")
+	token := taskBearerToken(t, task)
+
+	data, err := os.ReadFile(filepath.Join(dir, "tasks", task["id"].(string)+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), token) || !strings.Contains(string(data), hashTaskToken(token)) {
+		t.Fatalf("stored task record:\n%s", data)
+	}
+}
+
+func TestFileTaskStoreKeepsPushSecretsOutOfRecords(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestFileTaskStore(t, dir)
+	route := supportRoute("r_support")
+	route.Card.PushNotifications = true
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", Bridge: &fakeBridge{}, TaskStore: store, Routes: []Route{route}})
+	taskID, token := sendTestA2ATaskWithToken(t, gw)
+	set := postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req-set", "SetTaskPushNotificationConfig", map[string]any{
+		"taskId": taskID,
+		"pushNotificationConfig": map[string]any{
+			"url":            "https://hooks.example.com/a2a",
+			"token":          "notify-secret",
+			"authentication": map[string]any{"schemes": []string{"Bearer"}, "credentials": "hook-jwt"},
+		},
+	}), map[string]string{"X-A2A-Task-Token": token}, 200)
+	if set["error"] != nil {
+		t.Fatalf("set: %#v", set)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "tasks", taskID+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "notify-secret") || strings.Contains(string(data), "hook-jwt") {
+		t.Fatalf("stored task record:\n%s", data)
+	}
+	get := postRPC(t, gw, "/a2a/agents/r_support/rpc", rpcEnvelope("req-get", "GetTaskPushNotificationConfig", map[string]any{"id": taskID}), map[string]string{"X-A2A-Task-Token": token}, 200)
+	if get["error"] != nil {
+		t.Fatalf("running gateway lost the webhook: %#v", get)
+	}
+
+	legacy := `{"task":{"id":"legacy-task"},"route_id":"r_support","push_notification":{"url":"https://hooks.example.com","token":"old-secret"}}`
+	if err := os.WriteFile(filepath.Join(dir, "tasks", "legacy-task.json"), []byte(legacy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	records, err := newTestFileTaskStore(t, dir).LoadTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range records {
+		if record.PushNotification != nil {
+			t.Fatalf("%s loaded webhook secrets: %#v", record.ID, record.PushNotification)
+		}
+	}
+}
+
+func TestFileTaskStoreHashesLegacyPlaintextTokens(t *testing.T) {
+	dir := t.TempDir()
+	legacy := `{"task":{"id":"legacy-task","metadata":{"task_bearer_token":"tok-1"}},"route_id":"r_support","task_token":"tok-1"}`
+	if err := os.WriteFile(filepath.Join(newTestFileTaskStore(t, dir).dir, "tasks", "legacy-task.json"), []byte(legacy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	records, err := newTestFileTaskStore(t, dir).LoadTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].TaskTokenHash != hashTaskToken("tok-1") || !records[0].visibleTo("", "tok-1") {
+		t.Fatalf("records=%#v", records)
+	}
+	if _, ok := records[0].Metadata["task_bearer_token"]; ok {
+		t.Fatalf("legacy bearer token kept in metadata: %#v", records[0].Metadata)
+	}
+}
+
+func TestTaskStoreReportsBestEffortPersistFailures(t *testing.T) {
+	audit := &memoryAuditSink{}
+	store := &failingTaskStore{}
+	gw := newTestGateway(t, Config{Host: "team.aweb.ai", Bridge: &fakeBridge{}, Audit: audit, TaskStore: store, Routes: []Route{supportRoute("r_support")}})
+	record, err := gw.tasks.create("r_support", "caller-1", "req-1", A2AMessage{MessageID: "msg-1", ContextID: "ctx-1", Role: RoleUser, Parts: []A2APart{{Text: "hello", MediaType: "text/plain"}}}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.fail = true
+	if _, ok := gw.tasks.updateWorking(record.ID); !ok {
+		t.Fatal("update refused")
+	}
+	audit.mu.Lock()
+	defer audit.mu.Unlock()
+	for _, event := range audit.events {
+		if event.Stage == "task_persist" && event.Code == "persist_failed" && event.TaskID == record.ID {
+			return
+		}
+	}
+	t.Fatalf("persist failure not reported: %#v", audit.events)
+}
+
+type failingTaskStore struct {
+	mu   sync.Mutex
+	fail bool
+}
+
+func (s *failingTaskStore) LoadTasks() ([]TaskRecord, error) { return nil, nil }
+
+func (s *failingTaskStore) SaveTask(TaskRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("disk full")
+	}
+	return nil
+}
+
+func (s *failingTaskStore) DeleteTask(string) error              { return nil }
+func (s *failingTaskStore) LoadThreads() ([]BridgeThread, error) { return nil, nil }
+func (s *failingTaskStore) SaveThread(BridgeThread) error        { return nil }
+func (s *failingTaskStore) DeleteThread(string) error            { return nil }
+
+func TestFileTaskStorePrunesExpiredTasksAndThreadsOnLoad(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestFileTaskStore(t, dir)
+	past := time.Now().Add(-time.Minute)
+	if err := store.SaveTask(TaskRecord{Task: Task{ID: "expired-task"}, RouteID: "r_support", ExpiresAt: past}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveThread(BridgeThread{TaskID: "expired-task", ConversationID: "conv-1", ReplyDeadline: past}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewMailBridge(MailBridgeConfig{Client: &fakeMailTransport{}, Store: store}); err != nil {
+		t.Fatal(err)
+	}
+	newTestGateway(t, Config{Host: "team.aweb.ai", TaskStore: store, Routes: []Route{supportRoute("r_support")}})
+	for _, sub := range []string{"tasks", "threads"} {
+		if _, err := os.Stat(filepath.Join(dir, sub, "expired-task.json")); !os.IsNotExist(err) {
+			t.Fatalf("%s entry not pruned: %v", sub, err)
+		}
+	}
+}
+
+func TestFileTaskStoreRejectsPathLikeIDs(t *testing.T) {
+	store := newTestFileTaskStore(t, t.TempDir())
+	for _, id := range []string{"", "../escape", "a/b", "x.json"} {
+		if err := store.SaveTask(TaskRecord{Task: Task{ID: id}}); err == nil {
+			t.Fatalf("SaveTask(%q) succeeded", id)
+		}
+	}
+}
+
+func newTestFileTaskStore(t *testing.T, dir string) *FileTaskStore {
+	t.Helper()
+	store, err := NewFileTaskStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
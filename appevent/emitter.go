@@ -0,0 +1,291 @@
+// Package appevent signs and delivers app events to aweb so agents that
+// subscribe to them wake up. The credential format is pinned by
+// internal/conformance/vectors/app-emit-credential-v1.json.
+package appevent
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+// EventsPath is the emit endpoint relative to the aweb base URL. A base URL
+// with a root path, such as https://app.aweb.ai/api, signs and posts to
+// /api/v1/events/app.
+const EventsPath = "/v1/events/app"
+
+const (
+	defaultMaxAttempts = 4
+	defaultBackoff     = 500 * time.Millisecond
+	maxResponseBytes   = 1 << 20
+)
+
+// Event is one app event. EventID makes delivery idempotent: retries reuse
+// it, so the server records the event at most once. Emit fills it in when
+// empty.
+type Event struct {
+	Type           string         `json:"type"`
+	EventID        string         `json:"event_id,omitempty"`
+	ResourceRef    string         `json:"resource_ref,omitempty"`
+	DeliveryIntent string         `json:"delivery_intent,omitempty"`
+	Payload        map[string]any `json:"payload,omitempty"`
+}
+
+// Body returns the request body bytes for event. Like the signed payload,
+// it does not HTML-escape, so the bytes match what other consumers hash.
+func Body(event Event) ([]byte, error) {
+	if strings.TrimSpace(event.Type) == "" {
+		return nil, errors.New("event type is required")
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(event); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// ManifestEmitter is one entry of a manifest's event_emitters list.
+type ManifestEmitter struct {
+	KID    string `json:"kid"`
+	DIDKey string `json:"did_key"`
+}
+
+// ManifestEmitters reads event_emitters from app manifest JSON.
+func ManifestEmitters(manifestJSON []byte) ([]ManifestEmitter, error) {
+	var manifest struct {
+		EventEmitters []ManifestEmitter `json:"event_emitters"`
+	}
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return nil, fmt.Errorf("decode manifest: %w", err)
+	}
+	return manifest.EventEmitters, nil
+}
+
+// CheckEmitterKey reports whether key is the emitter key the manifest
+// publishes under kid. The server rejects events signed by any other key,
+// so checking first turns a 401 into a clear local error.
+func CheckEmitterKey(emitters []ManifestEmitter, kid string, key ed25519.PrivateKey) error {
+	kid = strings.TrimSpace(kid)
+	didKey := awid.ComputeDIDKey(key.Public().(ed25519.PublicKey))
+	for _, emitter := range emitters {
+		if strings.TrimSpace(emitter.KID) != kid {
+			continue
+		}
+		if strings.TrimSpace(emitter.DIDKey) != didKey {
+			return fmt.Errorf("manifest emitter %s is %s, but the signing key is %s", kid, emitter.DIDKey, didKey)
+		}
+		return nil
+	}
+	return fmt.Errorf("manifest has no event emitter with kid %q", kid)
+}
+
+// Emitter posts signed app events for one app and team.
+type Emitter struct {
+	BaseURL string
+	TeamID  string
+	AppID   string
+	KeyID   string
+	Key     ed25519.PrivateKey
+
+	HTTPClient  *http.Client
+	MaxAttempts int
+	Backoff     time.Duration
+	Now         func() time.Time
+	Sleep       func(context.Context, time.Duration) error
+}
+
+// NewEmitter returns an emitter with default retry settings.
+func NewEmitter(baseURL, teamID, appID, keyID string, key ed25519.PrivateKey) (*Emitter, error) {
+	e := &Emitter{
+		BaseURL: strings.TrimSpace(baseURL),
+		TeamID:  strings.TrimSpace(teamID),
+		AppID:   strings.TrimSpace(appID),
+		KeyID:   strings.TrimSpace(keyID),
+		Key:     key,
+	}
+	if _, err := e.target(); err != nil {
+		return nil, err
+	}
+	switch {
+	case e.TeamID == "":
+		return nil, errors.New("team_id is required")
+	case e.AppID == "":
+		return nil, errors.New("app_id is required")
+	case e.KeyID == "":
+		return nil, errors.New("key_id is required")
+	case len(key) != ed25519.PrivateKeySize:
+		return nil, errors.New("an Ed25519 signing key is required")
+	}
+	return e, nil
+}
+
+// Result is the server's answer to an accepted event.
+type Result struct {
+	EventID  string
+	Status   int
+	Attempts int
+	Body     []byte
+}
+
+// StatusError is a non-2xx answer that was not retried, or the last answer
+// after retries ran out.
+type StatusError struct {
+	Status int
+	Body   string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("app event rejected: HTTP %d", e.Status)
+	}
+	return fmt.Sprintf("app event rejected: HTTP %d: %s", e.Status, e.Body)
+}
+
+// Emit signs and posts event. Transport errors, 429 and 5xx are retried
+// with exponential backoff; every attempt is signed afresh with the same
+// body and event_id.
+func (e *Emitter) Emit(ctx context.Context, event Event) (*Result, error) {
+	if strings.TrimSpace(event.EventID) == "" {
+		id, err := newEventID()
+		if err != nil {
+			return nil, err
+		}
+		event.EventID = id
+	}
+	body, err := Body(event)
+	if err != nil {
+		return nil, err
+	}
+	target, err := e.target()
+	if err != nil {
+		return nil, err
+	}
+	attempts := e.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultMaxAttempts
+	}
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			if err := e.sleep(ctx, e.backoff()<<(attempt-2)); err != nil {
+				return nil, err
+			}
+		}
+		status, respBody, err := e.post(ctx, target, body)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+		if status >= 200 && status < 300 {
+			return &Result{EventID: event.EventID, Status: status, Attempts: attempt, Body: respBody}, nil
+		}
+		lastErr = &StatusError{Status: status, Body: strings.TrimSpace(string(respBody))}
+		if status != http.StatusTooManyRequests && status < 500 {
+			return nil, lastErr
+		}
+	}
+	return nil, fmt.Errorf("emit %s after %d attempts: %w", event.EventID, attempts, lastErr)
+}
+
+func (e *Emitter) post(ctx context.Context, target *url.URL, body []byte) (int, []byte, error) {
+	credential, err := awid.SignAppEmitCredential(e.Key, http.MethodPost, target, e.TeamID, e.AppID, e.KeyID, body, e.now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header = credential.Headers.Clone()
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client().Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, respBody, nil
+}
+
+// target joins the base URL's path with EventsPath. The verifier rebuilds
+// the raw request target including any mount prefix, so the prefix is
+// signed too.
+func (e *Emitter) target() (*url.URL, error) {
+	base, err := url.Parse(strings.TrimSpace(e.BaseURL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if (base.Scheme != "https" && base.Scheme != "http") || base.Host == "" {
+		return nil, fmt.Errorf("base URL must be an absolute http(s) URL: %q", e.BaseURL)
+	}
+	if base.RawQuery != "" || base.Fragment != "" {
+		return nil, fmt.Errorf("base URL must not carry a query or fragment: %q", e.BaseURL)
+	}
+	target := *base
+	target.Path = strings.TrimRight(base.Path, "/") + EventsPath
+	target.RawPath = ""
+	return &target, nil
+}
+
+func (e *Emitter) client() *http.Client {
+	if e.HTTPClient != nil {
+		return e.HTTPClient
+	}
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+func (e *Emitter) backoff() time.Duration {
+	if e.Backoff > 0 {
+		return e.Backoff
+	}
+	return defaultBackoff
+}
+
+func (e *Emitter) now() time.Time {
+	if e.Now != nil {
+		return e.Now()
+	}
+	return time.Now()
+}
+
+func (e *Emitter) sleep(ctx context.Context, d time.Duration) error {
+	if e.Sleep != nil {
+		return e.Sleep(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func newEventID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate event id: %w", err)
+	}
+	return "evt_" + hex.EncodeToString(b[:]), nil
+}
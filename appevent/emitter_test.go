@@ -0,0 +1,259 @@
+package appevent
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+type emitVector struct {
+	Cases []struct {
+		SeedHex   string         `json:"seed_hex"`
+		DIDKey    string         `json:"did_key"`
+		Body      string         `json:"body"`
+		Payload   map[string]any `json:"payload"`
+		Canonical string         `json:"canonical_payload"`
+		Signature string         `json:"signature_b64"`
+	} `json:"cases"`
+}
+
+func loadEmitVector(t *testing.T) emitVector {
+	t.Helper()
+	data, err := os.ReadFile("../internal/conformance/vectors/app-emit-credential-v1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vector emitVector
+	if err := json.Unmarshal(data, &vector); err != nil {
+		t.Fatal(err)
+	}
+	if len(vector.Cases) == 0 {
+		t.Fatal("vector has no cases")
+	}
+	return vector
+}
+
+func vectorKey(t *testing.T, seedHex string) ed25519.PrivateKey {
+	t.Helper()
+	seed, err := hex.DecodeString(seedHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+func TestBodyMatchesConformanceVector(t *testing.T) {
+	t.Parallel()
+	vc := loadEmitVector(t).Cases[0]
+
+	body, err := Body(Event{
+		Type:           "folio/doc.changed",
+		ResourceRef:    "pitch",
+		DeliveryIntent: "ambient",
+		Payload:        map[string]any{"title": "Café <draft>", "version": "7"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != vc.Body {
+		t.Fatalf("body=%s\nwant %s", body, vc.Body)
+	}
+	if _, err := Body(Event{}); err == nil {
+		t.Fatal("expected an error for an event without a type")
+	}
+}
+
+func TestEmitSignsConformanceVector(t *testing.T) {
+	t.Parallel()
+	vc := loadEmitVector(t).Cases[0]
+	key := vectorKey(t, vc.SeedHex)
+	ts, _ := time.Parse(time.RFC3339, vc.Payload["timestamp"].(string))
+
+	var gotAuth, gotSigned string
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.String() != "https://core.aweb.ai/v1/events/app" {
+			t.Errorf("url=%s", r.URL)
+		}
+		gotAuth = r.Header.Get("Authorization")
+		gotSigned = r.Header.Get("X-AWEB-Signed-Payload")
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(`{}`)), Header: http.Header{}}, nil
+	})
+	emitter, err := NewEmitter("https://core.aweb.ai", "default:atext.aweb.ai", "folio", "emit-2026-06", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	emitter.HTTPClient = &http.Client{Transport: transport}
+	emitter.Now = func() time.Time { return ts }
+
+	// The vector body has no event_id, so sign it through post directly.
+	status, _, err := emitter.post(context.Background(), mustTarget(t, emitter), []byte(vc.Body))
+	if err != nil || status != http.StatusAccepted {
+		t.Fatalf("status=%d err=%v", status, err)
+	}
+	if want := "AWEB-App DIDKey " + vc.DIDKey + " " + vc.Signature; gotAuth != want {
+		t.Fatalf("authorization=%q\nwant %q", gotAuth, want)
+	}
+	signed, err := base64.RawURLEncoding.DecodeString(gotSigned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(signed) != vc.Canonical {
+		t.Fatalf("signed payload=%s\nwant %s", signed, vc.Canonical)
+	}
+}
+
+func TestEmitSignsRootPathTarget(t *testing.T) {
+	t.Parallel()
+	_, key, _ := ed25519.GenerateKey(nil)
+
+	var gotPath string
+	var gotPayload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		signed, _ := base64.RawURLEncoding.DecodeString(r.Header.Get("X-AWEB-Signed-Payload"))
+		_ = json.Unmarshal(signed, &gotPayload)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	emitter, err := NewEmitter(server.URL+"/api/", "team", "folio", "emit-1", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := emitter.Emit(context.Background(), Event{Type: "folio/doc.changed"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/api/v1/events/app" {
+		t.Fatalf("path=%q", gotPath)
+	}
+	if gotPayload["path"] != "/api/v1/events/app" {
+		t.Fatalf("signed path=%v", gotPayload["path"])
+	}
+}
+
+func TestEmitRetriesWithSameEventID(t *testing.T) {
+	t.Parallel()
+	_, key, _ := ed25519.GenerateKey(nil)
+
+	var mu sync.Mutex
+	var eventIDs []string
+	statuses := []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusAccepted}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			EventID string `json:"event_id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		eventIDs = append(eventIDs, body.EventID)
+		status := statuses[len(eventIDs)-1]
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	emitter, err := NewEmitter(server.URL, "team", "folio", "emit-1", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sleeps []time.Duration
+	emitter.Backoff = 10 * time.Millisecond
+	emitter.Sleep = func(_ context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	result, err := emitter.Emit(context.Background(), Event{Type: "ci/build.failed", DeliveryIntent: "wake"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Attempts != 3 || result.Status != http.StatusAccepted {
+		t.Fatalf("result=%+v", result)
+	}
+	if len(eventIDs) != 3 || eventIDs[0] == "" || eventIDs[0] != eventIDs[1] || eventIDs[1] != eventIDs[2] || eventIDs[0] != result.EventID {
+		t.Fatalf("event ids=%v result=%s", eventIDs, result.EventID)
+	}
+	if len(sleeps) != 2 || sleeps[0] != 10*time.Millisecond || sleeps[1] != 20*time.Millisecond {
+		t.Fatalf("sleeps=%v", sleeps)
+	}
+}
+
+func TestEmitDoesNotRetryClientErrors(t *testing.T) {
+	t.Parallel()
+	_, key, _ := ed25519.GenerateKey(nil)
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "unknown kid", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	emitter, err := NewEmitter(server.URL, "team", "folio", "emit-1", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = emitter.Emit(context.Background(), Event{Type: "ci/build.failed", EventID: "evt-1"})
+	statusErr, ok := err.(*StatusError)
+	if !ok || statusErr.Status != http.StatusUnauthorized || !strings.Contains(statusErr.Body, "unknown kid") {
+		t.Fatalf("err=%v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("calls=%d", calls)
+	}
+}
+
+func TestCheckEmitterKey(t *testing.T) {
+	t.Parallel()
+	_, key, _ := ed25519.GenerateKey(nil)
+	_, other, _ := ed25519.GenerateKey(nil)
+	didKey := awid.ComputeDIDKey(key.Public().(ed25519.PublicKey))
+
+	emitters, err := ManifestEmitters([]byte(`{"app_id":"folio","event_emitters":[{"kid":"emit-1","did_key":"` + didKey + `"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckEmitterKey(emitters, "emit-1", key); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckEmitterKey(emitters, "emit-1", other); err == nil || !strings.Contains(err.Error(), "signing key is") {
+		t.Fatalf("mismatch err=%v", err)
+	}
+	if err := CheckEmitterKey(emitters, "emit-2", key); err == nil || !strings.Contains(err.Error(), "no event emitter") {
+		t.Fatalf("missing kid err=%v", err)
+	}
+}
+
+func TestNewEmitterRejectsBadBaseURL(t *testing.T) {
+	t.Parallel()
+	_, key, _ := ed25519.GenerateKey(nil)
+	for _, base := range []string{"", "core.aweb.ai", "https://core.aweb.ai?x=1"} {
+		if _, err := NewEmitter(base, "team", "folio", "emit-1", key); err == nil {
+			t.Fatalf("expected error for %q", base)
+		}
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func mustTarget(t *testing.T, e *Emitter) *url.URL {
+	t.Helper()
+	target, err := e.target()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return target
+}
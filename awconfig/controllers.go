@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/awebai/aw/awid"
+
 	"gopkg.in/yaml.v3"
 )
 
@@ -94,11 +96,15 @@ func LoadControllerKey(domain string) (ed25519.PrivateKey, error) {
 		if err != nil {
 			return nil, err
 		}
+		path = legacyPath
 	}
 	block, _ := pem.Decode(data)
 	if block == nil {
 		return nil, errors.New("no PEM block in controller key")
 	}
+	if block.Type == awid.EncryptedPrivateKeyPEMType {
+		return awid.UnlockPrivateKeyBlock(block, path)
+	}
 	if block.Type != "ED25519 PRIVATE KEY" {
 		return nil, errors.New("unexpected controller key PEM type")
 	}
@@ -33,6 +33,10 @@ type GrantHome struct {
 	ExpiresAt string       `yaml:"expires_at"`
 	AwebURL   string       `yaml:"aweb_url"`
 	MintedAt  string       `yaml:"minted_at,omitempty"`
+	Label     string       `yaml:"label,omitempty"`
+	// ParentHome is the identity home that minted the grant. When set, aw
+	// renews the grant from it before the grant expires.
+	ParentHome string `yaml:"parent_home,omitempty"`
 }
 
 func GrantHomeStatePath(root string) string {
@@ -47,6 +47,11 @@ func DefaultRunConfigPath() (string, error) {
 	return PathInUserState("run.json")
 }
 
+// DefaultProvidersDir holds declarative aw run provider definitions.
+func DefaultProvidersDir() (string, error) {
+	return PathInUserState("providers")
+}
+
 func DefaultLogsDir() (string, error) {
 	return PathInUserState("logs")
 }
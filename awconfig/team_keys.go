@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/awebai/aw/awid"
 )
 
 func DefaultTeamKeysDir() (string, error) {
@@ -92,11 +94,15 @@ func LoadTeamKey(domain, name string) (ed25519.PrivateKey, error) {
 		if err != nil {
 			return nil, err
 		}
+		path = legacyPath
 	}
 	block, _ := pem.Decode(data)
 	if block == nil {
 		return nil, errors.New("no PEM block in team key")
 	}
+	if block.Type == awid.EncryptedPrivateKeyPEMType {
+		return awid.UnlockPrivateKeyBlock(block, path)
+	}
 	if block.Type != "ED25519 PRIVATE KEY" {
 		return nil, errors.New("unexpected team key PEM type")
 	}
@@ -0,0 +1,121 @@
+package awebtest
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+const (
+	maxRequestBody     = 1 << 20
+	maxTimestampSkew   = 5 * time.Minute
+	authorizationProto = "DIDKey"
+)
+
+type authedHandler func(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte)
+
+// authed wraps a handler with team-certificate authentication. The request
+// must carry Authorization: DIDKey <did:key> <sig>, X-AWEB-Timestamp and
+// X-AWID-Team-Certificate; the signature covers the canonical JSON of the
+// body hash, team ID and timestamp.
+func (s *Server) authed(next authedHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBody+1))
+		if err != nil {
+			writeDetail(w, http.StatusBadRequest, "could not read request body")
+			return
+		}
+		if len(body) > maxRequestBody {
+			writeDetail(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		caller, err := s.authenticate(r, body)
+		if err != nil {
+			writeDetail(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		next(w, r, caller, body)
+	}
+}
+
+func (s *Server) authenticate(r *http.Request, body []byte) (*Agent, error) {
+	fields := strings.Fields(r.Header.Get("Authorization"))
+	if len(fields) != 3 || fields[0] != authorizationProto {
+		return nil, errors.New("missing DIDKey authorization")
+	}
+	did, sigB64 := fields[1], fields[2]
+	sig, err := base64.RawStdEncoding.DecodeString(sigB64)
+	if err != nil {
+		return nil, errors.New("malformed request signature")
+	}
+	pub, err := awid.ExtractPublicKey(did)
+	if err != nil {
+		return nil, errors.New("malformed did:key")
+	}
+
+	timestamp := strings.TrimSpace(r.Header.Get("X-AWEB-Timestamp"))
+	signedAt, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return nil, errors.New("missing or malformed X-AWEB-Timestamp")
+	}
+	if skew := time.Since(signedAt); skew > maxTimestampSkew || skew < -maxTimestampSkew {
+		return nil, errors.New("request timestamp outside allowed window")
+	}
+
+	certHeader := strings.TrimSpace(r.Header.Get("X-AWID-Team-Certificate"))
+	if certHeader == "" {
+		return nil, errors.New("missing X-AWID-Team-Certificate")
+	}
+	cert, err := awid.DecodeTeamCertificateHeader(certHeader)
+	if err != nil {
+		return nil, err
+	}
+	if cert.MemberDIDKey != did {
+		return nil, errors.New("certificate member_did_key does not match request signer")
+	}
+
+	s.mu.Lock()
+	team := s.teams[cert.Team]
+	agent := s.agentsByDID[did]
+	s.mu.Unlock()
+	if team == nil {
+		return nil, fmt.Errorf("unknown team %s", cert.Team)
+	}
+	if err := awid.VerifyTeamCertificate(cert, team.Key.Public().(ed25519.PublicKey)); err != nil {
+		return nil, fmt.Errorf("invalid team certificate: %v", err)
+	}
+	if agent == nil || agent.team != team || agent.Certificate.CertificateID != cert.CertificateID {
+		return nil, errors.New("team certificate is not registered or has been revoked")
+	}
+
+	payload, err := certAuthSignPayload(cert.Team, timestamp, body)
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(pub, payload, sig) {
+		return nil, errors.New("invalid request signature")
+	}
+	return agent, nil
+}
+
+func certAuthSignPayload(teamID, timestamp string, body []byte) ([]byte, error) {
+	h := sha256.Sum256(body)
+	payload, err := awid.CanonicalJSONValue(map[string]string{
+		"body_sha256": hex.EncodeToString(h[:]),
+		"team_id":     teamID,
+		"timestamp":   timestamp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(payload), nil
+}
@@ -0,0 +1,542 @@
+package awebtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+type chatSession struct {
+	id           string
+	team         *Team
+	participants []*Agent
+	messages     []*chatMessage
+	read         map[string]map[string]bool // agent ID -> message IDs
+	left         map[string]bool            // agent ID -> sent a leaving message
+	waitingUntil map[string]time.Time       // agent ID -> sender wait deadline
+	subs         map[*subscriber]struct{}
+	createdAt    time.Time
+	lastActivity time.Time
+}
+
+type chatMessage struct {
+	awid.ChatMessage
+	from      *Agent
+	createdAt time.Time
+}
+
+type chatSendInput struct {
+	messageID     string
+	body          string
+	leaving       bool
+	hangOn        bool
+	replyTo       string
+	waitSeconds   int
+	fromDID       string
+	signature     string
+	timestamp     string
+	signedPayload string
+}
+
+func (s *Server) handleChatCreateSession(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req awid.ChatCreateSessionRequest
+	if err := decodeBody(body, &req); err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "invalid request body")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	targets, detail := s.resolveTargetsLocked(caller, req.ToAliases, req.ToDIDs, req.ToAddresses)
+	if detail != "" {
+		writeDetail(w, http.StatusNotFound, detail)
+		return
+	}
+	if len(targets) == 0 {
+		writeDetail(w, http.StatusUnprocessableEntity, "at least one recipient is required")
+		return
+	}
+	participants := append([]*Agent{caller}, targets...)
+	session := s.sessions[strings.TrimSpace(req.SessionID)]
+	if session != nil && !session.hasParticipant(caller) {
+		writeDetail(w, http.StatusConflict, "session_id already in use")
+		return
+	}
+	if session == nil {
+		session = s.sessionForParticipantsLocked(participants)
+	}
+	if session == nil {
+		id := strings.TrimSpace(req.SessionID)
+		if id == "" {
+			id = newID()
+		}
+		now := s.now().UTC()
+		session = &chatSession{
+			id:           id,
+			team:         caller.team,
+			participants: participants,
+			read:         map[string]map[string]bool{},
+			left:         map[string]bool{},
+			waitingUntil: map[string]time.Time{},
+			subs:         map[*subscriber]struct{}{},
+			createdAt:    now,
+			lastActivity: now,
+		}
+		s.sessions[id] = session
+		s.sessionOrder = append(s.sessionOrder, id)
+	}
+	waitSeconds := 0
+	if req.WaitSeconds != nil {
+		waitSeconds = *req.WaitSeconds
+	}
+	msg := s.appendChatMessageLocked(session, caller, chatSendInput{
+		messageID:     req.MessageID,
+		body:          req.Message,
+		leaving:       req.Leaving,
+		replyTo:       req.ReplyTo,
+		waitSeconds:   waitSeconds,
+		fromDID:       req.FromDID,
+		signature:     req.Signature,
+		timestamp:     req.Timestamp,
+		signedPayload: req.SignedPayload,
+	})
+
+	resp := awid.ChatCreateSessionResponse{
+		SessionID:        session.id,
+		MessageID:        msg.MessageID,
+		SSEURL:           "/v1/chat/sessions/" + session.id + "/stream",
+		TargetsConnected: []string{},
+		TargetsLeft:      []string{},
+	}
+	for _, agent := range session.participants {
+		resp.Participants = append(resp.Participants, awid.ChatParticipant{AgentID: agent.AgentID, Alias: agent.Alias, DID: agent.DID})
+		if agent == caller {
+			continue
+		}
+		if s.connectedLocked(agent) || session.streaming(agent) {
+			resp.TargetsConnected = append(resp.TargetsConnected, agent.Alias)
+		}
+		if session.left[agent.AgentID] {
+			resp.TargetsLeft = append(resp.TargetsLeft, agent.Alias)
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleChatSendMessage(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req awid.ChatSendMessageRequest
+	if err := decodeBody(body, &req); err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "invalid request body")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.visibleSessionLocked(caller, r.PathValue("session"))
+	if session == nil {
+		writeDetail(w, http.StatusNotFound, "session not found")
+		return
+	}
+	msg := s.appendChatMessageLocked(session, caller, chatSendInput{
+		messageID:     req.MessageID,
+		body:          req.Body,
+		leaving:       req.Leaving,
+		hangOn:        req.ExtendWait,
+		replyTo:       req.ReplyTo,
+		fromDID:       req.FromDID,
+		signature:     req.Signature,
+		timestamp:     req.Timestamp,
+		signedPayload: req.SignedPayload,
+	})
+	resp := awid.ChatSendMessageResponse{MessageID: msg.MessageID}
+	for _, agent := range session.participants {
+		if agent != caller && (s.connectedLocked(agent) || session.streaming(agent)) {
+			resp.Delivered = true
+		}
+	}
+	if req.ExtendWait {
+		resp.ExtendsWaitSeconds = 300
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleChatListSessions(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now().UTC()
+	out := awid.ChatListSessionsResponse{Sessions: []awid.ChatSessionItem{}}
+	for _, id := range s.sessionOrder {
+		session := s.sessions[id]
+		if !session.hasParticipant(caller) {
+			continue
+		}
+		out.Sessions = append(out.Sessions, awid.ChatSessionItem{
+			SessionID:     session.id,
+			TeamID:        session.team.ID,
+			Participants:  session.aliases(),
+			CreatedAt:     formatTime(session.createdAt),
+			LastActivity:  formatTime(session.lastActivity),
+			SenderWaiting: session.otherWaiting(caller, now),
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleChatPending(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now().UTC()
+	out := awid.ChatPendingResponse{Pending: []awid.ChatPendingItem{}}
+	for _, id := range s.sessionOrder {
+		session := s.sessions[id]
+		if !session.hasParticipant(caller) {
+			continue
+		}
+		unread := session.unreadFor(caller)
+		if len(unread) == 0 {
+			continue
+		}
+		last := unread[len(unread)-1]
+		item := awid.ChatPendingItem{
+			SessionID:     session.id,
+			TeamID:        session.team.ID,
+			Participants:  session.aliases(),
+			LastMessage:   last.Body,
+			LastFrom:      last.from.Alias,
+			LastFromDID:   last.from.DID,
+			UnreadCount:   len(unread),
+			LastActivity:  formatTime(session.lastActivity),
+			SenderWaiting: session.otherWaiting(caller, now),
+		}
+		if until, ok := session.waitingUntil[last.from.AgentID]; ok && until.After(now) {
+			remaining := int(until.Sub(now).Seconds())
+			item.TimeRemainingSeconds = &remaining
+		}
+		out.Pending = append(out.Pending, item)
+		out.MessagesWaiting += len(unread)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleChatHistory(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.visibleSessionLocked(caller, r.PathValue("session"))
+	if session == nil {
+		writeDetail(w, http.StatusNotFound, "session not found")
+		return
+	}
+	messages := session.messages
+	if q.Get("unread_only") == "true" {
+		messages = session.unreadFor(caller)
+	}
+	out := awid.ChatHistoryResponse{Messages: []awid.ChatMessage{}}
+	for _, msg := range messages {
+		if id := strings.TrimSpace(q.Get("message_id")); id != "" && msg.MessageID != id {
+			continue
+		}
+		out.Messages = append(out.Messages, msg.ChatMessage)
+	}
+	if limit > 0 && len(out.Messages) > limit {
+		out.Messages = out.Messages[len(out.Messages)-limit:]
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleChatMarkRead(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req struct {
+		MessageIDs    []string `json:"message_ids"`
+		UpToMessageID string   `json:"up_to_message_id"`
+	}
+	if err := decodeBody(body, &req); err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "invalid request body")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.visibleSessionLocked(caller, r.PathValue("session"))
+	if session == nil {
+		writeDetail(w, http.StatusNotFound, "session not found")
+		return
+	}
+	marked := 0
+	readSet := session.read[caller.AgentID]
+	if readSet == nil {
+		readSet = map[string]bool{}
+		session.read[caller.AgentID] = readSet
+	}
+	for _, msg := range session.messages {
+		wanted := slices.Contains(req.MessageIDs, msg.MessageID)
+		if req.UpToMessageID != "" {
+			wanted = true
+		}
+		if wanted && msg.from != caller && !readSet[msg.MessageID] {
+			readSet[msg.MessageID] = true
+			marked++
+		}
+		if req.UpToMessageID != "" && msg.MessageID == req.UpToMessageID {
+			break
+		}
+	}
+	if marked > 0 {
+		data, _ := json.Marshal(map[string]any{
+			"type":         "read_receipt",
+			"session_id":   session.id,
+			"reader_alias": caller.Alias,
+			"timestamp":    s.timestamp(),
+		})
+		session.broadcast(sseFrame{Event: "read_receipt", Data: data}, caller)
+	}
+	writeJSON(w, http.StatusOK, awid.ChatMarkReadResponse{Success: true, MessagesMarked: marked})
+}
+
+func (s *Server) handleChatStream(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	q := r.URL.Query()
+	deadline, err := parseDeadline(q.Get("deadline"))
+	if err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	var after *time.Time
+	if raw := strings.TrimSpace(q.Get("after")); raw != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			writeDetail(w, http.StatusUnprocessableEntity, "after must be an RFC3339 timestamp")
+			return
+		}
+		after = &parsed
+	}
+	s.mu.Lock()
+	session := s.visibleSessionLocked(caller, r.PathValue("session"))
+	if session == nil {
+		s.mu.Unlock()
+		writeDetail(w, http.StatusNotFound, "session not found")
+		return
+	}
+	var replay []sseFrame
+	if after != nil {
+		for _, msg := range session.messages {
+			if msg.createdAt.After(*after) {
+				replay = append(replay, chatMessageFrame(session, msg))
+			}
+		}
+	}
+	sub := newSubscriber(caller.AgentID)
+	session.subs[sub] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(session.subs, sub)
+		s.mu.Unlock()
+	}()
+	serveSSE(w, r, deadline, sub, replay...)
+}
+
+func (s *Server) appendChatMessageLocked(session *chatSession, from *Agent, in chatSendInput) *chatMessage {
+	now := s.now().UTC()
+	messageID := strings.TrimSpace(in.messageID)
+	if messageID == "" {
+		messageID = newID()
+	}
+	timestamp := strings.TrimSpace(in.timestamp)
+	if timestamp == "" {
+		timestamp = formatTime(now)
+	}
+	msg := &chatMessage{
+		ChatMessage: awid.ChatMessage{
+			MessageID:        messageID,
+			ConversationID:   session.id,
+			FromAgent:        from.Alias,
+			Body:             in.body,
+			Timestamp:        timestamp,
+			SenderLeaving:    in.leaving,
+			ReplyToMessageID: in.replyTo,
+			FromDID:          in.fromDID,
+			Signature:        in.signature,
+			SignedPayload:    in.signedPayload,
+		},
+		from:      from,
+		createdAt: now,
+	}
+	session.messages = append(session.messages, msg)
+	session.lastActivity = now
+	session.left[from.AgentID] = in.leaving
+	if in.waitSeconds > 0 && !in.leaving {
+		session.waitingUntil[from.AgentID] = now.Add(time.Duration(in.waitSeconds) * time.Second)
+	} else if in.leaving {
+		delete(session.waitingUntil, from.AgentID)
+	}
+	session.broadcast(chatMessageFrame(session, msg), nil)
+
+	senderWaiting := session.waitingUntil[from.AgentID].After(now)
+	wakeMode := "prompt"
+	if senderWaiting {
+		wakeMode = "interrupt"
+	}
+	for _, agent := range session.participants {
+		if agent == from {
+			continue
+		}
+		s.emitLocked(agent, awid.AgentEventActionableChat, map[string]any{
+			"message_id":      msg.MessageID,
+			"conversation_id": session.id,
+			"session_id":      session.id,
+			"from_alias":      from.Alias,
+			"from_did":        from.DID,
+			"wake_mode":       wakeMode,
+			"unread_count":    len(session.unreadFor(agent)),
+			"sender_waiting":  senderWaiting,
+		})
+	}
+	return msg
+}
+
+func chatMessageFrame(session *chatSession, msg *chatMessage) sseFrame {
+	payload := map[string]any{
+		"type":           "message",
+		"session_id":     session.id,
+		"message_id":     msg.MessageID,
+		"from_agent":     msg.FromAgent,
+		"body":           msg.Body,
+		"timestamp":      msg.Timestamp,
+		"sender_leaving": msg.SenderLeaving,
+	}
+	if msg.ReplyToMessageID != "" {
+		payload["reply_to_message_id"] = msg.ReplyToMessageID
+	}
+	if msg.FromDID != "" {
+		payload["from_did"] = msg.FromDID
+		payload["signature"] = msg.Signature
+		payload["signed_payload"] = msg.SignedPayload
+	}
+	data, _ := json.Marshal(payload)
+	return sseFrame{Event: "message", Data: data}
+}
+
+// resolveTargetsLocked maps aliases, did:keys and namespace/alias addresses
+// to registered agents. Aliases and addresses resolve within the caller's
+// team; an unknown target yields a detail message.
+func (s *Server) resolveTargetsLocked(caller *Agent, aliases, dids, addresses []string) ([]*Agent, string) {
+	var out []*Agent
+	add := func(agent *Agent) {
+		if agent != caller && !slices.Contains(out, agent) {
+			out = append(out, agent)
+		}
+	}
+	for _, alias := range aliases {
+		agent := caller.team.agents[addressAlias(alias)]
+		if agent == nil {
+			return nil, "agent not found: " + alias
+		}
+		add(agent)
+	}
+	for _, did := range dids {
+		agent := s.agentsByDID[strings.TrimSpace(did)]
+		if agent == nil {
+			return nil, "agent not found: " + did
+		}
+		add(agent)
+	}
+	for _, address := range addresses {
+		agent := caller.team.agents[addressAlias(address)]
+		if agent == nil {
+			return nil, "agent not found: " + address
+		}
+		add(agent)
+	}
+	return out, ""
+}
+
+func (s *Server) sessionForParticipantsLocked(participants []*Agent) *chatSession {
+	for _, id := range s.sessionOrder {
+		session := s.sessions[id]
+		if len(session.participants) != len(participants) {
+			continue
+		}
+		match := true
+		for _, agent := range participants {
+			if !session.hasParticipant(agent) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return session
+		}
+	}
+	return nil
+}
+
+func (s *Server) visibleSessionLocked(caller *Agent, sessionID string) *chatSession {
+	session := s.sessions[strings.TrimSpace(sessionID)]
+	if session == nil || !session.hasParticipant(caller) {
+		return nil
+	}
+	return session
+}
+
+func (cs *chatSession) hasParticipant(agent *Agent) bool {
+	return slices.Contains(cs.participants, agent)
+}
+
+func (cs *chatSession) aliases() []string {
+	out := make([]string, 0, len(cs.participants))
+	for _, agent := range cs.participants {
+		out = append(out, agent.Alias)
+	}
+	return out
+}
+
+func (cs *chatSession) streaming(agent *Agent) bool {
+	for sub := range cs.subs {
+		if sub.agentID == agent.AgentID {
+			return true
+		}
+	}
+	return false
+}
+
+func (cs *chatSession) unreadFor(agent *Agent) []*chatMessage {
+	var out []*chatMessage
+	for _, msg := range cs.messages {
+		if msg.from != agent && !cs.read[agent.AgentID][msg.MessageID] {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+// otherWaiting reports whether any participant other than agent is still
+// within its sender wait window.
+func (cs *chatSession) otherWaiting(agent *Agent, now time.Time) bool {
+	for agentID, until := range cs.waitingUntil {
+		if (agent == nil || agentID != agent.AgentID) && until.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cs *chatSession) broadcast(frame sseFrame, except *Agent) {
+	for sub := range cs.subs {
+		if except != nil && sub.agentID == except.AgentID {
+			continue
+		}
+		sub.send(frame)
+	}
+}
+
+// addressAlias returns the alias part of a namespace/alias address, or the
+// value itself when it is already a bare alias.
+func addressAlias(value string) string {
+	value = strings.TrimSpace(value)
+	if i := strings.LastIndex(value, "/"); i >= 0 {
+		return value[i+1:]
+	}
+	return value
+}
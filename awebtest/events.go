@@ -0,0 +1,186 @@
+package awebtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+const subscriberBuffer = 256
+
+type sseFrame struct {
+	Event string
+	Data  []byte
+}
+
+// subscriber is one open SSE connection. Frames are dropped rather than
+// blocking the server when a slow test client stops reading.
+type subscriber struct {
+	agentID string
+	frames  chan sseFrame
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newSubscriber(agentID string) *subscriber {
+	return &subscriber{agentID: agentID, frames: make(chan sseFrame, subscriberBuffer), done: make(chan struct{})}
+}
+
+func (sub *subscriber) send(frame sseFrame) {
+	select {
+	case sub.frames <- frame:
+	default:
+	}
+}
+
+func (sub *subscriber) close() {
+	sub.once.Do(func() { close(sub.done) })
+}
+
+// Publish delivers a raw event to every open /v1/events/stream connection of
+// the agent, for event types the server does not generate itself (for
+// example app_event). payload is marshaled as the SSE data.
+func (s *Server) Publish(agent *Agent, eventType awid.AgentEventType, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.publishLocked(agent.AgentID, sseFrame{Event: string(eventType), Data: data})
+	return nil
+}
+
+// emitLocked marshals payload and delivers it to the agent's event streams.
+func (s *Server) emitLocked(agent *Agent, eventType awid.AgentEventType, payload map[string]any) {
+	if agent == nil {
+		return
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	s.publishLocked(agent.AgentID, sseFrame{Event: string(eventType), Data: data})
+}
+
+func (s *Server) publishLocked(agentID string, frame sseFrame) {
+	for sub := range s.eventSubs[agentID] {
+		sub.send(frame)
+	}
+}
+
+// connectedLocked reports whether the agent has an open event stream.
+func (s *Server) connectedLocked(agent *Agent) bool {
+	return len(s.eventSubs[agent.AgentID]) > 0
+}
+
+func (s *Server) handleEventStream(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	deadline, err := parseDeadline(r.URL.Query().Get("deadline"))
+	if err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	sub := newSubscriber(caller.AgentID)
+	s.mu.Lock()
+	if s.eventSubs[caller.AgentID] == nil {
+		s.eventSubs[caller.AgentID] = map[*subscriber]struct{}{}
+	}
+	s.eventSubs[caller.AgentID][sub] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.eventSubs[caller.AgentID], sub)
+		s.mu.Unlock()
+	}()
+
+	connected, _ := json.Marshal(map[string]any{"agent_id": caller.AgentID, "team_id": caller.team.ID})
+	serveSSE(w, r, deadline, sub, sseFrame{Event: string(awid.AgentEventConnected), Data: connected})
+}
+
+func (s *Server) handleControlSignal(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req awid.SendControlSignalRequest
+	if err := decodeBody(body, &req); err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "invalid request body")
+		return
+	}
+	if !req.Signal.Valid() {
+		writeDetail(w, http.StatusUnprocessableEntity, fmt.Sprintf("invalid control signal %q", req.Signal))
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	target := caller.team.agents[strings.TrimSpace(r.PathValue("alias"))]
+	if target == nil {
+		writeDetail(w, http.StatusNotFound, "agent not found")
+		return
+	}
+	signal := ControlSignal{
+		SignalID:    newID(),
+		TeamID:      caller.team.ID,
+		TargetAlias: target.Alias,
+		FromAlias:   caller.Alias,
+		Signal:      req.Signal,
+		CreatedAt:   s.now().UTC(),
+	}
+	s.signals = append(s.signals, signal)
+	s.emitLocked(target, awid.AgentEventType("control_"+string(req.Signal)), map[string]any{"signal_id": signal.SignalID})
+	writeJSON(w, http.StatusOK, awid.SendControlSignalResponse{SignalID: signal.SignalID, Signal: signal.Signal})
+}
+
+// serveSSE writes initial frames and then streams the subscriber's frames
+// until the deadline passes, the client disconnects, or the subscriber is
+// closed by the server.
+func serveSSE(w http.ResponseWriter, r *http.Request, deadline time.Time, sub *subscriber, initial ...sseFrame) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeDetail(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	for _, frame := range initial {
+		writeSSEFrame(w, frame)
+	}
+	flusher.Flush()
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-timer.C:
+			return
+		case <-sub.done:
+			return
+		case frame := <-sub.frames:
+			writeSSEFrame(w, frame)
+			flusher.Flush()
+		}
+	}
+}
+
+func writeSSEFrame(w http.ResponseWriter, frame sseFrame) {
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", frame.Event, frame.Data)
+}
+
+func parseDeadline(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, fmt.Errorf("deadline is required")
+	}
+	deadline, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("deadline must be an RFC3339 timestamp")
+	}
+	if !deadline.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("deadline must be in the future")
+	}
+	return deadline, nil
+}
@@ -0,0 +1,224 @@
+package awebtest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+type mailMessage struct {
+	awid.InboxMessage
+	from      *Agent
+	to        *Agent
+	createdAt time.Time
+	ackedAt   *time.Time
+}
+
+func (s *Server) handleMailSend(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req awid.SendMessageRequest
+	if err := decodeBody(body, &req); err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "invalid request body")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	to, detail := s.resolveMailTargetLocked(caller, req)
+	if detail != "" {
+		writeDetail(w, http.StatusNotFound, detail)
+		return
+	}
+	now := s.now().UTC()
+	messageID := strings.TrimSpace(req.MessageID)
+	if messageID == "" {
+		messageID = newID()
+	}
+	conversationID := strings.TrimSpace(req.ConversationID)
+	if conversationID == "" {
+		conversationID = newID()
+	}
+	priority := req.Priority
+	if priority == "" {
+		priority = awid.PriorityNormal
+	}
+	createdAt := strings.TrimSpace(req.Timestamp)
+	if createdAt == "" {
+		createdAt = formatTime(now)
+	}
+	msg := &mailMessage{
+		InboxMessage: awid.InboxMessage{
+			MessageID:      messageID,
+			ConversationID: conversationID,
+			FromAgentID:    caller.AgentID,
+			FromAlias:      caller.Alias,
+			ToAlias:        to.Alias,
+			Subject:        req.Subject,
+			Body:           req.Body,
+			ContentMode:    req.ContentMode,
+			Priority:       priority,
+			CreatedAt:      createdAt,
+			FromDID:        req.FromDID,
+			ToDID:          req.ToDID,
+			Signature:      req.Signature,
+			SignedPayload:  req.SignedPayload,
+		},
+		from:      caller,
+		to:        to,
+		createdAt: now,
+	}
+	s.messages = append(s.messages, msg)
+	s.emitLocked(to, awid.AgentEventActionableMail, map[string]any{
+		"message_id":      msg.MessageID,
+		"conversation_id": msg.ConversationID,
+		"from_alias":      caller.Alias,
+		"from_did":        caller.DID,
+		"subject":         msg.Subject,
+		"content_mode":    msg.ContentMode,
+		"wake_mode":       "prompt",
+		"unread_count":    s.unreadMailCountLocked(to),
+	})
+	writeJSON(w, http.StatusOK, awid.SendMessageResponse{
+		MessageID:      msg.MessageID,
+		ConversationID: msg.ConversationID,
+		Status:         "delivered",
+		DeliveredAt:    formatTime(now),
+	})
+}
+
+func (s *Server) handleMailInbox(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	unreadOnly := q.Get("unread_only") == "true"
+	messageID := strings.TrimSpace(q.Get("message_id"))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := awid.InboxResponse{Messages: []awid.InboxMessage{}}
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		msg := s.messages[i]
+		if msg.to != caller || (unreadOnly && msg.ackedAt != nil) || (messageID != "" && msg.MessageID != messageID) {
+			continue
+		}
+		if limit > 0 && len(out.Messages) >= limit {
+			out.HasMore = true
+			break
+		}
+		out.Messages = append(out.Messages, msg.view())
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleMailConversation(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	conversationID := r.PathValue("conversation")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := awid.InboxResponse{Messages: []awid.InboxMessage{}}
+	for _, msg := range s.messages {
+		if msg.ConversationID == conversationID && (msg.to == caller || msg.from == caller) {
+			out.Messages = append(out.Messages, msg.view())
+		}
+	}
+	if limit > 0 && len(out.Messages) > limit {
+		out.Messages = out.Messages[len(out.Messages)-limit:]
+		out.HasMore = true
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleMailGet(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg := s.findMailLocked(r.PathValue("message"))
+	if msg == nil || (msg.to != caller && msg.from != caller) {
+		writeDetail(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, msg.view())
+}
+
+func (s *Server) handleMailAck(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg := s.findMailLocked(r.PathValue("message"))
+	if msg == nil || msg.to != caller {
+		writeDetail(w, http.StatusNotFound, "message not found")
+		return
+	}
+	if msg.ackedAt == nil {
+		now := s.now().UTC()
+		msg.ackedAt = &now
+	}
+	writeJSON(w, http.StatusOK, awid.AckResponse{MessageID: msg.MessageID, AcknowledgedAt: formatTime(*msg.ackedAt)})
+}
+
+// resolveMailTargetLocked picks the recipient from the first recipient
+// field set on the request, falling back to the other participant of an
+// existing conversation.
+func (s *Server) resolveMailTargetLocked(caller *Agent, req awid.SendMessageRequest) (*Agent, string) {
+	switch {
+	case strings.TrimSpace(req.ToAgentID) != "":
+		if agent := s.agentsByID[strings.TrimSpace(req.ToAgentID)]; agent != nil {
+			return agent, ""
+		}
+		return nil, "agent not found: " + req.ToAgentID
+	case strings.TrimSpace(req.ToAlias) != "":
+		if agent := caller.team.agents[addressAlias(req.ToAlias)]; agent != nil {
+			return agent, ""
+		}
+		return nil, "agent not found: " + req.ToAlias
+	case strings.TrimSpace(req.ToAddress) != "":
+		if agent := caller.team.agents[addressAlias(req.ToAddress)]; agent != nil {
+			return agent, ""
+		}
+		return nil, "agent not found: " + req.ToAddress
+	case strings.TrimSpace(req.ToDID) != "":
+		if agent := s.agentsByDID[strings.TrimSpace(req.ToDID)]; agent != nil {
+			return agent, ""
+		}
+		return nil, "agent not found: " + req.ToDID
+	}
+	if conversationID := strings.TrimSpace(req.ConversationID); conversationID != "" {
+		for i := len(s.messages) - 1; i >= 0; i-- {
+			msg := s.messages[i]
+			if msg.ConversationID != conversationID {
+				continue
+			}
+			if msg.from == caller {
+				return msg.to, ""
+			}
+			if msg.to == caller {
+				return msg.from, ""
+			}
+		}
+	}
+	return nil, "recipient is required"
+}
+
+func (s *Server) findMailLocked(messageID string) *mailMessage {
+	for _, msg := range s.messages {
+		if msg.MessageID == messageID {
+			return msg
+		}
+	}
+	return nil
+}
+
+func (s *Server) unreadMailCountLocked(agent *Agent) int {
+	count := 0
+	for _, msg := range s.messages {
+		if msg.to == agent && msg.ackedAt == nil {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *mailMessage) view() awid.InboxMessage {
+	out := m.InboxMessage
+	if m.ackedAt != nil {
+		out.ReadAt = stringPtr(formatTime(*m.ackedAt))
+	}
+	return out
+}
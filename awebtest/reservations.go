@@ -0,0 +1,171 @@
+package awebtest
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	aweb "github.com/awebai/aw"
+)
+
+const defaultReservationTTL = time.Hour
+
+type reservation struct {
+	key        string
+	holder     *Agent
+	acquiredAt time.Time
+	expiresAt  time.Time
+	metadata   map[string]any
+}
+
+func (s *Server) handleReservationAcquire(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req aweb.ReservationAcquireRequest
+	if err := decodeBody(body, &req); err != nil || strings.TrimSpace(req.ResourceKey) == "" {
+		writeDetail(w, http.StatusUnprocessableEntity, "resource_key is required")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team := caller.team
+	now := s.now().UTC()
+	if held := team.liveReservationLocked(req.ResourceKey, now); held != nil && held.holder != caller {
+		writeJSON(w, http.StatusConflict, aweb.ReservationHeldError{
+			Detail:        "reservation is already held",
+			HolderAgentID: held.holder.AgentID,
+			HolderAlias:   held.holder.Alias,
+			ExpiresAt:     formatTime(held.expiresAt),
+		})
+		return
+	}
+	res := &reservation{
+		key:        req.ResourceKey,
+		holder:     caller,
+		acquiredAt: now,
+		expiresAt:  now.Add(reservationTTL(req.TTLSeconds)),
+		metadata:   req.Metadata,
+	}
+	team.reservations[req.ResourceKey] = res
+	writeJSON(w, http.StatusOK, aweb.ReservationAcquireResponse{
+		Status:        "acquired",
+		ResourceKey:   res.key,
+		HolderAgentID: caller.AgentID,
+		HolderAlias:   caller.Alias,
+		AcquiredAt:    formatTime(res.acquiredAt),
+		ExpiresAt:     formatTime(res.expiresAt),
+	})
+}
+
+func (s *Server) handleReservationRenew(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req aweb.ReservationRenewRequest
+	if err := decodeBody(body, &req); err != nil || strings.TrimSpace(req.ResourceKey) == "" {
+		writeDetail(w, http.StatusUnprocessableEntity, "resource_key is required")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now().UTC()
+	res := caller.team.liveReservationLocked(req.ResourceKey, now)
+	if res == nil || res.holder != caller {
+		writeDetail(w, http.StatusNotFound, "reservation not held")
+		return
+	}
+	res.expiresAt = now.Add(reservationTTL(req.TTLSeconds))
+	writeJSON(w, http.StatusOK, aweb.ReservationRenewResponse{Status: "renewed", ResourceKey: res.key, ExpiresAt: formatTime(res.expiresAt)})
+}
+
+func (s *Server) handleReservationRelease(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req aweb.ReservationReleaseRequest
+	if err := decodeBody(body, &req); err != nil || strings.TrimSpace(req.ResourceKey) == "" {
+		writeDetail(w, http.StatusUnprocessableEntity, "resource_key is required")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team := caller.team
+	res := team.liveReservationLocked(req.ResourceKey, s.now().UTC())
+	if res == nil || res.holder != caller {
+		writeDetail(w, http.StatusNotFound, "reservation not held")
+		return
+	}
+	delete(team.reservations, req.ResourceKey)
+	writeJSON(w, http.StatusOK, aweb.ReservationReleaseResponse{Status: "released", ResourceKey: req.ResourceKey})
+}
+
+func (s *Server) handleReservationRevoke(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req aweb.ReservationRevokeRequest
+	if err := decodeBody(body, &req); err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "invalid request body")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team := caller.team
+	out := aweb.ReservationRevokeResponse{RevokedKeys: []string{}}
+	for _, key := range team.reservationKeysLocked() {
+		if strings.HasPrefix(key, req.Prefix) {
+			delete(team.reservations, key)
+			out.RevokedKeys = append(out.RevokedKeys, key)
+		}
+	}
+	out.RevokedCount = len(out.RevokedKeys)
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleReservationList(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	prefix := r.URL.Query().Get("prefix")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team := caller.team
+	now := s.now().UTC()
+	out := aweb.ReservationListResponse{Reservations: []aweb.ReservationView{}}
+	for _, key := range team.reservationKeysLocked() {
+		res := team.liveReservationLocked(key, now)
+		if res == nil || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		out.Reservations = append(out.Reservations, aweb.ReservationView{
+			ResourceKey:   res.key,
+			HolderAgentID: res.holder.AgentID,
+			HolderAlias:   res.holder.Alias,
+			AcquiredAt:    formatTime(res.acquiredAt),
+			ExpiresAt:     formatTime(res.expiresAt),
+			Metadata:      res.metadata,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// liveReservationLocked returns the unexpired reservation for key, dropping
+// it when its TTL has lapsed.
+func (t *Team) liveReservationLocked(key string, now time.Time) *reservation {
+	res := t.reservations[key]
+	if res == nil {
+		return nil
+	}
+	if !now.Before(res.expiresAt) {
+		delete(t.reservations, key)
+		return nil
+	}
+	return res
+}
+
+func (t *Team) reservationKeysLocked() []string {
+	keys := make([]string, 0, len(t.reservations))
+	for key := range t.reservations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func reservationTTL(seconds int) time.Duration {
+	if seconds <= 0 {
+		return defaultReservationTTL
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
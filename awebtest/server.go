@@ -0,0 +1,334 @@
+// Package awebtest runs a stateful, in-memory aweb coordination server for
+// tests. It speaks the same wire protocol as the hosted service for tasks
+// (with dependencies and claims), reservations, chat sessions with SSE, mail
+// with ack, control signals and the agent event stream, and it authenticates
+// every request with team-certificate DIDKey signatures.
+//
+// A typical test creates a server, registers a team and a few agents, and
+// drives them through ordinary clients:
+//
+//	srv := awebtest.NewServer()
+//	defer srv.Close()
+//	team, _ := srv.AddTeam("backend:example.com")
+//	alice, _ := team.AddAgent("alice")
+//	client, _ := alice.Client()
+package awebtest
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	aweb "github.com/awebai/aw"
+	"github.com/awebai/aw/awid"
+)
+
+// Server is an in-memory aweb coordination server backed by httptest.
+type Server struct {
+	// URL is the base URL clients should be configured with.
+	URL string
+
+	httpServer *httptest.Server
+	now        func() time.Time
+
+	mu            sync.Mutex
+	teams         map[string]*Team
+	agentsByDID   map[string]*Agent
+	agentsByID    map[string]*Agent
+	sessions      map[string]*chatSession
+	sessionOrder  []string
+	messages      []*mailMessage
+	eventSubs     map[string]map[*subscriber]struct{}
+	signals       []ControlSignal
+	nextSignalSeq int
+}
+
+// Team is a registered team. Its key signs the certificates of its agents.
+type Team struct {
+	ID  string
+	Key ed25519.PrivateKey
+
+	server *Server
+
+	// Guarded by server.mu.
+	agents         map[string]*Agent
+	tasks          map[string]*task
+	taskOrder      []string
+	nextTaskNumber int
+	taskPrefix     string
+	reservations   map[string]*reservation
+}
+
+// Agent is a team member with its own signing key and team certificate.
+type Agent struct {
+	AgentID     string
+	Alias       string
+	DID         string
+	SigningKey  ed25519.PrivateKey
+	Certificate *awid.TeamCertificate
+
+	team *Team
+}
+
+// ControlSignal records a control signal delivered through the server.
+type ControlSignal struct {
+	SignalID    string
+	TeamID      string
+	TargetAlias string
+	FromAlias   string
+	Signal      awid.ControlSignal
+	CreatedAt   time.Time
+}
+
+// NewServer starts a new in-memory aweb server. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		now:         time.Now,
+		teams:       map[string]*Team{},
+		agentsByDID: map[string]*Agent{},
+		agentsByID:  map[string]*Agent{},
+		sessions:    map[string]*chatSession{},
+		eventSubs:   map[string]map[*subscriber]struct{}{},
+	}
+	s.httpServer = httptest.NewServer(s.routes())
+	s.URL = s.httpServer.URL
+	return s
+}
+
+// Close shuts down the server and ends all open streams.
+func (s *Server) Close() {
+	s.httpServer.CloseClientConnections()
+	s.httpServer.Close()
+}
+
+// SetClock overrides the clock used for task, reservation and message
+// timestamps and reservation expiry. Request signature freshness always uses
+// the wall clock, because clients sign with it.
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now == nil {
+		now = time.Now
+	}
+	s.now = now
+}
+
+// AddTeam registers a team with a freshly generated team key.
+func (s *Server) AddTeam(teamID string) (*Team, error) {
+	teamID = strings.TrimSpace(teamID)
+	if teamID == "" {
+		return nil, errors.New("awebtest: team id is required")
+	}
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.teams[teamID]; exists {
+		return nil, fmt.Errorf("awebtest: team %q already exists", teamID)
+	}
+	team := &Team{
+		ID:           teamID,
+		Key:          key,
+		server:       s,
+		agents:       map[string]*Agent{},
+		tasks:        map[string]*task{},
+		taskPrefix:   "aw",
+		reservations: map[string]*reservation{},
+	}
+	s.teams[teamID] = team
+	return team, nil
+}
+
+// Team returns a registered team by ID.
+func (s *Server) Team(teamID string) (*Team, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team, ok := s.teams[strings.TrimSpace(teamID)]
+	return team, ok
+}
+
+// ControlSignals returns the control signals sent so far, oldest first.
+func (s *Server) ControlSignals() []ControlSignal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ControlSignal(nil), s.signals...)
+}
+
+// SetTaskPrefix changes the prefix used for new task refs (default "aw").
+func (t *Team) SetTaskPrefix(prefix string) {
+	t.server.mu.Lock()
+	defer t.server.mu.Unlock()
+	t.taskPrefix = strings.TrimSpace(prefix)
+}
+
+// AddAgent registers a team member with a fresh signing key and a
+// certificate signed by the team key.
+func (t *Team) AddAgent(alias string) (*Agent, error) {
+	alias = strings.TrimSpace(alias)
+	if alias == "" {
+		return nil, errors.New("awebtest: alias is required")
+	}
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, err
+	}
+	did := awid.ComputeDIDKey(pub)
+	cert, err := awid.SignTeamCertificate(t.Key, awid.TeamCertificateFields{
+		Team:          t.ID,
+		MemberDIDKey:  did,
+		Alias:         alias,
+		IdentityScope: awid.IdentityModeLocal,
+	})
+	if err != nil {
+		return nil, err
+	}
+	agentID, err := awid.GenerateUUID4()
+	if err != nil {
+		return nil, err
+	}
+	agent := &Agent{AgentID: agentID, Alias: alias, DID: did, SigningKey: priv, Certificate: cert, team: t}
+
+	s := t.server
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := t.agents[alias]; exists {
+		return nil, fmt.Errorf("awebtest: alias %q already exists in team %s", alias, t.ID)
+	}
+	t.agents[alias] = agent
+	s.agentsByDID[did] = agent
+	s.agentsByID[agentID] = agent
+	return agent, nil
+}
+
+// RemoveAgent revokes an agent's membership. Later requests signed with its
+// certificate are rejected and its open event streams are closed.
+func (t *Team) RemoveAgent(alias string) bool {
+	s := t.server
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	agent, ok := t.agents[strings.TrimSpace(alias)]
+	if !ok {
+		return false
+	}
+	delete(t.agents, agent.Alias)
+	delete(s.agentsByDID, agent.DID)
+	delete(s.agentsByID, agent.AgentID)
+	for sub := range s.eventSubs[agent.AgentID] {
+		sub.close()
+	}
+	delete(s.eventSubs, agent.AgentID)
+	return true
+}
+
+// Agent returns a registered team member by alias.
+func (t *Team) Agent(alias string) (*Agent, bool) {
+	t.server.mu.Lock()
+	defer t.server.mu.Unlock()
+	agent, ok := t.agents[strings.TrimSpace(alias)]
+	return agent, ok
+}
+
+// Team returns the team the agent belongs to.
+func (a *Agent) Team() *Team { return a.team }
+
+// Client returns an aweb client authenticated as the agent.
+func (a *Agent) Client() (*aweb.Client, error) {
+	return aweb.NewWithCertificate(a.team.server.URL, a.SigningKey, a.Certificate)
+}
+
+// AWIDClient returns a low-level awid client authenticated as the agent, as
+// used by the chat package.
+func (a *Agent) AWIDClient() (*awid.Client, error) {
+	return awid.NewWithCertificate(a.team.server.URL, a.SigningKey, a.Certificate)
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /v1/tasks", s.authed(s.handleTaskCreate))
+	mux.HandleFunc("GET /v1/tasks", s.authed(s.handleTaskList))
+	mux.HandleFunc("GET /v1/tasks/ready", s.authed(s.handleTaskListReady))
+	mux.HandleFunc("GET /v1/tasks/blocked", s.authed(s.handleTaskListBlocked))
+	mux.HandleFunc("GET /v1/tasks/active", s.authed(s.handleTaskListActive))
+	mux.HandleFunc("GET /v1/tasks/{ref}", s.authed(s.handleTaskGet))
+	mux.HandleFunc("PATCH /v1/tasks/{ref}", s.authed(s.handleTaskUpdate))
+	mux.HandleFunc("DELETE /v1/tasks/{ref}", s.authed(s.handleTaskDelete))
+	mux.HandleFunc("POST /v1/tasks/{ref}/deps", s.authed(s.handleTaskAddDep))
+	mux.HandleFunc("DELETE /v1/tasks/{ref}/deps/{dep}", s.authed(s.handleTaskRemoveDep))
+	mux.HandleFunc("POST /v1/tasks/{ref}/comments", s.authed(s.handleTaskCommentCreate))
+	mux.HandleFunc("GET /v1/tasks/{ref}/comments", s.authed(s.handleTaskCommentList))
+	mux.HandleFunc("POST /v1/tasks/{ref}/claim", s.authed(s.handleTaskClaim))
+	mux.HandleFunc("POST /v1/tasks/{ref}/claim/heartbeat", s.authed(s.handleTaskClaimHeartbeat))
+	mux.HandleFunc("POST /v1/tasks/{ref}/claim/release", s.authed(s.handleTaskClaimRelease))
+	mux.HandleFunc("POST /v1/tasks/{ref}/claim/handoff", s.authed(s.handleTaskClaimHandoff))
+	mux.HandleFunc("GET /v1/claims", s.authed(s.handleClaimsList))
+
+	mux.HandleFunc("POST /v1/reservations", s.authed(s.handleReservationAcquire))
+	mux.HandleFunc("GET /v1/reservations", s.authed(s.handleReservationList))
+	mux.HandleFunc("POST /v1/reservations/renew", s.authed(s.handleReservationRenew))
+	mux.HandleFunc("POST /v1/reservations/release", s.authed(s.handleReservationRelease))
+	mux.HandleFunc("POST /v1/reservations/revoke", s.authed(s.handleReservationRevoke))
+
+	mux.HandleFunc("POST /v1/chat/sessions", s.authed(s.handleChatCreateSession))
+	mux.HandleFunc("GET /v1/chat/sessions", s.authed(s.handleChatListSessions))
+	mux.HandleFunc("GET /v1/chat/pending", s.authed(s.handleChatPending))
+	mux.HandleFunc("POST /v1/chat/sessions/{session}/messages", s.authed(s.handleChatSendMessage))
+	mux.HandleFunc("GET /v1/chat/sessions/{session}/messages", s.authed(s.handleChatHistory))
+	mux.HandleFunc("POST /v1/chat/sessions/{session}/read", s.authed(s.handleChatMarkRead))
+	mux.HandleFunc("GET /v1/chat/sessions/{session}/stream", s.authed(s.handleChatStream))
+
+	mux.HandleFunc("POST /v1/messages", s.authed(s.handleMailSend))
+	mux.HandleFunc("GET /v1/messages/inbox", s.authed(s.handleMailInbox))
+	mux.HandleFunc("GET /v1/messages/conversations/{conversation}", s.authed(s.handleMailConversation))
+	mux.HandleFunc("GET /v1/messages/{message}", s.authed(s.handleMailGet))
+	mux.HandleFunc("POST /v1/messages/{message}/ack", s.authed(s.handleMailAck))
+
+	mux.HandleFunc("POST /v1/agents/{alias}/control", s.authed(s.handleControlSignal))
+	mux.HandleFunc("GET /v1/events/stream", s.authed(s.handleEventStream))
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeDetail(w, http.StatusNotFound, "Not Found")
+	})
+	return mux
+}
+
+func (s *Server) timestamp() string {
+	return s.now().UTC().Format(time.RFC3339)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeDetail(w http.ResponseWriter, status int, detail string) {
+	writeJSON(w, status, map[string]string{"detail": detail})
+}
+
+func decodeBody(body []byte, target any) error {
+	if len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, target)
+}
+
+func newID() string {
+	id, err := awid.GenerateUUID4()
+	if err != nil {
+		panic(fmt.Sprintf("awebtest: generate id: %v", err))
+	}
+	return id
+}
+
+func stringPtr(v string) *string {
+	return &v
+}
@@ -0,0 +1,406 @@
+package awebtest_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	aweb "github.com/awebai/aw"
+	"github.com/awebai/aw/awebtest"
+	"github.com/awebai/aw/awid"
+	"github.com/awebai/aw/chat"
+)
+
+func newTeam(t *testing.T, aliases ...string) (*awebtest.Server, []*awebtest.Agent) {
+	t.Helper()
+	srv := awebtest.NewServer()
+	t.Cleanup(srv.Close)
+	team, err := srv.AddTeam("backend:example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	agents := make([]*awebtest.Agent, 0, len(aliases))
+	for _, alias := range aliases {
+		agent, err := team.AddAgent(alias)
+		if err != nil {
+			t.Fatal(err)
+		}
+		agents = append(agents, agent)
+	}
+	return srv, agents
+}
+
+func mustClient(t *testing.T, agent *awebtest.Agent) *aweb.Client {
+	t.Helper()
+	client, err := agent.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func openEvents(t *testing.T, client *aweb.Client) *awid.AgentEventStream {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	stream, err := client.EventStream(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = stream.Close() })
+	ev, err := stream.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != awid.AgentEventConnected {
+		t.Fatalf("first event=%s", ev.Type)
+	}
+	return stream
+}
+
+func nextEvent(t *testing.T, stream *awid.AgentEventStream, want awid.AgentEventType) *awid.AgentEvent {
+	t.Helper()
+	for {
+		ev, err := stream.Next(context.Background())
+		if err != nil {
+			t.Fatalf("waiting for %s: %v", want, err)
+		}
+		if ev.Type == want {
+			return ev
+		}
+	}
+}
+
+func TestServerRejectsUnauthenticatedAndRevokedRequests(t *testing.T) {
+	t.Parallel()
+	srv, agents := newTeam(t, "alice")
+	ctx := context.Background()
+
+	anon, err := aweb.New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := anon.TaskListReady(ctx); !isStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("anonymous err=%v", err)
+	}
+
+	_, foreignKey, _ := ed25519.GenerateKey(nil)
+	forged, err := awid.SignTeamCertificate(foreignKey, awid.TeamCertificateFields{
+		Team:          "backend:example.com",
+		MemberDIDKey:  agents[0].DID,
+		Alias:         "alice",
+		IdentityScope: awid.IdentityModeLocal,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	forgedClient, err := aweb.NewWithCertificate(srv.URL, agents[0].SigningKey, forged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := forgedClient.TaskListReady(ctx); !isStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("forged certificate err=%v", err)
+	}
+
+	client := mustClient(t, agents[0])
+	if _, err := client.TaskListReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !agents[0].Team().RemoveAgent("alice") {
+		t.Fatal("RemoveAgent returned false")
+	}
+	if _, err := client.TaskListReady(ctx); !isStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("revoked err=%v", err)
+	}
+}
+
+func TestServerTasksWithDepsAndClaims(t *testing.T) {
+	t.Parallel()
+	_, agents := newTeam(t, "alice", "bob")
+	ctx := context.Background()
+	alice := mustClient(t, agents[0])
+	bob := mustClient(t, agents[1])
+	bobEvents := openEvents(t, bob)
+
+	design, err := alice.TaskCreate(ctx, &aweb.TaskCreateRequest{Title: "Design", Priority: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev := nextEvent(t, bobEvents, awid.AgentEventWorkAvailable); ev.TaskID != design.TaskID {
+		t.Fatalf("work_available task=%s", ev.TaskID)
+	}
+	build, err := alice.TaskCreate(ctx, &aweb.TaskCreateRequest{Title: "Build", Priority: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextEvent(t, bobEvents, awid.AgentEventWorkAvailable)
+	if err := alice.TaskAddDep(ctx, build.TaskRef, &aweb.TaskAddDepRequest{DependsOn: design.TaskRef}); err != nil {
+		t.Fatal(err)
+	}
+	if err := alice.TaskAddDep(ctx, design.TaskRef, &aweb.TaskAddDepRequest{DependsOn: build.TaskRef}); !isStatus(err, http.StatusUnprocessableEntity) {
+		t.Fatalf("cycle err=%v", err)
+	}
+
+	ready, err := alice.TaskListReady(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ready.Tasks) != 1 || ready.Tasks[0].TaskRef != design.TaskRef {
+		t.Fatalf("ready=%+v", ready.Tasks)
+	}
+
+	inProgress := "in_progress"
+	if _, err := alice.TaskUpdate(ctx, design.TaskRef, &aweb.TaskUpdateRequest{Status: &inProgress}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = bob.TaskUpdate(ctx, design.TaskRef, &aweb.TaskUpdateRequest{Status: &inProgress})
+	var held *aweb.TaskHeldError
+	if !errors.As(err, &held) || held.AssigneeAlias != "alice" {
+		t.Fatalf("claim conflict err=%v", err)
+	}
+	claims, err := bob.ClaimsList(ctx, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claims.Claims) != 1 || claims.Claims[0].Alias != "alice" {
+		t.Fatalf("claims=%+v", claims.Claims)
+	}
+
+	closed := "closed"
+	if _, err := alice.TaskUpdate(ctx, design.TaskRef, &aweb.TaskUpdateRequest{Status: &closed}); err != nil {
+		t.Fatal(err)
+	}
+	if ev := nextEvent(t, bobEvents, awid.AgentEventWorkAvailable); ev.TaskID != build.TaskID {
+		t.Fatalf("unblocked work_available task=%s", ev.TaskID)
+	}
+	got, err := bob.TaskGet(ctx, build.TaskRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.BlockedBy) != 1 || got.BlockedBy[0].Status != "closed" {
+		t.Fatalf("blocked_by=%+v", got.BlockedBy)
+	}
+}
+
+func TestServerTaskClaimLifecycle(t *testing.T) {
+	t.Parallel()
+	srv, agents := newTeam(t, "alice", "bob", "carol")
+	ctx := context.Background()
+	alice := mustClient(t, agents[0])
+	bob := mustClient(t, agents[1])
+	carol := mustClient(t, agents[2])
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	srv.SetClock(func() time.Time { return now })
+
+	task, err := alice.TaskCreate(ctx, &aweb.TaskCreateRequest{Title: "Fix the build"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claim, err := alice.TaskClaim(ctx, task.TaskRef, &aweb.TaskClaimRequest{WorkspaceID: "ws-alice", Branch: "fix-build"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claim.Status != "in_progress" || claim.OwnerAlias != "alice" || claim.WorkspaceID != "ws-alice" || claim.Branch != "fix-build" {
+		t.Fatalf("claim=%+v", claim)
+	}
+
+	_, err = bob.TaskClaim(ctx, task.TaskRef, &aweb.TaskClaimRequest{WorkspaceID: "ws-bob", StaleAfterSeconds: 1800})
+	var held *aweb.TaskHeldError
+	if !errors.As(err, &held) || held.AssigneeAlias != "alice" {
+		t.Fatalf("fresh claim err=%v", err)
+	}
+	if _, err := bob.TaskClaimHeartbeat(ctx, task.TaskRef, &aweb.TaskClaimHeartbeatRequest{WorkspaceID: "ws-bob"}); !errors.As(err, &held) {
+		t.Fatalf("heartbeat by non-owner err=%v", err)
+	}
+
+	now = now.Add(20 * time.Minute)
+	if _, err := alice.TaskClaimHeartbeat(ctx, task.TaskRef, &aweb.TaskClaimHeartbeatRequest{WorkspaceID: "ws-alice"}); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(20 * time.Minute)
+	if _, err := bob.TaskClaim(ctx, task.TaskRef, &aweb.TaskClaimRequest{WorkspaceID: "ws-bob", StaleAfterSeconds: 1800}); !errors.As(err, &held) {
+		t.Fatalf("claim after heartbeat err=%v", err)
+	}
+
+	now = now.Add(20 * time.Minute)
+	reclaimed, err := bob.TaskClaim(ctx, task.TaskRef, &aweb.TaskClaimRequest{WorkspaceID: "ws-bob", StaleAfterSeconds: 1800})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reclaimed.OwnerAlias != "bob" || reclaimed.PreviousOwner == nil || reclaimed.PreviousOwner.Alias != "alice" || reclaimed.PreviousOwner.WorkspaceID != "ws-alice" {
+		t.Fatalf("reclaimed=%+v previous=%+v", reclaimed, reclaimed.PreviousOwner)
+	}
+
+	handed, err := bob.TaskHandoff(ctx, task.TaskRef, &aweb.TaskHandoffRequest{WorkspaceID: "ws-bob", ToAlias: "carol"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handed.OwnerAlias != "carol" || handed.Status != "in_progress" {
+		t.Fatalf("handoff=%+v", handed)
+	}
+	if err := bob.TaskRelease(ctx, task.TaskRef, &aweb.TaskReleaseRequest{WorkspaceID: "ws-bob"}); !errors.As(err, &held) || held.AssigneeAlias != "carol" {
+		t.Fatalf("release by previous owner err=%v", err)
+	}
+	if err := carol.TaskRelease(ctx, task.TaskRef, &aweb.TaskReleaseRequest{Reason: "blocked"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := alice.TaskGet(ctx, task.TaskRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != "open" {
+		t.Fatalf("status after release=%s", got.Status)
+	}
+}
+
+func TestServerReservations(t *testing.T) {
+	t.Parallel()
+	srv, agents := newTeam(t, "alice", "bob")
+	ctx := context.Background()
+	alice := mustClient(t, agents[0])
+	bob := mustClient(t, agents[1])
+
+	if _, err := alice.ReservationAcquire(ctx, &aweb.ReservationAcquireRequest{ResourceKey: "repo/main", TTLSeconds: 60}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := bob.ReservationAcquire(ctx, &aweb.ReservationAcquireRequest{ResourceKey: "repo/main"})
+	var held *aweb.ReservationHeldError
+	if !errors.As(err, &held) || held.HolderAlias != "alice" {
+		t.Fatalf("acquire conflict err=%v", err)
+	}
+
+	later := time.Now().Add(2 * time.Minute)
+	srv.SetClock(func() time.Time { return later })
+	if _, err := bob.ReservationAcquire(ctx, &aweb.ReservationAcquireRequest{ResourceKey: "repo/main"}); err != nil {
+		t.Fatalf("acquire after expiry: %v", err)
+	}
+	list, err := alice.ReservationList(ctx, "repo/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Reservations) != 1 || list.Reservations[0].HolderAlias != "bob" {
+		t.Fatalf("reservations=%+v", list.Reservations)
+	}
+}
+
+func TestServerMailAckAndControlSignals(t *testing.T) {
+	t.Parallel()
+	srv, agents := newTeam(t, "alice", "bob")
+	ctx := context.Background()
+	alice := mustClient(t, agents[0])
+	bob := mustClient(t, agents[1])
+	bobEvents := openEvents(t, bob)
+
+	sent, err := alice.SendMessage(ctx, &awid.SendMessageRequest{ToAlias: "bob", Subject: "hi", Body: "ship it"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev := nextEvent(t, bobEvents, awid.AgentEventActionableMail); ev.MessageID != sent.MessageID || ev.FromAlias != "alice" {
+		t.Fatalf("actionable_mail=%+v", ev)
+	}
+	inbox, err := bob.Inbox(ctx, awid.InboxParams{UnreadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inbox.Messages) != 1 || inbox.Messages[0].Body != "ship it" {
+		t.Fatalf("inbox=%+v", inbox.Messages)
+	}
+	if inbox.Messages[0].VerificationStatus != awid.Verified {
+		t.Fatalf("verification=%s", inbox.Messages[0].VerificationStatus)
+	}
+	if _, err := bob.AckMessage(ctx, sent.MessageID); err != nil {
+		t.Fatal(err)
+	}
+	inbox, err = bob.Inbox(ctx, awid.InboxParams{UnreadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inbox.Messages) != 0 {
+		t.Fatalf("unread after ack=%+v", inbox.Messages)
+	}
+
+	signal, err := alice.PauseAgent(ctx, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev := nextEvent(t, bobEvents, awid.AgentEventControlPause); ev.SignalID != signal.SignalID {
+		t.Fatalf("control_pause signal=%s", ev.SignalID)
+	}
+	if signals := srv.ControlSignals(); len(signals) != 1 || signals[0].FromAlias != "alice" {
+		t.Fatalf("signals=%+v", signals)
+	}
+}
+
+func TestServerChatSendEndToEnd(t *testing.T) {
+	t.Parallel()
+	_, agents := newTeam(t, "alice", "bob")
+	ctx := context.Background()
+	alice, err := agents[0].AWIDClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob := mustClient(t, agents[1])
+	bobEvents := openEvents(t, bob)
+
+	replied := make(chan error, 1)
+	go func() {
+		ev, err := bobEvents.Next(ctx)
+		for err == nil && ev.Type != awid.AgentEventActionableChat {
+			ev, err = bobEvents.Next(ctx)
+		}
+		if err != nil {
+			replied <- err
+			return
+		}
+		if !ev.SenderWaiting {
+			replied <- errors.New("actionable_chat did not report a waiting sender")
+			return
+		}
+		_, err = bob.ChatSendMessage(ctx, ev.SessionID, &awid.ChatSendMessageRequest{Body: "on it"})
+		replied <- err
+	}()
+
+	result, err := chat.Send(ctx, alice, "alice", []string{"bob"}, "can you review?", chat.SendOptions{Wait: 10}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-replied; err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "replied" || result.Reply != "on it" {
+		t.Fatalf("result status=%s reply=%q", result.Status, result.Reply)
+	}
+
+	pending, err := bob.ChatPending(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending.Pending) != 1 || pending.Pending[0].LastMessage != "can you review?" {
+		t.Fatalf("pending=%+v", pending.Pending)
+	}
+}
+
+func TestServerPublishDeliversCustomEvents(t *testing.T) {
+	t.Parallel()
+	srv, agents := newTeam(t, "alice")
+	stream := openEvents(t, mustClient(t, agents[0]))
+
+	if err := srv.Publish(agents[0], awid.AgentEventAppEvent, map[string]any{
+		"event_id":       "evt-1",
+		"app_id":         "ci",
+		"app_event_type": "build_failed",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if ev := nextEvent(t, stream, awid.AgentEventAppEvent); ev.EventID != "evt-1" || ev.AppEventType != "build_failed" {
+		t.Fatalf("app_event=%+v", ev)
+	}
+}
+
+func isStatus(err error, status int) bool {
+	code, ok := awid.HTTPStatusCode(err)
+	return ok && code == status
+}
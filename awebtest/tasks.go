@@ -0,0 +1,756 @@
+package awebtest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	aweb "github.com/awebai/aw"
+	"github.com/awebai/aw/awid"
+)
+
+const (
+	taskStatusOpen       = "open"
+	taskStatusInProgress = "in_progress"
+	taskStatusClosed     = "closed"
+)
+
+type task struct {
+	aweb.Task
+
+	deps        []string // task IDs this task depends on
+	holder      *Agent
+	claimedAt   string
+	workspaceID string
+	branch      string
+	lastSeenAt  string
+}
+
+func (s *Server) handleTaskCreate(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req aweb.TaskCreateRequest
+	if err := decodeBody(body, &req); err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		writeDetail(w, http.StatusUnprocessableEntity, "title is required")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team := caller.team
+	if req.ParentTaskID != nil && team.findTaskLocked(*req.ParentTaskID) == nil {
+		writeDetail(w, http.StatusNotFound, "parent task not found")
+		return
+	}
+	if req.AssigneeAlias != nil && team.agents[*req.AssigneeAlias] == nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "assignee is not a team member")
+		return
+	}
+	team.nextTaskNumber++
+	now := s.timestamp()
+	taskType := strings.TrimSpace(req.TaskType)
+	if taskType == "" {
+		taskType = "task"
+	}
+	t := &task{Task: aweb.Task{
+		TaskID:         newID(),
+		TaskRef:        fmt.Sprintf("%s-%03d", team.taskPrefix, team.nextTaskNumber),
+		TaskNumber:     team.nextTaskNumber,
+		Title:          req.Title,
+		Description:    req.Description,
+		Notes:          req.Notes,
+		Status:         taskStatusOpen,
+		Priority:       req.Priority,
+		TaskType:       taskType,
+		Labels:         append([]string(nil), req.Labels...),
+		AssigneeAlias:  req.AssigneeAlias,
+		CreatedByAlias: stringPtr(caller.Alias),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}}
+	if req.ParentTaskID != nil {
+		t.ParentTaskID = stringPtr(team.findTaskLocked(*req.ParentTaskID).TaskID)
+	}
+	team.tasks[t.TaskID] = t
+	team.taskOrder = append(team.taskOrder, t.TaskID)
+	s.announceWorkLocked(team, t, caller)
+	writeJSON(w, http.StatusOK, team.taskViewLocked(t))
+}
+
+func (s *Server) handleTaskList(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	q := r.URL.Query()
+	var priority *int
+	if raw := q.Get("priority"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			writeDetail(w, http.StatusUnprocessableEntity, "priority must be an integer")
+			return
+		}
+		priority = &value
+	}
+	var labels []string
+	if raw := q.Get("labels"); raw != "" {
+		labels = strings.Split(raw, ",")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team := caller.team
+	parentID := ""
+	if raw := q.Get("parent_task_id"); raw != "" {
+		parent := team.findTaskLocked(raw)
+		if parent == nil {
+			writeJSON(w, http.StatusOK, aweb.TaskListResponse{Tasks: []aweb.TaskSummary{}})
+			return
+		}
+		parentID = parent.TaskID
+	}
+	writeJSON(w, http.StatusOK, aweb.TaskListResponse{Tasks: team.taskSummariesLocked(func(t *task) bool {
+		if status := q.Get("status"); status != "" && t.Status != status {
+			return false
+		}
+		if alias := q.Get("assignee_alias"); alias != "" && (t.AssigneeAlias == nil || *t.AssigneeAlias != alias) {
+			return false
+		}
+		if taskType := q.Get("task_type"); taskType != "" && t.TaskType != taskType {
+			return false
+		}
+		if priority != nil && t.Priority != *priority {
+			return false
+		}
+		for _, label := range labels {
+			if !slices.Contains(t.Labels, strings.TrimSpace(label)) {
+				return false
+			}
+		}
+		if parentID != "" && (t.ParentTaskID == nil || *t.ParentTaskID != parentID) {
+			return false
+		}
+		return true
+	})})
+}
+
+func (s *Server) handleTaskListReady(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team := caller.team
+	writeJSON(w, http.StatusOK, aweb.TaskListResponse{Tasks: team.taskSummariesLocked(func(t *task) bool {
+		return team.readyLocked(t)
+	})})
+}
+
+func (s *Server) handleTaskListBlocked(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team := caller.team
+	writeJSON(w, http.StatusOK, aweb.TaskListResponse{Tasks: team.taskSummariesLocked(func(t *task) bool {
+		return t.Status != taskStatusClosed && team.blockedLocked(t)
+	})})
+}
+
+func (s *Server) handleTaskListActive(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team := caller.team
+	out := aweb.ActiveTaskListResponse{Tasks: []aweb.ActiveTaskSummary{}}
+	for _, id := range team.taskOrder {
+		t := team.tasks[id]
+		if t.Status != taskStatusInProgress {
+			continue
+		}
+		summary := aweb.ActiveTaskSummary{
+			TaskID:         t.TaskID,
+			TaskRef:        t.TaskRef,
+			TaskNumber:     t.TaskNumber,
+			Title:          t.Title,
+			Status:         t.Status,
+			Priority:       t.Priority,
+			TaskType:       t.TaskType,
+			AssigneeAlias:  t.AssigneeAlias,
+			CreatedByAlias: t.CreatedByAlias,
+			ParentTaskID:   t.ParentTaskID,
+			Labels:         t.Labels,
+			CreatedAt:      t.CreatedAt,
+			UpdatedAt:      t.UpdatedAt,
+		}
+		if t.holder != nil {
+			summary.OwnerAlias = stringPtr(t.holder.Alias)
+			summary.ClaimedAt = stringPtr(t.claimedAt)
+			summary.OwnerLastSeenAt = stringPtr(t.lastSeenAt)
+			if t.workspaceID != "" {
+				summary.WorkspaceID = stringPtr(t.workspaceID)
+			}
+			if t.branch != "" {
+				summary.Branch = stringPtr(t.branch)
+			}
+		}
+		out.Tasks = append(out.Tasks, summary)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleTaskGet(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := caller.team.findTaskLocked(r.PathValue("ref"))
+	if t == nil {
+		writeDetail(w, http.StatusNotFound, "task not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, caller.team.taskViewLocked(t))
+}
+
+func (s *Server) handleTaskUpdate(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req aweb.TaskUpdateRequest
+	if err := decodeBody(body, &req); err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "invalid request body")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team := caller.team
+	t := team.findTaskLocked(r.PathValue("ref"))
+	if t == nil {
+		writeDetail(w, http.StatusNotFound, "task not found")
+		return
+	}
+	if req.Status != nil {
+		switch *req.Status {
+		case taskStatusOpen, taskStatusInProgress, taskStatusClosed:
+		default:
+			writeDetail(w, http.StatusUnprocessableEntity, fmt.Sprintf("invalid status %q", *req.Status))
+			return
+		}
+		if *req.Status == taskStatusInProgress && t.holder != nil && t.holder != caller {
+			held := aweb.TaskHeldError{
+				Detail:        "task is already in progress",
+				HolderAgentID: t.holder.AgentID,
+				AssigneeAlias: t.holder.Alias,
+			}
+			writeJSON(w, http.StatusConflict, held)
+			return
+		}
+	}
+	if req.AssigneeAlias != nil && *req.AssigneeAlias != "" && team.agents[*req.AssigneeAlias] == nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "assignee is not a team member")
+		return
+	}
+	if req.ParentTaskID != nil && *req.ParentTaskID != "" {
+		parent := team.findTaskLocked(*req.ParentTaskID)
+		if parent == nil {
+			writeDetail(w, http.StatusNotFound, "parent task not found")
+			return
+		}
+		req.ParentTaskID = stringPtr(parent.TaskID)
+	}
+
+	if req.Title != nil {
+		t.Title = *req.Title
+	}
+	if req.Description != nil {
+		t.Description = *req.Description
+	}
+	if req.Notes != nil {
+		t.Notes = *req.Notes
+	}
+	if req.TaskType != nil {
+		t.TaskType = *req.TaskType
+	}
+	if req.Priority != nil {
+		t.Priority = *req.Priority
+	}
+	if req.Labels != nil {
+		t.Labels = append([]string(nil), req.Labels...)
+	}
+	if req.AssigneeAlias != nil {
+		if *req.AssigneeAlias == "" {
+			t.AssigneeAlias = nil
+		} else {
+			t.AssigneeAlias = stringPtr(*req.AssigneeAlias)
+		}
+	}
+	if req.ParentTaskID != nil {
+		if *req.ParentTaskID == "" {
+			t.ParentTaskID = nil
+		} else {
+			t.ParentTaskID = req.ParentTaskID
+		}
+	}
+	t.UpdatedAt = s.timestamp()
+
+	var autoClosed []aweb.TaskSummary
+	if req.Status != nil && *req.Status != t.Status {
+		switch *req.Status {
+		case taskStatusInProgress:
+			s.claimTaskLocked(t, caller)
+		case taskStatusOpen:
+			s.releaseTaskLocked(t)
+			t.Status = taskStatusOpen
+			t.ClosedAt = nil
+			t.ClosedByAlias = nil
+			s.announceWorkLocked(team, t, caller)
+		case taskStatusClosed:
+			autoClosed = s.closeTaskLocked(team, t, caller)
+		}
+	} else if req.Status != nil && *req.Status == taskStatusInProgress && t.holder == nil {
+		s.claimTaskLocked(t, caller)
+	}
+	writeJSON(w, http.StatusOK, aweb.TaskUpdateResponse{Task: team.taskViewLocked(t), AutoClosed: autoClosed})
+}
+
+func (s *Server) handleTaskDelete(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team := caller.team
+	t := team.findTaskLocked(r.PathValue("ref"))
+	if t == nil {
+		writeDetail(w, http.StatusNotFound, "task not found")
+		return
+	}
+	s.releaseTaskLocked(t)
+	delete(team.tasks, t.TaskID)
+	team.taskOrder = slices.DeleteFunc(team.taskOrder, func(id string) bool { return id == t.TaskID })
+	for _, other := range team.tasks {
+		other.deps = slices.DeleteFunc(other.deps, func(id string) bool { return id == t.TaskID })
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleTaskAddDep(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req aweb.TaskAddDepRequest
+	if err := decodeBody(body, &req); err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "invalid request body")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team := caller.team
+	t := team.findTaskLocked(r.PathValue("ref"))
+	dep := team.findTaskLocked(req.DependsOn)
+	if t == nil || dep == nil {
+		writeDetail(w, http.StatusNotFound, "task not found")
+		return
+	}
+	if dep == t || team.dependsOnLocked(dep, t.TaskID) {
+		writeDetail(w, http.StatusUnprocessableEntity, "dependency would create a cycle")
+		return
+	}
+	if !slices.Contains(t.deps, dep.TaskID) {
+		t.deps = append(t.deps, dep.TaskID)
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"task_id": t.TaskID, "depends_on": dep.TaskID})
+}
+
+func (s *Server) handleTaskRemoveDep(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team := caller.team
+	t := team.findTaskLocked(r.PathValue("ref"))
+	dep := team.findTaskLocked(r.PathValue("dep"))
+	if t == nil || dep == nil || !slices.Contains(t.deps, dep.TaskID) {
+		writeDetail(w, http.StatusNotFound, "dependency not found")
+		return
+	}
+	wasReady := team.readyLocked(t)
+	t.deps = slices.DeleteFunc(t.deps, func(id string) bool { return id == dep.TaskID })
+	if !wasReady {
+		s.announceWorkLocked(team, t, caller)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleTaskCommentCreate(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req aweb.TaskCommentCreateRequest
+	if err := decodeBody(body, &req); err != nil || strings.TrimSpace(req.Body) == "" {
+		writeDetail(w, http.StatusUnprocessableEntity, "comment body is required")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := caller.team.findTaskLocked(r.PathValue("ref"))
+	if t == nil {
+		writeDetail(w, http.StatusNotFound, "task not found")
+		return
+	}
+	comment := aweb.TaskComment{
+		CommentID:     newID(),
+		TaskID:        t.TaskID,
+		AuthorAgentID: stringPtr(caller.AgentID),
+		AuthorAlias:   caller.Alias,
+		Body:          req.Body,
+		ParentID:      req.ParentID,
+		CreatedAt:     s.timestamp(),
+	}
+	t.Comments = append(t.Comments, comment)
+	writeJSON(w, http.StatusOK, comment)
+}
+
+func (s *Server) handleTaskCommentList(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := caller.team.findTaskLocked(r.PathValue("ref"))
+	if t == nil {
+		writeDetail(w, http.StatusNotFound, "task not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, aweb.TaskCommentListResponse{Comments: append([]aweb.TaskComment{}, t.Comments...)})
+}
+
+func (s *Server) handleClaimsList(w http.ResponseWriter, r *http.Request, caller *Agent, _ []byte) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team := caller.team
+	out := aweb.ClaimsResponse{Claims: []aweb.ClaimView{}}
+	for _, id := range team.taskOrder {
+		t := team.tasks[id]
+		if t.holder == nil {
+			continue
+		}
+		if limit > 0 && len(out.Claims) >= limit {
+			out.HasMore = true
+			break
+		}
+		out.Claims = append(out.Claims, aweb.ClaimView{BeadID: t.TaskRef, Alias: t.holder.Alias, ClaimedAt: t.claimedAt})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleTaskClaim(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req aweb.TaskClaimRequest
+	if err := decodeBody(body, &req); err != nil || req.StaleAfterSeconds < 0 {
+		writeDetail(w, http.StatusUnprocessableEntity, "invalid request body")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := caller.team.findTaskLocked(r.PathValue("ref"))
+	if t == nil {
+		writeDetail(w, http.StatusNotFound, "task not found")
+		return
+	}
+	if t.Status == taskStatusClosed {
+		writeDetail(w, http.StatusUnprocessableEntity, "task is closed")
+		return
+	}
+	var previous *aweb.TaskClaimOwner
+	if t.holder != nil && t.holder != caller {
+		if !s.claimStaleLocked(t, req.StaleAfterSeconds) {
+			writeTaskHeld(w, t)
+			return
+		}
+		previous = &aweb.TaskClaimOwner{Alias: t.holder.Alias, WorkspaceID: t.workspaceID, LastSeenAt: t.lastSeenAt}
+		s.releaseTaskLocked(t)
+	}
+	if t.holder == nil {
+		s.claimTaskLocked(t, caller)
+	}
+	t.workspaceID = req.WorkspaceID
+	t.branch = req.Branch
+	t.lastSeenAt = s.timestamp()
+	t.UpdatedAt = t.lastSeenAt
+	view := t.claimView()
+	view.PreviousOwner = previous
+	writeJSON(w, http.StatusOK, view)
+}
+
+func (s *Server) handleTaskClaimHeartbeat(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req aweb.TaskClaimHeartbeatRequest
+	if err := decodeBody(body, &req); err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "invalid request body")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.heldTaskLocked(w, r, caller, req.WorkspaceID)
+	if t == nil {
+		return
+	}
+	t.lastSeenAt = s.timestamp()
+	writeJSON(w, http.StatusOK, t.claimView())
+}
+
+func (s *Server) handleTaskClaimRelease(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req aweb.TaskReleaseRequest
+	if err := decodeBody(body, &req); err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "invalid request body")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.heldTaskLocked(w, r, caller, req.WorkspaceID)
+	if t == nil {
+		return
+	}
+	s.releaseTaskLocked(t)
+	t.Status = taskStatusOpen
+	t.UpdatedAt = s.timestamp()
+	s.announceWorkLocked(caller.team, t, caller)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleTaskClaimHandoff(w http.ResponseWriter, r *http.Request, caller *Agent, body []byte) {
+	var req aweb.TaskHandoffRequest
+	if err := decodeBody(body, &req); err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "invalid request body")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	target := caller.team.agents[strings.TrimSpace(req.ToAlias)]
+	if target == nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "handoff target is not a team member")
+		return
+	}
+	t := s.heldTaskLocked(w, r, caller, req.WorkspaceID)
+	if t == nil {
+		return
+	}
+	s.releaseTaskLocked(t)
+	s.claimTaskLocked(t, target)
+	t.AssigneeAlias = stringPtr(target.Alias)
+	t.UpdatedAt = t.claimedAt
+	writeJSON(w, http.StatusOK, t.claimView())
+}
+
+// heldTaskLocked returns the task at the request's ref when caller's
+// workspace holds its claim, and otherwise writes the error response and
+// returns nil.
+func (s *Server) heldTaskLocked(w http.ResponseWriter, r *http.Request, caller *Agent, workspaceID string) *task {
+	t := caller.team.findTaskLocked(r.PathValue("ref"))
+	if t == nil {
+		writeDetail(w, http.StatusNotFound, "task not found")
+		return nil
+	}
+	if t.holder == nil {
+		writeDetail(w, http.StatusConflict, "task is not claimed")
+		return nil
+	}
+	if t.holder != caller || (workspaceID != "" && t.workspaceID != "" && workspaceID != t.workspaceID) {
+		writeTaskHeld(w, t)
+		return nil
+	}
+	return t
+}
+
+// claimStaleLocked reports whether t's owner has gone quiet for at least
+// staleAfterSeconds. Zero never treats a claim as stale.
+func (s *Server) claimStaleLocked(t *task, staleAfterSeconds int) bool {
+	if staleAfterSeconds <= 0 {
+		return false
+	}
+	lastSeen, err := time.Parse(time.RFC3339, t.lastSeenAt)
+	if err != nil {
+		return true
+	}
+	return s.now().Sub(lastSeen) >= time.Duration(staleAfterSeconds)*time.Second
+}
+
+func writeTaskHeld(w http.ResponseWriter, t *task) {
+	writeJSON(w, http.StatusConflict, aweb.TaskHeldError{
+		Detail:        "task is already in progress",
+		HolderAgentID: t.holder.AgentID,
+		AssigneeAlias: t.holder.Alias,
+	})
+}
+
+func (s *Server) claimTaskLocked(t *task, agent *Agent) {
+	t.Status = taskStatusInProgress
+	t.ClosedAt = nil
+	t.ClosedByAlias = nil
+	t.holder = agent
+	t.claimedAt = s.timestamp()
+	t.lastSeenAt = t.claimedAt
+	if t.AssigneeAlias == nil {
+		t.AssigneeAlias = stringPtr(agent.Alias)
+	}
+	s.emitLocked(agent, awid.AgentEventClaimUpdate, map[string]any{"task_id": t.TaskID, "title": t.Title, "status": t.Status})
+}
+
+func (s *Server) releaseTaskLocked(t *task) {
+	if t.holder == nil {
+		return
+	}
+	previous := t.holder
+	t.holder = nil
+	t.claimedAt = ""
+	t.workspaceID = ""
+	t.branch = ""
+	t.lastSeenAt = ""
+	s.emitLocked(previous, awid.AgentEventClaimRemoved, map[string]any{"task_id": t.TaskID})
+}
+
+// closeTaskLocked closes t and cascade-closes its open children, returning the
+// children it closed. Tasks unblocked by the close are announced as work.
+func (s *Server) closeTaskLocked(team *Team, t *task, closer *Agent) []aweb.TaskSummary {
+	wasBlocked := map[string]bool{}
+	for id, other := range team.tasks {
+		if team.blockedLocked(other) {
+			wasBlocked[id] = true
+		}
+	}
+	now := s.timestamp()
+	var autoClosed []aweb.TaskSummary
+	var closeOne func(*task)
+	closeOne = func(target *task) {
+		s.releaseTaskLocked(target)
+		target.Status = taskStatusClosed
+		target.ClosedAt = stringPtr(now)
+		target.ClosedByAlias = stringPtr(closer.Alias)
+		target.UpdatedAt = now
+		for _, id := range team.taskOrder {
+			child := team.tasks[id]
+			if child.ParentTaskID != nil && *child.ParentTaskID == target.TaskID && child.Status != taskStatusClosed {
+				closeOne(child)
+				autoClosed = append(autoClosed, child.summary())
+			}
+		}
+	}
+	closeOne(t)
+	for _, id := range team.taskOrder {
+		if wasBlocked[id] {
+			s.announceWorkLocked(team, team.tasks[id], closer)
+		}
+	}
+	return autoClosed
+}
+
+// announceWorkLocked emits work_available to every other team member when t
+// is ready and unassigned (or assigned to someone other than the actor).
+func (s *Server) announceWorkLocked(team *Team, t *task, actor *Agent) {
+	if !team.readyLocked(t) {
+		return
+	}
+	for _, agent := range team.agents {
+		if agent == actor {
+			continue
+		}
+		if t.AssigneeAlias != nil && *t.AssigneeAlias != agent.Alias {
+			continue
+		}
+		s.emitLocked(agent, awid.AgentEventWorkAvailable, map[string]any{"task_id": t.TaskID, "title": t.Title})
+	}
+}
+
+func (t *Team) findTaskLocked(ref string) *task {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil
+	}
+	if found := t.tasks[ref]; found != nil {
+		return found
+	}
+	for _, candidate := range t.tasks {
+		if strings.EqualFold(candidate.TaskRef, ref) {
+			return candidate
+		}
+	}
+	return nil
+}
+
+func (t *Team) blockedLocked(target *task) bool {
+	for _, id := range target.deps {
+		if dep := t.tasks[id]; dep != nil && dep.Status != taskStatusClosed {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Team) readyLocked(target *task) bool {
+	return target.Status == taskStatusOpen && !t.blockedLocked(target)
+}
+
+// dependsOnLocked reports whether from depends on taskID, directly or
+// transitively.
+func (t *Team) dependsOnLocked(from *task, taskID string) bool {
+	seen := map[string]bool{}
+	stack := append([]string(nil), from.deps...)
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == taskID {
+			return true
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if dep := t.tasks[id]; dep != nil {
+			stack = append(stack, dep.deps...)
+		}
+	}
+	return false
+}
+
+func (t *Team) taskViewLocked(target *task) aweb.Task {
+	view := target.Task
+	view.Labels = append([]string(nil), target.Labels...)
+	view.Comments = append([]aweb.TaskComment(nil), target.Comments...)
+	view.BlockedBy = nil
+	view.Blocks = nil
+	for _, id := range target.deps {
+		if dep := t.tasks[id]; dep != nil {
+			view.BlockedBy = append(view.BlockedBy, dep.depView())
+		}
+	}
+	for _, id := range t.taskOrder {
+		other := t.tasks[id]
+		if slices.Contains(other.deps, target.TaskID) {
+			view.Blocks = append(view.Blocks, other.depView())
+		}
+	}
+	return view
+}
+
+func (t *Team) taskSummariesLocked(keep func(*task) bool) []aweb.TaskSummary {
+	out := []aweb.TaskSummary{}
+	for _, id := range t.taskOrder {
+		if candidate := t.tasks[id]; keep(candidate) {
+			out = append(out, candidate.summary())
+		}
+	}
+	return out
+}
+
+func (t *task) summary() aweb.TaskSummary {
+	return aweb.TaskSummary{
+		TaskID:         t.TaskID,
+		TaskRef:        t.TaskRef,
+		TaskNumber:     t.TaskNumber,
+		Title:          t.Title,
+		Status:         t.Status,
+		Priority:       t.Priority,
+		TaskType:       t.TaskType,
+		AssigneeAlias:  t.AssigneeAlias,
+		CreatedByAlias: t.CreatedByAlias,
+		ParentTaskID:   t.ParentTaskID,
+		Labels:         append([]string(nil), t.Labels...),
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
+	}
+}
+
+func (t *task) claimView() aweb.TaskClaimView {
+	view := aweb.TaskClaimView{
+		TaskID:          t.TaskID,
+		TaskRef:         t.TaskRef,
+		Title:           t.Title,
+		Status:          t.Status,
+		WorkspaceID:     t.workspaceID,
+		Branch:          t.branch,
+		ClaimedAt:       t.claimedAt,
+		LastHeartbeatAt: t.lastSeenAt,
+	}
+	if t.holder != nil {
+		view.OwnerAlias = t.holder.Alias
+	}
+	return view
+}
+
+func (t *task) depView() aweb.TaskDepView {
+	return aweb.TaskDepView{TaskID: t.TaskID, TaskRef: t.TaskRef, Title: t.Title, Status: t.Status}
+}
@@ -1,7 +1,8 @@
 package awid
 
 import (
-	"crypto/ed25519"
+	"context"
+	"crypto"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
@@ -162,12 +163,12 @@ type A2AWriteResponse struct {
 
 type A2APublicationParams struct {
 	A2APublicationFields
-	SigningKey ed25519.PrivateKey
+	SigningKey crypto.Signer
 }
 
 type A2ADelegationParams struct {
 	A2ADelegationFields
-	SigningKey ed25519.PrivateKey
+	SigningKey crypto.Signer
 }
 
 func A2APublicationCanonical(fields A2APublicationFields) (string, error) {
@@ -473,15 +474,16 @@ func knownA2APublicationConflictCode(code string) bool {
 	return false
 }
 
-func signedA2APublicationBody(fields A2APublicationFields, signingKey ed25519.PrivateKey) (map[string]any, string, error) {
-	if signingKey == nil {
+func signedA2APublicationBody(ctx context.Context, fields A2APublicationFields, signingKey crypto.Signer) (map[string]any, string, error) {
+	pub := signingKeyPublic(signingKey)
+	if pub == nil {
 		return nil, "", fmt.Errorf("signing key is required")
 	}
 	normalized, err := normalizeA2APublicationFields(fields)
 	if err != nil {
 		return nil, "", err
 	}
-	if did := ComputeDIDKey(signingKey.Public().(ed25519.PublicKey)); did != normalized.SignerDID {
+	if did := ComputeDIDKey(pub); did != normalized.SignerDID {
 		return nil, "", fmt.Errorf("signing key does not match signer_did")
 	}
 	canonical, err := A2APublicationCanonical(normalized)
@@ -492,20 +494,25 @@ func signedA2APublicationBody(fields A2APublicationFields, signingKey ed25519.Pr
 	if err != nil {
 		return nil, "", err
 	}
-	signature := base64.RawStdEncoding.EncodeToString(ed25519.Sign(signingKey, []byte(canonical)))
+	sig, err := signWithKey(ctx, signingKey, []byte(canonical))
+	if err != nil {
+		return nil, "", err
+	}
+	signature := base64.RawStdEncoding.EncodeToString(sig)
 	body["signature"] = signature
 	return body, signature, nil
 }
 
-func signedA2ADelegationBody(fields A2ADelegationFields, signingKey ed25519.PrivateKey) (map[string]any, string, error) {
-	if signingKey == nil {
+func signedA2ADelegationBody(ctx context.Context, fields A2ADelegationFields, signingKey crypto.Signer) (map[string]any, string, error) {
+	pub := signingKeyPublic(signingKey)
+	if pub == nil {
 		return nil, "", fmt.Errorf("signing key is required")
 	}
 	normalized, err := normalizeA2ADelegationFields(fields)
 	if err != nil {
 		return nil, "", err
 	}
-	if did := ComputeDIDKey(signingKey.Public().(ed25519.PublicKey)); did != normalized.SignerDID {
+	if did := ComputeDIDKey(pub); did != normalized.SignerDID {
 		return nil, "", fmt.Errorf("signing key does not match signer_did")
 	}
 	canonical, err := A2ADelegationCanonical(normalized)
@@ -516,7 +523,11 @@ func signedA2ADelegationBody(fields A2ADelegationFields, signingKey ed25519.Priv
 	if err != nil {
 		return nil, "", err
 	}
-	signature := base64.RawStdEncoding.EncodeToString(ed25519.Sign(signingKey, []byte(canonical)))
+	sig, err := signWithKey(ctx, signingKey, []byte(canonical))
+	if err != nil {
+		return nil, "", err
+	}
+	signature := base64.RawStdEncoding.EncodeToString(sig)
 	body["signature"] = signature
 	return body, signature, nil
 }
@@ -53,7 +53,7 @@ func (c *RegistryClient) PublishA2ADelegationAt(ctx context.Context, registryURL
 		return nil, err
 	}
 	params.RegistryURL = registryURL
-	body, _, err := signedA2ADelegationBody(params.A2ADelegationFields, params.SigningKey)
+	body, _, err := signedA2ADelegationBody(ctx, params.A2ADelegationFields, params.SigningKey)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +70,7 @@ func (c *RegistryClient) PublishA2APublicationAt(ctx context.Context, registryUR
 		return nil, err
 	}
 	params.RegistryURL = registryURL
-	body, _, err := signedA2APublicationBody(params.A2APublicationFields, params.SigningKey)
+	body, _, err := signedA2APublicationBody(ctx, params.A2APublicationFields, params.SigningKey)
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +176,7 @@ func (r *TeamRosterResolver) resolve(ctx context.Context, identifier string, for
 	if !ok || r.Client == nil {
 		return nil, fmt.Errorf("TeamRosterResolver: unsupported team member reference %q", identifier)
 	}
-	if strings.TrimSpace(r.Client.teamID) != teamID || strings.TrimSpace(r.Client.teamCertHeader) == "" || len(r.Client.signingKey) == 0 {
+	if strings.TrimSpace(r.Client.teamID) != teamID || strings.TrimSpace(r.Client.teamCertHeader) == "" || r.Client.signer == nil {
 		return nil, errors.New("TeamRosterResolver: team-certificate authentication is required")
 	}
 	var out ListAgentsResponse
@@ -1,6 +1,8 @@
 package awid
 
 import (
+	"context"
+	"crypto"
 	"crypto/ed25519"
 	"encoding/base64"
 	"encoding/json"
@@ -44,7 +46,14 @@ type TeamCertificateFields struct {
 // SignTeamCertificate creates and signs a team membership certificate
 // using the team's Ed25519 private key.
 func SignTeamCertificate(teamKey ed25519.PrivateKey, fields TeamCertificateFields) (*TeamCertificate, error) {
-	if teamKey == nil {
+	return SignTeamCertificateContext(context.Background(), teamKey, fields)
+}
+
+// SignTeamCertificateContext is SignTeamCertificate for a team key that may
+// live outside the process (see CryptoSigner); ctx bounds the signature.
+func SignTeamCertificateContext(ctx context.Context, teamKey crypto.Signer, fields TeamCertificateFields) (*TeamCertificate, error) {
+	teamPub := signingKeyPublic(teamKey)
+	if teamPub == nil {
 		return nil, fmt.Errorf("team signing key is required")
 	}
 	if strings.TrimSpace(fields.Team) == "" {
@@ -69,14 +78,17 @@ func SignTeamCertificate(teamKey ed25519.PrivateKey, fields TeamCertificateField
 	if err != nil {
 		return nil, err
 	}
-	teamDIDKey := ComputeDIDKey(teamKey.Public().(ed25519.PublicKey))
+	teamDIDKey := ComputeDIDKey(teamPub)
 	issuedAt := time.Now().UTC().Format(time.RFC3339)
 
 	memberDIDAW := strings.TrimSpace(fields.MemberDIDAW)
 	memberAddress := strings.TrimSpace(fields.MemberAddress)
 
 	payload := canonicalCertificatePayload(certID, fields.Team, teamDIDKey, fields.MemberDIDKey, memberDIDAW, memberAddress, fields.Alias, identityScope, issuedAt, false)
-	sig := ed25519.Sign(teamKey, []byte(payload))
+	sig, err := signWithKey(ctx, teamKey, []byte(payload))
+	if err != nil {
+		return nil, fmt.Errorf("sign team certificate: %w", err)
+	}
 
 	return &TeamCertificate{
 		Version:       1,
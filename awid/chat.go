@@ -2,8 +2,6 @@ package awid
 
 import (
 	"context"
-	"crypto/ed25519"
-	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -214,6 +212,7 @@ type ChatCreateSessionResponse struct {
 	SSEURL           string            `json:"sse_url"`
 	TargetsConnected []string          `json:"targets_connected"`
 	TargetsLeft      []string          `json:"targets_left"`
+	Queued           bool              `json:"-"` // held in the outbox; not yet accepted by the server
 }
 
 type ChatParticipant struct {
@@ -228,7 +227,7 @@ func (c *Client) ChatCreateSession(ctx context.Context, req *ChatCreateSessionRe
 		return nil, errors.New("aweb: request is required")
 	}
 	payload := *req
-	if c.signingKey != nil && strings.TrimSpace(payload.SessionID) == "" {
+	if c.signer != nil && strings.TrimSpace(payload.SessionID) == "" {
 		sessionID, err := GenerateUUID4()
 		if err != nil {
 			return nil, err
@@ -239,11 +238,7 @@ func (c *Client) ChatCreateSession(ctx context.Context, req *ChatCreateSessionRe
 		if err := c.prepareE2EEChatCreate(ctx, &payload); err != nil {
 			return nil, err
 		}
-		var out ChatCreateSessionResponse
-		if err := c.Post(ctx, "/v1/chat/sessions", &payload, &out); err != nil {
-			return nil, err
-		}
-		return &out, nil
+		return c.postChatCreate(ctx, &payload)
 	}
 
 	to := strings.Join(payload.ToAliases, ",")
@@ -258,7 +253,7 @@ func (c *Client) ChatCreateSession(ctx context.Context, req *ChatCreateSessionRe
 		to = strings.Join(targets, ",")
 	}
 	from := c.address
-	if c.signingKey != nil {
+	if c.signer != nil {
 		if len(payload.ToAddresses) > 0 {
 			targets := append([]string(nil), payload.ToAddresses...)
 			sort.Strings(targets)
@@ -286,18 +281,34 @@ func (c *Client) ChatCreateSession(ctx context.Context, req *ChatCreateSessionRe
 	if err != nil {
 		return nil, err
 	}
-	if c.signingKey != nil {
+	if c.signer != nil {
 		payload.FromDID = sf.FromDID
 		payload.Signature = sf.Signature
 		payload.Timestamp = sf.Timestamp
 		payload.MessageID = sf.MessageID
 		payload.SignedPayload = sf.SignedPayload
 	}
+	return c.postChatCreate(ctx, &payload)
+}
 
+// postChatCreate posts a prepared session-opening message. A leaving
+// message expects no reply, so it is queued in the outbox when the server
+// is unreachable; a waiting one fails instead.
+func (c *Client) postChatCreate(ctx context.Context, payload *ChatCreateSessionRequest) (*ChatCreateSessionResponse, error) {
 	var out ChatCreateSessionResponse
-	if err := c.Post(ctx, "/v1/chat/sessions", &payload, &out); err != nil {
+	if !payload.Leaving {
+		if err := c.Post(ctx, "/v1/chat/sessions", payload, &out); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	}
+	queued, err := c.postOrQueue(ctx, OutboxKindChatCreate, "/v1/chat/sessions", payload.MessageID, payload, &out)
+	if err != nil {
 		return nil, err
 	}
+	if queued {
+		return &ChatCreateSessionResponse{SessionID: payload.SessionID, MessageID: payload.MessageID, Queued: true}, nil
+	}
 	return &out, nil
 }
 
@@ -305,7 +316,7 @@ func (c *Client) prepareE2EEChatCreate(ctx context.Context, payload *ChatCreateS
 	if c == nil || payload == nil {
 		return errors.New("aweb: request is required")
 	}
-	if c.signingKey == nil || strings.TrimSpace(c.did) == "" {
+	if c.signer == nil || strings.TrimSpace(c.did) == "" {
 		return errors.New("E2E messaging requires a local self-custodial signing key")
 	}
 	if c.e2eeEncryptionKey == nil {
@@ -331,14 +342,14 @@ func (c *Client) prepareE2EEChatCreate(ctx context.Context, payload *ChatCreateS
 		payload.SessionID = sessionID
 	}
 	now := time.Now().UTC().Truncate(time.Second)
-	envelope, err := EncryptE2EEChat(E2EEEncryptMessageParams{
+	envelope, err := EncryptE2EEChat(ctx, E2EEEncryptMessageParams{
 		Sender: E2EESenderKey{
 			Address:       c.e2eeAddress(),
 			DID:           c.did,
 			StableID:      c.stableID,
 			TeamID:        c.teamID,
 			EncryptionKey: c.e2eeEncryptionKey,
-			SigningKey:    c.signingKey,
+			Signer:        c.signer,
 		},
 		Recipients:       recipients,
 		Body:             payload.Message,
@@ -366,7 +377,7 @@ func (c *Client) prepareE2EEChatSend(ctx context.Context, sessionID string, payl
 	if c == nil || payload == nil {
 		return errors.New("aweb: request is required")
 	}
-	if c.signingKey == nil || strings.TrimSpace(c.did) == "" {
+	if c.signer == nil || strings.TrimSpace(c.did) == "" {
 		return errors.New("E2E messaging requires a local self-custodial signing key")
 	}
 	if c.e2eeEncryptionKey == nil {
@@ -384,14 +395,14 @@ func (c *Client) prepareE2EEChatSend(ctx context.Context, sessionID string, payl
 		messageID = strings.TrimSpace(payload.MessageID)
 	}
 	now := time.Now().UTC().Truncate(time.Second)
-	envelope, err := EncryptE2EEChat(E2EEEncryptMessageParams{
+	envelope, err := EncryptE2EEChat(ctx, E2EEEncryptMessageParams{
 		Sender: E2EESenderKey{
 			Address:       c.e2eeAddress(),
 			DID:           c.did,
 			StableID:      c.stableID,
 			TeamID:        c.teamID,
 			EncryptionKey: c.e2eeEncryptionKey,
-			SigningKey:    c.signingKey,
+			Signer:        c.signer,
 		},
 		Recipients:       recipients,
 		Body:             payload.Body,
@@ -857,6 +868,9 @@ func (c *Client) ChatStream(ctx context.Context, sessionID string, deadline time
 		// where sentAt and the signed timestamp land in the same second.
 		path += "&after=" + urlQueryEscape(after.Truncate(time.Second).Add(-time.Second).UTC().Format(time.RFC3339))
 	}
+	if err := c.checkGrantScope(http.MethodGet, path); err != nil {
+		return nil, err
+	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
 	if err != nil {
@@ -864,19 +878,25 @@ func (c *Client) ChatStream(ctx context.Context, sessionID string, deadline time
 	}
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
-	if c.teamCertHeader != "" && c.signingKey != nil {
+	if c.teamCertHeader != "" && c.signer != nil {
 		// Certificate auth: same DIDKey + cert headers as regular requests.
 		timestamp := time.Now().UTC().Format(time.RFC3339)
 		signPayload := certAuthSignPayload(c.teamID, timestamp, nil)
-		sig := ed25519.Sign(c.signingKey, signPayload)
-		req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, base64.RawStdEncoding.EncodeToString(sig)))
+		sig, err := c.sign(ctx, signPayload)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, sig))
 		req.Header.Set("X-AWEB-Timestamp", timestamp)
 		req.Header.Set("X-AWID-Team-Certificate", c.teamCertHeader)
-	} else if c.signingKey != nil {
+	} else if c.signer != nil {
 		timestamp := time.Now().UTC().Format(time.RFC3339)
 		signPayload := identityAuthSignPayload(c.stableID, timestamp, nil)
-		sig := ed25519.Sign(c.signingKey, signPayload)
-		req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, base64.RawStdEncoding.EncodeToString(sig)))
+		sig, err := c.sign(ctx, signPayload)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, sig))
 		req.Header.Set("X-AWEB-Timestamp", timestamp)
 		if c.stableID != "" {
 			req.Header.Set("X-AWEB-DID-AW", c.stableID)
@@ -919,6 +939,7 @@ type ChatSendMessageResponse struct {
 	MessageID          string `json:"message_id"`
 	Delivered          bool   `json:"delivered"`
 	ExtendsWaitSeconds int    `json:"extends_wait_seconds"`
+	Queued             bool   `json:"-"` // held in the outbox; not yet accepted by the server
 }
 
 func (c *Client) ChatSendMessage(ctx context.Context, sessionID string, req *ChatSendMessageRequest) (*ChatSendMessageResponse, error) {
@@ -930,11 +951,7 @@ func (c *Client) ChatSendMessage(ctx context.Context, sessionID string, req *Cha
 		if err := c.prepareE2EEChatSend(ctx, sessionID, &payload); err != nil {
 			return nil, err
 		}
-		var out ChatSendMessageResponse
-		if err := c.Post(ctx, "/v1/chat/sessions/"+urlPathEscape(sessionID)+"/messages", &payload, &out); err != nil {
-			return nil, err
-		}
-		return &out, nil
+		return c.postChatSend(ctx, sessionID, &payload)
 	}
 
 	// In-session messages: include deterministic To for signature verification.
@@ -942,7 +959,7 @@ func (c *Client) ChatSendMessage(ctx context.Context, sessionID string, req *Cha
 	to := ""
 	from := c.address
 	targetIsAddress := false
-	if c.signingKey != nil {
+	if c.signer != nil {
 		if toAddr, err := c.toAddressForSession(ctx, sessionID, false); err == nil {
 			to = toAddr
 		}
@@ -969,18 +986,34 @@ func (c *Client) ChatSendMessage(ctx context.Context, sessionID string, req *Cha
 	if err != nil {
 		return nil, err
 	}
-	if c.signingKey != nil {
+	if c.signer != nil {
 		payload.FromDID = sf.FromDID
 		payload.Signature = sf.Signature
 		payload.Timestamp = sf.Timestamp
 		payload.MessageID = sf.MessageID
 		payload.SignedPayload = sf.SignedPayload
 	}
+	return c.postChatSend(ctx, sessionID, &payload)
+}
 
+// postChatSend posts a prepared in-session message, queueing leaving
+// messages in the outbox when the server is unreachable.
+func (c *Client) postChatSend(ctx context.Context, sessionID string, payload *ChatSendMessageRequest) (*ChatSendMessageResponse, error) {
+	path := "/v1/chat/sessions/" + urlPathEscape(sessionID) + "/messages"
 	var out ChatSendMessageResponse
-	if err := c.Post(ctx, "/v1/chat/sessions/"+urlPathEscape(sessionID)+"/messages", &payload, &out); err != nil {
+	if !payload.Leaving {
+		if err := c.Post(ctx, path, payload, &out); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	}
+	queued, err := c.postOrQueue(ctx, OutboxKindChatSend, path, payload.MessageID, payload, &out)
+	if err != nil {
 		return nil, err
 	}
+	if queued {
+		return &ChatSendMessageResponse{MessageID: payload.MessageID, Queued: true}, nil
+	}
 	return &out, nil
 }
 
@@ -79,7 +79,7 @@ func isRegistryAddressNotFound(err error) bool {
 // returns a zero signedFields. Callers stamp the returned fields onto
 // the request struct before posting.
 func (c *Client) signEnvelope(ctx context.Context, env *MessageEnvelope) (signedFields, error) {
-	if c.signingKey == nil {
+	if c.signer == nil {
 		return signedFields{}, nil
 	}
 	if strings.TrimSpace(env.From) == "" {
@@ -142,7 +142,7 @@ func (c *Client) signEnvelope(ctx context.Context, env *MessageEnvelope) (signed
 		return signedFields{}, &RecipientResolutionError{Target: bindingTarget, MessageType: env.Type, Err: errors.New("missing current did:key")}
 	}
 
-	sig, err := SignMessage(c.signingKey, env)
+	sig, err := SignMessageWith(ctx, c.signer, env)
 	if err != nil {
 		return signedFields{}, fmt.Errorf("sign message: %w", err)
 	}
@@ -195,15 +195,17 @@ type agentMeta struct {
 type Client struct {
 	baseURL                 string
 	httpClient              *http.Client
-	sseClient               *http.Client       // No response timeout; SSE connections are long-lived.
-	signingKey              ed25519.PrivateKey // nil for legacy/custodial
-	did                     string             // empty for legacy/custodial
-	teamCertHeader          string             // base64-encoded team certificate for X-AWID-Team-Certificate
-	teamID                  string             // team identifier from certificate, used in auth signature
-	grantID                 string             // identity-grant id; non-empty selects grant auth with the session signing key
-	certAlias               string             // certificate alias, used for signed payloads in cert-auth mode
-	address                 string             // namespace/alias, used in signed envelopes
-	e2eeSenderAddress       string             // explicit address for E2EE envelopes; empty for addressless local/team identities
+	sseClient               *http.Client // No response timeout; SSE connections are long-lived.
+	signer                  Signer       // nil for legacy/custodial
+	did                     string       // empty for legacy/custodial
+	teamCertHeader          string       // base64-encoded team certificate for X-AWID-Team-Certificate
+	teamID                  string       // team identifier from certificate, used in auth signature
+	grantMu                 sync.RWMutex // guards grantID and grantScopes, which SwapGrant replaces on renewal
+	grantID                 string       // identity-grant id; non-empty selects grant auth with the session signing key
+	grantScopes             []string     // identity-grant scopes checked before a grant request is signed
+	certAlias               string       // certificate alias, used for signed payloads in cert-auth mode
+	address                 string       // namespace/alias, used in signed envelopes
+	e2eeSenderAddress       string       // explicit address for E2EE envelopes; empty for addressless local/team identities
 	e2eeSenderAddressSet    bool
 	stableID                string // did:aw:..., set on outgoing signed envelopes as from_stable_id
 	e2eeEncryptionKey       *EncryptionKeyAssertion
@@ -217,6 +219,7 @@ type Client struct {
 	pinStoreBaselineErr     error
 	pinStorePersister       func(path string, expectedYAML, desiredYAML []byte) error
 	pinMigrationObserver    func(PinMigrationDecline)
+	outbox                  Outbox       // optional; queues sends while the server is unreachable
 	metaCache               sync.Map     // address → *agentMeta; cached resolver results
 	latestClientVersion     atomic.Value // last seen X-Latest-Client-Version header (string)
 }
@@ -243,10 +246,18 @@ func NewWithIdentity(baseURL string, signingKey ed25519.PrivateKey, did string)
 	if signingKey == nil {
 		return nil, fmt.Errorf("signingKey must not be nil")
 	}
+	return NewWithIdentitySigner(baseURL, NewKeySigner(signingKey), did)
+}
+
+// NewWithIdentitySigner is NewWithIdentity for a key held by signer.
+func NewWithIdentitySigner(baseURL string, signer Signer, did string) (*Client, error) {
+	if signer == nil {
+		return nil, fmt.Errorf("signer must not be nil")
+	}
 	if did == "" {
 		return nil, fmt.Errorf("did must not be empty")
 	}
-	expected := ComputeDIDKey(signingKey.Public().(ed25519.PublicKey))
+	expected := ComputeDIDKey(signer.PublicKey())
 	if did != expected {
 		return nil, fmt.Errorf("did does not match signingKey")
 	}
@@ -254,7 +265,7 @@ func NewWithIdentity(baseURL string, signingKey ed25519.PrivateKey, did string)
 	if err != nil {
 		return nil, err
 	}
-	c.signingKey = signingKey
+	c.signer = signer
 	c.did = did
 	return c, nil
 }
@@ -265,10 +276,19 @@ func NewWithCertificate(baseURL string, signingKey ed25519.PrivateKey, cert *Tea
 	if signingKey == nil {
 		return nil, fmt.Errorf("signingKey must not be nil")
 	}
+	return NewWithCertificateSigner(baseURL, NewKeySigner(signingKey), cert)
+}
+
+// NewWithCertificateSigner is NewWithCertificate for a key held by signer,
+// such as an ssh-agent or an external signing command.
+func NewWithCertificateSigner(baseURL string, signer Signer, cert *TeamCertificate) (*Client, error) {
+	if signer == nil {
+		return nil, fmt.Errorf("signer must not be nil")
+	}
 	if cert == nil {
 		return nil, fmt.Errorf("certificate must not be nil")
 	}
-	did := ComputeDIDKey(signingKey.Public().(ed25519.PublicKey))
+	did := ComputeDIDKey(signer.PublicKey())
 	if did != cert.MemberDIDKey {
 		return nil, fmt.Errorf("signing key did:key %s does not match certificate member_did_key %s", did, cert.MemberDIDKey)
 	}
@@ -280,7 +300,7 @@ func NewWithCertificate(baseURL string, signingKey ed25519.PrivateKey, cert *Tea
 	if err != nil {
 		return nil, err
 	}
-	c.signingKey = signingKey
+	c.signer = signer
 	c.did = did
 	c.teamCertHeader = certHeader
 	c.teamID = cert.Team
@@ -295,6 +315,14 @@ func NewWithGrant(baseURL string, sessionKey ed25519.PrivateKey, grantID string)
 	if sessionKey == nil {
 		return nil, fmt.Errorf("sessionKey must not be nil")
 	}
+	return NewWithGrantSigner(baseURL, NewKeySigner(sessionKey), grantID)
+}
+
+// NewWithGrantSigner is NewWithGrant for a session key held by signer.
+func NewWithGrantSigner(baseURL string, signer Signer, grantID string) (*Client, error) {
+	if signer == nil {
+		return nil, fmt.Errorf("signer must not be nil")
+	}
 	grantID = strings.TrimSpace(grantID)
 	if grantID == "" {
 		return nil, fmt.Errorf("grantID must not be empty")
@@ -303,8 +331,8 @@ func NewWithGrant(baseURL string, sessionKey ed25519.PrivateKey, grantID string)
 	if err != nil {
 		return nil, err
 	}
-	c.signingKey = sessionKey
-	c.did = ComputeDIDKey(sessionKey.Public().(ed25519.PublicKey))
+	c.signer = signer
+	c.did = ComputeDIDKey(signer.PublicKey())
 	c.grantID = grantID
 	return c, nil
 }
@@ -314,6 +342,8 @@ func (c *Client) GrantID() string {
 	if c == nil {
 		return ""
 	}
+	c.grantMu.RLock()
+	defer c.grantMu.RUnlock()
 	return strings.TrimSpace(c.grantID)
 }
 
@@ -348,8 +378,22 @@ func (c *Client) HTTPClient() *http.Client { return c.httpClient }
 // SSEClient returns the HTTP client used for SSE requests.
 func (c *Client) SSEClient() *http.Client { return c.sseClient }
 
-// SigningKey returns the client's signing key, or nil for legacy/custodial clients.
-func (c *Client) SigningKey() ed25519.PrivateKey { return c.signingKey }
+// SigningKey returns the client's in-memory signing key, or nil for
+// legacy/custodial clients and clients whose key is held by an external signer.
+func (c *Client) SigningKey() ed25519.PrivateKey { return signerPrivateKey(c.signer) }
+
+// Signer returns the client's signer, or nil for legacy/custodial clients.
+func (c *Client) Signer() Signer { return c.signer }
+
+// sign signs payload with the client's key and returns the raw signature
+// base64-encoded (RFC 4648, no padding).
+func (c *Client) sign(ctx context.Context, payload []byte) (string, error) {
+	sig, err := c.signer.Sign(ctx, payload)
+	if err != nil {
+		return "", fmt.Errorf("sign: %w", err)
+	}
+	return base64.RawStdEncoding.EncodeToString(sig), nil
+}
 
 // DID returns the client's DID, or empty for legacy/custodial clients.
 func (c *Client) DID() string { return c.did }
@@ -386,7 +430,7 @@ func (c *Client) addressAlias() string {
 
 func (c *Client) signedPayloadFrom(identityTarget, preferAlias bool) string {
 	from := strings.TrimSpace(c.address)
-	if c.signingKey == nil {
+	if c.signer == nil {
 		return from
 	}
 	if identityTarget {
@@ -442,7 +486,7 @@ func (c *Client) Resolver() IdentityResolver { return c.resolver }
 // team certificate — the credential TeamRosterResolver requires for
 // certificate-authenticated roster reads.
 func (c *Client) HasTeamCertificateAuth() bool {
-	return c != nil && strings.TrimSpace(c.teamCertHeader) != "" && len(c.signingKey) != 0
+	return c != nil && strings.TrimSpace(c.teamCertHeader) != "" && c.signer != nil
 }
 
 func (c *Client) SetE2EEKey(assertion *EncryptionKeyAssertion, privateKey *ecdh.PrivateKey) {
@@ -1213,6 +1257,9 @@ func (c *Client) DoRaw(ctx context.Context, method, path, accept string, in any)
 
 // DoRawWithHeaders performs an HTTP request and returns the raw response.
 func (c *Client) DoRawWithHeaders(ctx context.Context, method, path, accept string, in any, extraHeaders map[string]string) (*http.Response, error) {
+	if err := c.checkGrantScope(method, path); err != nil {
+		return nil, err
+	}
 	var bodyBytes []byte
 	if in != nil {
 		data, err := json.Marshal(in)
@@ -1245,33 +1292,37 @@ func (c *Client) DoRawWithHeaders(ctx context.Context, method, path, accept stri
 				req.Header.Set(key, value)
 			}
 		}
-		if c.grantID != "" && c.signingKey != nil {
+		if grantID := c.GrantID(); grantID != "" && c.signer != nil {
 			// Grant auth: session did:key signature over the identity-grant
 			// envelope. aud, method, path, and body_sha256 bind the request to
 			// the grant, mirroring the v2 team envelope canonicalization.
 			timestamp := time.Now().UTC().Format(time.RFC3339)
-			credential, err := SignIdentityGrantCredential(c.signingKey, method, req.URL, c.grantID, bodyBytes, timestamp)
+			credential, err := SignIdentityGrantCredentialWith(ctx, c.signer, method, req.URL, grantID, bodyBytes, timestamp)
 			if err != nil {
 				return nil, err
 			}
 			for key := range credential.Headers {
 				req.Header.Set(key, credential.Headers.Get(key))
 			}
-		} else if c.teamCertHeader != "" && c.signingKey != nil {
+		} else if c.teamCertHeader != "" && c.signer != nil {
 			// Certificate auth: DIDKey signature over {body_sha256, team_id, timestamp}.
 			// body_sha256 binds the request body to the signature without the
 			// server having to consume the body stream for signature verification.
 			timestamp := time.Now().UTC().Format(time.RFC3339)
-			signPayload := certAuthSignPayload(c.teamID, timestamp, bodyBytes)
-			sig := ed25519.Sign(c.signingKey, signPayload)
-			req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, base64.RawStdEncoding.EncodeToString(sig)))
+			sig, err := c.sign(ctx, certAuthSignPayload(c.teamID, timestamp, bodyBytes))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, sig))
 			req.Header.Set("X-AWEB-Timestamp", timestamp)
 			req.Header.Set("X-AWID-Team-Certificate", c.teamCertHeader)
-		} else if c.signingKey != nil {
+		} else if c.signer != nil {
 			timestamp := time.Now().UTC().Format(time.RFC3339)
-			signPayload := identityAuthSignPayload(c.stableID, timestamp, bodyBytes)
-			sig := ed25519.Sign(c.signingKey, signPayload)
-			req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, base64.RawStdEncoding.EncodeToString(sig)))
+			sig, err := c.sign(ctx, identityAuthSignPayload(c.stableID, timestamp, bodyBytes))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, sig))
 			req.Header.Set("X-AWEB-Timestamp", timestamp)
 			if c.stableID != "" {
 				req.Header.Set("X-AWEB-DID-AW", c.stableID)
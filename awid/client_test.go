@@ -854,7 +854,7 @@ func TestMailE2EEConversationReplyLearnsLocalOnlySenderKey(t *testing.T) {
 
 	self := newE2EETestLocalIdentity(t)
 	remote := newE2EETestLocalIdentity(t)
-	env, err := EncryptE2EEMail(E2EEEncryptMailParams{
+	env, err := EncryptE2EEMail(context.Background(), E2EEEncryptMailParams{
 		Sender: E2EESenderKey{
 			DID:           remote.did,
 			EncryptionKey: remote.assertion,
@@ -947,7 +947,7 @@ func TestChatE2EEContinuationLearnsLocalOnlySenderKey(t *testing.T) {
 
 	self := newE2EETestLocalIdentity(t)
 	remote := newE2EETestLocalIdentity(t)
-	env, err := EncryptE2EEChat(E2EEEncryptMessageParams{
+	env, err := EncryptE2EEChat(context.Background(), E2EEEncryptMessageParams{
 		Sender: E2EESenderKey{
 			DID:           remote.did,
 			EncryptionKey: remote.assertion,
@@ -1,6 +1,8 @@
 package awid
 
 import (
+	"context"
+	"crypto"
 	"crypto/ecdh"
 	"crypto/ed25519"
 	"crypto/rand"
@@ -119,21 +121,22 @@ func LoadX25519PrivateKey(path string) (*ecdh.PrivateKey, error) {
 }
 
 func BuildEncryptionKeyAssertion(
-	signingKey ed25519.PrivateKey,
+	signingKey crypto.Signer,
 	identityDID string,
 	identityStableID string,
 	rawPublicKey []byte,
 	previousEncryptionKeyID string,
 	now time.Time,
 ) (*EncryptionKeyAssertion, error) {
-	if signingKey == nil {
+	pub := signingKeyPublic(signingKey)
+	if pub == nil {
 		return nil, fmt.Errorf("signing key is required")
 	}
 	identityDID = strings.TrimSpace(identityDID)
 	if identityDID == "" {
 		return nil, fmt.Errorf("identity did:key is required")
 	}
-	if got := ComputeDIDKey(signingKey.Public().(ed25519.PublicKey)); got != identityDID {
+	if got := ComputeDIDKey(pub); got != identityDID {
 		return nil, fmt.Errorf("identity did:key %s does not match signing key %s", identityDID, got)
 	}
 	if now.IsZero() {
@@ -170,18 +173,22 @@ func BuildEncryptionKeyAssertion(
 	return assertion, nil
 }
 
-func SignEncryptionKeyAssertion(assertion *EncryptionKeyAssertion, signingKey ed25519.PrivateKey) error {
+func SignEncryptionKeyAssertion(assertion *EncryptionKeyAssertion, signingKey crypto.Signer) error {
 	if assertion == nil {
 		return fmt.Errorf("missing encryption key assertion")
 	}
-	if signingKey == nil {
+	if signingKeyPublic(signingKey) == nil {
 		return fmt.Errorf("signing key is required")
 	}
 	payload, err := encryptionAssertionSignedPayload(assertion)
 	if err != nil {
 		return err
 	}
-	assertion.Signature = base64.RawStdEncoding.EncodeToString(ed25519.Sign(signingKey, []byte(payload)))
+	sig, err := signWithKey(context.Background(), signingKey, []byte(payload))
+	if err != nil {
+		return err
+	}
+	assertion.Signature = base64.RawStdEncoding.EncodeToString(sig)
 	return nil
 }
 
@@ -2,6 +2,7 @@ package awid
 
 import (
 	"bytes"
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/ecdh"
@@ -132,6 +133,16 @@ type E2EESenderKey struct {
 	TeamID        string
 	EncryptionKey *EncryptionKeyAssertion
 	SigningKey    ed25519.PrivateKey
+	// Signer signs instead of SigningKey when the key is held outside the
+	// process.
+	Signer Signer
+}
+
+func (k E2EESenderKey) signer() Signer {
+	if k.SigningKey != nil {
+		return NewKeySigner(k.SigningKey)
+	}
+	return k.Signer
 }
 
 type E2EEEncryptMessageParams struct {
@@ -182,24 +193,26 @@ func (c *Client) DecryptE2EEEnvelope(envelope *E2EEMessageEnvelope) (*E2EEInnerP
 	})
 }
 
-func EncryptE2EEMail(params E2EEEncryptMailParams) (*E2EEMessageEnvelope, error) {
+func EncryptE2EEMail(ctx context.Context, params E2EEEncryptMailParams) (*E2EEMessageEnvelope, error) {
 	params.Kind = "mail"
 	if len(params.Recipients) != 1 {
 		return nil, fmt.Errorf("E2E mail requires exactly one delivery recipient")
 	}
-	return EncryptE2EEMessage(params)
+	return EncryptE2EEMessage(ctx, params)
 }
 
-func EncryptE2EEChat(params E2EEEncryptMessageParams) (*E2EEMessageEnvelope, error) {
+func EncryptE2EEChat(ctx context.Context, params E2EEEncryptMessageParams) (*E2EEMessageEnvelope, error) {
 	params.Kind = "chat"
 	params.Subject = ""
 	if len(params.Recipients) == 0 {
 		return nil, fmt.Errorf("E2E chat requires at least one delivery recipient")
 	}
-	return EncryptE2EEMessage(params)
+	return EncryptE2EEMessage(ctx, params)
 }
 
-func EncryptE2EEMessage(params E2EEEncryptMessageParams) (*E2EEMessageEnvelope, error) {
+// EncryptE2EEMessage seals a message for its recipients and signs the
+// envelope; ctx bounds the signer, which may be an external process or agent.
+func EncryptE2EEMessage(ctx context.Context, params E2EEEncryptMessageParams) (*E2EEMessageEnvelope, error) {
 	kind := strings.TrimSpace(params.Kind)
 	if kind == "" {
 		kind = "mail"
@@ -213,7 +226,8 @@ func EncryptE2EEMessage(params E2EEEncryptMessageParams) (*E2EEMessageEnvelope,
 	if strings.TrimSpace(params.ConversationID) == "" {
 		return nil, fmt.Errorf("conversation_id is required")
 	}
-	if params.Sender.SigningKey == nil {
+	signer := params.Sender.signer()
+	if signer == nil {
 		return nil, fmt.Errorf("sender signing key is required")
 	}
 	if params.Sender.EncryptionKey == nil {
@@ -237,7 +251,7 @@ func EncryptE2EEMessage(params E2EEEncryptMessageParams) (*E2EEMessageEnvelope,
 	if from.DID == "" || from.EncryptionKeyID == "" {
 		return nil, fmt.Errorf("sender did and encryption key id are required")
 	}
-	if got := ComputeDIDKey(params.Sender.SigningKey.Public().(ed25519.PublicKey)); got != from.DID {
+	if got := ComputeDIDKey(signer.PublicKey()); got != from.DID {
 		return nil, fmt.Errorf("sender signing key does not match sender did")
 	}
 	if err := VerifyEncryptionKeyAssertion(params.Sender.EncryptionKey, from.DID, from.StableID, createdAt); err != nil {
@@ -377,7 +391,11 @@ func EncryptE2EEMessage(params E2EEEncryptMessageParams) (*E2EEMessageEnvelope,
 	if err != nil {
 		return nil, err
 	}
-	envelope.Signature = base64.RawStdEncoding.EncodeToString(ed25519.Sign(params.Sender.SigningKey, []byte(signedPayload)))
+	sig, err := signer.Sign(ctx, []byte(signedPayload))
+	if err != nil {
+		return nil, fmt.Errorf("sign envelope: %w", err)
+	}
+	envelope.Signature = base64.RawStdEncoding.EncodeToString(sig)
 	return envelope, nil
 }
 
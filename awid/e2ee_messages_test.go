@@ -78,7 +78,7 @@ func TestE2EEMailEncryptDecryptRecipientAndSenderCopy(t *testing.T) {
 	alice := newE2EETestIdentity(t, "example.com/alice")
 	bob := newE2EETestIdentity(t, "example.com/bob")
 
-	env, err := EncryptE2EEMail(E2EEEncryptMailParams{
+	env, err := EncryptE2EEMail(context.Background(), E2EEEncryptMailParams{
 		Sender: E2EESenderKey{
 			Address:       alice.address,
 			DID:           alice.did,
@@ -146,7 +146,7 @@ func TestE2EERecipientFromEnvelopeSenderRequiresMatchingAssertion(t *testing.T)
 	alice := newE2EETestLocalIdentity(t)
 	bob := newE2EETestLocalIdentity(t)
 
-	env, err := EncryptE2EEMail(E2EEEncryptMailParams{
+	env, err := EncryptE2EEMail(context.Background(), E2EEEncryptMailParams{
 		Sender: E2EESenderKey{
 			DID:           alice.did,
 			EncryptionKey: alice.assertion,
@@ -204,7 +204,7 @@ func TestE2EEChatEncryptDecryptGroupAndSenderCopy(t *testing.T) {
 	bob := newE2EETestIdentity(t, "example.com/bob")
 	carol := newE2EETestIdentity(t, "example.com/carol")
 
-	env, err := EncryptE2EEChat(E2EEEncryptMessageParams{
+	env, err := EncryptE2EEChat(context.Background(), E2EEEncryptMessageParams{
 		Sender: E2EESenderKey{
 			Address:       alice.address,
 			DID:           alice.did,
@@ -263,7 +263,7 @@ func TestE2EEChatMembershipChangesAreFutureOnly(t *testing.T) {
 				EncryptionKey: recipient.assertion,
 			})
 		}
-		env, err := EncryptE2EEChat(E2EEEncryptMessageParams{
+		env, err := EncryptE2EEChat(context.Background(), E2EEEncryptMessageParams{
 			Sender: E2EESenderKey{
 				Address:       alice.address,
 				DID:           alice.did,
@@ -325,7 +325,7 @@ func TestE2EEMailDecryptRejectsNonRecipientAndTamper(t *testing.T) {
 	bob := newE2EETestIdentity(t, "example.com/bob")
 	carol := newE2EETestIdentity(t, "example.com/carol")
 
-	env, err := EncryptE2EEMail(E2EEEncryptMailParams{
+	env, err := EncryptE2EEMail(context.Background(), E2EEEncryptMailParams{
 		Sender: E2EESenderKey{
 			Address:       alice.address,
 			DID:           alice.did,
@@ -379,7 +379,7 @@ func TestE2EEMailEncryptRejectsSubstitutedRecipientAssertion(t *testing.T) {
 	bob := newE2EETestIdentity(t, "example.com/bob")
 	carol := newE2EETestIdentity(t, "example.com/carol")
 
-	_, err := EncryptE2EEMail(E2EEEncryptMailParams{
+	_, err := EncryptE2EEMail(context.Background(), E2EEEncryptMailParams{
 		Sender: E2EESenderKey{
 			Address:       alice.address,
 			DID:           alice.did,
@@ -626,7 +626,7 @@ func TestClientChatCreateSessionE2EEPostsOpaqueEnvelopeOnly(t *testing.T) {
 
 func encryptE2EETestMessage(t *testing.T, alice, bob e2eeTestIdentity, messageID, conversationID string) *E2EEMessageEnvelope {
 	t.Helper()
-	env, err := EncryptE2EEMail(E2EEEncryptMailParams{
+	env, err := EncryptE2EEMail(context.Background(), E2EEEncryptMailParams{
 		Sender: E2EESenderKey{
 			Address:       alice.address,
 			DID:           alice.did,
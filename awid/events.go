@@ -3,8 +3,6 @@ package awid
 import (
 	"bytes"
 	"context"
-	"crypto/ed25519"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -31,6 +29,10 @@ const (
 	// AgentEventChannelReconnected is synthesized by the local run loop after
 	// recovering its SSE connection; it is never accepted from the server wire.
 	AgentEventChannelReconnected AgentEventType = "channel_reconnected"
+	// AgentEventLeaseLost is synthesized by the local run loop when another
+	// session takes its session admission lease; it is never accepted from
+	// the server wire.
+	AgentEventLeaseLost AgentEventType = "session_lease_lost"
 )
 
 // AgentEvent is a typed event emitted by GET /v1/events/stream.
@@ -66,6 +68,14 @@ type AgentEvent struct {
 	ProducerIntent string          `json:"producer_delivery_intent,omitempty"`
 	Payload        map[string]any  `json:"payload,omitempty"`
 	Text           string          `json:"text,omitempty"`
+	// Cursor is the SSE id of the stream event, or the last id before it.
+	Cursor string `json:"cursor,omitempty"`
+	// Resumed is set on a connected event when the server honoured
+	// Last-Event-ID and will replay what was sent after it, reported as
+	// "resumed": true in the connected payload. A server that does not
+	// replay omits the field, and callers must then treat the stream as
+	// not resumed and rebuild the gap themselves.
+	Resumed bool `json:"resumed,omitempty"`
 }
 
 func (e AgentEvent) IsActionableCoordination() bool {
@@ -117,13 +127,30 @@ func (s *AgentEventStream) Next(_ context.Context) (*AgentEvent, error) {
 		if !ok {
 			continue
 		}
+		out.Cursor = s.sse.LastEventID()
 		return &out, nil
 	}
 }
 
+// LastEventID returns the id of the last event read from the stream.
+func (s *AgentEventStream) LastEventID() string {
+	if s == nil || s.sse == nil {
+		return ""
+	}
+	return s.sse.LastEventID()
+}
+
 // EventStream opens GET /v1/events/stream using the active client auth.
 // deadline is sent as an ISO8601/RFC3339 timestamp because the server expects an absolute time.
 func (c *Client) EventStream(ctx context.Context, deadline time.Time) (*AgentEventStream, error) {
+	return c.EventStreamFrom(ctx, deadline, "")
+}
+
+// EventStreamFrom opens the event stream resuming after lastEventID, sent
+// as Last-Event-ID. The connected event reports Resumed only when its
+// payload carries "resumed": true; without it, events sent in between are
+// lost from the stream, so servers that predate replay are handled safely.
+func (c *Client) EventStreamFrom(ctx context.Context, deadline time.Time, lastEventID string) (*AgentEventStream, error) {
 	path := "/v1/events/stream?deadline=" + urlQueryEscape(deadline.UTC().Format(time.RFC3339))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
@@ -132,18 +159,27 @@ func (c *Client) EventStream(ctx context.Context, deadline time.Time) (*AgentEve
 	}
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
-	if c.teamCertHeader != "" && c.signingKey != nil {
+	if lastEventID = strings.TrimSpace(lastEventID); lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	if c.teamCertHeader != "" && c.signer != nil {
 		timestamp := time.Now().UTC().Format(time.RFC3339)
 		sigPayload := certAuthSignPayload(c.teamID, timestamp, nil)
-		sig := ed25519.Sign(c.signingKey, sigPayload)
-		req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, base64.RawStdEncoding.EncodeToString(sig)))
+		sig, err := c.sign(ctx, sigPayload)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, sig))
 		req.Header.Set("X-AWEB-Timestamp", timestamp)
 		req.Header.Set("X-AWID-Team-Certificate", c.teamCertHeader)
-	} else if c.signingKey != nil {
+	} else if c.signer != nil {
 		timestamp := time.Now().UTC().Format(time.RFC3339)
 		signPayload := identityAuthSignPayload(c.stableID, timestamp, nil)
-		sig := ed25519.Sign(c.signingKey, signPayload)
-		req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, base64.RawStdEncoding.EncodeToString(sig)))
+		sig, err := c.sign(ctx, signPayload)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, sig))
 		req.Header.Set("X-AWEB-Timestamp", timestamp)
 		if c.stableID != "" {
 			req.Header.Set("X-AWEB-DID-AW", c.stableID)
@@ -179,6 +215,7 @@ func parseAgentEvent(eventName, data string) (AgentEvent, bool, error) {
 		var payload struct {
 			AgentID string `json:"agent_id"`
 			TeamID  string `json:"team_id"`
+			Resumed bool   `json:"resumed"`
 		}
 		if err := json.Unmarshal(raw, &payload); err != nil {
 			return AgentEvent{}, false, fmt.Errorf("parse connected event: %w", err)
@@ -188,6 +225,7 @@ func parseAgentEvent(eventName, data string) (AgentEvent, bool, error) {
 			Raw:     raw,
 			AgentID: payload.AgentID,
 			TeamID:  payload.TeamID,
+			Resumed: payload.Resumed,
 		}, true, nil
 
 	case AgentEventActionableMail:
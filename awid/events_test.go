@@ -408,3 +408,47 @@ func TestEventStreamReturnsEOFOnCleanClose(t *testing.T) {
 		t.Fatalf("expected io.EOF, got %v", err)
 	}
 }
+
+func TestEventStreamFromSendsLastEventIDAndReportsResume(t *testing.T) {
+	t.Parallel()
+
+	var gotLastEventID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotLastEventID = r.Header.Get("Last-Event-ID")
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "event: connected\n")
+		_, _ = io.WriteString(w, "data: {\"agent_id\":\"a1\",\"resumed\":true}\n\n")
+		_, _ = io.WriteString(w, "id: evt-43\n")
+		_, _ = io.WriteString(w, "event: actionable_mail\n")
+		_, _ = io.WriteString(w, "data: {\"message_id\":\"m1\"}\n\n")
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := c.EventStreamFrom(context.Background(), time.Now().Add(time.Minute), "evt-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	if gotLastEventID != "evt-42" {
+		t.Fatalf("Last-Event-ID=%q", gotLastEventID)
+	}
+	ev, err := stream.Next(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != AgentEventConnected || !ev.Resumed || ev.Cursor != "" {
+		t.Fatalf("connected event=%#v", ev)
+	}
+	ev, err = stream.Next(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.MessageID != "m1" || ev.Cursor != "evt-43" || stream.LastEventID() != "evt-43" {
+		t.Fatalf("mail event=%#v", ev)
+	}
+}
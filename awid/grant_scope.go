@@ -0,0 +1,107 @@
+package awid
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Identity-grant scopes, as accepted by the aweb server's /v1/identity-grants
+// API (aweb server 1.27.2 and later). A grant client carries the scopes it
+// was minted with and refuses a request outside them before signing it; the
+// server checks the grant again and stays authoritative.
+const (
+	GrantScopeMailRead = "mail.read"
+	GrantScopeMailSend = "mail.send"
+	GrantScopeChatRead = "chat.read"
+	GrantScopeChatSend = "chat.send"
+)
+
+// GrantScopeError reports a request the client's identity grant does not
+// cover. Nothing was sent.
+type GrantScopeError struct {
+	GrantID string
+	Scope   string
+	Method  string
+	Path    string
+}
+
+func (e *GrantScopeError) Error() string {
+	return fmt.Sprintf("identity grant %s does not include the %s scope required for %s %s", e.GrantID, e.Scope, e.Method, e.Path)
+}
+
+// RequiredGrantScope returns the grant scope a request needs, or "" for a
+// route this client does not check. It is the client's copy of the server's
+// scope rules for mail and chat, used only to fail before signing: reads,
+// including acknowledging and marking messages read, need the read scope;
+// everything else on those routes needs the send scope. Other routes, such
+// as tasks, locks, A2A and identity, are deliberately left unmapped and go
+// to the server, which rejects them if the grant does not cover them.
+func RequiredGrantScope(method, path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	read := method == http.MethodGet || method == http.MethodHead
+	switch {
+	case path == "/v1/messages" || strings.HasPrefix(path, "/v1/messages/") || path == "/v1/conversations":
+		if read || strings.HasSuffix(path, "/ack") {
+			return GrantScopeMailRead
+		}
+		return GrantScopeMailSend
+	case strings.HasPrefix(path, "/v1/chat/"):
+		if read || strings.HasSuffix(path, "/read") {
+			return GrantScopeChatRead
+		}
+		return GrantScopeChatSend
+	}
+	return ""
+}
+
+// SetGrantScopes records the scopes of the client's identity grant. An
+// empty list leaves scope checks to the server.
+func (c *Client) SetGrantScopes(scopes []string) {
+	c.grantMu.Lock()
+	defer c.grantMu.Unlock()
+	c.grantScopes = slices.Clone(scopes)
+}
+
+// SwapGrant replaces the identity grant a grant client signs with, for a
+// long-running command whose grant was renewed. The session key stays the
+// same; requests already signed keep the previous grant id. Clients that
+// are not grant clients are left alone.
+func (c *Client) SwapGrant(grantID string, scopes []string) {
+	grantID = strings.TrimSpace(grantID)
+	if c == nil || grantID == "" {
+		return
+	}
+	c.grantMu.Lock()
+	defer c.grantMu.Unlock()
+	if c.grantID == "" {
+		return
+	}
+	c.grantID = grantID
+	c.grantScopes = slices.Clone(scopes)
+}
+
+// GrantScopes returns the scopes set with SetGrantScopes.
+func (c *Client) GrantScopes() []string {
+	if c == nil {
+		return nil
+	}
+	c.grantMu.RLock()
+	defer c.grantMu.RUnlock()
+	return slices.Clone(c.grantScopes)
+}
+
+func (c *Client) checkGrantScope(method, path string) error {
+	c.grantMu.RLock()
+	defer c.grantMu.RUnlock()
+	if c.grantID == "" || len(c.grantScopes) == 0 {
+		return nil
+	}
+	scope := RequiredGrantScope(method, path)
+	if scope == "" || slices.Contains(c.grantScopes, scope) {
+		return nil
+	}
+	path, _, _ = strings.Cut(path, "?")
+	return &GrantScopeError{GrantID: c.grantID, Scope: scope, Method: method, Path: path}
+}
@@ -0,0 +1,105 @@
+package awid
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRequiredGrantScope(t *testing.T) {
+	for _, tc := range []struct {
+		method, path, want string
+	}{
+		{http.MethodGet, "/v1/messages/inbox?unread_only=true", GrantScopeMailRead},
+		{http.MethodPost, "/v1/messages/m-1/ack", GrantScopeMailRead},
+		{http.MethodPost, "/v1/messages", GrantScopeMailSend},
+		{http.MethodGet, "/v1/conversations", GrantScopeMailRead},
+		{http.MethodGet, "/v1/chat/pending", GrantScopeChatRead},
+		{http.MethodPost, "/v1/chat/sessions/s-1/read", GrantScopeChatRead},
+		{http.MethodGet, "/v1/chat/sessions/s-1/stream?deadline=x", GrantScopeChatRead},
+		{http.MethodPost, "/v1/chat/sessions", GrantScopeChatSend},
+		{http.MethodPost, "/v1/chat/sessions/s-1/messages", GrantScopeChatSend},
+		{http.MethodGet, "/v1/agents", ""},
+		{http.MethodGet, "/v1/events/stream", ""},
+	} {
+		if got := RequiredGrantScope(tc.method, tc.path); got != tc.want {
+			t.Errorf("RequiredGrantScope(%s, %s)=%q, want %q", tc.method, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestGrantClientRefusesOutOfScopeRequestBeforeSending(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"messages":[]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, sessionKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewWithGrant(server.URL, sessionKey, "grant-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetGrantScopes([]string{GrantScopeMailRead})
+
+	if _, err := c.Inbox(context.Background(), InboxParams{}); err != nil {
+		t.Fatalf("in-scope inbox: %v", err)
+	}
+	err = c.Post(context.Background(), "/v1/messages", map[string]string{"body": "hi"}, nil)
+	var scopeErr *GrantScopeError
+	if !errors.As(err, &scopeErr) || scopeErr.Scope != GrantScopeMailSend || scopeErr.GrantID != "grant-1" {
+		t.Fatalf("send error=%v, want grant scope error", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("server saw %d requests, want only the in-scope one", n)
+	}
+}
+
+func TestSwapGrantSignsLaterRequestsWithRenewedGrant(t *testing.T) {
+	var grantIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		grantIDs = append(grantIDs, r.Header.Get("X-AWEB-Grant-ID"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"messages":[]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, sessionKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewWithGrant(server.URL, sessionKey, "grant-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetGrantScopes([]string{GrantScopeMailRead})
+	if _, err := c.Inbox(context.Background(), InboxParams{}); err != nil {
+		t.Fatal(err)
+	}
+	c.SwapGrant("grant-2", []string{GrantScopeMailRead, GrantScopeMailSend})
+	if err := c.Post(context.Background(), "/v1/messages", map[string]string{"body": "hi"}, nil); err != nil {
+		t.Fatalf("send after swap: %v", err)
+	}
+	if c.GrantID() != "grant-2" || len(grantIDs) != 2 || grantIDs[0] != "grant-1" || grantIDs[1] != "grant-2" {
+		t.Fatalf("grant=%q requests=%v", c.GrantID(), grantIDs)
+	}
+
+	plain, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain.SwapGrant("grant-2", nil)
+	if plain.GrantID() != "" {
+		t.Fatalf("non-grant client took grant %q", plain.GrantID())
+	}
+}
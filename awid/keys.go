@@ -83,7 +83,8 @@ func SaveSigningKeyExclusive(path string, priv ed25519.PrivateKey) error {
 	return nil
 }
 
-// LoadSigningKey reads an Ed25519 private key from a PEM file.
+// LoadSigningKey reads an Ed25519 private key from a PEM file. Encrypted
+// keys are unlocked through the source set with SetKeyPassphraseSource.
 func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
 	if err := preflightSigningKeyPath(path); err != nil {
 		return nil, err
@@ -96,6 +97,9 @@ func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
 	if block == nil {
 		return nil, fmt.Errorf("no PEM block in %s", path)
 	}
+	if block.Type == EncryptedPrivateKeyPEMType {
+		return UnlockPrivateKeyBlock(block, path)
+	}
 	if block.Type != "ED25519 PRIVATE KEY" {
 		return nil, fmt.Errorf("unexpected PEM type %q in %s", block.Type, path)
 	}
@@ -0,0 +1,327 @@
+package awid
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/awebai/aw/internal/crashtest"
+	"golang.org/x/crypto/scrypt"
+)
+
+// EncryptedPrivateKeyPEMType is the PEM type of a passphrase-encrypted
+// Ed25519 seed. The scrypt parameters, salt and GCM nonce travel in PEM
+// headers; the block bytes are the sealed seed.
+const EncryptedPrivateKeyPEMType = "AW ENCRYPTED ED25519 PRIVATE KEY"
+
+const (
+	encryptedKeyKDF    = "scrypt"
+	encryptedKeyCipher = "AES-256-GCM"
+	encryptedKeyR      = 8
+	encryptedKeyP      = 1
+)
+
+// encryptedKeyScryptN is the scrypt cost for newly encrypted keys (~32 MiB).
+// Tests lower it; decryption always uses the cost recorded in the file.
+var encryptedKeyScryptN = 1 << 15
+
+var (
+	// ErrSigningKeyLocked is returned when a key is encrypted and no
+	// passphrase source is configured or the source has no passphrase.
+	ErrSigningKeyLocked = errors.New("private key is encrypted and no passphrase is available")
+	// ErrWrongPassphrase is returned when a passphrase does not open a key.
+	ErrWrongPassphrase = errors.New("wrong passphrase for encrypted private key")
+)
+
+// KeyPassphraseFunc returns the passphrase for the encrypted key at path.
+// It returns ErrSigningKeyLocked when it has none to offer.
+type KeyPassphraseFunc func(path string) ([]byte, error)
+
+var keyPassphrases struct {
+	mu     sync.Mutex
+	source KeyPassphraseFunc
+	opened [][]byte
+}
+
+// SetKeyPassphraseSource installs the process-wide source used to unlock
+// encrypted keys on load. Passphrases that opened a key are remembered for
+// the life of the process, so one prompt unlocks every key sharing it.
+func SetKeyPassphraseSource(fn KeyPassphraseFunc) {
+	keyPassphrases.mu.Lock()
+	defer keyPassphrases.mu.Unlock()
+	keyPassphrases.source = fn
+	keyPassphrases.opened = nil
+}
+
+// EncryptPrivateKeyPEM seals priv's seed under passphrase.
+func EncryptPrivateKeyPEM(priv ed25519.PrivateKey, passphrase []byte) ([]byte, error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid ed25519 private key")
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New("empty passphrase")
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := encryptedKeyAEAD(passphrase, salt, encryptedKeyScryptN, encryptedKeyR, encryptedKeyP)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{
+		Type: EncryptedPrivateKeyPEMType,
+		Headers: map[string]string{
+			"Kdf":        encryptedKeyKDF,
+			"Kdf-Params": fmt.Sprintf("N=%d,r=%d,p=%d", encryptedKeyScryptN, encryptedKeyR, encryptedKeyP),
+			"Salt":       base64.StdEncoding.EncodeToString(salt),
+			"Cipher":     encryptedKeyCipher,
+			"Nonce":      base64.StdEncoding.EncodeToString(nonce),
+		},
+		Bytes: aead.Seal(nil, nonce, priv.Seed(), []byte(EncryptedPrivateKeyPEMType)),
+	}), nil
+}
+
+// DecryptPrivateKeyBlock opens an EncryptedPrivateKeyPEMType block.
+func DecryptPrivateKeyBlock(block *pem.Block, passphrase []byte) (ed25519.PrivateKey, error) {
+	if block == nil || block.Type != EncryptedPrivateKeyPEMType {
+		return nil, errors.New("not an encrypted private key")
+	}
+	if block.Headers["Kdf"] != encryptedKeyKDF || block.Headers["Cipher"] != encryptedKeyCipher {
+		return nil, fmt.Errorf("unsupported key encryption %s/%s", block.Headers["Kdf"], block.Headers["Cipher"])
+	}
+	n, r, p, err := parseScryptParams(block.Headers["Kdf-Params"])
+	if err != nil {
+		return nil, err
+	}
+	salt, err := base64.StdEncoding.DecodeString(block.Headers["Salt"])
+	if err != nil || len(salt) == 0 {
+		return nil, errors.New("invalid encrypted key salt")
+	}
+	nonce, err := base64.StdEncoding.DecodeString(block.Headers["Nonce"])
+	if err != nil {
+		return nil, errors.New("invalid encrypted key nonce")
+	}
+	aead, err := encryptedKeyAEAD(passphrase, salt, n, r, p)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid encrypted key nonce")
+	}
+	seed, err := aead.Open(nil, nonce, block.Bytes, []byte(EncryptedPrivateKeyPEMType))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid seed size %d", len(seed))
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// UnlockPrivateKeyBlock opens an encrypted block read from path with a
+// remembered passphrase or the configured passphrase source.
+func UnlockPrivateKeyBlock(block *pem.Block, path string) (ed25519.PrivateKey, error) {
+	key, _, err := unlockPrivateKeyBlock(block, path)
+	return key, err
+}
+
+func unlockPrivateKeyBlock(block *pem.Block, path string) (ed25519.PrivateKey, []byte, error) {
+	keyPassphrases.mu.Lock()
+	defer keyPassphrases.mu.Unlock()
+	for _, passphrase := range keyPassphrases.opened {
+		if key, err := DecryptPrivateKeyBlock(block, passphrase); err == nil {
+			return key, passphrase, nil
+		} else if !errors.Is(err, ErrWrongPassphrase) {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if keyPassphrases.source == nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, ErrSigningKeyLocked)
+	}
+	passphrase, err := keyPassphrases.source(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	key, err := DecryptPrivateKeyBlock(block, passphrase)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	keyPassphrases.opened = append(keyPassphrases.opened, passphrase)
+	return key, passphrase, nil
+}
+
+// SaveKeypairLike writes a keypair like SaveKeypairAt, but when the key at
+// like is encrypted the new private key is sealed under the same
+// passphrase, so replacing an encrypted key never leaves plaintext behind.
+// An empty like, or a plaintext key there, writes plaintext.
+func SaveKeypairLike(keyPath, pubPath string, pub ed25519.PublicKey, priv ed25519.PrivateKey, like string) error {
+	passphrase, err := keyFilePassphrase(like)
+	if err != nil {
+		return err
+	}
+	if passphrase == nil {
+		return SaveKeypairAt(keyPath, pubPath, pub, priv)
+	}
+	data, err := EncryptPrivateKeyPEM(priv, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := atomicWritePrivateKeyFile(keyPath, data); err != nil {
+		return fmt.Errorf("write private key %s: %w", keyPath, err)
+	}
+	// Crash-test observation only; inert without the inherited pipe capability.
+	crashtest.Checkpoint("after-keypair-private-commit", keyPath)
+	if err := writePublicKey(pubPath, pub); err != nil {
+		return err
+	}
+	crashtest.Checkpoint("after-keypair-public-commit", pubPath)
+	return nil
+}
+
+// keyFilePassphrase returns the passphrase that opens the encrypted key at
+// path, or nil when path is empty, missing or holds a plaintext key.
+func keyFilePassphrase(path string) ([]byte, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, nil
+	}
+	block, err := readPrivateKeyBlock(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if block.Type != EncryptedPrivateKeyPEMType {
+		return nil, nil
+	}
+	_, passphrase, err := unlockPrivateKeyBlock(block, path)
+	return passphrase, err
+}
+
+// IsEncryptedPrivateKeyFile reports whether path holds an encrypted key.
+func IsEncryptedPrivateKeyFile(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return false, fmt.Errorf("no PEM block in %s", path)
+	}
+	return block.Type == EncryptedPrivateKeyPEMType, nil
+}
+
+// EncryptPrivateKeyFile rewrites the plaintext Ed25519 key at path in the
+// encrypted format. It reports false when the key is already encrypted.
+func EncryptPrivateKeyFile(path string, passphrase []byte) (bool, error) {
+	block, err := readPrivateKeyBlock(path)
+	if err != nil {
+		return false, err
+	}
+	if block.Type == EncryptedPrivateKeyPEMType {
+		return false, nil
+	}
+	if block.Type != "ED25519 PRIVATE KEY" || len(block.Bytes) != ed25519.SeedSize {
+		return false, fmt.Errorf("%s is not an Ed25519 private key", path)
+	}
+	data, err := EncryptPrivateKeyPEM(ed25519.NewKeyFromSeed(block.Bytes), passphrase)
+	if err != nil {
+		return false, err
+	}
+	if err := atomicWritePrivateKeyFile(path, data); err != nil {
+		return false, fmt.Errorf("write private key %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// DecryptPrivateKeyFile rewrites the encrypted key at path as a plaintext
+// PEM. It reports false when the key is not encrypted.
+func DecryptPrivateKeyFile(path string, passphrase []byte) (bool, error) {
+	block, err := readPrivateKeyBlock(path)
+	if err != nil {
+		return false, err
+	}
+	if block.Type != EncryptedPrivateKeyPEMType {
+		return false, nil
+	}
+	key, err := DecryptPrivateKeyBlock(block, passphrase)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := writePrivateKey(path, key); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func readPrivateKeyBlock(path string) (*pem.Block, error) {
+	if err := preflightSigningKeyPath(path); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block in %s", path)
+	}
+	return block, nil
+}
+
+func encryptedKeyAEAD(passphrase, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, n, r, p, 32)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// maxScryptMemory bounds the 128·N·r bytes scrypt allocates for one load.
+const maxScryptMemory = 256 << 20
+
+// parseScryptParams reads "N=32768,r=8,p=1". r is capped at 8 and 128·N·r
+// at maxScryptMemory, so a crafted file cannot make a load allocate more
+// than 256 MiB.
+func parseScryptParams(raw string) (n, r, p int, err error) {
+	for _, part := range strings.Split(raw, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return 0, 0, 0, fmt.Errorf("invalid scrypt params %q", raw)
+		}
+		v, convErr := strconv.Atoi(value)
+		if convErr != nil || v <= 0 {
+			return 0, 0, 0, fmt.Errorf("invalid scrypt params %q", raw)
+		}
+		switch name {
+		case "N":
+			n = v
+		case "r":
+			r = v
+		case "p":
+			p = v
+		}
+	}
+	if n == 0 || r == 0 || p == 0 || r > 8 || p > 16 || n > maxScryptMemory/(128*r) {
+		return 0, 0, 0, fmt.Errorf("unsupported scrypt params %q", raw)
+	}
+	return n, r, p, nil
+}
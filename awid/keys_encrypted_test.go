@@ -0,0 +1,101 @@
+package awid
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func lowScryptCost(t *testing.T) {
+	t.Helper()
+	orig := encryptedKeyScryptN
+	encryptedKeyScryptN = 1 << 10
+	t.Cleanup(func() {
+		encryptedKeyScryptN = orig
+		SetKeyPassphraseSource(nil)
+	})
+}
+
+func TestEncryptPrivateKeyFileRoundTripsThroughLoadSigningKey(t *testing.T) {
+	lowScryptCost(t)
+	_, priv, err := GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "signing.key")
+	if err := SaveSigningKey(path, priv); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, err := EncryptPrivateKeyFile(path, []byte("hunter2")); err != nil || !changed {
+		t.Fatalf("encrypt changed=%v err=%v", changed, err)
+	}
+	if changed, err := EncryptPrivateKeyFile(path, []byte("hunter2")); err != nil || changed {
+		t.Fatalf("re-encrypt changed=%v err=%v", changed, err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), EncryptedPrivateKeyPEMType) || strings.Contains(string(data), "BEGIN ED25519 PRIVATE KEY") {
+		t.Fatalf("file=%s", data)
+	}
+
+	SetKeyPassphraseSource(nil)
+	if _, err := LoadSigningKey(path); !errors.Is(err, ErrSigningKeyLocked) {
+		t.Fatalf("locked err=%v", err)
+	}
+
+	SetKeyPassphraseSource(func(string) ([]byte, error) { return []byte("wrong"), nil })
+	if _, err := LoadSigningKey(path); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("wrong passphrase err=%v", err)
+	}
+
+	asked := 0
+	SetKeyPassphraseSource(func(got string) ([]byte, error) {
+		asked++
+		if got != path {
+			t.Fatalf("asked for %s", got)
+		}
+		return []byte("hunter2"), nil
+	})
+	for i := 0; i < 2; i++ {
+		loaded, err := LoadSigningKey(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !loaded.Equal(priv) {
+			t.Fatal("decrypted key differs")
+		}
+	}
+	if asked != 1 {
+		t.Fatalf("passphrase asked %d times", asked)
+	}
+
+	if _, err := DecryptPrivateKeyFile(path, []byte("wrong")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("decrypt wrong err=%v", err)
+	}
+	if changed, err := DecryptPrivateKeyFile(path, []byte("hunter2")); err != nil || !changed {
+		t.Fatalf("decrypt changed=%v err=%v", changed, err)
+	}
+	if encrypted, err := IsEncryptedPrivateKeyFile(path); err != nil || encrypted {
+		t.Fatalf("encrypted=%v err=%v", encrypted, err)
+	}
+}
+
+func TestDecryptPrivateKeyBlockRefusesExcessiveScryptCost(t *testing.T) {
+	if _, _, _, err := parseScryptParams("N=4194304,r=8,p=1"); err == nil {
+		t.Fatal("expected excessive N to be refused")
+	}
+	// 128·N·r = 4 GiB at the old caps.
+	for _, raw := range []string{"N=1048576,r=32,p=1", "N=524288,r=8,p=1", "N=1024,r=16,p=1"} {
+		if _, _, _, err := parseScryptParams(raw); err == nil {
+			t.Fatalf("expected %s to be refused", raw)
+		}
+	}
+	if _, _, _, err := parseScryptParams("N=262144,r=8,p=1"); err != nil {
+		t.Fatalf("256 MiB cost refused: %v", err)
+	}
+	if n, r, p, err := parseScryptParams("N=32768,r=8,p=1"); err != nil || n != 32768 || r != 8 || p != 1 {
+		t.Fatalf("n=%d r=%d p=%d err=%v", n, r, p, err)
+	}
+}
@@ -93,7 +93,7 @@ func (c *Client) sendMessage(ctx context.Context, req *SendMessageRequest, ident
 			strings.TrimSpace(payload.ToAddress) != ""
 	}
 	initialConversationID := strings.TrimSpace(payload.ConversationID)
-	if c.signingKey != nil && initialConversationID == "" && hasRecipient {
+	if c.signer != nil && initialConversationID == "" && hasRecipient {
 		conversationID, err := GenerateUUID4()
 		if err != nil {
 			return nil, err
@@ -119,14 +119,10 @@ func (c *Client) sendMessage(ctx context.Context, req *SendMessageRequest, ident
 		if err := c.prepareE2EEMail(ctx, &payload, identityTarget, initialConversationID, hasRecipient); err != nil {
 			return nil, err
 		}
-		var out SendMessageResponse
-		if err := c.Post(ctx, "/v1/messages", &payload, &out); err != nil {
-			return nil, err
-		}
-		return &out, nil
+		return c.postMessage(ctx, &payload)
 	}
 	from := c.address
-	if c.signingKey != nil {
+	if c.signer != nil {
 		from = c.signedPayloadFrom(identityTarget, payload.ToAlias != "" && !strings.Contains(payload.ToAlias, "/"))
 	}
 	sf, err := c.signEnvelope(ctx, &MessageEnvelope{
@@ -145,7 +141,7 @@ func (c *Client) sendMessage(ctx context.Context, req *SendMessageRequest, ident
 	if err != nil {
 		return nil, err
 	}
-	if c.signingKey != nil {
+	if c.signer != nil {
 		payload.FromDID = sf.FromDID
 		payload.ToDID = sf.ToDID
 		payload.ToStableID = sf.ToStableID
@@ -154,11 +150,24 @@ func (c *Client) sendMessage(ctx context.Context, req *SendMessageRequest, ident
 		payload.Timestamp = sf.Timestamp
 		payload.SignedPayload = sf.SignedPayload
 	}
+	return c.postMessage(ctx, &payload)
+}
 
+// postMessage sends a prepared mail payload, queueing it in the outbox when
+// the server is unreachable.
+func (c *Client) postMessage(ctx context.Context, payload *SendMessageRequest) (*SendMessageResponse, error) {
 	var out SendMessageResponse
-	if err := c.Post(ctx, "/v1/messages", &payload, &out); err != nil {
+	queued, err := c.postOrQueue(ctx, OutboxKindMail, "/v1/messages", payload.MessageID, payload, &out)
+	if err != nil {
 		return nil, err
 	}
+	if queued {
+		return &SendMessageResponse{
+			MessageID:      payload.MessageID,
+			ConversationID: payload.ConversationID,
+			Status:         MessageStatusQueued,
+		}, nil
+	}
 	return &out, nil
 }
 
@@ -166,7 +175,7 @@ func (c *Client) prepareE2EEMail(ctx context.Context, payload *SendMessageReques
 	if c == nil || payload == nil {
 		return errors.New("aweb: request is required")
 	}
-	if c.signingKey == nil || strings.TrimSpace(c.did) == "" {
+	if c.signer == nil || strings.TrimSpace(c.did) == "" {
 		return errors.New("E2E messaging requires a local self-custodial signing key")
 	}
 	if c.e2eeEncryptionKey == nil {
@@ -196,14 +205,14 @@ func (c *Client) prepareE2EEMail(ctx context.Context, payload *SendMessageReques
 		return errors.New("E2E mail requires a conversation_id or explicit recipient")
 	}
 	fromAddress := c.e2eeAddress()
-	envelope, err := EncryptE2EEMail(E2EEEncryptMailParams{
+	envelope, err := EncryptE2EEMail(ctx, E2EEEncryptMailParams{
 		Sender: E2EESenderKey{
 			Address:       fromAddress,
 			DID:           c.did,
 			StableID:      c.stableID,
 			TeamID:        c.teamID,
 			EncryptionKey: c.e2eeEncryptionKey,
-			SigningKey:    c.signingKey,
+			Signer:        c.signer,
 		},
 		Recipients:          []E2EERecipientKey{recipient},
 		Subject:             payload.Subject,
@@ -3,8 +3,6 @@ package awid
 import (
 	"bytes"
 	"context"
-	"crypto/ed25519"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -42,7 +40,7 @@ func (c *Client) BootstrapRedeem(ctx context.Context, req *BootstrapRedeemReques
 	if strings.TrimSpace(req.DIDKey) == "" {
 		return nil, fmt.Errorf("aweb: did_key is required for bootstrap-redeem")
 	}
-	if c.signingKey == nil {
+	if c.signer == nil {
 		return nil, fmt.Errorf("aweb: bootstrap-redeem requires a signing key")
 	}
 	if c.did == "" {
@@ -71,8 +69,11 @@ func (c *Client) BootstrapRedeem(ctx context.Context, req *BootstrapRedeemReques
 
 	timestamp := time.Now().UTC().Format(time.RFC3339)
 	signPayload := onboardingDIDKeySignPayload(httpReq.Method, httpReq.URL.Path, timestamp, bodyBytes)
-	signature := ed25519.Sign(c.signingKey, signPayload)
-	httpReq.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, base64.RawStdEncoding.EncodeToString(signature)))
+	signature, err := c.sign(ctx, signPayload)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", fmt.Sprintf("DIDKey %s %s", c.did, signature))
 	httpReq.Header.Set("X-AWEB-Timestamp", timestamp)
 
 	resp, err := DoNoRedirectWithTimeout(c.httpClient, httpReq, APITimeout())
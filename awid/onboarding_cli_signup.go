@@ -113,7 +113,10 @@ func CliSignup(
 	}
 
 	const path = "/api/v1/onboarding/cli-signup"
-	headers := onboardingDIDKeyHeaders(http.MethodPost, path, bodyBytes, signingKey)
+	headers, err := onboardingDIDKeyHeaders(ctx, http.MethodPost, path, bodyBytes, NewKeySigner(signingKey))
+	if err != nil {
+		return nil, err
+	}
 
 	var out CliSignupResponse
 	if err := postJSONWithHeaders(ctx, onboardingURL, path, bodyBytes, headers, &out); err != nil {
@@ -131,18 +134,22 @@ func CliSignup(
 // and bootstrap-redeem so all three onboarding endpoints produce
 // byte-identical canonical payloads.
 func onboardingDIDKeyHeaders(
+	ctx context.Context,
 	method, path string,
 	body []byte,
-	signingKey ed25519.PrivateKey,
-) map[string]string {
+	signer Signer,
+) (map[string]string, error) {
 	timestamp := time.Now().UTC().Format(time.RFC3339)
 	payload := onboardingDIDKeySignPayload(method, path, timestamp, body)
-	sig := ed25519.Sign(signingKey, payload)
-	did := ComputeDIDKey(signingKey.Public().(ed25519.PublicKey))
+	sig, err := signer.Sign(ctx, payload)
+	if err != nil {
+		return nil, fmt.Errorf("sign: %w", err)
+	}
+	did := ComputeDIDKey(signer.PublicKey())
 	return map[string]string{
 		"Authorization":    fmt.Sprintf("DIDKey %s %s", did, base64.RawStdEncoding.EncodeToString(sig)),
 		"X-AWEB-Timestamp": timestamp,
-	}
+	}, nil
 }
 
 // postJSONNoAuth sends a JSON POST with no authentication. Marshals once,
@@ -0,0 +1,104 @@
+package awid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	OutboxKindMail       = "mail"
+	OutboxKindChatCreate = "chat_create"
+	OutboxKindChatSend   = "chat_send"
+
+	// MessageStatusQueued is the send status reported for a message held
+	// in the outbox rather than accepted by the server.
+	MessageStatusQueued = "queued"
+)
+
+// OutboxItem is a fully prepared send: the request body is already signed
+// and, for E2EE messages, encrypted, so replaying it later delivers exactly
+// the message the sender wrote under the same message_id.
+type OutboxItem struct {
+	Kind      string          `json:"kind"`
+	Path      string          `json:"path"`
+	MessageID string          `json:"message_id"`
+	TeamID    string          `json:"team_id,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+	QueuedAt  string          `json:"queued_at"`
+}
+
+// Outbox durably stores sends whose POST could not reach the server.
+type Outbox interface {
+	Enqueue(item OutboxItem) error
+}
+
+// SetOutbox makes mail sends and leaving chat sends queue to o instead of
+// failing when the server is unreachable. A nil outbox disables queueing.
+func (c *Client) SetOutbox(o Outbox) { c.outbox = o }
+
+// IsServerUnreachable reports whether err means the request never reached
+// a working aweb server: a network failure, a timeout, or a gateway error.
+func IsServerUnreachable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if code, ok := HTTPStatusCode(err); ok {
+		return code == http.StatusBadGateway || code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// postOrQueue posts a prepared send and, when the server is unreachable and
+// an outbox is set, queues it instead. It reports whether it queued.
+func (c *Client) postOrQueue(ctx context.Context, kind, path, messageID string, payload, out any) (bool, error) {
+	err := c.Post(ctx, path, payload, out)
+	if err == nil || c.outbox == nil || strings.TrimSpace(messageID) == "" || !IsServerUnreachable(err) {
+		return false, err
+	}
+	raw, marshalErr := json.Marshal(payload)
+	if marshalErr != nil {
+		return false, err
+	}
+	item := OutboxItem{
+		Kind:      kind,
+		Path:      path,
+		MessageID: strings.TrimSpace(messageID),
+		TeamID:    c.TeamID(),
+		Payload:   raw,
+		QueuedAt:  time.Now().UTC().Format(time.RFC3339),
+	}
+	if queueErr := c.outbox.Enqueue(item); queueErr != nil {
+		return false, fmt.Errorf("%w (queueing for later also failed: %v)", err, queueErr)
+	}
+	return true, nil
+}
+
+// ReplayOutboxItem posts a queued send again. The server deduplicates on
+// message_id, so a 409 whose body reports a duplicate message_id means an
+// earlier attempt already landed. Any other 409 is returned as an error.
+func (c *Client) ReplayOutboxItem(ctx context.Context, item OutboxItem) error {
+	if !strings.HasPrefix(item.Path, "/v1/") || len(item.Payload) == 0 {
+		return fmt.Errorf("outbox item %s is malformed", item.MessageID)
+	}
+	err := c.Post(ctx, item.Path, item.Payload, nil)
+	if duplicateMessageConflict(err) {
+		return nil
+	}
+	return err
+}
+
+func duplicateMessageConflict(err error) bool {
+	if code, ok := HTTPStatusCode(err); !ok || code != http.StatusConflict {
+		return false
+	}
+	body, _ := HTTPErrorBody(err)
+	body = strings.ToLower(body)
+	return strings.Contains(body, "message_id") && (strings.Contains(body, "duplicate") || strings.Contains(body, "already"))
+}
@@ -0,0 +1,155 @@
+package awid
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type memoryOutbox struct {
+	items []OutboxItem
+}
+
+func (o *memoryOutbox) Enqueue(item OutboxItem) error {
+	o.items = append(o.items, item)
+	return nil
+}
+
+func TestSendMessageQueuesWhenServerUnavailable(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	did := ComputeDIDKey(pub)
+	status := http.StatusServiceUnavailable
+	conflictBody := `{"detail":"duplicate message_id"}`
+	var posted []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		posted = append(posted, body)
+		if status == http.StatusConflict {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(conflictBody))
+			return
+		}
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"message_id": body["message_id"].(string), "status": "delivered"})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewWithIdentity(server.URL, priv, did)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetAddress("myco/agent")
+	outbox := &memoryOutbox{}
+	c.SetOutbox(outbox)
+
+	resp, err := c.SendMessage(context.Background(), &SendMessageRequest{ToAlias: "otherco/monitor", Body: "later"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != MessageStatusQueued || len(outbox.items) != 1 {
+		t.Fatalf("status=%q queued=%d", resp.Status, len(outbox.items))
+	}
+	item := outbox.items[0]
+	if item.Kind != OutboxKindMail || item.Path != "/v1/messages" || item.MessageID != resp.MessageID || item.MessageID == "" {
+		t.Fatalf("item=%+v", item)
+	}
+
+	status = http.StatusOK
+	if err := c.ReplayOutboxItem(context.Background(), item); err != nil {
+		t.Fatal(err)
+	}
+	last := posted[len(posted)-1]
+	if last["signature"] != posted[0]["signature"] || last["message_id"] != item.MessageID {
+		t.Fatalf("replay did not resend the signed payload: %v", posted)
+	}
+
+	status = http.StatusConflict
+	if err := c.ReplayOutboxItem(context.Background(), item); err != nil {
+		t.Fatalf("duplicate replay err=%v, want delivered", err)
+	}
+	conflictBody = `{"detail":"recipient alias was renamed"}`
+	if err := c.ReplayOutboxItem(context.Background(), item); err == nil {
+		t.Fatal("non-duplicate conflict was treated as delivered")
+	}
+
+	status = http.StatusBadRequest
+	_, err = c.SendMessage(context.Background(), &SendMessageRequest{ToAlias: "otherco/monitor", Body: "rejected"})
+	if code, ok := HTTPStatusCode(err); !ok || code != http.StatusBadRequest {
+		t.Fatalf("err=%v, want 400", err)
+	}
+	if len(outbox.items) != 1 {
+		t.Fatalf("rejected send was queued: %d items", len(outbox.items))
+	}
+}
+
+func TestChatSendQueuesOnlyLeavingMessages(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewWithIdentity(server.URL, priv, ComputeDIDKey(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outbox := &memoryOutbox{}
+	c.SetOutbox(outbox)
+
+	if _, err := c.ChatCreateSession(context.Background(), &ChatCreateSessionRequest{ToAliases: []string{"bob"}, Message: "wait for me"}); err == nil {
+		t.Fatal("waiting chat send succeeded against an unavailable server")
+	}
+	resp, err := c.ChatCreateSession(context.Background(), &ChatCreateSessionRequest{ToAliases: []string{"bob"}, Message: "bye", Leaving: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Queued || resp.SessionID == "" || len(outbox.items) != 1 || outbox.items[0].Kind != OutboxKindChatCreate {
+		t.Fatalf("resp=%+v items=%+v", resp, outbox.items)
+	}
+}
+
+func TestIsServerUnreachable(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{&APIError{StatusCode: http.StatusServiceUnavailable}, true},
+		{&APIError{StatusCode: http.StatusGatewayTimeout}, true},
+		{&APIError{StatusCode: http.StatusInternalServerError}, false},
+		{&APIError{StatusCode: http.StatusUnauthorized}, false},
+		{context.Canceled, false},
+		{errors.New("boom"), false},
+	} {
+		if got := IsServerUnreachable(tc.err); got != tc.want {
+			t.Errorf("IsServerUnreachable(%v)=%v, want %v", tc.err, got, tc.want)
+		}
+	}
+
+	c, err := New("http://127.0.0.1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Post(context.Background(), "/v1/messages", map[string]string{}, nil); !IsServerUnreachable(err) {
+		t.Fatalf("connection refused err=%v not reported unreachable", err)
+	}
+}
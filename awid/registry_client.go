@@ -3,6 +3,7 @@ package awid
 import (
 	"bytes"
 	"context"
+	"crypto"
 	"crypto/ed25519"
 	"encoding/base64"
 	"encoding/json"
@@ -194,10 +195,14 @@ func (c *RegistryClient) ResolveKeyAt(ctx context.Context, registryURL, didAW st
 	return res, nil
 }
 
-func (c *RegistryClient) GetDIDFull(ctx context.Context, registryURL, didAW string, signingKey ed25519.PrivateKey) (*DIDMapping, error) {
+func (c *RegistryClient) GetDIDFull(ctx context.Context, registryURL, didAW string, signingKey crypto.Signer) (*DIDMapping, error) {
 	path := "/v1/did/" + urlPathEscape(strings.TrimSpace(didAW)) + "/full"
 	var out DIDMapping
-	if err := c.requestJSON(ctx, http.MethodGet, registryURL, path, signedPathHeaders(http.MethodGet, path, signingKey), nil, &out); err != nil {
+	headers, err := signedPathHeaders(ctx, http.MethodGet, path, signingKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.requestJSON(ctx, http.MethodGet, registryURL, path, headers, nil, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -268,7 +273,7 @@ func (c *RegistryClient) ListNamespaceAddressesAt(ctx context.Context, registryU
 func (c *RegistryClient) ListNamespaceAddressesAtSigned(
 	ctx context.Context,
 	registryURL, domain string,
-	signingKey ed25519.PrivateKey,
+	signingKey crypto.Signer,
 ) ([]RegistryAddress, string, error) {
 	return c.ListNamespaceAddressesAt(ctx, registryURL, domain)
 }
@@ -278,7 +283,7 @@ func (c *RegistryClient) RegisterIdentity(
 	registryURL string,
 	did string,
 	stableID string,
-	signingKey ed25519.PrivateKey,
+	signingKey crypto.Signer,
 ) (*DIDMapping, error) {
 	registryURL = strings.TrimSpace(registryURL)
 	did = strings.TrimSpace(did)
@@ -290,10 +295,11 @@ func (c *RegistryClient) RegisterIdentity(
 	if !strings.HasPrefix(stableID, "did:aw:") {
 		return nil, fmt.Errorf("stableID must start with did:aw:")
 	}
-	if signingKey == nil {
+	pub := signingKeyPublic(signingKey)
+	if pub == nil {
 		return nil, fmt.Errorf("signing key is required")
 	}
-	if pub := signingKey.Public().(ed25519.PublicKey); ComputeDIDKey(pub) != did {
+	if ComputeDIDKey(pub) != did {
 		return nil, fmt.Errorf("did does not match signing key")
 	}
 
@@ -309,6 +315,10 @@ func (c *RegistryClient) RegisterIdentity(
 		AuthorizedBy:   did,
 		Timestamp:      timestamp,
 	})
+	proof, err := signWithKey(ctx, signingKey, []byte(proofPayload))
+	if err != nil {
+		return nil, err
+	}
 	payload := didRegisterRequest{
 		AuthorizedBy:   did,
 		DIDAW:          stableID,
@@ -319,7 +329,7 @@ func (c *RegistryClient) RegisterIdentity(
 		Seq:            1,
 		StateHash:      stateHash,
 		Timestamp:      timestamp,
-		Proof:          base64.RawStdEncoding.EncodeToString(ed25519.Sign(signingKey, []byte(proofPayload))),
+		Proof:          base64.RawStdEncoding.EncodeToString(proof),
 	}
 	if err := c.requestJSON(ctx, http.MethodPost, registryURL, "/v1/did", nil, payload, nil); err != nil {
 		var regErr *RegistryError
@@ -358,13 +368,14 @@ func (c *RegistryClient) RotateDIDKey(
 	ctx context.Context,
 	registryURL string,
 	didAW string,
-	oldSigningKey ed25519.PrivateKey,
+	oldSigningKey crypto.Signer,
 	newSigningKey ed25519.PrivateKey,
 ) (*DIDMapping, error) {
-	if oldSigningKey == nil || newSigningKey == nil {
+	oldPub := signingKeyPublic(oldSigningKey)
+	if oldPub == nil || newSigningKey == nil {
 		return nil, fmt.Errorf("both old and new signing keys are required")
 	}
-	oldDID := ComputeDIDKey(oldSigningKey.Public().(ed25519.PublicKey))
+	oldDID := ComputeDIDKey(oldPub)
 	newDID := ComputeDIDKey(newSigningKey.Public().(ed25519.PublicKey))
 	current, err := c.GetDIDFull(ctx, registryURL, didAW, oldSigningKey)
 	if err != nil {
@@ -393,7 +404,11 @@ func (c *RegistryClient) RotateDIDKey(
 		AuthorizedBy:   oldDID,
 		Timestamp:      timestamp,
 	})
-	signature := base64.RawStdEncoding.EncodeToString(ed25519.Sign(oldSigningKey, []byte(signaturePayload)))
+	sig, err := signWithKey(ctx, oldSigningKey, []byte(signaturePayload))
+	if err != nil {
+		return nil, err
+	}
+	signature := base64.RawStdEncoding.EncodeToString(sig)
 	req := didUpdateRequest{
 		Operation:     "rotate_key",
 		NewDIDKey:     newDID,
@@ -637,25 +652,31 @@ func (c *RegistryClient) httpClient() *http.Client {
 // from the signed payload: the registry verifies over request.url.path only
 // (awid_service/routes/teams.py _verify_path_signature), so signing the query
 // would fail verification on every paginated read.
-func optionalSignedPathHeaders(method, path string, signingKey ed25519.PrivateKey) map[string]string {
-	if signingKey == nil {
-		return nil
+func optionalSignedPathHeaders(ctx context.Context, method, path string, signingKey crypto.Signer) (map[string]string, error) {
+	if signingKeyPublic(signingKey) == nil {
+		return nil, nil
 	}
 	if i := strings.IndexByte(path, '?'); i >= 0 {
 		path = path[:i]
 	}
-	return signedPathHeaders(method, path, signingKey)
+	return signedPathHeaders(ctx, method, path, signingKey)
 }
 
-func signedPathHeaders(method, path string, signingKey ed25519.PrivateKey) map[string]string {
+func signedPathHeaders(ctx context.Context, method, path string, signingKey crypto.Signer) (map[string]string, error) {
+	pub := signingKeyPublic(signingKey)
+	if pub == nil {
+		return nil, fmt.Errorf("signing key is required")
+	}
 	timestamp := time.Now().UTC().Format(time.RFC3339)
-	did := ComputeDIDKey(signingKey.Public().(ed25519.PublicKey))
 	payload := timestamp + "\n" + method + "\n" + path
-	signature := base64.RawStdEncoding.EncodeToString(ed25519.Sign(signingKey, []byte(payload)))
+	signature, err := signWithKey(ctx, signingKey, []byte(payload))
+	if err != nil {
+		return nil, err
+	}
 	return map[string]string{
-		"Authorization":    fmt.Sprintf("DIDKey %s %s", did, signature),
+		"Authorization":    fmt.Sprintf("DIDKey %s %s", ComputeDIDKey(pub), base64.RawStdEncoding.EncodeToString(signature)),
 		"X-AWEB-Timestamp": timestamp,
-	}
+	}, nil
 }
 
 func parseRegistryError(resp *http.Response) error {
@@ -2,6 +2,7 @@ package awid
 
 import (
 	"context"
+	"crypto"
 	"crypto/ed25519"
 	"encoding/base64"
 	"fmt"
@@ -33,7 +34,7 @@ type AtomicAddressClaimParams struct {
 	DIDAW                         string
 	CurrentDIDKey                 string
 	IdentitySigningKey            ed25519.PrivateKey
-	NamespaceControllerSigningKey ed25519.PrivateKey
+	NamespaceControllerSigningKey crypto.Signer
 	DryRun                        bool
 	IdentityCustody               string
 	NamespaceCustody              string
@@ -114,7 +115,7 @@ func (c *RegistryClient) GetNamespaceAddress(ctx context.Context, domain, name s
 func (c *RegistryClient) GetNamespaceAddressSigned(
 	ctx context.Context,
 	domain, name string,
-	signingKey ed25519.PrivateKey,
+	signingKey crypto.Signer,
 ) (*RegistryAddress, string, error) {
 	registryURL, err := c.DiscoverRegistry(ctx, domain)
 	if err != nil {
@@ -135,7 +136,7 @@ func (c *RegistryClient) GetNamespaceAddressAt(ctx context.Context, registryURL,
 func (c *RegistryClient) GetNamespaceAddressAtSigned(
 	ctx context.Context,
 	registryURL, domain, name string,
-	signingKey ed25519.PrivateKey,
+	signingKey crypto.Signer,
 ) (*RegistryAddress, string, error) {
 	return c.GetNamespaceAddressAt(ctx, registryURL, domain, name)
 }
@@ -144,7 +145,7 @@ func (c *RegistryClient) RegisterNamespace(
 	ctx context.Context,
 	domain string,
 	controllerDID string,
-	signingKey ed25519.PrivateKey,
+	signingKey crypto.Signer,
 ) (*RegistryNamespace, string, error) {
 	registryURL, err := c.DiscoverRegistry(ctx, domain)
 	if err != nil {
@@ -158,7 +159,7 @@ func (c *RegistryClient) RegisterNamespaceWithDeliveryOrigin(
 	ctx context.Context,
 	domain string,
 	controllerDID string,
-	signingKey ed25519.PrivateKey,
+	signingKey crypto.Signer,
 	defaultDeliveryOrigin string,
 ) (*RegistryNamespace, string, error) {
 	registryURL, err := c.DiscoverRegistry(ctx, domain)
@@ -174,7 +175,7 @@ func (c *RegistryClient) RegisterNamespaceAt(
 	registryURL string,
 	domain string,
 	controllerDID string,
-	signingKey ed25519.PrivateKey,
+	signingKey crypto.Signer,
 ) (*RegistryNamespace, error) {
 	return c.RegisterNamespaceWithDeliveryOriginAt(ctx, registryURL, domain, controllerDID, signingKey, "")
 }
@@ -184,7 +185,7 @@ func (c *RegistryClient) RegisterNamespaceWithDeliveryOriginAt(
 	registryURL string,
 	domain string,
 	controllerDID string,
-	signingKey ed25519.PrivateKey,
+	signingKey crypto.Signer,
 	defaultDeliveryOrigin string,
 ) (*RegistryNamespace, error) {
 	domain = canonicalizeDomain(domain)
@@ -210,12 +211,16 @@ func (c *RegistryClient) RegisterNamespaceWithDeliveryOriginAt(
 	}
 
 	var out RegistryNamespace
+	headers, err := signedNamespaceHeaders(ctx, domain, "register", signingKey, extraPayload)
+	if err != nil {
+		return nil, err
+	}
 	if err := c.requestJSON(
 		ctx,
 		http.MethodPost,
 		registryURL,
 		"/v1/namespaces",
-		signedNamespaceHeaders(domain, "register", signingKey, extraPayload),
+		headers,
 		namespaceRegisterRequest{
 			Domain:                domain,
 			ControllerDID:         controllerDID,
@@ -231,7 +236,7 @@ func (c *RegistryClient) RegisterNamespaceWithDeliveryOriginAt(
 func (c *RegistryClient) UpdateNamespaceDeliveryOrigin(
 	ctx context.Context,
 	domain string,
-	controllerSigningKey ed25519.PrivateKey,
+	controllerSigningKey crypto.Signer,
 	deliveryOrigin string,
 ) (*RegistryNamespace, string, error) {
 	registryURL, err := c.DiscoverRegistry(ctx, domain)
@@ -246,14 +251,14 @@ func (c *RegistryClient) UpdateNamespaceDeliveryOriginAt(
 	ctx context.Context,
 	registryURL string,
 	domain string,
-	controllerSigningKey ed25519.PrivateKey,
+	controllerSigningKey crypto.Signer,
 	deliveryOrigin string,
 ) (*RegistryNamespace, error) {
 	domain = canonicalizeDomain(domain)
 	if domain == "" {
 		return nil, fmt.Errorf("domain is required")
 	}
-	if controllerSigningKey == nil {
+	if signingKeyPublic(controllerSigningKey) == nil {
 		return nil, fmt.Errorf("controller signing key is required")
 	}
 	canonicalOrigin, err := CanonicalServerOrigin(deliveryOrigin)
@@ -262,17 +267,22 @@ func (c *RegistryClient) UpdateNamespaceDeliveryOriginAt(
 	}
 
 	var out RegistryNamespace
+	headers, err := signedNamespaceHeaders(
+		ctx,
+		domain,
+		"update_namespace",
+		controllerSigningKey,
+		map[string]string{"default_delivery_origin": canonicalOrigin},
+	)
+	if err != nil {
+		return nil, err
+	}
 	if err := c.requestJSON(
 		ctx,
 		http.MethodPatch,
 		registryURL,
 		"/v1/namespaces/"+urlPathEscape(domain),
-		signedNamespaceHeaders(
-			domain,
-			"update_namespace",
-			controllerSigningKey,
-			map[string]string{"default_delivery_origin": canonicalOrigin},
-		),
+		headers,
 		namespaceUpdateRequest{DefaultDeliveryOrigin: canonicalOrigin},
 		&out,
 	); err != nil {
@@ -284,7 +294,7 @@ func (c *RegistryClient) UpdateNamespaceDeliveryOriginAt(
 func (c *RegistryClient) DeleteNamespace(
 	ctx context.Context,
 	domain string,
-	controllerSigningKey ed25519.PrivateKey,
+	controllerSigningKey crypto.Signer,
 	reason string,
 ) (string, error) {
 	registryURL, err := c.DiscoverRegistry(ctx, domain)
@@ -298,14 +308,14 @@ func (c *RegistryClient) DeleteNamespaceAt(
 	ctx context.Context,
 	registryURL string,
 	domain string,
-	controllerSigningKey ed25519.PrivateKey,
+	controllerSigningKey crypto.Signer,
 	reason string,
 ) error {
 	domain = canonicalizeDomain(domain)
 	if domain == "" {
 		return fmt.Errorf("domain is required")
 	}
-	if controllerSigningKey == nil {
+	if signingKeyPublic(controllerSigningKey) == nil {
 		return fmt.Errorf("controller signing key is required")
 	}
 
@@ -314,12 +324,16 @@ func (c *RegistryClient) DeleteNamespaceAt(
 	if strings.TrimSpace(reason) != "" {
 		body = deleteReasonRequest{Reason: strings.TrimSpace(reason)}
 	}
+	headers, err := signedNamespaceHeaders(ctx, domain, "delete_namespace", controllerSigningKey, nil)
+	if err != nil {
+		return err
+	}
 	return c.requestJSON(
 		ctx,
 		http.MethodDelete,
 		registryURL,
 		path,
-		signedNamespaceHeaders(domain, "delete_namespace", controllerSigningKey, nil),
+		headers,
 		body,
 		nil,
 	)
@@ -356,7 +370,7 @@ func (c *RegistryClient) RegisterAddress(
 	name string,
 	didAW string,
 	currentDIDKey string,
-	controllerSigningKey ed25519.PrivateKey,
+	controllerSigningKey crypto.Signer,
 ) (*RegistryAddress, string, error) {
 	registryURL, err := c.DiscoverRegistry(ctx, domain)
 	if err != nil {
@@ -373,7 +387,7 @@ func (c *RegistryClient) RegisterAddressAt(
 	name string,
 	didAW string,
 	currentDIDKey string,
-	controllerSigningKey ed25519.PrivateKey,
+	controllerSigningKey crypto.Signer,
 ) (*RegistryAddress, error) {
 	domain = canonicalizeDomain(domain)
 	name = strings.TrimSpace(name)
@@ -391,18 +405,22 @@ func (c *RegistryClient) RegisterAddressAt(
 	if !strings.HasPrefix(currentDIDKey, "did:key:") {
 		return nil, fmt.Errorf("currentDIDKey must start with did:key:")
 	}
-	if controllerSigningKey == nil {
+	if signingKeyPublic(controllerSigningKey) == nil {
 		return nil, fmt.Errorf("controller signing key is required")
 	}
 
 	path := "/v1/namespaces/" + urlPathEscape(domain) + "/addresses"
 	var out RegistryAddress
+	headers, err := signedAddressHeaders(ctx, domain, name, "register_address", controllerSigningKey)
+	if err != nil {
+		return nil, err
+	}
 	if err := c.requestJSON(
 		ctx,
 		http.MethodPost,
 		registryURL,
 		path,
-		signedAddressHeaders(domain, name, "register_address", controllerSigningKey),
+		headers,
 		addressRegisterRequest{
 			Name:          name,
 			DIDAW:         didAW,
@@ -443,7 +461,7 @@ func (c *RegistryClient) ClaimIdentityAddressAt(
 	if params.IdentitySigningKey == nil {
 		return nil, fmt.Errorf("identity signing key is required")
 	}
-	if params.NamespaceControllerSigningKey == nil {
+	if signingKeyPublic(params.NamespaceControllerSigningKey) == nil {
 		return nil, fmt.Errorf("namespace controller signing key is required")
 	}
 	if did := ComputeDIDKey(params.IdentitySigningKey.Public().(ed25519.PublicKey)); did != params.CurrentDIDKey {
@@ -490,9 +508,11 @@ func (c *RegistryClient) ClaimIdentityAddressAt(
 	if err != nil {
 		return nil, err
 	}
-	namespaceSignature := base64.RawStdEncoding.EncodeToString(
-		ed25519.Sign(params.NamespaceControllerSigningKey, []byte(namespaceCanonical)),
-	)
+	namespaceSignatureBytes, err := signWithKey(ctx, params.NamespaceControllerSigningKey, []byte(namespaceCanonical))
+	if err != nil {
+		return nil, fmt.Errorf("sign namespace claim: %w", err)
+	}
+	namespaceSignature := base64.RawStdEncoding.EncodeToString(namespaceSignatureBytes)
 
 	body := atomicAddressClaimRequest{
 		Operation:          AtomicAddressClaimOperation,
@@ -572,7 +592,7 @@ func (c *RegistryClient) DeleteAddress(
 	ctx context.Context,
 	domain string,
 	name string,
-	controllerSigningKey ed25519.PrivateKey,
+	controllerSigningKey crypto.Signer,
 	reason string,
 ) (string, error) {
 	registryURL, err := c.DiscoverRegistry(ctx, domain)
@@ -587,7 +607,7 @@ func (c *RegistryClient) DeleteAddressAt(
 	registryURL string,
 	domain string,
 	name string,
-	controllerSigningKey ed25519.PrivateKey,
+	controllerSigningKey crypto.Signer,
 	reason string,
 ) error {
 	domain = canonicalizeDomain(domain)
@@ -598,7 +618,7 @@ func (c *RegistryClient) DeleteAddressAt(
 	if name == "" {
 		return fmt.Errorf("name is required")
 	}
-	if controllerSigningKey == nil {
+	if signingKeyPublic(controllerSigningKey) == nil {
 		return fmt.Errorf("controller signing key is required")
 	}
 
@@ -607,12 +627,16 @@ func (c *RegistryClient) DeleteAddressAt(
 	if strings.TrimSpace(reason) != "" {
 		body = deleteReasonRequest{Reason: strings.TrimSpace(reason)}
 	}
+	headers, err := signedAddressHeaders(ctx, domain, name, "delete_address", controllerSigningKey)
+	if err != nil {
+		return err
+	}
 	return c.requestJSON(
 		ctx,
 		http.MethodDelete,
 		registryURL,
 		path,
-		signedAddressHeaders(domain, name, "delete_address", controllerSigningKey),
+		headers,
 		body,
 		nil,
 	)
@@ -626,7 +650,7 @@ func (c *RegistryClient) DeleteAddressIfMatchesAt(
 	expectedAddressID string,
 	expectedDIDAW string,
 	expectedCurrentDIDKey string,
-	controllerSigningKey ed25519.PrivateKey,
+	controllerSigningKey crypto.Signer,
 	reason string,
 ) error {
 	domain = canonicalizeDomain(domain)
@@ -640,7 +664,7 @@ func (c *RegistryClient) DeleteAddressIfMatchesAt(
 	if expectedAddressID == "" || expectedDIDAW == "" || expectedCurrentDIDKey == "" {
 		return fmt.Errorf("expected address id, did:aw, and current did:key are required")
 	}
-	if controllerSigningKey == nil {
+	if signingKeyPublic(controllerSigningKey) == nil {
 		return fmt.Errorf("controller signing key is required")
 	}
 
@@ -649,12 +673,16 @@ func (c *RegistryClient) DeleteAddressIfMatchesAt(
 		"expected_did_aw":          expectedDIDAW,
 		"expected_current_did_key": expectedCurrentDIDKey,
 	}
+	headers, err := signedAddressHeadersWithFields(ctx, domain, name, "delete_address", controllerSigningKey, preconditions)
+	if err != nil {
+		return err
+	}
 	return c.requestJSON(
 		ctx,
 		http.MethodDelete,
 		registryURL,
 		"/v1/namespaces/"+urlPathEscape(domain)+"/addresses/"+urlPathEscape(name),
-		signedAddressHeadersWithFields(domain, name, "delete_address", controllerSigningKey, preconditions),
+		headers,
 		conditionalAddressDeleteRequest{
 			Reason:                strings.TrimSpace(reason),
 			ExpectedAddressID:     expectedAddressID,
@@ -665,11 +693,12 @@ func (c *RegistryClient) DeleteAddressIfMatchesAt(
 	)
 }
 
-func requireSigningKeyMatchesDID(signingKey ed25519.PrivateKey, expectedDID string) error {
-	if signingKey == nil {
+func requireSigningKeyMatchesDID(signingKey crypto.Signer, expectedDID string) error {
+	pub := signingKeyPublic(signingKey)
+	if pub == nil {
 		return fmt.Errorf("signing key is required")
 	}
-	actual := ComputeDIDKey(signingKey.Public().(ed25519.PublicKey))
+	actual := ComputeDIDKey(pub)
 	if actual != strings.TrimSpace(expectedDID) {
 		return fmt.Errorf("signing key does not match %s", strings.TrimSpace(expectedDID))
 	}
@@ -677,11 +706,12 @@ func requireSigningKeyMatchesDID(signingKey ed25519.PrivateKey, expectedDID stri
 }
 
 func signedNamespaceHeaders(
+	ctx context.Context,
 	domain string,
 	operation string,
-	signingKey ed25519.PrivateKey,
+	signingKey crypto.Signer,
 	extraPayload map[string]string,
-) map[string]string {
+) (map[string]string, error) {
 	timestamp := time.Now().UTC().Format(time.RFC3339)
 	fields := map[string]string{
 		"domain":    canonicalizeDomain(domain),
@@ -691,25 +721,27 @@ func signedNamespaceHeaders(
 	for key, value := range extraPayload {
 		fields[key] = strings.TrimSpace(value)
 	}
-	return signedCanonicalHeaders(fields, signingKey, timestamp)
+	return signedCanonicalHeaders(ctx, fields, signingKey, timestamp)
 }
 
 func signedAddressHeaders(
+	ctx context.Context,
 	domain string,
 	name string,
 	operation string,
-	signingKey ed25519.PrivateKey,
-) map[string]string {
-	return signedAddressHeadersWithFields(domain, name, operation, signingKey, nil)
+	signingKey crypto.Signer,
+) (map[string]string, error) {
+	return signedAddressHeadersWithFields(ctx, domain, name, operation, signingKey, nil)
 }
 
 func signedAddressHeadersWithFields(
+	ctx context.Context,
 	domain string,
 	name string,
 	operation string,
-	signingKey ed25519.PrivateKey,
+	signingKey crypto.Signer,
 	extra map[string]string,
-) map[string]string {
+) (map[string]string, error) {
 	timestamp := time.Now().UTC().Format(time.RFC3339)
 	fields := map[string]string{
 		"domain":    canonicalizeDomain(domain),
@@ -720,17 +752,23 @@ func signedAddressHeadersWithFields(
 	for key, value := range extra {
 		fields[key] = strings.TrimSpace(value)
 	}
-	return signedCanonicalHeaders(fields, signingKey, timestamp)
+	return signedCanonicalHeaders(ctx, fields, signingKey, timestamp)
 }
 
-func signedCanonicalHeaders(fields map[string]string, signingKey ed25519.PrivateKey, timestamp string) map[string]string {
-	did := ComputeDIDKey(signingKey.Public().(ed25519.PublicKey))
+func signedCanonicalHeaders(ctx context.Context, fields map[string]string, signingKey crypto.Signer, timestamp string) (map[string]string, error) {
+	pub := signingKeyPublic(signingKey)
+	if pub == nil {
+		return nil, fmt.Errorf("signing key is required")
+	}
 	payload := canonicalRegistryJSON(fields)
-	signature := base64.RawStdEncoding.EncodeToString(ed25519.Sign(signingKey, []byte(payload)))
+	signature, err := signWithKey(ctx, signingKey, []byte(payload))
+	if err != nil {
+		return nil, err
+	}
 	return map[string]string{
-		"Authorization":    fmt.Sprintf("DIDKey %s %s", did, signature),
+		"Authorization":    fmt.Sprintf("DIDKey %s %s", ComputeDIDKey(pub), base64.RawStdEncoding.EncodeToString(signature)),
 		"X-AWEB-Timestamp": timestamp,
-	}
+	}, nil
 }
 
 func canonicalRegistryJSON(fields map[string]string) string {
@@ -2,7 +2,7 @@ package awid
 
 import (
 	"context"
-	"crypto/ed25519"
+	"crypto"
 	"errors"
 	"fmt"
 	"net/http"
@@ -143,7 +143,7 @@ func (c *RegistryClient) CreateTeam(
 	name string,
 	displayName string,
 	teamDIDKey string,
-	controllerKey ed25519.PrivateKey,
+	controllerKey crypto.Signer,
 ) (*RegistryTeam, error) {
 	domain = canonicalizeDomain(domain)
 	name = strings.TrimSpace(name)
@@ -153,20 +153,24 @@ func (c *RegistryClient) CreateTeam(
 	if name == "" {
 		return nil, fmt.Errorf("team name is required")
 	}
-	if controllerKey == nil {
+	if signingKeyPublic(controllerKey) == nil {
 		return nil, fmt.Errorf("controller signing key is required")
 	}
 
 	path := "/v1/namespaces/" + urlPathEscape(domain) + "/teams"
 	var out RegistryTeam
+	headers, err := signedNamespaceHeaders(ctx, domain, "create_team", controllerKey, map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return nil, err
+	}
 	if err := c.requestJSON(
 		ctx,
 		http.MethodPost,
 		registryURL,
 		path,
-		signedNamespaceHeaders(domain, "create_team", controllerKey, map[string]string{
-			"name": name,
-		}),
+		headers,
 		teamCreateRequest{
 			Name:        name,
 			DisplayName: strings.TrimSpace(displayName),
@@ -188,7 +192,7 @@ func (c *RegistryClient) SetTeamVisibility(
 	domain string,
 	name string,
 	visibility string,
-	teamKey ed25519.PrivateKey,
+	teamKey crypto.Signer,
 ) (*RegistryTeam, error) {
 	domain = canonicalizeDomain(domain)
 	name = strings.TrimSpace(name)
@@ -202,21 +206,25 @@ func (c *RegistryClient) SetTeamVisibility(
 	if visibility != "public" && visibility != "private" {
 		return nil, fmt.Errorf("visibility must be 'public' or 'private'")
 	}
-	if teamKey == nil {
+	if signingKeyPublic(teamKey) == nil {
 		return nil, fmt.Errorf("team signing key is required")
 	}
 
 	path := "/v1/namespaces/" + urlPathEscape(domain) + "/teams/" + urlPathEscape(name) + "/visibility"
 	var out RegistryTeam
+	headers, err := signedNamespaceHeaders(ctx, domain, "set_team_visibility", teamKey, map[string]string{
+		"team_name":  name,
+		"visibility": visibility,
+	})
+	if err != nil {
+		return nil, err
+	}
 	if err := c.requestJSON(
 		ctx,
 		http.MethodPost,
 		registryURL,
 		path,
-		signedNamespaceHeaders(domain, "set_team_visibility", teamKey, map[string]string{
-			"team_name":  name,
-			"visibility": visibility,
-		}),
+		headers,
 		teamVisibilityRequest{Visibility: visibility},
 		&out,
 	); err != nil {
@@ -237,13 +245,17 @@ func (c *RegistryClient) GetTeam(
 	registryURL string,
 	domain string,
 	name string,
-	signingKey ed25519.PrivateKey,
+	signingKey crypto.Signer,
 ) (*RegistryTeam, error) {
 	domain = canonicalizeDomain(domain)
 	name = strings.TrimSpace(name)
 	path := "/v1/namespaces/" + urlPathEscape(domain) + "/teams/" + urlPathEscape(name)
 	var out RegistryTeam
-	if err := c.requestJSON(ctx, http.MethodGet, registryURL, path, optionalSignedPathHeaders(http.MethodGet, path, signingKey), nil, &out); err != nil {
+	headers, err := optionalSignedPathHeaders(ctx, http.MethodGet, path, signingKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.requestJSON(ctx, http.MethodGet, registryURL, path, headers, nil, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -256,7 +268,7 @@ func (c *RegistryClient) DeleteTeam(
 	registryURL string,
 	domain string,
 	name string,
-	controllerKey ed25519.PrivateKey,
+	controllerKey crypto.Signer,
 	reason string,
 ) error {
 	domain = canonicalizeDomain(domain)
@@ -267,7 +279,7 @@ func (c *RegistryClient) DeleteTeam(
 	if name == "" {
 		return fmt.Errorf("team name is required")
 	}
-	if controllerKey == nil {
+	if signingKeyPublic(controllerKey) == nil {
 		return fmt.Errorf("controller signing key is required")
 	}
 
@@ -276,14 +288,18 @@ func (c *RegistryClient) DeleteTeam(
 	if strings.TrimSpace(reason) != "" {
 		body = deleteReasonRequest{Reason: strings.TrimSpace(reason)}
 	}
+	headers, err := signedNamespaceHeaders(ctx, domain, "delete_team", controllerKey, map[string]string{
+		"team_name": name,
+	})
+	if err != nil {
+		return err
+	}
 	return c.requestJSON(
 		ctx,
 		http.MethodDelete,
 		registryURL,
 		path,
-		signedNamespaceHeaders(domain, "delete_team", controllerKey, map[string]string{
-			"team_name": name,
-		}),
+		headers,
 		body,
 		nil,
 	)
@@ -297,14 +313,14 @@ func (c *RegistryClient) RegisterCertificate(
 	domain string,
 	name string,
 	cert *TeamCertificate,
-	teamKey ed25519.PrivateKey,
+	teamKey crypto.Signer,
 ) error {
 	domain = canonicalizeDomain(domain)
 	name = strings.TrimSpace(name)
 	if cert == nil {
 		return fmt.Errorf("certificate is required")
 	}
-	if teamKey == nil {
+	if signingKeyPublic(teamKey) == nil {
 		return fmt.Errorf("team signing key is required")
 	}
 	encodedCert, err := EncodeTeamCertificateHeader(cert)
@@ -313,15 +329,19 @@ func (c *RegistryClient) RegisterCertificate(
 	}
 
 	path := "/v1/namespaces/" + urlPathEscape(domain) + "/teams/" + urlPathEscape(name) + "/certificates"
+	headers, err := signedNamespaceHeaders(ctx, domain, "register_certificate", teamKey, map[string]string{
+		"team_name":      name,
+		"certificate_id": cert.CertificateID,
+	})
+	if err != nil {
+		return err
+	}
 	if err := c.requestJSON(
 		ctx,
 		http.MethodPost,
 		registryURL,
 		path,
-		signedNamespaceHeaders(domain, "register_certificate", teamKey, map[string]string{
-			"team_name":      name,
-			"certificate_id": cert.CertificateID,
-		}),
+		headers,
 		certificateRegisterRequest{
 			CertificateID: cert.CertificateID,
 			MemberDIDKey:  cert.MemberDIDKey,
@@ -355,7 +375,7 @@ func (c *RegistryClient) FetchTeamCertificate(
 	domain string,
 	name string,
 	certificateID string,
-	signingKey ed25519.PrivateKey,
+	signingKey crypto.Signer,
 ) (*TeamCertificate, error) {
 	domain = canonicalizeDomain(domain)
 	name = strings.TrimSpace(name)
@@ -369,13 +389,17 @@ func (c *RegistryClient) FetchTeamCertificate(
 	if certificateID == "" {
 		return nil, fmt.Errorf("certificate_id is required")
 	}
-	if signingKey == nil {
+	if signingKeyPublic(signingKey) == nil {
 		return nil, fmt.Errorf("signing key is required")
 	}
 
 	path := "/v1/namespaces/" + urlPathEscape(domain) + "/teams/" + urlPathEscape(name) + "/certificates/" + urlPathEscape(certificateID)
 	var out registryCertificateFetchResponse
-	if err := c.requestJSON(ctx, http.MethodGet, registryURL, path, signedPathHeaders(http.MethodGet, path, signingKey), nil, &out); err != nil {
+	headers, err := signedPathHeaders(ctx, http.MethodGet, path, signingKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.requestJSON(ctx, http.MethodGet, registryURL, path, headers, nil, &out); err != nil {
 		return nil, err
 	}
 	cert, err := DecodeTeamCertificateHeader(strings.TrimSpace(out.Certificate))
@@ -388,7 +412,7 @@ func (c *RegistryClient) FetchTeamCertificate(
 	if strings.TrimSpace(out.TeamID) != "" && strings.TrimSpace(cert.Team) != strings.TrimSpace(out.TeamID) {
 		return nil, fmt.Errorf("fetched certificate team_id %q does not match response team_id %q", cert.Team, out.TeamID)
 	}
-	if did := ComputeDIDKey(signingKey.Public().(ed25519.PublicKey)); strings.TrimSpace(cert.MemberDIDKey) != did {
+	if did := ComputeDIDKey(signingKeyPublic(signingKey)); strings.TrimSpace(cert.MemberDIDKey) != did {
 		return nil, fmt.Errorf("fetched certificate member_did_key %q does not match local signing key %q", cert.MemberDIDKey, did)
 	}
 	teamPub, err := ExtractPublicKey(strings.TrimSpace(cert.TeamDIDKey))
@@ -414,7 +438,7 @@ func (c *RegistryClient) ListCertificates(
 	domain string,
 	name string,
 	activeOnly bool,
-	signingKey ed25519.PrivateKey,
+	signingKey crypto.Signer,
 ) ([]RegistryCertificate, error) {
 	domain = canonicalizeDomain(domain)
 	name = strings.TrimSpace(name)
@@ -433,7 +457,11 @@ func (c *RegistryClient) ListCertificates(
 			path += "&cursor=" + urlQueryEscape(cursor)
 		}
 		var out certificateListResponse
-		if err := c.requestJSON(ctx, http.MethodGet, registryURL, path, optionalSignedPathHeaders(http.MethodGet, path, signingKey), nil, &out); err != nil {
+		headers, err := optionalSignedPathHeaders(ctx, http.MethodGet, path, signingKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.requestJSON(ctx, http.MethodGet, registryURL, path, headers, nil, &out); err != nil {
 			return nil, err
 		}
 		certificates = append(certificates, out.Certificates...)
@@ -467,7 +495,7 @@ func (c *RegistryClient) ResolveTeamMember(
 	domain string,
 	name string,
 	alias string,
-	signingKey ed25519.PrivateKey,
+	signingKey crypto.Signer,
 ) (*TeamMemberReference, error) {
 	domain = canonicalizeDomain(domain)
 	name = strings.TrimSpace(name)
@@ -483,7 +511,11 @@ func (c *RegistryClient) ResolveTeamMember(
 	}
 	path := "/v1/namespaces/" + urlPathEscape(domain) + "/teams/" + urlPathEscape(name) + "/members/" + urlPathEscape(alias)
 	var out TeamMemberReference
-	if err := c.requestJSON(ctx, http.MethodGet, registryURL, path, optionalSignedPathHeaders(http.MethodGet, path, signingKey), nil, &out); err != nil {
+	headers, err := optionalSignedPathHeaders(ctx, http.MethodGet, path, signingKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.requestJSON(ctx, http.MethodGet, registryURL, path, headers, nil, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -497,7 +529,7 @@ func (c *RegistryClient) RevokeCertificate(
 	domain string,
 	name string,
 	certificateID string,
-	teamKey ed25519.PrivateKey,
+	teamKey crypto.Signer,
 ) error {
 	domain = canonicalizeDomain(domain)
 	name = strings.TrimSpace(name)
@@ -505,20 +537,24 @@ func (c *RegistryClient) RevokeCertificate(
 	if certificateID == "" {
 		return fmt.Errorf("certificate_id is required")
 	}
-	if teamKey == nil {
+	if signingKeyPublic(teamKey) == nil {
 		return fmt.Errorf("team signing key is required")
 	}
 
 	path := "/v1/namespaces/" + urlPathEscape(domain) + "/teams/" + urlPathEscape(name) + "/certificates/revoke"
+	headers, err := signedNamespaceHeaders(ctx, domain, "revoke_certificate", teamKey, map[string]string{
+		"team_name":      name,
+		"certificate_id": certificateID,
+	})
+	if err != nil {
+		return err
+	}
 	return c.requestJSON(
 		ctx,
 		http.MethodPost,
 		registryURL,
 		path,
-		signedNamespaceHeaders(domain, "revoke_certificate", teamKey, map[string]string{
-			"team_name":      name,
-			"certificate_id": certificateID,
-		}),
+		headers,
 		certificateRevokeRequest{
 			CertificateID: certificateID,
 		},
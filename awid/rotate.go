@@ -42,7 +42,7 @@ type RotateKeyResponse struct {
 // The rotation_signature is computed by signing the canonical rotation payload
 // with the current (old) key.
 func (c *Client) RotateKey(ctx context.Context, req *RotateKeyRequest) (*RotateKeyResponse, error) {
-	if c.signingKey == nil {
+	if c.signer == nil {
 		return nil, fmt.Errorf("RotateKey: client has no signing key")
 	}
 
@@ -50,14 +50,17 @@ func (c *Client) RotateKey(ctx context.Context, req *RotateKeyRequest) (*RotateK
 
 	// Sign the rotation payload with the old (current) key.
 	payload := CanonicalRotationJSON(c.did, req.NewDID, ts)
-	sig := ed25519.Sign(c.signingKey, []byte(payload))
+	sig, err := c.sign(ctx, []byte(payload))
+	if err != nil {
+		return nil, err
+	}
 
 	wire := &rotateKeyWireRequest{
 		Custody:           req.Custody,
 		Timestamp:         ts,
 		NewDID:            req.NewDID,
 		NewPublicKey:      base64.RawURLEncoding.EncodeToString(req.NewPublicKey),
-		RotationSignature: base64.RawStdEncoding.EncodeToString(sig),
+		RotationSignature: sig,
 	}
 
 	var resp RotateKeyResponse
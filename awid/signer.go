@@ -0,0 +1,200 @@
+package awid
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// Signer produces Ed25519 signatures for one identity key. Implementations
+// may keep the private key outside the process; Sign must return a raw
+// 64-byte signature over payload.
+type Signer interface {
+	PublicKey() ed25519.PublicKey
+	Sign(ctx context.Context, payload []byte) ([]byte, error)
+}
+
+// KeySigner signs with an in-memory private key.
+type KeySigner struct {
+	key ed25519.PrivateKey
+}
+
+// NewKeySigner wraps an in-memory private key.
+func NewKeySigner(key ed25519.PrivateKey) *KeySigner {
+	return &KeySigner{key: key}
+}
+
+func (s *KeySigner) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+func (s *KeySigner) Sign(_ context.Context, payload []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, payload), nil
+}
+
+// PrivateKey returns the wrapped key.
+func (s *KeySigner) PrivateKey() ed25519.PrivateKey { return s.key }
+
+// SSHAgentSigner signs through an ssh-agent protocol socket holding the
+// Ed25519 key whose public half is pub. The key never enters this process.
+type SSHAgentSigner struct {
+	socketPath string
+	pub        ed25519.PublicKey
+}
+
+// NewSSHAgentSigner returns a signer backed by the agent at socketPath.
+func NewSSHAgentSigner(socketPath string, pub ed25519.PublicKey) (*SSHAgentSigner, error) {
+	socketPath = strings.TrimSpace(socketPath)
+	if socketPath == "" {
+		return nil, errors.New("ssh-agent socket path is required")
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid ed25519 public key")
+	}
+	return &SSHAgentSigner{socketPath: socketPath, pub: pub}, nil
+}
+
+func (s *SSHAgentSigner) PublicKey() ed25519.PublicKey { return s.pub }
+
+func (s *SSHAgentSigner) Sign(ctx context.Context, payload []byte) ([]byte, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", s.socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("ssh-agent: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	sshPub, err := ssh.NewPublicKey(s.pub)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := agent.NewClient(conn).Sign(sshPub, payload)
+	if err != nil {
+		return nil, fmt.Errorf("ssh-agent sign with %s: %w", ComputeDIDKey(s.pub), err)
+	}
+	if sig.Format != ssh.KeyAlgoED25519 {
+		return nil, fmt.Errorf("ssh-agent returned a %s signature", sig.Format)
+	}
+	return checkSignature(s.pub, payload, sig.Blob)
+}
+
+// SignerDIDKeyEnv is set in a CommandSigner's environment to the did:key it
+// must sign for, so one command can serve identity, controller and team keys.
+const SignerDIDKeyEnv = "AW_SIGNER_DID_KEY"
+
+// CommandSigner runs an external program for every signature. The payload
+// is written to its stdin and it prints the signature, base64-encoded, on
+// stdout.
+type CommandSigner struct {
+	argv []string
+	pub  ed25519.PublicKey
+}
+
+// NewCommandSigner returns a signer that runs argv for the key pub.
+func NewCommandSigner(argv []string, pub ed25519.PublicKey) (*CommandSigner, error) {
+	if len(argv) == 0 || strings.TrimSpace(argv[0]) == "" {
+		return nil, errors.New("signing command is required")
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid ed25519 public key")
+	}
+	return &CommandSigner{argv: append([]string(nil), argv...), pub: pub}, nil
+}
+
+func (s *CommandSigner) PublicKey() ed25519.PublicKey { return s.pub }
+
+func (s *CommandSigner) Sign(ctx context.Context, payload []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, s.argv[0], s.argv[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), SignerDIDKeyEnv+"="+ComputeDIDKey(s.pub))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("signing command: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("signing command: %w", err)
+	}
+	encoded := strings.TrimRight(strings.TrimSpace(stdout.String()), "=")
+	sig, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("signing command: output is not base64: %w", err)
+	}
+	return checkSignature(s.pub, payload, sig)
+}
+
+// checkSignature rejects a signature from an out-of-process signer that
+// does not verify, so a misconfigured signer fails locally instead of as an
+// opaque 401.
+func checkSignature(pub ed25519.PublicKey, payload, sig []byte) ([]byte, error) {
+	if len(sig) != ed25519.SignatureSize || !ed25519.Verify(pub, payload, sig) {
+		return nil, fmt.Errorf("signer returned an invalid signature for %s", ComputeDIDKey(pub))
+	}
+	return sig, nil
+}
+
+// signerPrivateKey returns the in-memory key behind s, or nil when the key
+// lives elsewhere.
+func signerPrivateKey(s Signer) ed25519.PrivateKey {
+	if ks, ok := s.(*KeySigner); ok && ks != nil {
+		return ks.key
+	}
+	return nil
+}
+
+// CryptoSigner adapts s to crypto.Signer, so a key kept outside the process
+// can stand in for an ed25519.PrivateKey wherever the registry client takes
+// a namespace controller or team key. Registry calls sign through s with
+// their own context; a direct Sign call has no deadline.
+func CryptoSigner(s Signer) crypto.Signer {
+	if key := signerPrivateKey(s); key != nil {
+		return key
+	}
+	return cryptoSigner{signer: s}
+}
+
+type cryptoSigner struct {
+	signer Signer
+}
+
+func (c cryptoSigner) Public() crypto.PublicKey { return c.signer.PublicKey() }
+
+func (c cryptoSigner) Sign(_ io.Reader, message []byte, _ crypto.SignerOpts) ([]byte, error) {
+	return c.signer.Sign(context.Background(), message)
+}
+
+// signWithKey signs payload with key, passing ctx to an adapted Signer.
+func signWithKey(ctx context.Context, key crypto.Signer, payload []byte) ([]byte, error) {
+	if adapted, ok := key.(cryptoSigner); ok {
+		return adapted.signer.Sign(ctx, payload)
+	}
+	return key.Sign(nil, payload, crypto.Hash(0))
+}
+
+// signingKeyPublic returns key's Ed25519 public key, or nil when key is
+// missing (including a nil ed25519.PrivateKey) or not Ed25519.
+func signingKeyPublic(key crypto.Signer) ed25519.PublicKey {
+	if key == nil {
+		return nil
+	}
+	if priv, ok := key.(ed25519.PrivateKey); ok && len(priv) != ed25519.PrivateKeySize {
+		return nil
+	}
+	pub, _ := key.Public().(ed25519.PublicKey)
+	return pub
+}
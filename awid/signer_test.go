@@ -0,0 +1,201 @@
+package awid
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func startTestSSHAgent(t *testing.T, keys ...ed25519.PrivateKey) string {
+	t.Helper()
+	keyring := agent.NewKeyring()
+	for _, key := range keys {
+		if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dir, err := os.MkdirTemp("", "aw-agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	path := filepath.Join(dir, "agent.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+	return path
+}
+
+func TestSSHAgentSignerSignsWithAgentKey(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := NewSSHAgentSigner(startTestSSHAgent(t, priv), pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte(`{"team_id":"backend:acme.com"}`)
+	sig, err := signer.Sign(context.Background(), payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(pub, payload, sig) {
+		t.Fatal("agent signature does not verify")
+	}
+
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing, err := NewSSHAgentSigner(startTestSSHAgent(t, priv), otherPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := missing.Sign(context.Background(), payload); err == nil {
+		t.Fatal("expected an error for a key the agent does not hold")
+	}
+}
+
+func TestCommandSignerVerifiesOutput(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte("rotation payload")
+	dir := t.TempDir()
+	script := func(name, output string) string {
+		path := filepath.Join(dir, name)
+		body := "#!/bin/sh\ncat >/dev/null\necho " + output + "\n"
+		if err := os.WriteFile(path, []byte(body), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	good := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, payload))
+	signer, err := NewCommandSigner([]string{script("good", good)}, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signer.Sign(context.Background(), payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(pub, payload, sig) {
+		t.Fatal("command signature does not verify")
+	}
+
+	keyed := filepath.Join(dir, "keyed")
+	body := "#!/bin/sh\ncat >/dev/null\n[ \"$" + SignerDIDKeyEnv + "\" = \"" + ComputeDIDKey(pub) + "\" ] || exit 3\necho " + good + "\n"
+	if err := os.WriteFile(keyed, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	signer, err = NewCommandSigner([]string{keyed}, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.Sign(context.Background(), payload); err != nil {
+		t.Fatalf("command did not get %s: %v", SignerDIDKeyEnv, err)
+	}
+
+	wrong := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte("something else")))
+	signer, err = NewCommandSigner([]string{script("wrong", wrong)}, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.Sign(context.Background(), payload); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Fatalf("err=%v, want invalid signature", err)
+	}
+}
+
+func TestNewWithCertificateSignerAuthenticatesThroughAgent(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	did := ComputeDIDKey(pub)
+	cert := testTeamCertificate(t, priv, "alice")
+
+	var gotAuth, gotTimestamp string
+	var gotRaw []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotTimestamp = r.Header.Get("X-AWEB-Timestamp")
+		gotRaw, _ = io.ReadAll(r.Body)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"message_id":   "msg-1",
+			"status":       "delivered",
+			"delivered_at": "2026-04-13T00:00:00Z",
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	signer, err := NewSSHAgentSigner(startTestSSHAgent(t, priv), pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewWithCertificateSigner(server.URL, signer, cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.SigningKey() != nil {
+		t.Fatal("SigningKey() should be nil for an external signer")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := c.SendMessage(ctx, &SendMessageRequest{ToAlias: "bob", Body: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := strings.Fields(gotAuth)
+	if len(fields) != 3 || fields[0] != "DIDKey" || fields[1] != did {
+		t.Fatalf("Authorization=%q", gotAuth)
+	}
+	sig, err := base64.RawStdEncoding.DecodeString(fields[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(pub, certAuthSignPayload(cert.Team, gotTimestamp, gotRaw), sig) {
+		t.Fatal("request signature does not verify")
+	}
+	var body map[string]any
+	if err := json.Unmarshal(gotRaw, &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["from_did"] != did || body["signature"] == "" {
+		t.Fatalf("message not signed by agent key: %v", body)
+	}
+}
@@ -2,6 +2,7 @@ package awid
 
 import (
 	"bytes"
+	"context"
 	"crypto/ed25519"
 	"crypto/sha256"
 	"encoding/base64"
@@ -122,6 +123,15 @@ func SignMessage(key ed25519.PrivateKey, env *MessageEnvelope) (string, error) {
 	return base64.RawStdEncoding.EncodeToString(sig), nil
 }
 
+// SignMessageWith is SignMessage for a Signer.
+func SignMessageWith(ctx context.Context, signer Signer, env *MessageEnvelope) (string, error) {
+	sig, err := signer.Sign(ctx, []byte(CanonicalJSON(env)))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawStdEncoding.EncodeToString(sig), nil
+}
+
 // CanonicalJSONValue builds canonical JSON for an arbitrary JSON-compatible
 // value. It is used for generic DIDKey-authenticated payload signing on
 // the aw id sign / aw id request code path.
@@ -240,6 +250,14 @@ func SignIdentityGrantCredential(key ed25519.PrivateKey, method string, target *
 	if key == nil {
 		return nil, fmt.Errorf("signing key is required")
 	}
+	return SignIdentityGrantCredentialWith(context.Background(), NewKeySigner(key), method, target, grantID, body, timestamp)
+}
+
+// SignIdentityGrantCredentialWith is SignIdentityGrantCredential for a Signer.
+func SignIdentityGrantCredentialWith(ctx context.Context, signer Signer, method string, target *url.URL, grantID string, body []byte, timestamp string) (*IdentityGrantCredential, error) {
+	if signer == nil {
+		return nil, fmt.Errorf("signing key is required")
+	}
 	if target == nil || target.Scheme == "" || target.Host == "" {
 		return nil, fmt.Errorf("target URL is required")
 	}
@@ -258,7 +276,7 @@ func SignIdentityGrantCredential(key ed25519.PrivateKey, method string, target *
 		"grant_id":    grantID,
 		"body_sha256": bodyHash,
 	}
-	signedDIDKey, signature, canonical, err := SignArbitraryPayload(key, payload, timestamp)
+	signedDIDKey, signature, canonical, err := SignArbitraryPayloadWith(ctx, signer, payload, timestamp)
 	if err != nil {
 		return nil, err
 	}
@@ -293,6 +311,14 @@ func SignArbitraryPayload(key ed25519.PrivateKey, payload map[string]any, timest
 	if key == nil {
 		return "", "", "", fmt.Errorf("signing key is required")
 	}
+	return SignArbitraryPayloadWith(context.Background(), NewKeySigner(key), payload, timestamp)
+}
+
+// SignArbitraryPayloadWith is SignArbitraryPayload for a Signer.
+func SignArbitraryPayloadWith(ctx context.Context, signer Signer, payload map[string]any, timestamp string) (didKey string, signature string, canonical string, err error) {
+	if signer == nil {
+		return "", "", "", fmt.Errorf("signing key is required")
+	}
 	timestamp = strings.TrimSpace(timestamp)
 	if timestamp == "" {
 		return "", "", "", fmt.Errorf("timestamp is required")
@@ -314,8 +340,11 @@ func SignArbitraryPayload(key ed25519.PrivateKey, payload map[string]any, timest
 	if err != nil {
 		return "", "", "", err
 	}
-	didKey = ComputeDIDKey(key.Public().(ed25519.PublicKey))
-	sig := ed25519.Sign(key, []byte(canonical))
+	didKey = ComputeDIDKey(signer.PublicKey())
+	sig, err := signer.Sign(ctx, []byte(canonical))
+	if err != nil {
+		return "", "", "", err
+	}
 	return didKey, base64.RawStdEncoding.EncodeToString(sig), canonical, nil
 }
 
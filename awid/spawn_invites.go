@@ -98,7 +98,7 @@ func (c *Client) AcceptSpawnInvite(ctx context.Context, req *SpawnAcceptInviteRe
 	if strings.TrimSpace(req.DID) == "" {
 		return nil, fmt.Errorf("aweb: did is required for spawn accept-invite")
 	}
-	if c.signingKey == nil {
+	if c.signer == nil {
 		return nil, fmt.Errorf("aweb: spawn accept-invite requires a signing key")
 	}
 	if c.did == "" {
@@ -112,7 +112,10 @@ func (c *Client) AcceptSpawnInvite(ctx context.Context, req *SpawnAcceptInviteRe
 	if err != nil {
 		return nil, err
 	}
-	headers := onboardingDIDKeyHeaders(http.MethodPost, spawnAcceptInvitePath, bodyBytes, c.signingKey)
+	headers, err := onboardingDIDKeyHeaders(ctx, http.MethodPost, spawnAcceptInvitePath, bodyBytes, c.signer)
+	if err != nil {
+		return nil, err
+	}
 
 	var out SpawnAcceptInviteResponse
 	if err := postJSONWithHeaders(ctx, c.baseURL, spawnAcceptInvitePath, bodyBytes, headers, &out); err != nil {
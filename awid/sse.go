@@ -19,14 +19,22 @@ type SSEEvent struct {
 //
 // It is intentionally minimal; callers can unmarshal Data as JSON based on Event.
 type SSEStream struct {
-	body io.ReadCloser
-	r    *bufio.Reader
+	body        io.ReadCloser
+	r           *bufio.Reader
+	lastEventID string
 }
 
 func NewSSEStream(body io.ReadCloser) *SSEStream {
 	return &SSEStream{body: body, r: bufio.NewReader(body)}
 }
 
+// LastEventID returns the most recent id the stream sent. Like a browser
+// EventSource, it persists across events that carry no id of their own, so
+// a reconnect can send it as Last-Event-ID.
+func (s *SSEStream) LastEventID() string {
+	return s.lastEventID
+}
+
 func (s *SSEStream) Close() error {
 	if s.body == nil {
 		return nil
@@ -81,7 +89,10 @@ func (s *SSEStream) Next() (*SSEEvent, error) {
 		case "data":
 			dataLines = append(dataLines, value)
 		case "id":
-			eventID = value
+			if !strings.ContainsRune(value, 0) {
+				eventID = value
+				s.lastEventID = value
+			}
 		case "retry":
 			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
 				retry = ms
@@ -71,3 +71,28 @@ func TestSSEStreamIgnoresInvalidRetry(t *testing.T) {
 		t.Fatalf("retry=%d", ev.Retry)
 	}
 }
+
+func TestSSEStreamKeepsLastEventIDAcrossEvents(t *testing.T) {
+	t.Parallel()
+
+	stream := NewSSEStream(io.NopCloser(strings.NewReader(
+		"id: 7\n" +
+			"data: first\n" +
+			"\n" +
+			"data: second\n" +
+			"\n",
+	)))
+
+	for _, want := range []string{"first", "second"} {
+		ev, err := stream.Next()
+		if err != nil {
+			t.Fatalf("Next returned error: %v", err)
+		}
+		if ev.Data != want {
+			t.Fatalf("data=%q want %q", ev.Data, want)
+		}
+		if stream.LastEventID() != "7" {
+			t.Fatalf("last id=%q after %q", stream.LastEventID(), want)
+		}
+	}
+}
@@ -1172,6 +1172,7 @@ type sendResponse struct {
 	Participants     []awid.ChatParticipant
 	TargetsConnected []string
 	TargetsLeft      []string
+	Queued           bool
 }
 
 // Send sends a message to target agents and optionally waits for a reply.
@@ -1215,6 +1216,7 @@ func Send(ctx context.Context, client *awid.Client, myAlias string, targets []st
 			return sendCommon(ctx, client, client.ChatStream, sendResponse{
 				SessionID: sessionID,
 				MessageID: msgResp.MessageID,
+				Queued:    msgResp.Queued,
 			}, myAlias, targets, message, waitSeconds, opts, &sentAt, callback)
 		} else if findErr != nil &&
 			strings.Contains(findErr.Error(), "multiple conversations match") {
@@ -1232,6 +1234,7 @@ func Send(ctx context.Context, client *awid.Client, myAlias string, targets []st
 		Participants:     createResp.Participants,
 		TargetsConnected: createResp.TargetsConnected,
 		TargetsLeft:      createResp.TargetsLeft,
+		Queued:           createResp.Queued,
 	}, myAlias, targets, message, waitSeconds, opts, &sentAt, callback)
 }
 
@@ -1266,6 +1269,9 @@ func sendCommon(ctx context.Context, client *awid.Client, openStream streamOpene
 	}
 
 	if opts.Leaving {
+		if resp.Queued {
+			result.Status = awid.MessageStatusQueued
+		}
 		return result, nil
 	}
 
@@ -45,7 +45,7 @@ type Event struct {
 type SendResult struct {
 	SessionID          string  `json:"session_id"`
 	MessageID          string  `json:"message_id,omitempty"`
-	Status             string  `json:"status"` // sent, replied, sender_left, pending, targets_left, timeout
+	Status             string  `json:"status"` // sent, queued, replied, sender_left, pending, targets_left, timeout
 	TargetAgent        string  `json:"target_agent,omitempty"`
 	Reply              string  `json:"reply,omitempty"`
 	Events             []Event `json:"events"`
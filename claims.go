@@ -1,6 +1,9 @@
 package aweb
 
-import "context"
+import (
+	"context"
+	"strings"
+)
 
 type ClaimView struct {
 	BeadID      string `json:"bead_id"`
@@ -17,6 +20,12 @@ type ClaimsResponse struct {
 }
 
 func (c *Client) ClaimsList(ctx context.Context, workspaceID string, limit int) (*ClaimsResponse, error) {
+	return c.ClaimsListPage(ctx, workspaceID, limit, "")
+}
+
+// ClaimsListPage is ClaimsList starting at cursor, the NextCursor of the
+// previous page. An empty cursor lists the first page.
+func (c *Client) ClaimsListPage(ctx context.Context, workspaceID string, limit int, cursor string) (*ClaimsResponse, error) {
 	path := "/v1/claims"
 	sep := "?"
 	if workspaceID != "" {
@@ -25,6 +34,10 @@ func (c *Client) ClaimsList(ctx context.Context, workspaceID string, limit int)
 	}
 	if limit > 0 {
 		path += sep + "limit=" + itoa(limit)
+		sep = "&"
+	}
+	if cursor = strings.TrimSpace(cursor); cursor != "" {
+		path += sep + "cursor=" + urlQueryEscape(cursor)
 	}
 	var out ClaimsResponse
 	if err := c.Get(ctx, path, &out); err != nil {
@@ -33,6 +33,15 @@ func NewWithCertificate(baseURL string, signingKey ed25519.PrivateKey, cert *awi
 	return &Client{Client: c}, nil
 }
 
+// NewWithCertificateSigner is NewWithCertificate for a key held by signer.
+func NewWithCertificateSigner(baseURL string, signer awid.Signer, cert *awid.TeamCertificate) (*Client, error) {
+	c, err := awid.NewWithCertificateSigner(baseURL, signer, cert)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{Client: c}, nil
+}
+
 // NewWithGrant creates a client authenticated as an identity-grant session,
 // signing each request with the grant's session key.
 func NewWithGrant(baseURL string, sessionKey ed25519.PrivateKey, grantID string) (*Client, error) {
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto"
 	"crypto/ed25519"
 	"crypto/sha256"
 	"encoding/json"
@@ -638,7 +640,7 @@ func runA2APublish(ctx context.Context, cardURL string) (a2aPublishOutput, error
 	}, nil
 }
 
-func loadA2APublishSigningKey(selection *awconfig.Selection) (ed25519.PrivateKey, error) {
+func loadA2APublishSigningKey(selection *awconfig.Selection) (crypto.Signer, error) {
 	if selection == nil {
 		return nil, usageError("A2A publication requires a current global self-custodial identity")
 	}
@@ -649,11 +651,11 @@ func loadA2APublishSigningKey(selection *awconfig.Selection) (ed25519.PrivateKey
 	if signingKeyPath == "" {
 		signingKeyPath = awconfig.WorktreeSigningKeyPath(selection.WorkingDir)
 	}
-	signingKey, err := awid.LoadSigningKey(signingKeyPath)
+	signer, err := loadClientSigner(signingKeyPath, strings.TrimSpace(selection.DID))
 	if err != nil {
 		return nil, fmt.Errorf("load A2A publication signing key %s: %w", signingKeyPath, err)
 	}
-	return signingKey, nil
+	return awid.CryptoSigner(signer), nil
 }
 
 func routeIDFromA2ACardURL(cardURL string) (string, error) {
@@ -831,12 +833,39 @@ func formatA2ATask(task a2a.Task) string {
 	if text := a2aTaskText(task); text != "" {
 		sb.WriteString(fmt.Sprintf("Text:    %s\n", text))
 	}
+	for _, artifact := range task.Artifacts {
+		for _, part := range artifact.Parts {
+			switch {
+			case len(part.Data) > 0:
+				var compact bytes.Buffer
+				if err := json.Compact(&compact, part.Data); err != nil {
+					compact.Write(part.Data)
+				}
+				sb.WriteString(fmt.Sprintf("Data:    %s\n", compact.String()))
+			case part.URL != "":
+				sb.WriteString(fmt.Sprintf("File:    %s\n", a2aFileLabel(part, part.URL)))
+			case len(part.Raw) > 0:
+				sb.WriteString(fmt.Sprintf("File:    %s\n", a2aFileLabel(part, fmt.Sprintf("%d bytes inline", len(part.Raw)))))
+			}
+		}
+	}
 	if token, _ := task.Metadata["task_bearer_token"].(string); token != "" {
 		sb.WriteString(fmt.Sprintf("Token:   %s\n", token))
 	}
 	return sb.String()
 }
 
+func a2aFileLabel(part a2a.Part, location string) string {
+	label := location
+	if part.Filename != "" {
+		label = part.Filename + " (" + location + ")"
+	}
+	if part.MediaType != "" {
+		label += " " + part.MediaType
+	}
+	return label
+}
+
 func a2aTaskText(task a2a.Task) string {
 	if task.Status.Message != nil {
 		for _, part := range task.Status.Message.Parts {
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awid"
+	awrun "github.com/awebai/aw/run"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// agentdSocketEnv overrides where `aw agentd` listens.
+const agentdSocketEnv = "AW_AGENTD_SOCK"
+
+var (
+	agentdTTL           time.Duration
+	agentdAuthorityKeys bool
+)
+
+var agentdCmd = &cobra.Command{
+	Use:   "agentd [identity-dir...]",
+	Short: "Hold unlocked identity keys for other aw commands",
+	Long: `Load the signing key of each identity once, unlocking encrypted keys, and
+sign for aw commands of the same user over an owner-only Unix socket
+(~/.awid/agentd.sock, or $AW_AGENTD_SOCK) until interrupted or --ttl expires.
+
+The socket speaks the ssh-agent protocol, limited to listing and signing,
+plus an extension serving the team certificates of the held keys, cached
+until their files change. While it runs, aw builds its authenticated client
+from the daemon's certificate and signs with the daemon's copy of the key,
+so signing.key can be removed from the worktree. Without arguments
+the identity of the current directory is loaded. --authority-keys also loads
+every namespace controller and team key under ~/.awid, so an encrypted key
+is unlocked once instead of on every command. Private keys and passphrases
+never leave the daemon.`,
+	RunE: runAgentd,
+}
+
+func init() {
+	agentdCmd.GroupID = groupIdentity
+	agentdCmd.Flags().DurationVar(&agentdTTL, "ttl", 8*time.Hour, "Forget the keys and exit after this long (0: until interrupted)")
+	agentdCmd.Flags().BoolVar(&agentdAuthorityKeys, "authority-keys", false, "Also hold the namespace controller and team keys")
+	rootCmd.AddCommand(agentdCmd)
+}
+
+func runAgentd(cmd *cobra.Command, args []string) error {
+	keys, err := agentdLoadKeys(args, agentdAuthorityKeys)
+	if err != nil {
+		return err
+	}
+	served, err := newAgentdServer(keys)
+	if err != nil {
+		return err
+	}
+	path, err := agentdSocketPath()
+	if err != nil {
+		return err
+	}
+	listener, err := listenAgentdSocket(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+	defer listener.Close()
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if agentdTTL > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, agentdTTL)
+		defer cancel()
+	}
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+	for _, key := range keys {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Holding %s\n", awid.ComputeDIDKey(key.Public().(ed25519.PublicKey)))
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Agent daemon listening on %s\n", path)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			_ = agent.ServeAgent(served, conn)
+		}()
+	}
+}
+
+// agentdLoadKeys loads the signing key of each identity dir, or of the
+// current directory's identity when none is given, plus the controller and
+// team keys when authority is set.
+func agentdLoadKeys(dirs []string, authority bool) ([]ed25519.PrivateKey, error) {
+	var keyPaths []string
+	if len(dirs) == 0 {
+		workingDir, _ := os.Getwd()
+		home, err := identityHomeForDir(workingDir)
+		if err != nil && !authority {
+			return nil, err
+		}
+		if err == nil {
+			keyPath, err := awconfig.IdentityHomePath(home, "signing.key")
+			if err != nil {
+				return nil, err
+			}
+			if _, statErr := os.Stat(keyPath); statErr == nil || !authority {
+				keyPaths = append(keyPaths, keyPath)
+			}
+		}
+	}
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(strings.TrimSpace(dir))
+		if err != nil {
+			return nil, err
+		}
+		home, err := identityHomeForDir(abs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+		keyPath, err := awconfig.IdentityHomePath(home, "signing.key")
+		if err != nil {
+			return nil, err
+		}
+		keyPaths = append(keyPaths, keyPath)
+	}
+	if authority {
+		authorityKeys, err := authorityKeyFiles()
+		if err != nil {
+			return nil, err
+		}
+		keyPaths = append(keyPaths, authorityKeys...)
+	}
+	if len(keyPaths) == 0 {
+		return nil, usageError("no keys to hold")
+	}
+	var keys []ed25519.PrivateKey
+	seen := map[string]bool{}
+	for _, keyPath := range keyPaths {
+		key, err := awid.LoadSigningKey(keyPath)
+		if err != nil {
+			return nil, err
+		}
+		did := awid.ComputeDIDKey(key.Public().(ed25519.PublicKey))
+		if seen[did] {
+			continue
+		}
+		seen[did] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func agentdSocketPath() (string, error) {
+	if path := strings.TrimSpace(os.Getenv(agentdSocketEnv)); path != "" {
+		return path, nil
+	}
+	return awconfig.PathInAWIDState("agentd.sock")
+}
+
+func listenAgentdSocket(path string) (net.Listener, error) {
+	return awrun.ListenUnixSocket(path, "agentd socket")
+}
+
+// dialAgentd connects to a running `aw agentd`, returning nil when none is
+// listening.
+func dialAgentd() (net.Conn, string) {
+	path, err := agentdSocketPath()
+	if err != nil {
+		return nil, ""
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, ""
+	}
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		debugLog("agentd: %v", err)
+		return nil, ""
+	}
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+	return conn, path
+}
+
+// agentdSigner returns a signer backed by a running `aw agentd` that holds
+// the key for did, or nil when no daemon is running or it lacks the key.
+func agentdSigner(did string) awid.Signer {
+	if strings.TrimSpace(did) == "" {
+		return nil
+	}
+	pub, err := awid.ExtractPublicKey(did)
+	if err != nil {
+		return nil
+	}
+	conn, path := dialAgentd()
+	if conn == nil {
+		return nil
+	}
+	defer conn.Close()
+	held, err := agent.NewClient(conn).List()
+	if err != nil {
+		debugLog("agentd: list keys: %v", err)
+		return nil
+	}
+	want, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return nil
+	}
+	for _, key := range held {
+		if key.Type() == want.Type() && string(key.Marshal()) == string(want.Marshal()) {
+			signer, err := awid.NewSSHAgentSigner(path, pub)
+			if err != nil {
+				return nil
+			}
+			return signer
+		}
+	}
+	return nil
+}
+
+// agentdCertificate returns the team certificate stored at certPath together
+// with a signer for its member key, from a running `aw agentd` that holds
+// the key: one round trip authenticates a client, with no certificate read
+// or key listing in the calling process. It returns nil when no daemon
+// serves the certificate.
+func agentdCertificate(certPath string) (*awid.TeamCertificate, awid.Signer) {
+	certPath, err := filepath.Abs(certPath)
+	if err != nil {
+		return nil, nil
+	}
+	conn, path := dialAgentd()
+	if conn == nil {
+		return nil, nil
+	}
+	defer conn.Close()
+	data, err := agent.NewClient(conn).Extension(agentdCertificateExtension, []byte(certPath))
+	if err != nil {
+		debugLog("agentd: certificate %s: %v", certPath, err)
+		return nil, nil
+	}
+	var cert awid.TeamCertificate
+	if err := json.Unmarshal(data, &cert); err != nil {
+		return nil, nil
+	}
+	pub, err := awid.ExtractPublicKey(cert.MemberDIDKey)
+	if err != nil {
+		return nil, nil
+	}
+	signer, err := awid.NewSSHAgentSigner(path, pub)
+	if err != nil {
+		return nil, nil
+	}
+	return &cert, signer
+}
+
+// agentdCertificateExtension is the ssh-agent extension through which aw
+// agentd serves the team certificates of the keys it holds. The request
+// carries the certificate's absolute path; the reply is the certificate.
+const agentdCertificateExtension = "certificate@aweb.ai"
+
+var errAgentdReadOnly = errors.New("aw agentd only lists keys and signs")
+
+// agentdServer is the agent `aw agentd` serves: a keyring that refuses the
+// ssh-agent operations that would let a client add, remove or lock its
+// keys, plus the certificate extension. Certificates are cached until their
+// file changes.
+type agentdServer struct {
+	agent.ExtendedAgent
+
+	mu    sync.Mutex
+	certs map[string]agentdCachedCertificate
+}
+
+type agentdCachedCertificate struct {
+	modTime time.Time
+	size    int64
+	data    []byte
+}
+
+func newAgentdServer(keys []ed25519.PrivateKey) (*agentdServer, error) {
+	keyring := agent.NewKeyring().(agent.ExtendedAgent)
+	for _, key := range keys {
+		if err := keyring.Add(agent.AddedKey{PrivateKey: key, Comment: awid.ComputeDIDKey(key.Public().(ed25519.PublicKey))}); err != nil {
+			return nil, err
+		}
+	}
+	return &agentdServer{ExtendedAgent: keyring, certs: map[string]agentdCachedCertificate{}}, nil
+}
+
+func (*agentdServer) Add(agent.AddedKey) error       { return errAgentdReadOnly }
+func (*agentdServer) Remove(ssh.PublicKey) error     { return errAgentdReadOnly }
+func (*agentdServer) RemoveAll() error               { return errAgentdReadOnly }
+func (*agentdServer) Lock(passphrase []byte) error   { return errAgentdReadOnly }
+func (*agentdServer) Unlock(passphrase []byte) error { return errAgentdReadOnly }
+
+func (s *agentdServer) Extension(extensionType string, contents []byte) ([]byte, error) {
+	if extensionType != agentdCertificateExtension {
+		return nil, agent.ErrExtensionUnsupported
+	}
+	return s.certificate(string(contents))
+}
+
+func (s *agentdServer) certificate(path string) ([]byte, error) {
+	if !filepath.IsAbs(path) {
+		return nil, errors.New("certificate path must be absolute")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cached, ok := s.certs[path]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.data, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cert awid.TeamCertificate
+	if err := json.Unmarshal(data, &cert); err != nil {
+		return nil, err
+	}
+	if !s.holds(cert.MemberDIDKey) {
+		return nil, errors.New("certificate member key is not held")
+	}
+	s.certs[path] = agentdCachedCertificate{modTime: info.ModTime(), size: info.Size(), data: data}
+	return data, nil
+}
+
+func (s *agentdServer) holds(did string) bool {
+	pub, err := awid.ExtractPublicKey(strings.TrimSpace(did))
+	if err != nil {
+		return false
+	}
+	want, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return false
+	}
+	held, err := s.List()
+	if err != nil {
+		return false
+	}
+	for _, key := range held {
+		if string(key.Marshal()) == string(want.Marshal()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awid"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func startTestAgentd(t *testing.T, keys ...ed25519.PrivateKey) string {
+	t.Helper()
+	served, err := newAgentdServer(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := os.MkdirTemp("", "aw-agentd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	path := filepath.Join(dir, "agentd.sock")
+	listener, err := listenAgentdSocket(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = agent.ServeAgent(served, conn)
+			}()
+		}
+	}()
+	return path
+}
+
+func TestLoadClientSignerUsesAgentdWithoutKeyFile(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(signerSSHAgentEnv, "")
+	t.Setenv(signerCommandEnv, "")
+	t.Setenv(agentdSocketEnv, startTestAgentd(t, priv))
+	missingKey := filepath.Join(t.TempDir(), "signing.key")
+
+	signer, err := loadClientSigner(missingKey, awid.ComputeDIDKey(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := signer.(*awid.SSHAgentSigner); !ok {
+		t.Fatalf("signer=%T, want *awid.SSHAgentSigner", signer)
+	}
+	payload := []byte("hello")
+	sig, err := signer.Sign(context.Background(), payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(pub, payload, sig) {
+		t.Fatal("agentd signature does not verify")
+	}
+
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadClientSigner(missingKey, awid.ComputeDIDKey(otherPub)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("err=%v, want fallback to the missing key file", err)
+	}
+}
+
+func TestAgentdRefusesKeyChanges(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.Dial("unix", startTestAgentd(t, priv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := agent.NewClient(conn)
+	if err := client.RemoveAll(); err == nil {
+		t.Fatal("RemoveAll succeeded against agentd")
+	}
+	keys, err := client.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 {
+		t.Fatalf("keys=%d, want 1", len(keys))
+	}
+}
+
+func TestAgentdKeepsNonSocketPathAndWritesToCommandStderr(t *testing.T) {
+	tmp := t.TempDir()
+	_, priv, err := awid.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeSelectionFixtureForTest(t, tmp, testSelectionFixture{
+		AwebURL:       "https://app.aweb.ai",
+		TeamID:        "backend:demo",
+		Alias:         "alice",
+		WorkspaceID:   "workspace-1",
+		DID:           awid.ComputeDIDKey(priv.Public().(ed25519.PublicKey)),
+		Custody:       awid.CustodySelf,
+		IdentityScope: awid.IdentityModeLocal,
+		SigningKey:    priv,
+		CreatedAt:     "2026-05-26T00:00:00Z",
+	})
+	sockDir, err := os.MkdirTemp("", "aw-agentd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(sockDir) })
+	path := filepath.Join(sockDir, "agentd.sock")
+	t.Setenv(agentdSocketEnv, path)
+	oldTTL := agentdTTL
+	agentdTTL = 100 * time.Millisecond
+	t.Cleanup(func() { agentdTTL = oldTTL })
+
+	if err := os.WriteFile(path, []byte("not a socket"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	var stderr bytes.Buffer
+	cmd.SetErr(&stderr)
+	if err := runAgentd(cmd, []string{tmp}); err == nil || !strings.Contains(err.Error(), "is not a socket") {
+		t.Fatalf("err=%v, want refusal of a non-socket path", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "not a socket" {
+		t.Fatalf("file at socket path changed: %q, %v", data, err)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := runAgentd(cmd, []string{tmp}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stderr.String(), "Agent daemon listening on "+path) {
+		t.Fatalf("stderr=%q", stderr.String())
+	}
+}
+
+func TestAgentdServesCertificatesOfHeldKeysOnly(t *testing.T) {
+	pub, priv, err := awid.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, teamKey, err := awid.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, err := awid.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(agentdSocketEnv, startTestAgentd(t, priv))
+	saveCert := func(dir string, member ed25519.PublicKey) string {
+		cert, err := awid.SignTeamCertificate(teamKey, awid.TeamCertificateFields{
+			Team:          "backend:demo",
+			MemberDIDKey:  awid.ComputeDIDKey(member),
+			Alias:         "alice",
+			IdentityScope: awid.IdentityModeLocal,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := awconfig.SaveTeamCertificateForTeam(dir, "backend:demo", cert); err != nil {
+			t.Fatal(err)
+		}
+		return awconfig.TeamCertificatePath(dir, "backend:demo")
+	}
+
+	cert, signer := agentdCertificate(saveCert(t.TempDir(), pub))
+	if cert == nil || signer == nil {
+		t.Fatal("agentd did not serve the certificate of a held key")
+	}
+	if cert.MemberDIDKey != awid.ComputeDIDKey(pub) || !signer.PublicKey().Equal(pub) {
+		t.Fatalf("cert member=%s signer=%x", cert.MemberDIDKey, signer.PublicKey())
+	}
+	if cert, _ := agentdCertificate(saveCert(t.TempDir(), otherPub)); cert != nil {
+		t.Fatal("agentd served a certificate for a key it does not hold")
+	}
+}
+
+func TestEncryptionKeySetupSignsThroughAgentdWithoutKeyFile(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := awid.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	did := awid.ComputeDIDKey(pub)
+
+	var mu sync.Mutex
+	var gotCert *awid.TeamCertificate
+	var gotAssertion awid.EncryptionKeyAssertion
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/v1/agents/me/encryption-key":
+			mu.Lock()
+			gotCert = requireCertificateAuthForTest(t, r)
+			_ = json.NewDecoder(r.Body).Decode(&gotAssertion)
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "published"})
+		case r.URL.Path == "/v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeSelectionFixtureForTest(t, tmp, testSelectionFixture{
+		AwebURL:       server.URL,
+		TeamID:        "backend:demo",
+		Alias:         "alice",
+		WorkspaceID:   "workspace-1",
+		DID:           did,
+		Custody:       awid.CustodySelf,
+		IdentityScope: awid.IdentityModeLocal,
+		SigningKey:    priv,
+		CreatedAt:     "2026-05-26T00:00:00Z",
+	})
+	if err := os.Remove(awconfig.WorktreeSigningKeyPath(tmp)); err != nil {
+		t.Fatal(err)
+	}
+
+	run := exec.CommandContext(ctx, bin, "id", "encryption-key", "setup", "--json")
+	run.Env = append(testCommandEnv(tmp),
+		agentdSocketEnv+"="+startTestAgentd(t, priv),
+		signerSSHAgentEnv+"=",
+		signerCommandEnv+"=",
+	)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err != nil {
+		t.Fatalf("run failed: %v\n%s", err, string(out))
+	}
+	if _, err := os.Stat(awconfig.WorktreeSigningKeyPath(tmp)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("signing key reappeared: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if gotCert == nil || gotCert.MemberDIDKey != did {
+		t.Fatalf("publish was not certificate-authenticated as %s: %+v", did, gotCert)
+	}
+	if err := awid.VerifyEncryptionKeyAssertion(&gotAssertion, did, "", time.Now().UTC()); err != nil {
+		t.Fatalf("assertion signed through agentd does not verify: %v", err)
+	}
+}
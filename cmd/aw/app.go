@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/appevent"
+	"github.com/awebai/aw/awid"
+	"github.com/spf13/cobra"
+)
+
+var (
+	appEmitAppID       string
+	appEmitKID         string
+	appEmitKeyFile     string
+	appEmitType        string
+	appEmitResourceRef string
+	appEmitIntent      string
+	appEmitPayload     string
+	appEmitEventID     string
+	appEmitURL         string
+	appEmitManifest    string
+	appEmitTimeout     time.Duration
+)
+
+var appCmd = &cobra.Command{
+	Use:   "app",
+	Short: "Act as an app on the aweb server",
+}
+
+var appEmitCmd = &cobra.Command{
+	Use:   "emit",
+	Short: "Sign and post an app event to wake subscribed agents",
+	Long: `Sign an app event with an event emitter key and POST it to
+/v1/events/app. The key must be the one the app manifest publishes under
+--kid; when the manifest is available (--manifest, or the installed plugin
+of the same app id) the key is checked before sending.
+
+Transport errors, 429 and 5xx are retried with the same event_id, so the
+server records the event at most once. Pass --event-id to make reruns of a
+CI step idempotent too.`,
+	Args: cobra.NoArgs,
+	RunE: runAppEmit,
+}
+
+type appEmitOutput struct {
+	EventID  string          `json:"event_id"`
+	AppID    string          `json:"app_id"`
+	TeamID   string          `json:"team_id"`
+	Type     string          `json:"type"`
+	Status   int             `json:"status"`
+	Attempts int             `json:"attempts"`
+	Response json.RawMessage `json:"response,omitempty"`
+}
+
+func init() {
+	appEmitCmd.Flags().StringVar(&appEmitAppID, "app", "", "App id that owns the event type")
+	appEmitCmd.Flags().StringVar(&appEmitKID, "kid", "", "Manifest event emitter key id")
+	appEmitCmd.Flags().StringVar(&appEmitKeyFile, "key-file", "", "PEM Ed25519 emitter signing key")
+	appEmitCmd.Flags().StringVar(&appEmitType, "type", "", "Event type, e.g. ci/build.failed")
+	appEmitCmd.Flags().StringVar(&appEmitResourceRef, "resource-ref", "", "Resource the event is about")
+	appEmitCmd.Flags().StringVar(&appEmitIntent, "intent", "wake", "Delivery intent: wake or ambient")
+	appEmitCmd.Flags().StringVar(&appEmitPayload, "payload", "", "Event payload as a JSON object, or @file")
+	appEmitCmd.Flags().StringVar(&appEmitEventID, "event-id", "", "Idempotency key (default: generated)")
+	appEmitCmd.Flags().StringVar(&appEmitURL, "url", "", "aweb base URL, including any root path (default: this workspace's server)")
+	appEmitCmd.Flags().StringVar(&appEmitManifest, "manifest", "", "App manifest to check the emitter key against")
+	appEmitCmd.Flags().DurationVar(&appEmitTimeout, "timeout", 2*time.Minute, "Give up after this long, including retries")
+	appCmd.AddCommand(appEmitCmd)
+	rootCmd.AddCommand(appCmd)
+}
+
+func runAppEmit(cmd *cobra.Command, args []string) error {
+	appID := strings.TrimSpace(appEmitAppID)
+	kid := strings.TrimSpace(appEmitKID)
+	switch {
+	case appID == "":
+		return usageError("missing required flag: --app")
+	case kid == "":
+		return usageError("missing required flag: --kid")
+	case strings.TrimSpace(appEmitKeyFile) == "":
+		return usageError("missing required flag: --key-file")
+	case strings.TrimSpace(appEmitType) == "":
+		return usageError("missing required flag: --type")
+	}
+	intent := strings.TrimSpace(appEmitIntent)
+	if intent != "wake" && intent != "ambient" {
+		return usageError("--intent must be wake or ambient")
+	}
+	payload, err := parseAppEmitPayload(appEmitPayload)
+	if err != nil {
+		return err
+	}
+	key, err := awid.LoadSigningKey(appEmitKeyFile)
+	if err != nil {
+		return fmt.Errorf("load emitter key: %w", err)
+	}
+	if err := checkAppEmitManifest(appID, kid, key); err != nil {
+		return err
+	}
+
+	baseURL, teamID, err := resolveAppEmitTarget()
+	if err != nil {
+		return err
+	}
+	emitter, err := appevent.NewEmitter(baseURL, teamID, appID, kid, key)
+	if err != nil {
+		return usageError("%v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), appEmitTimeout)
+	defer cancel()
+	result, err := emitter.Emit(ctx, appevent.Event{
+		Type:           strings.TrimSpace(appEmitType),
+		EventID:        strings.TrimSpace(appEmitEventID),
+		ResourceRef:    strings.TrimSpace(appEmitResourceRef),
+		DeliveryIntent: intent,
+		Payload:        payload,
+	})
+	if err != nil {
+		return err
+	}
+	out := appEmitOutput{
+		EventID:  result.EventID,
+		AppID:    appID,
+		TeamID:   teamID,
+		Type:     strings.TrimSpace(appEmitType),
+		Status:   result.Status,
+		Attempts: result.Attempts,
+	}
+	if json.Valid(result.Body) {
+		out.Response = result.Body
+	}
+	printOutput(out, formatAppEmit)
+	return nil
+}
+
+// resolveAppEmitTarget fills --url and --team from the current workspace
+// when either is missing. CI runners usually pass both and have no
+// workspace at all.
+func resolveAppEmitTarget() (string, string, error) {
+	baseURL := strings.TrimSpace(appEmitURL)
+	teamID := strings.TrimSpace(teamFlag)
+	if baseURL != "" && teamID != "" {
+		return baseURL, teamID, nil
+	}
+	workingDir, _ := os.Getwd()
+	sel, err := resolveSelectionForDir(workingDir)
+	if err != nil {
+		return "", "", usageError("pass --url and --team, or run inside an aw workspace: %v", err)
+	}
+	if baseURL == "" {
+		baseURL = strings.TrimSpace(sel.AwebURL)
+	}
+	if teamID == "" {
+		teamID = strings.TrimSpace(sel.TeamID)
+	}
+	if baseURL == "" || teamID == "" {
+		return "", "", usageError("pass --url and --team; the workspace does not name both")
+	}
+	return baseURL, teamID, nil
+}
+
+func checkAppEmitManifest(appID, kid string, key ed25519.PrivateKey) error {
+	path := strings.TrimSpace(appEmitManifest)
+	if path == "" {
+		dir, err := pluginDir()
+		if err != nil {
+			return nil
+		}
+		path = manifestPluginManifestPath(dir, appID)
+		if _, err := os.Stat(path); err != nil {
+			debugLog("app emit: no installed manifest for %s; skipping emitter key check", appID)
+			return nil
+		}
+	}
+	data, err := readFileBounded(path, maxManifestBytes)
+	if err != nil {
+		return fmt.Errorf("read manifest: %w", err)
+	}
+	emitters, err := appevent.ManifestEmitters(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := appevent.CheckEmitterKey(emitters, kid, key); err != nil {
+		return usageError("%s: %v", path, err)
+	}
+	return nil
+}
+
+func parseAppEmitPayload(raw string) (map[string]any, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(raw, "@") {
+		data, err := readFileBounded(strings.TrimPrefix(raw, "@"), maxManifestBytes)
+		if err != nil {
+			return nil, fmt.Errorf("read payload: %w", err)
+		}
+		raw = string(data)
+	}
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+	var payload map[string]any
+	if err := dec.Decode(&payload); err != nil || payload == nil {
+		return nil, usageError("--payload must be a JSON object")
+	}
+	if dec.More() {
+		return nil, usageError("--payload must be a single JSON object")
+	}
+	return payload, nil
+}
+
+func formatAppEmit(v any) string {
+	out := v.(appEmitOutput)
+	attempts := ""
+	if out.Attempts > 1 {
+		attempts = fmt.Sprintf(" after %d attempts", out.Attempts)
+	}
+	return fmt.Sprintf("Emitted %s %s to team %s (HTTP %d%s)\n", out.Type, out.EventID, out.TeamID, out.Status, attempts)
+}
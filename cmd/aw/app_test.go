@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+func TestAwAppEmitSignsAndPostsUnderRootPath(t *testing.T) {
+	t.Parallel()
+
+	var gotPath, gotAuth string
+	var gotPayload map[string]any
+	var gotBody map[string]any
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		signed, _ := base64.RawURLEncoding.DecodeString(r.Header.Get("X-AWEB-Signed-Payload"))
+		_ = json.Unmarshal(signed, &gotPayload)
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &gotBody)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"accepted":true}`))
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	keyPath := filepath.Join(tmp, "emit.key")
+	if err := awid.SaveSigningKey(keyPath, priv); err != nil {
+		t.Fatal(err)
+	}
+	manifestPath := filepath.Join(tmp, "manifest.json")
+	manifest := `{"event_emitters":[{"kid":"emit-1","did_key":"` + awid.ComputeDIDKey(pub) + `"}]}`
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	run := exec.CommandContext(ctx, bin, "app", "emit",
+		"--url", server.URL+"/api",
+		"--team", "default:acme.com",
+		"--app", "ci",
+		"--kid", "emit-1",
+		"--key-file", keyPath,
+		"--manifest", manifestPath,
+		"--type", "ci/build.failed",
+		"--resource-ref", "main",
+		"--payload", `{"run":42}`,
+		"--event-id", "build-42",
+		"--json",
+	)
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err != nil {
+		t.Fatalf("run failed: %v\n%s", err, out)
+	}
+
+	if gotPath != "/api/v1/events/app" || gotPayload["path"] != "/api/v1/events/app" {
+		t.Fatalf("path=%q signed path=%v", gotPath, gotPayload["path"])
+	}
+	if !strings.HasPrefix(gotAuth, "AWEB-App DIDKey "+awid.ComputeDIDKey(pub)+" ") {
+		t.Fatalf("authorization=%q", gotAuth)
+	}
+	if gotPayload["team_id"] != "default:acme.com" || gotPayload["app_id"] != "ci" || gotPayload["kid"] != "emit-1" {
+		t.Fatalf("signed payload=%v", gotPayload)
+	}
+	if gotBody["event_id"] != "build-42" || gotBody["delivery_intent"] != "wake" || gotBody["resource_ref"] != "main" {
+		t.Fatalf("body=%v", gotBody)
+	}
+	if payload, _ := gotBody["payload"].(map[string]any); payload["run"] != float64(42) {
+		t.Fatalf("payload=%v", gotBody["payload"])
+	}
+
+	var resp map[string]any
+	if err := json.Unmarshal(extractJSON(t, out), &resp); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, out)
+	}
+	if resp["event_id"] != "build-42" || resp["status"] != float64(http.StatusAccepted) {
+		t.Fatalf("resp=%v", resp)
+	}
+}
+
+func TestAwAppEmitRefusesKeyNotInManifest(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+
+	_, priv, _ := ed25519.GenerateKey(nil)
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	keyPath := filepath.Join(tmp, "emit.key")
+	if err := awid.SaveSigningKey(keyPath, priv); err != nil {
+		t.Fatal(err)
+	}
+	// An installed plugin manifest is checked without --manifest.
+	pluginManifest := filepath.Join(tmp, "aw-home", "plugins", "ci", "manifest.json")
+	if err := os.MkdirAll(filepath.Dir(pluginManifest), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{"event_emitters":[{"kid":"emit-1","did_key":"` + awid.ComputeDIDKey(otherPub) + `"}]}`
+	if err := os.WriteFile(pluginManifest, []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	run := exec.CommandContext(ctx, bin, "app", "emit",
+		"--url", server.URL,
+		"--team", "default:acme.com",
+		"--app", "ci",
+		"--kid", "emit-1",
+		"--key-file", keyPath,
+		"--type", "ci/build.failed",
+	)
+	run.Env = append(testCommandEnv(tmp), "AW_HOME="+filepath.Join(tmp, "aw-home"))
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected failure:\n%s", out)
+	}
+	if !strings.Contains(string(out), "signing key is") {
+		t.Fatalf("output=%s", out)
+	}
+	if calls != 0 {
+		t.Fatalf("server called %d times", calls)
+	}
+}
@@ -0,0 +1,406 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awid"
+	"github.com/awebai/aw/chat"
+	"github.com/awebai/aw/internal/blueprint"
+	awrun "github.com/awebai/aw/run"
+	"github.com/spf13/cobra"
+)
+
+const (
+	approvalRouteRun      = "aw-run"
+	approvalRouteTerminal = "terminal"
+	approvalRouteChat     = "chat"
+	approvalRouteNone     = "none"
+
+	maxApprovalDetailRunes = 400
+
+	// ownerApprovalOperation tags the payload the team owner signs, so an
+	// approval signature cannot stand in for any other team-key signature.
+	ownerApprovalOperation = "aw_approval"
+)
+
+// Test hooks.
+var (
+	approvalStdinIsTTY = isTTY
+	approvalAskChat    = askApprovalOverChat
+	approvalNow        = time.Now
+	approvalTeam       = resolveApprovalTeam
+)
+
+type approvalRecord struct {
+	Time     string `json:"time"`
+	Action   string `json:"action"`
+	Rule     string `json:"rule"`
+	Detail   string `json:"detail,omitempty"`
+	Route    string `json:"route"`
+	Approved bool   `json:"approved"`
+	Approver string `json:"approver,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// ownerApprovalRequest is what the team owner signs to allow one gated
+// action. The nonce makes each signature good for exactly one request.
+type ownerApprovalRequest struct {
+	Operation string `json:"operation"`
+	TeamID    string `json:"team_id"`
+	Action    string `json:"action"`
+	Detail    string `json:"detail"`
+	Nonce     string `json:"nonce"`
+}
+
+func (r ownerApprovalRequest) canonical() (string, error) {
+	return awid.CanonicalJSONValue(map[string]any{
+		"operation": r.Operation,
+		"team_id":   r.TeamID,
+		"action":    r.Action,
+		"detail":    r.Detail,
+		"nonce":     r.Nonce,
+	})
+}
+
+// code is the request as the owner passes it to `aw id team approve`.
+func (r ownerApprovalRequest) code() (string, error) {
+	canonical, err := r.canonical()
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(canonical)), nil
+}
+
+func parseOwnerApprovalCode(code string) (ownerApprovalRequest, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(code))
+	if err != nil {
+		return ownerApprovalRequest{}, fmt.Errorf("invalid approval code: %w", err)
+	}
+	var req ownerApprovalRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return ownerApprovalRequest{}, fmt.Errorf("invalid approval code: %w", err)
+	}
+	if req.Operation != ownerApprovalOperation || strings.TrimSpace(req.TeamID) == "" || strings.TrimSpace(req.Action) == "" || strings.TrimSpace(req.Nonce) == "" {
+		return ownerApprovalRequest{}, fmt.Errorf("invalid approval code: not an aw approval request")
+	}
+	return req, nil
+}
+
+func signOwnerApproval(teamKey crypto.Signer, req ownerApprovalRequest) (string, error) {
+	canonical, err := req.canonical()
+	if err != nil {
+		return "", err
+	}
+	sig, err := signWithSigner(teamKey, []byte(canonical))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawStdEncoding.EncodeToString(sig), nil
+}
+
+// verifyOwnerApproval checks signature against the team controller key.
+func verifyOwnerApproval(teamDIDKey string, req ownerApprovalRequest, signature string) error {
+	signature = strings.TrimSpace(signature)
+	if signature == "" {
+		return errors.New("no team owner signature")
+	}
+	pub, err := awid.ExtractPublicKey(strings.TrimSpace(teamDIDKey))
+	if err != nil {
+		return fmt.Errorf("team key: %w", err)
+	}
+	sig, err := base64.RawStdEncoding.DecodeString(signature)
+	if err != nil {
+		return errors.New("malformed team owner signature")
+	}
+	canonical, err := req.canonical()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, []byte(canonical), sig) {
+		return fmt.Errorf("signature is not from the %s team key", req.TeamID)
+	}
+	return nil
+}
+
+// requireCommandApproval gates a CLI command listed in the profile's
+// approval_required.
+func requireCommandApproval(cmd *cobra.Command, args []string) error {
+	return requireApproval(cmd.CommandPath(), strings.Join(args, " "), false)
+}
+
+// requireToolApproval gates a manifest plugin tool call.
+func requireToolApproval(plugin, verb string, args map[string]any, mutation bool) error {
+	detail := ""
+	if len(args) > 0 {
+		if data, err := json.Marshal(args); err == nil {
+			detail = string(data)
+		}
+	}
+	return requireApproval(plugin+" "+verb, detail, mutation)
+}
+
+// requireApproval returns nil when action is not listed in the materialized
+// profile's approval_required, or the team owner allowed it. Only a
+// signature by the team controller key over this request approves; the
+// surrounding aw run, the terminal and the configured approver over chat
+// merely carry the request and the signature, so an agent cannot approve its
+// own action through any of them. The decision is appended to
+// .aw/approvals.jsonl either way.
+func requireApproval(action, detail string, mutatingTool bool) error {
+	workingDir, _ := os.Getwd()
+	root := interactionLogRoot(workingDir)
+	required, err := blueprint.LoadMaterializedApprovalRequired(root)
+	if err != nil {
+		return fmt.Errorf("load approval_required: %w", err)
+	}
+	rule, gated := blueprint.ApprovalRequiredFor(required, action, mutatingTool)
+	if !gated {
+		return nil
+	}
+	action = blueprint.NormalizeApprovalAction(action)
+	detail = truncateApprovalDetail(detail)
+
+	config := awrun.ApprovalConfig{}
+	if cfg, err := awrun.LoadUserConfig(workingDir); err != nil {
+		debugLog("approval: load run config: %v", err)
+	} else if cfg.Approval != nil {
+		config = *cfg.Approval
+	}
+
+	record := approvalRecord{Action: action, Rule: rule, Detail: detail}
+	ctx, cancel := context.WithTimeout(context.Background(), awid.APITimeout())
+	teamID, teamDIDKey, err := approvalTeam(ctx, workingDir)
+	cancel()
+	if err != nil {
+		record.Route = approvalRouteNone
+		record.Reason = fmt.Sprintf("cannot resolve the team key: %v", err)
+	} else {
+		nonce := make([]byte, 16)
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+		request := ownerApprovalRequest{
+			Operation: ownerApprovalOperation,
+			TeamID:    teamID,
+			Action:    action,
+			Detail:    detail,
+			Nonce:     hex.EncodeToString(nonce),
+		}
+		code, err := request.code()
+		if err != nil {
+			return err
+		}
+		decision, route, err := askApproval(root, request, code, config)
+		record.Route = route
+		switch {
+		case err != nil:
+			record.Reason = err.Error()
+		case decision.Approved:
+			record.Approver = decision.Approver
+			if verifyErr := verifyOwnerApproval(teamDIDKey, request, decision.Signature); verifyErr != nil {
+				record.Reason = "approval rejected: " + verifyErr.Error()
+			} else {
+				record.Approved = true
+			}
+		default:
+			record.Approver = decision.Approver
+			record.Reason = decision.Reason
+		}
+	}
+	record.Time = approvalNow().UTC().Format(time.RFC3339)
+	if logErr := appendApprovalRecord(root, record); logErr != nil {
+		debugLog("approval: record decision: %v", logErr)
+		if record.Approved {
+			return fmt.Errorf("%s requires approval but the decision could not be recorded: %w", action, logErr)
+		}
+	}
+	if record.Approved {
+		return nil
+	}
+	reason := record.Reason
+	if reason == "" {
+		reason = "denied"
+		if record.Approver != "" {
+			reason += " by " + record.Approver
+		}
+	}
+	return fmt.Errorf("%s requires human approval (approval_required: %s): %s", action, rule, reason)
+}
+
+// resolveApprovalTeam returns the selected team and its controller did:key
+// as the registry publishes it. The local team certificate is not trusted
+// for this, since the agent can rewrite it.
+func resolveApprovalTeam(ctx context.Context, workingDir string) (string, string, error) {
+	sel, err := resolveSelectionForDir(workingDir)
+	if err != nil {
+		return "", "", err
+	}
+	teamID := strings.TrimSpace(sel.TeamID)
+	domain, name, err := awid.ParseTeamID(teamID)
+	if err != nil {
+		return "", "", err
+	}
+	registry, err := newConfiguredRegistryClient(nil, "")
+	if err != nil {
+		return "", "", err
+	}
+	if registryURL := strings.TrimSpace(sel.RegistryURL); registryURL != "" {
+		if err := registry.SetFallbackRegistryURL(registryURL); err != nil {
+			return "", "", err
+		}
+	}
+	signers := teamReadSignersForKeyPath(sel.SigningKey, domain, name)
+	var team *awid.RegistryTeam
+	if _, err := readSignedTeamState(signers, func(key ed25519.PrivateKey) error {
+		var readErr error
+		team, readErr = registry.GetTeam(ctx, strings.TrimSpace(registry.DefaultRegistryURL), domain, name, key)
+		return readErr
+	}); err != nil {
+		return "", "", friendlyTeamReadError(err, teamID, len(signers) > 0)
+	}
+	if strings.TrimSpace(team.TeamDIDKey) == "" {
+		return "", "", fmt.Errorf("AWID team %s is missing team_did_key", teamID)
+	}
+	return teamID, strings.TrimSpace(team.TeamDIDKey), nil
+}
+
+// askApproval carries the request to whoever can reach the team owner: the
+// aw run of this worktree, the terminal, then the configured approver over
+// chat. None of them can approve without the owner's signature.
+func askApproval(root string, request ownerApprovalRequest, code string, config awrun.ApprovalConfig) (awrun.ApprovalDecision, string, error) {
+	timeout := config.Timeout()
+	socket := awrun.ControlSocketPath(root)
+	if _, err := os.Stat(socket); err == nil {
+		resp, err := awrun.SendControlSocket(socket, awrun.HeadlessCommand{
+			Type: "approval_request",
+			Approval: &awrun.ApprovalRequest{
+				Action:         request.Action,
+				Detail:         request.Detail,
+				Code:           code,
+				TimeoutSeconds: int(timeout / time.Second),
+			},
+		}, timeout+10*time.Second)
+		switch {
+		case err == nil && resp.Approval != nil:
+			return *resp.Approval, approvalRouteRun, nil
+		case err == nil:
+			debugLog("approval: aw run declined the request: %s", resp.Error)
+		default:
+			debugLog("approval: %v", err)
+		}
+	}
+	if approvalStdinIsTTY() {
+		decision, err := promptApproval(request.Action, request.Detail, code)
+		return decision, approvalRouteTerminal, err
+	}
+	if approver := strings.TrimSpace(config.Approver); approver != "" {
+		decision, err := approvalAskChat(approver, request.Action, request.Detail, code, timeout)
+		return decision, approvalRouteChat, err
+	}
+	return awrun.ApprovalDecision{}, approvalRouteNone, errors.New("no approver is reachable; run it from a terminal, under aw run, or set approval.approver in run.json")
+}
+
+func promptApproval(action, detail, code string) (awrun.ApprovalDecision, error) {
+	fmt.Fprintf(os.Stderr, "Approval required: %s", action)
+	if detail != "" {
+		fmt.Fprintf(os.Stderr, " %s", detail)
+	}
+	fmt.Fprintf(os.Stderr, "\nThe team owner signs it with: aw id team approve %s\n", code)
+	fmt.Fprint(os.Stderr, "Enter \"approve <signature>\" or \"deny\": ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && strings.TrimSpace(line) == "" {
+		return awrun.ApprovalDecision{}, fmt.Errorf("read approval: %w", err)
+	}
+	approved, signature, _ := parseApprovalReply(line)
+	return awrun.ApprovalDecision{Approved: approved, Approver: "terminal", Signature: signature}, nil
+}
+
+func askApprovalOverChat(approver, action, detail, code string, timeout time.Duration) (awrun.ApprovalDecision, error) {
+	if timeout > chat.MaxSendTimeout {
+		timeout = chat.MaxSendTimeout
+	}
+	message := "Approval requested: " + action
+	if detail != "" {
+		message += " " + detail
+	}
+	message += "\nThe team owner signs it with: aw id team approve " + code
+	message += "\nReply \"approve <signature>\" to allow it or \"deny\" to refuse."
+	ctx, cancel := context.WithTimeout(context.Background(), timeout+30*time.Second)
+	defer cancel()
+	result, _, err := chatSend(ctx, approver, message, chat.SendOptions{
+		Wait:              int(timeout / time.Second),
+		WaitExplicit:      true,
+		StartConversation: true,
+	})
+	if err != nil {
+		return awrun.ApprovalDecision{}, fmt.Errorf("ask %s: %w", approver, err)
+	}
+	if result.Status != "replied" {
+		return awrun.ApprovalDecision{Approver: approver, Reason: fmt.Sprintf("no reply from %s (%s)", approver, result.Status)}, nil
+	}
+	approved, signature, ok := parseApprovalReply(result.Reply)
+	if !ok {
+		return awrun.ApprovalDecision{Approver: approver, Reason: fmt.Sprintf("unrecognized reply from %s", approver)}, nil
+	}
+	return awrun.ApprovalDecision{Approved: approved, Approver: approver, Signature: signature}, nil
+}
+
+// parseApprovalReply reads the first word of a reply, and for an approval
+// the owner signature that follows it.
+func parseApprovalReply(reply string) (approved bool, signature string, ok bool) {
+	fields := strings.Fields(reply)
+	if len(fields) == 0 {
+		return false, "", false
+	}
+	switch strings.Trim(strings.ToLower(fields[0]), ".,!:;\"'") {
+	case "approve", "approved", "allow", "yes", "y":
+		if len(fields) > 1 {
+			signature = strings.Trim(fields[1], "\"'`")
+		}
+		return true, signature, true
+	case "deny", "denied", "refuse", "reject", "no", "n":
+		return false, "", true
+	}
+	return false, "", false
+}
+
+func appendApprovalRecord(root string, record approvalRecord) error {
+	path := filepath.Join(root, ".aw", "approvals.jsonl")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func truncateApprovalDetail(detail string) string {
+	detail = strings.Join(strings.Fields(detail), " ")
+	if runes := []rune(detail); len(runes) > maxApprovalDetailRunes {
+		detail = string(runes[:maxApprovalDetailRunes-1]) + "…"
+	}
+	return detail
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/awebai/aw/awid"
+	awrun "github.com/awebai/aw/run"
+)
+
+func writeApprovalWorkspaceForTest(t *testing.T, dir, runJSON string) {
+	t.Helper()
+	t.Setenv("HOME", dir)
+	t.Setenv("AW_HOME", filepath.Join(dir, "aw-home"))
+	for path, content := range map[string]string{
+		filepath.Join(dir, ".aw", "context"):                 "",
+		filepath.Join(dir, ".aw", "run.json"):                runJSON,
+		filepath.Join(dir, ".aw", "profile", "profile.yaml"): "approval_required: [task.close, app.mutating]\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+}
+
+// useApprovalTeamKeyForTest makes key the controller key of the selected
+// team and returns a signer for approval codes.
+func useApprovalTeamKeyForTest(t *testing.T, key ed25519.PrivateKey) func(code string) string {
+	t.Helper()
+	orig := approvalTeam
+	t.Cleanup(func() { approvalTeam = orig })
+	approvalTeam = func(context.Context, string) (string, string, error) {
+		return "backend:acme.com", awid.ComputeDIDKey(key.Public().(ed25519.PublicKey)), nil
+	}
+	return func(code string) string {
+		req, err := parseOwnerApprovalCode(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature, err := signOwnerApproval(key, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signature
+	}
+}
+
+func TestRequireApprovalAsksConfiguredApproverAndRecordsDecision(t *testing.T) {
+	tmp := t.TempDir()
+	writeApprovalWorkspaceForTest(t, tmp, `{"approval":{"approver":"owner","timeout_seconds":30}}`)
+	_, teamKey, _ := ed25519.GenerateKey(nil)
+	sign := useApprovalTeamKeyForTest(t, teamKey)
+
+	origTTY, origChat := approvalStdinIsTTY, approvalAskChat
+	t.Cleanup(func() { approvalStdinIsTTY, approvalAskChat = origTTY, origChat })
+	approvalStdinIsTTY = func() bool { return false }
+	var asked []string
+	reply := func(code string) string { return "approve " + sign(code) }
+	approvalAskChat = func(approver, action, detail, code string, timeout time.Duration) (awrun.ApprovalDecision, error) {
+		asked = append(asked, approver+" "+action+" "+detail+" "+timeout.String())
+		approved, signature, _ := parseApprovalReply(reply(code))
+		return awrun.ApprovalDecision{Approved: approved, Approver: approver, Signature: signature}, nil
+	}
+
+	if err := requireApproval("aw task list", "", false); err != nil || len(asked) != 0 {
+		t.Fatalf("ungated command: err=%v asked=%v", err, asked)
+	}
+	if err := requireApproval("aw task close", "t-1", false); err != nil {
+		t.Fatal(err)
+	}
+	reply = func(string) string { return "deny, not yet" }
+	err := requireToolApproval("ci", "deploy", map[string]any{"env": "prod"}, true)
+	if err == nil || !strings.Contains(err.Error(), "ci.deploy requires human approval (approval_required: app.mutating): denied by owner") {
+		t.Fatalf("err=%v", err)
+	}
+	if len(asked) != 2 || asked[0] != "owner task.close t-1 30s" || asked[1] != `owner ci.deploy {"env":"prod"} 30s` {
+		t.Fatalf("asked=%v", asked)
+	}
+
+	// An approval signed by any key but the team's, such as the agent's own,
+	// or carrying no signature at all, is refused.
+	_, agentKey, _ := ed25519.GenerateKey(nil)
+	for _, forged := range []func(string) string{
+		func(code string) string {
+			req, _ := parseOwnerApprovalCode(code)
+			signature, _ := signOwnerApproval(agentKey, req)
+			return "approve " + signature
+		},
+		func(string) string { return "approve" },
+	} {
+		reply = forged
+		err = requireApproval("aw task close", "t-2", false)
+		if err == nil || !strings.Contains(err.Error(), "approval rejected") {
+			t.Fatalf("forged approval err=%v", err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmp, ".aw", "approvals.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("records=%q", data)
+	}
+	var first, second, third approvalRecord
+	_ = json.Unmarshal([]byte(lines[0]), &first)
+	_ = json.Unmarshal([]byte(lines[1]), &second)
+	_ = json.Unmarshal([]byte(lines[2]), &third)
+	if !first.Approved || first.Action != "task.close" || first.Route != approvalRouteChat || first.Approver != "owner" {
+		t.Fatalf("first=%+v", first)
+	}
+	if second.Approved || second.Rule != "app.mutating" {
+		t.Fatalf("second=%+v", second)
+	}
+	if third.Approved || !strings.Contains(third.Reason, "not from the backend:acme.com team key") {
+		t.Fatalf("third=%+v", third)
+	}
+}
+
+func TestRequireApprovalProviderCannotSelfApproveThroughRunSocket(t *testing.T) {
+	// Unix socket paths are short; keep the worktree near the root.
+	tmp, err := os.MkdirTemp("", "aw-approve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(tmp) })
+	writeApprovalWorkspaceForTest(t, tmp, `{"approval":{"timeout_seconds":5}}`)
+	_, teamKey, _ := ed25519.GenerateKey(nil)
+	sign := useApprovalTeamKeyForTest(t, teamKey)
+	origTTY := approvalStdinIsTTY
+	t.Cleanup(func() { approvalStdinIsTTY = origTTY })
+	approvalStdinIsTTY = func() bool { return false }
+
+	socketPath := awrun.ControlSocketPath(tmp)
+	socket := awrun.NewControlSocket(socketPath)
+	if err := socket.Start(func() awrun.LoopSnapshot { return awrun.LoopSnapshot{} }); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = socket.Stop() })
+
+	// The provider sees the request over the socket it can reach and tries
+	// to approve it itself: bare, then with a signature of its own.
+	_, agentKey, _ := ed25519.GenerateKey(nil)
+	go func() {
+		event := <-socket.Events()
+		code := event.Text[strings.Index(event.Text, "approve ")+len("approve ") : strings.Index(event.Text, "`;")]
+		if resp, err := awrun.SendControlSocket(socketPath, awrun.HeadlessCommand{Type: "approve"}, time.Second); err != nil || resp.OK {
+			t.Errorf("unsigned approve=%#v err=%v", resp, err)
+		}
+		req, _ := parseOwnerApprovalCode(code)
+		forged, _ := signOwnerApproval(agentKey, req)
+		_, _ = awrun.SendControlSocket(socketPath, awrun.HeadlessCommand{Type: "approve", Text: "1 " + forged}, time.Second)
+	}()
+	err = requireApproval("aw task close", "t-1", false)
+	if err == nil || !strings.Contains(err.Error(), "approval rejected") {
+		t.Fatalf("self-approval err=%v", err)
+	}
+
+	// The team owner's signature, relayed the same way, approves.
+	go func() {
+		event := <-socket.Events()
+		code := event.Text[strings.Index(event.Text, "approve ")+len("approve ") : strings.Index(event.Text, "`;")]
+		_, _ = awrun.SendControlSocket(socketPath, awrun.HeadlessCommand{Type: "approve", Text: "2 " + sign(code)}, time.Second)
+	}()
+	if err := requireApproval("aw task close", "t-1", false); err != nil {
+		t.Fatalf("owner approval err=%v", err)
+	}
+}
+
+func TestRequireApprovalDeniesWithoutReachableApprover(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv("AW_HOME", filepath.Join(tmp, "aw-home"))
+	profile := filepath.Join(tmp, ".aw", "profile", "profile.yaml")
+	if err := os.MkdirAll(filepath.Dir(profile), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, ".aw", "context"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(profile, []byte("approval_required: [id.team.remove-member]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(tmp)
+	origTTY := approvalStdinIsTTY
+	t.Cleanup(func() { approvalStdinIsTTY = origTTY })
+	approvalStdinIsTTY = func() bool { return false }
+	_, teamKey, _ := ed25519.GenerateKey(nil)
+	useApprovalTeamKeyForTest(t, teamKey)
+
+	err := requireApproval("aw id team remove-member", "bob", false)
+	if err == nil || !strings.Contains(err.Error(), "no approver is reachable") {
+		t.Fatalf("err=%v", err)
+	}
+}
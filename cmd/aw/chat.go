@@ -48,6 +48,10 @@ func chatSend(ctx context.Context, toAlias, message string, opts chat.SendOption
 			}
 		}
 	}
+	if opts.Leaving {
+		wd, _ := os.Getwd()
+		attachOutbox(c.Client, wd)
+	}
 	r, err := chat.Send(ctx, c.Client, sel.Alias, []string{target}, message, opts, chatStderrCallback)
 	return r, sel, err
 }
@@ -182,6 +186,7 @@ var chatSendAndWaitCmd = &cobra.Command{
 			Timestamp: time.Now().UTC().Format(time.RFC3339),
 			Dir:       "send",
 			Channel:   "chat",
+			MessageID: result.MessageID,
 			SessionID: result.SessionID,
 			From:      myAddr,
 			To:        args[0],
@@ -233,6 +238,7 @@ var chatSendAndLeaveCmd = &cobra.Command{
 			Timestamp: time.Now().UTC().Format(time.RFC3339),
 			Dir:       "send",
 			Channel:   "chat",
+			MessageID: result.MessageID,
 			SessionID: result.SessionID,
 			From:      selectionAddress(sel),
 			To:        args[0],
@@ -350,6 +356,9 @@ func formatChatSendMessage(v any) string {
 	if !ok || resp == nil {
 		return ""
 	}
+	if resp.Queued {
+		return fmt.Sprintf("Server unreachable; queued chat message %s in the outbox\n", resp.MessageID)
+	}
 	if resp.MessageID != "" {
 		return fmt.Sprintf("Sent chat message %s\n", resp.MessageID)
 	}
@@ -385,6 +394,10 @@ var chatSendCmd = &cobra.Command{
 				return err
 			}
 		}
+		if chatSendLeave {
+			wd, _ := os.Getwd()
+			attachOutbox(c.Client, wd)
+		}
 		resp, err := c.Client.ChatSendMessage(ctx, sessionID, &awid.ChatSendMessageRequest{
 			Body:        body,
 			Leaving:     chatSendLeave,
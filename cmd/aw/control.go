@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/awebai/aw/awid"
+	awrun "github.com/awebai/aw/run"
 	"github.com/spf13/cobra"
 )
 
@@ -50,11 +55,120 @@ func controlSignalCmd(signal awid.ControlSignal) *cobra.Command {
 	return cmd
 }
 
+var controlLocalDir string
+
+var controlLocalCmd = &cobra.Command{
+	Use:   "local <pause|resume|stop|quit|prompt|inject|approve|deny|status|state> [text]",
+	Short: "Drive the aw run in this workspace over its local control socket",
+	Long: `Send one request to the control socket (.aw/run.sock) of the aw run
+in this workspace. No server connection is needed.
+
+prompt queues text as a typed prompt and turns autofeed off; inject queues
+it without touching autofeed. approve [id] <signature> relays the team
+owner's signature from ` + "`aw id team approve`" + ` to a pending approval request,
+and deny [id] refuses one (default the oldest); a bare approve is refused.
+status and state report the loop.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runControlLocal,
+}
+
+func runControlLocal(cmd *cobra.Command, args []string) error {
+	request := awrun.HeadlessCommand{Type: strings.ToLower(strings.TrimSpace(args[0]))}
+	text := strings.TrimSpace(strings.Join(args[1:], " "))
+	switch request.Type {
+	case "prompt", "inject":
+		if text == "" {
+			return usageError("%s needs text", request.Type)
+		}
+		request.Text = text
+	case "approve", "deny":
+		request.Text = text
+	case "pause", "resume", "stop", "quit", "status", "state":
+		if text != "" {
+			return usageError("%s takes no text", request.Type)
+		}
+	default:
+		return usageError("unknown local control %q", args[0])
+	}
+
+	dir := strings.TrimSpace(controlLocalDir)
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		dir = wd
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	resp, err := awrun.SendControlSocket(awrun.ControlSocketPath(interactionLogRoot(dir)), request, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	if !resp.OK {
+		return errors.New(resp.Error)
+	}
+	printOutput(resp, func(v any) string {
+		return formatControlLocalResponse(request.Type, v.(awrun.ControlSocketResponse))
+	})
+	return nil
+}
+
+func formatControlLocalResponse(kind string, resp awrun.ControlSocketResponse) string {
+	switch {
+	case resp.Status != nil:
+		status := resp.Status
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "phase: %s\n", status.Phase)
+		fmt.Fprintf(&sb, "paused: %t\n", status.Paused)
+		fmt.Fprintf(&sb, "pending events: %d\n", status.PendingEvents)
+		if status.QueuedPrompt != "" {
+			fmt.Fprintf(&sb, "queued prompt: %s\n", status.QueuedPrompt)
+		}
+		return sb.String()
+	case resp.State != nil:
+		state := resp.State
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "run: %d (%s)\n", state.Run, state.Phase)
+		if state.SessionID != "" {
+			fmt.Fprintf(&sb, "session: %s\n", state.SessionID)
+		}
+		fmt.Fprintf(&sb, "paused: %t\n", state.Paused)
+		if state.LeaseLost {
+			sb.WriteString("session lease: lost (read-only)\n")
+		}
+		fmt.Fprintf(&sb, "autofeed: %t\n", state.Autofeed)
+		fmt.Fprintf(&sb, "connection: %s\n", state.Connection)
+		fmt.Fprintf(&sb, "pending events: %d\n", state.PendingEvents)
+		if state.ClaimedTask != "" {
+			fmt.Fprintf(&sb, "task: %s\n", state.ClaimedTask)
+		}
+		if state.QueuedPrompt != "" {
+			fmt.Fprintf(&sb, "queued prompt: %s\n", state.QueuedPrompt)
+		}
+		fmt.Fprintf(&sb, "cost: $%.2f\n", state.CostUSD)
+		if state.LastRunError != "" {
+			fmt.Fprintf(&sb, "last error: %s\n", state.LastRunError)
+		}
+		for _, approval := range state.PendingApprovals {
+			fmt.Fprintf(&sb, "approval pending: %s\n", approval)
+		}
+		return sb.String()
+	default:
+		return fmt.Sprintf("Sent %s\n", kind)
+	}
+}
+
 func init() {
+	controlLocalCmd.Flags().StringVar(&controlLocalDir, "dir", "", "Workspace directory of the aw run (default: current directory)")
 	controlCmd.AddCommand(
 		controlSignalCmd(awid.ControlSignalPause),
 		controlSignalCmd(awid.ControlSignalResume),
 		controlSignalCmd(awid.ControlSignalInterrupt),
+		controlLocalCmd,
 	)
 	rootCmd.AddCommand(controlCmd)
 }
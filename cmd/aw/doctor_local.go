@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/ed25519"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -42,6 +43,8 @@ const (
 	doctorCheckIdentityCustody          = "local.identity_yaml.custody_present"
 	doctorCheckIdentityRegistryURL      = "local.identity_yaml.registry_url_syntax"
 	doctorCheckRegistryCoherence        = "local.registry_url.local_coherence"
+	doctorCheckAuthorityKeysReadable    = "local.authority_keys.readable"
+	doctorCheckAuthorityKeysEncrypted   = "local.authority_keys.encrypted"
 )
 
 type doctorLocalState struct {
@@ -104,6 +107,7 @@ type doctorTeamMembershipYAML struct {
 }
 
 func (r *doctorRunner) runLocalChecks() {
+	r.runAuthorityKeyEncryptionCheck()
 	state := doctorLocalState{
 		workingDir:     r.workingDir,
 		identityHome:   r.identityHome,
@@ -435,6 +439,17 @@ func (r *doctorRunner) runSigningKeyFileChecks(state *doctorLocalState) {
 	r.add(localPathCheck(doctorCheckSigningKeyExists, doctorStatusOK, state.signingKeyPath, "Signing key file is present.", "", map[string]any{"state": "present"}))
 
 	signingKey, err := awid.LoadSigningKey(state.signingKeyPath)
+	if errors.Is(err, awid.ErrSigningKeyLocked) {
+		r.add(localPathCheck(
+			doctorCheckSigningKeyParse,
+			doctorStatusUnknown,
+			state.signingKeyPath,
+			"Signing key is encrypted and could not be unlocked.",
+			"Set AW_KEY_PASSPHRASE_FILE or run doctor from a terminal.",
+			map[string]any{"encrypted": true},
+		))
+		return
+	}
 	if err != nil {
 		check := localPathCheck(
 			doctorCheckSigningKeyParse,
@@ -455,6 +470,56 @@ func (r *doctorRunner) runSigningKeyFileChecks(state *doctorLocalState) {
 	r.add(localPathCheck(doctorCheckSigningKeyParse, doctorStatusOK, state.signingKeyPath, "Signing key parsed successfully.", "", map[string]any{"did_key": state.signingKeyDID}))
 }
 
+// runAuthorityKeyEncryptionCheck warns about namespace controller and team
+// keys stored as plaintext. Those keys cannot be reissued by a server, so
+// they are the ones worth a passphrase. Keys that cannot be read are
+// reported on their own rather than counted as plaintext.
+func (r *doctorRunner) runAuthorityKeyEncryptionCheck() {
+	paths, err := authorityKeyFiles()
+	if err != nil || len(paths) == 0 {
+		return
+	}
+	var plaintext, unreadable []string
+	unreadableErrors := map[string]any{}
+	for _, path := range paths {
+		encrypted, err := awid.IsEncryptedPrivateKeyFile(path)
+		if err != nil {
+			unreadable = append(unreadable, abbreviateUserHome(path))
+			unreadableErrors[abbreviateUserHome(path)] = err.Error()
+			continue
+		}
+		if !encrypted {
+			plaintext = append(plaintext, abbreviateUserHome(path))
+		}
+	}
+	if len(unreadable) > 0 {
+		r.add(localCheck(
+			doctorCheckAuthorityKeysReadable,
+			doctorStatusFail,
+			nil,
+			fmt.Sprintf("%d of %d controller and team keys could not be read.", len(unreadable), len(paths)),
+			"Check the permissions and contents of the listed key files.",
+			map[string]any{"unreadable": unreadable, "errors": unreadableErrors},
+		))
+	}
+	readable := len(paths) - len(unreadable)
+	if readable == 0 {
+		return
+	}
+	if len(plaintext) == 0 {
+		r.add(localCheck(doctorCheckAuthorityKeysEncrypted, doctorStatusOK, nil, "Controller and team keys are encrypted at rest.", "", map[string]any{"keys": readable}))
+		return
+	}
+	r.add(localCheck(
+		doctorCheckAuthorityKeysEncrypted,
+		doctorStatusWarn,
+		nil,
+		fmt.Sprintf("%d of %d controller and team keys are stored unencrypted.", len(plaintext), readable),
+		"Run `aw id keys encrypt` to protect them with a passphrase.",
+		map[string]any{"unencrypted": plaintext},
+	))
+}
+
 func (r *doctorRunner) runSigningKeyChecks(state *doctorLocalState) {
 	r.runSigningKeyFileChecks(state)
 	if state.cert == nil {
@@ -121,7 +121,14 @@ func formatIntrospect(v any) string {
 		sb.WriteString(fmt.Sprintf("Scope:     %s\n", awid.DescribeIdentityScope(out.IdentityScope)))
 	}
 	if out.GrantID != "" {
-		sb.WriteString(fmt.Sprintf("Grant:     %s (%s, expires %s)\n", out.GrantID, out.GrantStatus, out.GrantExpiresAt))
+		expiry := "expires " + out.GrantExpiresAt
+		if out.GrantRemainingSeconds > 0 {
+			expiry += ", " + formatDuration(out.GrantRemainingSeconds) + " left"
+		}
+		sb.WriteString(fmt.Sprintf("Grant:     %s (%s, %s)\n", out.GrantID, out.GrantStatus, expiry))
+		if out.GrantRenewsFrom != "" {
+			sb.WriteString(fmt.Sprintf("Renews:    from %s\n", out.GrantRenewsFrom))
+		}
 		if len(out.GrantScopes) > 0 {
 			sb.WriteString(fmt.Sprintf("Scopes:    %s\n", strings.Join(out.GrantScopes, ", ")))
 		}
@@ -374,6 +381,10 @@ func formatChatSend(v any) string {
 		}
 		return sb.String()
 
+	case awid.MessageStatusQueued:
+		sb.WriteString(fmt.Sprintf("Message to %s queued in the outbox; the server is unreachable\n", result.TargetAgent))
+		return sb.String()
+
 	case "timeout":
 		sb.WriteString(fmt.Sprintf("Message sent to %s\n", result.TargetAgent))
 		if result.TargetNotConnected {
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/awebai/aw/awid"
+	"github.com/spf13/cobra"
+)
+
+// grantCommandScopes lists the grant scopes each mail and chat command
+// needs, following awid.RequiredGrantScope. From a grant home these commands
+// stop before any request when the grant lacks one. Task, lock, a2a and id
+// commands are deliberately not listed: no client-side scope covers them,
+// so they run and the server decides whether the grant allows them.
+var grantCommandScopes = map[string][]string{
+	"aw chat extend-wait":    {awid.GrantScopeChatSend},
+	"aw chat history":        {awid.GrantScopeChatRead},
+	"aw chat listen":         {awid.GrantScopeChatRead},
+	"aw chat open":           {awid.GrantScopeChatRead},
+	"aw chat pending":        {awid.GrantScopeChatRead},
+	"aw chat read":           {awid.GrantScopeChatRead},
+	"aw chat send":           {awid.GrantScopeChatSend},
+	"aw chat send-and-leave": {awid.GrantScopeChatSend},
+	"aw chat send-and-wait":  {awid.GrantScopeChatSend, awid.GrantScopeChatRead},
+	"aw chat show-pending":   {awid.GrantScopeChatRead},
+	"aw mail ack":            {awid.GrantScopeMailRead},
+	"aw mail inbox":          {awid.GrantScopeMailRead},
+	"aw mail reply":          {awid.GrantScopeMailRead, awid.GrantScopeMailSend},
+	"aw mail send":           {awid.GrantScopeMailSend},
+	"aw mail show":           {awid.GrantScopeMailRead},
+	"aw notify":              {awid.GrantScopeChatRead},
+}
+
+// requireGrantCommandScope refuses a command the active grant home's scopes
+// do not cover. Grants minted without recorded scopes are not checked.
+func requireGrantCommandScope(cmd *cobra.Command) error {
+	if cmd == nil {
+		return nil
+	}
+	required, ok := grantCommandScopes[strings.TrimSpace(cmd.CommandPath())]
+	if !ok {
+		return nil
+	}
+	grant, isGrantHome := activeGrantHome()
+	if !isGrantHome || len(grant.Scopes) == 0 {
+		return nil
+	}
+	var missing []string
+	for _, scope := range required {
+		if !slices.Contains(grant.Scopes, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return usageError("%s needs grant scope %s, but identity grant %s only has %s; mint a grant with --scope %s from the identity's own .aw home",
+		cmd.CommandPath(), strings.Join(missing, ", "), grant.GrantID, strings.Join(grant.Scopes, ", "), strings.Join(append(slices.Clone(grant.Scopes), missing...), ","))
+}
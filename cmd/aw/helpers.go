@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"context"
+	"crypto"
 	"crypto/ed25519"
 	"encoding/json"
 	"errors"
@@ -192,19 +193,19 @@ func resolveLocalIdentityWithoutState(workingDir string) (*awconfig.ResolvedIden
 		return nil, usageError("current global identity is missing .aw/identity.yaml; restore it or run `aw init` again")
 	}
 
+	certDID := strings.TrimSpace(cert.MemberDIDKey)
+	if certDID == "" {
+		return nil, fmt.Errorf("active team certificate is missing member_did_key")
+	}
 	signingKeyPath := awconfig.WorktreeSigningKeyPath(workingDir)
-	signingKey, err := awid.LoadSigningKey(signingKeyPath)
+	signer, err := loadClientSigner(signingKeyPath, certDID)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return nil, usageError("current identity has no local signing key")
 		}
 		return nil, fmt.Errorf("failed to load signing key: %w", err)
 	}
-	didKey := awid.ComputeDIDKey(signingKey.Public().(ed25519.PublicKey))
-	certDID := strings.TrimSpace(cert.MemberDIDKey)
-	if certDID == "" {
-		return nil, fmt.Errorf("active team certificate is missing member_did_key")
-	}
+	didKey := awid.ComputeDIDKey(signer.PublicKey())
 	if certDID != didKey {
 		return nil, fmt.Errorf("current signing key did:key %q does not match active team certificate member_did_key %q", didKey, certDID)
 	}
@@ -254,14 +255,14 @@ func validateResolvedIdentity(identity *awconfig.ResolvedIdentity) error {
 	if signingKeyPath == "" {
 		return usageError("current identity has no local signing key")
 	}
-	signingKey, err := awid.LoadSigningKey(signingKeyPath)
+	signer, err := loadClientSigner(signingKeyPath, strings.TrimSpace(identity.DID))
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return usageError("current identity has no local signing key")
 		}
 		return fmt.Errorf("failed to load signing key: %w", err)
 	}
-	computedDID := awid.ComputeDIDKey(signingKey.Public().(ed25519.PublicKey))
+	computedDID := awid.ComputeDIDKey(signer.PublicKey())
 	if computedDID != strings.TrimSpace(identity.DID) {
 		return usageError("current identity is invalid: .aw/identity.yaml did %q does not match .aw/signing.key %q", strings.TrimSpace(identity.DID), computedDID)
 	}
@@ -376,7 +377,7 @@ func resolveIdentityMessagingClientSelectionForDir(workingDir string) (*aweb.Cli
 		didKey = strings.TrimSpace(identity.DID)
 	}
 
-	signingKey, err := awid.LoadSigningKey(signingKeyPath)
+	signer, err := loadClientSigner(signingKeyPath, didKey)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return nil, nil, errors.New("current workspace has no local signing key; run `aw init` here first")
@@ -384,10 +385,10 @@ func resolveIdentityMessagingClientSelectionForDir(workingDir string) (*aweb.Cli
 		return nil, nil, fmt.Errorf("load signing key: %w", err)
 	}
 	if didKey == "" {
-		didKey = awid.ComputeDIDKey(signingKey.Public().(ed25519.PublicKey))
+		didKey = awid.ComputeDIDKey(signer.PublicKey())
 	}
 
-	rawClient, err := awid.NewWithIdentity(baseURL, signingKey, didKey)
+	rawClient, err := awid.NewWithIdentitySigner(baseURL, signer, didKey)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -642,6 +643,11 @@ func resolveCertificateClient(sel *awconfig.Selection, baseURL string) (*aweb.Cl
 	if err != nil {
 		return nil, err
 	}
+	if !externalSignerConfigured() {
+		if cert, signer := agentdCertificate(certPath); cert != nil {
+			return aweb.NewWithCertificateSigner(baseURL, signer, cert)
+		}
+	}
 	cert, err := awid.LoadTeamCertificate(certPath)
 	if err != nil {
 		return nil, fmt.Errorf("load team certificate for %s: %w", selectedMembership.TeamID, err)
@@ -653,11 +659,11 @@ func resolveCertificateClient(sel *awconfig.Selection, baseURL string) (*aweb.Cl
 			return nil, err
 		}
 	}
-	signingKey, err := awid.LoadSigningKey(signingKeyPath)
+	signer, err := loadClientSigner(signingKeyPath, cert.MemberDIDKey)
 	if err != nil {
 		return nil, fmt.Errorf("team certificate found but signing key missing: %w", err)
 	}
-	return aweb.NewWithCertificate(baseURL, signingKey, cert)
+	return aweb.NewWithCertificateSigner(baseURL, signer, cert)
 }
 
 func configureResolvedClient(c *aweb.Client, sel *awconfig.Selection, baseURL string) error {
@@ -907,7 +913,7 @@ func newConfiguredRegistryClient(httpClient *http.Client, baseURL string) (*awid
 	return client, nil
 }
 
-func loadOptionalWorktreeSigningKey(workingDir string) (ed25519.PrivateKey, error) {
+func loadOptionalWorktreeSigningKey(workingDir string) (crypto.Signer, error) {
 	home, err := identityHomeForDir(workingDir)
 	if err != nil {
 		return nil, err
@@ -919,14 +925,14 @@ func loadOptionalWorktreeSigningKey(workingDir string) (ed25519.PrivateKey, erro
 			return nil, err
 		}
 	}
-	signingKey, err := awid.LoadSigningKey(signingKeyPath)
+	signer, err := loadClientSigner(signingKeyPath, "")
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return nil, nil
 		}
 		return nil, err
 	}
-	return signingKey, nil
+	return awid.CryptoSigner(signer), nil
 }
 
 func configureEmbeddedRegistryBaseURL(baseURL string, setFallback func(string) error) error {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto"
 	"crypto/ed25519"
 	"errors"
 	"fmt"
@@ -142,11 +143,11 @@ func executeIDAddressClaim(ctx context.Context, workingDir string, opts idAddres
 	}, nil
 }
 
-func loadAddressClaimNamespaceController(domain string) (ed25519.PrivateKey, string, error) {
+func loadAddressClaimNamespaceController(domain string) (crypto.Signer, string, error) {
 	if isHostedAddressClaimDomain(domain) {
 		return nil, "", usageError("namespace authority is required to claim %s; standalone hosted address claims are not supported. Join a hosted team with `aw id team accept-invite` or `aw team join` so aweb Cloud can claim the hosted address during accept.", domain)
 	}
-	exists, err := awconfig.ControllerKeyExists(domain)
+	exists, err := controllerKeyAvailable(domain)
 	if err != nil {
 		return nil, "", err
 	}
@@ -154,7 +155,7 @@ func loadAddressClaimNamespaceController(domain string) (ed25519.PrivateKey, str
 		keyPath, _ := awconfig.ControllerKeyPath(domain)
 		return nil, "", usageError("namespace authority is required to claim %s; no local controller key found at %s", domain, keyPath)
 	}
-	key, err := awconfig.LoadControllerKey(domain)
+	key, err := loadControllerSigner(domain)
 	if err != nil {
 		return nil, "", fmt.Errorf("load namespace controller key for %s: %w", domain, err)
 	}
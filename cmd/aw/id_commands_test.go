@@ -1657,7 +1657,7 @@ func TestAwIDRotateKeyPreservesPendingRotationWhenRecoveryIsUnknown(t *testing.T
 	}
 	pendingDID := awid.ComputeDIDKey(pendingPub)
 	operationID := "11111111-1111-4111-8111-111111111111"
-	pendingKeyPath, err := savePendingRotationKeypair(rotationDir, operationID, pendingPub, pendingPriv)
+	pendingKeyPath, err := savePendingRotationKeypair(rotationDir, operationID, pendingPub, pendingPriv, "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -2651,7 +2651,7 @@ func TestPromotePendingRotationKeypairRecoversInterruptedPromotion(t *testing.T)
 		t.Fatal(err)
 	}
 	newDID := awid.ComputeDIDKey(newPub)
-	pendingKeyPath, err := savePendingRotationKeypair(rotationDir, newDID, newPub, newPriv)
+	pendingKeyPath, err := savePendingRotationKeypair(rotationDir, newDID, newPub, newPriv, "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto"
 	"crypto/ed25519"
 	"errors"
 	"fmt"
@@ -88,7 +89,7 @@ type idCreatePlan struct {
 type preparedIDCreate struct {
 	Plan          *idCreatePlan
 	IdentityKey   ed25519.PrivateKey
-	ControllerKey ed25519.PrivateKey
+	ControllerKey crypto.Signer
 }
 
 func runIDCreate(cmd *cobra.Command, args []string) error {
@@ -441,7 +442,7 @@ func ensureStandaloneRegistryRegistration(
 	ctx context.Context,
 	registry *awid.RegistryClient,
 	plan *idCreatePlan,
-	controllerKey ed25519.PrivateKey,
+	controllerKey crypto.Signer,
 	identityKey ed25519.PrivateKey,
 ) error {
 	if err := ensureStandaloneNamespace(ctx, registry, plan, controllerKey); err != nil {
@@ -467,7 +468,7 @@ func ensureStandaloneNamespace(
 	ctx context.Context,
 	registry *awid.RegistryClient,
 	plan *idCreatePlan,
-	controllerKey ed25519.PrivateKey,
+	controllerKey crypto.Signer,
 ) error {
 	namespace, _, err := registry.GetNamespaceAt(ctx, plan.RegistryURL, plan.Domain)
 	if err == nil {
@@ -536,13 +537,13 @@ func idCreateAtomicClaimError(plan *idCreatePlan, err error) error {
 	return err
 }
 
-func resolveOrCreateControllerKey(domain, registryURL, createdAt string) (ed25519.PrivateKey, string, bool, error) {
-	exists, err := awconfig.ControllerKeyExists(domain)
+func resolveOrCreateControllerKey(domain, registryURL, createdAt string) (crypto.Signer, string, bool, error) {
+	exists, err := controllerKeyAvailable(domain)
 	if err != nil {
 		return nil, "", false, err
 	}
 	if exists {
-		key, err := awconfig.LoadControllerKey(domain)
+		key, err := loadControllerSigner(domain)
 		if err != nil {
 			return nil, "", false, err
 		}
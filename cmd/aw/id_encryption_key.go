@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto"
 	"crypto/ed25519"
 	"encoding/base64"
 	"encoding/json"
@@ -454,14 +455,14 @@ func resolveIdentityForEncryptionKeyForDir(workingDir string, identityHome encry
 	}
 
 	signingKeyPath := awconfig.WorktreeSigningKeyPath(workingDir)
-	signingKey, err := awid.LoadSigningKey(signingKeyPath)
+	signer, err := loadClientSigner(signingKeyPath, "")
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return nil, usageError("current identity has no local signing key")
 		}
 		return nil, fmt.Errorf("failed to load signing key: %w", err)
 	}
-	didKey := awid.ComputeDIDKey(signingKey.Public().(ed25519.PublicKey))
+	didKey := awid.ComputeDIDKey(signer.PublicKey())
 
 	return &awconfig.ResolvedIdentity{
 		WorkingDir:     strings.TrimSpace(workingDir),
@@ -489,11 +490,11 @@ func resolveActiveCertificateIdentityAtHomeForEncryptionKey(workingDir, identity
 	if err != nil {
 		return nil, err
 	}
-	signingKey, err := awid.LoadSigningKey(signingKeyPath)
+	signer, err := loadClientSigner(signingKeyPath, strings.TrimSpace(cert.MemberDIDKey))
 	if err != nil {
 		return nil, fmt.Errorf("load external identity signing key: %w", err)
 	}
-	didKey := awid.ComputeDIDKey(signingKey.Public().(ed25519.PublicKey))
+	didKey := awid.ComputeDIDKey(signer.PublicKey())
 	if certDID := strings.TrimSpace(cert.MemberDIDKey); certDID == "" || certDID != didKey {
 		return nil, fmt.Errorf("external signing key did:key %q does not match active team certificate member_did_key %q", didKey, certDID)
 	}
@@ -552,19 +553,19 @@ func resolveActiveCertificateIdentityForEncryptionKey(workingDir string) (*awcon
 		return nil, nil
 	}
 
+	certDID := strings.TrimSpace(cert.MemberDIDKey)
+	if certDID == "" {
+		return nil, fmt.Errorf("active team certificate is missing member_did_key")
+	}
 	signingKeyPath := awconfig.WorktreeSigningKeyPath(workingDir)
-	signingKey, err := awid.LoadSigningKey(signingKeyPath)
+	signer, err := loadClientSigner(signingKeyPath, certDID)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return nil, usageError("current identity has no local signing key")
 		}
 		return nil, fmt.Errorf("failed to load signing key: %w", err)
 	}
-	didKey := awid.ComputeDIDKey(signingKey.Public().(ed25519.PublicKey))
-	certDID := strings.TrimSpace(cert.MemberDIDKey)
-	if certDID == "" {
-		return nil, fmt.Errorf("active team certificate is missing member_did_key")
-	}
+	didKey := awid.ComputeDIDKey(signer.PublicKey())
 	if certDID != didKey {
 		return nil, fmt.Errorf("current signing key did:key %q does not match active team certificate member_did_key %q", didKey, certDID)
 	}
@@ -600,7 +601,7 @@ func resolveActiveCertificateIdentityForEncryptionKey(workingDir string) (*awcon
 	return resolved, nil
 }
 
-func createLocalEncryptionKeyRecord(identity *awconfig.ResolvedIdentity, signingKey ed25519.PrivateKey, previousKeyID string) (*awconfig.EncryptionKeyRecord, *awid.EncryptionKeyAssertion, error) {
+func createLocalEncryptionKeyRecord(identity *awconfig.ResolvedIdentity, signingKey crypto.Signer, previousKeyID string) (*awconfig.EncryptionKeyRecord, *awid.EncryptionKeyAssertion, error) {
 	priv, rawPub, err := awid.GenerateX25519Keypair()
 	if err != nil {
 		return nil, nil, err
@@ -643,7 +644,7 @@ func createLocalEncryptionKeyRecord(identity *awconfig.ResolvedIdentity, signing
 	}, assertion, nil
 }
 
-func rebindLocalEncryptionKeyRecord(identity *awconfig.ResolvedIdentity, signingKey ed25519.PrivateKey, record *awconfig.EncryptionKeyRecord, material *encryptionRecordKeyMaterial, previousAssertion *awid.EncryptionKeyAssertion) (*awconfig.EncryptionKeyRecord, *awid.EncryptionKeyAssertion, error) {
+func rebindLocalEncryptionKeyRecord(identity *awconfig.ResolvedIdentity, signingKey crypto.Signer, record *awconfig.EncryptionKeyRecord, material *encryptionRecordKeyMaterial, previousAssertion *awid.EncryptionKeyAssertion) (*awconfig.EncryptionKeyRecord, *awid.EncryptionKeyAssertion, error) {
 	if identity == nil || record == nil || material == nil {
 		return nil, nil, errors.New("cannot rebind incomplete E2E encryption key state")
 	}
@@ -750,7 +751,7 @@ func validateEncryptionRecordAssertion(identity *awconfig.ResolvedIdentity, reco
 	return nil
 }
 
-func publishIdentityEncryptionKey(ctx context.Context, identity *awconfig.ResolvedIdentity, signingKey ed25519.PrivateKey, assertion *awid.EncryptionKeyAssertion) ([]string, []string, error) {
+func publishIdentityEncryptionKey(ctx context.Context, identity *awconfig.ResolvedIdentity, signingKey crypto.Signer, assertion *awid.EncryptionKeyAssertion) ([]string, []string, error) {
 	published := []string{}
 	skipped := []string{}
 
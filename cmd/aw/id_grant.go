@@ -21,10 +21,11 @@ var grantCmd = &cobra.Command{
 }
 
 var (
-	grantMintScopes []string
-	grantMintTTL    time.Duration
-	grantMintLabel  string
-	grantMintOut    string
+	grantMintScopes  []string
+	grantMintTTL     time.Duration
+	grantMintLabel   string
+	grantMintOut     string
+	grantMintNoRenew bool
 )
 
 const (
@@ -81,8 +82,8 @@ func resolveGrantClientSelection(workingDir string, home awconfig.IdentityHome)
 	if err != nil {
 		return nil, nil, err
 	}
-	if expires, ok := parseTimeBestEffort(grant.ExpiresAt); ok && time.Now().After(expires) {
-		return nil, nil, fmt.Errorf("identity grant %s expired at %s; mint a new grant from the identity's own .aw home", grant.GrantID, grant.ExpiresAt)
+	if grant, err = refreshGrantHome(home.Root, grant, time.Now()); err != nil {
+		return nil, nil, err
 	}
 	sessionKeyPath := awconfig.GrantHomeSigningKeyPath(home.Root)
 	sessionKey, err := awid.LoadSigningKey(sessionKeyPath)
@@ -118,14 +119,30 @@ func resolveGrantClientSelection(workingDir string, home awconfig.IdentityHome)
 	if err != nil {
 		return nil, nil, err
 	}
+	c.SetGrantScopes(grant.Scopes)
 	if err := configureResolvedClient(c, sel, baseURL); err != nil {
 		return nil, nil, err
 	}
+	if strings.TrimSpace(grant.ParentHome) != "" {
+		go keepGrantRenewed(home.Root, grant, c)
+	}
 
 	lastClient = c
 	return c, sel, nil
 }
 
+// grantParentHome is the absolute identity home a minted grant renews from.
+func grantParentHome(sel *awconfig.Selection) string {
+	root := strings.TrimSpace(sel.IdentityHome)
+	if root == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	return root
+}
+
 func parseGrantScopes(values []string) ([]string, error) {
 	scopes := make([]string, 0, len(values))
 	seen := make(map[string]struct{}, len(values))
@@ -238,6 +255,10 @@ func runGrantMint(cmd *cobra.Command, _ []string) error {
 		ExpiresAt: strings.TrimSpace(view.ExpiresAt),
 		AwebURL:   sel.BaseURL,
 		MintedAt:  firstNonEmpty(strings.TrimSpace(view.IssuedAt), time.Now().UTC().Format(time.RFC3339)),
+		Label:     strings.TrimSpace(grantMintLabel),
+	}
+	if !grantMintNoRenew {
+		state.ParentHome = grantParentHome(sel)
 	}
 	// The session key lands first: a directory only becomes a detectable grant
 	// home (grant.yaml present) once its credential is already on disk.
@@ -386,6 +407,7 @@ func init() {
 	mintCmd.Flags().DurationVar(&grantMintTTL, "ttl", 8*time.Hour, "Grant duration before expiry (60s to 720h)")
 	mintCmd.Flags().StringVar(&grantMintLabel, "label", "", "Optional label for the grant")
 	mintCmd.Flags().StringVar(&grantMintOut, "out", "", "Directory to write the grant home (created fresh; a non-empty directory is refused)")
+	mintCmd.Flags().BoolVar(&grantMintNoRenew, "no-renew", false, "Do not renew the grant from this identity home before it expires")
 	listCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List this identity's session grants",
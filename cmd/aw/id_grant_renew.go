@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	aweb "github.com/awebai/aw"
+	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awid"
+	"github.com/spf13/cobra"
+)
+
+// grantRenewMinLead is how long before expiry a grant is renewed when its
+// original duration is unknown or short.
+const grantRenewMinLead = 5 * time.Minute
+
+// grantRenewRetry is how long a running command waits before trying again
+// after a background renewal did not produce a new grant.
+const grantRenewRetry = time.Minute
+
+// grantRenewParentClient returns a client for the identity home that minted
+// a grant. It signs like any other command there: with a running signer
+// when one holds the key, else with the home's key file.
+var grantRenewParentClient = func(parentHome, teamID string) (*aweb.Client, error) {
+	home := awconfig.IdentityHome{Root: parentHome, Source: awconfig.IdentityHomeFlag}
+	client, _, err := resolveClientSelectionAtIdentityHomeWithTeamOverride(filepath.Dir(parentHome), teamID, home)
+	return client, err
+}
+
+type grantRenewOutput struct {
+	GrantID         string `json:"grant_id"`
+	PreviousGrantID string `json:"previous_grant_id"`
+	ExpiresAt       string `json:"expires_at"`
+}
+
+// grantDuration is how long the grant was minted for, or 0 when grant.yaml
+// does not say.
+func grantDuration(grant *awconfig.GrantHome) time.Duration {
+	minted, okMinted := parseTimeBestEffort(grant.MintedAt)
+	expires, okExpires := parseTimeBestEffort(grant.ExpiresAt)
+	if !okMinted || !okExpires || !expires.After(minted) {
+		return 0
+	}
+	return expires.Sub(minted)
+}
+
+// grantRenewalDue reports whether the grant is in the last quarter of its
+// duration, or within grantRenewMinLead of expiry.
+func grantRenewalDue(grant *awconfig.GrantHome, now time.Time) bool {
+	expires, ok := parseTimeBestEffort(grant.ExpiresAt)
+	if !ok {
+		return false
+	}
+	lead := max(grantDuration(grant)/4, grantRenewMinLead)
+	return !now.Before(expires.Add(-lead))
+}
+
+// renewGrantHome mints a successor grant for the same session key from the
+// parent identity home, then rewrites grant.yaml. Scopes, duration and label
+// come from the parent's record of the current grant, not from grant.yaml,
+// which the grant session can edit; a grant.yaml whose scopes differ from
+// that record is refused. The previous grant is left to expire so commands
+// already using it finish.
+func renewGrantHome(ctx context.Context, root string, grant *awconfig.GrantHome) (*awconfig.GrantHome, error) {
+	parent := strings.TrimSpace(grant.ParentHome)
+	if parent == "" {
+		return nil, fmt.Errorf("identity grant %s does not record the identity home that minted it", grant.GrantID)
+	}
+	sessionKey, err := awid.LoadSigningKey(awconfig.GrantHomeSigningKeyPath(root))
+	if err != nil {
+		return nil, fmt.Errorf("load grant session key: %w", err)
+	}
+	sessionDIDKey := awid.ComputeDIDKey(sessionKey.Public().(ed25519.PublicKey))
+	client, err := grantRenewParentClient(parent, grant.TeamID)
+	if err != nil {
+		return nil, fmt.Errorf("open parent identity home %s: %w", parent, err)
+	}
+	current, err := parentGrantRecord(ctx, client, grant.GrantID, sessionDIDKey)
+	if err != nil {
+		return nil, err
+	}
+	if !sameGrantScopes(grant.Scopes, current.Scopes) {
+		return nil, fmt.Errorf("identity grant %s: grant.yaml lists scopes %s but the identity granted %s; refusing to renew",
+			grant.GrantID, strings.Join(grant.Scopes, ","), strings.Join(current.Scopes, ","))
+	}
+	ttl := grantRecordDuration(current)
+	if ttl < identityGrantMinTTL || ttl > identityGrantMaxTTL {
+		ttl = 8 * time.Hour
+	}
+	view, err := client.MintIdentityGrant(ctx, &aweb.IdentityGrantMintRequest{
+		GrantDIDKey: sessionDIDKey,
+		Scopes:      current.Scopes,
+		TTLSeconds:  int(ttl / time.Second),
+		Label:       strings.TrimSpace(current.Label),
+	})
+	if err != nil {
+		return nil, err
+	}
+	renewed := *grant
+	renewed.GrantID = strings.TrimSpace(view.GrantID)
+	renewed.ExpiresAt = strings.TrimSpace(view.ExpiresAt)
+	renewed.MintedAt = firstNonEmpty(strings.TrimSpace(view.IssuedAt), time.Now().UTC().Format(time.RFC3339))
+	renewed.Scopes = current.Scopes
+	if len(view.Scopes) > 0 {
+		renewed.Scopes = view.Scopes
+	}
+	renewed.Label = strings.TrimSpace(current.Label)
+	if err := awconfig.SaveGrantHomeTo(awconfig.GrantHomeStatePath(root), &renewed); err != nil {
+		return nil, err
+	}
+	return &renewed, nil
+}
+
+// parentGrantRecord looks the grant up in the parent identity's grant list.
+// Only an unrevoked grant for this home's session key is renewed.
+func parentGrantRecord(ctx context.Context, client *aweb.Client, grantID, sessionDIDKey string) (*aweb.IdentityGrantView, error) {
+	resp, err := client.ListIdentityGrants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("look up identity grant %s at its parent: %w", grantID, err)
+	}
+	for i := range resp.Grants {
+		view := &resp.Grants[i]
+		if strings.TrimSpace(view.GrantID) != strings.TrimSpace(grantID) {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(view.Status), "revoked") {
+			return nil, fmt.Errorf("identity grant %s was revoked; refusing to renew", grantID)
+		}
+		if got := strings.TrimSpace(view.GrantDIDKey); got != "" && got != sessionDIDKey {
+			return nil, fmt.Errorf("identity grant %s was granted to %s, not this grant home's session key; refusing to renew", grantID, got)
+		}
+		return view, nil
+	}
+	return nil, fmt.Errorf("identity grant %s is not one of the parent identity's grants; refusing to renew", grantID)
+}
+
+// grantRecordDuration is how long the parent minted a grant for, or 0 when
+// its record does not say.
+func grantRecordDuration(view *aweb.IdentityGrantView) time.Duration {
+	return grantDuration(&awconfig.GrantHome{MintedAt: view.IssuedAt, ExpiresAt: view.ExpiresAt})
+}
+
+func sameGrantScopes(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+// lockGrantHome serializes renewals of one grant home across processes, so
+// concurrent commands do not each mint a successor grant.
+func lockGrantHome(root string) (io.Closer, error) {
+	return awconfig.LockExclusive(awconfig.GrantHomeStatePath(root) + ".lock")
+}
+
+// refreshGrantHome renews a grant that is close to expiry. It holds the
+// grant home lock and rereads grant.yaml first, so a grant another command
+// already renewed is picked up instead of renewed again. A failed renewal
+// is only a warning while the current grant is still valid.
+func refreshGrantHome(root string, grant *awconfig.GrantHome, now time.Time) (*awconfig.GrantHome, error) {
+	if !grantRenewalDue(grant, now) {
+		return grant, nil
+	}
+	if strings.TrimSpace(grant.ParentHome) == "" {
+		if expires, ok := parseTimeBestEffort(grant.ExpiresAt); ok && now.After(expires) {
+			return nil, fmt.Errorf("identity grant %s expired at %s; mint a new grant from the identity's own .aw home", grant.GrantID, grant.ExpiresAt)
+		}
+		return grant, nil
+	}
+	lock, err := lockGrantHome(root)
+	if err != nil {
+		return nil, fmt.Errorf("lock grant home: %w", err)
+	}
+	defer func() { _ = lock.Close() }()
+	if latest, err := awconfig.LoadGrantHome(root); err == nil {
+		grant = latest
+	}
+	if !grantRenewalDue(grant, now) {
+		return grant, nil
+	}
+	expires, ok := parseTimeBestEffort(grant.ExpiresAt)
+	expired := ok && now.After(expires)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	renewed, err := renewGrantHome(ctx, root, grant)
+	if err == nil {
+		debugLog("grant: renewed %s as %s, expires %s", grant.GrantID, renewed.GrantID, renewed.ExpiresAt)
+		return renewed, nil
+	}
+	if expired {
+		return nil, fmt.Errorf("identity grant %s expired at %s and could not be renewed: %w", grant.GrantID, grant.ExpiresAt, err)
+	}
+	fmt.Fprintf(os.Stderr, "warning: could not renew identity grant %s before it expires at %s: %v\n", grant.GrantID, grant.ExpiresAt, err)
+	return grant, nil
+}
+
+// keepGrantRenewed renews the grant behind client whenever it comes due and
+// swaps the successor into client, so long-running commands such as aw run,
+// aw chat listen and aw mcp serve keep working past the first grant's
+// expiry. It runs until the process exits.
+func keepGrantRenewed(root string, grant *awconfig.GrantHome, client *aweb.Client) {
+	for {
+		wait := grantRenewRetry
+		if expires, ok := parseTimeBestEffort(grant.ExpiresAt); ok {
+			lead := max(grantDuration(grant)/4, grantRenewMinLead)
+			wait = max(time.Until(expires.Add(-lead)), grantRenewRetry)
+		}
+		time.Sleep(wait)
+		next, err := refreshGrantHome(root, grant, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			continue
+		}
+		if next.GrantID != grant.GrantID {
+			client.SwapGrant(next.GrantID, next.Scopes)
+		}
+		grant = next
+	}
+}
+
+func runGrantRenew(cmd *cobra.Command, _ []string) error {
+	wd, _ := os.Getwd()
+	home, err := identityHomeForDir(wd)
+	if err != nil {
+		return err
+	}
+	if !awconfig.IsGrantHome(home.Root) {
+		return usageError("aw id grant renew runs from a grant home; this identity home is not one")
+	}
+	lock, err := lockGrantHome(home.Root)
+	if err != nil {
+		return fmt.Errorf("lock grant home: %w", err)
+	}
+	defer func() { _ = lock.Close() }()
+	grant, err := awconfig.LoadGrantHome(home.Root)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	renewed, err := renewGrantHome(ctx, home.Root, grant)
+	if err != nil {
+		return err
+	}
+	out := grantRenewOutput{GrantID: renewed.GrantID, PreviousGrantID: grant.GrantID, ExpiresAt: renewed.ExpiresAt}
+	printOutput(out, func(any) string {
+		return fmt.Sprintf("Renewed grant %s as %s, expires %s.\n", out.PreviousGrantID, out.GrantID, out.ExpiresAt)
+	})
+	return nil
+}
+
+func init() {
+	grantCmd.AddCommand(&cobra.Command{
+		Use:   "renew",
+		Short: "Renew this grant home's grant from the identity that minted it",
+		RunE:  runGrantRenew,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	aweb "github.com/awebai/aw"
 	"github.com/awebai/aw/awconfig"
 	"github.com/awebai/aw/awid"
 	"github.com/spf13/cobra"
@@ -24,6 +26,7 @@ func resetGrantCommandGlobals(t *testing.T) {
 		grantMintTTL = 8 * time.Hour
 		grantMintLabel = ""
 		grantMintOut = ""
+		grantMintNoRenew = false
 		jsonFlag = false
 	}
 	reset()
@@ -150,6 +153,9 @@ func TestRunGrantMintWritesGrantHome(t *testing.T) {
 	if grant.Subject.Alias != "alice" || grant.Subject.DIDAW != "did:aw:alice" {
 		t.Fatalf("grant subject=%+v", grant.Subject)
 	}
+	if grant.Label != "worker" || grant.ParentHome != filepath.Join(tmp, ".aw") {
+		t.Fatalf("grant label=%q parent_home=%q", grant.Label, grant.ParentHome)
+	}
 
 	keyPath := awconfig.GrantHomeSigningKeyPath(outDir)
 	info, err := os.Stat(keyPath)
@@ -274,3 +280,197 @@ func TestRootAuthorityCommandsRefuseGrantHome(t *testing.T) {
 		t.Fatalf("selection error=%v, want grant-home refusal", err)
 	}
 }
+
+func TestGrantHomeRenewsFromParentBeforeExpiry(t *testing.T) {
+	resetGrantCommandGlobals(t)
+	tmp := t.TempDir()
+	t.Chdir(tmp)
+	setGrantTestEnv(t, tmp)
+
+	var mintBody aweb.IdentityGrantMintRequest
+	var sessionDIDKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/identity-grants":
+			_ = json.NewEncoder(w).Encode(aweb.IdentityGrantListResponse{Grants: []aweb.IdentityGrantView{{
+				GrantID:     "grant-777",
+				GrantDIDKey: sessionDIDKey,
+				Scopes:      []string{"mail.send", "mail.read"},
+				Status:      "active",
+				IssuedAt:    time.Now().Add(-55 * time.Minute).UTC().Format(time.RFC3339),
+				ExpiresAt:   time.Now().Add(5 * time.Minute).UTC().Format(time.RFC3339),
+			}}})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/identity-grants":
+			if err := json.NewDecoder(r.Body).Decode(&mintBody); err != nil {
+				t.Errorf("decode mint body: %v", err)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"grant_id":   "grant-778",
+				"scopes":     mintBody.Scopes,
+				"issued_at":  time.Now().UTC().Format(time.RFC3339),
+				"expires_at": time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/agents":
+			if got := r.Header.Get("X-AWEB-Grant-ID"); got != "grant-778" {
+				t.Errorf("request used grant %q", got)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"agents": []any{}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	root := filepath.Join(tmp, ".aw")
+	sessionPub, grant := writeGrantHomeForTest(t, root, server.URL)
+	sessionDIDKey = awid.ComputeDIDKey(sessionPub)
+	grant.MintedAt = time.Now().Add(-55 * time.Minute).UTC().Format(time.RFC3339)
+	grant.ExpiresAt = time.Now().Add(5 * time.Minute).UTC().Format(time.RFC3339)
+	grant.ParentHome = filepath.Join(tmp, "parent", ".aw")
+	if err := awconfig.SaveGrantHomeTo(awconfig.GrantHomeStatePath(root), grant); err != nil {
+		t.Fatal(err)
+	}
+	var parentTeam string
+	oldParentClient := grantRenewParentClient
+	grantRenewParentClient = func(parentHome, teamID string) (*aweb.Client, error) {
+		if parentHome != grant.ParentHome {
+			t.Errorf("parent home=%q", parentHome)
+		}
+		parentTeam = teamID
+		return aweb.New(server.URL)
+	}
+	t.Cleanup(func() { grantRenewParentClient = oldParentClient })
+
+	client, _, err := resolveClientSelectionForDir(tmp)
+	if err != nil {
+		t.Fatalf("resolveClientSelectionForDir: %v", err)
+	}
+	if client.GrantID() != "grant-778" || parentTeam != grant.TeamID {
+		t.Fatalf("client grant=%q parent team=%q", client.GrantID(), parentTeam)
+	}
+	if mintBody.GrantDIDKey != sessionDIDKey || mintBody.TTLSeconds != 3600 || strings.Join(mintBody.Scopes, ",") != "mail.send,mail.read" {
+		t.Fatalf("renewal mint=%+v", mintBody)
+	}
+	if _, err := client.Client.ListAgents(context.Background()); err != nil {
+		t.Fatalf("ListAgents through renewed grant: %v", err)
+	}
+	saved, err := awconfig.LoadGrantHome(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.GrantID != "grant-778" || saved.ParentHome != grant.ParentHome {
+		t.Fatalf("saved grant=%+v", saved)
+	}
+}
+
+func TestGrantHomeRenewalRefusesEditedScopes(t *testing.T) {
+	resetGrantCommandGlobals(t)
+	tmp := t.TempDir()
+	t.Chdir(tmp)
+	setGrantTestEnv(t, tmp)
+
+	var sessionDIDKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/v1/identity-grants" {
+			_ = json.NewEncoder(w).Encode(aweb.IdentityGrantListResponse{Grants: []aweb.IdentityGrantView{{
+				GrantID:     "grant-777",
+				GrantDIDKey: sessionDIDKey,
+				Scopes:      []string{"mail.read"},
+				IssuedAt:    time.Now().Add(-55 * time.Minute).UTC().Format(time.RFC3339),
+				ExpiresAt:   time.Now().Add(5 * time.Minute).UTC().Format(time.RFC3339),
+			}}})
+			return
+		}
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	root := filepath.Join(tmp, ".aw")
+	sessionPub, grant := writeGrantHomeForTest(t, root, server.URL)
+	sessionDIDKey = awid.ComputeDIDKey(sessionPub)
+	grant.ExpiresAt = time.Now().Add(5 * time.Minute).UTC().Format(time.RFC3339)
+	grant.ParentHome = filepath.Join(tmp, "parent", ".aw")
+	if err := awconfig.SaveGrantHomeTo(awconfig.GrantHomeStatePath(root), grant); err != nil {
+		t.Fatal(err)
+	}
+	oldParentClient := grantRenewParentClient
+	grantRenewParentClient = func(string, string) (*aweb.Client, error) { return aweb.New(server.URL) }
+	t.Cleanup(func() { grantRenewParentClient = oldParentClient })
+
+	_, err := renewGrantHome(context.Background(), root, grant)
+	if err == nil || !strings.Contains(err.Error(), "refusing to renew") {
+		t.Fatalf("err=%v, want scope mismatch refusal", err)
+	}
+	saved, err := awconfig.LoadGrantHome(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.GrantID != "grant-777" {
+		t.Fatalf("saved grant=%+v", saved)
+	}
+}
+
+func TestGrantHomeExpiredWithoutParentIsRefused(t *testing.T) {
+	resetGrantCommandGlobals(t)
+	tmp := t.TempDir()
+	t.Chdir(tmp)
+	setGrantTestEnv(t, tmp)
+
+	root := filepath.Join(tmp, ".aw")
+	_, grant := writeGrantHomeForTest(t, root, "https://app.aweb.ai")
+	grant.ExpiresAt = "2026-01-01T00:00:00Z"
+	if err := awconfig.SaveGrantHomeTo(awconfig.GrantHomeStatePath(root), grant); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := resolveClientSelectionForDir(tmp); err == nil || !strings.Contains(err.Error(), "expired at 2026-01-01T00:00:00Z; mint a new grant") {
+		t.Fatalf("error=%v, want expiry refusal", err)
+	}
+}
+
+func TestGrantHomeRefusesOutOfScopeCommandsAndRequests(t *testing.T) {
+	resetGrantCommandGlobals(t)
+	tmp := t.TempDir()
+	t.Chdir(tmp)
+	setGrantTestEnv(t, tmp)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("out-of-scope request reached the server: %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+	writeGrantHomeForTest(t, filepath.Join(tmp, ".aw"), server.URL)
+
+	if err := requireGrantCommandScope(mailSendCmd); err != nil {
+		t.Fatalf("mail send within scope refused: %v", err)
+	}
+	err := requireGrantCommandScope(chatSendCmd)
+	if err == nil || !strings.Contains(err.Error(), "needs grant scope chat.send") || !strings.Contains(err.Error(), "--scope mail.read,mail.send,chat.send") {
+		t.Fatalf("chat send error=%v, want scope refusal", err)
+	}
+
+	client, _, err := resolveClientSelectionForDir(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Client.ChatPending(context.Background())
+	var scopeErr *awid.GrantScopeError
+	if !errors.As(err, &scopeErr) || scopeErr.Scope != awid.GrantScopeChatRead {
+		t.Fatalf("ChatPending error=%v, want grant scope error", err)
+	}
+}
+
+func TestFormatIntrospectShowsGrantTimeLeft(t *testing.T) {
+	got := formatIntrospect(introspectOutput{
+		Alias:                 "alice",
+		GrantID:               "grant-777",
+		GrantStatus:           "active",
+		GrantExpiresAt:        "2099-01-01T00:00:00Z",
+		GrantRemainingSeconds: 3*3600 + 12*60,
+		GrantRenewsFrom:       "/home/alice/.aw",
+	})
+	if !strings.Contains(got, "Grant:     grant-777 (active, expires 2099-01-01T00:00:00Z, 3h12m left)\n") || !strings.Contains(got, "Renews:    from /home/alice/.aw\n") {
+		t.Fatalf("whoami output:\n%s", got)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awid"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var idKeysPassphraseFile string
+
+type idKeysOutput struct {
+	Status    string   `json:"status"`
+	Changed   []string `json:"changed,omitempty"`
+	Unchanged []string `json:"unchanged,omitempty"`
+}
+
+var idKeysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Encrypt or decrypt private keys at rest",
+	Long: `Private keys are plaintext PEM files by default, protected only by file
+mode. encrypt seals them with a passphrase (scrypt + AES-256-GCM); every aw
+command that needs an encrypted key then unlocks it from $AW_KEY_PASSPHRASE_FILE
+or a terminal prompt. To unlock once per session, run ` + "`aw agentd --authority-keys`" + `,
+which holds the unlocked keys and signs for other aw commands.
+
+Without arguments the commands act on this identity's signing key and every
+namespace controller and team key under ~/.awid.`,
+}
+
+var idKeysEncryptCmd = &cobra.Command{
+	Use:   "encrypt [key-file...]",
+	Short: "Encrypt private keys with a passphrase",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIDKeysConvert(args, true)
+	},
+}
+
+var idKeysDecryptCmd = &cobra.Command{
+	Use:   "decrypt [key-file...]",
+	Short: "Rewrite encrypted private keys as plaintext",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIDKeysConvert(args, false)
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{idKeysEncryptCmd, idKeysDecryptCmd} {
+		cmd.Flags().StringVar(&idKeysPassphraseFile, "passphrase-file", "", "Read the passphrase from this file (default: $AW_KEY_PASSPHRASE_FILE or prompt)")
+	}
+	idKeysCmd.AddCommand(idKeysEncryptCmd, idKeysDecryptCmd)
+	identityCmd.AddCommand(idKeysCmd)
+}
+
+func runIDKeysConvert(args []string, encrypt bool) error {
+	paths, err := idKeysTargets(args)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return usageError("no private keys found; pass key files explicitly")
+	}
+	passphrase, err := idKeysPassphrase(encrypt)
+	if err != nil {
+		return err
+	}
+	out := idKeysOutput{Status: "ok"}
+	for _, path := range paths {
+		var changed bool
+		if encrypt {
+			changed, err = awid.EncryptPrivateKeyFile(path, passphrase)
+		} else {
+			changed, err = awid.DecryptPrivateKeyFile(path, passphrase)
+		}
+		if err != nil {
+			return err
+		}
+		if changed {
+			out.Changed = append(out.Changed, path)
+		} else {
+			out.Unchanged = append(out.Unchanged, path)
+		}
+	}
+	verb := "Encrypted"
+	if !encrypt {
+		verb = "Decrypted"
+	}
+	printOutput(out, func(v any) string {
+		return formatIDKeys(v.(idKeysOutput), verb)
+	})
+	return nil
+}
+
+// idKeysTargets returns the explicit key files, or this identity's signing
+// key plus every controller and team key on the machine.
+func idKeysTargets(args []string) ([]string, error) {
+	if len(args) > 0 {
+		paths := make([]string, 0, len(args))
+		for _, arg := range args {
+			path, err := filepath.Abs(strings.TrimSpace(arg))
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, path)
+		}
+		return paths, nil
+	}
+	var paths []string
+	workingDir, _ := os.Getwd()
+	if home, err := identityHomeForDir(workingDir); err == nil {
+		signingKey := filepath.Join(home.Root, "signing.key")
+		if _, err := os.Stat(signingKey); err == nil {
+			paths = append(paths, signingKey)
+		}
+	}
+	authorityKeys, err := authorityKeyFiles()
+	if err != nil {
+		return nil, err
+	}
+	return append(paths, authorityKeys...), nil
+}
+
+// authorityKeyFiles lists namespace controller and team private keys in
+// the current and legacy state directories.
+func authorityKeyFiles() ([]string, error) {
+	var patterns []string
+	for _, dir := range []func() (string, error){awconfig.DefaultControllersDir, awconfig.LegacyControllersDir} {
+		root, err := dir()
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, filepath.Join(root, "*.key"))
+	}
+	for _, elem := range []func(...string) (string, error){awconfig.PathInAWIDState, awconfig.PathInUserState} {
+		root, err := elem("team-keys")
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, filepath.Join(root, "*", "*.key"))
+	}
+	var paths []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// idKeysPassphrase reads the passphrase from --passphrase-file, the
+// environment or the terminal. A new passphrase is asked twice.
+func idKeysPassphrase(confirm bool) ([]byte, error) {
+	if path := strings.TrimSpace(idKeysPassphraseFile); path != "" {
+		return readPassphraseFile(path)
+	}
+	if passphrase, ok, err := passphraseFromEnvFile(); ok || err != nil {
+		return passphrase, err
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, usageError("no passphrase: pass --passphrase-file or set %s", keyPassphraseFileEnv)
+	}
+	passphrase, err := promptPassphrase("Passphrase: ")
+	if err != nil || !confirm {
+		return passphrase, err
+	}
+	again, err := promptPassphrase("Repeat passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(passphrase, again) {
+		return nil, errors.New("passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+func formatIDKeys(out idKeysOutput, verb string) string {
+	var sb strings.Builder
+	for _, path := range out.Changed {
+		fmt.Fprintf(&sb, "%s %s\n", verb, abbreviateUserHome(path))
+	}
+	for _, path := range out.Unchanged {
+		fmt.Fprintf(&sb, "Unchanged %s\n", abbreviateUserHome(path))
+	}
+	return sb.String()
+}
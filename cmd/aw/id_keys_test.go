@@ -0,0 +1,118 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awid"
+)
+
+func TestIDKeysEncryptControllerKeysAndHoldThemInAgentd(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv("AW_HOME", filepath.Join(tmp, "aw-home"))
+	t.Setenv(keyPassphraseFileEnv, "")
+	t.Chdir(tmp)
+	t.Cleanup(func() {
+		idKeysPassphraseFile = ""
+		awid.SetKeyPassphraseSource(unlockKeyPassphrase)
+	})
+
+	_, controller, _ := awid.GenerateKeypair()
+	if err := awconfig.SaveControllerKey("acme.com", controller); err != nil {
+		t.Fatal(err)
+	}
+	_, team, _ := awid.GenerateKeypair()
+	if err := awconfig.SaveTeamKey("acme.com", "default", team); err != nil {
+		t.Fatal(err)
+	}
+
+	doctor := &doctorRunner{}
+	doctor.runAuthorityKeyEncryptionCheck()
+	if len(doctor.output.Checks) != 1 || doctor.output.Checks[0].Status != doctorStatusWarn {
+		t.Fatalf("checks=%+v", doctor.output.Checks)
+	}
+
+	passphraseFile := filepath.Join(tmp, "passphrase")
+	if err := os.WriteFile(passphraseFile, []byte("correct horse\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	idKeysPassphraseFile = passphraseFile
+	if err := runIDKeysConvert(nil, true); err != nil {
+		t.Fatal(err)
+	}
+	doctor = &doctorRunner{}
+	doctor.runAuthorityKeyEncryptionCheck()
+	if len(doctor.output.Checks) != 1 || doctor.output.Checks[0].Status != doctorStatusOK {
+		t.Fatalf("checks=%+v", doctor.output.Checks)
+	}
+
+	// A fresh source has no remembered passphrase; agentd unlocks every
+	// authority key once and holds them.
+	awid.SetKeyPassphraseSource(unlockKeyPassphrase)
+	t.Setenv(keyPassphraseFileEnv, passphraseFile)
+	held, err := agentdLoadKeys(nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(held) != 2 || !held[0].Equal(controller) && !held[1].Equal(controller) {
+		t.Fatalf("agentd held %d keys, want the controller and team keys", len(held))
+	}
+
+	// A replacement for an encrypted key is sealed under the same passphrase.
+	teamPath, _ := awconfig.TeamKeyPath("acme.com", "default")
+	_, replacement, _ := awid.GenerateKeypair()
+	replacementPath := filepath.Join(tmp, "replacement.key")
+	if err := awid.SaveKeypairLike(replacementPath, awid.PublicKeyPath(replacementPath), replacement.Public().(ed25519.PublicKey), replacement, teamPath); err != nil {
+		t.Fatal(err)
+	}
+	if encrypted, err := awid.IsEncryptedPrivateKeyFile(replacementPath); err != nil || !encrypted {
+		t.Fatalf("replacement encrypted=%v err=%v", encrypted, err)
+	}
+	t.Setenv(keyPassphraseFileEnv, "")
+
+	if err := runIDKeysConvert(nil, false); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := awconfig.ControllerKeyPath("acme.com")
+	if encrypted, err := awid.IsEncryptedPrivateKeyFile(path); err != nil || encrypted {
+		t.Fatalf("encrypted=%v err=%v", encrypted, err)
+	}
+}
+
+func TestDoctorReportsUnreadableAuthorityKeysSeparately(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv("AW_HOME", filepath.Join(tmp, "aw-home"))
+
+	_, controller, _ := awid.GenerateKeypair()
+	if err := awconfig.SaveControllerKey("acme.com", controller); err != nil {
+		t.Fatal(err)
+	}
+	teamPath, err := awconfig.TeamKeyPath("acme.com", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(teamPath), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(teamPath, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	doctor := &doctorRunner{}
+	doctor.runAuthorityKeyEncryptionCheck()
+	checks := doctor.output.Checks
+	if len(checks) != 2 {
+		t.Fatalf("checks=%+v", checks)
+	}
+	if checks[0].ID != doctorCheckAuthorityKeysReadable || checks[0].Status != doctorStatusFail {
+		t.Fatalf("readable check=%+v", checks[0])
+	}
+	if checks[1].ID != doctorCheckAuthorityKeysEncrypted || checks[1].Status != doctorStatusWarn || checks[1].Message != "1 of 1 controller and team keys are stored unencrypted." {
+		t.Fatalf("encrypted check=%+v", checks[1])
+	}
+}
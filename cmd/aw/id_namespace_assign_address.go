@@ -78,7 +78,7 @@ func executeIDNamespaceAssignAddress(ctx context.Context, opts idNamespaceAssign
 	if !strings.HasPrefix(didAW, "did:aw:") || strings.TrimSpace(strings.TrimPrefix(didAW, "did:aw:")) == "" {
 		return idNamespaceAssignAddressOutput{}, usageError("--did-aw must be a non-empty did:aw: identifier")
 	}
-	exists, err := awconfig.ControllerKeyExists(domain)
+	exists, err := controllerKeyAvailable(domain)
 	if err != nil {
 		return idNamespaceAssignAddressOutput{}, err
 	}
@@ -86,7 +86,7 @@ func executeIDNamespaceAssignAddress(ctx context.Context, opts idNamespaceAssign
 		keyPath, _ := awconfig.ControllerKeyPath(domain)
 		return idNamespaceAssignAddressOutput{}, fmt.Errorf("no controller key for domain %q (expected at %s); import the controller seed before assigning addresses", domain, keyPath)
 	}
-	controllerKey, err := awconfig.LoadControllerKey(domain)
+	controllerKey, err := loadControllerSigner(domain)
 	if err != nil {
 		return idNamespaceAssignAddressOutput{}, fmt.Errorf("load controller key for %s: %w", domain, err)
 	}
@@ -2,12 +2,12 @@ package main
 
 import (
 	"context"
+	"crypto"
 	"crypto/ed25519"
 	"fmt"
 	"strings"
 	"time"
 
-	"github.com/awebai/aw/awconfig"
 	"github.com/awebai/aw/awid"
 	"github.com/spf13/cobra"
 )
@@ -94,11 +94,11 @@ func executeIDNamespaceCheckTXT(opts idNamespaceCheckTXTOptions) (idNamespaceChe
 	}, nil
 }
 
-func loadIDNamespaceCheckTXTKey(domain, path string) (ed25519.PrivateKey, error) {
+func loadIDNamespaceCheckTXTKey(domain, path string) (crypto.Signer, error) {
 	if strings.TrimSpace(path) != "" {
 		return awid.LoadSigningKey(strings.TrimSpace(path))
 	}
-	key, err := awconfig.LoadControllerKey(domain)
+	key, err := loadControllerSigner(domain)
 	if err != nil {
 		return nil, fmt.Errorf("load namespace controller key for %s: %w (run `aw id namespace prepare-controller --domain %s` first)", domain, err, domain)
 	}
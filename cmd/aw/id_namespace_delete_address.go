@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto"
 	"crypto/ed25519"
 	"fmt"
 	"net/http"
@@ -109,8 +110,8 @@ func executeIDNamespaceDeleteAddress(ctx context.Context, opts idNamespaceDelete
 	}, nil
 }
 
-func loadVerifiedNamespaceControllerKey(ctx context.Context, domain, registryOverride string) (ed25519.PrivateKey, string, error) {
-	exists, err := awconfig.ControllerKeyExists(domain)
+func loadVerifiedNamespaceControllerKey(ctx context.Context, domain, registryOverride string) (crypto.Signer, string, error) {
+	exists, err := controllerKeyAvailable(domain)
 	if err != nil {
 		return nil, "", err
 	}
@@ -118,7 +119,7 @@ func loadVerifiedNamespaceControllerKey(ctx context.Context, domain, registryOve
 		keyPath, _ := awconfig.ControllerKeyPath(domain)
 		return nil, "", fmt.Errorf("no controller key for domain %q (expected at %s)", domain, keyPath)
 	}
-	controllerKey, err := awconfig.LoadControllerKey(domain)
+	controllerKey, err := loadControllerSigner(domain)
 	if err != nil {
 		return nil, "", fmt.Errorf("load controller key for %s: %w", domain, err)
 	}
@@ -67,7 +67,7 @@ func executeIDNamespaceDeliveryOrigin(ctx context.Context, opts idNamespaceDeliv
 		return idNamespaceDeliveryOriginOutput{}, fmt.Errorf("--origin: %w", err)
 	}
 
-	exists, err := awconfig.ControllerKeyExists(domain)
+	exists, err := controllerKeyAvailable(domain)
 	if err != nil {
 		return idNamespaceDeliveryOriginOutput{}, err
 	}
@@ -75,7 +75,7 @@ func executeIDNamespaceDeliveryOrigin(ctx context.Context, opts idNamespaceDeliv
 		keyPath, _ := awconfig.ControllerKeyPath(domain)
 		return idNamespaceDeliveryOriginOutput{}, fmt.Errorf("no controller key for domain %q (expected at %s); import the controller seed before setting delivery origin", domain, keyPath)
 	}
-	controllerKey, err := awconfig.LoadControllerKey(domain)
+	controllerKey, err := loadControllerSigner(domain)
 	if err != nil {
 		return idNamespaceDeliveryOriginOutput{}, fmt.Errorf("load controller key for %s: %w", domain, err)
 	}
@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"crypto/ed25519"
+	"crypto"
 	"errors"
 	"fmt"
 	"net/http"
@@ -422,7 +422,7 @@ func executeIDNamespaceRotateController(opts idNamespaceRotateControllerOptions)
 	}, nil
 }
 
-func requireGlobalSelfCustodialIdentity(identity *awconfig.ResolvedIdentity, signingKey ed25519.PrivateKey) error {
+func requireGlobalSelfCustodialIdentity(identity *awconfig.ResolvedIdentity, signingKey crypto.Signer) error {
 	if identity == nil {
 		return fmt.Errorf("missing identity context")
 	}
@@ -508,18 +508,18 @@ func resolveIdentityRegistryClient(identity *awconfig.ResolvedIdentity) (*awid.R
 	return registry, nil
 }
 
-func resolveIdentitySigningKey(identity *awconfig.ResolvedIdentity) (ed25519.PrivateKey, error) {
+func resolveIdentitySigningKey(identity *awconfig.ResolvedIdentity) (crypto.Signer, error) {
 	if identity == nil {
 		return nil, fmt.Errorf("missing identity context")
 	}
 	if strings.TrimSpace(identity.SigningKeyPath) == "" {
 		return nil, usageError("current identity has no local signing key")
 	}
-	priv, err := awid.LoadSigningKey(identity.SigningKeyPath)
+	signer, err := loadClientSigner(identity.SigningKeyPath, strings.TrimSpace(identity.DID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load signing key: %w", err)
 	}
-	return priv, nil
+	return awid.CryptoSigner(signer), nil
 }
 
 func resolveOptionalGlobalIdentityForLookup(workingDir string) (*awconfig.ResolvedIdentity, error) {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto"
 	"crypto/ed25519"
 	"errors"
 	"fmt"
@@ -92,7 +93,7 @@ type registryReadError struct {
 type registryReadAuthority struct {
 	Mode       string
 	SubjectDID string
-	SigningKey ed25519.PrivateKey
+	SigningKey crypto.Signer
 }
 
 var (
@@ -364,7 +365,7 @@ func resolveRegistryReadAuthority(workingDir, domain, rawMode string) (registryR
 			SigningKey: signingKey,
 		}, nil
 	case registryReadAuthorityNamespaceController:
-		signingKey, err := awconfig.LoadControllerKey(domain)
+		signingKey, err := loadControllerSigner(domain)
 		if err != nil {
 			return registryReadAuthority{}, fmt.Errorf("load namespace controller key for %s: %w", domain, err)
 		}
@@ -123,7 +123,7 @@ func runIDRotateKey(cmd *cobra.Command, args []string) error {
 	}
 	// Crash-test observation only; the state rename is now visible to recovery.
 	crashtest.Checkpoint("after-pending-state-commit")
-	if _, err := savePendingRotationKeypair(rotationDir, operationID, newPub, newPriv); err != nil {
+	if _, err := savePendingRotationKeypair(rotationDir, operationID, newPub, newPriv, identity.SigningKeyPath); err != nil {
 		_ = cleanupPendingRotationKeypair(pendingKeyPath, newDID)
 		_ = removePendingRotationStateOwned(rotationDir, identity.StableID, operationID)
 		return err
@@ -698,7 +698,7 @@ func seedForeignRotationOperation(t *testing.T, dir string) *foreignRotationOper
 	if err != nil {
 		t.Fatal(err)
 	}
-	privatePath, err := savePendingRotationKeypair(rotationDir, operationID, pub, priv)
+	privatePath, err := savePendingRotationKeypair(rotationDir, operationID, pub, priv, "")
 	if err != nil {
 		t.Fatal(err)
 	}
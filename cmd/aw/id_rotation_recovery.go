@@ -121,7 +121,9 @@ func removePendingRotationStateOwned(rotationDir, stableID, operationID string)
 	return nil
 }
 
-func savePendingRotationKeypair(rotationDir, did string, pub ed25519.PublicKey, priv ed25519.PrivateKey) (string, error) {
+// savePendingRotationKeypair writes the replacement keypair, encrypted under
+// the active key's passphrase when activeKeyPath holds an encrypted key.
+func savePendingRotationKeypair(rotationDir, did string, pub ed25519.PublicKey, priv ed25519.PrivateKey, activeKeyPath string) (string, error) {
 	keyPath, pubPath := pendingRotationKeyPaths(rotationDir, did)
 	if err := preflightRotationFile(keyPath); err != nil {
 		return "", err
@@ -129,7 +131,7 @@ func savePendingRotationKeypair(rotationDir, did string, pub ed25519.PublicKey,
 	if err := preflightRotationFile(pubPath); err != nil {
 		return "", err
 	}
-	if err := awid.SaveKeypairAt(keyPath, pubPath, pub, priv); err != nil {
+	if err := awid.SaveKeypairLike(keyPath, pubPath, pub, priv, activeKeyPath); err != nil {
 		return "", err
 	}
 	return keyPath, nil
@@ -302,7 +304,7 @@ func ensurePublicKeyMatchesPrivate(signingKeyPath string) error {
 		return err
 	}
 	pub := priv.Public().(ed25519.PublicKey)
-	return awid.SaveKeypairAt(signingKeyPath, awid.PublicKeyPath(signingKeyPath), pub, priv)
+	return awid.SaveKeypairLike(signingKeyPath, awid.PublicKeyPath(signingKeyPath), pub, priv, signingKeyPath)
 }
 
 func loadRotationSigningKey(activeKeyPath string, pending *pendingRotationState) (ed25519.PrivateKey, bool, error) {
@@ -164,7 +164,7 @@ func TestAwIDRotateKeyRecoverReconcilesAuthoritativeState(t *testing.T) {
 			writeStandaloneSelfCustodyIdentity(t, dir, "acme.com/alice", oldDID, stableID, fixture.server.URL, oldPriv)
 			operationID := "11111111-1111-4111-8111-111111111111"
 			rotationDir := filepath.Join(dir, ".aw", "rotation")
-			pendingKey, err := savePendingRotationKeypair(rotationDir, operationID, newPub, newPriv)
+			pendingKey, err := savePendingRotationKeypair(rotationDir, operationID, newPub, newPriv, "")
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -273,7 +273,7 @@ func TestAwIDRotateKeyWithPendingStateRecoversAndRequiresRerun(t *testing.T) {
 	writeStandaloneSelfCustodyIdentity(t, dir, "acme.com/alice", oldDID, stableID, fixture.server.URL, oldPriv)
 	operationID := "11111111-1111-4111-8111-111111111111"
 	rotationDir := filepath.Join(dir, ".aw", "rotation")
-	pendingKey, err := savePendingRotationKeypair(rotationDir, operationID, newPub, newPriv)
+	pendingKey, err := savePendingRotationKeypair(rotationDir, operationID, newPub, newPriv, "")
 	if err != nil {
 		t.Fatal(err)
 	}
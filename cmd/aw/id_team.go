@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto"
 	"crypto/ed25519"
 	"encoding/base64"
 	"encoding/json"
@@ -200,7 +201,7 @@ type certShowOutput struct {
 type localTeamRegistration struct {
 	TeamID      string
 	TeamDIDKey  string
-	TeamKey     ed25519.PrivateKey
+	TeamKey     crypto.Signer
 	TeamKeyPath string
 }
 
@@ -614,7 +615,7 @@ func runTeamCreate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load namespace controller key for auth
-	controllerKey, err := awconfig.LoadControllerKey(domain)
+	controllerKey, err := loadControllerSigner(domain)
 	if err != nil {
 		return fmt.Errorf("load controller key for %s: %w (run `aw id namespace prepare-controller --domain %s` first)", domain, err, domain)
 	}
@@ -1369,7 +1370,10 @@ func acceptTeamInviteWithDetails(workingDir, token string, opts teamAcceptInvite
 
 	teamID := awid.BuildTeamID(invite.Domain, invite.TeamName)
 
-	teamKey, err := awconfig.LoadTeamKey(invite.Domain, invite.TeamName)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	teamKey, err := loadTeamSigner(ctx, invite.Domain, invite.TeamName)
 	if err != nil {
 		return nil, fmt.Errorf("local team controller key for %s not found: %w (cross-machine joins should use `aw id team request`, controller-side `aw id team add-member`, then invitee-side `aw id team fetch-cert`)", teamID, err)
 	}
@@ -1382,8 +1386,6 @@ func acceptTeamInviteWithDetails(workingDir, token string, opts teamAcceptInvite
 	if registryURL == "" {
 		registryURL = strings.TrimSpace(registry.DefaultRegistryURL)
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
 
 	plan, err := resolveTeamMemberEnrollment(ctx, teamMemberEnrollmentResolveOptions{
 		WorkingDir:        workingDir,
@@ -1413,7 +1415,7 @@ func acceptTeamInviteWithDetails(workingDir, token string, opts teamAcceptInvite
 		}
 	}
 
-	cert, err := awid.SignTeamCertificate(teamKey, awid.TeamCertificateFields{
+	cert, err := awid.SignTeamCertificateContext(ctx, teamKey, awid.TeamCertificateFields{
 		Team:          teamID,
 		MemberDIDKey:  plan.MemberDIDKey,
 		MemberDIDAW:   plan.MemberDIDAW,
@@ -1862,14 +1864,14 @@ func revokeAcceptedTeamCertificate(accepted *acceptedTeamInvite) error {
 		registryURL = strings.TrimSpace(registry.DefaultRegistryURL)
 	}
 
-	teamKey, err := awconfig.LoadTeamKey(accepted.Domain, accepted.TeamName)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	teamKey, err := loadTeamSigner(ctx, accepted.Domain, accepted.TeamName)
 	if err != nil {
 		return fmt.Errorf("load team key for %s/%s: %w", accepted.Domain, accepted.TeamName, err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	if err := registry.RevokeCertificate(
 		ctx,
 		registryURL,
@@ -2016,15 +2018,15 @@ func runTeamAddMember(cmd *cobra.Command, args []string) error {
 
 	teamID := awid.BuildTeamID(domain, team)
 
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
 	// Load team key
-	teamKey, err := awconfig.LoadTeamKey(domain, team)
+	teamKey, err := loadTeamSigner(ctx, domain, team)
 	if err != nil {
 		return teamKeyLoadError(teamID, domain, err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	registry, err := newConfiguredRegistryClient(nil, "")
 	if err != nil {
 		return err
@@ -2077,7 +2079,7 @@ func runTeamAddMember(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	cert, err := awid.SignTeamCertificate(teamKey, awid.TeamCertificateFields{
+	cert, err := awid.SignTeamCertificateContext(ctx, teamKey, awid.TeamCertificateFields{
 		Team:          teamID,
 		MemberDIDKey:  memberDID,
 		MemberDIDAW:   memberDIDAW,
@@ -2236,7 +2238,10 @@ func runTeamRemoveMember(cmd *cobra.Command, args []string) error {
 		return runHostedTeamRemoveMember(teamID, member, certificateID)
 	}
 
-	teamKey, err := awconfig.LoadTeamKey(domain, team)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	teamKey, err := loadTeamSigner(ctx, domain, team)
 	if err != nil {
 		return fmt.Errorf("load team key for %s: %w", teamID, err)
 	}
@@ -2248,9 +2253,6 @@ func runTeamRemoveMember(cmd *cobra.Command, args []string) error {
 
 	registryURL := resolveTeamRemoveRegistryURL(registry)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	if certificateID == "" {
 		// The typed namespace is discarded here on purpose-for-now: the alias is
 		// resolved against the TEAM's namespace, because cross-namespace membership
@@ -2392,7 +2394,7 @@ func revokeRegistryTeamCertificate(
 	ctx context.Context,
 	registry *awid.RegistryClient,
 	registryURL, domain, team, certificateID string,
-	teamKey ed25519.PrivateKey,
+	teamKey crypto.Signer,
 ) (certificateStoreResult, error) {
 	result := certificateStoreResult{CertificateID: certificateID}
 	err := registry.RevokeCertificate(ctx, registryURL, domain, team, certificateID, teamKey)
@@ -2622,7 +2624,7 @@ func runTeamImportRequest(cmd *cobra.Command, args []string) error {
 	}
 
 	teamID := awid.BuildTeamID(domain, team)
-	teamKey, err := awconfig.LoadTeamKey(domain, team)
+	teamKey, err := loadTeamSigner(context.Background(), domain, team)
 	if err != nil {
 		return teamKeyLoadError(teamID, domain, err)
 	}
@@ -2665,7 +2667,7 @@ func runTeamRegister(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	teamKey, err := awconfig.LoadTeamKey(domain, teamName)
+	teamKey, err := loadTeamSigner(ctx, domain, teamName)
 	if err != nil {
 		return teamKeyLoadError(teamID, domain, err)
 	}
@@ -2705,7 +2707,7 @@ func runTeamCleanupCloud(cmd *cobra.Command, args []string) error {
 	}
 	teamID := awid.BuildTeamID(domain, team)
 	controllerScope := "team"
-	var controllerKey ed25519.PrivateKey
+	var controllerKey crypto.Signer
 	var err error
 	if teamCleanupCloudNamespaceController {
 		controllerScope = "namespace"
@@ -2714,7 +2716,7 @@ func runTeamCleanupCloud(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("load namespace controller key for %s: %w", domain, err)
 		}
 	} else {
-		controllerKey, err = loadTeamCleanupCloudKey(domain, team, teamCleanupCloudTeamKeyPath)
+		controllerKey, err = loadTeamCleanupCloudKey(ctx, domain, team, teamCleanupCloudTeamKeyPath)
 		if err != nil {
 			return teamKeyLoadError(teamID, domain, err)
 		}
@@ -2729,7 +2731,7 @@ func runTeamCleanupCloud(cmd *cobra.Command, args []string) error {
 
 func executeTeamRegister(
 	ctx context.Context,
-	teamKey ed25519.PrivateKey,
+	teamKey crypto.Signer,
 	awidTeamID string,
 	serviceURL string,
 	dryRun bool,
@@ -2750,7 +2752,7 @@ type teamRegistrationEndpoint struct {
 
 func executeTeamRegisterWithTarget(
 	ctx context.Context,
-	teamKey ed25519.PrivateKey,
+	teamKey crypto.Signer,
 	awidTeamID string,
 	target teamRegistrationEndpoint,
 	dryRun bool,
@@ -2780,7 +2782,11 @@ func executeTeamRegisterWithTarget(
 	if err != nil {
 		return teamRegisterOutput{}, err
 	}
-	signature := base64.RawStdEncoding.EncodeToString(ed25519.Sign(teamKey, []byte(canonical)))
+	sig, err := signWithSigner(teamKey, []byte(canonical))
+	if err != nil {
+		return teamRegisterOutput{}, err
+	}
+	signature := base64.RawStdEncoding.EncodeToString(sig)
 	body := map[string]any{
 		"awid_team_id":         strings.TrimSpace(awidTeamID),
 		"service_url":          strings.TrimSpace(target.ServiceURL),
@@ -2915,7 +2921,7 @@ func newTeamCloudHTTPClient() *http.Client {
 	return &http.Client{Timeout: awid.APITimeout(), Transport: awid.NewAPITransport()}
 }
 
-func verifyTeamRegisterLocalKey(ctx context.Context, teamKey ed25519.PrivateKey, domain, teamName, registryURL string) error {
+func verifyTeamRegisterLocalKey(ctx context.Context, teamKey crypto.Signer, domain, teamName, registryURL string) error {
 	registry, err := newConfiguredRegistryClient(nil, "")
 	if err != nil {
 		return err
@@ -2945,7 +2951,7 @@ func verifyTeamRegisterLocalKey(ctx context.Context, teamKey ed25519.PrivateKey,
 }
 
 func buildTeamImportRequestOutput(
-	teamKey ed25519.PrivateKey,
+	teamKey crypto.Signer,
 	awidTeamID string,
 	organizationID string,
 	cloudTeamID string,
@@ -2965,7 +2971,11 @@ func buildTeamImportRequestOutput(
 		return teamImportRequestOutput{}, err
 	}
 	controllerDID := awid.ComputeDIDKey(teamKey.Public().(ed25519.PublicKey))
-	signature := base64.RawStdEncoding.EncodeToString(ed25519.Sign(teamKey, []byte(canonical)))
+	sig, err := signWithSigner(teamKey, []byte(canonical))
+	if err != nil {
+		return teamImportRequestOutput{}, err
+	}
+	signature := base64.RawStdEncoding.EncodeToString(sig)
 	body := map[string]any{
 		"awid_team_id":         strings.TrimSpace(awidTeamID),
 		"organization_id":      nullableString(strings.TrimSpace(organizationID)),
@@ -2986,20 +2996,20 @@ func buildTeamImportRequestOutput(
 	}, nil
 }
 
-func loadTeamCleanupCloudKey(domain, team, path string) (ed25519.PrivateKey, error) {
+func loadTeamCleanupCloudKey(ctx context.Context, domain, team, path string) (crypto.Signer, error) {
 	if strings.TrimSpace(path) != "" {
 		return awid.LoadSigningKey(strings.TrimSpace(path))
 	}
-	return awconfig.LoadTeamKey(domain, team)
+	return loadTeamSigner(ctx, domain, team)
 }
 
-func loadTeamCleanupCloudNamespaceKey(ctx context.Context, domain, path string) (ed25519.PrivateKey, error) {
-	var key ed25519.PrivateKey
+func loadTeamCleanupCloudNamespaceKey(ctx context.Context, domain, path string) (crypto.Signer, error) {
+	var key crypto.Signer
 	var err error
 	if strings.TrimSpace(path) != "" {
 		key, err = awid.LoadSigningKey(strings.TrimSpace(path))
 	} else {
-		key, err = awconfig.LoadControllerKey(domain)
+		key, err = loadControllerSigner(domain)
 	}
 	if err != nil {
 		return nil, err
@@ -3017,7 +3027,7 @@ func loadTeamCleanupCloudNamespaceKey(ctx context.Context, domain, path string)
 
 func executeTeamCleanupCloud(
 	ctx context.Context,
-	controllerKey ed25519.PrivateKey,
+	controllerKey crypto.Signer,
 	controllerScope string,
 	awidTeamID string,
 	dryRun bool,
@@ -3052,7 +3062,11 @@ func executeTeamCleanupCloud(
 	if err != nil {
 		return teamCleanupCloudOutput{}, err
 	}
-	signature := base64.RawStdEncoding.EncodeToString(ed25519.Sign(controllerKey, []byte(canonical)))
+	sig, err := signWithSigner(controllerKey, []byte(canonical))
+	if err != nil {
+		return teamCleanupCloudOutput{}, err
+	}
+	signature := base64.RawStdEncoding.EncodeToString(sig)
 	body := map[string]any{
 		"awid_team_id":         strings.TrimSpace(awidTeamID),
 		"dry_run":              dryRun,
@@ -3394,7 +3408,7 @@ func ensureLocalTeamRegistered(
 	ctx context.Context,
 	registry *awid.RegistryClient,
 	registryURL, domain, name, displayName string,
-	controllerKey ed25519.PrivateKey,
+	controllerKey crypto.Signer,
 ) (*localTeamRegistration, error) {
 	domain = awconfig.NormalizeDomain(domain)
 	name = strings.ToLower(strings.TrimSpace(name))
@@ -3423,30 +3437,32 @@ func ensureLocalTeamRegistered(
 		return nil, fmt.Errorf("ensure namespace at registry: %w", err)
 	}
 
-	var teamPriv ed25519.PrivateKey
+	var teamKey crypto.Signer
+	var newTeamKey ed25519.PrivateKey
 	exists, err := awconfig.TeamKeyExists(domain, name)
 	if err != nil {
 		return nil, err
 	}
 	if exists {
-		teamPriv, err = awconfig.LoadTeamKey(domain, name)
+		teamKey, err = loadTeamSigner(ctx, domain, name)
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		_, teamPriv, err = awid.GenerateKeypair()
+		_, newTeamKey, err = awid.GenerateKeypair()
 		if err != nil {
 			return nil, err
 		}
+		teamKey = newTeamKey
 	}
-	teamDIDKey := awid.ComputeDIDKey(teamPriv.Public().(ed25519.PublicKey))
+	teamDIDKey := awid.ComputeDIDKey(teamKey.Public().(ed25519.PublicKey))
 
 	_, err = registry.CreateTeam(ctx, registryURL, domain, name, strings.TrimSpace(displayName), teamDIDKey, controllerKey)
 	if err != nil {
 		if code, ok := registryStatusCode(err); !ok || code != http.StatusConflict {
 			return nil, fmt.Errorf("create team at registry: %w", err)
 		}
-		existingTeam, getErr := registry.GetTeam(ctx, registryURL, domain, name, teamPriv)
+		existingTeam, getErr := registry.GetTeam(ctx, registryURL, domain, name, teamKey)
 		if getErr != nil {
 			return nil, fmt.Errorf("create team at registry: %w", err)
 		}
@@ -3456,7 +3472,7 @@ func ensureLocalTeamRegistered(
 	}
 
 	if !exists {
-		if err := awconfig.SaveTeamKey(domain, name, teamPriv); err != nil {
+		if err := awconfig.SaveTeamKey(domain, name, newTeamKey); err != nil {
 			return nil, err
 		}
 	}
@@ -3467,7 +3483,7 @@ func ensureLocalTeamRegistered(
 	return &localTeamRegistration{
 		TeamID:      awid.BuildTeamID(domain, name),
 		TeamDIDKey:  teamDIDKey,
-		TeamKey:     teamPriv,
+		TeamKey:     teamKey,
 		TeamKeyPath: teamKeyPath,
 	}, nil
 }
@@ -3476,7 +3492,8 @@ func bootstrapFirstLocalTeamMember(
 	ctx context.Context,
 	registry *awid.RegistryClient,
 	registryURL, domain, teamName, displayName string,
-	controllerKey, memberKey ed25519.PrivateKey,
+	controllerKey crypto.Signer,
+	memberKey ed25519.PrivateKey,
 	memberDIDAW, memberAddress, alias string,
 ) (*localTeamBootstrapResult, error) {
 	return bootstrapLocalTeamMemberWithScope(
@@ -3499,7 +3516,8 @@ func bootstrapLocalTeamMemberWithScope(
 	ctx context.Context,
 	registry *awid.RegistryClient,
 	registryURL, domain, teamName, displayName string,
-	controllerKey, memberKey ed25519.PrivateKey,
+	controllerKey crypto.Signer,
+	memberKey ed25519.PrivateKey,
 	memberDIDAW, memberAddress, alias, identityScope string,
 ) (*localTeamBootstrapResult, error) {
 	if memberKey == nil {
@@ -3518,7 +3536,7 @@ func bootstrapLocalTeamMemberWithScope(
 		return nil, err
 	}
 	memberDIDKey := awid.ComputeDIDKey(memberKey.Public().(ed25519.PublicKey))
-	cert, err := awid.SignTeamCertificate(registration.TeamKey, awid.TeamCertificateFields{
+	cert, err := awid.SignTeamCertificateContext(ctx, registration.TeamKey, awid.TeamCertificateFields{
 		Team:          registration.TeamID,
 		MemberDIDKey:  memberDIDKey,
 		MemberDIDAW:   strings.TrimSpace(memberDIDAW),
@@ -3687,15 +3705,15 @@ func resolveGlobalIdentityForTeamAccept(workingDir, identityHome string) (*awcon
 	return identity, signingKey, nil
 }
 
-func loadOptionalNamespaceControllerKey(domain string) (ed25519.PrivateKey, bool, error) {
-	exists, err := awconfig.ControllerKeyExists(domain)
+func loadOptionalNamespaceControllerKey(domain string) (crypto.Signer, bool, error) {
+	exists, err := controllerKeyAvailable(domain)
 	if err != nil {
 		return nil, false, err
 	}
 	if !exists {
 		return nil, false, nil
 	}
-	key, err := awconfig.LoadControllerKey(domain)
+	key, err := loadControllerSigner(domain)
 	if err != nil {
 		return nil, false, fmt.Errorf("load namespace controller key for %s: %w", awconfig.NormalizeDomain(domain), err)
 	}
@@ -3706,7 +3724,7 @@ func validateMemberAddressForCertificate(
 	ctx context.Context,
 	registry *awid.RegistryClient,
 	registryURL, memberAddress, memberDIDAW, memberDIDKey string,
-	signingKey ed25519.PrivateKey,
+	signingKey crypto.Signer,
 ) error {
 	memberAddress = strings.TrimSpace(memberAddress)
 	if memberAddress == "" {
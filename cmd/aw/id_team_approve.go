@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/awebai/aw/awid"
+	"github.com/spf13/cobra"
+)
+
+type teamApproveOutput struct {
+	TeamID    string `json:"team_id"`
+	Action    string `json:"action"`
+	Detail    string `json:"detail,omitempty"`
+	Signature string `json:"signature"`
+	Reply     string `json:"reply"`
+}
+
+var teamApproveCmd = &cobra.Command{
+	Use:   "approve <code>",
+	Short: "Protocol/admin: sign an approval for a gated agent action",
+	Long: "Sign an approval for an action a profile lists in approval_required.\n\n" +
+		"A gated command prints a code and waits for the team owner. This command\n" +
+		"signs that one request with the local team controller key\n" +
+		"(~/.awid/team-keys/<namespace>/<team>.key) and prints the reply to send\n" +
+		"back: \"approve <signature>\", typed at the aw run prompt as\n" +
+		"/approve <id> <signature>, at the terminal, or in the approval chat. The\n" +
+		"agent verifies the signature against the team key, so only the holder of\n" +
+		"that key can approve.",
+	Args: cobra.ExactArgs(1),
+	RunE: runTeamApprove,
+}
+
+func init() {
+	teamCmd.AddCommand(teamApproveCmd)
+}
+
+func runTeamApprove(cmd *cobra.Command, args []string) error {
+	req, err := parseOwnerApprovalCode(args[0])
+	if err != nil {
+		return usageError("%v", err)
+	}
+	domain, name, err := awid.ParseTeamID(req.TeamID)
+	if err != nil {
+		return err
+	}
+	teamKey, err := loadTeamSigner(context.Background(), domain, name)
+	if err != nil {
+		return fmt.Errorf("load team controller key for %s: %w", req.TeamID, err)
+	}
+	signature, err := signOwnerApproval(teamKey, req)
+	if err != nil {
+		return err
+	}
+	printOutput(teamApproveOutput{
+		TeamID:    req.TeamID,
+		Action:    req.Action,
+		Detail:    req.Detail,
+		Signature: signature,
+		Reply:     "approve " + signature,
+	}, formatTeamApprove)
+	return nil
+}
+
+func formatTeamApprove(v any) string {
+	out := v.(teamApproveOutput)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Approved %s", out.Action)
+	if out.Detail != "" {
+		fmt.Fprintf(&sb, " %s", out.Detail)
+	}
+	fmt.Fprintf(&sb, " for %s. Reply with:\n%s\n", out.TeamID, out.Reply)
+	return sb.String()
+}
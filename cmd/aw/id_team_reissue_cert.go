@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto"
 	"crypto/ed25519"
 	"fmt"
 	"net/http"
@@ -119,7 +120,10 @@ func runTeamReissueCert(cmd *cobra.Command, args []string) error {
 	}
 	teamID := awid.BuildTeamID(domain, team)
 
-	teamKey, err := loadReissueCertController(domain, team)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	teamKey, err := loadReissueCertController(ctx, domain, team)
 	if err != nil {
 		return err
 	}
@@ -158,9 +162,6 @@ func runTeamReissueCert(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	// The registry enforces one unrevoked certificate per (team, alias), so the
 	// alias's active registration - if any - is both the row to revoke and the
 	// authoritative record of the member facts the fresh certificate must keep.
@@ -227,7 +228,7 @@ func runTeamReissueCert(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	freshCertificate, err := awid.SignTeamCertificate(teamKey, awid.TeamCertificateFields{
+	freshCertificate, err := awid.SignTeamCertificateContext(ctx, teamKey, awid.TeamCertificateFields{
 		Team:          teamID,
 		MemberDIDKey:  memberDID,
 		MemberDIDAW:   memberDIDAW,
@@ -291,7 +292,7 @@ func runTeamReissueCert(cmd *cobra.Command, args []string) error {
 // loadReissueCertController follows the replace-key key-loading path: the
 // operation runs where the team signing key is locally held, and hosted
 // namespaces refuse rather than pretending local custody exists.
-func loadReissueCertController(domain, teamName string) (ed25519.PrivateKey, error) {
+func loadReissueCertController(ctx context.Context, domain, teamName string) (crypto.Signer, error) {
 	if isAwebHostedNamespace(domain) {
 		return nil, usageError("team %s:%s is hosted; reissue-cert cannot use a local team controller key for hosted custody. Hosted certificate re-issuance runs through the hosted service or operator support", teamName, domain)
 	}
@@ -302,7 +303,7 @@ func loadReissueCertController(domain, teamName string) (ed25519.PrivateKey, err
 	if !exists {
 		return nil, usageError("no local team controller key is available for %s:%s; reissue-cert supports local-controller/BYOT teams only. Restore the local team controller key, or use hosted operator support for hosted teams", teamName, domain)
 	}
-	key, err := awconfig.LoadTeamKey(domain, teamName)
+	key, err := loadTeamSigner(ctx, domain, teamName)
 	if err != nil {
 		return nil, fmt.Errorf("load local team controller key: %w", err)
 	}
@@ -343,7 +344,7 @@ func resolveActiveReissueCertMember(
 	ctx context.Context,
 	registry *awid.RegistryClient,
 	registryURL, domain, team, teamID, alias string,
-	teamKey ed25519.PrivateKey,
+	teamKey crypto.Signer,
 ) (*awid.TeamMemberReference, error) {
 	ref, err := registry.ResolveTeamMember(ctx, registryURL, domain, team, alias, teamKey)
 	if err == nil {
@@ -376,7 +377,7 @@ func resolveLatestRevokedReissueCertMember(
 	ctx context.Context,
 	registry *awid.RegistryClient,
 	registryURL, domain, team, teamID, alias, memberDID string,
-	teamKey ed25519.PrivateKey,
+	teamKey crypto.Signer,
 ) (*awid.TeamMemberReference, error) {
 	certificates, err := registry.ListCertificates(
 		ctx, registryURL, domain, team, false, teamKey,
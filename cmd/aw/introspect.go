@@ -25,10 +25,12 @@ type introspectOutput struct {
 	InboundConfigurable *bool  `json:"inbound_configurable,omitempty"`
 	InboundModeError    string `json:"inbound_mode_error,omitempty"`
 
-	GrantID        string   `json:"grant_id,omitempty"`
-	GrantStatus    string   `json:"grant_status,omitempty"`
-	GrantScopes    []string `json:"grant_scopes,omitempty"`
-	GrantExpiresAt string   `json:"grant_expires_at,omitempty"`
+	GrantID               string   `json:"grant_id,omitempty"`
+	GrantStatus           string   `json:"grant_status,omitempty"`
+	GrantScopes           []string `json:"grant_scopes,omitempty"`
+	GrantExpiresAt        string   `json:"grant_expires_at,omitempty"`
+	GrantRemainingSeconds int      `json:"grant_remaining_seconds,omitempty"`
+	GrantRenewsFrom       string   `json:"grant_renews_from,omitempty"`
 }
 
 var introspectCmd = &cobra.Command{
@@ -62,8 +64,10 @@ var introspectCmd = &cobra.Command{
 			if expires, ok := parseTimeBestEffort(grant.ExpiresAt); ok && time.Now().After(expires) {
 				out.GrantStatus = "expired"
 			}
+			out.GrantRemainingSeconds = ttlRemainingSeconds(grant.ExpiresAt, time.Now())
 			out.GrantScopes = grant.Scopes
 			out.GrantExpiresAt = strings.TrimSpace(grant.ExpiresAt)
+			out.GrantRenewsFrom = strings.TrimSpace(grant.ParentHome)
 		}
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
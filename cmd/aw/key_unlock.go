@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/awebai/aw/awid"
+	"golang.org/x/term"
+)
+
+const (
+	// keyPassphraseFileEnv names a file holding the passphrase for encrypted
+	// keys, for unattended hosts that keep it in a secrets mount.
+	keyPassphraseFileEnv = "AW_KEY_PASSPHRASE_FILE"
+
+	maxPassphraseBytes = 4096
+)
+
+func init() {
+	awid.SetKeyPassphraseSource(unlockKeyPassphrase)
+}
+
+// unlockKeyPassphrase finds the passphrase for an encrypted key: the
+// passphrase file, then a terminal prompt.
+func unlockKeyPassphrase(path string) ([]byte, error) {
+	if passphrase, ok, err := passphraseFromEnvFile(); ok || err != nil {
+		return passphrase, err
+	}
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return promptPassphrase(fmt.Sprintf("Passphrase for %s: ", abbreviateUserHome(path)))
+	}
+	return nil, fmt.Errorf("%w; set %s, start `aw agentd`, or run from a terminal", awid.ErrSigningKeyLocked, keyPassphraseFileEnv)
+}
+
+func passphraseFromEnvFile() ([]byte, bool, error) {
+	path := strings.TrimSpace(os.Getenv(keyPassphraseFileEnv))
+	if path == "" {
+		return nil, false, nil
+	}
+	passphrase, err := readPassphraseFile(path)
+	return passphrase, true, err
+}
+
+func readPassphraseFile(path string) ([]byte, error) {
+	data, err := readFileBounded(path, maxPassphraseBytes)
+	if err != nil {
+		return nil, fmt.Errorf("read passphrase file: %w", err)
+	}
+	passphrase := []byte(strings.TrimRight(string(data), "\r\n"))
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase file %s is empty", path)
+	}
+	return passphrase, nil
+}
+
+func promptPassphrase(label string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, label)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("read passphrase: %w", err)
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New("empty passphrase")
+	}
+	return passphrase, nil
+}
@@ -59,6 +59,77 @@ var logCmd = &cobra.Command{
 	},
 }
 
+var (
+	logSearchLimit        int
+	logSearchChannel      string
+	logSearchWith         string
+	logSearchConversation string
+	logSearchVerification string
+	logSearchSince        string
+	logSearchUntil        string
+)
+
+var logSearchCmd = &cobra.Command{
+	Use:   "search [words...]",
+	Short: "Search the local message archive",
+	Long: `Search sent and received mail and chat in the local communication log,
+including decrypted E2EE bodies. Every word must occur in the subject, body,
+participants or IDs of a message; end a word with * to match a prefix.
+Combine with filters for channel, participant, conversation, verification
+status and date range.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		now := time.Now()
+		since, err := parseArchiveTime(logSearchSince, now, false)
+		if err != nil {
+			return usageError("--since: %v", err)
+		}
+		until, err := parseArchiveTime(logSearchUntil, now, true)
+		if err != nil {
+			return usageError("--until: %v", err)
+		}
+		_, sel, err := resolveClientSelection()
+		if err != nil {
+			return err
+		}
+		path := commLogPath(defaultLogsDir(), commLogNameForSelection(sel))
+		entries, err := searchCommLog(path, commLogSearch{
+			Query:        strings.Join(args, " "),
+			Channel:      strings.TrimSpace(logSearchChannel),
+			Participant:  strings.TrimSpace(logSearchWith),
+			Conversation: strings.TrimSpace(logSearchConversation),
+			Verification: strings.TrimSpace(logSearchVerification),
+			Since:        since,
+			Until:        until,
+			Limit:        logSearchLimit,
+		})
+		if err != nil {
+			if os.IsNotExist(err) {
+				fmt.Println("No log entries yet.")
+				return nil
+			}
+			return err
+		}
+
+		if jsonFlag {
+			for _, e := range entries {
+				data, _ := json.Marshal(e)
+				fmt.Println(string(data))
+			}
+			return nil
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No matching log entries.")
+			return nil
+		}
+
+		for _, e := range entries {
+			fmt.Print(formatLogLine(&e))
+		}
+		return nil
+	},
+}
+
 // readCommLog reads JSONL entries from a log file.
 // If limit > 0, returns only the last `limit` entries.
 func readCommLog(path string, limit int) ([]CommLogEntry, error) {
@@ -143,5 +214,14 @@ func init() {
 	logCmd.Flags().StringVar(&logChannel, "channel", "", "Filter by channel (mail, chat, dm)")
 	logCmd.Flags().StringVar(&logFrom, "from", "", "Filter by sender (substring match)")
 
+	logSearchCmd.Flags().IntVar(&logSearchLimit, "limit", 20, "Max entries to show (most recent matches)")
+	logSearchCmd.Flags().StringVar(&logSearchChannel, "channel", "", "Filter by channel (mail, chat, dm)")
+	logSearchCmd.Flags().StringVar(&logSearchWith, "with", "", "Filter by participant name, address or DID (substring match)")
+	logSearchCmd.Flags().StringVar(&logSearchConversation, "conversation", "", "Filter by conversation or chat session ID")
+	logSearchCmd.Flags().StringVar(&logSearchVerification, "verification", "", "Filter by verification status (e.g. verified, failed)")
+	logSearchCmd.Flags().StringVar(&logSearchSince, "since", "", "Only messages at or after this time (RFC 3339, YYYY-MM-DD, or an age like 7d)")
+	logSearchCmd.Flags().StringVar(&logSearchUntil, "until", "", "Only messages before this time; a date includes that whole day")
+	logCmd.AddCommand(logSearchCmd)
+
 	rootCmd.AddCommand(logCmd)
 }
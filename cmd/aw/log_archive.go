@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/awebai/aw/awid"
+)
+
+// The communication log doubles as the message archive: entries carry
+// decrypted bodies, conversation IDs and verification status. A sidecar
+// inverted index maps each word to the byte offsets of the entries that
+// contain it, and is brought up to date from the log's tail before every
+// search.
+
+const (
+	commLogIndexVersion = 1
+	commLogHeadBytes    = 512
+)
+
+type commLogIndex struct {
+	Version      int                `json:"version"`
+	IndexedBytes int64              `json:"indexed_bytes"`
+	Head         string             `json:"head"` // sha256 of the log's first bytes, to spot a rewritten log
+	Postings     map[string][]int64 `json:"postings"`
+}
+
+// commLogIndexPath returns the index file kept next to a JSONL log.
+func commLogIndexPath(logPath string) string {
+	return strings.TrimSuffix(logPath, ".jsonl") + ".index.json"
+}
+
+// commLogSearch selects archive entries. Every query word must occur in
+// the entry; a word ending in * matches as a prefix.
+type commLogSearch struct {
+	Query        string
+	Channel      string
+	Participant  string
+	Conversation string
+	Verification string
+	Since        time.Time
+	Until        time.Time
+	Limit        int
+}
+
+// searchCommLog returns the matching entries of the log at path, oldest
+// first, keeping the most recent Limit of them.
+func searchCommLog(path string, search commLogSearch) ([]CommLogEntry, error) {
+	index, err := updateCommLogIndex(path)
+	if err != nil {
+		return nil, err
+	}
+	offsets, all := index.candidates(tokenizeArchiveText(search.Query))
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var matches []CommLogEntry
+	visit := func(e CommLogEntry) {
+		if search.matches(e) {
+			matches = append(matches, e)
+		}
+	}
+	if all {
+		if _, err := scanCommLog(f, 0, index.IndexedBytes, func(_ int64, e CommLogEntry) { visit(e) }); err != nil {
+			return nil, err
+		}
+	} else {
+		reader := bufio.NewReader(f)
+		for _, offset := range offsets {
+			if _, err := f.Seek(offset, io.SeekStart); err != nil {
+				return nil, err
+			}
+			reader.Reset(f)
+			line, err := reader.ReadBytes('\n')
+			if err != nil && !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			var e CommLogEntry
+			if json.Unmarshal(line, &e) == nil {
+				visit(e)
+			}
+		}
+	}
+	if search.Limit > 0 && len(matches) > search.Limit {
+		matches = matches[len(matches)-search.Limit:]
+	}
+	return matches, nil
+}
+
+func (s commLogSearch) matches(e CommLogEntry) bool {
+	if s.Channel != "" && e.Channel != s.Channel {
+		return false
+	}
+	if s.Conversation != "" && e.ConversationID != s.Conversation && e.SessionID != s.Conversation {
+		return false
+	}
+	if s.Verification != "" && !strings.EqualFold(e.Verification, s.Verification) {
+		return false
+	}
+	if s.Participant != "" {
+		want := strings.ToLower(s.Participant)
+		found := false
+		for _, field := range []string{e.From, e.To, e.FromDID, e.ToDID, e.FromStableID, e.ToStableID} {
+			if strings.Contains(strings.ToLower(field), want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !s.Since.IsZero() || !s.Until.IsZero() {
+		ts, ok := parseTimeBestEffort(e.Timestamp)
+		if !ok || (!s.Since.IsZero() && ts.Before(s.Since)) || (!s.Until.IsZero() && !ts.Before(s.Until)) {
+			return false
+		}
+	}
+	return true
+}
+
+// candidates returns the offsets of entries containing every term, in log
+// order, or all=true when there are no terms to narrow by.
+func (idx *commLogIndex) candidates(terms []string) (offsets []int64, all bool) {
+	if len(terms) == 0 {
+		return nil, true
+	}
+	var result map[int64]bool
+	for _, term := range terms {
+		hits := map[int64]bool{}
+		if prefix, ok := strings.CutSuffix(term, "*"); ok {
+			for word, postings := range idx.Postings {
+				if strings.HasPrefix(word, prefix) {
+					for _, offset := range postings {
+						hits[offset] = true
+					}
+				}
+			}
+		} else {
+			for _, offset := range idx.Postings[term] {
+				hits[offset] = true
+			}
+		}
+		if result == nil {
+			result = hits
+		} else {
+			for offset := range result {
+				if !hits[offset] {
+					delete(result, offset)
+				}
+			}
+		}
+		if len(result) == 0 {
+			return nil, false
+		}
+	}
+	for offset := range result {
+		offsets = append(offsets, offset)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return offsets, false
+}
+
+// updateCommLogIndex loads the index for the log at path and indexes the
+// entries appended since it was last saved. A log that shrank or whose
+// first bytes changed was rewritten, so its index is rebuilt from scratch.
+func updateCommLogIndex(path string) (*commLogIndex, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	indexPath := commLogIndexPath(path)
+	index := &commLogIndex{}
+	if data, err := os.ReadFile(indexPath); err == nil {
+		if json.Unmarshal(data, index) != nil {
+			index = &commLogIndex{}
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	head, err := commLogHead(f)
+	if err != nil {
+		return nil, err
+	}
+	if index.Version != commLogIndexVersion || index.IndexedBytes > info.Size() || index.Postings == nil ||
+		(index.IndexedBytes > 0 && index.Head != head) {
+		index = &commLogIndex{Version: commLogIndexVersion, Postings: map[string][]int64{}}
+	}
+	if index.IndexedBytes == info.Size() {
+		return index, nil
+	}
+	// Only complete lines are indexed; a line still being appended is
+	// picked up by the next search.
+	end, err := scanCommLog(f, index.IndexedBytes, info.Size(), func(offset int64, e CommLogEntry) {
+		for _, word := range commLogEntryWords(e) {
+			index.Postings[word] = append(index.Postings[word], offset)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	index.IndexedBytes = end
+	if index.Head, err = commLogHead(f); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return nil, err
+	}
+	if err := awid.AtomicWriteFile(indexPath, data); err != nil {
+		debugLog("commlog index: write %s: %v", indexPath, err)
+	}
+	return index, nil
+}
+
+// commLogHead fingerprints the first bytes of a log. Appends change it
+// only while the log is shorter than commLogHeadBytes.
+func commLogHead(f *os.File) (string, error) {
+	buf := make([]byte, commLogHeadBytes)
+	n, err := f.ReadAt(buf, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	sum := sha256.Sum256(buf[:n])
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// scanCommLog calls fn with the offset and entry of each complete line of
+// f between start and end, skipping malformed lines, and returns the offset
+// just past the last complete line.
+func scanCommLog(f *os.File, start, end int64, fn func(int64, CommLogEntry)) (int64, error) {
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		return start, err
+	}
+	reader := bufio.NewReader(io.LimitReader(f, end-start))
+	offset := start
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return offset, nil
+			}
+			return offset, err
+		}
+		var e CommLogEntry
+		if strings.TrimSpace(string(line)) != "" && json.Unmarshal(line, &e) == nil {
+			fn(offset, e)
+		}
+		offset += int64(len(line))
+	}
+}
+
+// commLogEntryWords returns the distinct searchable words of an entry.
+func commLogEntryWords(e CommLogEntry) []string {
+	seen := map[string]bool{}
+	var words []string
+	for _, field := range []string{
+		e.Subject, e.Body, e.From, e.To, e.FromDID, e.ToDID, e.FromStableID, e.ToStableID,
+		e.MessageID, e.ConversationID, e.SessionID,
+	} {
+		for _, word := range tokenizeArchiveText(field) {
+			if !seen[word] {
+				seen[word] = true
+				words = append(words, word)
+			}
+		}
+	}
+	return words
+}
+
+// tokenizeArchiveText lowercases text and splits it into words of letters
+// and digits, keeping a trailing * on a word for prefix queries.
+func tokenizeArchiveText(text string) []string {
+	var words []string
+	var word strings.Builder
+	flush := func(prefix bool) {
+		if word.Len() > 0 {
+			w := word.String()
+			if prefix {
+				w += "*"
+			}
+			words = append(words, w)
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		case r == '*':
+			flush(true)
+		default:
+			flush(false)
+		}
+	}
+	flush(false)
+	return words
+}
+
+// parseArchiveTime accepts RFC 3339, a date (YYYY-MM-DD, local time), or
+// an age such as 36h or 7d measured back from now. With endOfDay, a date
+// means the end of that day, so --until includes it.
+func parseArchiveTime(value string, now time.Time, endOfDay bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if ts, err := time.Parse(time.RFC3339, value); err == nil {
+		return ts, nil
+	}
+	if ts, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if endOfDay {
+			ts = ts.AddDate(0, 0, 1)
+		}
+		return ts, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if age, err := time.ParseDuration(value); err == nil && age >= 0 {
+		return now.Add(-age), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use RFC 3339, YYYY-MM-DD, or an age like 36h or 7d)", value)
+}
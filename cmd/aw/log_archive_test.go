@@ -0,0 +1,150 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func writeArchiveFixture(t *testing.T) (string, string) {
+	t.Helper()
+	logsDir := t.TempDir()
+	for _, e := range []*CommLogEntry{
+		{Timestamp: "2026-03-01T09:00:00Z", Dir: "recv", Channel: "mail", MessageID: "m1", ConversationID: "conv-a", From: "demo/rose", To: "demo/eve", Subject: "Deploy plan", Body: "We decided to deploy on Friday.", Verification: "verified"},
+		{Timestamp: "2026-03-02T09:00:00Z", Dir: "send", Channel: "chat", MessageID: "m2", SessionID: "sess-b", From: "demo/eve", To: "demo/bob", Body: "Deployment moved to Monday", FromDID: "did:key:z6MkEve"},
+		{Timestamp: "2026-03-03T09:00:00Z", Dir: "recv", Channel: "mail", MessageID: "m3", ConversationID: "conv-a", From: "demo/rose", To: "demo/eve", Subject: "Re: Deploy plan", Body: "Friday it is, décidé.", Verification: "failed"},
+	} {
+		appendCommLog(logsDir, "acct", e)
+	}
+	return logsDir, commLogPath(logsDir, "acct")
+}
+
+func archiveMessageIDs(entries []CommLogEntry) []string {
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.MessageID)
+	}
+	return ids
+}
+
+func TestSearchCommLog(t *testing.T) {
+	t.Parallel()
+
+	_, path := writeArchiveFixture(t)
+	for name, tc := range map[string]struct {
+		search commLogSearch
+		want   []string
+	}{
+		"all words":       {commLogSearch{Query: "deploy friday"}, []string{"m1", "m3"}},
+		"case and accent": {commLogSearch{Query: "DÉCIDÉ"}, []string{"m3"}},
+		"prefix":          {commLogSearch{Query: "deploy*"}, []string{"m1", "m2", "m3"}},
+		"no match":        {commLogSearch{Query: "tuesday"}, nil},
+		"channel":         {commLogSearch{Query: "deploy*", Channel: "chat"}, []string{"m2"}},
+		"participant":     {commLogSearch{Participant: "z6mkeve"}, []string{"m2"}},
+		"conversation":    {commLogSearch{Conversation: "conv-a"}, []string{"m1", "m3"}},
+		"session":         {commLogSearch{Conversation: "sess-b"}, []string{"m2"}},
+		"verification":    {commLogSearch{Verification: "verified"}, []string{"m1"}},
+		"date range": {commLogSearch{
+			Since: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+			Until: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC),
+		}, []string{"m2"}},
+		"limit keeps latest": {commLogSearch{Query: "deploy*", Limit: 2}, []string{"m2", "m3"}},
+	} {
+		entries, err := searchCommLog(path, tc.search)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := archiveMessageIDs(entries); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", name, got, tc.want)
+		}
+	}
+}
+
+func TestSearchCommLogIndexesAppendedEntries(t *testing.T) {
+	t.Parallel()
+
+	logsDir, path := writeArchiveFixture(t)
+	if _, err := searchCommLog(path, commLogSearch{Query: "friday"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(commLogIndexPath(path)); err != nil {
+		t.Fatalf("index not written: %v", err)
+	}
+
+	appendCommLog(logsDir, "acct", &CommLogEntry{Timestamp: "2026-03-04T09:00:00Z", Channel: "mail", MessageID: "m4", Body: "Friday rollback drill"})
+	// A partially written line is left for the next search.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"ts":"2026-03-05T09:00:00Z","msg_id":"m5","body":"friday`)
+	_ = f.Close()
+
+	entries, err := searchCommLog(path, commLogSearch{Query: "friday"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := archiveMessageIDs(entries); !slices.Equal(got, []string{"m1", "m3", "m4"}) {
+		t.Fatalf("got %v", got)
+	}
+
+	// A rewritten, shorter log rebuilds the index.
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	appendCommLog(logsDir, "acct", &CommLogEntry{Timestamp: "2026-03-06T09:00:00Z", Channel: "chat", MessageID: "m6", Body: "fresh start"})
+	entries, err = searchCommLog(path, commLogSearch{Query: "fresh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := archiveMessageIDs(entries); !slices.Equal(got, []string{"m6"}) {
+		t.Fatalf("after rewrite got %v", got)
+	}
+
+	// So does a log rewritten to a larger size with different content.
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		appendCommLog(logsDir, "acct", &CommLogEntry{Timestamp: "2026-03-07T09:00:00Z", Channel: "chat", MessageID: "r" + strconv.Itoa(i), Body: "replacement history"})
+	}
+	entries, err = searchCommLog(path, commLogSearch{Query: "fresh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("stale index matched %v", archiveMessageIDs(entries))
+	}
+	if filepath.Dir(commLogIndexPath(path)) != logsDir {
+		t.Fatalf("index path %s outside logs dir", commLogIndexPath(path))
+	}
+}
+
+func TestParseArchiveTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	for _, tc := range []struct {
+		value    string
+		endOfDay bool
+		want     time.Time
+	}{
+		{"2026-03-01T08:00:00Z", false, time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)},
+		{"2026-03-01", false, day},
+		{"2026-03-01", true, day.AddDate(0, 0, 1)},
+		{"7d", false, now.AddDate(0, 0, -7)},
+		{"36h", false, now.Add(-36 * time.Hour)},
+	} {
+		got, err := parseArchiveTime(tc.value, now, tc.endOfDay)
+		if err != nil || !got.Equal(tc.want) {
+			t.Errorf("parseArchiveTime(%q, %v)=%v, %v; want %v", tc.value, tc.endOfDay, got, err, tc.want)
+		}
+	}
+	if _, err := parseArchiveTime("last tuesday", now, false); err == nil {
+		t.Fatal("invalid time accepted")
+	}
+}
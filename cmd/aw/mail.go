@@ -487,6 +487,8 @@ var mailSendCmd = &cobra.Command{
 				return err
 			}
 		}
+		wd, _ := os.Getwd()
+		attachOutbox(c.Client, wd)
 		if targetKind == "alias" {
 			resp, err = c.SendMessage(ctx, req)
 		} else {
@@ -528,6 +530,8 @@ var mailSendCmd = &cobra.Command{
 		})
 		if jsonFlag {
 			printJSON(resp)
+		} else if resp.Status == awid.MessageStatusQueued {
+			fmt.Printf("Server unreachable; queued mail to %s (message_id=%s). aw run or `aw outbox flush` delivers it.\n", targetValue, resp.MessageID)
 		} else if targetKind == "conversation" {
 			fmt.Printf("Sent mail in conversation %s (message_id=%s)\n", targetValue, resp.MessageID)
 			fmt.Print(mailSendBoundaryNotice())
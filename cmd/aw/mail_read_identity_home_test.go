@@ -76,7 +76,7 @@ func TestMailReadsDecryptWithSelectedPrincipalIdentityHome(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	envelope, err := awid.EncryptE2EEMail(awid.E2EEEncryptMailParams{
+	envelope, err := awid.EncryptE2EEMail(context.Background(), awid.E2EEEncryptMailParams{
 		Sender: awid.E2EESenderKey{
 			DID:           remoteDID,
 			EncryptionKey: remoteAssertion,
@@ -10,18 +10,25 @@ import (
 
 var (
 	mcpConfigChannel bool
+	mcpConfigStdio   bool
 )
 
 var mcpConfigCmd = &cobra.Command{
 	Use:   "mcp-config",
 	Short: "Output MCP server configuration for the current identity",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if mcpConfigChannel {
+		if mcpConfigChannel && mcpConfigStdio {
+			return usageError("--channel and --stdio are mutually exclusive")
+		}
+		if mcpConfigChannel || mcpConfigStdio {
 			wd, err := os.Getwd()
 			if err != nil {
 				return fmt.Errorf("get working directory: %w", err)
 			}
 			cfg := channelMCPConfig(wd)
+			if mcpConfigStdio {
+				cfg = stdioMCPConfig(wd)
+			}
 			out, err := json.MarshalIndent(cfg, "", "  ")
 			if err != nil {
 				return fmt.Errorf("marshal config: %w", err)
@@ -30,7 +37,7 @@ var mcpConfigCmd = &cobra.Command{
 			return nil
 		}
 
-		return usageError("HTTP MCP config is not emitted by this command because /mcp now requires per-request DIDKey signatures plus a team certificate; use `aw mcp-config --channel`")
+		return usageError("HTTP MCP config is not emitted by this command because /mcp now requires per-request DIDKey signatures plus a team certificate; use `aw mcp-config --channel` or `aw mcp-config --stdio`")
 	},
 }
 
@@ -46,7 +53,22 @@ func channelMCPConfig(cwd string) map[string]any {
 	}
 }
 
+// stdioMCPConfig points the client at `aw mcp serve`, which signs requests
+// itself and needs no Node runtime.
+func stdioMCPConfig(cwd string) map[string]any {
+	return map[string]any{
+		"mcpServers": map[string]any{
+			"aweb": map[string]any{
+				"command": "aw",
+				"args":    []string{"mcp", "serve"},
+				"cwd":     cwd,
+			},
+		},
+	}
+}
+
 func init() {
 	mcpConfigCmd.Flags().BoolVar(&mcpConfigChannel, "channel", false, "Output stdio channel config instead of HTTP MCP config")
+	mcpConfigCmd.Flags().BoolVar(&mcpConfigStdio, "stdio", false, "Output config for the native `aw mcp serve` server")
 	rootCmd.AddCommand(mcpConfigCmd)
 }
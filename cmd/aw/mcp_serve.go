@@ -0,0 +1,508 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/awebai/aw/internal/appmanifest"
+	"github.com/awebai/aw/internal/mcpserver"
+	"github.com/spf13/cobra"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Serve aw coordination to MCP clients",
+}
+
+var mcpServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a stdio MCP server for this workspace",
+	Long: `Run a Model Context Protocol server on stdin/stdout for the workspace in
+the current directory.
+
+It exposes chat, mail, tasks, locks and work ready as tools, plus every tool
+of each installed manifest plugin. Requests are signed with the workspace's
+team certificate, exactly as the matching aw commands sign them.
+
+Print a client config for it with ` + "`aw mcp-config --stdio`" + `.`,
+	Args: cobra.NoArgs,
+	RunE: runMCPServe,
+}
+
+// mcpRunAw runs one aw command for a tool call and returns its stdout.
+// Tests replace it to avoid spawning the binary.
+var mcpRunAw = runAwSubprocess
+
+func init() {
+	bindTeamSelector(mcpCmd)
+	mcpCmd.AddCommand(mcpServeCmd)
+	rootCmd.AddCommand(mcpCmd)
+}
+
+func runMCPServe(cmd *cobra.Command, args []string) error {
+	server, err := newAwMCPServer()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err = server.Serve(ctx, cmd.InOrStdin(), cmd.OutOrStdout())
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
+
+func newAwMCPServer() (*mcpserver.Server, error) {
+	server := mcpserver.New("aw", version)
+	server.Instructions = "Tools for coordinating with your aweb team: chat, mail, tasks, locks and ready work. Results are the JSON output of the matching aw command."
+	team := strings.TrimSpace(teamFlag)
+	for _, builtin := range mcpBuiltinTools() {
+		err := server.AddTool(mcpserver.Tool{
+			Name:        builtin.name,
+			Description: builtin.description,
+			InputSchema: builtin.schema,
+			Handler: func(ctx context.Context, args map[string]any) (*mcpserver.ToolResult, error) {
+				argv, err := builtin.argv(args)
+				if err != nil {
+					return nil, err
+				}
+				stdout, stderr, err := mcpRunAw(ctx, mcpCommandArgs(argv, team))
+				if err != nil {
+					if msg := strings.TrimSpace(string(stderr)); msg != "" {
+						return nil, fmt.Errorf("%s", msg)
+					}
+					return nil, err
+				}
+				return mcpserver.TextResult(string(stdout)), nil
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := addManifestPluginMCPTools(server); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// mcpCommandArgs adds --json and the --team override. The group-level
+// flag has to follow the group name.
+func mcpCommandArgs(argv []string, team string) []string {
+	out := []string{"--json", argv[0]}
+	if team != "" {
+		out = append(out, "--team", team)
+	}
+	return append(out, argv[1:]...)
+}
+
+func runAwSubprocess(ctx context.Context, argv []string) ([]byte, []byte, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, exe, argv...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = setEnvValue(os.Environ(), "AW_NO_UPDATE_CHECK", "1")
+	err = cmd.Run()
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+type mcpBuiltinTool struct {
+	name        string
+	description string
+	schema      map[string]any
+	argv        func(args map[string]any) ([]string, error)
+}
+
+func mcpBuiltinTools() []mcpBuiltinTool {
+	return []mcpBuiltinTool{
+		{
+			name:        "chat_send",
+			description: "Send a chat message and wait for the reply, or send and leave the conversation.",
+			schema: mcpObjectSchema([]string{"to", "message"}, map[string]any{
+				"to":                 mcpProp("string", "Recipient name or address"),
+				"message":            mcpProp("string", "Message text"),
+				"wait":               mcpProp("integer", "Seconds to wait for a reply (default 120)"),
+				"start_conversation": mcpProp("boolean", "Start a new conversation"),
+				"leave":              mcpProp("boolean", "Leave the conversation instead of waiting for a reply"),
+			}),
+			argv: func(args map[string]any) ([]string, error) {
+				to, message, err := mcpRequired2(args, "to", "message")
+				if err != nil {
+					return nil, err
+				}
+				argv := []string{"chat", "send-and-wait"}
+				if mcpBool(args, "leave") {
+					argv = []string{"chat", "send-and-leave"}
+				} else if wait, ok, err := mcpInt(args, "wait"); err != nil {
+					return nil, err
+				} else if ok {
+					argv = append(argv, "--wait", strconv.Itoa(wait))
+				}
+				if mcpBool(args, "start_conversation") {
+					argv = append(argv, "--start-conversation")
+				}
+				return append(argv, "--", to, message), nil
+			},
+		},
+		{
+			name:        "chat_wait",
+			description: "Wait for a chat message from someone without sending.",
+			schema: mcpObjectSchema([]string{"from"}, map[string]any{
+				"from": mcpProp("string", "Sender name or address"),
+				"wait": mcpProp("integer", "Seconds to wait (default 120, 0 returns immediately)"),
+			}),
+			argv: func(args map[string]any) ([]string, error) {
+				from, err := mcpRequired(args, "from")
+				if err != nil {
+					return nil, err
+				}
+				argv := []string{"chat", "listen"}
+				if wait, ok, err := mcpInt(args, "wait"); err != nil {
+					return nil, err
+				} else if ok {
+					argv = append(argv, "--wait", strconv.Itoa(wait))
+				}
+				return append(argv, "--", from), nil
+			},
+		},
+		{
+			name:        "mail_send",
+			description: "Send mail to another agent.",
+			schema: mcpObjectSchema([]string{"to", "body"}, map[string]any{
+				"to":              mcpProp("string", "Recipient name within the team, or a routable address"),
+				"body":            mcpProp("string", "Message body"),
+				"subject":         mcpProp("string", "Subject"),
+				"priority":        mcpProp("string", "low, normal, high or urgent"),
+				"conversation_id": mcpProp("string", "Existing mail conversation to continue"),
+			}),
+			argv: func(args map[string]any) ([]string, error) {
+				to, body, err := mcpRequired2(args, "to", "body")
+				if err != nil {
+					return nil, err
+				}
+				argv := []string{"mail", "send", "--to=" + to, "--body=" + body}
+				return mcpAppendFlags(argv, args, "subject", "priority", "conversation_id"), nil
+			},
+		},
+		{
+			name:        "mail_inbox",
+			description: "List inbox messages, unread only by default. Listed messages are acknowledged.",
+			schema: mcpObjectSchema(nil, map[string]any{
+				"show_all": mcpProp("boolean", "Include already-read messages"),
+				"limit":    mcpProp("integer", "Max messages per page"),
+				"cursor":   mcpProp("string", "next_cursor from a previous page"),
+			}),
+			argv: func(args map[string]any) ([]string, error) {
+				argv := []string{"mail", "inbox"}
+				if mcpBool(args, "show_all") {
+					argv = append(argv, "--show-all")
+				}
+				if limit, ok, err := mcpInt(args, "limit"); err != nil {
+					return nil, err
+				} else if ok {
+					argv = append(argv, "--limit", strconv.Itoa(limit))
+				}
+				return mcpAppendFlags(argv, args, "cursor"), nil
+			},
+		},
+		{
+			name:        "mail_ack",
+			description: "Acknowledge one mail message as read.",
+			schema: mcpObjectSchema([]string{"message_id"}, map[string]any{
+				"message_id": mcpProp("string", "Message id"),
+			}),
+			argv: func(args map[string]any) ([]string, error) {
+				id, err := mcpRequired(args, "message_id")
+				if err != nil {
+					return nil, err
+				}
+				return []string{"mail", "ack", "--", id}, nil
+			},
+		},
+		{
+			name:        "task_create",
+			description: "Create a task.",
+			schema:      mcpObjectSchema([]string{"title"}, mcpTaskFieldProps(true)),
+			argv: func(args map[string]any) ([]string, error) {
+				title, err := mcpRequired(args, "title")
+				if err != nil {
+					return nil, err
+				}
+				argv := []string{"task", "create", "--title=" + title}
+				return mcpAppendFlags(argv, args, "description", "notes", "type", "priority", "labels", "assignee", "parent"), nil
+			},
+		},
+		{
+			name:        "task_list",
+			description: "List tasks, optionally filtered.",
+			schema: mcpObjectSchema(nil, map[string]any{
+				"status":   mcpProp("string", "open, in_progress, closed or blocked"),
+				"type":     mcpProp("string", "task, bug, feature or epic"),
+				"priority": mcpProp("string", "0-4 or P0-P4"),
+				"labels":   mcpProp("string", "Comma-separated labels"),
+				"assignee": mcpProp("string", "Assignee agent name"),
+				"parent":   mcpProp("string", "Parent task ref"),
+			}),
+			argv: func(args map[string]any) ([]string, error) {
+				return mcpAppendFlags([]string{"task", "list"}, args, "status", "type", "priority", "labels", "assignee", "parent"), nil
+			},
+		},
+		{
+			name:        "task_update",
+			description: "Update fields of a task.",
+			schema: mcpObjectSchema([]string{"ref"}, mcpMergeProps(mcpTaskFieldProps(false), map[string]any{
+				"ref":    mcpProp("string", "Task ref"),
+				"status": mcpProp("string", "open, in_progress or closed"),
+			})),
+			argv: func(args map[string]any) ([]string, error) {
+				ref, err := mcpRequired(args, "ref")
+				if err != nil {
+					return nil, err
+				}
+				argv := mcpAppendFlags([]string{"task", "update"}, args, "status", "title", "description", "notes", "type", "priority", "labels", "assignee", "parent")
+				return append(argv, "--", ref), nil
+			},
+		},
+		{
+			name:        "task_claim",
+			description: "Claim a task for this workspace and branch, moving it to in_progress.",
+			schema: mcpObjectSchema([]string{"ref"}, map[string]any{
+				"ref":           mcpProp("string", "Task ref"),
+				"reclaim_after": mcpProp("string", "Take over a claim whose owner has not been seen for this long (e.g. 30m)"),
+			}),
+			argv: func(args map[string]any) ([]string, error) {
+				ref, err := mcpRequired(args, "ref")
+				if err != nil {
+					return nil, err
+				}
+				argv := mcpAppendFlags([]string{"task", "claim"}, args, "reclaim_after")
+				return append(argv, "--", ref), nil
+			},
+		},
+		{
+			name:        "lock_acquire",
+			description: "Acquire a lock on a resource key.",
+			schema: mcpObjectSchema([]string{"resource_key"}, map[string]any{
+				"resource_key": mcpProp("string", "Opaque resource key"),
+				"ttl_seconds":  mcpProp("integer", "Lock duration in seconds (default 3600)"),
+			}),
+			argv: func(args map[string]any) ([]string, error) {
+				key, err := mcpRequired(args, "resource_key")
+				if err != nil {
+					return nil, err
+				}
+				argv := []string{"lock", "acquire", "--resource-key=" + key}
+				if ttl, ok, err := mcpInt(args, "ttl_seconds"); err != nil {
+					return nil, err
+				} else if ok {
+					argv = append(argv, "--ttl-seconds", strconv.Itoa(ttl))
+				}
+				return argv, nil
+			},
+		},
+		{
+			name:        "lock_release",
+			description: "Release a lock held by this workspace.",
+			schema: mcpObjectSchema([]string{"resource_key"}, map[string]any{
+				"resource_key": mcpProp("string", "Opaque resource key"),
+			}),
+			argv: func(args map[string]any) ([]string, error) {
+				key, err := mcpRequired(args, "resource_key")
+				if err != nil {
+					return nil, err
+				}
+				return []string{"lock", "release", "--resource-key=" + key}, nil
+			},
+		},
+		{
+			name:        "work_ready",
+			description: "List ready tasks nobody has claimed.",
+			schema:      mcpObjectSchema(nil, map[string]any{}),
+			argv: func(args map[string]any) ([]string, error) {
+				return []string{"work", "ready"}, nil
+			},
+		},
+	}
+}
+
+func mcpTaskFieldProps(forCreate bool) map[string]any {
+	props := map[string]any{
+		"title":       mcpProp("string", "Title"),
+		"description": mcpProp("string", "Description"),
+		"notes":       mcpProp("string", "Notes"),
+		"type":        mcpProp("string", "task, bug, feature or epic"),
+		"priority":    mcpProp("string", "0-4 or P0-P4"),
+		"labels":      mcpProp("string", "Comma-separated labels"),
+		"assignee":    mcpProp("string", "Assignee agent name"),
+		"parent":      mcpProp("string", "Parent task ref"),
+	}
+	if !forCreate {
+		props["assignee"] = mcpProp("string", "Assignee agent name (empty to unassign)")
+		props["parent"] = mcpProp("string", "Parent task ref (empty to make root)")
+	}
+	return props
+}
+
+func mcpObjectSchema(required []string, properties map[string]any) map[string]any {
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func mcpProp(typ, description string) map[string]any {
+	return map[string]any{"type": typ, "description": description}
+}
+
+func mcpMergeProps(base, extra map[string]any) map[string]any {
+	for key, value := range extra {
+		base[key] = value
+	}
+	return base
+}
+
+func mcpRequired(args map[string]any, key string) (string, error) {
+	value, _ := args[key].(string)
+	if strings.TrimSpace(value) == "" {
+		return "", fmt.Errorf("%s is required", key)
+	}
+	return value, nil
+}
+
+func mcpRequired2(args map[string]any, first, second string) (string, string, error) {
+	a, err := mcpRequired(args, first)
+	if err != nil {
+		return "", "", err
+	}
+	b, err := mcpRequired(args, second)
+	if err != nil {
+		return "", "", err
+	}
+	return a, b, nil
+}
+
+func mcpBool(args map[string]any, key string) bool {
+	value, _ := args[key].(bool)
+	return value
+}
+
+func mcpInt(args map[string]any, key string) (int, bool, error) {
+	switch value := args[key].(type) {
+	case nil:
+		return 0, false, nil
+	case float64:
+		if value != float64(int(value)) {
+			return 0, false, fmt.Errorf("%s must be an integer", key)
+		}
+		return int(value), true, nil
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return 0, false, fmt.Errorf("%s must be an integer", key)
+		}
+		return n, true, nil
+	default:
+		return 0, false, fmt.Errorf("%s must be an integer", key)
+	}
+}
+
+// mcpAppendFlags adds --key value for each present string argument. Keys
+// use underscores; flags use dashes. Present-but-empty values are kept so
+// task_update can clear the assignee or parent.
+func mcpAppendFlags(argv []string, args map[string]any, keys ...string) []string {
+	for _, key := range keys {
+		raw, ok := args[key]
+		if !ok || raw == nil {
+			continue
+		}
+		value := fmt.Sprint(raw)
+		if value == "" && key != "assignee" && key != "parent" {
+			continue
+		}
+		argv = append(argv, "--"+strings.ReplaceAll(key, "_", "-")+"="+value)
+	}
+	return argv
+}
+
+var mcpToolNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+func mcpPluginToolName(plugin, verb string) string {
+	return mcpToolNameUnsafe.ReplaceAllString(plugin+"_"+verb, "_")
+}
+
+// addManifestPluginMCPTools exposes every tool of every installed manifest
+// plugin, using the manifest's input schema as the MCP input schema.
+func addManifestPluginMCPTools(server *mcpserver.Server) error {
+	plugins, err := installedPlugins()
+	if err != nil {
+		return err
+	}
+	dir, err := pluginDir()
+	if err != nil {
+		return err
+	}
+	for _, plugin := range plugins {
+		if plugin.Kind != "manifest" {
+			continue
+		}
+		manifest, exists, err := loadInstalledManifest(dir, plugin.Name)
+		if !exists {
+			continue
+		}
+		if err != nil {
+			// One broken plugin should not take the other tools down.
+			debugLog("skip plugin %s: %v", plugin.Name, err)
+			continue
+		}
+		for _, tool := range manifest.Tools {
+			if err := server.AddTool(manifestPluginMCPTool(plugin.Name, manifest, tool)); err != nil {
+				debugLog("skip plugin tool %s %s: %v", plugin.Name, tool.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func manifestPluginMCPTool(plugin string, manifest appmanifest.Manifest, tool appmanifest.Tool) mcpserver.Tool {
+	verb := strings.TrimSpace(tool.Name)
+	schema := tool.InputSchema
+	if schema == nil {
+		schema = map[string]any{"type": "object"}
+	}
+	description := strings.TrimSpace(tool.Description)
+	if description == "" {
+		description = fmt.Sprintf("%s %s", plugin, verb)
+	}
+	return mcpserver.Tool{
+		Name:        mcpPluginToolName(plugin, verb),
+		Description: description,
+		InputSchema: schema,
+		Handler: func(ctx context.Context, args map[string]any) (*mcpserver.ToolResult, error) {
+			if err := validateManifestDispatchArgs(plugin, &tool, args); err != nil {
+				return nil, err
+			}
+			result, err := invokeInstalledManifestTool(plugin, manifest, verb, args, nil)
+			if err != nil {
+				return nil, err
+			}
+			if result.Status >= 400 {
+				return mcpserver.ErrorResult(fmt.Sprintf("HTTP %d: %s", result.Status, strings.TrimSpace(string(result.Body)))), nil
+			}
+			return mcpserver.TextResult(string(result.Body)), nil
+		},
+	}
+}
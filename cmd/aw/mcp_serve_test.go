@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func callMCPToolForTest(t *testing.T, name string, args map[string]any) (string, bool) {
+	t.Helper()
+	server, err := newAwMCPServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, _ := json.Marshal(map[string]any{"name": name, "arguments": args})
+	var out strings.Builder
+	in := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":` + string(params) + "}\n"
+	if err := server.Serve(context.Background(), strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	var resp struct {
+		Result struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+			IsError bool `json:"isError"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(out.String()), &resp); err != nil || len(resp.Result.Content) == 0 {
+		t.Fatalf("response %q: %v", out.String(), err)
+	}
+	return resp.Result.Content[0].Text, resp.Result.IsError
+}
+
+func TestMCPServeMapsBuiltinToolsToAwCommands(t *testing.T) {
+	t.Setenv("AW_HOME", t.TempDir())
+	oldRun, oldTeam := mcpRunAw, teamFlag
+	t.Cleanup(func() { mcpRunAw, teamFlag = oldRun, oldTeam })
+	teamFlag = "backend:acme.com"
+
+	var got [][]string
+	mcpRunAw = func(ctx context.Context, argv []string) ([]byte, []byte, error) {
+		got = append(got, argv)
+		if argv[len(argv)-1] == "missing" {
+			return nil, []byte("task not found\n"), errors.New("exit status 1")
+		}
+		return []byte(`{"ok":true}`), nil, nil
+	}
+
+	if text, isErr := callMCPToolForTest(t, "chat_send", map[string]any{"to": "bob", "message": "-- ready?", "wait": 30}); isErr || text != `{"ok":true}` {
+		t.Fatalf("chat_send text=%q isErr=%v", text, isErr)
+	}
+	callMCPToolForTest(t, "task_claim", map[string]any{"ref": "aw-12", "reclaim_after": "30m"})
+	callMCPToolForTest(t, "task_update", map[string]any{"ref": "aw-12", "assignee": "", "status": "closed"})
+	callMCPToolForTest(t, "lock_acquire", map[string]any{"resource_key": "repo/main", "ttl_seconds": 60})
+	if text, isErr := callMCPToolForTest(t, "task_claim", map[string]any{"ref": "missing"}); !isErr || text != "task not found" {
+		t.Fatalf("failed command text=%q isErr=%v", text, isErr)
+	}
+	if text, isErr := callMCPToolForTest(t, "mail_send", map[string]any{"to": "bob"}); !isErr || !strings.Contains(text, "body is required") {
+		t.Fatalf("missing arg text=%q isErr=%v", text, isErr)
+	}
+
+	want := [][]string{
+		{"--json", "chat", "--team", "backend:acme.com", "send-and-wait", "--wait", "30", "--", "bob", "-- ready?"},
+		{"--json", "task", "--team", "backend:acme.com", "claim", "--reclaim-after=30m", "--", "aw-12"},
+		{"--json", "task", "--team", "backend:acme.com", "update", "--status=closed", "--assignee=", "--", "aw-12"},
+		{"--json", "lock", "--team", "backend:acme.com", "acquire", "--resource-key=repo/main", "--ttl-seconds", "60"},
+		{"--json", "task", "--team", "backend:acme.com", "claim", "--", "missing"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("argv=%#v\nwant=%#v", got, want)
+	}
+}
+
+func TestMCPServeExposesInstalledManifestPluginTools(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/documents/pitch" {
+			t.Errorf("unexpected request %s", r.URL.String())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"slug":"pitch"}`))
+	}))
+	defer server.Close()
+
+	home := t.TempDir()
+	t.Setenv("AW_HOME", home)
+	manifestPath := filepath.Join(home, "plugins", "folio", "manifest.json")
+	if err := os.MkdirAll(filepath.Dir(manifestPath), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{"manifest_version":1,"app":{"id":"folio","version":"1.0.0","origin":"` + server.URL + `"},"tools":[{"name":"show","description":"Show a document","method":"GET","path":"/v1/documents/{slug}","auth":"none","input_schema":{"type":"object","properties":{"slug":{"type":"string"}},"required":["slug"]},"params":[{"name":"slug","in":"path"}],"mutation":false}]}`
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	mcp, err := newAwMCPServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tool map[string]any
+	for _, candidate := range mcp.Tools() {
+		if candidate.Name == "folio_show" {
+			data, _ := json.Marshal(candidate)
+			_ = json.Unmarshal(data, &tool)
+		}
+	}
+	if tool == nil || tool["description"] != "Show a document" {
+		t.Fatalf("folio_show not exposed: %#v", mcp.Tools())
+	}
+	if required := tool["inputSchema"].(map[string]any)["required"]; !reflect.DeepEqual(required, []any{"slug"}) {
+		t.Fatalf("inputSchema=%#v", tool["inputSchema"])
+	}
+	if text, isErr := callMCPToolForTest(t, "folio_show", map[string]any{"slug": "pitch"}); isErr || text != `{"slug":"pitch"}` {
+		t.Fatalf("folio_show text=%q isErr=%v", text, isErr)
+	}
+	if text, isErr := callMCPToolForTest(t, "folio_show", map[string]any{"slug": "pitch", "owner": "mallory"}); !isErr || !strings.Contains(text, "unknown flag --owner") {
+		t.Fatalf("out-of-schema argument text=%q isErr=%v", text, isErr)
+	}
+}
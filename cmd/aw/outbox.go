@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awid"
+	"github.com/awebai/aw/outbox"
+	"github.com/spf13/cobra"
+)
+
+// outboxFlushInterval is how often `aw run` retries queued sends.
+const outboxFlushInterval = time.Minute
+
+var outboxFlushDueOnly bool
+
+var outboxCmd = &cobra.Command{
+	Use:   "outbox",
+	Short: "Inspect and deliver sends queued while the server was unreachable",
+	Long: `Mail and send-and-leave chat messages that cannot reach the aweb server
+are kept, already signed and encrypted, under the identity's outbox/
+directory. aw run retries them in the background; aw outbox flush retries
+them now. The server drops duplicates by message_id.`,
+}
+
+var outboxListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List queued sends",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		wd, _ := os.Getwd()
+		store, err := outboxStoreForDir(wd)
+		if err != nil {
+			return err
+		}
+		records, err := store.List()
+		if err != nil {
+			return err
+		}
+		failed, err := store.ListFailed()
+		if err != nil {
+			return err
+		}
+		printOutput(outboxListOutput{Queued: records, Failed: failed}, formatOutboxList)
+		return nil
+	},
+}
+
+var outboxFlushCmd = &cobra.Command{
+	Use:   "flush",
+	Short: "Send queued messages now",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		wd, _ := os.Getwd()
+		store, err := outboxStoreForDir(wd)
+		if err != nil {
+			return err
+		}
+		c, _, err := resolveClientSelection()
+		if err != nil {
+			return err
+		}
+		result, err := store.Flush(ctx, c.Client, !outboxFlushDueOnly)
+		if err != nil {
+			return err
+		}
+		printOutput(result, formatOutboxFlush)
+		return nil
+	},
+}
+
+func init() {
+	outboxCmd.GroupID = groupNetwork
+	outboxFlushCmd.Flags().BoolVar(&outboxFlushDueOnly, "due-only", false, "Only retry sends whose backoff has elapsed")
+	outboxCmd.AddCommand(outboxListCmd, outboxFlushCmd)
+	rootCmd.AddCommand(outboxCmd)
+}
+
+type outboxListOutput struct {
+	Queued []outbox.Record `json:"queued"`
+	Failed []outbox.Record `json:"failed"`
+}
+
+func formatOutboxList(v any) string {
+	out := v.(outboxListOutput)
+	if len(out.Queued) == 0 && len(out.Failed) == 0 {
+		return "Outbox is empty.\n"
+	}
+	var sb strings.Builder
+	for _, r := range out.Queued {
+		sb.WriteString(fmt.Sprintf("%s  %s  queued %s", r.MessageID, r.Kind, r.QueuedAt))
+		if r.Attempts > 0 {
+			sb.WriteString(fmt.Sprintf("  attempts=%d next=%s", r.Attempts, r.NextAttemptAt))
+		}
+		sb.WriteString("\n")
+	}
+	for _, r := range out.Failed {
+		sb.WriteString(fmt.Sprintf("%s  %s  FAILED: %s\n", r.MessageID, r.Kind, r.LastError))
+	}
+	return sb.String()
+}
+
+func formatOutboxFlush(v any) string {
+	r := v.(outbox.FlushResult)
+	s := fmt.Sprintf("Delivered %d, pending %d, failed %d", r.Delivered, r.Pending, r.Failed)
+	if r.Skipped > 0 {
+		s += fmt.Sprintf(", skipped %d from another team", r.Skipped)
+	}
+	return s + "\n"
+}
+
+func outboxStoreForDir(workingDir string) (*outbox.Store, error) {
+	home, err := identityHomeForDir(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(home.Root) == "" {
+		return nil, fmt.Errorf("no identity home for %s", workingDir)
+	}
+	return outbox.New(filepath.Join(home.Root, outbox.DirName)), nil
+}
+
+// attachOutbox lets client queue sends under the identity home of
+// workingDir when the server is unreachable. Best-effort: without an
+// identity home, sends fail as before.
+func attachOutbox(client *awid.Client, workingDir string) {
+	if client == nil {
+		return
+	}
+	store, err := outboxStoreForDir(workingDir)
+	if err != nil {
+		debugLog("outbox: %v", err)
+		return
+	}
+	client.SetOutbox(store)
+}
+
+// flushOutboxPeriodically retries queued sends until ctx is done. Each
+// client delivers only its own team's sends.
+func flushOutboxPeriodically(ctx context.Context, workingDir string, clients ...*awid.Client) {
+	if len(clients) == 0 {
+		return
+	}
+	store, err := outboxStoreForDir(workingDir)
+	if err != nil {
+		debugLog("outbox: %v", err)
+		return
+	}
+	ticker := time.NewTicker(outboxFlushInterval)
+	defer ticker.Stop()
+	for {
+		for _, client := range clients {
+			result, err := store.Flush(ctx, client, false)
+			if err != nil && ctx.Err() == nil {
+				debugLog("outbox flush: %v", err)
+			} else if result.Delivered > 0 || result.Failed > 0 {
+				debugLog("outbox flush: delivered %d, failed %d, pending %d", result.Delivered, result.Failed, result.Pending)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1015,20 +1015,9 @@ func executeInstalledManifestTool(name string, args []string) (*installedManifes
 		debugLog("resolve plugin dir: %v", err)
 		return nil, false, nil
 	}
-	manifestPath := manifestPluginManifestPath(dir, name)
-	data, err := readFileBounded(manifestPath, maxManifestBytes)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return nil, false, nil
-		}
-		return nil, true, err
-	}
-	var manifest appmanifest.Manifest
-	if err := appmanifest.DecodeSingleJSONStrict(data, &manifest); err != nil {
-		return nil, true, fmt.Errorf("decode manifest %s: %w", manifestPath, err)
-	}
-	if err := appmanifest.Validate(manifest, reservedRootCommandNames()); err != nil {
-		return nil, true, err
+	manifest, exists, err := loadInstalledManifest(dir, name)
+	if !exists || err != nil {
+		return nil, exists, err
 	}
 	if len(args) == 0 || strings.TrimSpace(args[0]) == "" {
 		return nil, true, fmt.Errorf("missing verb for app %q; run `aw %s --help`", name, name)
@@ -1061,53 +1050,83 @@ func executeInstalledManifestTool(name string, args []string) (*installedManifes
 	if err := validateManifestDispatchArgs(name, tool, parsedArgs); err != nil {
 		return nil, true, err
 	}
+	result, err := invokeInstalledManifestTool(name, manifest, verb, parsedArgs, rawBody)
+	if err != nil {
+		return nil, true, err
+	}
+	return result, true, nil
+}
+
+// loadInstalledManifest reads and validates the manifest of an installed
+// manifest plugin. exists is false when no manifest plugin has that name.
+func loadInstalledManifest(dir, name string) (manifest appmanifest.Manifest, exists bool, err error) {
+	manifestPath := manifestPluginManifestPath(dir, name)
+	data, err := readFileBounded(manifestPath, maxManifestBytes)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return appmanifest.Manifest{}, false, nil
+		}
+		return appmanifest.Manifest{}, true, err
+	}
+	if err := appmanifest.DecodeSingleJSONStrict(data, &manifest); err != nil {
+		return appmanifest.Manifest{}, true, fmt.Errorf("decode manifest %s: %w", manifestPath, err)
+	}
+	if err := appmanifest.Validate(manifest, reservedRootCommandNames()); err != nil {
+		return appmanifest.Manifest{}, true, err
+	}
+	return manifest, true, nil
+}
+
+// invokeInstalledManifestTool interprets verb against the manifest and sends
+// the request, signed with the workspace identity unless the tool has
+// auth "none".
+func invokeInstalledManifestTool(name string, manifest appmanifest.Manifest, verb string, args map[string]any, rawBody []byte) (*installedManifestToolResult, error) {
 	spec, err := appmanifest.Interpret(appmanifest.InterpretRequest{
 		Manifest:      manifest,
 		Verb:          verb,
-		Args:          parsedArgs,
+		Args:          args,
 		RawBody:       rawBody,
 		ReservedNames: reservedRootCommandNames(),
 	})
 	if err != nil {
-		return nil, true, err
+		return nil, err
 	}
-	effectiveArgs, err := effectiveLibraryManifestMaterializeArgs(name, verb, parsedArgs, spec.Body)
+	effectiveArgs, err := effectiveLibraryManifestMaterializeArgs(name, verb, args, spec.Body)
 	if err != nil {
-		return nil, true, err
+		return nil, err
 	}
 	if isLibraryManifestLocalMaterialize(name, verb, effectiveArgs) && (!spec.Mutation || spec.Auth == "none") {
-		return nil, true, fmt.Errorf("library materialize --target local requires a signed mutation tool")
+		return nil, fmt.Errorf("library materialize --target local requires a signed mutation tool")
+	}
+	if err := requireToolApproval(name, verb, args, spec.Mutation); err != nil {
+		return nil, err
 	}
 	parsedURL, err := url.Parse(spec.URL)
 	if err != nil {
-		return nil, true, err
+		return nil, err
 	}
 	headers := make(http.Header)
 	for key, value := range spec.Headers {
 		headers.Set(key, value)
 	}
 	if spec.Auth == "none" {
-		result, err := executeUnsignedManifestRequest(spec.Method, parsedURL, spec.Body, headers)
-		if err != nil {
-			return nil, true, err
-		}
-		return result, true, nil
+		return executeUnsignedManifestRequest(spec.Method, parsedURL, spec.Body, headers)
 	}
 	identity, err := resolveLocalSigningIdentity()
 	if err != nil {
-		return nil, true, err
+		return nil, err
 	}
 	result, err := executeSignedIDRequest(spec.Method, parsedURL, identity, spec.Body, headers, map[string]any{}, true)
 	if err != nil {
-		return nil, true, err
+		return nil, err
 	}
 	installedResult := &installedManifestToolResult{Status: result.Status, Body: result.Body}
 	if installedResult.Status >= http.StatusOK && installedResult.Status < http.StatusMultipleChoices {
 		if err := applyLibraryManifestLocalMaterialize(name, verb, effectiveArgs, installedResult.Body); err != nil {
-			return nil, true, err
+			return nil, err
 		}
 	}
-	return installedResult, true, nil
+	return installedResult, nil
 }
 
 func executeUnsignedManifestRequest(method string, parsedURL *url.URL, bodyBytes []byte, headers http.Header) (*installedManifestToolResult, error) {
@@ -63,7 +63,10 @@ var rootCmd = &cobra.Command{
 		}
 		restoreIdentityHomeAfterCommand(cmd, identityHomeEnv, hadIdentityHomeEnv, previousActiveIdentityHome)
 		maybeCheckLatestVersion(cmd)
-		return nil
+		if err := requireGrantCommandScope(cmd); err != nil {
+			return err
+		}
+		return requireCommandApproval(cmd, args)
 	},
 	SilenceUsage:  true,
 	SilenceErrors: true,
@@ -120,6 +123,7 @@ func init() {
 	introspectCmd.GroupID = groupIdentity
 	identityCmd.GroupID = groupIdentity
 	mcpConfigCmd.GroupID = groupIdentity
+	mcpCmd.GroupID = groupIdentity
 
 	chatCmd.GroupID = groupNetwork
 	mailCmd.GroupID = groupNetwork
@@ -139,6 +143,7 @@ func init() {
 	notifyCmd.GroupID = groupCoordination
 	instructionsCmd.GroupID = groupCoordination
 	rolesCmd.GroupID = groupCoordination
+	appCmd.GroupID = groupCoordination
 
 	versionCmd.GroupID = groupUtility
 	upgradeCmd.GroupID = groupUtility
@@ -172,6 +177,7 @@ func init() {
 	bindTeamSelector(directoryCmd)
 	bindTeamSelector(introspectCmd)
 	bindTeamSelector(doctorCmd)
+	bindTeamSelector(appCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(upgradeCmd)
 	rootCmd.AddCommand(a2aCmd)
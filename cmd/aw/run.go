@@ -16,6 +16,7 @@ import (
 
 	aweb "github.com/awebai/aw"
 	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/internal/blueprint"
 	awrun "github.com/awebai/aw/run"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
@@ -37,25 +38,30 @@ var (
 	runTripOnDanger  bool
 	runAutofeedWork  bool
 	runInitConfig    bool
+	runHeadless      bool
+	runHeadlessSock  string
+	runNoControlSock bool
+	runTakeoverLease string
+	runAllTeams      bool
 )
 
 var (
-	runLoadUserConfig  = awrun.LoadUserConfig
-	runInitUserConfig  = awrun.InitUserConfig
-	runResolveSettings = awrun.ResolveSettings
-	runNewProvider     = awrun.NewProvider
-	runNewLoop         = awrun.NewLoop
-	runExecuteLoop     = func(loop *awrun.Loop, ctx context.Context, opts awrun.LoopOptions) error { return loop.Run(ctx, opts) }
-	runNewEventBus     = func(client *aweb.Client) *awrun.EventBus {
-		return awrun.NewEventBus(awrun.EventBusConfig{
-			Stream: awrun.NewEventStreamOpener(client.Client),
-		})
-	}
+	runLoadUserConfig        = awrun.LoadUserConfig
+	runInitUserConfig        = awrun.InitUserConfig
+	runResolveSettings       = awrun.ResolveSettings
+	runNewProvider           = awrun.NewProvider
+	runNewLoop               = awrun.NewLoop
+	runExecuteLoop           = func(loop *awrun.Loop, ctx context.Context, opts awrun.LoopOptions) error { return loop.Run(ctx, opts) }
+	runNewEventBus           = newRunEventBus
 	runNewScreenController   = awrun.NewScreenController
 	runResolveClientForDir   = resolveClientSelectionForDir
 	runWorkspaceStateForDir  = resolveRunWorkspaceStateForDir
 	runGetwd                 = os.Getwd
 	runResolveClaimedTaskRef = resolveRunClaimedTaskRef
+	runAcquireSessionLease   = acquireRunSessionLease
+	runResolveTeams          = resolveRunTeams
+	runNewTeamEventBus       = newRunTeamEventBus
+	runNewTaskClaims         = newRunTaskClaims
 )
 
 var runCmd = &cobra.Command{
@@ -98,6 +104,11 @@ func init() {
 	runCmd.Flags().BoolVar(&runTripOnDanger, "trip-on-danger", false, "Remove provider bypass flags and use native provider safety checks")
 	runCmd.Flags().BoolVar(&runAutofeedWork, "autofeed-work", false, "Wake for work-related events in addition to incoming mail/chat")
 	runCmd.Flags().BoolVar(&runInitConfig, "init", false, "Prompt for ~/.config/aw/run.json values and write them")
+	runCmd.Flags().BoolVar(&runHeadless, "headless", false, "Emit JSON lines instead of the terminal UI and read JSON control lines from stdin")
+	runCmd.Flags().StringVar(&runHeadlessSock, "headless-socket", "", "With --headless, read JSON control lines from this Unix socket instead of stdin")
+	runCmd.Flags().BoolVar(&runNoControlSock, "no-control-socket", false, "Do not listen on the workspace control socket (.aw/run.sock)")
+	runCmd.Flags().StringVar(&runTakeoverLease, "takeover-lease", "", "Take over the identity's session lease from another aw run, recording this reason")
+	runCmd.Flags().BoolVar(&runAllTeams, "all-teams", false, "Listen for wakes from every team membership of this identity, not just the active team")
 
 	rootCmd.AddCommand(runCmd)
 }
@@ -106,6 +117,12 @@ func runRun(cmd *cobra.Command, args []string) error {
 	if runMaxRuns < 0 {
 		return fmt.Errorf("--max-runs must be >= 0")
 	}
+	if strings.TrimSpace(runHeadlessSock) != "" && !runHeadless {
+		return usageError("--headless-socket requires --headless")
+	}
+	if runAllTeams && strings.TrimSpace(teamFlag) != "" {
+		return usageError("--all-teams and --team are mutually exclusive")
+	}
 
 	workingDir, err := effectiveRunDir()
 	if err != nil {
@@ -132,6 +149,11 @@ func runRun(cmd *cobra.Command, args []string) error {
 	}
 
 	screen := runNewScreenController(cmd.InOrStdin(), cmd.OutOrStdout())
+	var headless *awrun.HeadlessUI
+	if runHeadless {
+		screen = nil
+		headless = awrun.NewHeadlessUI(cmd.InOrStdin(), cmd.OutOrStdout(), runHeadlessSock)
+	}
 	promptInput := bufferedPromptReader(cmd.InOrStdin())
 	providerName, initialPrompt, providerArgs, err := resolveRunInvocation(cmd, args, screen != nil, promptInput)
 	if err != nil {
@@ -146,10 +168,16 @@ func runRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 	defer restoreIdentityHome()
+	var teams []runTeam
+	if runAllTeams && client != nil {
+		if teams, err = runResolveTeams(workingDir, client, sel); err != nil {
+			return err
+		}
+	}
 	if strings.TrimSpace(initialPrompt) == "" && onboarding != nil && strings.TrimSpace(onboarding.InitialPrompt) != "" {
 		initialPrompt = strings.TrimSpace(onboarding.InitialPrompt)
 	}
-	allowInteractiveEmptyPrompt := screen != nil
+	allowInteractiveEmptyPrompt := screen != nil || headless != nil
 	if strings.TrimSpace(settings.BasePrompt) == "" && initialPrompt == "" && !allowInteractiveEmptyPrompt {
 		return usageError("missing prompt (pass --prompt, --base-prompt, or configure base_prompt with `aw run --init`)")
 	}
@@ -168,13 +196,56 @@ func runRun(cmd *cobra.Command, args []string) error {
 
 	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
+	// The outbox speaks for the identity, so it stops with the session
+	// lease.
+	outboxCtx, stopOutbox := context.WithCancel(ctx)
+	defer stopOutbox()
+	if len(teams) > 0 {
+		go flushOutboxPeriodically(outboxCtx, workingDir, runTeamClients(teams)...)
+	} else if client != nil {
+		go flushOutboxPeriodically(outboxCtx, workingDir, client.Client)
+	}
 
 	loop := runNewLoop(provider, cmd.OutOrStdout())
+	loop.OnLeaseLost = stopOutbox
 	lastSessionID := ""
 	var lastBuildOptions awrun.BuildOptions
-	loop.EventBus = runNewEventBus(client)
-	loop.Control = screen
-	loop.Dispatch = newRunDispatcher(settings, newRunWakeValidator(client, sel.Alias))
+	if len(teams) > 0 {
+		loop.EventBus = runNewTeamEventBus(teams, workingDir)
+	} else {
+		loop.EventBus = runNewEventBus(client, workingDir)
+	}
+	if headless != nil {
+		loop.Control = headless
+	} else {
+		loop.Control = screen
+	}
+	if !runNoControlSock {
+		loop.ControlSocket = awrun.NewControlSocket(awrun.ControlSocketPath(interactionLogRoot(workingDir)))
+	}
+	subscriptions, err := blueprint.LoadMaterializedSubscriptions(interactionLogRoot(workingDir))
+	if err != nil {
+		return fmt.Errorf("load profile event subscriptions: %w", err)
+	}
+	if len(subscriptions) > 0 {
+		debugLog("run: waking on app events for %d profile subscription(s)", len(subscriptions))
+	}
+	if len(teams) > 0 {
+		if loop.Lease, err = acquireRunTeamSessionLeases(ctx, cmd, teams, screen != nil, promptInput); err != nil {
+			return err
+		}
+		loop.TaskClaims = newRunTeamTaskClaims(teams)
+	} else if client != nil {
+		if loop.Lease, err = runAcquireSessionLease(ctx, cmd, client, screen != nil, promptInput); err != nil {
+			return err
+		}
+		loop.TaskClaims = runNewTaskClaims(client, sel.WorkspaceID)
+	}
+	if len(teams) > 0 {
+		loop.Dispatch = newRunTeamDispatcher(settings, subscriptions, teams)
+	} else {
+		loop.Dispatch = newRunDispatcher(settings, subscriptions, newRunWakeValidator(client, sel.Alias))
+	}
 	loop.StatusIdentity = statusIdentity
 	loop.OnSessionID = func(sessionID string) {
 		lastSessionID = strings.TrimSpace(sessionID)
@@ -220,10 +291,14 @@ func runRun(cmd *cobra.Command, args []string) error {
 		ProviderArgs:    providerArgs,
 		ProviderPTY:     effectiveProviderPTY(cmd, screen != nil),
 		Services:        settings.Services,
+		Budget:          settings.Budget,
+		LedgerPath:      awrun.LedgerPath(interactionLogRoot(workingDir)),
 	}
 
 	err = runExecuteLoop(loop, ctx, opts)
-	printRunExitCommands(cmd.OutOrStdout(), providerName, workingDir, provider, lastSessionID, lastBuildOptions)
+	if headless == nil {
+		printRunExitCommands(cmd.OutOrStdout(), providerName, workingDir, provider, lastSessionID, lastBuildOptions)
+	}
 	if err == nil || err == context.Canceled {
 		return nil
 	}
@@ -341,6 +416,11 @@ func initRunCommandVars() {
 	runProviderPTY = false
 	runAutofeedWork = false
 	runInitConfig = false
+	runHeadless = false
+	runHeadlessSock = ""
+	runNoControlSock = false
+	runTakeoverLease = ""
+	runAllTeams = false
 }
 
 func setRunCommandIO(cmd *cobra.Command, in io.Reader, out io.Writer, errOut io.Writer) {
@@ -362,7 +442,7 @@ func resolveRunInvocation(cmd *cobra.Command, args []string, interactive bool, p
 		if !interactive {
 			return "", "", nil, usageError("missing provider (use `aw run <provider>`)")
 		}
-		selected, err := promptIndexedChoice("Provider", []string{"claude", "codex"}, 0, promptInput, cmd.ErrOrStderr())
+		selected, err := promptIndexedChoice("Provider", awrun.ProviderNames(), 0, promptInput, cmd.ErrOrStderr())
 		if err != nil {
 			return "", "", nil, err
 		}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"time"
+
+	aweb "github.com/awebai/aw"
+	"github.com/awebai/aw/awid"
+	awrun "github.com/awebai/aw/run"
+)
+
+const runEventCursorFile = "event-cursor.json"
+
+func newRunEventBus(client *aweb.Client, workingDir string) *awrun.EventBus {
+	source := runEventSource(client)
+	if home, err := identityHomeForDir(workingDir); err == nil && home.Root != "" {
+		source.Cursor = awrun.FileCursorStore{Path: filepath.Join(home.Root, runEventCursorFile)}
+	}
+	return awrun.NewEventBus(awrun.EventBusConfig{Teams: []awrun.TeamEventSource{source}})
+}
+
+func runEventSource(client *aweb.Client) awrun.TeamEventSource {
+	return awrun.TeamEventSource{
+		Stream:  awrun.NewEventStreamOpener(client.Client),
+		Resume:  awrun.NewResumableEventStreamOpener(client.Client),
+		CatchUp: func(ctx context.Context) ([]awid.AgentEvent, error) { return runCatchUpEvents(ctx, client) },
+	}
+}
+
+// runCatchUpEvents rebuilds the wake events an agent may have missed from
+// what is still outstanding: unread mail and pending chat, oldest first,
+// then the ready tasks.
+func runCatchUpEvents(ctx context.Context, client *aweb.Client) ([]awid.AgentEvent, error) {
+	type timedEvent struct {
+		at    time.Time
+		hasAt bool
+		evt   awid.AgentEvent
+	}
+	timed := func(at string, evt awid.AgentEvent) timedEvent {
+		ts, ok := parseTimeBestEffort(at)
+		return timedEvent{at: ts, hasAt: ok, evt: evt}
+	}
+	var comms []timedEvent
+
+	inbox, err := client.Inbox(ctx, awid.InboxParams{UnreadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range inbox.Messages {
+		comms = append(comms, timed(msg.CreatedAt, awid.AgentEvent{
+			Type:           awid.AgentEventActionableMail,
+			Channel:        "mail",
+			MessageID:      msg.MessageID,
+			ConversationID: msg.ConversationID,
+			FromAlias:      msg.FromAlias,
+			FromStableID:   msg.FromStableID,
+			FromDID:        msg.FromDID,
+			FromAddress:    msg.FromAddress,
+			Subject:        msg.Subject,
+		}))
+	}
+
+	pending, err := client.ChatPending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range pending.Pending {
+		comms = append(comms, timed(item.LastActivity, awid.AgentEvent{
+			Type:          awid.AgentEventActionableChat,
+			Channel:       "chat",
+			SessionID:     item.SessionID,
+			FromAlias:     item.LastFrom,
+			FromStableID:  item.LastFromStableID,
+			FromDID:       item.LastFromDID,
+			FromAddress:   item.LastFromAddress,
+			UnreadCount:   item.UnreadCount,
+			SenderWaiting: item.SenderWaiting,
+		}))
+	}
+	// Items without a parseable time sort after every timed one, keeping
+	// their original order, so the comparison stays a strict weak ordering.
+	sort.SliceStable(comms, func(i, j int) bool {
+		if comms[i].hasAt != comms[j].hasAt {
+			return comms[i].hasAt
+		}
+		return comms[i].hasAt && comms[i].at.Before(comms[j].at)
+	})
+
+	ready, err := client.TaskListReady(ctx)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]awid.AgentEvent, 0, len(comms)+len(ready.Tasks))
+	for _, c := range comms {
+		events = append(events, c.evt)
+	}
+	for _, task := range ready.Tasks {
+		events = append(events, awid.AgentEvent{
+			Type:   awid.AgentEventWorkAvailable,
+			TaskID: task.TaskID,
+			Title:  task.Title,
+		})
+	}
+	return events, nil
+}
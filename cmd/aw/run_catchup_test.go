@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	aweb "github.com/awebai/aw"
+)
+
+func TestRunCatchUpEventsOrdersOutstandingWork(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/messages/inbox":
+			if r.URL.Query().Get("unread_only") != "true" {
+				t.Errorf("inbox query=%q", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"messages":[
+				{"message_id":"m-undated","from_alias":"kim","subject":"no timestamp"},
+				{"message_id":"m-late","from_alias":"rose","subject":"later","created_at":"2026-03-01T10:00:00Z"},
+				{"message_id":"m-early","from_alias":"bob","subject":"first","created_at":"2026-03-01T08:00:00Z"}
+			]}`))
+		case "/v1/chat/pending":
+			_, _ = w.Write([]byte(`{"pending":[
+				{"session_id":"s-1","last_from":"eve","unread_count":2,"sender_waiting":true,"last_activity":"2026-03-01T09:00:00Z"}
+			]}`))
+		case "/v1/tasks/ready":
+			_, _ = w.Write([]byte(`{"tasks":[{"task_id":"t-1","title":"Fix the build"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := aweb.New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := runCatchUpEvents(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, ev := range events {
+		got = append(got, string(ev.Type)+":"+ev.MessageID+ev.SessionID+ev.TaskID)
+	}
+	want := []string{"actionable_mail:m-early", "actionable_chat:s-1", "actionable_mail:m-late", "actionable_mail:m-undated", "work_available:t-1"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("events=%v, want %v", got, want)
+	}
+	if chat := events[1]; chat.FromAlias != "eve" || chat.UnreadCount != 2 || !chat.SenderWaiting {
+		t.Fatalf("chat event=%#v", chat)
+	}
+}
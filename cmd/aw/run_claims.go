@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	aweb "github.com/awebai/aw"
+	awrun "github.com/awebai/aw/run"
+)
+
+const runTaskClaimInterval = time.Minute
+
+// runTaskClaimPageCap bounds how many claim pages one heartbeat or release
+// walks, so a server that never stops paging cannot hold the loop.
+const runTaskClaimPageCap = 100
+
+// newRunTaskClaims keeps the task claims held by workspaceID alive while aw
+// run works and releases them when it exits. It covers claims the agent
+// takes during the run as well as the one held when it started. Only claims
+// this run heartbeated are released: a claim taken after the last heartbeat
+// stays with the workspace.
+func newRunTaskClaims(client *aweb.Client, workspaceID string) *awrun.TaskClaims {
+	workspaceID = strings.TrimSpace(workspaceID)
+	if client == nil || workspaceID == "" {
+		return nil
+	}
+	var mu sync.Mutex
+	kept := map[string]struct{}{}
+	return &awrun.TaskClaims{
+		Interval: runTaskClaimInterval,
+		Heartbeat: func(ctx context.Context) error {
+			return forEachRunTaskClaim(ctx, client, workspaceID, func(ctx context.Context, ref string) error {
+				if _, err := client.TaskClaimHeartbeat(ctx, ref, &aweb.TaskClaimHeartbeatRequest{WorkspaceID: workspaceID}); err != nil {
+					return err
+				}
+				mu.Lock()
+				kept[ref] = struct{}{}
+				mu.Unlock()
+				return nil
+			})
+		},
+		Release: func(ctx context.Context) error {
+			return forEachRunTaskClaim(ctx, client, workspaceID, func(ctx context.Context, ref string) error {
+				mu.Lock()
+				_, ok := kept[ref]
+				mu.Unlock()
+				if !ok {
+					return nil
+				}
+				return client.TaskRelease(ctx, ref, &aweb.TaskReleaseRequest{WorkspaceID: workspaceID, Reason: "aw run exited"})
+			})
+		},
+	}
+}
+
+// forEachRunTaskClaim calls fn for every claim workspaceID holds, following
+// the listing's cursor across pages.
+func forEachRunTaskClaim(ctx context.Context, client *aweb.Client, workspaceID string, fn func(context.Context, string) error) error {
+	var errs []error
+	cursor := ""
+	for page := 0; page < runTaskClaimPageCap; page++ {
+		resp, err := client.ClaimsListPage(ctx, workspaceID, 0, cursor)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("list claims: %w", err))
+			break
+		}
+		for _, claim := range resp.Claims {
+			ref := strings.TrimSpace(claim.BeadID)
+			if ref == "" {
+				continue
+			}
+			if err := fn(ctx, ref); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", ref, err))
+			}
+		}
+		if !resp.HasMore {
+			break
+		}
+		next := ""
+		if resp.NextCursor != nil {
+			next = strings.TrimSpace(*resp.NextCursor)
+		}
+		if next == "" || next == cursor {
+			errs = append(errs, fmt.Errorf("list claims: server reports more claims but gave no new cursor after %q", cursor))
+			break
+		}
+		cursor = next
+	}
+	return errors.Join(errs...)
+}
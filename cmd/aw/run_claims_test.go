@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	aweb "github.com/awebai/aw"
+)
+
+func TestRunTaskClaimsHeartbeatAndReleaseWorkspaceClaims(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var calls []string
+	released := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/claims":
+			if got := r.URL.Query().Get("workspace_id"); got != "ws-1" {
+				t.Errorf("workspace_id=%q", got)
+			}
+			if r.URL.Query().Get("cursor") == "page-2" {
+				page := aweb.ClaimsResponse{Claims: []aweb.ClaimView{{BeadID: "aw-2"}}}
+				if released {
+					// Claimed after the last heartbeat, so not this run's to release.
+					page.Claims = append(page.Claims, aweb.ClaimView{BeadID: "aw-3"})
+				}
+				json.NewEncoder(w).Encode(page)
+				return
+			}
+			next := "page-2"
+			json.NewEncoder(w).Encode(aweb.ClaimsResponse{Claims: []aweb.ClaimView{{BeadID: "aw-1"}}, HasMore: true, NextCursor: &next})
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/tasks/"):
+			var req map[string]any
+			json.NewDecoder(r.Body).Decode(&req)
+			if req["workspace_id"] != "ws-1" {
+				t.Errorf("%s workspace_id=%v", r.URL.Path, req["workspace_id"])
+			}
+			calls = append(calls, r.URL.Path)
+			json.NewEncoder(w).Encode(aweb.TaskClaimView{})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	claims := newRunTaskClaims(mustWebClient(t, server.URL), "ws-1")
+	if err := claims.Heartbeat(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	released = true
+	mu.Unlock()
+	if err := claims.Release(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"/v1/tasks/aw-1/claim/heartbeat",
+		"/v1/tasks/aw-2/claim/heartbeat",
+		"/v1/tasks/aw-1/claim/release",
+		"/v1/tasks/aw-2/claim/release",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Fatalf("calls=%v", calls)
+	}
+	if newRunTaskClaims(mustWebClient(t, server.URL), "") != nil {
+		t.Fatal("expected no claim keeper without a workspace")
+	}
+}
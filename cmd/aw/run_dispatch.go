@@ -12,6 +12,7 @@ import (
 	aweb "github.com/awebai/aw"
 	"github.com/awebai/aw/awid"
 	"github.com/awebai/aw/chat"
+	"github.com/awebai/aw/internal/blueprint"
 	awrun "github.com/awebai/aw/run"
 )
 
@@ -32,17 +33,44 @@ type runDispatcher struct {
 	workPromptSuffix  string
 	commsPromptSuffix string
 	resolveWake       runWakeResolver
+	// subscriptions come from the materialized profile. When set, app
+	// events that match none of them do not wake the agent.
+	subscriptions []blueprint.Subscription
+	// tagTeams names the originating team in every wake prompt, for runs
+	// that listen to more than one team.
+	tagTeams bool
 }
 
-func newRunDispatcher(settings awrun.Settings, resolveWake runWakeResolver) awrun.Dispatcher {
+func newRunDispatcher(settings awrun.Settings, subscriptions []blueprint.Subscription, resolveWake runWakeResolver) awrun.Dispatcher {
 	return runDispatcher{
 		workPromptSuffix:  strings.TrimSpace(settings.WorkPromptSuffix),
 		commsPromptSuffix: strings.TrimSpace(settings.CommsPromptSuffix),
 		resolveWake:       resolveWake,
+		subscriptions:     subscriptions,
 	}
 }
 
+// newRunTeamDispatcher dispatches wakes from several teams, resolving each
+// through its own team's client and naming the team in the prompt.
+func newRunTeamDispatcher(settings awrun.Settings, subscriptions []blueprint.Subscription, teams []runTeam) awrun.Dispatcher {
+	d := newRunDispatcher(settings, subscriptions, newRunTeamWakeResolver(teams)).(runDispatcher)
+	d.tagTeams = true
+	return d
+}
+
 func (d runDispatcher) Next(ctx context.Context, autofeed bool, wakeEvent *awid.AgentEvent) (awrun.DispatchDecision, error) {
+	decision, err := d.next(ctx, autofeed, wakeEvent)
+	if err != nil || decision.Skip || !d.tagTeams || wakeEvent == nil {
+		return decision, err
+	}
+	if teamID := strings.TrimSpace(wakeEvent.TeamID); teamID != "" {
+		decision.CycleContext = joinPromptSections(formatTeamWakePreamble(teamID), decision.CycleContext)
+		decision.DisplayLines = append([]awrun.DisplayLine{{Kind: awrun.DisplayKindCommunication, Text: "team " + teamID}}, decision.DisplayLines...)
+	}
+	return decision, nil
+}
+
+func (d runDispatcher) next(ctx context.Context, autofeed bool, wakeEvent *awid.AgentEvent) (awrun.DispatchDecision, error) {
 	if wakeEvent == nil {
 		return awrun.DispatchDecision{Skip: true}, nil
 	}
@@ -87,8 +115,18 @@ func (d runDispatcher) Next(ctx context.Context, autofeed bool, wakeEvent *awid.
 		if strings.TrimSpace(summary) == "" {
 			return awrun.DispatchDecision{Skip: true}, nil
 		}
+		cycleContext := fmt.Sprintf("Wake reason: app event %s. Review the app-emitted event and continue the task-oriented cycle.", summary)
+		if len(d.subscriptions) > 0 {
+			sub, ok := blueprint.MatchSubscription(d.subscriptions, *wakeEvent)
+			if !ok {
+				return awrun.DispatchDecision{Skip: true}, nil
+			}
+			if prompt := strings.TrimSpace(sub.RenderPrompt(*wakeEvent, summary)); prompt != "" {
+				cycleContext = prompt
+			}
+		}
 		return awrun.DispatchDecision{
-			CycleContext: fmt.Sprintf("Wake reason: app event %s. Review the app-emitted event and continue the task-oriented cycle.", summary),
+			CycleContext: cycleContext,
 			DisplayLines: awrun.SplitDisplayText(awrun.DisplayKindCommunication, summary),
 			WaitSeconds:  awrun.DefaultWaitSeconds,
 		}, nil
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	aweb "github.com/awebai/aw"
+	"github.com/awebai/aw/awid"
+	awrun "github.com/awebai/aw/run"
+	"github.com/spf13/cobra"
+)
+
+const (
+	runSessionLeaseTTL      = 2 * time.Minute
+	runSessionLeaseInterval = 40 * time.Second
+)
+
+// acquireRunSessionLease claims the principal's session admission lease
+// for this aw run, so a second aw run on the same identity does not answer
+// the same mail and claim the same work. When another session holds it, the
+// lease is taken over with --takeover-lease or after a confirmation in a
+// terminal; otherwise aw run refuses to start. It returns nil when the
+// server predates session leases.
+func acquireRunSessionLease(ctx context.Context, cmd *cobra.Command, client *aweb.Client, interactive bool, promptInput io.Reader) (*awrun.SessionLease, error) {
+	sessionID, sessionKey, err := newRunSessionCredentials()
+	if err != nil {
+		return nil, err
+	}
+	req := &aweb.SessionLeaseRequest{
+		SessionID:  sessionID,
+		SessionKey: sessionKey,
+		TTLSeconds: int(runSessionLeaseTTL / time.Second),
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	view, err := client.SessionLeaseAcquire(ctx, req)
+	if err != nil {
+		code, ok := awid.HTTPStatusCode(err)
+		switch {
+		case ok && code == http.StatusNotFound:
+			fmt.Fprintln(cmd.ErrOrStderr(), "warning: this aweb server does not support session leases; another aw run on this identity will not be detected")
+			return nil, nil
+		case ok && code == http.StatusConflict:
+			view, err = takeOverRunSessionLease(ctx, cmd, client, req, interactive, promptInput)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("acquire session lease: %w", err)
+		}
+	}
+
+	lease := &awrun.SessionLease{
+		Interval: runSessionLeaseInterval,
+		TTL:      runSessionLeaseTTL,
+		Renew: func(ctx context.Context) (time.Time, error) {
+			view, err := client.SessionLeaseRenew(ctx, req)
+			if err != nil {
+				return time.Time{}, err
+			}
+			expiresAt, _ := parseTimeBestEffort(view.ExpiresAt)
+			return expiresAt, nil
+		},
+		Release: func(ctx context.Context) error {
+			return client.SessionLeaseRelease(ctx, &aweb.SessionLeaseReleaseRequest{SessionID: sessionID, SessionKey: sessionKey})
+		},
+	}
+	lease.ExpiresAt, _ = parseTimeBestEffort(view.ExpiresAt)
+	return lease, nil
+}
+
+func takeOverRunSessionLease(ctx context.Context, cmd *cobra.Command, client *aweb.Client, req *aweb.SessionLeaseRequest, interactive bool, promptInput io.Reader) (*aweb.SessionLeaseView, error) {
+	holder := "another session"
+	if current, err := client.SessionLeaseGet(ctx); err == nil && current.SessionID != "" {
+		holder = fmt.Sprintf("session %s, generation %d, until %s", current.SessionID, current.Generation, current.ExpiresAt)
+	}
+	reason := strings.TrimSpace(runTakeoverLease)
+	if reason == "" {
+		refusal := fmt.Errorf("another aw run holds this identity's session lease (%s); stop it, wait for the lease to expire, or pass --takeover-lease <reason>", holder)
+		if !interactive {
+			return nil, refusal
+		}
+		proceed, err := promptYesNoWithIO(
+			fmt.Sprintf("Another aw run holds this identity's session lease (%s). Take it over?", holder),
+			false,
+			promptInput,
+			cmd.ErrOrStderr(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if !proceed {
+			return nil, refusal
+		}
+		reason = "confirmed at aw run startup"
+		if host, err := os.Hostname(); err == nil && host != "" {
+			reason += " on " + host
+		}
+	}
+	view, err := client.SessionLeaseTakeover(ctx, &aweb.SessionLeaseTakeoverRequest{
+		SessionID:  req.SessionID,
+		SessionKey: req.SessionKey,
+		TTLSeconds: req.TTLSeconds,
+		Reason:     reason,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("take over session lease: %w", err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Took over the session lease at generation %d (%s).\n", view.Generation, reason)
+	return view, nil
+}
+
+// newRunSessionCredentials returns a fresh session id and secret; they
+// live only in this process, so a restarted aw run is a new session.
+func newRunSessionCredentials() (string, string, error) {
+	var buf [40]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", "", fmt.Errorf("generate session credentials: %w", err)
+	}
+	return "aw-run-" + hex.EncodeToString(buf[:8]), hex.EncodeToString(buf[8:]), nil
+}
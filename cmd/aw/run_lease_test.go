@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	aweb "github.com/awebai/aw"
+	"github.com/spf13/cobra"
+)
+
+func newSessionLeaseTestServer(t *testing.T, acquireStatus int, takeoverReason *string) *aweb.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/session-leases":
+			if acquireStatus != http.StatusOK {
+				w.WriteHeader(acquireStatus)
+				_, _ = w.Write([]byte(`{"detail":"lease held"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"status":"active","session_id":"mine","generation":1,"expires_at":"2026-03-01T10:02:00Z"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/session-leases":
+			_, _ = w.Write([]byte(`{"status":"active","session_id":"aw-run-old","generation":4,"expires_at":"2026-03-01T10:01:00Z"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/session-leases/takeover":
+			var req aweb.SessionLeaseTakeoverRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			*takeoverReason = req.Reason
+			_, _ = w.Write([]byte(`{"status":"active","session_id":"mine","generation":5,"expires_at":"2026-03-01T10:03:00Z"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	client, err := aweb.New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestAcquireRunSessionLease(t *testing.T) {
+	t.Cleanup(func() { runTakeoverLease = "" })
+	cmd := &cobra.Command{}
+	var stderr bytes.Buffer
+	cmd.SetErr(&stderr)
+
+	var reason string
+	lease, err := acquireRunSessionLease(context.Background(), cmd, newSessionLeaseTestServer(t, http.StatusOK, &reason), false, nil)
+	if err != nil || lease == nil || lease.ExpiresAt.IsZero() {
+		t.Fatalf("lease=%+v err=%v", lease, err)
+	}
+
+	_, err = acquireRunSessionLease(context.Background(), cmd, newSessionLeaseTestServer(t, http.StatusConflict, &reason), false, nil)
+	if err == nil || !strings.Contains(err.Error(), "aw-run-old") || !strings.Contains(err.Error(), "--takeover-lease") {
+		t.Fatalf("conflict err=%v", err)
+	}
+
+	_, err = acquireRunSessionLease(context.Background(), cmd, newSessionLeaseTestServer(t, http.StatusConflict, &reason), true, strings.NewReader("n\n"))
+	if err == nil || reason != "" {
+		t.Fatalf("declined takeover: err=%v reason=%q", err, reason)
+	}
+
+	runTakeoverLease = "stale tmux window"
+	lease, err = acquireRunSessionLease(context.Background(), cmd, newSessionLeaseTestServer(t, http.StatusConflict, &reason), false, nil)
+	if err != nil || lease == nil || reason != "stale tmux window" {
+		t.Fatalf("takeover lease=%+v err=%v reason=%q", lease, err, reason)
+	}
+	runTakeoverLease = ""
+
+	lease, err = acquireRunSessionLease(context.Background(), cmd, newSessionLeaseTestServer(t, http.StatusNotFound, &reason), false, nil)
+	if err != nil || lease != nil || !strings.Contains(stderr.String(), "does not support session leases") {
+		t.Fatalf("old server: lease=%+v err=%v stderr=%q", lease, err, stderr.String())
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	aweb "github.com/awebai/aw"
+	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awid"
+	awrun "github.com/awebai/aw/run"
+	"github.com/spf13/cobra"
+)
+
+const runTeamCursorDir = "event-cursors"
+
+// runTeam is one team membership aw run --all-teams listens to, with a
+// client bound to that team's certificate.
+type runTeam struct {
+	TeamID      string
+	Alias       string
+	WorkspaceID string
+	Client      *aweb.Client
+}
+
+// resolveRunTeams returns a client for every team membership of the
+// identity. The already selected team reuses client.
+func resolveRunTeams(workingDir string, client *aweb.Client, sel *awconfig.Selection) ([]runTeam, error) {
+	home, err := identityHomeForDir(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	if awconfig.IsGrantHome(home.Root) {
+		return nil, usageError("--all-teams is not available to a delegated grant identity")
+	}
+	state, err := awconfig.LoadTeamStateFromIdentityHome(home.Root)
+	if err != nil {
+		return nil, fmt.Errorf("load team memberships: %w", err)
+	}
+	teamIDs := state.AvailableTeamIDs()
+	if len(teamIDs) == 0 {
+		return nil, usageError("--all-teams found no team memberships for this identity")
+	}
+	teams := make([]runTeam, 0, len(teamIDs))
+	for _, teamID := range teamIDs {
+		if sel != nil && client != nil && strings.EqualFold(teamID, sel.TeamID) {
+			teams = append(teams, runTeam{TeamID: teamID, Alias: sel.Alias, WorkspaceID: sel.WorkspaceID, Client: client})
+			continue
+		}
+		teamClient, teamSel, err := resolveClientSelectionForDirWithTeamOverride(workingDir, teamID)
+		if err != nil {
+			return nil, fmt.Errorf("resolve team %s: %w", teamID, err)
+		}
+		teams = append(teams, runTeam{TeamID: teamID, Alias: teamSel.Alias, WorkspaceID: teamSel.WorkspaceID, Client: teamClient})
+	}
+	return teams, nil
+}
+
+// newRunTeamEventBus opens one event stream per team. Each team resumes
+// from its own cursor file, since event ids are only meaningful to the
+// stream that issued them.
+func newRunTeamEventBus(teams []runTeam, workingDir string) *awrun.EventBus {
+	cursorRoot := ""
+	if home, err := identityHomeForDir(workingDir); err == nil && home.Root != "" {
+		cursorRoot = filepath.Join(home.Root, runTeamCursorDir)
+	}
+	var cfg awrun.EventBusConfig
+	for _, team := range teams {
+		source := runEventSource(team.Client)
+		source.Team = team.TeamID
+		if cursorRoot != "" {
+			source.Cursor = awrun.FileCursorStore{Path: filepath.Join(cursorRoot, sanitizeKeyComponent(team.TeamID)+".json")}
+		}
+		cfg.Teams = append(cfg.Teams, source)
+	}
+	return awrun.NewEventBus(cfg)
+}
+
+// acquireRunTeamSessionLeases takes the session lease of every team, since
+// each membership answers to the server as its own principal, and keeps
+// them as one: losing any team's lease pauses the run. A team that cannot
+// be leased releases the ones already taken.
+func acquireRunTeamSessionLeases(ctx context.Context, cmd *cobra.Command, teams []runTeam, interactive bool, promptInput io.Reader) (*awrun.SessionLease, error) {
+	leases := map[string]*awrun.SessionLease{}
+	var teamIDs []string
+	for _, team := range teams {
+		lease, err := runAcquireSessionLease(ctx, cmd, team.Client, interactive, promptInput)
+		if err != nil {
+			releaseRunTeamSessionLeases(teamIDs, leases)
+			return nil, fmt.Errorf("team %s: %w", team.TeamID, err)
+		}
+		if lease != nil {
+			teamIDs = append(teamIDs, team.TeamID)
+			leases[team.TeamID] = lease
+		}
+	}
+	return joinRunTeamSessionLeases(teamIDs, leases), nil
+}
+
+func joinRunTeamSessionLeases(teamIDs []string, leases map[string]*awrun.SessionLease) *awrun.SessionLease {
+	switch len(teamIDs) {
+	case 0:
+		return nil
+	case 1:
+		return leases[teamIDs[0]]
+	}
+	first := leases[teamIDs[0]]
+	joined := &awrun.SessionLease{Interval: first.Interval, TTL: first.TTL}
+	for _, teamID := range teamIDs {
+		joined.ExpiresAt = earliestLeaseExpiry(joined.ExpiresAt, leases[teamID].ExpiresAt, teamID == teamIDs[0])
+	}
+	joined.Renew = func(ctx context.Context) (time.Time, error) {
+		var expiresAt time.Time
+		for i, teamID := range teamIDs {
+			next, err := leases[teamID].Renew(ctx)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("team %s: %w", teamID, err)
+			}
+			expiresAt = earliestLeaseExpiry(expiresAt, next, i == 0)
+		}
+		return expiresAt, nil
+	}
+	joined.Release = func(ctx context.Context) error {
+		var errs []error
+		for _, teamID := range teamIDs {
+			if release := leases[teamID].Release; release != nil {
+				if err := release(ctx); err != nil {
+					errs = append(errs, fmt.Errorf("team %s: %w", teamID, err))
+				}
+			}
+		}
+		return errors.Join(errs...)
+	}
+	return joined
+}
+
+// earliestLeaseExpiry folds next into the earliest expiry so far. An
+// unknown (zero) expiry on any team makes the whole unknown, so the lease
+// falls back to its TTL rather than trusting another team's expiry.
+func earliestLeaseExpiry(current, next time.Time, first bool) time.Time {
+	switch {
+	case first:
+		return next
+	case current.IsZero() || next.IsZero():
+		return time.Time{}
+	case next.Before(current):
+		return next
+	default:
+		return current
+	}
+}
+
+func releaseRunTeamSessionLeases(teamIDs []string, leases map[string]*awrun.SessionLease) {
+	lease := joinRunTeamSessionLeases(teamIDs, leases)
+	if lease == nil || lease.Release == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := lease.Release(ctx); err != nil {
+		debugLog("run: release session leases: %v", err)
+	}
+}
+
+// newRunTeamTaskClaims keeps each team's task claims alive through that
+// team's client and workspace.
+func newRunTeamTaskClaims(teams []runTeam) *awrun.TaskClaims {
+	type teamClaims struct {
+		teamID string
+		claims *awrun.TaskClaims
+	}
+	var all []teamClaims
+	for _, team := range teams {
+		if claims := runNewTaskClaims(team.Client, team.WorkspaceID); claims != nil {
+			all = append(all, teamClaims{teamID: team.TeamID, claims: claims})
+		}
+	}
+	if len(all) == 0 {
+		return nil
+	}
+	each := func(ctx context.Context, fn func(*awrun.TaskClaims) func(context.Context) error) error {
+		var errs []error
+		for _, team := range all {
+			if err := fn(team.claims)(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("team %s: %w", team.teamID, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+	return &awrun.TaskClaims{
+		Interval: all[0].claims.Interval,
+		Heartbeat: func(ctx context.Context) error {
+			return each(ctx, func(c *awrun.TaskClaims) func(context.Context) error { return c.Heartbeat })
+		},
+		Release: func(ctx context.Context) error {
+			return each(ctx, func(c *awrun.TaskClaims) func(context.Context) error { return c.Release })
+		},
+	}
+}
+
+// runTeamClients returns the client of every team, for work such as the
+// outbox flush that each team's certificate must do for itself.
+func runTeamClients(teams []runTeam) []*awid.Client {
+	clients := make([]*awid.Client, 0, len(teams))
+	for _, team := range teams {
+		clients = append(clients, team.Client.Client)
+	}
+	return clients
+}
+
+// newRunTeamWakeResolver resolves each wake through the client of the team
+// that delivered it, so messages are read and marked read with that team's
+// certificate.
+func newRunTeamWakeResolver(teams []runTeam) runWakeResolver {
+	resolvers := make(map[string]runWakeResolver, len(teams))
+	for _, team := range teams {
+		if resolve := newRunWakeValidator(team.Client, team.Alias); resolve != nil {
+			resolvers[strings.ToLower(team.TeamID)] = resolve
+		}
+	}
+	return func(ctx context.Context, evt awid.AgentEvent) (runWakeResolution, error) {
+		resolve, ok := resolvers[strings.ToLower(strings.TrimSpace(evt.TeamID))]
+		if !ok {
+			return runWakeResolution{CycleContext: formatFallbackCommsContext(evt)}, nil
+		}
+		return resolve(ctx, evt)
+	}
+}
+
+func formatTeamWakePreamble(teamID string) string {
+	return fmt.Sprintf("This wake came from team %s. Pass `--team %s` to the aw commands you run for it, so replies, reads, and task claims use that team's certificate.", teamID, teamID)
+}
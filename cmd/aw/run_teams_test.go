@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/awebai/aw/awid"
+	awrun "github.com/awebai/aw/run"
+	"github.com/spf13/cobra"
+)
+
+func TestRunTeamDispatcherRoutesWakesThroughTheirTeam(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	acked := map[string]string{}
+	teamServer := func(team string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == "GET" && r.URL.Path == "/v1/messages/inbox":
+				json.NewEncoder(w).Encode(awid.InboxResponse{
+					Messages: []awid.InboxMessage{{MessageID: "msg-" + team, FromAlias: "alice", Subject: "hello", Body: "from " + team}},
+				})
+			case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/ack"):
+				mu.Lock()
+				acked[team] = strings.Split(r.URL.Path, "/")[3]
+				mu.Unlock()
+				json.NewEncoder(w).Encode(awid.AckResponse{})
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	teams := []runTeam{
+		{TeamID: "backend:demo", Alias: "eve", Client: mustWebClient(t, teamServer("backend").URL)},
+		{TeamID: "ops:demo", Alias: "eve", Client: mustWebClient(t, teamServer("ops").URL)},
+	}
+	dispatcher := newRunTeamDispatcher(awrun.Settings{}, nil, teams)
+
+	decision, err := dispatcher.Next(context.Background(), false, &awid.AgentEvent{
+		Type:      awid.AgentEventActionableMail,
+		TeamID:    "ops:demo",
+		MessageID: "msg-ops",
+		FromAlias: "alice",
+		Subject:   "hello",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(decision.CycleContext, formatTeamWakePreamble("ops:demo")) || !strings.Contains(decision.CycleContext, "from ops") {
+		t.Fatalf("cycle context=%q", decision.CycleContext)
+	}
+	if len(decision.DisplayLines) == 0 || decision.DisplayLines[0].Text != "team ops:demo" {
+		t.Fatalf("display lines=%+v", decision.DisplayLines)
+	}
+	if decision.AfterDelivery == nil {
+		t.Fatal("expected post-delivery acknowledgement")
+	}
+	if err := decision.AfterDelivery(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if acked["ops"] != "msg-ops" || acked["backend"] != "" {
+		t.Fatalf("acked=%v", acked)
+	}
+}
+
+func TestRunTeamDispatcherTagsWorkWakes(t *testing.T) {
+	t.Parallel()
+
+	dispatcher := newRunTeamDispatcher(awrun.Settings{}, nil, nil)
+	decision, err := dispatcher.Next(context.Background(), true, &awid.AgentEvent{
+		Type:   awid.AgentEventWorkAvailable,
+		TeamID: "backend:demo",
+		TaskID: "t-1",
+		Title:  "Fix the build",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(decision.CycleContext, "`--team backend:demo`") || !strings.Contains(decision.CycleContext, "Fix the build") {
+		t.Fatalf("cycle context=%q", decision.CycleContext)
+	}
+}
+
+func TestRunTeamLeasesAndClaimsUseEachTeamClient(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	calls := map[string][]string{}
+	teamServer := func(team string, refuseRenew bool) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			calls[team] = append(calls[team], r.Method+" "+r.URL.Path)
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.URL.Path == "/v1/session-leases/renew" && refuseRenew:
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(`{"detail":"lease held"}`))
+			case strings.HasPrefix(r.URL.Path, "/v1/session-leases"):
+				_, _ = w.Write([]byte(`{"status":"active","session_id":"mine","generation":1,"expires_at":"2026-03-01T10:02:00Z"}`))
+			case r.URL.Path == "/v1/claims":
+				if got := r.URL.Query().Get("workspace_id"); got != "ws-"+team {
+					t.Errorf("%s workspace_id=%q", team, got)
+				}
+				_, _ = w.Write([]byte(`{"claims":[{"bead_id":"aw-` + team + `"}]}`))
+			case strings.HasPrefix(r.URL.Path, "/v1/tasks/"):
+				_, _ = w.Write([]byte(`{}`))
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	teams := []runTeam{
+		{TeamID: "backend:demo", WorkspaceID: "ws-backend", Client: mustWebClient(t, teamServer("backend", false).URL)},
+		{TeamID: "ops:demo", WorkspaceID: "ws-ops", Client: mustWebClient(t, teamServer("ops", true).URL)},
+	}
+
+	lease, err := acquireRunTeamSessionLeases(context.Background(), &cobra.Command{}, teams, false, nil)
+	if err != nil || lease == nil {
+		t.Fatalf("lease=%+v err=%v", lease, err)
+	}
+	if _, err := lease.Renew(context.Background()); err == nil || !strings.Contains(err.Error(), "ops:demo") {
+		t.Fatalf("renew err=%v, want the ops team's refusal", err)
+	} else if code, ok := awid.HTTPStatusCode(err); !ok || code != http.StatusConflict {
+		t.Fatalf("renew err=%v lost its status", err)
+	}
+	if err := lease.Release(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	claims := newRunTeamTaskClaims(teams)
+	if err := claims.Heartbeat(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, team := range []string{"backend", "ops"} {
+		got := strings.Join(calls[team], ",")
+		for _, want := range []string{
+			"POST /v1/session-leases",
+			"POST /v1/session-leases/renew",
+			"POST /v1/session-leases/release",
+			"POST /v1/tasks/aw-" + team + "/claim/heartbeat",
+		} {
+			if !strings.Contains(got+",", want+",") {
+				t.Errorf("%s calls=%s, missing %s", team, got, want)
+			}
+		}
+	}
+}
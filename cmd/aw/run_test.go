@@ -90,6 +90,7 @@ func TestRunBuildsLoopOptionsFromConfigAndFlags(t *testing.T) {
 	oldNewLoop := runNewLoop
 	oldExecuteLoop := runExecuteLoop
 	oldNewEventBus := runNewEventBus
+	stubRunSessionLease(t)
 	oldNewScreen := runNewScreenController
 	oldWorkspaceState := runWorkspaceStateForDir
 	oldResolveClaimedTaskRef := runResolveClaimedTaskRef
@@ -145,7 +146,7 @@ func TestRunBuildsLoopOptionsFromConfigAndFlags(t *testing.T) {
 	runWorkspaceStateForDir = func(dir string) (runWorkspaceState, error) {
 		return runWorkspaceStateInitialized, nil
 	}
-	runNewEventBus = func(client *aweb.Client) *awrun.EventBus {
+	runNewEventBus = func(client *aweb.Client, workingDir string) *awrun.EventBus {
 		if client == nil {
 			t.Fatal("expected client for event bus")
 		}
@@ -203,6 +204,9 @@ func TestRunBuildsLoopOptionsFromConfigAndFlags(t *testing.T) {
 	if capturedLoop.Dispatch == nil {
 		t.Fatal("expected run loop to have a dispatcher")
 	}
+	if capturedLoop.TaskClaims == nil {
+		t.Fatal("expected run loop to keep the workspace's task claims")
+	}
 	if capturedLoop.OnUserPrompt == nil || capturedLoop.OnRunComplete == nil {
 		t.Fatal("expected interaction log hooks on loop")
 	}
@@ -310,6 +314,7 @@ func TestRunAllowsEmptyPromptWhenInteractiveScreenIsAvailable(t *testing.T) {
 	oldNewLoop := runNewLoop
 	oldExecuteLoop := runExecuteLoop
 	oldNewEventBus := runNewEventBus
+	stubRunSessionLease(t)
 	oldNewScreen := runNewScreenController
 	oldWorkspaceState := runWorkspaceStateForDir
 	t.Cleanup(func() {
@@ -336,7 +341,7 @@ func TestRunAllowsEmptyPromptWhenInteractiveScreenIsAvailable(t *testing.T) {
 		return &aweb.Client{}, &awconfig.Selection{Domain: "team", Alias: "rose"}, nil
 	}
 	runWorkspaceStateForDir = func(string) (runWorkspaceState, error) { return runWorkspaceStateInitialized, nil }
-	runNewEventBus = func(client *aweb.Client) *awrun.EventBus { return nil }
+	runNewEventBus = func(client *aweb.Client, workingDir string) *awrun.EventBus { return nil }
 	runNewScreenController = func(in io.Reader, out io.Writer) *awrun.ScreenController {
 		return &awrun.ScreenController{}
 	}
@@ -377,6 +382,7 @@ func TestRunDefaultsCodexToNonPTYWhenInteractive(t *testing.T) {
 	oldNewLoop := runNewLoop
 	oldExecuteLoop := runExecuteLoop
 	oldNewEventBus := runNewEventBus
+	stubRunSessionLease(t)
 	oldNewScreen := runNewScreenController
 	oldWorkspaceState := runWorkspaceStateForDir
 	t.Cleanup(func() {
@@ -403,7 +409,7 @@ func TestRunDefaultsCodexToNonPTYWhenInteractive(t *testing.T) {
 		return &aweb.Client{}, &awconfig.Selection{Domain: "team", Alias: "rose"}, nil
 	}
 	runWorkspaceStateForDir = func(string) (runWorkspaceState, error) { return runWorkspaceStateInitialized, nil }
-	runNewEventBus = func(client *aweb.Client) *awrun.EventBus { return nil }
+	runNewEventBus = func(client *aweb.Client, workingDir string) *awrun.EventBus { return nil }
 	runNewScreenController = func(in io.Reader, out io.Writer) *awrun.ScreenController {
 		return &awrun.ScreenController{}
 	}
@@ -441,6 +447,7 @@ func TestRunHonorsExplicitCodexPTYOverride(t *testing.T) {
 	oldNewLoop := runNewLoop
 	oldExecuteLoop := runExecuteLoop
 	oldNewEventBus := runNewEventBus
+	stubRunSessionLease(t)
 	oldNewScreen := runNewScreenController
 	oldWorkspaceState := runWorkspaceStateForDir
 	t.Cleanup(func() {
@@ -467,7 +474,7 @@ func TestRunHonorsExplicitCodexPTYOverride(t *testing.T) {
 		return &aweb.Client{}, &awconfig.Selection{Domain: "team", Alias: "rose"}, nil
 	}
 	runWorkspaceStateForDir = func(string) (runWorkspaceState, error) { return runWorkspaceStateInitialized, nil }
-	runNewEventBus = func(client *aweb.Client) *awrun.EventBus { return nil }
+	runNewEventBus = func(client *aweb.Client, workingDir string) *awrun.EventBus { return nil }
 	runNewScreenController = func(in io.Reader, out io.Writer) *awrun.ScreenController {
 		return &awrun.ScreenController{}
 	}
@@ -548,6 +555,7 @@ func TestRunInteractiveOnboardsBeforeRunning(t *testing.T) {
 	oldNewLoop := runNewLoop
 	oldExecuteLoop := runExecuteLoop
 	oldNewEventBus := runNewEventBus
+	stubRunSessionLease(t)
 	oldWorkspaceState := runWorkspaceStateForDir
 	oldWizard := guidedOnboardingWizard
 	t.Cleanup(func() {
@@ -587,7 +595,7 @@ func TestRunInteractiveOnboardsBeforeRunning(t *testing.T) {
 	runExecuteLoop = func(loop *awrun.Loop, ctx context.Context, opts awrun.LoopOptions) error {
 		return nil
 	}
-	runNewEventBus = func(client *aweb.Client) *awrun.EventBus { return nil }
+	runNewEventBus = func(client *aweb.Client, workingDir string) *awrun.EventBus { return nil }
 
 	var capturedReq guidedOnboardingRequest
 	guidedOnboardingWizard = func(req guidedOnboardingRequest) (*guidedOnboardingResult, error) {
@@ -632,7 +640,7 @@ func TestNewRunDispatcherBuildsMailPrompt(t *testing.T) {
 	dispatcher := newRunDispatcher(awrun.Settings{
 		WorkPromptSuffix:  "work suffix",
 		CommsPromptSuffix: "comms suffix",
-	}, func(context.Context, awid.AgentEvent) (runWakeResolution, error) {
+	}, nil, func(context.Context, awid.AgentEvent) (runWakeResolution, error) {
 		return runWakeResolution{CycleContext: "● from mia (mail): API review — please take a look"}, nil
 	})
 
@@ -685,7 +693,7 @@ func TestFormatIncomingChatContextIndentsMultiLineBodyUnderAlias(t *testing.T) {
 func TestNewRunDispatcherBuildsActionableChatPrompt(t *testing.T) {
 	dispatcher := newRunDispatcher(awrun.Settings{
 		CommsPromptSuffix: "comms suffix",
-	}, func(context.Context, awid.AgentEvent) (runWakeResolution, error) {
+	}, nil, func(context.Context, awid.AgentEvent) (runWakeResolution, error) {
 		return runWakeResolution{CycleContext: "● from henry (chat): ping"}, nil
 	})
 
@@ -712,7 +720,7 @@ func TestNewRunDispatcherBuildsActionableChatPrompt(t *testing.T) {
 }
 
 func TestNewRunDispatcherBuildsIdleActionableChatPrompt(t *testing.T) {
-	dispatcher := newRunDispatcher(awrun.Settings{}, func(context.Context, awid.AgentEvent) (runWakeResolution, error) {
+	dispatcher := newRunDispatcher(awrun.Settings{}, nil, func(context.Context, awid.AgentEvent) (runWakeResolution, error) {
 		return runWakeResolution{CycleContext: "● from rose (chat): when you have a moment"}, nil
 	})
 
@@ -871,7 +879,7 @@ func TestRunUsesAppEventWakeToTriggerSecondCycle(t *testing.T) {
 func TestNewRunDispatcherSkipsWorkWakeWithoutAutofeed(t *testing.T) {
 	dispatcher := newRunDispatcher(awrun.Settings{
 		WorkPromptSuffix: "work suffix",
-	}, nil)
+	}, nil, nil)
 
 	decision, err := dispatcher.Next(context.Background(), false, &awid.AgentEvent{
 		Type:   awid.AgentEventWorkAvailable,
@@ -887,7 +895,7 @@ func TestNewRunDispatcherSkipsWorkWakeWithoutAutofeed(t *testing.T) {
 }
 
 func TestNewRunDispatcherBuildsTaskActivityDisplayForWorkWake(t *testing.T) {
-	dispatcher := newRunDispatcher(awrun.Settings{}, nil)
+	dispatcher := newRunDispatcher(awrun.Settings{}, nil, nil)
 
 	decision, err := dispatcher.Next(context.Background(), true, &awid.AgentEvent{
 		Type:   awid.AgentEventClaimUpdate,
@@ -910,7 +918,7 @@ func TestNewRunDispatcherBuildsTaskActivityDisplayForWorkWake(t *testing.T) {
 }
 
 func TestNewRunDispatcherSkipsStaleActionableChat(t *testing.T) {
-	dispatcher := newRunDispatcher(awrun.Settings{}, func(context.Context, awid.AgentEvent) (runWakeResolution, error) {
+	dispatcher := newRunDispatcher(awrun.Settings{}, nil, func(context.Context, awid.AgentEvent) (runWakeResolution, error) {
 		return runWakeResolution{Skip: true}, nil
 	})
 
@@ -1507,6 +1515,7 @@ func TestRunContinuePrintsRecentInteractionRecap(t *testing.T) {
 	oldNewLoop := runNewLoop
 	oldExecuteLoop := runExecuteLoop
 	oldNewEventBus := runNewEventBus
+	stubRunSessionLease(t)
 	oldNewScreen := runNewScreenController
 	t.Cleanup(func() {
 		runLoadUserConfig = oldLoad
@@ -1544,7 +1553,7 @@ func TestRunContinuePrintsRecentInteractionRecap(t *testing.T) {
 		return &aweb.Client{}, &awconfig.Selection{Domain: "team", Alias: "rose"}, nil
 	}
 	runWorkspaceStateForDir = func(string) (runWorkspaceState, error) { return runWorkspaceStateInitialized, nil }
-	runNewEventBus = func(client *aweb.Client) *awrun.EventBus { return nil }
+	runNewEventBus = func(client *aweb.Client, workingDir string) *awrun.EventBus { return nil }
 	runNewScreenController = func(in io.Reader, out io.Writer) *awrun.ScreenController { return nil }
 	runNewLoop = func(provider awrun.Provider, out io.Writer) *awrun.Loop {
 		return awrun.NewLoop(provider, out)
@@ -1637,6 +1646,7 @@ func TestRunPrintsContinueAndProviderCommandsOnExit(t *testing.T) {
 	oldNewLoop := runNewLoop
 	oldExecuteLoop := runExecuteLoop
 	oldNewEventBus := runNewEventBus
+	stubRunSessionLease(t)
 	oldNewScreen := runNewScreenController
 	oldWorkspaceState := runWorkspaceStateForDir
 	t.Cleanup(func() {
@@ -1664,7 +1674,7 @@ func TestRunPrintsContinueAndProviderCommandsOnExit(t *testing.T) {
 		return &aweb.Client{}, &awconfig.Selection{Domain: "team", Alias: "rose"}, nil
 	}
 	runWorkspaceStateForDir = func(string) (runWorkspaceState, error) { return runWorkspaceStateInitialized, nil }
-	runNewEventBus = func(client *aweb.Client) *awrun.EventBus { return nil }
+	runNewEventBus = func(client *aweb.Client, workingDir string) *awrun.EventBus { return nil }
 	runNewScreenController = func(in io.Reader, out io.Writer) *awrun.ScreenController { return nil }
 	runNewLoop = func(provider awrun.Provider, out io.Writer) *awrun.Loop {
 		return awrun.NewLoop(provider, out)
@@ -1736,3 +1746,13 @@ func (c *cobraCommandClone) ResetFlagsForTest() {
 	c.Command.Flags().BoolVar(&runAutofeedWork, "autofeed-work", false, "")
 	c.Command.Flags().BoolVar(&runInitConfig, "init", false, "")
 }
+
+// stubRunSessionLease keeps runRun tests from acquiring a real session lease.
+func stubRunSessionLease(t *testing.T) {
+	t.Helper()
+	old := runAcquireSessionLease
+	runAcquireSessionLease = func(context.Context, *cobra.Command, *aweb.Client, bool, io.Reader) (*awrun.SessionLease, error) {
+		return nil, nil
+	}
+	t.Cleanup(func() { runAcquireSessionLease = old })
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awid"
+)
+
+const (
+	// signerSSHAgentEnv names an ssh-agent protocol socket holding the
+	// identity's Ed25519 key. aw asks it for every signature and never
+	// reads signing.key.
+	signerSSHAgentEnv = "AW_SIGNER_SSH_AUTH_SOCK"
+	// signerCommandEnv names an external signing command, split into words
+	// like a shell would (quotes and backslashes, no expansion). It gets the
+	// payload on stdin and prints the base64 signature on stdout.
+	signerCommandEnv = "AW_SIGNER_COMMAND"
+)
+
+// loadClientSigner returns the signer for the identity did whose key file is
+// signingKeyPath: an external signer when one is configured, else a running
+// aw agentd holding the key, else the key file itself. An empty did is read
+// from the .pub file beside the key when there is one.
+func loadClientSigner(signingKeyPath, did string) (awid.Signer, error) {
+	signer, err := configuredExternalSigner(func() (ed25519.PublicKey, error) {
+		return externalSignerPublicKey(signingKeyPath, did)
+	})
+	if err != nil || signer != nil {
+		return signer, err
+	}
+	if strings.TrimSpace(did) == "" {
+		if pub, err := awid.LoadPublicKey(awid.PublicKeyPath(signingKeyPath)); err == nil {
+			did = awid.ComputeDIDKey(pub)
+		}
+	}
+	if signer := agentdSigner(did); signer != nil {
+		return signer, nil
+	}
+	key, err := awid.LoadSigningKey(signingKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	return awid.NewKeySigner(key), nil
+}
+
+// externalSignerConfigured reports whether signerSSHAgentEnv or
+// signerCommandEnv is set.
+func externalSignerConfigured() bool {
+	return strings.TrimSpace(os.Getenv(signerSSHAgentEnv)) != "" || strings.TrimSpace(os.Getenv(signerCommandEnv)) != ""
+}
+
+// configuredExternalSigner returns the signer named by signerSSHAgentEnv or
+// signerCommandEnv for the key pub returns, or nil when neither is set.
+func configuredExternalSigner(pub func() (ed25519.PublicKey, error)) (awid.Signer, error) {
+	socket := strings.TrimSpace(os.Getenv(signerSSHAgentEnv))
+	command, err := splitShellWords(os.Getenv(signerCommandEnv))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", signerCommandEnv, err)
+	}
+	if socket == "" && len(command) == 0 {
+		return nil, nil
+	}
+	if socket != "" && len(command) > 0 {
+		return nil, fmt.Errorf("set only one of %s and %s", signerSSHAgentEnv, signerCommandEnv)
+	}
+	key, err := pub()
+	if err != nil {
+		return nil, err
+	}
+	if socket != "" {
+		return awid.NewSSHAgentSigner(socket, key)
+	}
+	return awid.NewCommandSigner(command, key)
+}
+
+// loadAuthoritySigner returns a signer for a namespace controller or team
+// key whose did:key is did: a running aw agentd holding it, else the key
+// file read by load, else the configured external signer when there is no
+// key file. With an empty did only the key file is tried.
+func loadAuthoritySigner(did string, load func() (ed25519.PrivateKey, error)) (crypto.Signer, error) {
+	if signer := agentdSigner(did); signer != nil {
+		return awid.CryptoSigner(signer), nil
+	}
+	key, err := load()
+	if err == nil {
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) || strings.TrimSpace(did) == "" {
+		return nil, err
+	}
+	signer, extErr := configuredExternalSigner(func() (ed25519.PublicKey, error) {
+		return awid.ExtractPublicKey(strings.TrimSpace(did))
+	})
+	if extErr != nil {
+		return nil, extErr
+	}
+	if signer == nil {
+		return nil, err
+	}
+	return awid.CryptoSigner(signer), nil
+}
+
+// loadControllerSigner returns the signer for domain's namespace controller
+// key. The controller did:key comes from its local metadata.
+func loadControllerSigner(domain string) (crypto.Signer, error) {
+	did := ""
+	if meta, err := awconfig.LoadControllerMeta(domain); err == nil && meta != nil {
+		did = meta.ControllerDID
+	}
+	return loadAuthoritySigner(did, func() (ed25519.PrivateKey, error) {
+		return awconfig.LoadControllerKey(domain)
+	})
+}
+
+// controllerKeyAvailable reports whether domain's controller key can sign:
+// its key file exists, or its recorded did:key is held by aw agentd or left
+// to a configured external signer.
+func controllerKeyAvailable(domain string) (bool, error) {
+	exists, err := awconfig.ControllerKeyExists(domain)
+	if err != nil || exists {
+		return exists, err
+	}
+	meta, err := awconfig.LoadControllerMeta(domain)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	did := strings.TrimSpace(meta.ControllerDID)
+	if did == "" {
+		return false, nil
+	}
+	if agentdSigner(did) != nil {
+		return true, nil
+	}
+	return externalSignerConfigured(), nil
+}
+
+// loadTeamSigner returns the signer for the team controller key of
+// domain/name. Team keys carry no local metadata, so when aw agentd or an
+// external signer could hold the key, its did:key is read from the team's
+// registry record.
+func loadTeamSigner(ctx context.Context, domain, name string) (crypto.Signer, error) {
+	did := ""
+	if authoritySignerReachable() {
+		did = registeredTeamDIDKey(ctx, domain, name)
+	}
+	return loadAuthoritySigner(did, func() (ed25519.PrivateKey, error) {
+		return awconfig.LoadTeamKey(domain, name)
+	})
+}
+
+// authoritySignerReachable reports whether anything but a key file could
+// sign for an authority key: a running aw agentd or a configured external
+// signer.
+func authoritySignerReachable() bool {
+	if externalSignerConfigured() {
+		return true
+	}
+	path, err := agentdSocketPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+func registeredTeamDIDKey(ctx context.Context, domain, name string) string {
+	registry, err := newRegistryClientWithPreferredBaseURL("")
+	if err != nil {
+		return ""
+	}
+	registryURL, err := registry.DiscoverRegistry(ctx, domain)
+	if err != nil {
+		return ""
+	}
+	team, err := registry.GetTeam(ctx, registryURL, domain, name, nil)
+	if err != nil {
+		debugLog("team key: look up %s/%s: %v", domain, name, err)
+		return ""
+	}
+	return strings.TrimSpace(team.TeamDIDKey)
+}
+
+// signWithSigner signs payload with key, which may be held out of process.
+func signWithSigner(key crypto.Signer, payload []byte) ([]byte, error) {
+	return key.Sign(nil, payload, crypto.Hash(0))
+}
+
+// externalSignerPublicKey finds the public key an external signer must sign
+// for: the identity's did:key, or the .pub file beside the key path.
+func externalSignerPublicKey(signingKeyPath, did string) (ed25519.PublicKey, error) {
+	if did = strings.TrimSpace(did); did != "" {
+		return awid.ExtractPublicKey(did)
+	}
+	pub, err := awid.LoadPublicKey(awid.PublicKeyPath(signingKeyPath))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("external signer configured but the identity's did:key is unknown: %w", err)
+		}
+		return nil, err
+	}
+	return pub, nil
+}
+
+// splitShellWords splits s into words the way a POSIX shell would, honoring
+// single quotes, double quotes and backslash escapes but expanding nothing.
+func splitShellWords(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			// Inside double quotes a backslash only escapes the characters
+			// a shell would treat specially there.
+			if quote == '"' && !strings.ContainsRune("$`\"\\\n", r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\' && quote == 0:
+			escaped, inWord = true, true
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if escaped || quote != 0 {
+		return nil, errors.New("unterminated quote or escape")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
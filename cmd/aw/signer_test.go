@@ -0,0 +1,140 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awid"
+)
+
+func TestLoadClientSignerUsesExternalSignerWithoutKeyFile(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	did := awid.ComputeDIDKey(pub)
+	missingKey := filepath.Join(t.TempDir(), "signing.key")
+
+	t.Setenv(signerSSHAgentEnv, "")
+	t.Setenv(signerCommandEnv, "my-signer --key agent")
+	signer, err := loadClientSigner(missingKey, did)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := signer.(*awid.CommandSigner); !ok {
+		t.Fatalf("signer=%T, want *awid.CommandSigner", signer)
+	}
+	if !signer.PublicKey().Equal(pub) {
+		t.Fatal("signer public key does not match did:key")
+	}
+
+	t.Setenv(signerSSHAgentEnv, filepath.Join(t.TempDir(), "agent.sock"))
+	if _, err := loadClientSigner(missingKey, did); err == nil || !strings.Contains(err.Error(), "only one of") {
+		t.Fatalf("err=%v, want conflict", err)
+	}
+
+	t.Setenv(signerCommandEnv, "")
+	signer, err = loadClientSigner(missingKey, did)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := signer.(*awid.SSHAgentSigner); !ok {
+		t.Fatalf("signer=%T, want *awid.SSHAgentSigner", signer)
+	}
+}
+
+func TestLoadClientSignerDefaultsToKeyFile(t *testing.T) {
+	t.Setenv(signerSSHAgentEnv, "")
+	t.Setenv(signerCommandEnv, "")
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "signing.key")
+	if err := awid.SaveSigningKey(path, priv); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := loadClientSigner(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !signer.PublicKey().Equal(pub) {
+		t.Fatal("signer public key does not match signing.key")
+	}
+}
+
+func TestSplitShellWordsHonorsQuotes(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want []string
+	}{
+		{`my-signer --key agent`, []string{"my-signer", "--key", "agent"}},
+		{`"/opt/my signer/bin" --key 'a b'`, []string{"/opt/my signer/bin", "--key", "a b"}},
+		{`/opt/my\ signer "say \"hi\"" "C:\path"`, []string{"/opt/my signer", `say "hi"`, `C:\path`}},
+		{`a ''  ""`, []string{"a", "", ""}},
+		{"  ", nil},
+	} {
+		got, err := splitShellWords(tc.in)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.in, err)
+		}
+		if strings.Join(got, "|") != strings.Join(tc.want, "|") || len(got) != len(tc.want) {
+			t.Fatalf("%q: got %q, want %q", tc.in, got, tc.want)
+		}
+	}
+	for _, in := range []string{`"open`, `'open`, `trailing\`} {
+		if _, err := splitShellWords(in); err == nil {
+			t.Fatalf("%q: expected an error", in)
+		}
+	}
+}
+
+func TestLoadControllerSignerFallsBackToExternalSigner(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(signerSSHAgentEnv, "")
+	t.Setenv(signerCommandEnv, "")
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	did := awid.ComputeDIDKey(pub)
+	if err := awconfig.SaveControllerMeta("example.com", &awconfig.ControllerMeta{Domain: "example.com", ControllerDID: did}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := controllerKeyAvailable("example.com"); err != nil || ok {
+		t.Fatalf("available=%v err=%v without key file or signer", ok, err)
+	}
+
+	t.Setenv(signerCommandEnv, `"/opt/my signer" --key controller`)
+	if ok, err := controllerKeyAvailable("example.com"); err != nil || !ok {
+		t.Fatalf("available=%v err=%v with external signer", ok, err)
+	}
+	signer, err := loadControllerSigner("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := signer.(ed25519.PrivateKey); ok {
+		t.Fatal("signer is a raw key, want the external signer")
+	}
+	if !pub.Equal(signer.Public()) {
+		t.Fatal("signer public key does not match the controller did:key")
+	}
+
+	if err := awconfig.SaveControllerKey("example.com", priv); err != nil {
+		t.Fatal(err)
+	}
+	signer, err = loadControllerSigner("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signWithSigner(signer, []byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(pub, []byte("payload"), sig) {
+		t.Fatal("key file signature does not verify")
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	aweb "github.com/awebai/aw"
+	"github.com/awebai/aw/awid"
+	"github.com/spf13/cobra"
+)
+
+var taskClaimCmd = &cobra.Command{
+	Use:   "claim <ref>",
+	Short: "Claim a task for this workspace and branch",
+	Long: `Claim a task for this workspace and its current git branch, moving it
+to in_progress. aw run keeps the claims of its workspace alive and releases
+them when it exits.
+
+A task claimed by a teammate is refused. With --reclaim-after, a claim whose
+owner has not been seen for that long is taken over instead, and the previous
+owner is told by mail.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTaskClaim,
+}
+
+var taskReleaseCmd = &cobra.Command{
+	Use:   "release <ref>",
+	Short: "Release this workspace's claim on a task",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTaskRelease,
+}
+
+var taskHandoffCmd = &cobra.Command{
+	Use:   "handoff <ref> <alias>",
+	Short: "Hand this workspace's claim on a task to a teammate",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runTaskHandoff,
+}
+
+func init() {
+	taskClaimCmd.Flags().Duration("reclaim-after", 0, "Take over a claim whose owner has not been seen for this long (e.g. 30m)")
+	taskReleaseCmd.Flags().String("reason", "", "Why the task is being released")
+	taskHandoffCmd.Flags().String("note", "", "Note for the teammate taking over")
+	taskCmd.AddCommand(taskClaimCmd)
+	taskCmd.AddCommand(taskReleaseCmd)
+	taskCmd.AddCommand(taskHandoffCmd)
+}
+
+type taskClaimOutput struct {
+	*aweb.TaskClaimView
+	PreviousOwnerNotified bool `json:"previous_owner_notified,omitempty"`
+}
+
+func runTaskClaim(cmd *cobra.Command, args []string) error {
+	ref := args[0]
+	reclaimAfter, _ := cmd.Flags().GetDuration("reclaim-after")
+	if reclaimAfter < 0 {
+		return usageError("--reclaim-after must not be negative")
+	}
+
+	client, sel, err := resolveClientSelection()
+	if err != nil {
+		return err
+	}
+	wd, _ := os.Getwd()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	view, err := client.TaskClaim(ctx, ref, &aweb.TaskClaimRequest{
+		WorkspaceID:       strings.TrimSpace(sel.WorkspaceID),
+		Branch:            discoverGitBranch(wd),
+		StaleAfterSeconds: int(reclaimAfter / time.Second),
+	})
+	if err != nil {
+		var held *aweb.TaskHeldError
+		if errors.As(err, &held) {
+			if reclaimAfter > 0 {
+				return fmt.Errorf("task %s is held by %s, who was seen within the last %s", ref, taskHolderLabel(held), reclaimAfter)
+			}
+			return fmt.Errorf("task %s is held by %s; pass --reclaim-after to take it over once its owner has gone quiet", ref, taskHolderLabel(held))
+		}
+		return fmt.Errorf("claiming task %s: %w", ref, err)
+	}
+
+	out := taskClaimOutput{TaskClaimView: view}
+	if view.PreviousOwner != nil {
+		if err := notifyReclaimedTaskOwner(ctx, client, view, sel.Alias); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not tell %s that task %s was reclaimed: %v\n", view.PreviousOwner.Alias, view.TaskRef, err)
+		} else {
+			out.PreviousOwnerNotified = true
+		}
+	}
+
+	printOutput(out, func(any) string {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "✓ Claimed %s: %s\n", view.TaskRef, view.Title)
+		if view.Branch != "" {
+			fmt.Fprintf(&sb, "  Branch: %s\n", view.Branch)
+		}
+		if prev := view.PreviousOwner; prev != nil {
+			fmt.Fprintf(&sb, "  Reclaimed from %s, last seen %s", prev.Alias, taskOwnerLastSeen(prev))
+			if out.PreviousOwnerNotified {
+				sb.WriteString("; notified by mail")
+			}
+			sb.WriteString("\n")
+		}
+		return sb.String()
+	})
+	return nil
+}
+
+func runTaskRelease(cmd *cobra.Command, args []string) error {
+	ref := args[0]
+	reason, _ := cmd.Flags().GetString("reason")
+
+	client, sel, err := resolveClientSelection()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if err := client.TaskRelease(ctx, ref, &aweb.TaskReleaseRequest{
+		WorkspaceID: strings.TrimSpace(sel.WorkspaceID),
+		Reason:      strings.TrimSpace(reason),
+	}); err != nil {
+		var held *aweb.TaskHeldError
+		if errors.As(err, &held) {
+			return fmt.Errorf("task %s is claimed by %s, not this workspace", ref, taskHolderLabel(held))
+		}
+		return fmt.Errorf("releasing task %s: %w", ref, err)
+	}
+
+	printOutput(map[string]string{"released": ref}, func(any) string {
+		return fmt.Sprintf("✓ Released %s\n", ref)
+	})
+	return nil
+}
+
+func runTaskHandoff(cmd *cobra.Command, args []string) error {
+	ref := args[0]
+	toAlias := strings.TrimSpace(args[1])
+	if toAlias == "" {
+		return usageError("handoff needs the alias of the teammate taking over")
+	}
+	note, _ := cmd.Flags().GetString("note")
+
+	client, sel, err := resolveClientSelection()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	view, err := client.TaskHandoff(ctx, ref, &aweb.TaskHandoffRequest{
+		WorkspaceID: strings.TrimSpace(sel.WorkspaceID),
+		ToAlias:     toAlias,
+		Note:        strings.TrimSpace(note),
+	})
+	if err != nil {
+		var held *aweb.TaskHeldError
+		if errors.As(err, &held) {
+			return fmt.Errorf("task %s is claimed by %s, not this workspace", ref, taskHolderLabel(held))
+		}
+		return fmt.Errorf("handing off task %s: %w", ref, err)
+	}
+
+	printOutput(view, func(any) string {
+		return fmt.Sprintf("✓ Handed %s to %s: %s\n", view.TaskRef, firstNonEmpty(view.OwnerAlias, toAlias), view.Title)
+	})
+	return nil
+}
+
+// notifyReclaimedTaskOwner tells the previous owner of a reclaimed task by
+// mail, so an agent that comes back does not keep working on it.
+func notifyReclaimedTaskOwner(ctx context.Context, client *aweb.Client, view *aweb.TaskClaimView, claimer string) error {
+	prev := view.PreviousOwner
+	body := fmt.Sprintf("%s reclaimed task %s (%s). You held it, but were last seen %s. If you are still working on it, talk to %s before you continue.",
+		claimer, view.TaskRef, view.Title, taskOwnerLastSeen(prev), claimer)
+	_, err := client.SendMessage(ctx, &awid.SendMessageRequest{
+		ToAlias: prev.Alias,
+		Subject: "Task " + view.TaskRef + " was reclaimed",
+		Body:    body,
+	})
+	return err
+}
+
+func taskOwnerLastSeen(owner *aweb.TaskClaimOwner) string {
+	if strings.TrimSpace(owner.LastSeenAt) == "" {
+		return "a while ago"
+	}
+	return formatTimeAgo(owner.LastSeenAt)
+}
+
+func taskHolderLabel(held *aweb.TaskHeldError) string {
+	return firstNonEmpty(strings.TrimSpace(held.AssigneeAlias), "another agent")
+}
+
+// discoverGitBranch returns the branch checked out in workingDir, or "" when
+// it is not a git checkout or HEAD is detached.
+func discoverGitBranch(workingDir string) string {
+	out, err := exec.Command("git", "-C", workingDir, "symbolic-ref", "--quiet", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
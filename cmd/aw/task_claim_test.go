@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	aweb "github.com/awebai/aw"
+	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awebtest"
+	"github.com/awebai/aw/awid"
+)
+
+func TestAwTaskClaimReclaimsStaleTaskAndMailsPreviousOwner(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var gotClaim aweb.TaskClaimRequest
+	var gotMail map[string]any
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/tasks/aw-042/claim":
+			mu.Lock()
+			_ = json.NewDecoder(r.Body).Decode(&gotClaim)
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(aweb.TaskClaimView{
+				TaskRef:       "aw-042",
+				Title:         "Fix the build",
+				Status:        "in_progress",
+				OwnerAlias:    "alice",
+				Branch:        gotClaim.Branch,
+				PreviousOwner: &aweb.TaskClaimOwner{Alias: "bob", LastSeenAt: time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)},
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/messages":
+			mu.Lock()
+			_ = json.NewDecoder(r.Body).Decode(&gotMail)
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(awid.SendMessageResponse{MessageID: "msg-1", Status: "delivered"})
+		case r.URL.Path == "/v1/agents/heartbeat":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeDefaultWorkspaceBindingForTest(t, tmp, server.URL)
+
+	run := exec.CommandContext(ctx, bin, "task", "claim", "aw-042", "--reclaim-after", "30m")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err != nil {
+		t.Fatalf("run failed: %v\n%s", err, string(out))
+	}
+	text := string(out)
+	if !strings.Contains(text, "Claimed aw-042") || !strings.Contains(text, "Reclaimed from bob, last seen 2h ago; notified by mail") {
+		t.Fatalf("output:\n%s", text)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if gotClaim.WorkspaceID != "workspace-1" || gotClaim.StaleAfterSeconds != 1800 {
+		t.Fatalf("claim request=%+v", gotClaim)
+	}
+	if gotMail["to_alias"] != "bob" || !strings.Contains(gotMail["subject"].(string), "aw-042") {
+		t.Fatalf("mail=%v", gotMail)
+	}
+}
+
+func TestAwTaskClaimHeldTaskSuggestsReclaim(t *testing.T) {
+	t.Parallel()
+
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/v1/tasks/aw-042/claim" {
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(aweb.TaskHeldError{Detail: "task already held", AssigneeAlias: "bob"})
+			return
+		}
+		http.NotFound(w, r)
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeDefaultWorkspaceBindingForTest(t, tmp, server.URL)
+
+	run := exec.CommandContext(ctx, bin, "task", "claim", "aw-042")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected held task to be refused:\n%s", string(out))
+	}
+	if !strings.Contains(string(out), "held by bob") || !strings.Contains(string(out), "--reclaim-after") {
+		t.Fatalf("output:\n%s", string(out))
+	}
+}
+
+func TestAwTaskHandoffSendsWorkspace(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var gotHandoff aweb.TaskHandoffRequest
+	server := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/v1/tasks/aw-042/claim/handoff" {
+			mu.Lock()
+			_ = json.NewDecoder(r.Body).Decode(&gotHandoff)
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(aweb.TaskClaimView{TaskRef: "aw-042", Title: "Fix the build", OwnerAlias: "bob"})
+			return
+		}
+		if r.URL.Path == "/v1/agents/heartbeat" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "aw")
+	buildAwBinary(t, ctx, bin)
+	writeDefaultWorkspaceBindingForTest(t, tmp, server.URL)
+
+	run := exec.CommandContext(ctx, bin, "task", "handoff", "aw-042", "bob", "--note", "tests pass")
+	run.Env = testCommandEnv(tmp)
+	run.Dir = tmp
+	out, err := run.CombinedOutput()
+	if err != nil {
+		t.Fatalf("run failed: %v\n%s", err, string(out))
+	}
+	if !strings.Contains(string(out), "Handed aw-042 to bob") {
+		t.Fatalf("output:\n%s", string(out))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if gotHandoff.WorkspaceID != "workspace-1" || gotHandoff.ToAlias != "bob" || gotHandoff.Note != "tests pass" {
+		t.Fatalf("handoff request=%+v", gotHandoff)
+	}
+}
+
+func TestAwTaskClaimReleaseHandoffAgainstAwebtest(t *testing.T) {
+	t.Parallel()
+
+	srv := awebtest.NewServer()
+	t.Cleanup(srv.Close)
+	team, err := srv.AddTeam("backend:demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	srv.SetClock(func() time.Time { return now })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	bin := filepath.Join(t.TempDir(), "aw")
+	buildAwBinary(t, ctx, bin)
+
+	workspaces := map[string]string{}
+	for _, alias := range []string{"alice", "bob", "carol"} {
+		agent, err := team.AddAgent(alias)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := t.TempDir()
+		writeSelectionFixtureForTest(t, dir, testSelectionFixture{
+			AwebURL:       srv.URL,
+			TeamID:        team.ID,
+			Alias:         alias,
+			WorkspaceID:   "ws-" + alias,
+			DID:           agent.DID,
+			Custody:       awid.CustodySelf,
+			IdentityScope: awid.IdentityModeLocal,
+			SigningKey:    agent.SigningKey,
+			CreatedAt:     "2026-04-04T00:00:00Z",
+		})
+		if _, err := awconfig.SaveTeamCertificateForTeam(dir, team.ID, agent.Certificate); err != nil {
+			t.Fatal(err)
+		}
+		workspaces[alias] = dir
+	}
+	aw := func(alias string, args ...string) (string, error) {
+		run := exec.CommandContext(ctx, bin, args...)
+		run.Env = testCommandEnv(workspaces[alias])
+		run.Dir = workspaces[alias]
+		out, err := run.CombinedOutput()
+		return string(out), err
+	}
+
+	alice, _ := team.Agent("alice")
+	aliceClient, err := alice.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := aliceClient.TaskCreate(ctx, &aweb.TaskCreateRequest{Title: "Fix the build"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out, err := aw("alice", "task", "claim", task.TaskRef); err != nil || !strings.Contains(out, "Claimed "+task.TaskRef) {
+		t.Fatalf("alice claim err=%v\n%s", err, out)
+	}
+	if out, err := aw("bob", "task", "claim", task.TaskRef, "--reclaim-after", "30m"); err == nil || !strings.Contains(out, "held by alice") {
+		t.Fatalf("bob claim of a fresh task err=%v\n%s", err, out)
+	}
+	if out, err := aw("bob", "task", "release", task.TaskRef); err == nil || !strings.Contains(out, "claimed by alice") {
+		t.Fatalf("bob release of alice's claim err=%v\n%s", err, out)
+	}
+
+	now = now.Add(time.Hour)
+	out, err := aw("bob", "task", "claim", task.TaskRef, "--reclaim-after", "30m")
+	if err != nil || !strings.Contains(out, "Reclaimed from alice") || !strings.Contains(out, "notified by mail") {
+		t.Fatalf("bob stale reclaim err=%v\n%s", err, out)
+	}
+	inbox, err := aliceClient.Inbox(ctx, awid.InboxParams{UnreadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inbox.Messages) != 1 || !strings.Contains(inbox.Messages[0].Subject, task.TaskRef) {
+		t.Fatalf("alice inbox=%+v", inbox.Messages)
+	}
+
+	if out, err := aw("bob", "task", "handoff", task.TaskRef, "carol", "--note", "tests pass"); err != nil || !strings.Contains(out, "Handed "+task.TaskRef+" to carol") {
+		t.Fatalf("bob handoff err=%v\n%s", err, out)
+	}
+	if out, err := aw("carol", "task", "release", task.TaskRef, "--reason", "blocked"); err != nil || !strings.Contains(out, "Released "+task.TaskRef) {
+		t.Fatalf("carol release err=%v\n%s", err, out)
+	}
+	got, err := aliceClient.TaskGet(ctx, task.TaskRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != "open" {
+		t.Fatalf("status after release=%s", got.Status)
+	}
+}
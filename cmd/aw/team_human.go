@@ -854,7 +854,7 @@ func bootstrapTeamCreateGlobalIdentity(wd, alias, domain, registryURL string, al
 	if domain == "" {
 		return usageError("--first-agent-global requires --namespace with --byot when no global identity exists")
 	}
-	exists, err := awconfig.ControllerKeyExists(domain)
+	exists, err := controllerKeyAvailable(domain)
 	if err != nil {
 		return err
 	}
@@ -962,14 +962,14 @@ func foundTeamWithNamespaceControllerAuthority(wd, teamName, alias, explicitDoma
 		}
 		domain = identityDomain
 	}
-	exists, err := awconfig.ControllerKeyExists(domain)
+	exists, err := controllerKeyAvailable(domain)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
 		return nil, usageError("current identity is hosted-managed for namespace %s; creating another hosted team is not supported yet (tracked in default-aaas.3.15)", domain)
 	}
-	controllerKey, err := awconfig.LoadControllerKey(domain)
+	controllerKey, err := loadControllerSigner(domain)
 	if err != nil {
 		return nil, fmt.Errorf("load controller key for %s: %w", domain, err)
 	}
@@ -1016,7 +1016,7 @@ func foundTeamWithNamespaceControllerAuthority(wd, teamName, alias, explicitDoma
 	if err != nil {
 		return nil, err
 	}
-	cert, err := awid.SignTeamCertificate(registration.TeamKey, awid.TeamCertificateFields{
+	cert, err := awid.SignTeamCertificateContext(ctx, registration.TeamKey, awid.TeamCertificateFields{
 		Team:          registration.TeamID,
 		MemberDIDKey:  plan.MemberDIDKey,
 		MemberDIDAW:   strings.TrimSpace(plan.MemberDIDAW),
@@ -1675,7 +1675,7 @@ func preflightTeamHumanAddRosterAuthority(inviteAnchorDir string, plans []teamHu
 		domain = resolvedDomain
 		teamID = awid.BuildTeamID(domain, teamName)
 	}
-	controllerExists, err := awconfig.ControllerKeyExists(domain)
+	controllerExists, err := controllerKeyAvailable(domain)
 	if err != nil {
 		return err
 	}
@@ -2066,7 +2066,7 @@ func rollbackJustCreatedTeamMemberWithExplicitHostedAuth(anchorDir string, accep
 	} else {
 		registryURL = strings.TrimSpace(registry.DefaultRegistryURL)
 	}
-	teamKey, err := awconfig.LoadTeamKey(target.Domain, target.TeamName)
+	teamKey, err := loadTeamSigner(ctx, target.Domain, target.TeamName)
 	if err != nil {
 		return fmt.Errorf("load team key for %s/%s: %w", target.Domain, target.TeamName, err)
 	}
@@ -2597,7 +2597,7 @@ func revokeTeamCertificate(ctx context.Context, domain, team, teamID, memberAddr
 		return revokeHostedTeamCertificate(ctx, teamID, memberAddress, "")
 	}
 
-	teamKey, err := awconfig.LoadTeamKey(domain, team)
+	teamKey, err := loadTeamSigner(ctx, domain, team)
 	if err != nil {
 		return certificateStoreResult{}, fmt.Errorf("load team key for %s: %w", teamID, err)
 	}
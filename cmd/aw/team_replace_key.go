@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto"
 	"crypto/ed25519"
 	"encoding/base64"
 	"encoding/json"
@@ -180,7 +181,9 @@ func runTeamHumanReplaceKey(cmd *cobra.Command, args []string) (runErr error) {
 	if err != nil {
 		return err
 	}
-	teamKey, err := loadLocalReplaceKeyController(domain, teamName)
+	ctx, cancel := context.WithTimeout(context.Background(), awid.APITimeout())
+	defer cancel()
+	teamKey, err := loadLocalReplaceKeyController(ctx, domain, teamName)
 	if err != nil {
 		return err
 	}
@@ -235,7 +238,7 @@ func runTeamHumanReplaceKey(cmd *cobra.Command, args []string) (runErr error) {
 		return fmt.Errorf("invalid registry URL: %w", err)
 	}
 
-	newCertificate, err := awid.SignTeamCertificate(teamKey, awid.TeamCertificateFields{
+	newCertificate, err := awid.SignTeamCertificateContext(ctx, teamKey, awid.TeamCertificateFields{
 		Team: teamID, MemberDIDKey: newDIDKey, Alias: alias, IdentityScope: awid.IdentityModeLocal,
 	})
 	if err != nil {
@@ -249,8 +252,6 @@ func runTeamHumanReplaceKey(cmd *cobra.Command, args []string) (runErr error) {
 		TeamID: teamID, OldDIDKey: oldDIDKey, NewDIDKey: newDIDKey,
 		OldCertificateID: oldCertificateID, NewCertificateID: newCertificate.CertificateID,
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), awid.APITimeout())
-	defer cancel()
 	rosterResult, err := postLocalIdentityKeyReplacement(ctx, serviceURL, alias, requestPayload, teamKey)
 	if err != nil {
 		var unknown *replacementRosterOutcomeUnknownError
@@ -295,7 +296,7 @@ func runTeamHumanReplaceKey(cmd *cobra.Command, args []string) (runErr error) {
 	return nil
 }
 
-func loadLocalReplaceKeyController(domain, teamName string) (ed25519.PrivateKey, error) {
+func loadLocalReplaceKeyController(ctx context.Context, domain, teamName string) (crypto.Signer, error) {
 	if isAwebHostedNamespace(domain) {
 		return nil, usageError("team %s:%s is hosted; phase-1 replace-key cannot use a local team controller key for hosted custody. Hosted owner/admin replacement requires the pending AC integration or operator support", teamName, domain)
 	}
@@ -306,7 +307,7 @@ func loadLocalReplaceKeyController(domain, teamName string) (ed25519.PrivateKey,
 	if !exists {
 		return nil, usageError("no local team controller key is available for %s:%s; phase-1 replace-key supports local-controller/BYOT teams only. Restore the local team controller key for a BYOT team; hosted owner/admin replacement requires the pending AC integration or operator support", teamName, domain)
 	}
-	key, err := awconfig.LoadTeamKey(domain, teamName)
+	key, err := loadTeamSigner(ctx, domain, teamName)
 	if err != nil {
 		return nil, fmt.Errorf("load local team controller key: %w", err)
 	}
@@ -398,7 +399,7 @@ func loadReplacementAgentOldCertificate(homeDir, teamID, alias, oldDIDKey, newDI
 	return oldCertificate.CertificateID, oldCertificate, nil
 }
 
-func postLocalIdentityKeyReplacement(ctx context.Context, serviceURL, alias string, payload localIdentityKeyReplacementRequest, teamKey ed25519.PrivateKey) (*localIdentityKeyReplacementResponse, error) {
+func postLocalIdentityKeyReplacement(ctx context.Context, serviceURL, alias string, payload localIdentityKeyReplacementRequest, teamKey crypto.Signer) (*localIdentityKeyReplacementResponse, error) {
 	var firstUnknown error
 	for attempt := 0; attempt < 2; attempt++ {
 		out, err := postLocalIdentityKeyReplacementOnce(ctx, serviceURL, alias, payload, teamKey)
@@ -428,7 +429,7 @@ func postLocalIdentityKeyReplacement(ctx context.Context, serviceURL, alias stri
 	return nil, &replacementRosterOutcomeUnknownError{err: fmt.Errorf("exact replay could not reconcile roster outcome: %w", firstUnknown)}
 }
 
-func postLocalIdentityKeyReplacementOnce(ctx context.Context, serviceURL, alias string, payload localIdentityKeyReplacementRequest, teamKey ed25519.PrivateKey) (*localIdentityKeyReplacementResponse, error) {
+func postLocalIdentityKeyReplacementOnce(ctx context.Context, serviceURL, alias string, payload localIdentityKeyReplacementRequest, teamKey crypto.Signer) (*localIdentityKeyReplacementResponse, error) {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return nil, err
@@ -439,7 +440,11 @@ func postLocalIdentityKeyReplacementOnce(ctx context.Context, serviceURL, alias
 	if err != nil {
 		return nil, err
 	}
-	signature := base64.RawStdEncoding.EncodeToString(ed25519.Sign(teamKey, canonical))
+	sig, err := signWithSigner(teamKey, canonical)
+	if err != nil {
+		return nil, err
+	}
+	signature := base64.RawStdEncoding.EncodeToString(sig)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(serviceURL, "/")+path, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
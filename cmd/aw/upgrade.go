@@ -25,6 +25,13 @@ const (
 	updateGithubRepo    = "awebai/aw"
 	updateGithubAPIBase = "https://api.github.com"
 	updateCheckCacheTTL = time.Hour
+
+	// releaseURLEnv points upgrades at a GitHub-API-compatible mirror that
+	// serves /repos/awebai/aw/releases/latest.
+	releaseURLEnv = "AW_RELEASE_URL"
+
+	checksumsAssetName          = "checksums.txt"
+	checksumsSignatureAssetName = "checksums.txt.minisig"
 )
 
 type releaseAsset struct {
@@ -50,17 +57,30 @@ var (
 	updateCheckCachePath             = defaultUpdateCheckCachePath
 )
 
+var (
+	upgradeVerifyOnly bool
+	upgradeReleaseURL string
+)
+
 var upgradeCmd = &cobra.Command{
 	Use:   "upgrade",
 	Short: "Upgrade aw to the latest version",
+	Long: `Download the latest aw release, verify checksums.txt against the release
+signing key built into aw and the archive against checksums.txt, and replace
+the running binary. Older releases than the running version are refused.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		// No heartbeat for upgrade.
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return selfUpdate(cmd.OutOrStdout(), "")
+		return selfUpdate(cmd.OutOrStdout(), upgradeReleaseURL)
 	},
 }
 
+func init() {
+	upgradeCmd.Flags().BoolVar(&upgradeVerifyOnly, "verify-only", false, "Download and verify the latest release without installing it")
+	upgradeCmd.Flags().StringVar(&upgradeReleaseURL, "release-url", "", "GitHub-API-compatible release mirror (default: $"+releaseURLEnv+" or api.github.com)")
+}
+
 // compareVersions compares two version strings (X.Y.Z format).
 // Returns -1 if a < b, 0 if a == b, 1 if a > b.
 func compareVersions(a, b string) int {
@@ -93,12 +113,15 @@ func compareVersions(a, b string) int {
 // fetchLatestRelease fetches the latest release info from GitHub.
 // apiBase overrides the API base URL for testing; pass "" for production.
 func fetchLatestRelease(timeoutSeconds int, apiBase string) (*releaseInfo, error) {
+	if apiBase == "" {
+		apiBase = strings.TrimSpace(os.Getenv(releaseURLEnv))
+	}
 	if apiBase == "" {
 		apiBase = updateGithubAPIBase
 	}
-	url := fmt.Sprintf("%s/repos/%s/releases/latest", apiBase, updateGithubRepo)
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", strings.TrimRight(apiBase, "/"), updateGithubRepo)
 
-	client := &http.Client{Timeout: time.Duration(timeoutSeconds) * time.Second}
+	client := &http.Client{Timeout: time.Duration(timeoutSeconds) * time.Second, Transport: releaseTransport}
 	resp, err := client.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("fetching latest release: %w", err)
@@ -408,7 +431,7 @@ func resignMacOS(binaryPath string) {
 func selfUpdate(w io.Writer, apiBase string) error {
 	currentVersion := strings.TrimPrefix(version, "v")
 
-	if currentVersion == "dev" || currentVersion == "" {
+	if (currentVersion == "dev" || currentVersion == "") && !upgradeVerifyOnly {
 		fmt.Fprintln(w, "Skipping upgrade: running a dev build. Install a release build to use upgrade.")
 		return nil
 	}
@@ -422,13 +445,18 @@ func selfUpdate(w io.Writer, apiBase string) error {
 
 	latestVersion := strings.TrimPrefix(info.TagName, "v")
 
-	if compareVersions(currentVersion, latestVersion) >= 0 {
-		fmt.Fprintf(w, "aw v%s is already the latest version.\n", currentVersion)
-		return nil
+	if !upgradeVerifyOnly {
+		switch cmp := compareVersions(currentVersion, latestVersion); {
+		case cmp == 0:
+			fmt.Fprintf(w, "aw v%s is already the latest version.\n", currentVersion)
+			return nil
+		case cmp > 0:
+			fmt.Fprintf(w, "Refusing to downgrade aw v%s to the published v%s.\n", currentVersion, latestVersion)
+			return nil
+		}
+		fmt.Fprintf(w, "Updating aw v%s → v%s...\n", currentVersion, latestVersion)
 	}
 
-	fmt.Fprintf(w, "Updating aw v%s → v%s...\n", currentVersion, latestVersion)
-
 	// Determine platform archive name
 	goos := runtime.GOOS
 	goarch := runtime.GOARCH
@@ -439,19 +467,24 @@ func selfUpdate(w io.Writer, apiBase string) error {
 	archiveName := fmt.Sprintf("aw_%s_%s_%s.%s", latestVersion, goos, goarch, ext)
 
 	// Find download URLs
-	var archiveURL, checksumsURL string
+	var archiveURL, checksumsURL, signatureURL string
 	for _, a := range info.Assets {
-		if a.Name == archiveName {
+		switch a.Name {
+		case archiveName:
 			archiveURL = a.BrowserDownloadURL
-		}
-		if a.Name == "checksums.txt" {
+		case checksumsAssetName:
 			checksumsURL = a.BrowserDownloadURL
+		case checksumsSignatureAssetName:
+			signatureURL = a.BrowserDownloadURL
 		}
 	}
 
 	if archiveURL == "" {
 		return fmt.Errorf("no release asset found for %s/%s (expected %s)", goos, goarch, archiveName)
 	}
+	if checksumsURL == "" || signatureURL == "" {
+		return fmt.Errorf("release v%s is missing %s or %s; refusing an unsigned release", latestVersion, checksumsAssetName, checksumsSignatureAssetName)
+	}
 
 	// Download to temp dir
 	tmpDir, err := os.MkdirTemp("", "aw-update-*")
@@ -460,26 +493,44 @@ func selfUpdate(w io.Writer, apiBase string) error {
 	}
 	defer os.RemoveAll(tmpDir)
 
+	// Verify the signed checksums before trusting anything they list. The
+	// archive name carries the version, so a signed checksums.txt from an
+	// older release cannot vouch for it.
+	checksumsPath := filepath.Join(tmpDir, checksumsAssetName)
+	if err := downloadFile(checksumsPath, checksumsURL); err != nil {
+		return fmt.Errorf("downloading checksums: %w", err)
+	}
+	signaturePath := filepath.Join(tmpDir, checksumsSignatureAssetName)
+	if err := downloadFile(signaturePath, signatureURL); err != nil {
+		return fmt.Errorf("downloading checksums signature: %w", err)
+	}
+	checksums, err := os.ReadFile(checksumsPath)
+	if err != nil {
+		return err
+	}
+	signature, err := os.ReadFile(signaturePath)
+	if err != nil {
+		return err
+	}
+	if err := verifyReleaseChecksums(checksums, signature); err != nil {
+		return fmt.Errorf("verifying release v%s: %w", latestVersion, err)
+	}
+	expected, err := findChecksum(checksumsPath, archiveName)
+	if err != nil {
+		return fmt.Errorf("reading checksums: %w", err)
+	}
+
 	archivePath := filepath.Join(tmpDir, archiveName)
 	if err := downloadFile(archivePath, archiveURL); err != nil {
 		return fmt.Errorf("downloading archive: %w", err)
 	}
+	if err := verifyChecksum(archivePath, expected); err != nil {
+		return err
+	}
 
-	// Verify checksum if available
-	if checksumsURL != "" {
-		checksumsPath := filepath.Join(tmpDir, "checksums.txt")
-		if err := downloadFile(checksumsPath, checksumsURL); err != nil {
-			return fmt.Errorf("downloading checksums: %w", err)
-		}
-
-		expected, err := findChecksum(checksumsPath, archiveName)
-		if err != nil {
-			return fmt.Errorf("reading checksums: %w", err)
-		}
-
-		if err := verifyChecksum(archivePath, expected); err != nil {
-			return err
-		}
+	if upgradeVerifyOnly {
+		fmt.Fprintf(w, "Verified aw v%s (%s): signed checksums and archive match.\n", latestVersion, archiveName)
+		return nil
 	}
 
 	// Extract binary
@@ -520,13 +571,17 @@ func selfUpdate(w io.Writer, apiBase string) error {
 	return nil
 }
 
+// releaseTransport carries release lookups and downloads; tests point it at
+// a TLS fixture they trust.
+var releaseTransport http.RoundTripper = http.DefaultTransport
+
 // downloadFile downloads a URL to a local file.
 func downloadFile(destPath, rawURL string) error {
 	if !strings.HasPrefix(rawURL, "https://") {
 		return fmt.Errorf("refusing non-HTTPS download URL: %s", rawURL)
 	}
 
-	client := &http.Client{Timeout: 5 * time.Minute}
+	client := &http.Client{Timeout: 5 * time.Minute, Transport: releaseTransport}
 	resp, err := client.Get(rawURL)
 	if err != nil {
 		return err
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// releasePublicKey is the minisign public key that signs checksums.txt of
+// every aw release, as the base64 line of a minisign .pub file (key ID
+// BA52638134A3CD5D). It lives in source so every build, release or not,
+// verifies upgrades against the same key. The release workflow signs with
+// the matching password-protected secret key from the
+// AW_RELEASE_MINISIGN_KEY and AW_RELEASE_MINISIGN_PASSWORD secrets.
+const releasePublicKey = "RWRdzaM0gWNSuhYqpqRZY/L9JNLLSaz6PG9sYhPQvkxZlT/JxxRIpG9N"
+
+// trustedReleaseKey is the key verifyReleaseChecksums checks against; tests
+// replace it with a key they hold.
+var trustedReleaseKey = releasePublicKey
+
+const (
+	minisignAlgEd       = "Ed" // signature over the file
+	minisignAlgPrehash  = "ED" // signature over BLAKE2b-512 of the file
+	minisignKeyIDSize   = 8
+	trustedCommentLabel = "trusted comment: "
+)
+
+type minisignPublicKey struct {
+	keyID [minisignKeyIDSize]byte
+	key   ed25519.PublicKey
+}
+
+// parseMinisignPublicKey accepts a whole minisign .pub file or only its
+// base64 line.
+func parseMinisignPublicKey(text string) (minisignPublicKey, error) {
+	var line string
+	for _, l := range strings.Split(strings.TrimSpace(text), "\n") {
+		l = strings.TrimSpace(l)
+		if l != "" && !strings.HasPrefix(l, "untrusted comment:") {
+			line = l
+			break
+		}
+	}
+	raw, err := base64.StdEncoding.DecodeString(line)
+	if err != nil || len(raw) != 2+minisignKeyIDSize+ed25519.PublicKeySize || string(raw[:2]) != minisignAlgEd {
+		return minisignPublicKey{}, errors.New("invalid minisign public key")
+	}
+	var pub minisignPublicKey
+	copy(pub.keyID[:], raw[2:2+minisignKeyIDSize])
+	pub.key = ed25519.PublicKey(raw[2+minisignKeyIDSize:])
+	return pub, nil
+}
+
+// verifyMinisign checks a minisign signature file over message, including
+// the global signature over its trusted comment.
+func verifyMinisign(pub minisignPublicKey, message, signature []byte) error {
+	lines := strings.Split(strings.ReplaceAll(string(signature), "\r\n", "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[0], "untrusted comment:") || !strings.HasPrefix(lines[2], trustedCommentLabel) {
+		return errors.New("malformed minisign signature")
+	}
+	sigBlob, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sigBlob) != 2+minisignKeyIDSize+ed25519.SignatureSize {
+		return errors.New("malformed minisign signature")
+	}
+	if !bytes.Equal(sigBlob[2:2+minisignKeyIDSize], pub.keyID[:]) {
+		return fmt.Errorf("signed by key %X, want %X", sigBlob[2:2+minisignKeyIDSize], pub.keyID[:])
+	}
+	sig := sigBlob[2+minisignKeyIDSize:]
+	switch string(sigBlob[:2]) {
+	case minisignAlgEd:
+	case minisignAlgPrehash:
+		sum := blake2b.Sum512(message)
+		message = sum[:]
+	default:
+		return fmt.Errorf("unsupported minisign algorithm %q", sigBlob[:2])
+	}
+	if !ed25519.Verify(pub.key, message, sig) {
+		return errors.New("signature does not verify")
+	}
+	trusted := strings.TrimPrefix(lines[2], trustedCommentLabel)
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || !ed25519.Verify(pub.key, append(append([]byte(nil), sig...), trusted...), globalSig) {
+		return errors.New("trusted comment signature does not verify")
+	}
+	return nil
+}
+
+// verifyReleaseChecksums checks checksums.txt against the embedded release key.
+func verifyReleaseChecksums(checksums, signature []byte) error {
+	pub, err := parseMinisignPublicKey(trustedReleaseKey)
+	if err != nil {
+		return err
+	}
+	if err := verifyMinisign(pub, checksums, signature); err != nil {
+		return fmt.Errorf("checksums.txt: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+type testMinisignKey struct {
+	keyID [minisignKeyIDSize]byte
+	priv  ed25519.PrivateKey
+}
+
+func newTestMinisignKey(t *testing.T) testMinisignKey {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := testMinisignKey{priv: priv}
+	copy(key.keyID[:], priv.Public().(ed25519.PublicKey)[:minisignKeyIDSize])
+	return key
+}
+
+func (k testMinisignKey) publicKey() string {
+	raw := append([]byte(minisignAlgEd), k.keyID[:]...)
+	raw = append(raw, k.priv.Public().(ed25519.PublicKey)...)
+	return "untrusted comment: minisign public key\n" + base64.StdEncoding.EncodeToString(raw) + "\n"
+}
+
+func (k testMinisignKey) sign(message []byte, prehash bool) []byte {
+	alg := minisignAlgEd
+	if prehash {
+		alg = minisignAlgPrehash
+		sum := blake2b.Sum512(message)
+		message = sum[:]
+	}
+	sig := ed25519.Sign(k.priv, message)
+	blob := append([]byte(alg), k.keyID[:]...)
+	blob = append(blob, sig...)
+	trusted := "timestamp:1760000000\tfile:checksums.txt"
+	global := ed25519.Sign(k.priv, append(append([]byte(nil), sig...), trusted...))
+	return []byte("untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(blob) + "\n" +
+		trustedCommentLabel + trusted + "\n" +
+		base64.StdEncoding.EncodeToString(global) + "\n")
+}
+
+func TestVerifyMinisign(t *testing.T) {
+	key := newTestMinisignKey(t)
+	pub, err := parseMinisignPublicKey(key.publicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("abc  aw_1.0.0_linux_amd64.tar.gz\n")
+	for _, prehash := range []bool{false, true} {
+		if err := verifyMinisign(pub, message, key.sign(message, prehash)); err != nil {
+			t.Fatalf("prehash=%v: %v", prehash, err)
+		}
+	}
+	if err := verifyMinisign(pub, []byte("tampered"), key.sign(message, true)); err == nil {
+		t.Fatal("tampered message verified")
+	}
+	sig := key.sign(message, false)
+	forged := bytes.Replace(sig, []byte("file:checksums.txt"), []byte("file:other.txt"), 1)
+	if err := verifyMinisign(pub, message, forged); err == nil || !strings.Contains(err.Error(), "trusted comment") {
+		t.Fatalf("err=%v, want trusted comment failure", err)
+	}
+	other := newTestMinisignKey(t)
+	if err := verifyMinisign(pub, message, other.sign(message, false)); err == nil {
+		t.Fatal("signature from another key verified")
+	}
+}
+
+// releaseFixture serves a GitHub-API-compatible release for version with
+// an archive, checksums.txt and its minisign signature over TLS, and points
+// release downloads at it.
+type releaseFixture struct {
+	server    *httptest.Server
+	checksums []byte
+	signature []byte
+	unsigned  bool
+}
+
+func newReleaseFixture(t *testing.T, key testMinisignKey, releaseVersion string) *releaseFixture {
+	t.Helper()
+	archiveName := fmt.Sprintf("aw_%s_%s_%s.tar.gz", releaseVersion, runtime.GOOS, runtime.GOARCH)
+	archive := []byte("not really an archive")
+	sum := sha256.Sum256(archive)
+	f := &releaseFixture{checksums: []byte(fmt.Sprintf("%x  %s\n", sum, archiveName))}
+	f.signature = key.sign(f.checksums, true)
+	mux := http.NewServeMux()
+	f.server = httptest.NewTLSServer(mux)
+	t.Cleanup(f.server.Close)
+	oldTransport := releaseTransport
+	releaseTransport = f.server.Client().Transport
+	t.Cleanup(func() { releaseTransport = oldTransport })
+	mux.HandleFunc("/repos/awebai/aw/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		assets := []releaseAsset{
+			{Name: archiveName, BrowserDownloadURL: f.server.URL + "/dl/archive"},
+			{Name: checksumsAssetName, BrowserDownloadURL: f.server.URL + "/dl/checksums"},
+		}
+		if !f.unsigned {
+			assets = append(assets, releaseAsset{Name: checksumsSignatureAssetName, BrowserDownloadURL: f.server.URL + "/dl/signature"})
+		}
+		_ = json.NewEncoder(w).Encode(releaseInfo{TagName: "v" + releaseVersion, Assets: assets})
+	})
+	mux.HandleFunc("/dl/archive", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(archive) })
+	mux.HandleFunc("/dl/checksums", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(f.checksums) })
+	mux.HandleFunc("/dl/signature", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(f.signature) })
+	return f
+}
+
+func setUpgradeTestState(t *testing.T, currentVersion, publicKey string, verifyOnly bool) {
+	t.Helper()
+	oldVersion, oldKey, oldVerifyOnly := version, trustedReleaseKey, upgradeVerifyOnly
+	t.Cleanup(func() { version, trustedReleaseKey, upgradeVerifyOnly = oldVersion, oldKey, oldVerifyOnly })
+	version, trustedReleaseKey, upgradeVerifyOnly = currentVersion, publicKey, verifyOnly
+}
+
+func TestSelfUpdateVerifyOnlyAcceptsSignedRelease(t *testing.T) {
+	key := newTestMinisignKey(t)
+	setUpgradeTestState(t, "1.0.0", key.publicKey(), true)
+	fixture := newReleaseFixture(t, key, "1.1.0")
+
+	var buf bytes.Buffer
+	if err := selfUpdate(&buf, fixture.server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Verified aw v1.1.0") {
+		t.Fatalf("output=%q", buf.String())
+	}
+}
+
+func TestSelfUpdateRejectsUnverifiedReleases(t *testing.T) {
+	key := newTestMinisignKey(t)
+
+	t.Run("tampered checksums", func(t *testing.T) {
+		setUpgradeTestState(t, "1.0.0", key.publicKey(), true)
+		fixture := newReleaseFixture(t, key, "1.1.0")
+		fixture.checksums = append(fixture.checksums, []byte("0000  extra\n")...)
+		err := selfUpdate(&bytes.Buffer{}, fixture.server.URL)
+		if err == nil || !strings.Contains(err.Error(), "does not verify") {
+			t.Fatalf("err=%v, want signature failure", err)
+		}
+	})
+
+	t.Run("unsigned release", func(t *testing.T) {
+		setUpgradeTestState(t, "1.0.0", key.publicKey(), true)
+		fixture := newReleaseFixture(t, key, "1.1.0")
+		fixture.unsigned = true
+		err := selfUpdate(&bytes.Buffer{}, fixture.server.URL)
+		if err == nil || !strings.Contains(err.Error(), "unsigned") {
+			t.Fatalf("err=%v, want unsigned refusal", err)
+		}
+	})
+
+	t.Run("signed by another key", func(t *testing.T) {
+		setUpgradeTestState(t, "1.0.0", newTestMinisignKey(t).publicKey(), true)
+		fixture := newReleaseFixture(t, key, "1.1.0")
+		if err := selfUpdate(&bytes.Buffer{}, fixture.server.URL); err == nil {
+			t.Fatal("release signed by another key verified")
+		}
+	})
+
+	t.Run("committed release key", func(t *testing.T) {
+		setUpgradeTestState(t, "1.0.0", releasePublicKey, true)
+		if _, err := parseMinisignPublicKey(releasePublicKey); err != nil {
+			t.Fatalf("committed release key: %v", err)
+		}
+		fixture := newReleaseFixture(t, key, "1.1.0")
+		if err := selfUpdate(&bytes.Buffer{}, fixture.server.URL); err == nil {
+			t.Fatal("release signed by a test key verified against the committed release key")
+		}
+	})
+}
+
+func TestSelfUpdateRefusesDowngrade(t *testing.T) {
+	key := newTestMinisignKey(t)
+	setUpgradeTestState(t, "1.2.0", key.publicKey(), false)
+	fixture := newReleaseFixture(t, key, "1.1.0")
+
+	var buf bytes.Buffer
+	if err := selfUpdate(&buf, fixture.server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Refusing to downgrade aw v1.2.0") {
+		t.Fatalf("output=%q", buf.String())
+	}
+}
@@ -466,6 +466,7 @@ type fileConfig struct {
 	Listen                    string             `yaml:"listen"`
 	Host                      string             `yaml:"host"`
 	WorkspaceDir              string             `yaml:"workspace_dir"`
+	StateDir                  string             `yaml:"state_dir"`
 	TeamID                    string             `yaml:"team_id"`
 	RootCardMode              string             `yaml:"root_card_mode"`
 	DefaultRouteID            string             `yaml:"default_route_id"`
@@ -477,6 +478,7 @@ type fileConfig struct {
 	RequireVerifiedReplies    *bool              `yaml:"require_verified_replies"`
 	AllowUnverifiedLocalReply bool               `yaml:"allow_unverified_local_reply"`
 	AllowQuestionReply        bool               `yaml:"allow_question_reply"`
+	AllowLoopbackPushURLs     bool               `yaml:"allow_loopback_push_urls"`
 	RouterCard                cardConfig         `yaml:"router_card"`
 	Routes                    []routeConfig      `yaml:"routes"`
 	Audit                     auditConfig        `yaml:"audit"`
@@ -1042,6 +1044,7 @@ func defaultRouterCard(host string) cardConfig {
 
 type gatewayRuntime struct {
 	audit            a2agw.AuditSink
+	store            a2agw.TaskStore
 	bridge           *a2agw.MailBridge
 	managedTransport *managedBridgeTransport
 }
@@ -1076,6 +1079,10 @@ func buildGatewayWithRuntime(cfg fileConfig, runtime *gatewayRuntime, previous *
 		if err != nil {
 			return nil, nil, err
 		}
+		runtime.store, err = taskStoreFromConfig(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 	client, gatewayIdentity, err := mailTransportFromConfig(cfg)
 	if err != nil {
@@ -1120,6 +1127,8 @@ func buildGatewayWithRuntime(cfg fileConfig, runtime *gatewayRuntime, previous *
 			AllowUnverifiedLocalReply: cfg.AllowUnverifiedLocalReply,
 			AllowQuestionReply:        cfg.AllowQuestionReply,
 			Audit:                     runtime.audit,
+			Store:                     runtime.store,
+			EventStream:               eventStreamFromTransport(client),
 		})
 		if err != nil {
 			return nil, nil, err
@@ -1129,6 +1138,7 @@ func buildGatewayWithRuntime(cfg fileConfig, runtime *gatewayRuntime, previous *
 	if err != nil {
 		return nil, nil, err
 	}
+	gatewayConfig.TaskStore = runtime.store
 	gateway, err := a2agw.NewPreservingRuntime(gatewayConfig, previous)
 	if err != nil {
 		return nil, nil, err
@@ -1139,6 +1149,33 @@ func buildGatewayWithRuntime(cfg fileConfig, runtime *gatewayRuntime, previous *
 	return runtime, gateway, nil
 }
 
+// eventStreamFromTransport lets a workspace identity pick up replies from
+// its event stream. The managed bridge API has no event stream, so managed
+// gateways keep polling.
+func eventStreamFromTransport(client a2agw.MailTransport) a2agw.EventStreamOpener {
+	awebClient, ok := client.(*awid.Client)
+	if !ok {
+		return nil
+	}
+	return func(ctx context.Context, deadline time.Time) (awid.EventSource, error) {
+		return awebClient.EventStream(ctx, deadline)
+	}
+}
+
+// taskStoreFromConfig opens the durable task store when state_dir is set.
+// Without it tasks live in memory and do not survive a restart.
+func taskStoreFromConfig(cfg fileConfig) (a2agw.TaskStore, error) {
+	dir := strings.TrimSpace(cfg.StateDir)
+	if dir == "" {
+		return nil, nil
+	}
+	store, err := a2agw.NewFileTaskStore(dir)
+	if err != nil {
+		return nil, fmt.Errorf("state_dir: %w", err)
+	}
+	return store, nil
+}
+
 func mailTransportFromConfig(cfg fileConfig) (a2agw.MailTransport, string, error) {
 	if managedConfigEnabled(cfg.ManagedConfig) {
 		client, err := managedBridgeTransportFromConfig(cfg)
@@ -1181,14 +1218,15 @@ func gatewayConfigFromFile(cfg fileConfig, bridge a2agw.Bridge, audit a2agw.Audi
 		return a2agw.Config{}, err
 	}
 	return a2agw.Config{
-		Host:                strings.TrimSpace(cfg.Host),
-		RootCardMode:        a2agw.RootCardMode(strings.TrimSpace(cfg.RootCardMode)),
-		DefaultRouteID:      strings.TrimSpace(cfg.DefaultRouteID),
-		RouterCard:          convertRouterCard(cfg.RouterCard),
-		Routes:              routes,
-		Bridge:              bridge,
-		Audit:               audit,
-		AcceptNewTasksUntil: acceptUntil,
+		Host:                  strings.TrimSpace(cfg.Host),
+		RootCardMode:          a2agw.RootCardMode(strings.TrimSpace(cfg.RootCardMode)),
+		DefaultRouteID:        strings.TrimSpace(cfg.DefaultRouteID),
+		RouterCard:            convertRouterCard(cfg.RouterCard),
+		Routes:                routes,
+		Bridge:                bridge,
+		Audit:                 audit,
+		AcceptNewTasksUntil:   acceptUntil,
+		AllowLoopbackPushURLs: cfg.AllowLoopbackPushURLs,
 	}, nil
 }
 
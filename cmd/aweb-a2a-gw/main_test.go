@@ -59,6 +59,9 @@ func TestA2AGatewayBuildsFromWorkspaceConfigServesCardAndSendsTask(t *testing.T)
 			})
 		case "/v1/messages/conversations/conv-1":
 			_ = json.NewEncoder(w).Encode(awid.InboxResponse{Messages: []awid.InboxMessage{}})
+		case "/v1/events/stream":
+			// No event stream here: the bridge falls back to polling.
+			http.NotFound(w, r)
 		default:
 			t.Fatalf("unexpected aweb request %s %s", r.Method, r.URL.Path)
 		}
@@ -1633,3 +1636,23 @@ func TestA2AGatewayRuntimeHealthClaimsNoRepositoryWhenItWasNotGivenOne(t *testin
 			"which a reader would then trust: %s", resp.Body.String())
 	}
 }
+
+func TestA2AGatewayStateDirOpensDurableTaskStore(t *testing.T) {
+	store, err := taskStoreFromConfig(fileConfig{})
+	if err != nil || store != nil {
+		t.Fatalf("no state_dir: store=%v err=%v", store, err)
+	}
+	dir := filepath.Join(t.TempDir(), "state")
+	store, err = taskStoreFromConfig(fileConfig{StateDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.(*a2agw.FileTaskStore); !ok {
+		t.Fatalf("store=%T, want *a2agw.FileTaskStore", store)
+	}
+	for _, sub := range []string{"tasks", "threads"} {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err != nil || !info.IsDir() {
+			t.Fatalf("state_dir %s missing: %v", sub, err)
+		}
+	}
+}
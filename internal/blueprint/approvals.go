@@ -0,0 +1,75 @@
+package blueprint
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ApprovalMutatingApps is the approval_required entry that gates every
+// manifest plugin tool declared with mutation: true.
+const ApprovalMutatingApps = "app.mutating"
+
+// LoadMaterializedApprovalRequired reads approval_required from the profile
+// materialized into homeDir. A home without a materialized profile gates
+// nothing and returns nil.
+func LoadMaterializedApprovalRequired(homeDir string) ([]string, error) {
+	profile, profilePath, err := loadMaterializedProfile(homeDir)
+	if err != nil || profile == nil {
+		return nil, err
+	}
+	var actions []string
+	for i, action := range profile.ApprovalRequired {
+		action = NormalizeApprovalAction(action)
+		if action == "" {
+			continue
+		}
+		if _, err := path.Match(action, ""); err != nil {
+			return nil, fmt.Errorf("%s:approval_required[%d]: %w", profilePath, i, err)
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// NormalizeApprovalAction turns a command path into the dotted action name
+// approval_required lists: "aw task close" and "task close" both become
+// "task.close".
+func NormalizeApprovalAction(action string) string {
+	fields := strings.Fields(strings.ToLower(action))
+	if len(fields) > 1 && fields[0] == "aw" {
+		fields = fields[1:]
+	}
+	return strings.Join(fields, ".")
+}
+
+// ApprovalRequiredFor reports which approval_required entry gates action.
+// Entries are path.Match patterns over dotted names ("task.*"), and an entry
+// also gates every subcommand below it ("id.team" gates
+// "id.team.remove-member"). Mutating plugin tools are "<app>.<verb>" and are
+// also gated by ApprovalMutatingApps.
+func ApprovalRequiredFor(required []string, action string, mutatingTool bool) (string, bool) {
+	action = NormalizeApprovalAction(action)
+	if action == "" {
+		return "", false
+	}
+	for _, entry := range required {
+		entry = NormalizeApprovalAction(entry)
+		if entry == "" {
+			continue
+		}
+		if entry == ApprovalMutatingApps {
+			if mutatingTool {
+				return entry, true
+			}
+			continue
+		}
+		if ok, err := path.Match(entry, action); err == nil && ok {
+			return entry, true
+		}
+		if strings.HasPrefix(action, entry+".") {
+			return entry, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,46 @@
+package blueprint
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestApprovalRequiredForMatchesCommandsAndMutatingTools(t *testing.T) {
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, ".aw", "profile", "profile.yaml"), `id: reviewer
+name: Reviewer
+version: 1.0.0
+approval_required:
+  - task.close
+  - id team
+  - secrets.*
+  - app.mutating
+`)
+	required, err := LoadMaterializedApprovalRequired(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		action   string
+		mutating bool
+		rule     string
+	}{
+		{"aw task close", false, "task.close"},
+		{"aw id team remove-member", false, "id.team"},
+		{"secrets read", false, "secrets.*"},
+		{"ci deploy", true, "app.mutating"},
+		{"aw task list", false, ""},
+		{"aw identity", false, ""},
+		{"ci status", false, ""},
+	} {
+		rule, gated := ApprovalRequiredFor(required, tc.action, tc.mutating)
+		if rule != tc.rule || gated != (tc.rule != "") {
+			t.Fatalf("%s: rule=%q gated=%v", tc.action, rule, gated)
+		}
+	}
+
+	if required, err := LoadMaterializedApprovalRequired(t.TempDir()); err != nil || required != nil {
+		t.Fatalf("required=%v err=%v", required, err)
+	}
+}
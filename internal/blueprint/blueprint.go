@@ -86,6 +86,7 @@ type Subscription struct {
 	Event       string         `json:"event" yaml:"event"`
 	ResourceRef string         `json:"resource_ref,omitempty" yaml:"resource_ref"`
 	Filter      map[string]any `json:"filter,omitempty" yaml:"filter"`
+	Prompt      string         `json:"prompt,omitempty" yaml:"prompt"`
 }
 
 type PathResource struct {
@@ -522,7 +523,7 @@ func validateSubscription(field string, sub Subscription) error {
 	if strings.TrimSpace(sub.ResourceRef) != "" && hasControl(sub.ResourceRef) {
 		return fmt.Errorf("%s.resource_ref: control characters are not allowed", field)
 	}
-	return nil
+	return validateSubscriptionMatchers(field, sub)
 }
 
 func validateResource(root, profileDir, profileRel, field string, idx int, resource *PathResource) error {
@@ -0,0 +1,252 @@
+package blueprint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/awebai/aw/awid"
+	"gopkg.in/yaml.v3"
+)
+
+// Subscription prompts may reference these placeholders; payload fields are
+// reached with {{payload.<key>}}, dotted for nested objects.
+var subscriptionPromptPlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+var subscriptionPromptFields = map[string]bool{
+	"app":          true,
+	"event":        true,
+	"resource_ref": true,
+	"event_id":     true,
+	"summary":      true,
+}
+
+// materializedProfile is the part of a materialized profile.yaml that aw
+// reads at run time. Other fields are ignored, so a profile written by a
+// newer schema still loads.
+type materializedProfile struct {
+	EventSubscriptions []Subscription `yaml:"event_subscriptions"`
+	ApprovalRequired   []string       `yaml:"approval_required"`
+}
+
+// loadMaterializedProfile reads the profile materialized into homeDir
+// (.aw/profile/profile.yaml). It returns a nil profile when the home has
+// none, along with the path for error messages.
+func loadMaterializedProfile(homeDir string) (*materializedProfile, string, error) {
+	profilePath := filepath.Join(homeDir, ".aw", "profile", "profile.yaml")
+	raw, err := os.ReadFile(profilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, profilePath, nil
+	}
+	if err != nil {
+		return nil, profilePath, err
+	}
+	var profile materializedProfile
+	if err := yaml.Unmarshal(raw, &profile); err != nil {
+		return nil, profilePath, fmt.Errorf("%s: parse: %w", profilePath, err)
+	}
+	return &profile, profilePath, nil
+}
+
+// LoadMaterializedSubscriptions reads event_subscriptions from the profile
+// materialized into homeDir. A home without a materialized profile has no
+// subscriptions and returns nil.
+func LoadMaterializedSubscriptions(homeDir string) ([]Subscription, error) {
+	profile, profilePath, err := loadMaterializedProfile(homeDir)
+	if err != nil || profile == nil {
+		return nil, err
+	}
+	for i, sub := range profile.EventSubscriptions {
+		if err := validateSubscription(fmt.Sprintf("%s:event_subscriptions[%d]", profilePath, i), sub); err != nil {
+			return nil, err
+		}
+	}
+	return profile.EventSubscriptions, nil
+}
+
+// MatchSubscription returns the first subscription that evt satisfies.
+func MatchSubscription(subs []Subscription, evt awid.AgentEvent) (Subscription, bool) {
+	for _, sub := range subs {
+		if sub.Matches(evt) {
+			return sub, true
+		}
+	}
+	return Subscription{}, false
+}
+
+// Matches reports whether an app event satisfies the subscription. Event
+// and ResourceRef are path.Match patterns ("ci/*"); every Filter entry must
+// hold on the payload, where a list value means any of its items.
+func (s Subscription) Matches(evt awid.AgentEvent) bool {
+	if evt.Type != awid.AgentEventAppEvent {
+		return false
+	}
+	if strings.TrimSpace(s.App) != strings.TrimSpace(evt.AppID) {
+		return false
+	}
+	if !globMatch(s.Event, evt.AppEventType) {
+		return false
+	}
+	if ref := strings.TrimSpace(s.ResourceRef); ref != "" && !globMatch(ref, evt.ResourceRef) {
+		return false
+	}
+	keys := make([]string, 0, len(s.Filter))
+	for key := range s.Filter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		got, ok := payloadValue(evt.Payload, key)
+		if !ok || !filterValueMatches(s.Filter[key], got) {
+			return false
+		}
+	}
+	return true
+}
+
+// RenderPrompt fills the subscription's prompt template for evt. summary is
+// the one-line event summary the dispatcher would otherwise show. Values come
+// from the app, so each is flattened to one line and capped; only the
+// template itself may span lines. An empty template renders as "".
+func (s Subscription) RenderPrompt(evt awid.AgentEvent, summary string) string {
+	return subscriptionPromptPlaceholder.ReplaceAllStringFunc(s.Prompt, func(match string) string {
+		name := subscriptionPromptPlaceholder.FindStringSubmatch(match)[1]
+		switch name {
+		case "app":
+			return promptValue(evt.AppID)
+		case "event":
+			return promptValue(evt.AppEventType)
+		case "resource_ref":
+			return promptValue(evt.ResourceRef)
+		case "event_id":
+			return promptValue(evt.EventID)
+		case "summary":
+			return promptValue(summary)
+		}
+		if key, ok := strings.CutPrefix(name, "payload."); ok {
+			if value, ok := payloadValue(evt.Payload, key); ok {
+				return promptValue(value)
+			}
+		}
+		return ""
+	})
+}
+
+func validateSubscriptionMatchers(field string, sub Subscription) error {
+	if _, err := path.Match(sub.Event, ""); err != nil {
+		return fmt.Errorf("%s.event: %w", field, err)
+	}
+	if _, err := path.Match(sub.ResourceRef, ""); err != nil {
+		return fmt.Errorf("%s.resource_ref: %w", field, err)
+	}
+	for key, value := range sub.Filter {
+		if strings.TrimSpace(key) == "" || hasControl(key) {
+			return fmt.Errorf("%s.filter: keys must be non-empty payload paths", field)
+		}
+		values, isList := value.([]any)
+		if !isList {
+			values = []any{value}
+		}
+		for _, v := range values {
+			switch v.(type) {
+			case string, bool, int, int64, uint64, float64, nil:
+			default:
+				return fmt.Errorf("%s.filter.%s: values must be scalars or lists of scalars", field, key)
+			}
+		}
+	}
+	if strings.TrimSpace(sub.Prompt) == "" {
+		return nil
+	}
+	if hasDisallowedTextControl(sub.Prompt) {
+		return fmt.Errorf("%s.prompt: control characters are not allowed", field)
+	}
+	for _, m := range subscriptionPromptPlaceholder.FindAllStringSubmatch(sub.Prompt, -1) {
+		name := m[1]
+		if subscriptionPromptFields[name] {
+			continue
+		}
+		if key, ok := strings.CutPrefix(name, "payload."); ok && key != "" {
+			continue
+		}
+		return fmt.Errorf("%s.prompt: unknown placeholder {{%s}}", field, name)
+	}
+	return nil
+}
+
+func globMatch(pattern, value string) bool {
+	ok, err := path.Match(strings.TrimSpace(pattern), strings.TrimSpace(value))
+	return err == nil && ok
+}
+
+func payloadValue(payload map[string]any, key string) (any, bool) {
+	var current any = payload
+	for _, part := range strings.Split(key, ".") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = object[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func filterValueMatches(want, got any) bool {
+	if values, ok := want.([]any); ok {
+		for _, v := range values {
+			if scalarEqual(v, got) {
+				return true
+			}
+		}
+		return false
+	}
+	return scalarEqual(want, got)
+}
+
+// scalarEqual compares through JSON so a YAML int filter matches a JSON
+// float64 payload value.
+func scalarEqual(a, b any) bool {
+	left, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	right, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(left) == string(right)
+}
+
+const maxPromptValueRunes = 160
+
+func promptValue(value any) string {
+	text, ok := value.(string)
+	if !ok {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			text = fmt.Sprint(value)
+		} else {
+			text = string(encoded)
+		}
+	}
+	text = strings.Join(strings.Fields(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, text)), " ")
+	if runes := []rune(text); len(runes) > maxPromptValueRunes {
+		text = string(runes[:maxPromptValueRunes-1]) + "…"
+	}
+	return text
+}
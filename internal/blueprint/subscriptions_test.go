@@ -0,0 +1,91 @@
+package blueprint
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/awebai/aw/awid"
+)
+
+func TestLoadMaterializedSubscriptionsMatchesAndRendersPrompt(t *testing.T) {
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, ".aw", "profile", "profile.yaml"), `id: reviewer
+name: Reviewer
+version: 1.0.0
+event_subscriptions:
+  - app: ci
+    event: build.*
+    resource_ref: main
+    filter:
+      status: [failed, errored]
+      run.attempt: 2
+    prompt: "Build {{payload.run.id}} on {{resource_ref}} {{payload.status}}: {{summary}}"
+  - app: tasks
+    event: task.assigned
+`)
+	subs, err := LoadMaterializedSubscriptions(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 2 {
+		t.Fatalf("subscriptions=%d", len(subs))
+	}
+
+	evt := awid.AgentEvent{
+		Type:         awid.AgentEventAppEvent,
+		AppID:        "ci",
+		AppEventType: "build.finished",
+		ResourceRef:  "main",
+		Payload:      map[string]any{"status": "failed", "run": map[string]any{"id": "r-9", "attempt": float64(2)}},
+	}
+	sub, ok := MatchSubscription(subs, evt)
+	if !ok || sub.App != "ci" {
+		t.Fatalf("match=%v sub=%+v", ok, sub)
+	}
+	if got := sub.RenderPrompt(evt, "build.finished — main"); got != "Build r-9 on main failed: build.finished — main" {
+		t.Fatalf("prompt=%q", got)
+	}
+
+	for name, mutate := range map[string]func(*awid.AgentEvent){
+		"other app":      func(e *awid.AgentEvent) { e.AppID = "folio" },
+		"other event":    func(e *awid.AgentEvent) { e.AppEventType = "deploy.finished" },
+		"other resource": func(e *awid.AgentEvent) { e.ResourceRef = "release" },
+		"filter miss":    func(e *awid.AgentEvent) { e.Payload = map[string]any{"status": "passed"} },
+		"not app event":  func(e *awid.AgentEvent) { e.Type = awid.AgentEventActionableMail },
+	} {
+		miss := evt
+		mutate(&miss)
+		if _, ok := MatchSubscription(subs, miss); ok {
+			t.Fatalf("%s: unexpected match", name)
+		}
+	}
+
+	assigned := awid.AgentEvent{Type: awid.AgentEventAppEvent, AppID: "tasks", AppEventType: "task.assigned"}
+	if sub, ok := MatchSubscription(subs, assigned); !ok || sub.Prompt != "" {
+		t.Fatalf("match=%v sub=%+v", ok, sub)
+	}
+}
+
+func TestLoadMaterializedSubscriptionsWithoutProfile(t *testing.T) {
+	subs, err := LoadMaterializedSubscriptions(t.TempDir())
+	if err != nil || subs != nil {
+		t.Fatalf("subs=%v err=%v", subs, err)
+	}
+}
+
+func TestValidateSubscriptionRejectsBadMatchers(t *testing.T) {
+	for name, tc := range map[string]struct {
+		sub  Subscription
+		want string
+	}{
+		"bad pattern":         {Subscription{App: "ci", Event: "build.[", Prompt: ""}, "event"},
+		"nested filter value": {Subscription{App: "ci", Event: "build", Filter: map[string]any{"run": map[string]any{"id": 1}}}, "scalars"},
+		"unknown placeholder": {Subscription{App: "ci", Event: "build", Prompt: "see {{task}}"}, "unknown placeholder"},
+	} {
+		err := validateSubscription("event_subscriptions[0]", tc.sub)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: err=%v", name, err)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+// Package mcpserver is a minimal Model Context Protocol server over stdio:
+// newline-delimited JSON-RPC 2.0 carrying initialize, ping, tools/list and
+// tools/call. Resources and prompts are not offered.
+package mcpserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+const (
+	jsonRPCVersion = "2.0"
+
+	// ProtocolVersion is answered when the client asks for a version this
+	// server does not know.
+	ProtocolVersion = "2025-06-18"
+
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+var supportedProtocolVersions = map[string]bool{
+	"2024-11-05": true,
+	"2025-03-26": true,
+	"2025-06-18": true,
+}
+
+// Tool is one callable tool. InputSchema is a JSON Schema object.
+type Tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"inputSchema"`
+
+	Handler func(ctx context.Context, args map[string]any) (*ToolResult, error) `json:"-"`
+}
+
+// Content is one item of a tool result. Only text content is produced.
+type Content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// ToolResult is the result of tools/call. IsError reports a tool-level
+// failure the model should see, as opposed to a protocol error.
+type ToolResult struct {
+	Content []Content `json:"content"`
+	IsError bool      `json:"isError,omitempty"`
+}
+
+// TextResult wraps text as a successful tool result.
+func TextResult(text string) *ToolResult {
+	return &ToolResult{Content: []Content{{Type: "text", Text: text}}}
+}
+
+// ErrorResult wraps text as a failed tool result.
+func ErrorResult(text string) *ToolResult {
+	return &ToolResult{Content: []Content{{Type: "text", Text: text}}, IsError: true}
+}
+
+// Server answers MCP requests for a fixed tool set.
+type Server struct {
+	Name         string
+	Version      string
+	Instructions string
+
+	tools map[string]Tool
+	order []string
+
+	writeMu sync.Mutex
+}
+
+func New(name, version string) *Server {
+	return &Server{Name: name, Version: version, tools: map[string]Tool{}}
+}
+
+// AddTool registers a tool. A duplicate name is an error.
+func (s *Server) AddTool(tool Tool) error {
+	name := strings.TrimSpace(tool.Name)
+	if name == "" {
+		return fmt.Errorf("tool name is required")
+	}
+	if tool.Handler == nil {
+		return fmt.Errorf("tool %s has no handler", name)
+	}
+	if _, exists := s.tools[name]; exists {
+		return fmt.Errorf("duplicate tool %s", name)
+	}
+	if tool.InputSchema == nil {
+		tool.InputSchema = map[string]any{"type": "object"}
+	}
+	tool.Name = name
+	s.tools[name] = tool
+	s.order = append(s.order, name)
+	return nil
+}
+
+// Tools returns the registered tools in registration order.
+func (s *Server) Tools() []Tool {
+	out := make([]Tool, 0, len(s.order))
+	for _, name := range s.order {
+		out = append(out, s.tools[name])
+	}
+	return out
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Serve reads requests from in until EOF or ctx is done. Tool calls run
+// concurrently so a long chat wait does not block other requests.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := append([]byte(nil), scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case line := <-lines:
+			if len(strings.TrimSpace(string(line))) == 0 {
+				continue
+			}
+			var req request
+			if err := json.Unmarshal(line, &req); err != nil {
+				s.write(out, response{JSONRPC: jsonRPCVersion, ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: "parse error"}})
+				continue
+			}
+			if req.Method == "tools/call" && len(req.ID) > 0 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					s.reply(out, req, s.callTool(ctx, req.Params))
+				}()
+				continue
+			}
+			s.reply(out, req, s.handle(req))
+		}
+	}
+}
+
+type outcome struct {
+	result any
+	err    *rpcError
+}
+
+func (s *Server) reply(out io.Writer, req request, o outcome) {
+	// Requests without an id are notifications and get no response.
+	if len(req.ID) == 0 {
+		return
+	}
+	s.write(out, response{JSONRPC: jsonRPCVersion, ID: req.ID, Result: o.result, Error: o.err})
+}
+
+func (s *Server) handle(req request) outcome {
+	if req.JSONRPC != jsonRPCVersion {
+		return outcome{err: &rpcError{Code: codeInvalidRequest, Message: "jsonrpc must be 2.0"}}
+	}
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+		version := params.ProtocolVersion
+		if !supportedProtocolVersions[version] {
+			version = ProtocolVersion
+		}
+		result := map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": s.Name, "version": s.Version},
+		}
+		if s.Instructions != "" {
+			result["instructions"] = s.Instructions
+		}
+		return outcome{result: result}
+	case "ping":
+		return outcome{result: map[string]any{}}
+	case "tools/list":
+		return outcome{result: map[string]any{"tools": s.Tools()}}
+	case "notifications/initialized", "notifications/cancelled":
+		return outcome{}
+	default:
+		return outcome{err: &rpcError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}}
+	}
+}
+
+func (s *Server) callTool(ctx context.Context, raw json.RawMessage) outcome {
+	var params struct {
+		Name      string         `json:"name"`
+		Arguments map[string]any `json:"arguments"`
+	}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return outcome{err: &rpcError{Code: codeInvalidParams, Message: "invalid tools/call params"}}
+	}
+	tool, ok := s.tools[params.Name]
+	if !ok {
+		return outcome{err: &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + params.Name}}
+	}
+	if params.Arguments == nil {
+		params.Arguments = map[string]any{}
+	}
+	result, err := tool.Handler(ctx, params.Arguments)
+	if err != nil {
+		result = ErrorResult(err.Error())
+	}
+	if result == nil {
+		result = TextResult("")
+	}
+	return outcome{result: result}
+}
+
+func (s *Server) write(out io.Writer, resp response) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_, _ = out.Write(data)
+}
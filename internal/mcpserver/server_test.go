@@ -0,0 +1,118 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func serveLines(t *testing.T, server *Server, lines ...string) []map[string]any {
+	t.Helper()
+	var out strings.Builder
+	if err := server.Serve(context.Background(), strings.NewReader(strings.Join(lines, "\n")+"\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	var responses []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var resp map[string]any
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("bad response line %q: %v", line, err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func testServer(t *testing.T) *Server {
+	t.Helper()
+	server := New("aw", "test")
+	err := server.AddTool(Tool{
+		Name:        "echo",
+		Description: "Echo text",
+		InputSchema: map[string]any{"type": "object", "properties": map[string]any{"text": map[string]any{"type": "string"}}},
+		Handler: func(ctx context.Context, args map[string]any) (*ToolResult, error) {
+			text, _ := args["text"].(string)
+			if text == "" {
+				return nil, errors.New("text is required")
+			}
+			return TextResult(text), nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func TestServerInitializeListAndCall(t *testing.T) {
+	responses := serveLines(t, testServer(t),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+	)
+	if len(responses) != 3 {
+		t.Fatalf("expected 3 responses (notification unanswered), got %#v", responses)
+	}
+	byID := map[float64]map[string]any{}
+	for _, resp := range responses {
+		byID[resp["id"].(float64)] = resp
+	}
+	initResult := byID[1]["result"].(map[string]any)
+	if initResult["protocolVersion"] != "2025-03-26" || initResult["serverInfo"].(map[string]any)["name"] != "aw" {
+		t.Fatalf("initialize=%#v", initResult)
+	}
+	tools := byID[2]["result"].(map[string]any)["tools"].([]any)
+	if len(tools) != 1 || tools[0].(map[string]any)["name"] != "echo" || tools[0].(map[string]any)["inputSchema"] == nil {
+		t.Fatalf("tools=%#v", tools)
+	}
+	content := byID[3]["result"].(map[string]any)["content"].([]any)
+	if content[0].(map[string]any)["text"] != "hi" {
+		t.Fatalf("call=%#v", byID[3])
+	}
+}
+
+func TestServerReportsToolAndProtocolErrors(t *testing.T) {
+	responses := serveLines(t, testServer(t),
+		`not json`,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"nope"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"resources/list"}`,
+	)
+	if len(responses) != 5 {
+		t.Fatalf("responses=%#v", responses)
+	}
+	byID := map[any]map[string]any{}
+	for _, resp := range responses {
+		byID[resp["id"]] = resp
+	}
+	if byID[nil]["error"].(map[string]any)["code"] != float64(codeParseError) {
+		t.Fatalf("parse error=%#v", byID[nil])
+	}
+	if byID[float64(1)]["result"].(map[string]any)["protocolVersion"] != ProtocolVersion {
+		t.Fatalf("expected fallback protocol version, got %#v", byID[float64(1)])
+	}
+	failed := byID[float64(2)]["result"].(map[string]any)
+	if failed["isError"] != true || !strings.Contains(failed["content"].([]any)[0].(map[string]any)["text"].(string), "text is required") {
+		t.Fatalf("tool error=%#v", failed)
+	}
+	if byID[float64(3)]["error"].(map[string]any)["code"] != float64(codeInvalidParams) {
+		t.Fatalf("unknown tool=%#v", byID[float64(3)])
+	}
+	if byID[float64(4)]["error"].(map[string]any)["code"] != float64(codeMethodNotFound) {
+		t.Fatalf("unknown method=%#v", byID[float64(4)])
+	}
+}
+
+func TestAddToolRejectsDuplicates(t *testing.T) {
+	server := testServer(t)
+	if err := server.AddTool(Tool{Name: "echo", Handler: func(context.Context, map[string]any) (*ToolResult, error) { return nil, nil }}); err == nil {
+		t.Fatal("expected duplicate tool error")
+	}
+}
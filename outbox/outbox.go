@@ -0,0 +1,236 @@
+// Package outbox keeps mail and chat sends that could not reach aweb on
+// disk under the identity home and replays them later. Queued payloads are
+// already signed and, for E2EE messages, encrypted; the server deduplicates
+// replays on their message_id.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awconfig"
+	"github.com/awebai/aw/awid"
+)
+
+const (
+	// DirName is the outbox directory inside an identity home.
+	DirName = "outbox"
+
+	failedDirName = "failed"
+	baseBackoff   = 30 * time.Second
+	maxBackoff    = time.Hour
+)
+
+// Record is a queued send with its delivery attempts so far.
+type Record struct {
+	awid.OutboxItem
+	Attempts      int    `json:"attempts"`
+	NextAttemptAt string `json:"next_attempt_at,omitempty"`
+	LastError     string `json:"last_error,omitempty"`
+}
+
+// Store is a directory holding one JSON file per queued message.
+type Store struct {
+	Dir string
+	now func() time.Time
+}
+
+// New returns the store rooted at dir, usually <identity home>/outbox.
+func New(dir string) *Store {
+	return &Store{Dir: dir, now: time.Now}
+}
+
+// Enqueue implements awid.Outbox.
+func (s *Store) Enqueue(item awid.OutboxItem) error {
+	return s.write(s.Dir, Record{OutboxItem: item})
+}
+
+// List returns the queued records, oldest first.
+func (s *Store) List() ([]Record, error) {
+	return readRecords(s.Dir)
+}
+
+// ListFailed returns the records the server rejected permanently.
+func (s *Store) ListFailed() ([]Record, error) {
+	return readRecords(filepath.Join(s.Dir, failedDirName))
+}
+
+// FlushResult counts what one Flush did with the queued records.
+type FlushResult struct {
+	Delivered int `json:"delivered"`
+	Pending   int `json:"pending"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+}
+
+// Flush replays the queued records that are due, or all of them when force
+// is set. Delivered records are removed; records the server rejects with a
+// permanent client error move to the failed directory; the rest back off
+// exponentially. Records queued by another team's client are skipped.
+// Flush holds an exclusive lock on the outbox directory so concurrent
+// flushes do not replay or move the same record twice; with nothing ever
+// queued there is no directory and nothing to lock.
+// Flush stops at the first unreachable-server error, since the remaining
+// records would fail the same way.
+func (s *Store) Flush(ctx context.Context, client *awid.Client, force bool) (FlushResult, error) {
+	var result FlushResult
+	if _, err := os.Stat(s.Dir); errors.Is(err, os.ErrNotExist) {
+		return result, nil
+	}
+	unlock, err := awconfig.LockExclusive(s.Dir + ".lock")
+	if err != nil {
+		return result, err
+	}
+	defer func() { _ = unlock.Close() }()
+
+	records, err := s.List()
+	if err != nil {
+		return result, err
+	}
+	now := s.clock()
+	for i, record := range records {
+		if record.TeamID != "" && client.TeamID() != "" && record.TeamID != client.TeamID() {
+			result.Skipped++
+			continue
+		}
+		if !force && record.NextAttemptAt != "" {
+			if next, err := time.Parse(time.RFC3339, record.NextAttemptAt); err == nil && now.Before(next) {
+				result.Pending++
+				continue
+			}
+		}
+		sendErr := client.ReplayOutboxItem(ctx, record.OutboxItem)
+		switch {
+		case sendErr == nil:
+			if err := os.Remove(s.path(s.Dir, record.MessageID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return result, err
+			}
+			result.Delivered++
+			continue
+		case isPermanent(sendErr):
+			record.LastError = sendErr.Error()
+			if err := s.write(filepath.Join(s.Dir, failedDirName), record); err != nil {
+				return result, err
+			}
+			if err := os.Remove(s.path(s.Dir, record.MessageID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return result, err
+			}
+			result.Failed++
+			continue
+		}
+		record.Attempts++
+		record.NextAttemptAt = now.Add(backoff(record.Attempts)).UTC().Format(time.RFC3339)
+		record.LastError = sendErr.Error()
+		if err := s.write(s.Dir, record); err != nil {
+			return result, err
+		}
+		result.Pending++
+		if ctx.Err() != nil || awid.IsServerUnreachable(sendErr) {
+			result.Pending += len(records) - i - 1
+			return result, ctx.Err()
+		}
+	}
+	return result, nil
+}
+
+// backoff is the wait after the given number of failed attempts.
+func backoff(attempts int) time.Duration {
+	wait := baseBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxBackoff {
+		return maxBackoff
+	}
+	return wait
+}
+
+// isPermanent reports whether retrying the same signed payload cannot
+// succeed: a client error other than a timeout or rate limit.
+func isPermanent(err error) bool {
+	code, ok := awid.HTTPStatusCode(err)
+	if !ok || code < 400 || code >= 500 {
+		return false
+	}
+	return code != http.StatusRequestTimeout && code != http.StatusTooManyRequests
+}
+
+func (s *Store) clock() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+	return s.now()
+}
+
+func (s *Store) path(dir, messageID string) string {
+	return filepath.Join(dir, messageID+".json")
+}
+
+func (s *Store) write(dir string, record Record) error {
+	if !validMessageID(record.MessageID) {
+		return fmt.Errorf("outbox: invalid message_id %q", record.MessageID)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	return awid.AtomicWriteFile(s.path(dir, record.MessageID), append(data, '\n'))
+}
+
+func readRecords(dir string) ([]Record, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var record Record
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("outbox: %s: %w", entry.Name(), err)
+		}
+		records = append(records, record)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].QueuedAt != records[j].QueuedAt {
+			return records[i].QueuedAt < records[j].QueuedAt
+		}
+		return records[i].MessageID < records[j].MessageID
+	})
+	return records, nil
+}
+
+// validMessageID keeps message IDs usable as file names.
+func validMessageID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
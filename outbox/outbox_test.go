@@ -0,0 +1,149 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *awid.Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c, err := awid.New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func testItem(id string) awid.OutboxItem {
+	return awid.OutboxItem{
+		Kind:      awid.OutboxKindMail,
+		Path:      "/v1/messages",
+		MessageID: id,
+		Payload:   json.RawMessage(`{"message_id":"` + id + `","body":"hi"}`),
+		QueuedAt:  "2026-01-02T03:04:05Z",
+	}
+}
+
+func TestFlushDeliversAndBacksOff(t *testing.T) {
+	store := New(t.TempDir())
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	if err := store.Enqueue(testItem("m1")); err != nil {
+		t.Fatal(err)
+	}
+
+	status := http.StatusServiceUnavailable
+	var posts int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	result, err := store.Flush(context.Background(), client, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Pending != 1 || result.Delivered != 0 {
+		t.Fatalf("result=%+v", result)
+	}
+	records, err := store.List()
+	if err != nil || len(records) != 1 {
+		t.Fatalf("records=%+v err=%v", records, err)
+	}
+	if records[0].Attempts != 1 || records[0].NextAttemptAt != "2026-01-02T03:04:35Z" || records[0].LastError == "" {
+		t.Fatalf("record=%+v", records[0])
+	}
+
+	postsBefore := posts
+	status = http.StatusOK
+	if result, err = store.Flush(context.Background(), client, false); err != nil || result.Pending != 1 || posts != postsBefore {
+		t.Fatalf("flush before backoff: result=%+v err=%v posts=%d", result, err, posts-postsBefore)
+	}
+	if result, err = store.Flush(context.Background(), client, true); err != nil || result.Delivered != 1 {
+		t.Fatalf("forced flush: result=%+v err=%v", result, err)
+	}
+	if records, _ := store.List(); len(records) != 0 {
+		t.Fatalf("delivered record still queued: %+v", records)
+	}
+}
+
+func TestFlushMovesRejectedItemsAside(t *testing.T) {
+	store := New(t.TempDir())
+	for _, id := range []string{"dup", "bad", "conflict"} {
+		if err := store.Enqueue(testItem(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			MessageID string `json:"message_id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch body.MessageID {
+		case "dup":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"detail":"duplicate message_id"}`))
+		case "conflict":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"detail":"session is closed"}`))
+		default:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{}`))
+		}
+	})
+
+	result, err := store.Flush(context.Background(), client, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Delivered != 1 || result.Failed != 2 {
+		t.Fatalf("result=%+v", result)
+	}
+	failed, err := store.ListFailed()
+	if err != nil || len(failed) != 2 || failed[0].MessageID != "bad" || failed[1].MessageID != "conflict" {
+		t.Fatalf("failed=%+v err=%v", failed, err)
+	}
+	if records, _ := store.List(); len(records) != 0 {
+		t.Fatalf("records=%+v", records)
+	}
+}
+
+func TestFlushWithNothingQueuedLeavesHomeUntouched(t *testing.T) {
+	home := t.TempDir()
+	store := New(filepath.Join(home, DirName))
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+	})
+	if _, err := store.Flush(context.Background(), client, true); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(home); len(entries) != 0 {
+		t.Fatalf("flush created %v", entries)
+	}
+}
+
+func TestEnqueueRejectsUnsafeMessageID(t *testing.T) {
+	store := New(t.TempDir())
+	if err := store.Enqueue(testItem("../escape")); err == nil {
+		t.Fatal("path-like message_id accepted")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{1: 30 * time.Second, 2: time.Minute, 4: 4 * time.Minute, 12: time.Hour} {
+		if got := backoff(attempts); got != want {
+			t.Errorf("backoff(%d)=%s, want %s", attempts, got, want)
+		}
+	}
+}
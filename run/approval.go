@@ -0,0 +1,200 @@
+package run
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultApprovalTimeoutSeconds = 600
+
+// ApprovalConfig routes profile approval_required actions to a human.
+// Approver is an agent address (alias or namespace/alias) that is asked over
+// chat when no local aw run or terminal can answer.
+type ApprovalConfig struct {
+	Approver       string `json:"approver,omitempty"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+}
+
+func (a ApprovalConfig) Timeout() time.Duration {
+	if a.TimeoutSeconds <= 0 {
+		return DefaultApprovalTimeoutSeconds * time.Second
+	}
+	return time.Duration(a.TimeoutSeconds) * time.Second
+}
+
+func (a ApprovalConfig) validate() error {
+	if a.TimeoutSeconds < 0 {
+		return fmt.Errorf("approval.timeout_seconds must be >= 0")
+	}
+	return nil
+}
+
+// ApprovalRequest asks the operator of a running aw run to allow one
+// gated action. Code is what the team owner signs with
+// `aw id team approve`.
+type ApprovalRequest struct {
+	Action         string `json:"action"`
+	Detail         string `json:"detail,omitempty"`
+	Code           string `json:"code,omitempty"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+}
+
+// ApprovalDecision is the answer to an ApprovalRequest. An approval carries
+// the team owner's Signature; aw run only relays it, and the gated command
+// verifies it against the team key, so nothing that can reach the control
+// socket can approve on its own.
+type ApprovalDecision struct {
+	ID        string `json:"id"`
+	Approved  bool   `json:"approved"`
+	Approver  string `json:"approver,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// ParseApprovalArgs splits the argument of /approve and /deny into the
+// pending request id and the owner's signature. Either may be omitted: a
+// lone number is an id, anything else a signature for the oldest request.
+func ParseApprovalArgs(text string) (id, signature string) {
+	fields := strings.Fields(text)
+	switch len(fields) {
+	case 0:
+		return "", ""
+	case 1:
+		if _, err := strconv.Atoi(fields[0]); err == nil {
+			return fields[0], ""
+		}
+		return "", fields[0]
+	default:
+		return fields[0], fields[1]
+	}
+}
+
+type pendingApproval struct {
+	id       string
+	request  ApprovalRequest
+	decision chan ApprovalDecision
+}
+
+// requestApproval queues req, announces it to the loop and blocks until an
+// operator answers, the request times out or the socket stops.
+func (s *ControlSocket) requestApproval(req ApprovalRequest) ControlSocketResponse {
+	if strings.TrimSpace(req.Action) == "" {
+		return ControlSocketResponse{Error: "approval action is required"}
+	}
+	timeout := ApprovalConfig{TimeoutSeconds: req.TimeoutSeconds}.Timeout()
+
+	s.mu.Lock()
+	if s.listener == nil {
+		s.mu.Unlock()
+		return ControlSocketResponse{Error: "aw run is shutting down"}
+	}
+	s.approvalSeq++
+	pending := &pendingApproval{
+		id:       strconv.Itoa(s.approvalSeq),
+		request:  req,
+		decision: make(chan ApprovalDecision, 1),
+	}
+	s.approvals[pending.id] = pending
+	s.mu.Unlock()
+
+	announce := fmt.Sprintf("approval needed [%s]: %s", pending.id, req.Action)
+	if detail := strings.TrimSpace(req.Detail); detail != "" {
+		announce += " " + detail
+	}
+	if code := strings.TrimSpace(req.Code); code != "" {
+		announce += fmt.Sprintf(" — the team owner signs it with `aw id team approve %s`; enter /approve %s <signature> or /deny %s", code, pending.id, pending.id)
+	} else {
+		announce += fmt.Sprintf(" — /deny %s", pending.id)
+	}
+	if resp := s.deliver(ControlEvent{Type: ControlApprovalRequested, Text: announce}); !resp.OK {
+		s.dropApproval(pending.id)
+		return resp
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case decision := <-pending.decision:
+		return ControlSocketResponse{OK: true, Approval: &decision}
+	case <-timer.C:
+		s.dropApproval(pending.id)
+		return ControlSocketResponse{OK: true, Approval: &ApprovalDecision{
+			ID:     pending.id,
+			Reason: fmt.Sprintf("no decision within %s", timeout),
+		}}
+	case <-s.done:
+		s.dropApproval(pending.id)
+		return ControlSocketResponse{OK: true, Approval: &ApprovalDecision{
+			ID:     pending.id,
+			Reason: "aw run stopped",
+		}}
+	}
+}
+
+// ResolveApproval answers the pending approval id, or the oldest one when id
+// is empty. It returns the resolved request's action. Approving needs the
+// team owner's signature; anyone may deny.
+func (s *ControlSocket) ResolveApproval(id string, approved bool, approver, signature string) (string, error) {
+	signature = strings.TrimSpace(signature)
+	if approved && signature == "" {
+		return "", fmt.Errorf("approving needs the team owner's signature from `aw id team approve`")
+	}
+	s.mu.Lock()
+	id = strings.TrimSpace(id)
+	if id == "" {
+		ids := s.pendingApprovalIDs()
+		if len(ids) == 0 {
+			s.mu.Unlock()
+			return "", fmt.Errorf("no pending approvals")
+		}
+		id = ids[0]
+	}
+	pending, ok := s.approvals[id]
+	if ok {
+		delete(s.approvals, id)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("no pending approval %q", id)
+	}
+	decision := ApprovalDecision{ID: id, Approved: approved, Approver: approver}
+	if approved {
+		decision.Signature = signature
+	}
+	pending.decision <- decision
+	return pending.request.Action, nil
+}
+
+// PendingApprovals returns the waiting requests in arrival order.
+func (s *ControlSocket) PendingApprovals() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var lines []string
+	for _, id := range s.pendingApprovalIDs() {
+		lines = append(lines, id+": "+s.approvals[id].request.Action)
+	}
+	return lines
+}
+
+func (s *ControlSocket) dropApproval(id string) {
+	s.mu.Lock()
+	delete(s.approvals, id)
+	s.mu.Unlock()
+}
+
+// pendingApprovalIDs must be called with s.mu held.
+func (s *ControlSocket) pendingApprovalIDs() []string {
+	ids := make([]string, 0, len(s.approvals))
+	for id := range s.approvals {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, _ := strconv.Atoi(ids[i])
+		b, _ := strconv.Atoi(ids[j])
+		return a < b
+	})
+	return ids
+}
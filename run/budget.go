@@ -0,0 +1,415 @@
+package run
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/awebai/aw/awconfig"
+)
+
+// BudgetAction is what the loop does once a budget is exceeded.
+type BudgetAction string
+
+const (
+	// BudgetActionWarn only prints a warning.
+	BudgetActionWarn BudgetAction = "warn"
+	// BudgetActionPause pauses the loop after the run that crossed the
+	// budget; headless loops stop instead.
+	BudgetActionPause BudgetAction = "pause"
+	// BudgetActionRefuseAutofeed turns autofeed off and refuses to turn it
+	// back on, so only mail and chat can wake the agent.
+	BudgetActionRefuseAutofeed BudgetAction = "refuse_autofeed"
+
+	DefaultBudgetWarnAt = 0.8
+
+	// ledgerRetention bounds how long per-day and per-session totals stay
+	// in the ledger file.
+	ledgerRetention = 31 * 24 * time.Hour
+	ledgerFileName  = "run-ledger.json"
+)
+
+// BudgetConfig is the "budget" block of run.json. Zero limits are unset.
+type BudgetConfig struct {
+	SessionUSD    float64      `json:"session_usd,omitempty"`
+	DailyUSD      float64      `json:"daily_usd,omitempty"`
+	SessionTokens int          `json:"session_tokens,omitempty"`
+	DailyTokens   int          `json:"daily_tokens,omitempty"`
+	Action        BudgetAction `json:"action,omitempty"`
+	WarnAt        float64      `json:"warn_at,omitempty"`
+}
+
+func (b BudgetConfig) enabled() bool {
+	return b.SessionUSD > 0 || b.DailyUSD > 0 || b.SessionTokens > 0 || b.DailyTokens > 0
+}
+
+func (b BudgetConfig) action() BudgetAction {
+	if b.Action == "" {
+		return BudgetActionWarn
+	}
+	return b.Action
+}
+
+func (b BudgetConfig) warnAt() float64 {
+	if b.WarnAt <= 0 {
+		return DefaultBudgetWarnAt
+	}
+	return b.WarnAt
+}
+
+func (b BudgetConfig) validate() error {
+	if b.SessionUSD < 0 || b.DailyUSD < 0 || b.SessionTokens < 0 || b.DailyTokens < 0 {
+		return fmt.Errorf("budget limits must be >= 0")
+	}
+	switch b.Action {
+	case "", BudgetActionWarn, BudgetActionPause, BudgetActionRefuseAutofeed:
+	default:
+		return fmt.Errorf("budget.action must be one of warn, pause, refuse_autofeed")
+	}
+	if b.WarnAt < 0 || b.WarnAt >= 1 {
+		return fmt.Errorf("budget.warn_at must be >= 0 and < 1")
+	}
+	return nil
+}
+
+// LedgerTotals accumulates provider spend for one bucket of runs.
+type LedgerTotals struct {
+	Runs                     int       `json:"runs"`
+	CostUSD                  float64   `json:"cost_usd"`
+	InputTokens              int       `json:"input_tokens"`
+	CacheCreationInputTokens int       `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int       `json:"cache_read_input_tokens"`
+	OutputTokens             int       `json:"output_tokens"`
+	UpdatedAt                time.Time `json:"updated_at"`
+}
+
+// Tokens is every input and output token the bucket consumed.
+func (t LedgerTotals) Tokens() int {
+	return t.InputTokens + t.CacheCreationInputTokens + t.CacheReadInputTokens + t.OutputTokens
+}
+
+func (t *LedgerTotals) add(spend RunSpend) {
+	t.Runs++
+	t.CostUSD += spend.CostUSD
+	t.InputTokens += spend.Usage.InputTokens
+	t.CacheCreationInputTokens += spend.Usage.CacheCreationInputTokens
+	t.CacheReadInputTokens += spend.Usage.CacheReadInputTokens
+	t.OutputTokens += spend.Usage.OutputTokens
+	t.UpdatedAt = spend.At
+}
+
+// LedgerDay is one local calendar day of spend, split by wake source.
+type LedgerDay struct {
+	LedgerTotals
+	WakeSources map[string]*LedgerTotals `json:"wake_sources,omitempty"`
+}
+
+// RunLedger is the persisted spend ledger for one worktree's aw run.
+type RunLedger struct {
+	Days     map[string]*LedgerDay    `json:"days"`
+	Sessions map[string]*LedgerTotals `json:"sessions"`
+}
+
+// RunSpend is what one provider run consumed.
+type RunSpend struct {
+	At         time.Time
+	SessionID  string
+	WakeSource string
+	CostUSD    float64
+	Usage      UsageStats
+}
+
+// LedgerPath is where aw run keeps the spend ledger for a worktree.
+func LedgerPath(workingDir string) string {
+	return filepath.Join(workingDir, ".aw", ledgerFileName)
+}
+
+func ledgerDayKey(t time.Time) string {
+	return t.Local().Format("2006-01-02")
+}
+
+// LoadRunLedger reads the ledger at path. A missing file is an empty ledger.
+func LoadRunLedger(path string) (*RunLedger, error) {
+	ledger := &RunLedger{Days: map[string]*LedgerDay{}, Sessions: map[string]*LedgerTotals{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ledger, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, ledger); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if ledger.Days == nil {
+		ledger.Days = map[string]*LedgerDay{}
+	}
+	if ledger.Sessions == nil {
+		ledger.Sessions = map[string]*LedgerTotals{}
+	}
+	return ledger, nil
+}
+
+// RecordRunSpend adds one run to the ledger at path. It holds a lock on
+// path+".lock" across the read and the write so concurrent loops in one
+// worktree do not drop each other's spend.
+func RecordRunSpend(path string, spend RunSpend) (*RunLedger, error) {
+	unlock, err := awconfig.LockExclusive(path + ".lock")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = unlock.Close() }()
+
+	ledger, err := LoadRunLedger(path)
+	if err != nil {
+		return nil, err
+	}
+	ledger.add(spend)
+	ledger.prune(spend.At)
+	data, err := json.MarshalIndent(ledger, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	data = append(data, '\n')
+	if err := atomicWriteFileMode(path, data, 0o600); err != nil {
+		return nil, err
+	}
+	return ledger, nil
+}
+
+func (l *RunLedger) add(spend RunSpend) {
+	dayKey := ledgerDayKey(spend.At)
+	day := l.Days[dayKey]
+	if day == nil {
+		day = &LedgerDay{}
+		l.Days[dayKey] = day
+	}
+	day.add(spend)
+	source := strings.TrimSpace(spend.WakeSource)
+	if source == "" {
+		source = wakeSourceUser
+	}
+	if day.WakeSources == nil {
+		day.WakeSources = map[string]*LedgerTotals{}
+	}
+	if day.WakeSources[source] == nil {
+		day.WakeSources[source] = &LedgerTotals{}
+	}
+	day.WakeSources[source].add(spend)
+	if sessionID := strings.TrimSpace(spend.SessionID); sessionID != "" {
+		if l.Sessions[sessionID] == nil {
+			l.Sessions[sessionID] = &LedgerTotals{}
+		}
+		l.Sessions[sessionID].add(spend)
+	}
+}
+
+func (l *RunLedger) prune(now time.Time) {
+	cutoff := now.Add(-ledgerRetention)
+	for key := range l.Days {
+		day, err := time.ParseInLocation("2006-01-02", key, time.Local)
+		if err != nil || day.Before(cutoff) {
+			delete(l.Days, key)
+		}
+	}
+	for key, totals := range l.Sessions {
+		if totals == nil || totals.UpdatedAt.Before(cutoff) {
+			delete(l.Sessions, key)
+		}
+	}
+}
+
+// Day returns the totals for the local day containing t.
+func (l *RunLedger) Day(t time.Time) LedgerTotals {
+	if l == nil || l.Days[ledgerDayKey(t)] == nil {
+		return LedgerTotals{}
+	}
+	return l.Days[ledgerDayKey(t)].LedgerTotals
+}
+
+// Session returns the totals recorded for a provider session.
+func (l *RunLedger) Session(sessionID string) LedgerTotals {
+	if l == nil || l.Sessions[strings.TrimSpace(sessionID)] == nil {
+		return LedgerTotals{}
+	}
+	return *l.Sessions[strings.TrimSpace(sessionID)]
+}
+
+// budgetCheck is one limit compared against the ledger.
+type budgetCheck struct {
+	key   string
+	label string
+	used  float64
+	limit float64
+	money bool
+}
+
+func (c budgetCheck) describe() string {
+	if c.money {
+		return fmt.Sprintf("%s $%.2f of $%.2f", c.label, c.used, c.limit)
+	}
+	return fmt.Sprintf("%s %s of %s tokens", c.label, formatTokenCount(int(c.used)), formatTokenCount(int(c.limit)))
+}
+
+func budgetChecks(budget BudgetConfig, ledger *RunLedger, sessionID string, now time.Time) []budgetCheck {
+	day := ledger.Day(now)
+	var checks []budgetCheck
+	if budget.DailyUSD > 0 {
+		checks = append(checks, budgetCheck{key: "daily_usd", label: "daily spend", used: day.CostUSD, limit: budget.DailyUSD, money: true})
+	}
+	if budget.DailyTokens > 0 {
+		checks = append(checks, budgetCheck{key: "daily_tokens", label: "daily usage", used: float64(day.Tokens()), limit: float64(budget.DailyTokens)})
+	}
+	if strings.TrimSpace(sessionID) != "" {
+		session := ledger.Session(sessionID)
+		if budget.SessionUSD > 0 {
+			checks = append(checks, budgetCheck{key: "session_usd:" + sessionID, label: "session spend", used: session.CostUSD, limit: budget.SessionUSD, money: true})
+		}
+		if budget.SessionTokens > 0 {
+			checks = append(checks, budgetCheck{key: "session_tokens:" + sessionID, label: "session usage", used: float64(session.Tokens()), limit: float64(budget.SessionTokens)})
+		}
+	}
+	return checks
+}
+
+// budgetExceeded returns the exceeded limits, announcing each warning and
+// overrun once per loop.
+func (l *Loop) budgetExceeded(st *state) []budgetCheck {
+	if st.Ledger == nil || !st.Budget.enabled() {
+		return nil
+	}
+	if st.BudgetNotices == nil {
+		st.BudgetNotices = map[string]bool{}
+	}
+	var exceeded []budgetCheck
+	for _, check := range budgetChecks(st.Budget, st.Ledger, st.SessionID, l.Now()) {
+		switch {
+		case check.used >= check.limit:
+			exceeded = append(exceeded, check)
+			if !st.BudgetNotices["over:"+check.key] {
+				st.BudgetNotices["over:"+check.key] = true
+				l.printf("warning: budget exceeded: %s\n", check.describe())
+			}
+		case check.used >= check.limit*st.Budget.warnAt():
+			if !st.BudgetNotices["warn:"+check.key] {
+				st.BudgetNotices["warn:"+check.key] = true
+				l.printf("warning: budget: %s used\n", check.describe())
+			}
+		}
+	}
+	return exceeded
+}
+
+var errBudgetExhausted = errors.New("run budget exhausted")
+
+// enforceBudget applies the configured action once a budget is exceeded.
+// It returns errBudgetExhausted when a loop without interactive controls
+// has to stop.
+func (l *Loop) enforceBudget(st *state) error {
+	exceeded := l.budgetExceeded(st)
+	if len(exceeded) == 0 {
+		return nil
+	}
+	switch st.Budget.action() {
+	case BudgetActionPause:
+		if !l.hasControls() {
+			return fmt.Errorf("%w: %s", errBudgetExhausted, exceeded[0].describe())
+		}
+		st.PauseAfterRun = true
+	case BudgetActionRefuseAutofeed:
+		if st.Autofeed {
+			st.Autofeed = false
+			l.announceAutofeedState(false, "off: budget exceeded. only comms can wake the agent.")
+		}
+	}
+	return nil
+}
+
+// autofeedRefused reports whether the budget forbids turning autofeed on.
+func (l *Loop) autofeedRefused(st *state) bool {
+	if st.Budget.action() != BudgetActionRefuseAutofeed || st.Ledger == nil {
+		return false
+	}
+	for _, check := range budgetChecks(st.Budget, st.Ledger, st.SessionID, l.Now()) {
+		if check.used >= check.limit {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Loop) loadLedger(opts LoopOptions, st *state) {
+	if strings.TrimSpace(opts.LedgerPath) == "" {
+		return
+	}
+	ledger, err := LoadRunLedger(opts.LedgerPath)
+	if err != nil {
+		l.printf("warning: budget ledger unavailable: %v\n", err)
+		return
+	}
+	st.Ledger = ledger
+}
+
+// recordRunSpend adds the finished run to the ledger.
+func (l *Loop) recordRunSpend(opts LoopOptions, st *state) {
+	if strings.TrimSpace(opts.LedgerPath) == "" || (!st.HasRunUsage && st.LastRunCostUSD == 0) {
+		return
+	}
+	spend := RunSpend{
+		At:         l.Now(),
+		SessionID:  st.SessionID,
+		WakeSource: st.RunWakeSource,
+		CostUSD:    st.LastRunCostUSD,
+	}
+	if st.HasRunUsage {
+		spend.Usage = st.LastRunUsage
+	}
+	ledger, err := RecordRunSpend(opts.LedgerPath, spend)
+	if err != nil {
+		l.printf("warning: could not record run spend: %v\n", err)
+		return
+	}
+	st.Ledger = ledger
+}
+
+// formatBudgetStatus renders today's spend for the status line, against
+// the daily limits when they are set.
+func formatBudgetStatus(st *state, now time.Time) string {
+	if st == nil || st.Ledger == nil {
+		return ""
+	}
+	day := st.Ledger.Day(now)
+	var parts []string
+	switch {
+	case st.Budget.DailyUSD > 0:
+		parts = append(parts, fmt.Sprintf("today $%.2f/$%.2f", day.CostUSD, st.Budget.DailyUSD))
+	case day.CostUSD > 0:
+		parts = append(parts, fmt.Sprintf("today $%.2f", day.CostUSD))
+	}
+	if st.Budget.DailyTokens > 0 {
+		parts = append(parts, fmt.Sprintf("%s/%s tok", formatTokenCount(day.Tokens()), formatTokenCount(st.Budget.DailyTokens)))
+	}
+	return strings.Join(parts, " · ")
+}
+
+const (
+	wakeSourceUser       = "user"
+	wakeSourceBasePrompt = "base_prompt"
+	wakeSourceDispatch   = "dispatch"
+)
+
+// formatTokenCount shortens token counts for status and warning lines.
+func formatTokenCount(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
@@ -0,0 +1,168 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecordRunSpendAccumulatesBySessionDayAndWakeSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".aw", "run-ledger.json")
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+
+	if _, err := RecordRunSpend(path, RunSpend{At: now, SessionID: "s-1", WakeSource: "actionable_mail", CostUSD: 0.25, Usage: UsageStats{InputTokens: 100, OutputTokens: 50}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RecordRunSpend(path, RunSpend{At: now.Add(time.Hour), SessionID: "s-1", WakeSource: "work_available", CostUSD: 0.5, Usage: UsageStats{CacheReadInputTokens: 10}}); err != nil {
+		t.Fatal(err)
+	}
+	// Spend older than the retention window is pruned on the next write.
+	if _, err := RecordRunSpend(path, RunSpend{At: now.Add(-40 * 24 * time.Hour), SessionID: "old", CostUSD: 9}); err != nil {
+		t.Fatal(err)
+	}
+	ledger, err := RecordRunSpend(path, RunSpend{At: now.Add(2 * time.Hour), SessionID: "s-2", CostUSD: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := ledger.Day(now)
+	if day.Runs != 3 || day.CostUSD != 1.75 || day.Tokens() != 160 {
+		t.Fatalf("day totals=%#v", day)
+	}
+	if session := ledger.Session("s-1"); session.Runs != 2 || session.CostUSD != 0.75 {
+		t.Fatalf("session totals=%#v", session)
+	}
+	sources := ledger.Days["2026-03-10"].WakeSources
+	if sources["actionable_mail"].CostUSD != 0.25 || sources["work_available"].CostUSD != 0.5 || sources[wakeSourceUser].CostUSD != 1 {
+		t.Fatalf("wake sources=%#v", sources)
+	}
+	if _, ok := ledger.Sessions["old"]; ok {
+		t.Fatal("expected old session to be pruned")
+	}
+
+	reloaded, err := LoadRunLedger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Day(now).CostUSD != 1.75 {
+		t.Fatalf("reloaded day=%#v", reloaded.Day(now))
+	}
+}
+
+func TestLoopPausesHeadlessRunWhenDailyBudgetIsExhausted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run-ledger.json")
+	cost := 0.6
+	loop := NewLoop(fakeProvider{event: &Event{Type: EventDone, Session: "s-1", CostUSD: &cost}}, &bytes.Buffer{})
+	runs := 0
+	loop.Runner = func(ctx context.Context, dir string, argv []string, onLine func(string), stderrSink any) error {
+		runs++
+		onLine("done")
+		return nil
+	}
+	loop.Sleep = func(ctx context.Context, d time.Duration) error { return nil }
+	loop.Dispatch = &fakeDispatcher{decisions: []DispatchDecision{{Mission: "one"}, {Mission: "two"}, {Mission: "three"}}}
+
+	opts := LoopOptions{MaxRuns: 3, LedgerPath: path, Budget: BudgetConfig{DailyUSD: 1, Action: BudgetActionPause}}
+	err := loop.Run(context.Background(), opts)
+	if !errors.Is(err, errBudgetExhausted) {
+		t.Fatalf("expected budget error, got %v", err)
+	}
+	if runs != 2 {
+		t.Fatalf("expected the loop to stop after the run that crossed the budget, ran %d", runs)
+	}
+
+	// A restart does not reset the day's spend.
+	err = loop.Run(context.Background(), opts)
+	if !errors.Is(err, errBudgetExhausted) || runs != 2 {
+		t.Fatalf("restart err=%v runs=%d", err, runs)
+	}
+}
+
+func TestEnforceBudgetRefusesAutofeed(t *testing.T) {
+	out := &bytes.Buffer{}
+	loop := NewLoop(fakeProvider{}, out)
+	now := time.Now()
+	ledger := &RunLedger{Days: map[string]*LedgerDay{}, Sessions: map[string]*LedgerTotals{}}
+	ledger.add(RunSpend{At: now, SessionID: "s-1", Usage: UsageStats{InputTokens: 900, OutputTokens: 200}})
+	st := &state{
+		Autofeed:  true,
+		SessionID: "s-1",
+		Ledger:    ledger,
+		Budget:    BudgetConfig{SessionTokens: 1000, Action: BudgetActionRefuseAutofeed},
+	}
+
+	if err := loop.enforceBudget(st); err != nil {
+		t.Fatal(err)
+	}
+	if st.Autofeed {
+		t.Fatal("expected autofeed to be turned off")
+	}
+	loop.applyControlEvent(ControlEvent{Type: ControlAutofeedOn}, st, false, nil)
+	if st.Autofeed {
+		t.Fatal("expected /autofeed on to be refused while over budget")
+	}
+	if !strings.Contains(out.String(), "budget exceeded: session usage 1.1k of 1.0k tokens") {
+		t.Fatalf("output=%q", out.String())
+	}
+}
+
+func TestRecordRunSpendConcurrentWritersKeepEveryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ledgerFileName)
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := RecordRunSpend(path, RunSpend{At: now, SessionID: "s-1", CostUSD: 1}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	ledger, err := LoadRunLedger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ledger.Session("s-1").Runs; got != 20 {
+		t.Fatalf("runs=%d, want 20", got)
+	}
+}
+
+func TestFormatWaitStatusShowsDailyBudget(t *testing.T) {
+	ledger := &RunLedger{Days: map[string]*LedgerDay{}, Sessions: map[string]*LedgerTotals{}}
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.Local)
+	ledger.add(RunSpend{At: now, CostUSD: 1.5})
+	st := &state{Ledger: ledger, Budget: BudgetConfig{DailyUSD: 20}}
+
+	got := formatWaitStatus("waiting for work", st, ConnDisconnected, now)
+	if got != "waiting for work · today $1.50/$20.00" {
+		t.Fatalf("status=%q", got)
+	}
+}
+
+func TestResolveSettingsValidatesBudget(t *testing.T) {
+	for _, budget := range []BudgetConfig{
+		{DailyUSD: -1},
+		{Action: "stop"},
+		{WarnAt: 1.5},
+	} {
+		if _, err := ResolveSettings(UserConfig{Budget: &budget}, SettingOverrides{}); err == nil {
+			t.Fatalf("expected error for %#v", budget)
+		}
+	}
+	settings, err := ResolveSettings(UserConfig{Budget: &BudgetConfig{DailyUSD: 5, Action: BudgetActionPause}}, SettingOverrides{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Budget.DailyUSD != 5 || settings.Budget.action() != BudgetActionPause {
+		t.Fatalf("budget=%#v", settings.Budget)
+	}
+}
@@ -0,0 +1,68 @@
+package run
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const taskClaimReleaseTimeout = 10 * time.Second
+
+// TaskClaims keeps the workspace's task claims alive while a loop runs, so
+// teammates do not reclaim work the agent is still doing, and releases them
+// when the loop exits.
+type TaskClaims struct {
+	// Heartbeat refreshes every claim the workspace holds.
+	Heartbeat func(ctx context.Context) error
+	// Release gives up the workspace's claims.
+	Release func(ctx context.Context) error
+	// Interval is the time between heartbeats.
+	Interval time.Duration
+}
+
+// keep sends a heartbeat at once and then every Interval until ctx ends.
+// warn is called when heartbeats start failing, not on every failure.
+func (c *TaskClaims) keep(ctx context.Context, warn func(error)) {
+	failing := false
+	for {
+		err := c.Heartbeat(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil && !failing {
+			warn(err)
+		}
+		failing = err != nil
+		if SleepWithContext(ctx, c.Interval) != nil {
+			return
+		}
+	}
+}
+
+// keepTaskClaims heartbeats l.TaskClaims in the background and returns a
+// function that stops and releases the claims. Claims stay put when the
+// session lease was lost, since the session that took it over now answers
+// for this identity.
+func (l *Loop) keepTaskClaims(ctx context.Context, st *state) func() {
+	claimsCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		l.TaskClaims.keep(claimsCtx, func(err error) {
+			l.printf("warning: could not refresh task claims: %v\n", err)
+		})
+	}()
+	return func() {
+		cancel()
+		wg.Wait()
+		if st.LeaseLost || l.TaskClaims.Release == nil {
+			return
+		}
+		releaseCtx, cancelRelease := context.WithTimeout(context.Background(), taskClaimReleaseTimeout)
+		defer cancelRelease()
+		if err := l.TaskClaims.Release(releaseCtx); err != nil {
+			l.printf("warning: could not release task claims: %v\n", err)
+		}
+	}
+}
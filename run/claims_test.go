@@ -0,0 +1,72 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTaskClaimsKeepWarnsOncePerFailureStreak(t *testing.T) {
+	var heartbeats, warnings atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	claims := &TaskClaims{
+		Interval: time.Millisecond,
+		Heartbeat: func(context.Context) error {
+			n := heartbeats.Add(1)
+			if n == 6 {
+				cancel()
+			}
+			if n == 3 {
+				return nil
+			}
+			return errors.New("unavailable")
+		},
+	}
+
+	claims.keep(ctx, func(error) { warnings.Add(1) })
+	if heartbeats.Load() != 6 || warnings.Load() != 2 {
+		t.Fatalf("heartbeats=%d warnings=%d", heartbeats.Load(), warnings.Load())
+	}
+}
+
+func TestKeepTaskClaimsReleasesOnStopUnlessLeaseLost(t *testing.T) {
+	var out bytes.Buffer
+	var heartbeats, releases atomic.Int32
+	loop := NewLoop(ClaudeProvider{}, &out)
+	loop.TaskClaims = &TaskClaims{
+		Interval: time.Hour,
+		Heartbeat: func(context.Context) error {
+			heartbeats.Add(1)
+			return nil
+		},
+		Release: func(context.Context) error {
+			releases.Add(1)
+			return errors.New("server unavailable")
+		},
+	}
+
+	st := &state{}
+	stop := loop.keepTaskClaims(context.Background(), st)
+	deadline := time.Now().Add(2 * time.Second)
+	for heartbeats.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	stop()
+	if heartbeats.Load() != 1 || releases.Load() != 1 {
+		t.Fatalf("heartbeats=%d releases=%d", heartbeats.Load(), releases.Load())
+	}
+	if !strings.Contains(out.String(), "could not release task claims") {
+		t.Fatalf("output=%q", out.String())
+	}
+
+	st.LeaseLost = true
+	loop.keepTaskClaims(context.Background(), st)()
+	if releases.Load() != 1 {
+		t.Fatalf("claims released after the lease was lost: releases=%d", releases.Load())
+	}
+}
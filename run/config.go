@@ -25,6 +25,8 @@ type UserConfig struct {
 	WaitSeconds       *int            `json:"wait_seconds"`
 	IdleWaitSeconds   *int            `json:"idle_wait_seconds"`
 	Services          []ServiceConfig `json:"services"`
+	Budget            *BudgetConfig   `json:"budget,omitempty"`
+	Approval          *ApprovalConfig `json:"approval,omitempty"`
 }
 
 type Settings struct {
@@ -34,6 +36,8 @@ type Settings struct {
 	WaitSeconds       int
 	IdleWaitSeconds   int
 	Services          []ServiceConfig
+	Budget            BudgetConfig
+	Approval          ApprovalConfig
 }
 
 type SettingOverrides struct {
@@ -129,6 +133,12 @@ func ResolveSettings(cfg UserConfig, overrides SettingOverrides) (Settings, erro
 	if cfg.Services != nil {
 		settings.Services = append([]ServiceConfig(nil), cfg.Services...)
 	}
+	if cfg.Budget != nil {
+		settings.Budget = *cfg.Budget
+	}
+	if cfg.Approval != nil {
+		settings.Approval = *cfg.Approval
+	}
 
 	if overrides.BasePrompt != nil {
 		settings.BasePrompt = *overrides.BasePrompt
@@ -151,6 +161,12 @@ func ResolveSettings(cfg UserConfig, overrides SettingOverrides) (Settings, erro
 	if settings.IdleWaitSeconds < 0 {
 		return Settings{}, fmt.Errorf("idle_wait_seconds must be >= 0")
 	}
+	if err := settings.Budget.validate(); err != nil {
+		return Settings{}, err
+	}
+	if err := settings.Approval.validate(); err != nil {
+		return Settings{}, err
+	}
 	for _, service := range settings.Services {
 		if strings.TrimSpace(service.Name) == "" {
 			return Settings{}, fmt.Errorf("services.name must be non-empty")
@@ -199,6 +215,12 @@ func mergeUserConfig(base UserConfig, override UserConfig) UserConfig {
 	if override.Services != nil {
 		merged.Services = append([]ServiceConfig(nil), override.Services...)
 	}
+	if override.Budget != nil {
+		merged.Budget = override.Budget
+	}
+	if override.Approval != nil {
+		merged.Approval = override.Approval
+	}
 	return merged
 }
 
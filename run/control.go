@@ -28,6 +28,12 @@ func ParseControlSubmission(text string) ControlEvent {
 				Text: strings.TrimSpace(strings.TrimPrefix(text, "/provider")),
 			}
 		}
+		if id, ok := controlCommandArg(text, "/approve"); ok {
+			return ControlEvent{Type: ControlApprove, Text: id}
+		}
+		if id, ok := controlCommandArg(text, "/deny"); ok {
+			return ControlEvent{Type: ControlDeny, Text: id}
+		}
 		if strings.HasPrefix(text, "/") {
 			return ControlEvent{Type: ControlUnknownCommand, Text: text}
 		}
@@ -35,6 +41,17 @@ func ParseControlSubmission(text string) ControlEvent {
 	}
 }
 
+// controlCommandArg matches "/name" or "/name ARG" and returns ARG.
+func controlCommandArg(text, name string) (string, bool) {
+	if text == name {
+		return "", true
+	}
+	if rest, ok := strings.CutPrefix(text, name+" "); ok {
+		return strings.TrimSpace(rest), true
+	}
+	return "", false
+}
+
 func FormatInputLine(promptLabel string, value string) string {
 	if strings.TrimSpace(promptLabel) == "" {
 		promptLabel = DefaultInputPromptLabel
@@ -0,0 +1,283 @@
+package run
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const controlSocketFileName = "run.sock"
+
+// ControlSocketPath is the per-workspace control socket of a running aw run.
+func ControlSocketPath(workingDir string) string {
+	return filepath.Join(workingDir, ".aw", controlSocketFileName)
+}
+
+// LoopSnapshot is the loop state reported over the control socket.
+type LoopSnapshot struct {
+	Run           int       `json:"run"`
+	Phase         RunPhase  `json:"phase"`
+	SessionID     string    `json:"session_id,omitempty"`
+	Paused        bool      `json:"paused"`
+	PauseAfterRun bool      `json:"pause_after_run"`
+	LeaseLost     bool      `json:"lease_lost,omitempty"`
+	Autofeed      bool      `json:"autofeed"`
+	QueuedPrompt  string    `json:"queued_prompt,omitempty"`
+	WakeSource    string    `json:"wake_source,omitempty"`
+	ClaimedTask   string    `json:"claimed_task,omitempty"`
+	CostUSD       float64   `json:"cost_usd"`
+	LastRunError  string    `json:"last_run_error,omitempty"`
+	Connection    string    `json:"connection"`
+	PendingEvents int       `json:"pending_events"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	PendingApprovals []string `json:"pending_approvals,omitempty"`
+}
+
+// QueueStatus is the short answer to a "status" request.
+type QueueStatus struct {
+	Phase         RunPhase `json:"phase"`
+	Paused        bool     `json:"paused"`
+	QueuedPrompt  string   `json:"queued_prompt,omitempty"`
+	PendingEvents int      `json:"pending_events"`
+}
+
+// ControlSocketResponse is written back for every request line.
+type ControlSocketResponse struct {
+	OK     bool          `json:"ok"`
+	Error  string        `json:"error,omitempty"`
+	Status *QueueStatus  `json:"status,omitempty"`
+	State  *LoopSnapshot `json:"state,omitempty"`
+
+	Approval *ApprovalDecision `json:"approval,omitempty"`
+}
+
+// ControlSocket accepts JSON control lines from local clients on a Unix
+// socket. It takes the headless command set plus "inject" (queue a prompt
+// without switching autofeed off), "status", "state" and
+// "approval_request", which blocks until the operator approves or denies.
+type ControlSocket struct {
+	path   string
+	events chan ControlEvent
+	done   chan struct{}
+
+	mu          sync.Mutex
+	state       func() LoopSnapshot
+	listener    net.Listener
+	conns       map[net.Conn]struct{}
+	approvals   map[string]*pendingApproval
+	approvalSeq int
+	wg          sync.WaitGroup
+}
+
+func NewControlSocket(path string) *ControlSocket {
+	return &ControlSocket{
+		path:      path,
+		events:    make(chan ControlEvent, 64),
+		done:      make(chan struct{}),
+		conns:     map[net.Conn]struct{}{},
+		approvals: map[string]*pendingApproval{},
+	}
+}
+
+func (s *ControlSocket) Path() string {
+	return s.path
+}
+
+func (s *ControlSocket) Events() <-chan ControlEvent {
+	return s.events
+}
+
+// Start listens on the socket path. state is called to answer status and
+// state requests and must be safe to call from any goroutine.
+func (s *ControlSocket) Start(state func() LoopSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener != nil {
+		return nil
+	}
+	listener, err := listenUnixSocket(s.path)
+	if err != nil {
+		return err
+	}
+	s.state = state
+	s.listener = listener
+	s.wg.Add(1)
+	go s.acceptLoop(listener)
+	return nil
+}
+
+func (s *ControlSocket) Stop() error {
+	s.mu.Lock()
+	listener := s.listener
+	s.listener = nil
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+	if listener == nil {
+		return nil
+	}
+	close(s.done)
+	_ = listener.Close()
+	_ = os.Remove(s.path)
+	s.wg.Wait()
+	return nil
+}
+
+func (s *ControlSocket) acceptLoop(listener net.Listener) {
+	defer s.wg.Done()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.listener == nil {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serve(conn)
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+			_ = conn.Close()
+		}()
+	}
+}
+
+func (s *ControlSocket) serve(conn net.Conn) {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	encoder := json.NewEncoder(conn)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := encoder.Encode(s.handle(line)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *ControlSocket) handle(line string) ControlSocketResponse {
+	var cmd HeadlessCommand
+	if err := json.Unmarshal([]byte(line), &cmd); err != nil {
+		return ControlSocketResponse{Error: fmt.Sprintf("invalid control line: %v", err)}
+	}
+	switch strings.ToLower(strings.TrimSpace(cmd.Type)) {
+	case "status":
+		snapshot := s.state()
+		return ControlSocketResponse{OK: true, Status: &QueueStatus{
+			Phase:         snapshot.Phase,
+			Paused:        snapshot.Paused,
+			QueuedPrompt:  snapshot.QueuedPrompt,
+			PendingEvents: snapshot.PendingEvents,
+		}}
+	case "state":
+		snapshot := s.state()
+		snapshot.PendingApprovals = s.PendingApprovals()
+		return ControlSocketResponse{OK: true, State: &snapshot}
+	case "inject":
+		if strings.TrimSpace(cmd.Text) == "" {
+			return ControlSocketResponse{Error: "inject text is required"}
+		}
+		return s.deliver(ControlEvent{Type: ControlInjectPrompt, Text: cmd.Text})
+	case "approval_request":
+		if cmd.Approval == nil {
+			return ControlSocketResponse{Error: "approval is required"}
+		}
+		return s.requestApproval(*cmd.Approval)
+	case "approve", "deny":
+		// Only the owner's signature approves; the gated command checks it.
+		approved := strings.EqualFold(strings.TrimSpace(cmd.Type), "approve")
+		id, signature := ParseApprovalArgs(cmd.Text)
+		if _, err := s.ResolveApproval(id, approved, "local", signature); err != nil {
+			return ControlSocketResponse{Error: err.Error()}
+		}
+		return ControlSocketResponse{OK: true}
+	}
+	event, err := ParseHeadlessCommand(line)
+	if err != nil {
+		return ControlSocketResponse{Error: err.Error()}
+	}
+	return s.deliver(event)
+}
+
+func (s *ControlSocket) deliver(event ControlEvent) ControlSocketResponse {
+	select {
+	case s.events <- event:
+		return ControlSocketResponse{OK: true}
+	default:
+		return ControlSocketResponse{Error: "control queue is full"}
+	}
+}
+
+// listenUnixSocket listens on path for the control socket.
+func listenUnixSocket(path string) (net.Listener, error) {
+	return ListenUnixSocket(path, "control socket")
+}
+
+// ListenUnixSocket listens on path with owner-only permissions. A leftover
+// socket from a crashed process is replaced; a live one is an error, and so
+// is anything at path that is not a socket. name describes the socket in
+// errors.
+func ListenUnixSocket(path, name string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s %s exists and is not a socket", name, path)
+		}
+		if conn, err := net.DialTimeout("unix", path, 200*time.Millisecond); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("%s %s is already in use", name, path)
+		}
+		_ = os.Remove(path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", name, err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// SendControlSocket sends one request line to the control socket at path
+// and returns the response.
+func SendControlSocket(path string, cmd HeadlessCommand, timeout time.Duration) (ControlSocketResponse, error) {
+	conn, err := net.DialTimeout("unix", path, timeout)
+	if err != nil {
+		return ControlSocketResponse{}, fmt.Errorf("no aw run is listening on %s: %w", path, err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	if err := json.NewEncoder(conn).Encode(cmd); err != nil {
+		return ControlSocketResponse{}, err
+	}
+	var resp ControlSocketResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return ControlSocketResponse{}, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,191 @@
+package run
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func shortSocketDir(t *testing.T) string {
+	t.Helper()
+	// t.TempDir paths can exceed the Unix socket path limit.
+	dir, err := os.MkdirTemp("", "aws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return dir
+}
+
+func TestControlSocketInjectsPromptAndReportsState(t *testing.T) {
+	dir := shortSocketDir(t)
+	socketPath := ControlSocketPath(dir)
+
+	loop := NewLoop(fakeProvider{event: &Event{Type: EventText, Text: "done\n", Session: "s-9"}}, io.Discard)
+	loop.ControlSocket = NewControlSocket(socketPath)
+	var prompts []string
+	loop.OnUserPrompt = func(text string) { prompts = append(prompts, text) }
+	loop.Runner = func(ctx context.Context, dir string, argv []string, onLine func(string), stderrSink any) error {
+		onLine("{}")
+		return nil
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- loop.Run(context.Background(), LoopOptions{MaxRuns: 1, WaitSeconds: 5, Autofeed: true})
+	}()
+
+	var status ControlSocketResponse
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp, err := SendControlSocket(socketPath, HeadlessCommand{Type: "status"}, time.Second)
+		if err == nil {
+			status = resp
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("control socket never came up: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !status.OK || status.Status == nil {
+		t.Fatalf("status=%#v", status)
+	}
+
+	resp, err := SendControlSocket(socketPath, HeadlessCommand{Type: "state"}, time.Second)
+	if err != nil || !resp.OK || resp.State == nil || !resp.State.Autofeed || resp.State.Connection != ConnDisconnected.String() {
+		t.Fatalf("state=%#v err=%v", resp, err)
+	}
+	if resp, err := SendControlSocket(socketPath, HeadlessCommand{Type: "inject"}, time.Second); err != nil || resp.OK || !strings.Contains(resp.Error, "required") {
+		t.Fatalf("empty inject=%#v err=%v", resp, err)
+	}
+	if resp, err := SendControlSocket(socketPath, HeadlessCommand{Type: "inject", Text: "check the build"}, time.Second); err != nil || !resp.OK {
+		t.Fatalf("inject=%#v err=%v", resp, err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the injected run")
+	}
+	if len(prompts) != 1 || prompts[0] != "check the build" {
+		t.Fatalf("prompts=%#v", prompts)
+	}
+	snapshot := loop.Snapshot()
+	if !snapshot.Autofeed || snapshot.SessionID != "s-9" || snapshot.Run != 1 {
+		t.Fatalf("snapshot after inject=%#v", snapshot)
+	}
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Fatalf("expected socket removed after Run, stat err=%v", err)
+	}
+}
+
+func TestControlSocketRefusesLiveSocketAndReplacesStaleOne(t *testing.T) {
+	dir := shortSocketDir(t)
+	socketPath := filepath.Join(dir, "run.sock")
+
+	first := NewControlSocket(socketPath)
+	if err := first.Start(func() LoopSnapshot { return LoopSnapshot{} }); err != nil {
+		t.Fatal(err)
+	}
+	second := NewControlSocket(socketPath)
+	if err := second.Start(func() LoopSnapshot { return LoopSnapshot{} }); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Fatalf("expected in-use error, got %v", err)
+	}
+	resp, err := SendControlSocket(socketPath, HeadlessCommand{Type: "pause"}, time.Second)
+	if err != nil || !resp.OK {
+		t.Fatalf("pause=%#v err=%v", resp, err)
+	}
+	if event := <-first.Events(); event.Type != ControlWait {
+		t.Fatalf("event=%#v", event)
+	}
+	if resp, err := SendControlSocket(socketPath, HeadlessCommand{Type: "dance"}, time.Second); err != nil || resp.OK {
+		t.Fatalf("unknown command=%#v err=%v", resp, err)
+	}
+	if err := first.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A socket file left behind by a crash has no listener and is replaced.
+	stale, err := listenUnixSocket(socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unix, ok := stale.(interface{ SetUnlinkOnClose(bool) }); ok {
+		unix.SetUnlinkOnClose(false)
+	}
+	_ = stale.Close()
+	if err := second.Start(func() LoopSnapshot { return LoopSnapshot{} }); err != nil {
+		t.Fatalf("expected stale socket to be replaced: %v", err)
+	}
+	_ = second.Stop()
+}
+
+func TestControlSocketApprovalRequestWaitsForDecision(t *testing.T) {
+	socketPath := ControlSocketPath(shortSocketDir(t))
+	socket := NewControlSocket(socketPath)
+	if err := socket.Start(func() LoopSnapshot { return LoopSnapshot{} }); err != nil {
+		t.Fatal(err)
+	}
+	defer socket.Stop()
+
+	decided := make(chan ControlSocketResponse, 2)
+	ask := func(action string) {
+		resp, err := SendControlSocket(socketPath, HeadlessCommand{
+			Type:     "approval_request",
+			Approval: &ApprovalRequest{Action: action, Detail: "t-1", Code: "req-code"},
+		}, 5*time.Second)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		decided <- resp
+	}
+	go ask("task.close")
+	select {
+	case event := <-socket.Events():
+		if event.Type != ControlApprovalRequested || !strings.Contains(event.Text, "task.close t-1") || !strings.Contains(event.Text, "aw id team approve req-code") || !strings.Contains(event.Text, "/approve 1") {
+			t.Fatalf("event=%#v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("approval request was not announced")
+	}
+
+	resp, err := SendControlSocket(socketPath, HeadlessCommand{Type: "state"}, time.Second)
+	if err != nil || resp.State == nil || len(resp.State.PendingApprovals) != 1 || resp.State.PendingApprovals[0] != "1: task.close" {
+		t.Fatalf("state=%#v err=%v", resp, err)
+	}
+	if resp, err := SendControlSocket(socketPath, HeadlessCommand{Type: "approve", Text: "9"}, time.Second); err != nil || resp.OK {
+		t.Fatalf("approve unknown=%#v err=%v", resp, err)
+	}
+	// A socket client (the provider included) cannot approve without the
+	// team owner's signature, and the request stays pending.
+	if resp, err := SendControlSocket(socketPath, HeadlessCommand{Type: "approve"}, time.Second); err != nil || resp.OK || !strings.Contains(resp.Error, "signature") {
+		t.Fatalf("unsigned approve=%#v err=%v", resp, err)
+	}
+	if pending := socket.PendingApprovals(); len(pending) != 1 {
+		t.Fatalf("pending after unsigned approve=%v", pending)
+	}
+	if resp, err := SendControlSocket(socketPath, HeadlessCommand{Type: "approve", Text: "1 owner-sig"}, time.Second); err != nil || !resp.OK {
+		t.Fatalf("approve=%#v err=%v", resp, err)
+	}
+	got := <-decided
+	if !got.OK || got.Approval == nil || !got.Approval.Approved || got.Approval.ID != "1" || got.Approval.Signature != "owner-sig" {
+		t.Fatalf("decision=%#v", got)
+	}
+
+	go ask("id.team.remove-member")
+	<-socket.Events()
+	if action, err := socket.ResolveApproval("", false, "local", ""); err != nil || action != "id.team.remove-member" {
+		t.Fatalf("deny action=%q err=%v", action, err)
+	}
+	if got := <-decided; got.Approval == nil || got.Approval.Approved {
+		t.Fatalf("decision=%#v", got)
+	}
+}
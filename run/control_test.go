@@ -138,3 +138,27 @@ func TestProviderInputEventWritesToActiveProvider(t *testing.T) {
 		t.Fatalf("expected confirmation output, got %q", got)
 	}
 }
+
+func TestParseControlSubmissionApprovals(t *testing.T) {
+	for input, want := range map[string]ControlEvent{
+		"/approve":     {Type: ControlApprove},
+		"/approve 3":   {Type: ControlApprove, Text: "3"},
+		"/approve 3 s": {Type: ControlApprove, Text: "3 s"},
+		"/deny 2":      {Type: ControlDeny, Text: "2"},
+		"/approvefoo":  {Type: ControlUnknownCommand, Text: "/approvefoo"},
+	} {
+		if got := ParseControlSubmission(input); got != want {
+			t.Fatalf("%q: got %#v", input, got)
+		}
+	}
+	for text, want := range map[string][2]string{
+		"":      {"", ""},
+		"3":     {"3", ""},
+		"sig":   {"", "sig"},
+		"3 sig": {"3", "sig"},
+	} {
+		if id, signature := ParseApprovalArgs(text); id != want[0] || signature != want[1] {
+			t.Fatalf("%q: id=%q signature=%q", text, id, signature)
+		}
+	}
+}
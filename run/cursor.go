@@ -0,0 +1,50 @@
+package run
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+// CursorStore persists the id of the last event the bus received, so a
+// later connection can resume the stream after it.
+type CursorStore interface {
+	LoadCursor() (string, error)
+	SaveCursor(cursor string) error
+}
+
+// FileCursorStore keeps the cursor in a small JSON file, usually under the
+// identity home so each identity resumes its own stream.
+type FileCursorStore struct {
+	Path string
+}
+
+type cursorFile struct {
+	Cursor    string `json:"cursor"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func (s FileCursorStore) LoadCursor() (string, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var f cursorFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(f.Cursor), nil
+}
+
+func (s FileCursorStore) SaveCursor(cursor string) error {
+	data, err := json.Marshal(cursorFile{Cursor: cursor, UpdatedAt: time.Now().UTC().Format(time.RFC3339)})
+	if err != nil {
+		return err
+	}
+	return atomicWriteFileMode(s.Path, append(data, '\n'), 0o600)
+}
@@ -2,6 +2,8 @@ package run
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -46,6 +48,9 @@ const (
 type BusEvent struct {
 	Event    awid.AgentEvent
 	Priority EventPriority
+	// Team is the team whose stream delivered the event, when the bus was
+	// given one.
+	Team string
 }
 
 // classifyAgentEvent assigns a priority to an agent event.
@@ -147,14 +152,16 @@ func (q *PriorityQueue) Ready() <-chan struct{} {
 // through an interrupt channel (for high-priority) and a priority queue
 // (for deferred events).
 type EventBus struct {
-	stream EventStreamOpener
-	now    func() time.Time
+	sources []*busSource
+	now     func() time.Time
 
 	interrupts chan BusEvent
 	queue      *PriorityQueue
+	dedupeMu   sync.Mutex
 	deduper    *recentEventDeduper
 	streamTTL  time.Duration
 
+	stateMu            sync.Mutex
 	connState          atomic.Int32
 	onStateChange      func(ConnectionState)
 	onConnectionNotice func(string)
@@ -166,19 +173,52 @@ type EventBus struct {
 
 // EventBusConfig holds construction parameters for an EventBus.
 type EventBusConfig struct {
-	Stream        EventStreamOpener
+	Stream EventStreamOpener
+	// Resume, when set, is used instead of Stream and opens the stream
+	// after the last event id the bus has seen.
+	Resume ResumableEventStreamOpener
+	// Cursor persists the last seen event id across connections and runs.
+	Cursor CursorStore
+	// CatchUp lists the actionable events outstanding after a gap the
+	// server could not replay. Without it, a reconnect queues a single
+	// AgentEventChannelReconnected instead.
+	CatchUp func(ctx context.Context) ([]awid.AgentEvent, error)
+	// Teams, when set, replaces the single stream above with one stream
+	// per team membership, all feeding the same queue.
+	Teams         []TeamEventSource
 	Now           func() time.Time
 	OnStateChange func(ConnectionState)
 	StreamTTL     time.Duration
 }
 
+// TeamEventSource is one team's event stream. Events it delivers carry
+// the team in BusEvent.Team and, when the server left it out, in the
+// event's TeamID.
+type TeamEventSource struct {
+	Team    string
+	Stream  EventStreamOpener
+	Resume  ResumableEventStreamOpener
+	Cursor  CursorStore
+	CatchUp func(ctx context.Context) ([]awid.AgentEvent, error)
+}
+
+// busSource is the connection state the bus keeps for one stream.
+type busSource struct {
+	TeamEventSource
+	cursor string
+	state  ConnectionState
+}
+
 func NewEventBus(cfg EventBusConfig) *EventBus {
 	nowFn := cfg.Now
 	if nowFn == nil {
 		nowFn = time.Now
 	}
+	sources := cfg.Teams
+	if len(sources) == 0 {
+		sources = []TeamEventSource{{Stream: cfg.Stream, Resume: cfg.Resume, Cursor: cfg.Cursor, CatchUp: cfg.CatchUp}}
+	}
 	b := &EventBus{
-		stream:        cfg.Stream,
 		now:           nowFn,
 		interrupts:    make(chan BusEvent, 8),
 		queue:         NewPriorityQueue(),
@@ -190,6 +230,9 @@ func NewEventBus(cfg EventBusConfig) *EventBus {
 	if b.streamTTL <= 0 {
 		b.streamTTL = streamDeadline
 	}
+	for _, source := range sources {
+		b.sources = append(b.sources, &busSource{TeamEventSource: source})
+	}
 	b.connState.Store(int32(ConnDisconnected))
 	return b
 }
@@ -225,10 +268,25 @@ func (b *EventBus) State() ConnectionState {
 	return ConnectionState(b.connState.Load())
 }
 
-func (b *EventBus) setState(s ConnectionState) {
-	old := ConnectionState(b.connState.Swap(int32(s)))
-	if old != s && b.onStateChange != nil {
-		b.onStateChange(s)
+// setState records one stream's state and reports the bus as a whole:
+// reconnecting while any stream is, else streaming while any stream is.
+func (b *EventBus) setState(src *busSource, s ConnectionState) {
+	b.stateMu.Lock()
+	src.state = s
+	overall := ConnDisconnected
+	for _, source := range b.sources {
+		if source.state == ConnReconnecting {
+			overall = ConnReconnecting
+			break
+		}
+		if source.state == ConnStreaming {
+			overall = ConnStreaming
+		}
+	}
+	old := ConnectionState(b.connState.Swap(int32(overall)))
+	b.stateMu.Unlock()
+	if old != overall && b.onStateChange != nil {
+		b.onStateChange(overall)
 	}
 }
 
@@ -244,30 +302,55 @@ const streamDeadline = 10 * time.Minute
 
 func (b *EventBus) run(ctx context.Context) {
 	defer close(b.done)
-	defer b.setState(ConnDisconnected)
+	var wg sync.WaitGroup
+	for _, src := range b.sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.runSource(ctx, src)
+		}()
+	}
+	wg.Wait()
+}
+
+// runSource keeps one stream connected until ctx ends or the server
+// rejects it.
+func (b *EventBus) runSource(ctx context.Context, src *busSource) {
+	defer b.setState(src, ConnDisconnected)
 
 	delay := 250 * time.Millisecond
 	maxDelay := 2 * time.Second
 	recoveryPending := false
 	disconnectReported := false
+	b.loadCursor(src)
+	// A cursor saved by an earlier run means events may have arrived while
+	// nothing was listening.
+	gapPending := src.cursor != ""
 
 	for ctx.Err() == nil {
-		b.setState(ConnReconnecting)
+		b.setState(src, ConnReconnecting)
 
 		deadline := b.now().Add(b.streamTTL)
 		streamCtx, cancel := context.WithDeadline(ctx, deadline)
-		source, err := b.stream(streamCtx, deadline)
+		source, err := src.open(streamCtx, deadline)
 		if err != nil {
 			cancel()
 			if code, ok := awid.HTTPStatusCode(err); ok && code >= 400 && code < 500 {
-				b.connectionNotice("aweb: event stream disconnected (authentication or request rejected); not retrying")
-				b.setState(ConnDisconnected)
+				if src.cursor != "" && cursorRejected(err) {
+					// The server no longer knows the cursor; reconnect
+					// without it and rebuild the gap locally.
+					b.setCursor(src, "")
+					gapPending = true
+					continue
+				}
+				b.connectionNotice(src, "aweb: event stream disconnected (authentication or request rejected); not retrying")
+				b.setState(src, ConnDisconnected)
 				return
 			}
 			recoveryPending = true
 			if !disconnectReported {
 				disconnectReported = true
-				b.connectionNotice(formatStreamDisconnectNotice(err, delay))
+				b.connectionNotice(src, formatStreamDisconnectNotice(err, delay))
 			}
 			if !sleepForRetry(ctx, b.now, deadline, delay) {
 				return
@@ -281,31 +364,42 @@ func (b *EventBus) run(ctx context.Context) {
 		// only after the stream produces an event. This prevents early-EOF flaps
 		// from oscillating recovery/down and queueing false catch-up wakes.
 		streamConfirmed := false
-		confirmStream := func() {
+		confirmStream := func(first awid.AgentEvent) {
 			if streamConfirmed {
 				return
 			}
 			streamConfirmed = true
 			delay = 250 * time.Millisecond
-			b.setState(ConnStreaming)
-			if recoveryPending {
+			b.setState(src, ConnStreaming)
+			resumed := first.Type == awid.AgentEventConnected && first.Resumed
+			switch {
+			case recoveryPending:
 				recoveryPending = false
 				disconnectReported = false
-				b.connectionNotice("aweb: event stream reconnected; catching up")
-				b.queue.Push(BusEvent{Priority: PriorityCommunication, Event: awid.AgentEvent{Type: awid.AgentEventChannelReconnected}})
+				gapPending = false
+				if resumed {
+					b.connectionNotice(src, "aweb: event stream resumed; replaying missed events")
+				} else {
+					b.catchUpGap(streamCtx, src, true)
+				}
+			case gapPending:
+				gapPending = false
+				if !resumed {
+					b.catchUpGap(streamCtx, src, false)
+				}
 			}
 		}
 
-		consumeErr := b.consumeStream(streamCtx, source, confirmStream)
+		consumeErr := b.consumeStream(streamCtx, src, source, confirmStream)
 		streamContextErr := streamCtx.Err()
 		_ = source.Close()
 		cancel()
 		if consumeErr != nil && ctx.Err() == nil && streamContextErr == nil {
-			b.setState(ConnReconnecting)
+			b.setState(src, ConnReconnecting)
 			recoveryPending = true
 			if !disconnectReported {
 				disconnectReported = true
-				b.connectionNotice(formatStreamDisconnectNotice(consumeErr, delay))
+				b.connectionNotice(src, formatStreamDisconnectNotice(consumeErr, delay))
 			}
 			if !sleepForRetry(ctx, b.now, time.Time{}, delay) {
 				return
@@ -315,10 +409,97 @@ func (b *EventBus) run(ctx context.Context) {
 	}
 }
 
-func (b *EventBus) connectionNotice(message string) {
-	if b.onConnectionNotice != nil {
-		b.onConnectionNotice(message)
+func (src *busSource) open(ctx context.Context, deadline time.Time) (awid.EventSource, error) {
+	if src.Resume != nil {
+		return src.Resume(ctx, deadline, src.cursor)
+	}
+	return src.Stream(ctx, deadline)
+}
+
+// cursorRejected reports whether an error answering a resume request means
+// the cursor itself is unusable rather than the request being malformed or
+// unauthorized. 410, 412 and 416 only answer a stale cursor; a 400 or 409
+// counts only when its body names the cursor, so an unrelated bad request
+// still stops the stream instead of silently dropping the cursor.
+func cursorRejected(err error) bool {
+	code, ok := awid.HTTPStatusCode(err)
+	if !ok {
+		return false
+	}
+	switch code {
+	case http.StatusGone, http.StatusPreconditionFailed, http.StatusRequestedRangeNotSatisfiable:
+		return true
+	case http.StatusBadRequest, http.StatusConflict:
+		body, _ := awid.HTTPErrorBody(err)
+		body = strings.ToLower(body)
+		return strings.Contains(body, "last-event-id") || strings.Contains(body, "cursor")
+	default:
+		return false
+	}
+}
+
+func (b *EventBus) loadCursor(src *busSource) {
+	if src.Cursor == nil {
+		return
+	}
+	cursor, err := src.Cursor.LoadCursor()
+	if err != nil {
+		b.connectionNotice(src, "aweb: ignoring unreadable event stream cursor: "+err.Error())
+		return
+	}
+	src.cursor = cursor
+}
+
+func (b *EventBus) setCursor(src *busSource, cursor string) {
+	if cursor == src.cursor {
+		return
+	}
+	src.cursor = cursor
+	if src.Cursor == nil {
+		return
+	}
+	if err := src.Cursor.SaveCursor(cursor); err != nil {
+		b.connectionNotice(src, "aweb: could not save event stream cursor: "+err.Error())
+	}
+}
+
+// catchUpGap queues the events rebuilt by CatchUp after a gap the server
+// did not replay, oldest first. When CatchUp is unset or fails, a reconnect
+// falls back to a single AgentEventChannelReconnected.
+func (b *EventBus) catchUpGap(ctx context.Context, src *busSource, reconnected bool) {
+	if src.CatchUp != nil {
+		events, err := src.CatchUp(ctx)
+		if err == nil {
+			if reconnected {
+				b.connectionNotice(src, fmt.Sprintf("aweb: event stream reconnected; caught up on %d outstanding event(s)", len(events)))
+			} else if len(events) > 0 {
+				b.connectionNotice(src, fmt.Sprintf("aweb: caught up on %d event(s) outstanding since the last run", len(events)))
+			}
+			for _, ev := range events {
+				if err := b.deliver(ctx, src, ev); err != nil {
+					return
+				}
+			}
+			return
+		}
+		b.connectionNotice(src, "aweb: catching up after event stream gap failed: "+err.Error())
+	}
+	if reconnected {
+		b.connectionNotice(src, "aweb: event stream reconnected; catching up")
+		b.queue.Push(BusEvent{Priority: PriorityCommunication, Team: src.Team, Event: awid.AgentEvent{Type: awid.AgentEventChannelReconnected, TeamID: src.Team}})
+	}
+}
+
+// connectionNotice reports a stream's connection news, naming the team
+// when the bus listens to more than one.
+func (b *EventBus) connectionNotice(src *busSource, message string) {
+	if b.onConnectionNotice == nil {
+		return
+	}
+	if len(b.sources) > 1 && src != nil && src.Team != "" {
+		message = strings.Replace(message, "aweb:", "aweb ["+src.Team+"]:", 1)
 	}
+	b.onConnectionNotice(message)
 }
 
 func formatStreamDisconnectNotice(err error, delay time.Duration) string {
@@ -339,46 +520,59 @@ func formatStreamDisconnectNotice(err error, delay time.Duration) string {
 	return "aweb: event stream disconnected (" + cause + ") — retrying in " + delay.String()
 }
 
-func (b *EventBus) consumeStream(ctx context.Context, source awid.EventSource, confirmStream func()) error {
+func (b *EventBus) consumeStream(ctx context.Context, src *busSource, source awid.EventSource, confirmStream func(awid.AgentEvent)) error {
 	for ctx.Err() == nil {
 		ev, err := source.Next(ctx)
 		if err != nil {
 			return err
 		}
 		if confirmStream != nil {
-			confirmStream()
+			confirmStream(*ev)
 		}
 
-		if ev.Type == awid.AgentEventConnected {
-			continue
-		}
 		if ev.Type == awid.AgentEventError {
 			if b.onError != nil {
 				b.onError(*ev)
 			}
-			continue
-		}
-
-		priority, shouldQueue := classifyAgentEvent(*ev)
-		if !shouldQueue {
-			continue
+		} else if ev.Type != awid.AgentEventConnected {
+			if err := b.deliver(ctx, src, *ev); err != nil {
+				return err
+			}
 		}
-		if b.deduper != nil && b.deduper.Seen(*ev) {
-			continue
+		if ev.Cursor != "" {
+			b.setCursor(src, ev.Cursor)
 		}
+	}
+	return ctx.Err()
+}
 
-		busEvt := BusEvent{Event: *ev, Priority: priority}
-		if priority == PriorityInterrupt {
-			select {
-			case b.interrupts <- busEvt:
-			case <-ctx.Done():
-				return ctx.Err()
-			}
-		} else {
-			b.queue.Push(busEvt)
+// deliver routes an event to the interrupt channel or the queue, dropping
+// informational and already-seen events.
+func (b *EventBus) deliver(ctx context.Context, src *busSource, ev awid.AgentEvent) error {
+	priority, shouldQueue := classifyAgentEvent(ev)
+	if !shouldQueue {
+		return nil
+	}
+	if src.Team != "" && ev.TeamID == "" {
+		ev.TeamID = src.Team
+	}
+	b.dedupeMu.Lock()
+	seen := b.deduper != nil && b.deduper.Seen(ev)
+	b.dedupeMu.Unlock()
+	if seen {
+		return nil
+	}
+	busEvt := BusEvent{Event: ev, Priority: priority, Team: src.Team}
+	if priority == PriorityInterrupt {
+		select {
+		case b.interrupts <- busEvt:
+		case <-ctx.Done():
+			return ctx.Err()
 		}
+	} else {
+		b.queue.Push(busEvt)
 	}
-	return ctx.Err()
+	return nil
 }
 
 type recentEventDeduper struct {
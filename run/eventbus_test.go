@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net/http"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -846,3 +847,290 @@ func TestEventBusCallsOnErrorForErrorEvents(t *testing.T) {
 	cancel()
 	bus.Stop()
 }
+
+type memoryCursorStore struct {
+	mu     sync.Mutex
+	cursor string
+}
+
+func (s *memoryCursorStore) LoadCursor() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursor, nil
+}
+
+func (s *memoryCursorStore) SaveCursor(cursor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursor = cursor
+	return nil
+}
+
+func TestEventBusResumesFromSavedCursor(t *testing.T) {
+	store := &memoryCursorStore{cursor: "c-5"}
+	var mu sync.Mutex
+	var cursors []string
+	var catchUps atomic.Int32
+	bus := NewEventBus(EventBusConfig{
+		Cursor: store,
+		Resume: func(ctx context.Context, deadline time.Time, lastEventID string) (awid.EventSource, error) {
+			mu.Lock()
+			cursors = append(cursors, lastEventID)
+			calls := len(cursors)
+			mu.Unlock()
+			if calls == 1 {
+				return newFakeEventSource(
+					awid.AgentEvent{Type: awid.AgentEventConnected, Resumed: true, Cursor: "c-5"},
+					awid.AgentEvent{Type: awid.AgentEventActionableMail, MessageID: "m-6", Cursor: "c-6"},
+				), nil
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		CatchUp: func(context.Context) ([]awid.AgentEvent, error) {
+			catchUps.Add(1)
+			return nil, nil
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus.Start(ctx)
+
+	select {
+	case <-bus.Queue().Ready():
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for queue ready")
+	}
+	time.Sleep(400 * time.Millisecond)
+	cancel()
+	bus.Stop()
+
+	queued := bus.Queue().Drain()
+	if len(queued) != 1 || queued[0].Event.MessageID != "m-6" {
+		t.Fatalf("queued=%v", queued)
+	}
+	if catchUps.Load() != 0 {
+		t.Fatal("catch-up ran although the server resumed the stream")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(cursors) < 2 || cursors[0] != "c-5" || cursors[1] != "c-6" {
+		t.Fatalf("resume cursors=%v", cursors)
+	}
+	if cursor, _ := store.LoadCursor(); cursor != "c-6" {
+		t.Fatalf("saved cursor=%q", cursor)
+	}
+}
+
+func TestEventBusCatchesUpWhenCursorIsRejected(t *testing.T) {
+	store := &memoryCursorStore{cursor: "stale"}
+	var mu sync.Mutex
+	var cursors []string
+	bus := NewEventBus(EventBusConfig{
+		Cursor: store,
+		Resume: func(ctx context.Context, deadline time.Time, lastEventID string) (awid.EventSource, error) {
+			mu.Lock()
+			cursors = append(cursors, lastEventID)
+			calls := len(cursors)
+			mu.Unlock()
+			switch {
+			case lastEventID == "stale":
+				return nil, &awid.APIError{StatusCode: 410, Body: "cursor expired"}
+			case calls == 2:
+				return newFakeEventSource(
+					awid.AgentEvent{Type: awid.AgentEventConnected, Cursor: "c-9"},
+					awid.AgentEvent{Type: awid.AgentEventActionableMail, MessageID: "m-1", Cursor: "c-10"},
+				), nil
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		CatchUp: func(context.Context) ([]awid.AgentEvent, error) {
+			return []awid.AgentEvent{
+				{Type: awid.AgentEventActionableMail, MessageID: "m-1"},
+				{Type: awid.AgentEventActionableChat, SessionID: "s-1"},
+				{Type: awid.AgentEventWorkAvailable, TaskID: "t-1"},
+			}, nil
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus.Start(ctx)
+
+	select {
+	case <-bus.Queue().Ready():
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for queue ready")
+	}
+	time.Sleep(400 * time.Millisecond)
+	cancel()
+	bus.Stop()
+
+	var got []string
+	for _, evt := range bus.Queue().Drain() {
+		got = append(got, string(evt.Event.Type)+":"+evt.Event.MessageID+evt.Event.SessionID+evt.Event.TaskID)
+	}
+	want := []string{"actionable_mail:m-1", "actionable_chat:s-1", "work_available:t-1"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("queued=%v, want %v", got, want)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(cursors) < 2 || cursors[1] != "" {
+		t.Fatalf("resume cursors=%v", cursors)
+	}
+	if cursor, _ := store.LoadCursor(); cursor != "c-10" {
+		t.Fatalf("saved cursor=%q", cursor)
+	}
+}
+
+func TestEventBusCatchesUpWhenServerDoesNotConfirmResume(t *testing.T) {
+	store := &memoryCursorStore{cursor: "c-5"}
+	var calls atomic.Int32
+	var catchUps atomic.Int32
+	bus := NewEventBus(EventBusConfig{
+		Cursor: store,
+		Resume: func(ctx context.Context, deadline time.Time, lastEventID string) (awid.EventSource, error) {
+			if calls.Add(1) == 1 {
+				return newFakeEventSource(awid.AgentEvent{Type: awid.AgentEventConnected, Cursor: "c-9"}), nil
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		CatchUp: func(context.Context) ([]awid.AgentEvent, error) {
+			catchUps.Add(1)
+			return []awid.AgentEvent{{Type: awid.AgentEventWorkAvailable, TaskID: "t-1"}}, nil
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus.Start(ctx)
+
+	select {
+	case <-bus.Queue().Ready():
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for queue ready")
+	}
+	cancel()
+	bus.Stop()
+
+	if catchUps.Load() != 1 {
+		t.Fatalf("catch-ups=%d, want 1 when the connected event does not report resumed", catchUps.Load())
+	}
+}
+
+func TestCursorRejectedOnlyForCursorErrors(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{&awid.APIError{StatusCode: http.StatusGone, Body: "gone"}, true},
+		{&awid.APIError{StatusCode: http.StatusRequestedRangeNotSatisfiable}, true},
+		{&awid.APIError{StatusCode: http.StatusBadRequest, Body: `{"detail":"unknown Last-Event-ID"}`}, true},
+		{&awid.APIError{StatusCode: http.StatusConflict, Body: "cursor expired"}, true},
+		{&awid.APIError{StatusCode: http.StatusBadRequest, Body: `{"detail":"invalid deadline"}`}, false},
+		{&awid.APIError{StatusCode: http.StatusUnauthorized, Body: "cursor"}, false},
+		{context.Canceled, false},
+	} {
+		if got := cursorRejected(tc.err); got != tc.want {
+			t.Errorf("cursorRejected(%v)=%v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestFileCursorStoreRoundTrip(t *testing.T) {
+	store := FileCursorStore{Path: t.TempDir() + "/event-cursor.json"}
+	if cursor, err := store.LoadCursor(); err != nil || cursor != "" {
+		t.Fatalf("missing file: cursor=%q err=%v", cursor, err)
+	}
+	if err := store.SaveCursor("evt-42"); err != nil {
+		t.Fatal(err)
+	}
+	if cursor, err := store.LoadCursor(); err != nil || cursor != "evt-42" {
+		t.Fatalf("cursor=%q err=%v", cursor, err)
+	}
+}
+
+// stallingEventSource delivers its events, then stays open until ctx ends.
+type stallingEventSource struct{ *fakeEventSource }
+
+func (s stallingEventSource) Next(ctx context.Context) (*awid.AgentEvent, error) {
+	ev, err := s.fakeEventSource.Next(ctx)
+	if errors.Is(err, io.EOF) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return ev, err
+}
+
+func TestEventBusTagsEventsWithTheirTeam(t *testing.T) {
+	opened := map[string]bool{}
+	var mu sync.Mutex
+	openOnce := func(team string, open func() (awid.EventSource, error)) EventStreamOpener {
+		return func(ctx context.Context, deadline time.Time) (awid.EventSource, error) {
+			mu.Lock()
+			first := !opened[team]
+			opened[team] = true
+			mu.Unlock()
+			if !first {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return open()
+		}
+	}
+	bus := NewEventBus(EventBusConfig{Teams: []TeamEventSource{
+		{Team: "backend:demo", Stream: openOnce("backend:demo", func() (awid.EventSource, error) {
+			return stallingEventSource{newFakeEventSource(awid.AgentEvent{Type: awid.AgentEventActionableMail, MessageID: "m-1"})}, nil
+		})},
+		{Team: "ops:demo", Stream: openOnce("ops:demo", func() (awid.EventSource, error) {
+			return nil, &awid.APIError{StatusCode: 403, Body: "forbidden"}
+		})},
+	}})
+	var notices []string
+	bus.onConnectionNotice = func(notice string) {
+		mu.Lock()
+		notices = append(notices, notice)
+		mu.Unlock()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus.Start(ctx)
+
+	select {
+	case <-bus.Queue().Ready():
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for queue ready")
+	}
+	evt, ok := bus.Queue().Pop()
+	if !ok || evt.Team != "backend:demo" || evt.Event.TeamID != "backend:demo" {
+		t.Fatalf("event=%+v ok=%v", evt, ok)
+	}
+
+	// The rejected team stops; the other keeps the bus streaming.
+	noticed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(notices) > 0
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for (!noticed() || bus.State() != ConnStreaming) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if bus.State() != ConnStreaming {
+		t.Fatalf("state=%s", bus.State())
+	}
+	cancel()
+	bus.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := "aweb [ops:demo]: event stream disconnected (authentication or request rejected); not retrying"
+	if len(notices) != 1 || notices[0] != want {
+		t.Fatalf("notices=%q", notices)
+	}
+}
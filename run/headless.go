@@ -0,0 +1,290 @@
+package run
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HeadlessUI drives the loop without a terminal. It writes one JSON object
+// per line to out for every display line and loop event, and reads control
+// commands as JSON lines from stdin or a Unix socket.
+type HeadlessUI struct {
+	in         io.Reader
+	socketPath string
+	now        func() time.Time
+
+	mu       sync.Mutex
+	out      io.Writer
+	partial  strings.Builder
+	partKind DisplayKind
+	active   bool
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+
+	events chan ControlEvent
+}
+
+var (
+	_ UI           = (*HeadlessUI)(nil)
+	_ LoopObserver = (*HeadlessUI)(nil)
+)
+
+// HeadlessRecord is one line of headless output.
+type HeadlessRecord struct {
+	Type string      `json:"type"`
+	Time string      `json:"time"`
+	Kind DisplayKind `json:"kind,omitempty"`
+	Text string      `json:"text,omitempty"`
+	*LoopEvent
+}
+
+// HeadlessCommand is one control line: {"type":"prompt","text":"..."},
+// {"type":"pause"}, {"type":"resume"}, {"type":"stop"}, {"type":"quit"},
+// {"type":"autofeed","enabled":true}, {"type":"provider_input","text":"..."},
+// or {"type":"approve","text":"<id>"} and {"type":"deny","text":"<id>"}.
+type HeadlessCommand struct {
+	Type     string           `json:"type"`
+	Text     string           `json:"text,omitempty"`
+	Enabled  *bool            `json:"enabled,omitempty"`
+	Approval *ApprovalRequest `json:"approval,omitempty"`
+}
+
+// NewHeadlessUI reads commands from in, or from a Unix socket at
+// socketPath when it is non-empty, and writes records to out.
+func NewHeadlessUI(in io.Reader, out io.Writer, socketPath string) *HeadlessUI {
+	return &HeadlessUI{
+		in:         in,
+		out:        out,
+		socketPath: strings.TrimSpace(socketPath),
+		now:        time.Now,
+		conns:      map[net.Conn]struct{}{},
+		events:     make(chan ControlEvent, 64),
+	}
+}
+
+// ParseHeadlessCommand converts one JSON control line into a ControlEvent.
+func ParseHeadlessCommand(line string) (ControlEvent, error) {
+	var cmd HeadlessCommand
+	if err := json.Unmarshal([]byte(line), &cmd); err != nil {
+		return ControlEvent{}, fmt.Errorf("invalid control line: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(cmd.Type)) {
+	case "prompt":
+		if strings.TrimSpace(cmd.Text) == "" {
+			return ControlEvent{}, errors.New("prompt text is required")
+		}
+		return ControlEvent{Type: ControlPrompt, Text: cmd.Text}, nil
+	case "pause", "wait":
+		return ControlEvent{Type: ControlWait}, nil
+	case "resume":
+		return ControlEvent{Type: ControlResume}, nil
+	case "stop":
+		return ControlEvent{Type: ControlStop}, nil
+	case "quit", "exit":
+		return ControlEvent{Type: ControlQuit}, nil
+	case "autofeed":
+		if cmd.Enabled == nil {
+			return ControlEvent{}, errors.New("autofeed needs enabled")
+		}
+		if *cmd.Enabled {
+			return ControlEvent{Type: ControlAutofeedOn}, nil
+		}
+		return ControlEvent{Type: ControlAutofeedOff}, nil
+	case "provider_input":
+		return ControlEvent{Type: ControlProviderInput, Text: cmd.Text}, nil
+	case "approve":
+		return ControlEvent{Type: ControlApprove, Text: strings.TrimSpace(cmd.Text)}, nil
+	case "deny":
+		return ControlEvent{Type: ControlDeny, Text: strings.TrimSpace(cmd.Text)}, nil
+	default:
+		return ControlEvent{}, fmt.Errorf("unknown control type %q", cmd.Type)
+	}
+}
+
+func (h *HeadlessUI) Start() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.active {
+		return nil
+	}
+	if h.socketPath != "" {
+		listener, err := listenUnixSocket(h.socketPath)
+		if err != nil {
+			return err
+		}
+		h.listener = listener
+		h.wg.Add(1)
+		go h.acceptLoop(listener)
+	} else if h.in != nil {
+		// Stdin cannot be interrupted portably; this reader ends at EOF.
+		go h.readCommands(h.in)
+	}
+	h.active = true
+	return nil
+}
+
+func (h *HeadlessUI) Stop() error {
+	h.mu.Lock()
+	if !h.active {
+		h.mu.Unlock()
+		return nil
+	}
+	h.active = false
+	h.flushPartialLocked()
+	listener := h.listener
+	h.listener = nil
+	for conn := range h.conns {
+		_ = conn.Close()
+	}
+	h.mu.Unlock()
+	if listener != nil {
+		_ = listener.Close()
+		_ = os.Remove(h.socketPath)
+	}
+	h.wg.Wait()
+	return nil
+}
+
+func (h *HeadlessUI) acceptLoop(listener net.Listener) {
+	defer h.wg.Done()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		h.mu.Lock()
+		if !h.active {
+			h.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		h.conns[conn] = struct{}{}
+		h.mu.Unlock()
+		h.wg.Add(1)
+		go func() {
+			defer h.wg.Done()
+			h.readCommands(conn)
+			h.mu.Lock()
+			delete(h.conns, conn)
+			h.mu.Unlock()
+			_ = conn.Close()
+		}()
+	}
+}
+
+func (h *HeadlessUI) readCommands(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		event, err := ParseHeadlessCommand(line)
+		if err != nil {
+			h.emit(HeadlessRecord{Type: "control_error", Text: err.Error()})
+			continue
+		}
+		h.events <- event
+	}
+}
+
+func (h *HeadlessUI) Events() <-chan ControlEvent {
+	return h.events
+}
+
+func (h *HeadlessUI) HasPendingInput() bool { return false }
+
+func (h *HeadlessUI) HasActiveProgram() bool { return false }
+
+func (h *HeadlessUI) AppendText(text string) {
+	h.AppendDisplayText(DisplayKindPlain, text)
+}
+
+func (h *HeadlessUI) AppendLine(line string) {
+	h.AppendDisplayLine(DisplayKindPlain, line)
+}
+
+// AppendDisplayText buffers streamed text until it completes a line.
+func (h *HeadlessUI) AppendDisplayText(kind DisplayKind, text string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.partial.Len() > 0 && h.partKind != kind {
+		h.flushPartialLocked()
+	}
+	h.partKind = kind
+	h.partial.WriteString(text)
+	buffered := h.partial.String()
+	newline := strings.LastIndexByte(buffered, '\n')
+	if newline < 0 {
+		return
+	}
+	h.partial.Reset()
+	h.partial.WriteString(buffered[newline+1:])
+	for _, line := range SplitDisplayText(kind, buffered[:newline]) {
+		h.emitLocked(HeadlessRecord{Type: "display", Kind: line.Kind, Text: line.Text})
+	}
+}
+
+func (h *HeadlessUI) AppendDisplayLine(kind DisplayKind, line string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.flushPartialLocked()
+	for _, split := range SplitDisplayText(kind, line) {
+		h.emitLocked(HeadlessRecord{Type: "display", Kind: split.Kind, Text: split.Text})
+	}
+}
+
+func (h *HeadlessUI) flushPartialLocked() {
+	if h.partial.Len() == 0 {
+		return
+	}
+	text := h.partial.String()
+	h.partial.Reset()
+	for _, line := range SplitDisplayText(h.partKind, text) {
+		h.emitLocked(HeadlessRecord{Type: "display", Kind: line.Kind, Text: line.Text})
+	}
+}
+
+// The footer has no meaning without a terminal; status lines, the input
+// line and the busy spinner are dropped.
+func (h *HeadlessUI) SetInputLine(string)      {}
+func (h *HeadlessUI) SetStatusLine(string)     {}
+func (h *HeadlessUI) ClearStatusLine()         {}
+func (h *HeadlessUI) ClearInputLine()          {}
+func (h *HeadlessUI) SetExitConfirmation(bool) {}
+
+func (h *HeadlessUI) ObserveLoopEvent(event LoopEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.flushPartialLocked()
+	h.emitLocked(HeadlessRecord{Type: string(event.Type), LoopEvent: &event})
+}
+
+func (h *HeadlessUI) emit(record HeadlessRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.emitLocked(record)
+}
+
+func (h *HeadlessUI) emitLocked(record HeadlessRecord) {
+	if h.out == nil {
+		return
+	}
+	record.Time = h.now().UTC().Format(time.RFC3339Nano)
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+	_, _ = h.out.Write(data)
+}
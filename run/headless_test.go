@@ -0,0 +1,166 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) records(t *testing.T) []map[string]any {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("non-JSON output line %q: %v", line, err)
+		}
+		out = append(out, record)
+	}
+	return out
+}
+
+func TestHeadlessUIRunsPromptFromStdinAndEmitsJSONLines(t *testing.T) {
+	stdinR, stdinW := io.Pipe()
+	defer stdinW.Close()
+	out := &syncBuffer{}
+	ui := NewHeadlessUI(stdinR, out, "")
+
+	cost := 0.02
+	loop := NewLoop(fakeProvider{event: &Event{Type: EventText, Text: "hello from agent\n", Session: "s-1", CostUSD: &cost, Usage: &UsageStats{InputTokens: 7}}}, io.Discard)
+	loop.Control = ui
+	loop.Runner = func(ctx context.Context, dir string, argv []string, onLine func(string), stderrSink any) error {
+		onLine("{}")
+		return nil
+	}
+
+	go func() {
+		_, _ = io.WriteString(stdinW, "not json\n")
+		_, _ = io.WriteString(stdinW, `{"type":"prompt","text":"fix the bug"}`+"\n")
+	}()
+	if err := loop.Run(context.Background(), LoopOptions{MaxRuns: 1, WaitSeconds: 5}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	records := out.records(t)
+	var types []string
+	for _, record := range records {
+		types = append(types, record["type"].(string))
+	}
+	joined := strings.Join(types, ",")
+	for _, want := range []string{"control_error", "wake", "run_start", "display", "run_finish"} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("missing %s record in %s", want, joined)
+		}
+	}
+	for _, record := range records {
+		switch record["type"] {
+		case "run_start":
+			if record["prompt"] != "fix the bug" || record["wake_source"] != "user" {
+				t.Fatalf("run_start=%#v", record)
+			}
+		case "run_finish":
+			summary, _ := record["summary"].(map[string]any)
+			if summary["agent_text"] != "hello from agent" || record["cost_usd"] != 0.02 || record["session_id"] != "s-1" {
+				t.Fatalf("run_finish=%#v", record)
+			}
+			if usage, _ := record["usage"].(map[string]any); usage["input_tokens"] != float64(7) {
+				t.Fatalf("run_finish usage=%#v", record["usage"])
+			}
+		}
+	}
+	if strings.Contains(out.buf.String(), "aweb agent runner") {
+		t.Fatalf("headless output included the startup banner: %s", out.buf.String())
+	}
+}
+
+func TestParseHeadlessCommand(t *testing.T) {
+	for line, want := range map[string]ControlEventType{
+		`{"type":"prompt","text":"hi"}`:       ControlPrompt,
+		`{"type":"pause"}`:                    ControlWait,
+		`{"type":"resume"}`:                   ControlResume,
+		`{"type":"stop"}`:                     ControlStop,
+		`{"type":"quit"}`:                     ControlQuit,
+		`{"type":"autofeed","enabled":false}`: ControlAutofeedOff,
+	} {
+		event, err := ParseHeadlessCommand(line)
+		if err != nil || event.Type != want {
+			t.Fatalf("%s: event=%#v err=%v", line, event, err)
+		}
+	}
+	for _, line := range []string{`{"type":"prompt"}`, `{"type":"autofeed"}`, `{"type":"dance"}`, `nope`} {
+		if _, err := ParseHeadlessCommand(line); err == nil {
+			t.Fatalf("%s: expected error", line)
+		}
+	}
+}
+
+func TestHeadlessUIReadsControlLinesFromUnixSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "awh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "control.sock")
+	ui := NewHeadlessUI(nil, io.Discard, socketPath)
+	if err := ui.Start(); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(conn, `{"type":"stop"}`+"\n"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-ui.Events():
+		if event.Type != ControlStop {
+			t.Fatalf("event=%#v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for socket control event")
+	}
+	_ = conn.Close()
+	if err := ui.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Fatalf("expected socket removed on stop, stat err=%v", err)
+	}
+}
+
+func TestHeadlessUIBuffersStreamedTextIntoLines(t *testing.T) {
+	out := &syncBuffer{}
+	ui := NewHeadlessUI(nil, out, "")
+	ui.AppendDisplayText(DisplayKindAgentText, "hel")
+	ui.AppendDisplayText(DisplayKindAgentText, "lo\nwor")
+	ui.AppendDisplayLine(DisplayKindTool, "Bash ls")
+	records := out.records(t)
+	if len(records) != 3 || records[0]["text"] != "hello" || records[1]["text"] != "wor" || records[2]["kind"] != string(DisplayKindTool) {
+		t.Fatalf("records=%#v", records)
+	}
+}
@@ -0,0 +1,128 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/awebai/aw/awid"
+)
+
+const leaseReleaseTimeout = 5 * time.Second
+
+// SessionLease keeps the principal's session admission lease while a loop
+// runs, so only one aw run answers for an identity at a time. A loop that
+// loses the lease pauses read-only until it exits.
+type SessionLease struct {
+	// Renew extends the lease and returns its new expiry.
+	Renew func(ctx context.Context) (time.Time, error)
+	// Release gives the lease up when the loop exits.
+	Release func(ctx context.Context) error
+	// Interval is the time between renewals; failed renewals retry sooner.
+	Interval time.Duration
+	// ExpiresAt is when the lease as acquired runs out.
+	ExpiresAt time.Time
+	// TTL is how long the lease is taken to last after an acquisition or
+	// renewal whose expiry the server did not report. Interval when zero.
+	TTL time.Duration
+}
+
+// keep renews the lease until ctx ends and returns why it was lost, or ""
+// when ctx ended first. A renewal the server refuses loses the lease at
+// once; other failures are retried until the lease expires.
+func (s *SessionLease) keep(ctx context.Context, now func() time.Time) string {
+	wait := s.Interval
+	ttl := s.TTL
+	if ttl <= 0 {
+		ttl = s.Interval
+	}
+	expiresAt := s.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = now().Add(ttl)
+	}
+	for {
+		if err := SleepWithContext(ctx, wait); err != nil {
+			return ""
+		}
+		next, err := s.Renew(ctx)
+		if ctx.Err() != nil {
+			return ""
+		}
+		if err == nil {
+			if next.IsZero() {
+				next = now().Add(ttl)
+			}
+			expiresAt = next
+			wait = s.Interval
+			continue
+		}
+		if leaseRefused(err) {
+			return fmt.Sprintf("session lease renewal refused: %v", err)
+		}
+		if !now().Before(expiresAt) {
+			return fmt.Sprintf("session lease expired before it could be renewed: %v", err)
+		}
+		wait = s.Interval / 4
+	}
+}
+
+// leaseRefused reports whether a renewal error means another session holds
+// the lease, as opposed to a failure worth retrying.
+func leaseRefused(err error) bool {
+	code, ok := awid.HTTPStatusCode(err)
+	if !ok || code < 400 || code >= 500 {
+		return false
+	}
+	return code != http.StatusRequestTimeout && code != http.StatusTooManyRequests
+}
+
+// keepLease renews l.Lease in the background and returns a function that
+// stops renewing and releases the lease, unless it was already lost.
+func (l *Loop) keepLease(ctx context.Context) func() {
+	if l.EventBus == nil {
+		l.leaseInterrupts = make(chan BusEvent, 1)
+	}
+	leaseCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	var lost atomic.Bool
+	go func() {
+		defer close(done)
+		if reason := l.Lease.keep(leaseCtx, l.Now); reason != "" {
+			lost.Store(true)
+			l.loseLease(ctx, reason)
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+		if lost.Load() || l.Lease.Release == nil {
+			return
+		}
+		releaseCtx, cancelRelease := context.WithTimeout(context.Background(), leaseReleaseTimeout)
+		defer cancelRelease()
+		if err := l.Lease.Release(releaseCtx); err != nil {
+			l.printf("warning: could not release session lease: %v\n", err)
+		}
+	}
+}
+
+// loseLease reports the loss, runs OnLeaseLost so the caller stops work
+// that speaks for the identity, and hands the loss to the main loop as an
+// interrupt, which stops any active run and holds the loop paused.
+func (l *Loop) loseLease(ctx context.Context, reason string) {
+	l.println("\naweb: " + reason)
+	if l.OnLeaseLost != nil {
+		l.OnLeaseLost()
+	}
+	interrupts := l.leaseInterrupts
+	if l.EventBus != nil {
+		interrupts = l.EventBus.interrupts
+	}
+	evt := BusEvent{Priority: PriorityInterrupt, Event: awid.AgentEvent{Type: awid.AgentEventLeaseLost, Text: reason}}
+	select {
+	case interrupts <- evt:
+	case <-ctx.Done():
+	}
+}
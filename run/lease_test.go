@@ -0,0 +1,187 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	awid "github.com/awebai/aw/awid"
+)
+
+func TestSessionLeaseKeepStopsWhenRenewalRefused(t *testing.T) {
+	var renewals atomic.Int32
+	lease := &SessionLease{
+		Interval: time.Millisecond,
+		Renew: func(context.Context) (time.Time, error) {
+			if renewals.Add(1) < 3 {
+				return time.Now().Add(time.Minute), nil
+			}
+			return time.Time{}, &awid.APIError{StatusCode: 409, Body: "held by another session"}
+		},
+	}
+
+	reason := lease.keep(context.Background(), time.Now)
+	if !strings.Contains(reason, "refused") || renewals.Load() != 3 {
+		t.Fatalf("reason=%q renewals=%d", reason, renewals.Load())
+	}
+}
+
+func TestSessionLeaseKeepRetriesUntilExpiry(t *testing.T) {
+	var renewals atomic.Int32
+	expiresAt := time.Now().Add(time.Hour)
+	now := func() time.Time {
+		if renewals.Load() >= 3 {
+			return expiresAt
+		}
+		return expiresAt.Add(-time.Minute)
+	}
+	lease := &SessionLease{
+		Interval:  time.Millisecond,
+		ExpiresAt: expiresAt,
+		Renew: func(context.Context) (time.Time, error) {
+			renewals.Add(1)
+			return time.Time{}, &awid.APIError{StatusCode: 503, Body: "unavailable"}
+		},
+	}
+
+	reason := lease.keep(context.Background(), now)
+	if !strings.Contains(reason, "expired") || renewals.Load() != 3 {
+		t.Fatalf("reason=%q renewals=%d", reason, renewals.Load())
+	}
+}
+
+func TestSessionLeaseKeepExpiresWithoutReportedExpiry(t *testing.T) {
+	lease := &SessionLease{
+		Interval: time.Millisecond,
+		TTL:      20 * time.Millisecond,
+		Renew: func(context.Context) (time.Time, error) {
+			return time.Time{}, &awid.APIError{StatusCode: 503, Body: "unavailable"}
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if reason := lease.keep(ctx, time.Now); !strings.Contains(reason, "expired") {
+		t.Fatalf("reason=%q, want expiry after the TTL", reason)
+	}
+}
+
+func TestKeepLeaseReleasesOnStopUnlessLost(t *testing.T) {
+	var releases atomic.Int32
+	loop := NewLoop(ClaudeProvider{}, &bytes.Buffer{})
+	loop.Lease = &SessionLease{
+		Interval: time.Hour,
+		Renew:    func(context.Context) (time.Time, error) { return time.Time{}, nil },
+		Release: func(context.Context) error {
+			releases.Add(1)
+			return nil
+		},
+	}
+	loop.keepLease(context.Background())()
+	if releases.Load() != 1 {
+		t.Fatalf("releases=%d after stop", releases.Load())
+	}
+
+	bus := newTestEventBus()
+	loop.EventBus = bus
+	loop.Lease.Interval = time.Millisecond
+	loop.Lease.Renew = func(context.Context) (time.Time, error) {
+		return time.Time{}, &awid.APIError{StatusCode: 409}
+	}
+	stop := loop.keepLease(context.Background())
+	select {
+	case evt := <-bus.Interrupts():
+		if evt.Event.Type != awid.AgentEventLeaseLost {
+			t.Fatalf("interrupt=%#v", evt)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("lease loss was not reported")
+	}
+	stop()
+	if releases.Load() != 1 {
+		t.Fatalf("lost lease was released: releases=%d", releases.Load())
+	}
+}
+
+func TestLeaseLostHoldsLoopPausedReadOnly(t *testing.T) {
+	var out bytes.Buffer
+	loop := NewLoop(ClaudeProvider{}, &out)
+	st := &state{}
+
+	canceled := false
+	loop.applyBusInterrupt(BusEvent{
+		Priority: PriorityInterrupt,
+		Event:    awid.AgentEvent{Type: awid.AgentEventLeaseLost, Text: "session lease renewal refused"},
+	}, st, func() { canceled = true })
+	if !canceled || !st.Paused || !st.LeaseLost {
+		t.Fatalf("canceled=%v state=%+v", canceled, st)
+	}
+
+	loop.applyControlEvent(ControlEvent{Type: ControlPrompt, Text: "answer the mail"}, st, false, nil)
+	loop.applyControlEvent(ControlEvent{Type: ControlResume}, st, false, nil)
+	loop.holdWhileLeaseLost(st)
+	if st.NextPrompt != "" || !st.Paused {
+		t.Fatalf("lease-lost loop accepted work: %+v", st)
+	}
+
+	if !strings.Contains(out.String(), "paused read-only") {
+		t.Fatalf("output=%q", out.String())
+	}
+}
+
+func TestLeaseLostHoldsLoopWithoutControls(t *testing.T) {
+	out := &syncBuffer{}
+	loop := NewLoop(ClaudeProvider{}, out)
+	loop.Sleep = func(ctx context.Context, d time.Duration) error {
+		return SleepWithContext(ctx, 50*time.Millisecond)
+	}
+	var runs atomic.Int32
+	// The first run is still going when the lease is lost, so the loss
+	// reaches the loop through the run, not the idle wait.
+	loop.Runner = func(ctx context.Context, dir string, argv []string, onLine func(string), stderrSink any) error {
+		if runs.Add(1) == 1 {
+			onLine(`{"type":"system","subtype":"init","session_id":"sess-42"}`)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(2 * time.Second):
+			}
+		}
+		onLine(`{"type":"result","duration_ms":1000,"session_id":"sess-42"}`)
+		return nil
+	}
+	loop.Dispatch = &fakeDispatcher{
+		decisions: []DispatchDecision{
+			{Mission: "first", WaitSeconds: 1},
+			{Mission: "second", WaitSeconds: 1},
+			{Mission: "third", WaitSeconds: 1},
+		},
+	}
+	var lostCalls atomic.Int32
+	loop.OnLeaseLost = func() { lostCalls.Add(1) }
+	loop.Lease = &SessionLease{
+		Interval: time.Millisecond,
+		Renew: func(context.Context) (time.Time, error) {
+			return time.Time{}, &awid.APIError{StatusCode: 409, Body: "held by another session"}
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	_ = loop.Run(ctx, LoopOptions{WaitSeconds: 1, MaxRuns: 3})
+
+	if got := runs.Load(); got != 1 {
+		t.Fatalf("runs=%d after the lease was lost, want 1", got)
+	}
+	if lostCalls.Load() != 1 {
+		t.Fatalf("OnLeaseLost calls=%d", lostCalls.Load())
+	}
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	if text := out.buf.String(); !strings.Contains(text, "session lease renewal refused") || !strings.Contains(text, "paused read-only") {
+		t.Fatalf("output=%q", text)
+	}
+}
@@ -28,9 +28,12 @@ type Loop struct {
 	Runner            CommandRunner
 	Sleep             SleepFunc
 	EventBus          *EventBus
+	Lease             *SessionLease
+	TaskClaims        *TaskClaims
 	ServiceSupervisor ServiceSupervisor
 	Out               io.Writer
 	Control           InputController
+	ControlSocket     *ControlSocket
 	Dispatch          Dispatcher
 	Now               func() time.Time
 	InputPromptLabel  string
@@ -39,8 +42,19 @@ type Loop struct {
 	OnRunComplete     func(RunSummary)
 	OnSessionID       func(string)
 	OnBuildCommand    func([]string, BuildOptions)
+	// OnLeaseLost runs once, off the main loop, when the session lease is
+	// lost.
+	OnLeaseLost func()
 
 	writeMu sync.Mutex
+
+	// controlMerged carries Control and ControlSocket events when the
+	// socket is in use; snapshot is the state the socket reports.
+	controlMerged chan ControlEvent
+	snapshot      atomic.Pointer[LoopSnapshot]
+
+	// leaseInterrupts carries a lost lease to a loop without an event bus.
+	leaseInterrupts chan BusEvent
 }
 
 type state struct {
@@ -54,6 +68,7 @@ type state struct {
 	PauseNoticeShown   bool
 	StopRequested      bool
 	Paused             bool
+	LeaseLost          bool
 	ExitConfirmPending bool
 	Autofeed           bool
 	NextPrompt         string
@@ -65,6 +80,12 @@ type state struct {
 	LastRunError       string
 	LastRunUsage       UsageStats
 	HasRunUsage        bool
+	LastRunCostUSD     float64
+	RunWakeSource      string
+	Budget             BudgetConfig
+	Ledger             *RunLedger
+	BudgetNotices      map[string]bool
+	IdleReported       bool
 	ProviderInput      *providerInputState
 	ClaimedTaskRef     string
 }
@@ -111,6 +132,8 @@ func (p *providerInputState) SendLine(text string) error {
 const (
 	pausedNoticeText = "paused. type a prompt or clear the input to resume."
 	pausedStatusText = "paused: type or clear input to resume"
+	leaseLostNotice  = "another aw run took this identity's session lease. paused read-only; /quit to exit."
+	leaseLostStatus  = "paused read-only: session lease lost"
 	exitStatusText   = "exit aw run? [y/N]"
 	startupBanner    = `                                         _           _
   __ ___      __  _    __ ___      _____| |__   __ _(_)
@@ -123,6 +146,8 @@ const (
   /provider TEXT  send one line to the active provider stdin
   /autofeed on    enable autofeed (work events wake the agent)
   /autofeed off   disable autofeed
+  /approve ID SIG allow a pending approval (SIG: aw id team approve)
+  /deny [ID]      refuse a pending approval (default: oldest)
   /quit           exit aw run
   /help           show this help`
 )
@@ -157,20 +182,34 @@ func (l *Loop) Run(ctx context.Context, opts LoopOptions) error {
 	if l.Out == nil {
 		l.Out = io.Discard
 	}
-	if l.Dispatch == nil && strings.TrimSpace(opts.BasePrompt) == "" && strings.TrimSpace(opts.InitialPrompt) == "" && l.Control == nil {
+	if l.Dispatch == nil && strings.TrimSpace(opts.BasePrompt) == "" && strings.TrimSpace(opts.InitialPrompt) == "" && !l.hasControls() {
 		return fmt.Errorf("prompt cannot be empty when dispatch is unavailable")
 	}
 
 	state := &state{
 		Autofeed:       opts.Autofeed,
 		ClaimedTaskRef: strings.TrimSpace(opts.ClaimedTaskRef),
+		Budget:         opts.Budget,
 	}
+	l.loadLedger(opts, state)
 	if l.Control != nil {
 		if err := l.Control.Start(); err != nil {
 			return err
 		}
 		defer func() { _ = l.Control.Stop() }()
 	}
+	if l.ControlSocket != nil {
+		l.publishState(state)
+		if err := l.ControlSocket.Start(l.Snapshot); err != nil {
+			// Running without the socket beats refusing to run.
+			l.printf("control socket disabled: %v\n", err)
+			l.ControlSocket = nil
+		} else {
+			defer func() { _ = l.ControlSocket.Stop() }()
+			stopMerge := l.mergeControlEvents()
+			defer stopMerge()
+		}
+	}
 	serviceSupervisor := l.ServiceSupervisor
 	if serviceSupervisor == nil && len(opts.Services) > 0 {
 		serviceSupervisor = NewServiceManager(l.println)
@@ -196,6 +235,7 @@ func (l *Loop) Run(ctx context.Context, opts LoopOptions) error {
 			if cs == ConnStreaming {
 				streamErrorReported.Store(false)
 			}
+			l.observe(LoopEvent{Type: LoopEventConnection, Connection: cs.String()})
 			l.markStatusDirty()
 		}
 		l.EventBus.onConnectionNotice = l.println
@@ -208,14 +248,34 @@ func (l *Loop) Run(ctx context.Context, opts LoopOptions) error {
 		l.EventBus.Start(ctx)
 		defer l.EventBus.Stop()
 	}
+	if l.Lease != nil {
+		defer l.keepLease(ctx)()
+	}
+	if l.TaskClaims != nil {
+		defer l.keepTaskClaims(ctx, state)()
+	}
 	l.refreshStatusLine(state)
 	l.showStartupGreeting(opts, state)
+	if err := l.enforceBudget(state); err != nil {
+		return err
+	}
+	if state.PauseAfterRun {
+		// Over budget before the first run: wait for the operator rather
+		// than spending on the base prompt.
+		if err := l.waitForNextCycle(ctx, opts.WaitSeconds, state); err != nil {
+			if state.StopRequested && errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		}
+	}
 
 	for {
 		decision, err := l.nextPrompt(ctx, opts, state)
 		if err != nil {
 			return err
 		}
+		l.observeWake(decision, state)
 		if decision.Skip {
 			if err := l.waitForWork(ctx, decision.WaitSeconds, state); err != nil {
 				if state.StopRequested && errors.Is(err, context.Canceled) {
@@ -242,6 +302,7 @@ func (l *Loop) Run(ctx context.Context, opts LoopOptions) error {
 		}
 		state.Run++
 		runErr := l.runOnce(ctx, opts, state, fullPrompt, decision.ImagePaths, displayLines, decision.UserPrompt)
+		l.recordRunSpend(opts, state)
 		interrupted := errors.Is(runErr, errRunInterrupted)
 		if runErr != nil && !interrupted {
 			if state.StopRequested && (errors.Is(runErr, context.Canceled) || errors.Is(runErr, context.DeadlineExceeded)) {
@@ -262,6 +323,9 @@ func (l *Loop) Run(ctx context.Context, opts LoopOptions) error {
 				return err
 			}
 		}
+		if err := l.enforceBudget(state); err != nil {
+			return err
+		}
 		if opts.MaxRuns > 0 && state.Run >= opts.MaxRuns {
 			l.printf("\ndone: reached max-runs (%d)\n", opts.MaxRuns)
 			return nil
@@ -287,6 +351,7 @@ func (l *Loop) nextPrompt(ctx context.Context, opts LoopOptions, st *state) (Dis
 		explicitMissionPrompt = strings.TrimSpace(opts.InitialPrompt)
 	}
 	if explicitMissionPrompt != "" {
+		st.RunWakeSource = wakeSourceUser
 		return DispatchDecision{
 			Mission:     explicitMissionPrompt,
 			UserPrompt:  explicitMissionPrompt,
@@ -295,9 +360,10 @@ func (l *Loop) nextPrompt(ctx context.Context, opts LoopOptions, st *state) (Dis
 		}, nil
 	}
 	if st.Run == 0 && strings.TrimSpace(opts.BasePrompt) != "" {
+		st.RunWakeSource = wakeSourceBasePrompt
 		return DispatchDecision{Mission: strings.TrimSpace(opts.BasePrompt), WaitSeconds: opts.WaitSeconds}, nil
 	}
-	if l.Dispatch == nil && st.Run == 0 && l.Control != nil && strings.TrimSpace(opts.BasePrompt) == "" {
+	if l.Dispatch == nil && st.Run == 0 && l.hasControls() && strings.TrimSpace(opts.BasePrompt) == "" {
 		return DispatchDecision{WaitSeconds: opts.WaitSeconds, Skip: true}, nil
 	}
 	if l.Dispatch != nil {
@@ -312,6 +378,10 @@ func (l *Loop) nextPrompt(ctx context.Context, opts LoopOptions, st *state) (Dis
 			decision.WaitSeconds = opts.WaitSeconds
 		}
 		st.LastWakeEvent = nil
+		st.RunWakeSource = wakeSourceDispatch
+		if wakeEvent != nil && wakeEvent.Type != "" {
+			st.RunWakeSource = string(wakeEvent.Type)
+		}
 		return decision, nil
 	}
 	// Without an external dispatcher, run one cycle then rely on wake/control
@@ -319,6 +389,7 @@ func (l *Loop) nextPrompt(ctx context.Context, opts LoopOptions, st *state) (Dis
 	if st.Run > 0 && l.EventBus != nil {
 		return DispatchDecision{WaitSeconds: opts.WaitSeconds, Skip: true}, nil
 	}
+	st.RunWakeSource = wakeSourceUser
 	return DispatchDecision{Mission: explicitMissionPrompt, WaitSeconds: opts.WaitSeconds}, nil
 }
 
@@ -329,6 +400,7 @@ func (l *Loop) runOnce(ctx context.Context, opts LoopOptions, st *state, prompt
 	st.LastRunError = ""
 	st.LastRunUsage = UsageStats{}
 	st.HasRunUsage = false
+	st.LastRunCostUSD = 0
 	if text := strings.TrimSpace(userPrompt); text != "" && l.OnUserPrompt != nil {
 		l.OnUserPrompt(text)
 	}
@@ -345,6 +417,9 @@ func (l *Loop) runOnce(ctx context.Context, opts LoopOptions, st *state, prompt
 	if opts.ProviderPTY {
 		buildOpts.PromptTransport = PromptTransportArg
 	}
+	if pinned, ok := l.Provider.(interface{ PromptTransport() PromptTransport }); ok && pinned.PromptTransport() != "" {
+		buildOpts.PromptTransport = pinned.PromptTransport()
+	}
 	worktreeGitDir, err := detectWorktreeGitDir(opts.WorkingDir)
 	if err != nil {
 		return err
@@ -374,7 +449,15 @@ func (l *Loop) runOnce(ctx context.Context, opts LoopOptions, st *state, prompt
 		l.OnBuildCommand(append([]string(nil), argv...), buildCopy)
 	}
 
+	l.observe(LoopEvent{
+		Type:       LoopEventRunStart,
+		Run:        st.Run,
+		WakeSource: st.RunWakeSource,
+		Prompt:     strings.TrimSpace(userPrompt),
+		SessionID:  buildOpts.SessionID,
+	})
 	st.RunPhase = RunPhaseWorking
+	l.publishState(st)
 	l.setBusy(true)
 	defer l.setBusy(false)
 	if len(displayLines) > 0 {
@@ -386,7 +469,7 @@ func (l *Loop) runOnce(ctx context.Context, opts LoopOptions, st *state, prompt
 		}
 		l.displayText(DisplayKindPlain, "\n")
 	}
-	l.setStatusLine(formatRunStatus(st, l.connState()))
+	l.setStatusLine(formatRunStatus(st, l.connState(), l.Now()))
 	l.renderInputPrompt(st)
 
 	presenter := &presenterState{}
@@ -403,19 +486,18 @@ func (l *Loop) runOnce(ctx context.Context, opts LoopOptions, st *state, prompt
 			providerInput.Clear()
 		}
 		st.ProviderInput = nil
-		if l.OnRunComplete == nil {
-			return
-		}
-		text := strings.TrimSpace(agentText.String())
-		if text == "" {
-			return
-		}
-		l.OnRunComplete(RunSummary{
+		summary := RunSummary{
 			UserPrompt: strings.TrimSpace(userPrompt),
 			SessionID:  strings.TrimSpace(st.SessionID),
-			AgentText:  text,
+			AgentText:  strings.TrimSpace(agentText.String()),
 			Failed:     strings.TrimSpace(st.LastRunError) != "",
-		})
+		}
+		l.observeRunFinish(st, summary)
+		l.publishState(st)
+		if l.OnRunComplete == nil || summary.AgentText == "" {
+			return
+		}
+		l.OnRunComplete(summary)
 	}()
 	runCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -434,10 +516,7 @@ func (l *Loop) runOnce(ctx context.Context, opts LoopOptions, st *state, prompt
 		}
 	}
 
-	var busInterrupts <-chan BusEvent
-	if l.EventBus != nil {
-		busInterrupts = l.EventBus.Interrupts()
-	}
+	busInterrupts := l.interrupts()
 
 	sinks := &commandOutputSinks{
 		usePTY: opts.ProviderPTY,
@@ -573,10 +652,11 @@ func (l *Loop) handleOutputLine(line string, presenter *presenterState, st *stat
 	}
 	if event != nil && event.CostUSD != nil {
 		st.CumulativeCostUSD += *event.CostUSD
+		st.LastRunCostUSD += *event.CostUSD
 		statusChanged = true
 	}
 	if statusChanged {
-		l.setStatusLine(formatRunStatus(st, l.connState()))
+		l.setStatusLine(formatRunStatus(st, l.connState(), l.Now()))
 	}
 	switch event.Type {
 	case EventText:
@@ -715,8 +795,12 @@ func (l *Loop) waitForNextCycle(ctx context.Context, waitSeconds int, st *state)
 	if st.StopRequested {
 		return context.Canceled
 	}
-	if l.Control == nil {
-		return l.idle(ctx, waitSeconds)
+	if st.LeaseLost {
+		l.holdWhileLeaseLost(st)
+		return l.waitWhilePaused(ctx, st)
+	}
+	if !l.hasControls() {
+		return l.idle(ctx, waitSeconds, st)
 	}
 	if strings.TrimSpace(st.NextPrompt) != "" {
 		return nil
@@ -862,6 +946,19 @@ func (l *Loop) applyBusInterrupt(evt BusEvent, st *state, cancel context.CancelF
 			cancel()
 		}
 		return false
+	case awid.AgentEventLeaseLost:
+		st.LeaseLost = true
+		st.PendingInput = false
+		st.InputBuffer = ""
+		st.Paused = true
+		st.PauseAfterRun = cancel != nil
+		st.RunInterrupted = cancel != nil
+		l.println(leaseLostNotice)
+		st.PauseNoticeShown = true
+		if cancel != nil {
+			cancel()
+		}
+		return false
 	case awid.AgentEventControlPause:
 		st.PauseAfterRun = true
 		l.println("\nwill pause after this run.")
@@ -878,12 +975,10 @@ func (l *Loop) applyBusInterrupt(evt BusEvent, st *state, cancel context.CancelF
 }
 
 func (l *Loop) waitWhilePaused(ctx context.Context, st *state) error {
-	var busInterrupts <-chan BusEvent
-	if l.EventBus != nil {
-		busInterrupts = l.EventBus.Interrupts()
-	}
+	busInterrupts := l.interrupts()
 
 	for {
+		l.holdWhileLeaseLost(st)
 		l.refreshStatusLine(st)
 		if st.StopRequested {
 			return context.Canceled
@@ -902,12 +997,13 @@ func (l *Loop) waitWhilePaused(ctx context.Context, st *state) error {
 		}
 		select {
 		case busEvt := <-busInterrupts:
-			if l.applyBusInterrupt(busEvt, st, nil) {
+			if l.applyBusInterrupt(busEvt, st, nil) && !st.LeaseLost {
 				st.Paused = false
 				return nil
 			}
 		case event := <-l.controlEvents():
 			l.applyControlEvent(event, st, false, nil)
+			l.holdWhileLeaseLost(st)
 			if st.StopRequested {
 				return context.Canceled
 			}
@@ -926,11 +1022,44 @@ func (l *Loop) waitWhilePaused(ctx context.Context, st *state) error {
 	}
 }
 
-func (l *Loop) idle(ctx context.Context, seconds int) error {
+// holdWhileLeaseLost keeps a loop that lost its session lease paused:
+// queued prompts are dropped and resume requests have no effect.
+func (l *Loop) holdWhileLeaseLost(st *state) {
+	if !st.LeaseLost {
+		return
+	}
+	if strings.TrimSpace(st.NextPrompt) != "" {
+		l.println("info: prompt dropped: " + leaseLostStatus)
+	}
+	st.NextPrompt = ""
+	st.NextImagePaths = nil
+	st.Paused = true
+	st.PauseAfterRun = false
+}
+
+// idle waits between runs of a loop without controls. A lost lease or a
+// pause still reaches it, and holds the loop instead of starting the next
+// run.
+func (l *Loop) idle(ctx context.Context, seconds int, st *state) error {
 	if seconds <= 0 {
 		return nil
 	}
-	for remaining := seconds; remaining > 0; remaining-- {
+	interrupts := l.interrupts()
+	for remaining := seconds; ; remaining-- {
+		select {
+		case busEvt := <-interrupts:
+			l.applyBusInterrupt(busEvt, st, nil)
+			if st.LeaseLost || st.Paused {
+				l.clearStatusLine()
+				return l.waitWhilePaused(ctx, st)
+			}
+			remaining++
+			continue
+		default:
+		}
+		if remaining <= 0 {
+			break
+		}
 		l.renderIdleLine("next run", remaining, nil)
 		if err := l.Sleep(ctx, time.Second); err != nil {
 			l.clearStatusLine()
@@ -983,19 +1112,135 @@ func (l *Loop) idleWithControlsLabel(ctx context.Context, seconds int, st *state
 	return nil
 }
 
+// interrupts returns the channel interrupts reach the loop on: the event
+// bus's, or the lease's own when the loop runs without a bus.
+func (l *Loop) interrupts() <-chan BusEvent {
+	if l.EventBus != nil {
+		return l.EventBus.Interrupts()
+	}
+	return l.leaseInterrupts
+}
+
 func (l *Loop) controlEvents() <-chan ControlEvent {
+	if l.controlMerged != nil {
+		return l.controlMerged
+	}
 	if l.Control == nil {
 		return nil
 	}
 	return l.Control.Events()
 }
 
+func (l *Loop) hasControls() bool {
+	return l.Control != nil || l.ControlSocket != nil
+}
+
+// mergeControlEvents fans Control and ControlSocket events into one
+// channel so every wait site sees both.
+func (l *Loop) mergeControlEvents() func() {
+	merged := make(chan ControlEvent, 64)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	forward := func(source <-chan ControlEvent) {
+		defer wg.Done()
+		for {
+			select {
+			case event := <-source:
+				select {
+				case merged <- event:
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}
+	if l.Control != nil {
+		if source := l.Control.Events(); source != nil {
+			wg.Add(1)
+			go forward(source)
+		}
+	}
+	wg.Add(1)
+	go forward(l.ControlSocket.Events())
+	l.controlMerged = merged
+	return func() {
+		close(done)
+		wg.Wait()
+		l.controlMerged = nil
+	}
+}
+
+// Snapshot returns the loop state last published by the main loop. It is
+// safe to call from any goroutine.
+func (l *Loop) Snapshot() LoopSnapshot {
+	var snapshot LoopSnapshot
+	if published := l.snapshot.Load(); published != nil {
+		snapshot = *published
+	}
+	snapshot.Connection = l.connState().String()
+	if l.EventBus != nil {
+		snapshot.PendingEvents = l.EventBus.Queue().Len()
+	}
+	return snapshot
+}
+
+func (l *Loop) resolveApproval(event ControlEvent) {
+	if l.ControlSocket == nil {
+		l.println("info: no pending approvals")
+		return
+	}
+	approved := event.Type == ControlApprove
+	id, signature := ParseApprovalArgs(event.Text)
+	action, err := l.ControlSocket.ResolveApproval(id, approved, "local", signature)
+	if err != nil {
+		l.printf("info: %v\n", err)
+		return
+	}
+	if approved {
+		l.printf("approved: %s\n", action)
+	} else {
+		l.printf("denied: %s\n", action)
+	}
+}
+
+func (l *Loop) publishState(st *state) {
+	if l.ControlSocket == nil || st == nil {
+		return
+	}
+	l.snapshot.Store(&LoopSnapshot{
+		Run:           st.Run,
+		Phase:         st.RunPhase,
+		SessionID:     st.SessionID,
+		Paused:        st.Paused,
+		PauseAfterRun: st.PauseAfterRun,
+		LeaseLost:     st.LeaseLost,
+		Autofeed:      st.Autofeed,
+		QueuedPrompt:  strings.TrimSpace(st.NextPrompt),
+		WakeSource:    st.RunWakeSource,
+		ClaimedTask:   st.ClaimedTaskRef,
+		CostUSD:       st.CumulativeCostUSD,
+		LastRunError:  st.LastRunError,
+		UpdatedAt:     l.Now().UTC(),
+	})
+}
+
 func (l *Loop) applyControlEvent(event ControlEvent, st *state, activeRun bool, cancel context.CancelFunc) {
+	defer l.publishState(st)
 	switch event.Type {
 	case ControlHelp:
 		l.println(helpText)
 		l.renderInputPrompt(st)
 		return
+	case ControlApprovalRequested:
+		l.println(event.Text)
+		l.renderInputPrompt(st)
+		return
+	case ControlApprove, ControlDeny:
+		l.resolveApproval(event)
+		l.renderInputPrompt(st)
+		return
 	case ControlUnknownCommand:
 		resolved, handled, err := resolvePromptInput(event.Text)
 		if err != nil {
@@ -1081,6 +1326,11 @@ func (l *Loop) applyControlEvent(event ControlEvent, st *state, activeRun bool,
 			l.queuePromptText(st, event.Text, nil, activeRun)
 		}
 		l.renderInputPrompt(st)
+	case ControlInjectPrompt:
+		// Scripted prompts queue like typed ones but are not a manual
+		// conversation, so autofeed stays as it is.
+		l.enqueuePrompt(st, event.Text, nil, activeRun, false)
+		l.renderInputPrompt(st)
 	case ControlWait:
 		st.PendingInput = false
 		st.InputBuffer = ""
@@ -1102,6 +1352,11 @@ func (l *Loop) applyControlEvent(event ControlEvent, st *state, activeRun bool,
 		}
 		l.renderInputPrompt(st)
 	case ControlAutofeedOn:
+		if l.autofeedRefused(st) {
+			l.println("info: autofeed stays off: budget exceeded.")
+			l.renderInputPrompt(st)
+			break
+		}
 		st.Autofeed = true
 		l.announceAutofeedState(true, "on. work events can wake the agent.")
 		l.renderInputPrompt(st)
@@ -1155,6 +1410,10 @@ func (l *Loop) applyControlEvent(event ControlEvent, st *state, activeRun bool,
 }
 
 func (l *Loop) queuePromptText(st *state, rawText string, imagePaths []string, activeRun bool) {
+	l.enqueuePrompt(st, rawText, imagePaths, activeRun, true)
+}
+
+func (l *Loop) enqueuePrompt(st *state, rawText string, imagePaths []string, activeRun bool, manual bool) {
 	if st == nil {
 		return
 	}
@@ -1174,14 +1433,14 @@ func (l *Loop) queuePromptText(st *state, rawText string, imagePaths []string, a
 	}
 	st.Paused = false
 	st.PauseNoticeShown = false
-	if st.Autofeed {
+	if manual && st.Autofeed {
 		st.Autofeed = false
 		l.announceAutofeedState(false, "disabled for manual conversation. use /autofeed on to re-enable.")
 	}
 	if activeRun {
 		l.printf("\nqueued: %s\n", newText)
 		if st.RunPhase == RunPhaseWorking {
-			l.setStatusLine(formatRunStatus(st, l.connState()))
+			l.setStatusLine(formatRunStatus(st, l.connState(), l.Now()))
 		}
 	}
 }
@@ -1400,6 +1659,9 @@ func (l *Loop) showStartupGreeting(opts LoopOptions, st *state) {
 	if l.screen() == nil || opts.ContinueMode {
 		return
 	}
+	if _, headless := l.Control.(*HeadlessUI); headless {
+		return
+	}
 
 	l.println(startupBanner)
 	l.println("The aweb agent runner")
@@ -1421,17 +1683,22 @@ func (l *Loop) refreshStatusLine(st *state) {
 		l.clearStatusLine()
 		return
 	}
+	defer l.publishState(st)
 	if st.ExitConfirmPending {
 		l.setStatusLine(exitStatusText)
 		return
 	}
 	if st.Paused {
 		st.RunPhase = RunPhasePaused
-		l.setStatusLine(pausedStatusText)
+		if st.LeaseLost {
+			l.setStatusLine(leaseLostStatus)
+		} else {
+			l.setStatusLine(pausedStatusText)
+		}
 		return
 	}
 	conn := l.connState()
-	if status := formatRunStatus(st, conn); strings.TrimSpace(status) != "" {
+	if status := formatRunStatus(st, conn, l.Now()); strings.TrimSpace(status) != "" {
 		l.setStatusLine(status)
 		return
 	}
@@ -1442,7 +1709,7 @@ func (l *Loop) refreshStatusLine(st *state) {
 	} else {
 		st.RunPhase = RunPhaseWaitingForWork
 	}
-	l.setStatusLine(formatWaitStatus(label, st, conn))
+	l.setStatusLine(formatWaitStatus(label, st, conn, l.Now()))
 }
 
 func (l *Loop) clearStatusLine() {
@@ -1470,6 +1737,50 @@ func (l *Loop) setBusy(active bool) {
 	}
 }
 
+func (l *Loop) observe(event LoopEvent) {
+	if l == nil || l.Control == nil {
+		return
+	}
+	if observer, ok := l.Control.(LoopObserver); ok {
+		observer.ObserveLoopEvent(event)
+	}
+}
+
+// observeWake reports dispatch decisions. Idle skips are reported once per
+// idle stretch rather than on every wait tick.
+func (l *Loop) observeWake(decision DispatchDecision, st *state) {
+	if decision.Skip {
+		if st.IdleReported {
+			return
+		}
+		st.IdleReported = true
+		l.observe(LoopEvent{Type: LoopEventWake, Skip: true, WaitSeconds: decision.WaitSeconds})
+		return
+	}
+	st.IdleReported = false
+	l.observe(LoopEvent{Type: LoopEventWake, Run: st.Run + 1, WakeSource: st.RunWakeSource, Prompt: strings.TrimSpace(decision.UserPrompt)})
+}
+
+func (l *Loop) observeRunFinish(st *state, summary RunSummary) {
+	event := LoopEvent{
+		Type:       LoopEventRunFinish,
+		Run:        st.Run,
+		WakeSource: st.RunWakeSource,
+		SessionID:  summary.SessionID,
+		Summary:    &summary,
+		Error:      strings.TrimSpace(st.LastRunError),
+	}
+	if st.HasRunUsage {
+		usage := st.LastRunUsage
+		event.Usage = &usage
+	}
+	if st.LastRunCostUSD > 0 {
+		cost := st.LastRunCostUSD
+		event.CostUSD = &cost
+	}
+	l.observe(event)
+}
+
 func (l *Loop) screen() UI {
 	if l == nil || l.Control == nil {
 		return nil
@@ -802,7 +802,7 @@ func TestLoopBasePromptDoesNotAutoRerunWithoutWake(t *testing.T) {
 
 func TestFormatRunStatusOmitsRunLabel(t *testing.T) {
 	st := &state{RunPhase: RunPhaseWaitingForWork}
-	got := formatRunStatus(st, ConnDisconnected)
+	got := formatRunStatus(st, ConnDisconnected, time.Now())
 	if got != "" {
 		t.Fatalf("expected empty status with only run label, got %q", got)
 	}
@@ -810,7 +810,7 @@ func TestFormatRunStatusOmitsRunLabel(t *testing.T) {
 
 func TestFormatWaitStatusShowsConnectionStateAndAutofeed(t *testing.T) {
 	st := &state{Autofeed: true}
-	got := formatWaitStatus("waiting for prompt", st, ConnReconnecting)
+	got := formatWaitStatus("waiting for prompt", st, ConnReconnecting, time.Now())
 	want := "waiting for prompt · autofeed · aweb events down; retrying"
 	if got != want {
 		t.Fatalf("expected %q, got %q", want, got)
@@ -819,7 +819,7 @@ func TestFormatWaitStatusShowsConnectionStateAndAutofeed(t *testing.T) {
 
 func TestFormatWaitStatusShowsClaimedTaskRef(t *testing.T) {
 	st := &state{ClaimedTaskRef: "aweb-aaag"}
-	got := formatWaitStatus("waiting for prompt", st, ConnDisconnected)
+	got := formatWaitStatus("waiting for prompt", st, ConnDisconnected, time.Now())
 	want := "waiting for prompt · task aweb-aaag"
 	if got != want {
 		t.Fatalf("expected %q, got %q", want, got)
@@ -832,7 +832,7 @@ func TestFormatRunStatusShowsCostAndAutofeed(t *testing.T) {
 		CumulativeCostUSD: 0.05,
 		Autofeed:          true,
 	}
-	got := formatRunStatus(st, ConnStreaming)
+	got := formatRunStatus(st, ConnStreaming, time.Now())
 	want := "$0.05 · autofeed · aweb events connected"
 	if got != want {
 		t.Fatalf("expected %q, got %q", want, got)
@@ -844,7 +844,7 @@ func TestFormatRunStatusShowsClaimedTaskRef(t *testing.T) {
 		RunPhase:       RunPhaseWorking,
 		ClaimedTaskRef: "aweb-aaag",
 	}
-	got := formatRunStatus(st, ConnStreaming)
+	got := formatRunStatus(st, ConnStreaming, time.Now())
 	want := "task aweb-aaag · aweb events connected"
 	if got != want {
 		t.Fatalf("expected %q, got %q", want, got)
@@ -856,7 +856,7 @@ func TestFormatRunStatusShowsReconnecting(t *testing.T) {
 		RunPhase:          RunPhaseWorking,
 		CumulativeCostUSD: 0.05,
 	}
-	got := formatRunStatus(st, ConnReconnecting)
+	got := formatRunStatus(st, ConnReconnecting, time.Now())
 	want := "$0.05 · aweb events down; retrying"
 	if got != want {
 		t.Fatalf("expected %q, got %q", want, got)
@@ -868,7 +868,7 @@ func TestFormatRunStatusShowsQueuedWhenPromptPending(t *testing.T) {
 		RunPhase:   RunPhaseWorking,
 		NextPrompt: "fix the bug",
 	}
-	got := formatRunStatus(st, ConnDisconnected)
+	got := formatRunStatus(st, ConnDisconnected, time.Now())
 	if got != "queued" {
 		t.Fatalf("expected 'queued', got %q", got)
 	}
@@ -876,7 +876,7 @@ func TestFormatRunStatusShowsQueuedWhenPromptPending(t *testing.T) {
 
 func TestFormatRunStatusOmitsQueuedWhenNoPromptPending(t *testing.T) {
 	st := &state{RunPhase: RunPhaseWorking}
-	got := formatRunStatus(st, ConnDisconnected)
+	got := formatRunStatus(st, ConnDisconnected, time.Now())
 	if strings.Contains(got, "queued") {
 		t.Fatalf("expected no 'queued' indicator without pending prompt, got %q", got)
 	}
@@ -3,6 +3,7 @@ package run
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 func IdentityPromptLabel(projectSlug string, canonicalOrigin string, repoOrigin string, alias string) string {
@@ -47,7 +48,7 @@ func ComposeStatusLine(identity string, transient string) string {
 	return identity + " · " + transient
 }
 
-func formatRunStatus(st *state, conn ConnectionState) string {
+func formatRunStatus(st *state, conn ConnectionState, now time.Time) string {
 	if st == nil || st.RunPhase != RunPhaseWorking {
 		return ""
 	}
@@ -58,6 +59,9 @@ func formatRunStatus(st *state, conn ConnectionState) string {
 	if st.CumulativeCostUSD > 0 {
 		parts = append(parts, fmt.Sprintf("$%.2f", st.CumulativeCostUSD))
 	}
+	if budget := formatBudgetStatus(st, now); budget != "" {
+		parts = append(parts, budget)
+	}
 	if st.Autofeed {
 		parts = append(parts, "autofeed")
 	}
@@ -73,7 +77,7 @@ func formatRunStatus(st *state, conn ConnectionState) string {
 	return strings.Join(parts, " · ")
 }
 
-func formatWaitStatus(label string, st *state, conn ConnectionState) string {
+func formatWaitStatus(label string, st *state, conn ConnectionState, now time.Time) string {
 	label = strings.TrimSpace(label)
 	if label == "" {
 		return ""
@@ -85,6 +89,9 @@ func formatWaitStatus(label string, st *state, conn ConnectionState) string {
 			parts = append(parts, "task "+taskRef)
 		}
 	}
+	if budget := formatBudgetStatus(st, now); budget != "" {
+		parts = append(parts, budget)
+	}
 	if st != nil && st.Autofeed {
 		parts = append(parts, "autofeed")
 	}
@@ -7,14 +7,28 @@ import (
 )
 
 func NewProvider(name string) (Provider, error) {
-	switch strings.ToLower(strings.TrimSpace(name)) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	switch key {
 	case "", "claude":
 		return ClaudeProvider{}, nil
 	case "codex":
 		return CodexProvider{}, nil
-	default:
-		return nil, fmt.Errorf("unsupported provider %q", name)
 	}
+	if provider, ok := registeredProvider(key); ok {
+		return provider, nil
+	}
+	spec, ok, err := userProviderSpec(key)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		provider, err := NewDeclarativeProvider(spec)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	}
+	return nil, fmt.Errorf("unsupported provider %q", name)
 }
 
 type ClaudeProvider struct{}
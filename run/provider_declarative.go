@@ -0,0 +1,544 @@
+package run
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/awebai/aw/awconfig"
+)
+
+// ProviderSpec declares how to drive a headless agent CLI that emits JSON
+// lines. Argument templates may reference {prompt}, {session_id}, {model},
+// {dir}, {path} and {allowed_tools}; each template is appended only when the
+// value it describes is set.
+type ProviderSpec struct {
+	Name    string   `json:"name"`
+	Command []string `json:"command"`
+
+	// PromptTransport pins how the prompt reaches the CLI. Empty follows
+	// the loop (stdin, or arg under --provider-pty); "arg" always passes
+	// it through PromptArgs for CLIs that cannot read stdin.
+	PromptTransport  PromptTransport `json:"prompt_transport,omitempty"`
+	PromptArgs       []string        `json:"prompt_args,omitempty"`
+	StdinArgs        []string        `json:"stdin_args,omitempty"`
+	ModelArgs        []string        `json:"model_args,omitempty"`
+	ResumeArgs       []string        `json:"resume_args,omitempty"`
+	ContinueArgs     []string        `json:"continue_args,omitempty"`
+	AddDirArgs       []string        `json:"add_dir_args,omitempty"`
+	ImageArgs        []string        `json:"image_args,omitempty"`
+	AllowedToolsArgs []string        `json:"allowed_tools_args,omitempty"`
+	BypassArgs       []string        `json:"bypass_args,omitempty"`
+
+	ResumeCommand []string `json:"resume_command,omitempty"`
+	ResumeHint    []string `json:"resume_hint,omitempty"`
+
+	// SessionIDPath is read from every output line that carries it, for
+	// CLIs that repeat the session id on each event.
+	SessionIDPath string              `json:"session_id_path,omitempty"`
+	Events        []ProviderEventRule `json:"events"`
+}
+
+// ProviderEventRule maps one kind of output line to a run.Event. Paths are
+// dotted JSON paths ("item.content.0.text"). Match compares the value at
+// each path with the expected string; "*" only requires the path to exist.
+// The first matching rule wins.
+type ProviderEventRule struct {
+	Match      map[string]string   `json:"match"`
+	Event      EventType           `json:"event,omitempty"`
+	Text       string              `json:"text,omitempty"`
+	SessionID  string              `json:"session_id,omitempty"`
+	ToolName   string              `json:"tool_name,omitempty"`
+	ToolInput  string              `json:"tool_input,omitempty"`
+	DurationMS string              `json:"duration_ms,omitempty"`
+	CostUSD    string              `json:"cost_usd,omitempty"`
+	IsError    string              `json:"is_error,omitempty"`
+	Usage      *ProviderUsagePaths `json:"usage,omitempty"`
+}
+
+type ProviderUsagePaths struct {
+	InputTokens              string `json:"input_tokens,omitempty"`
+	CacheCreationInputTokens string `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     string `json:"cache_read_input_tokens,omitempty"`
+	OutputTokens             string `json:"output_tokens,omitempty"`
+}
+
+var providerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+func builtinProviderNames() []string {
+	return []string{"claude", "codex"}
+}
+
+// Validate checks the spec is usable before it is registered.
+func (s ProviderSpec) Validate() error {
+	name := strings.TrimSpace(s.Name)
+	if !providerNamePattern.MatchString(name) {
+		return fmt.Errorf("provider name %q must be lower-case letters, digits, '.', '_' or '-'", s.Name)
+	}
+	if len(s.Command) == 0 || strings.TrimSpace(s.Command[0]) == "" {
+		return fmt.Errorf("provider %s: command is required", name)
+	}
+	switch s.PromptTransport {
+	case "", PromptTransportArg, PromptTransportStdin:
+	default:
+		return fmt.Errorf("provider %s: unsupported prompt_transport %q", name, s.PromptTransport)
+	}
+	if len(s.Events) == 0 {
+		return fmt.Errorf("provider %s: at least one events rule is required", name)
+	}
+	for i, rule := range s.Events {
+		if len(rule.Match) == 0 {
+			return fmt.Errorf("provider %s: events[%d] needs a match", name, i)
+		}
+		switch rule.Event {
+		case "", EventText, EventToolCall, EventToolResult, EventDone, EventSystem:
+		default:
+			return fmt.Errorf("provider %s: events[%d] has unsupported event %q", name, i, rule.Event)
+		}
+		if rule.Event == EventToolCall && rule.ToolName == "" {
+			return fmt.Errorf("provider %s: events[%d] tool_call needs tool_name", name, i)
+		}
+	}
+	return nil
+}
+
+// DeclarativeProvider runs a CLI described by a ProviderSpec.
+type DeclarativeProvider struct {
+	spec ProviderSpec
+}
+
+func NewDeclarativeProvider(spec ProviderSpec) (*DeclarativeProvider, error) {
+	spec.Name = strings.TrimSpace(spec.Name)
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &DeclarativeProvider{spec: spec}, nil
+}
+
+func (p *DeclarativeProvider) Name() string {
+	return p.spec.Name
+}
+
+// PromptTransport reports the transport the spec pins, or "" when the
+// provider follows the loop's choice.
+func (p *DeclarativeProvider) PromptTransport() PromptTransport {
+	return p.spec.PromptTransport
+}
+
+func (p *DeclarativeProvider) BuildCommand(prompt string, opts BuildOptions) ([]string, error) {
+	if strings.TrimSpace(prompt) == "" {
+		return nil, fmt.Errorf("prompt cannot be empty")
+	}
+	spec := p.spec
+	vars := templateVars("prompt", prompt, "model", opts.Model, "session_id", opts.SessionID, "allowed_tools", opts.AllowedTools)
+
+	command := append([]string(nil), spec.Command...)
+	if !opts.TripOnDanger {
+		command = appendTemplate(command, spec.BypassArgs, vars)
+	}
+	if strings.TrimSpace(opts.SessionID) != "" && len(spec.ResumeArgs) > 0 {
+		command = appendTemplate(command, spec.ResumeArgs, vars)
+	} else if opts.ContinueSession || strings.TrimSpace(opts.SessionID) != "" {
+		if len(spec.ContinueArgs) == 0 {
+			return nil, fmt.Errorf("provider %s does not support resuming a session", spec.Name)
+		}
+		command = appendTemplate(command, spec.ContinueArgs, vars)
+	}
+	if strings.TrimSpace(opts.AllowedTools) != "" {
+		if len(spec.AllowedToolsArgs) == 0 {
+			return nil, fmt.Errorf("provider %s does not support --allowed-tools", spec.Name)
+		}
+		command = appendTemplate(command, spec.AllowedToolsArgs, vars)
+	}
+	if strings.TrimSpace(opts.Model) != "" {
+		if len(spec.ModelArgs) == 0 {
+			return nil, fmt.Errorf("provider %s does not support --model", spec.Name)
+		}
+		command = appendTemplate(command, spec.ModelArgs, vars)
+	}
+	for _, dir := range opts.AddDirs {
+		if strings.TrimSpace(dir) == "" {
+			continue
+		}
+		if len(spec.AddDirArgs) == 0 {
+			return nil, fmt.Errorf("provider %s does not support extra directories", spec.Name)
+		}
+		command = appendTemplate(command, spec.AddDirArgs, templateVars("dir", dir))
+	}
+	for _, path := range opts.ImagePaths {
+		if strings.TrimSpace(path) == "" {
+			continue
+		}
+		if len(spec.ImageArgs) == 0 {
+			return nil, fmt.Errorf("provider %s does not support images", spec.Name)
+		}
+		command = appendTemplate(command, spec.ImageArgs, templateVars("path", path))
+	}
+	command = append(command, opts.ProviderArgs...)
+
+	transport := opts.PromptTransport
+	if spec.PromptTransport != "" {
+		transport = spec.PromptTransport
+	}
+	if transport == PromptTransportStdin {
+		return appendTemplate(command, spec.StdinArgs, vars), nil
+	}
+	promptArgs := spec.PromptArgs
+	if len(promptArgs) == 0 {
+		promptArgs = []string{"{prompt}"}
+	}
+	return appendTemplate(command, promptArgs, vars), nil
+}
+
+func (p *DeclarativeProvider) BuildResumeCommand(opts BuildOptions) ([]string, error) {
+	return p.buildResume(p.spec.ResumeCommand, opts, true)
+}
+
+func (p *DeclarativeProvider) BuildResumeHint(opts BuildOptions) ([]string, error) {
+	if len(p.spec.ResumeHint) > 0 {
+		return p.buildResume(p.spec.ResumeHint, opts, false)
+	}
+	return p.buildResume(p.spec.ResumeCommand, opts, false)
+}
+
+func (p *DeclarativeProvider) buildResume(template []string, opts BuildOptions, full bool) ([]string, error) {
+	sessionID := strings.TrimSpace(opts.SessionID)
+	if sessionID == "" {
+		return nil, fmt.Errorf("session id is required")
+	}
+	if len(template) == 0 {
+		return nil, fmt.Errorf("provider %s does not support interactive resume", p.spec.Name)
+	}
+	vars := templateVars("session_id", sessionID, "model", opts.Model)
+	command := appendTemplate(nil, template, vars)
+	if full && !opts.TripOnDanger {
+		command = appendTemplate(command, p.spec.BypassArgs, vars)
+	}
+	if strings.TrimSpace(opts.Model) != "" {
+		if len(p.spec.ModelArgs) == 0 {
+			return nil, fmt.Errorf("provider %s does not support --model", p.spec.Name)
+		}
+		command = appendTemplate(command, p.spec.ModelArgs, vars)
+	}
+	if full {
+		command = append(command, opts.ProviderArgs...)
+	}
+	return command, nil
+}
+
+func (p *DeclarativeProvider) ParseOutput(line string) (*Event, error) {
+	var doc any
+	if err := json.Unmarshal([]byte(line), &doc); err != nil {
+		return nil, err
+	}
+	event := &Event{}
+	if p.spec.SessionIDPath != "" {
+		event.Session = jsonPathString(doc, p.spec.SessionIDPath)
+	}
+	for _, rule := range p.spec.Events {
+		if !ruleMatches(doc, rule.Match) {
+			continue
+		}
+		event.Type = rule.Event
+		if rule.Text != "" {
+			event.Text = jsonPathString(doc, rule.Text)
+		}
+		if rule.SessionID != "" {
+			if session := jsonPathString(doc, rule.SessionID); session != "" {
+				event.Session = session
+			}
+		}
+		if rule.Event == EventToolCall {
+			call := ToolCall{Name: jsonPathString(doc, rule.ToolName)}
+			if input, ok := jsonPathValue(doc, rule.ToolInput); ok && rule.ToolInput != "" {
+				if fields, ok := input.(map[string]any); ok {
+					call.Input = fields
+				} else {
+					call.Input = map[string]any{"input": input}
+				}
+			}
+			event.ToolCalls = []ToolCall{call}
+		}
+		if rule.DurationMS != "" {
+			if ms, ok := jsonPathNumber(doc, rule.DurationMS); ok {
+				event.DurationMS = int(ms)
+			}
+		}
+		if rule.CostUSD != "" {
+			if cost, ok := jsonPathNumber(doc, rule.CostUSD); ok {
+				event.CostUSD = &cost
+			}
+		}
+		if rule.IsError != "" {
+			if value, ok := jsonPathValue(doc, rule.IsError); ok {
+				flag, _ := value.(bool)
+				event.IsError = flag
+			}
+		}
+		if rule.Usage != nil {
+			event.Usage = parseUsagePaths(doc, *rule.Usage)
+		}
+		break
+	}
+	return event, nil
+}
+
+func (p *DeclarativeProvider) SessionID(event *Event) string {
+	if event == nil {
+		return ""
+	}
+	return strings.TrimSpace(event.Session)
+}
+
+func parseUsagePaths(doc any, paths ProviderUsagePaths) *UsageStats {
+	read := func(path string) (int, bool) {
+		if path == "" {
+			return 0, false
+		}
+		n, ok := jsonPathNumber(doc, path)
+		return int(n), ok
+	}
+	var usage UsageStats
+	found := false
+	for _, field := range []struct {
+		path string
+		dst  *int
+	}{
+		{paths.InputTokens, &usage.InputTokens},
+		{paths.CacheCreationInputTokens, &usage.CacheCreationInputTokens},
+		{paths.CacheReadInputTokens, &usage.CacheReadInputTokens},
+		{paths.OutputTokens, &usage.OutputTokens},
+	} {
+		if n, ok := read(field.path); ok {
+			*field.dst = n
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+	return &usage
+}
+
+func ruleMatches(doc any, match map[string]string) bool {
+	for path, want := range match {
+		value, ok := jsonPathValue(doc, path)
+		if !ok {
+			return false
+		}
+		if want != "*" && jsonScalarString(value) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonPathValue walks a dotted path through decoded JSON objects and
+// arrays; numeric segments index arrays.
+func jsonPathValue(doc any, path string) (any, bool) {
+	current := doc
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			next, ok := node[segment]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func jsonPathString(doc any, path string) string {
+	value, ok := jsonPathValue(doc, path)
+	if !ok {
+		return ""
+	}
+	return jsonScalarString(value)
+}
+
+func jsonPathNumber(doc any, path string) (float64, bool) {
+	value, ok := jsonPathValue(doc, path)
+	if !ok {
+		return 0, false
+	}
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func jsonScalarString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64, bool:
+		return fmt.Sprint(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+}
+
+// templateVars builds one replacer for the {key} placeholders in a spec
+// template. Every placeholder is substituted in a single pass, so a value
+// that itself contains "{prompt}" or "{model}" is passed through verbatim.
+func templateVars(pairs ...string) *strings.Replacer {
+	oldnew := make([]string, 0, len(pairs))
+	for i := 0; i+1 < len(pairs); i += 2 {
+		oldnew = append(oldnew, "{"+pairs[i]+"}", pairs[i+1])
+	}
+	return strings.NewReplacer(oldnew...)
+}
+
+func appendTemplate(command []string, template []string, vars *strings.Replacer) []string {
+	for _, arg := range template {
+		command = append(command, vars.Replace(arg))
+	}
+	return command
+}
+
+var (
+	providerRegistryMu sync.RWMutex
+	providerRegistry   = map[string]func() Provider{}
+)
+
+// RegisterProvider makes a provider available to NewProvider by name.
+// Built-in providers cannot be replaced, and a name registers once.
+func RegisterProvider(name string, factory func() Provider) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !providerNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid provider name %q", name)
+	}
+	if factory == nil {
+		return fmt.Errorf("provider %s: factory is required", name)
+	}
+	for _, builtin := range builtinProviderNames() {
+		if name == builtin {
+			return fmt.Errorf("provider %s is built in", name)
+		}
+	}
+	providerRegistryMu.Lock()
+	defer providerRegistryMu.Unlock()
+	if _, exists := providerRegistry[name]; exists {
+		return fmt.Errorf("provider %s is already registered", name)
+	}
+	providerRegistry[name] = factory
+	return nil
+}
+
+func registeredProvider(name string) (Provider, bool) {
+	providerRegistryMu.RLock()
+	factory, ok := providerRegistry[name]
+	providerRegistryMu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return factory(), true
+}
+
+// LoadProviderSpecs reads every *.json provider definition in dir. A
+// missing directory yields no specs. A spec without a name takes the file
+// name without its extension.
+func LoadProviderSpecs(dir string) ([]ProviderSpec, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	specs := make([]ProviderSpec, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var spec ProviderSpec
+		if err := json.Unmarshal(data, &spec); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		if strings.TrimSpace(spec.Name) == "" {
+			spec.Name = strings.TrimSuffix(filepath.Base(path), ".json")
+		}
+		if err := spec.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// providerSpecsDir is a variable so tests can point it at a temp dir.
+var providerSpecsDir = awconfig.DefaultProvidersDir
+
+func userProviderSpec(name string) (ProviderSpec, bool, error) {
+	dir, err := providerSpecsDir()
+	if err != nil {
+		return ProviderSpec{}, false, nil
+	}
+	specs, err := LoadProviderSpecs(dir)
+	if err != nil {
+		return ProviderSpec{}, false, err
+	}
+	for _, spec := range specs {
+		if spec.Name == name {
+			return spec, true, nil
+		}
+	}
+	return ProviderSpec{}, false, nil
+}
+
+// ProviderNames lists the built-in, registered and user-defined providers
+// NewProvider accepts, built-ins first.
+func ProviderNames() []string {
+	names := builtinProviderNames()
+	seen := map[string]bool{}
+	for _, name := range names {
+		seen[name] = true
+	}
+	var extra []string
+	providerRegistryMu.RLock()
+	for name := range providerRegistry {
+		extra = append(extra, name)
+	}
+	providerRegistryMu.RUnlock()
+	if dir, err := providerSpecsDir(); err == nil {
+		if specs, err := LoadProviderSpecs(dir); err == nil {
+			for _, spec := range specs {
+				extra = append(extra, spec.Name)
+			}
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,252 @@
+package run
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func geminiTestSpec() ProviderSpec {
+	return ProviderSpec{
+		Name:          "gemini",
+		Command:       []string{"gemini", "--output-format", "stream-json"},
+		PromptArgs:    []string{"-p", "{prompt}"},
+		ModelArgs:     []string{"-m", "{model}"},
+		ResumeArgs:    []string{"--resume", "{session_id}"},
+		ContinueArgs:  []string{"--resume", "latest"},
+		AddDirArgs:    []string{"--include-directories", "{dir}"},
+		BypassArgs:    []string{"--yolo"},
+		ResumeCommand: []string{"gemini", "--resume", "{session_id}"},
+		SessionIDPath: "session_id",
+		Events: []ProviderEventRule{
+			{Match: map[string]string{"type": "message", "role": "assistant"}, Event: EventText, Text: "content"},
+			{Match: map[string]string{"type": "tool_use"}, Event: EventToolCall, ToolName: "tool_name", ToolInput: "parameters"},
+			{Match: map[string]string{"type": "tool_result"}, Event: EventToolResult, Text: "output"},
+			{
+				Match:      map[string]string{"type": "result"},
+				Event:      EventDone,
+				Text:       "response",
+				DurationMS: "stats.duration_ms",
+				IsError:    "is_error",
+				Usage:      &ProviderUsagePaths{InputTokens: "stats.input_tokens", OutputTokens: "stats.output_tokens"},
+			},
+		},
+	}
+}
+
+func TestDeclarativeProviderBuildCommand(t *testing.T) {
+	provider, err := NewDeclarativeProvider(geminiTestSpec())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	command, err := provider.BuildCommand("fix the bug", BuildOptions{
+		Model:           "gemini-2.5-pro",
+		AddDirs:         []string{"/tmp/gitdir"},
+		PromptTransport: PromptTransportArg,
+		ProviderArgs:    []string{"--debug"},
+	})
+	if err != nil {
+		t.Fatalf("BuildCommand returned error: %v", err)
+	}
+	want := "gemini --output-format stream-json --yolo -m gemini-2.5-pro --include-directories /tmp/gitdir --debug -p fix the bug"
+	if got := strings.Join(command, " "); got != want {
+		t.Fatalf("command=%q want %q", got, want)
+	}
+
+	command, err = provider.BuildCommand("next", BuildOptions{SessionID: "s-1", ContinueSession: true, TripOnDanger: true, PromptTransport: PromptTransportStdin})
+	if err != nil {
+		t.Fatalf("BuildCommand returned error: %v", err)
+	}
+	joined := strings.Join(command, " ")
+	if !strings.Contains(joined, "--resume s-1") || strings.Contains(joined, "--yolo") || strings.Contains(joined, "next") {
+		t.Fatalf("resume command=%q", joined)
+	}
+
+	if _, err := provider.BuildCommand("x", BuildOptions{AllowedTools: "read"}); err == nil {
+		t.Fatal("expected error for unsupported allowed tools")
+	}
+}
+
+func TestDeclarativeProviderSubstitutesPlaceholdersInOnePass(t *testing.T) {
+	provider, err := NewDeclarativeProvider(geminiTestSpec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	command, err := provider.BuildCommand("use {model} and {session_id} literally", BuildOptions{
+		Model:           "{prompt}",
+		PromptTransport: PromptTransportArg,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "gemini --output-format stream-json --yolo -m {prompt} -p use {model} and {session_id} literally"
+	if got := strings.Join(command, " "); got != want {
+		t.Fatalf("command=%q want %q", got, want)
+	}
+}
+
+func TestDeclarativeProviderRejectsInputsWithoutTemplate(t *testing.T) {
+	spec := geminiTestSpec()
+	spec.ModelArgs = nil
+	spec.AddDirArgs = nil
+	spec.ImageArgs = nil
+	provider, err := NewDeclarativeProvider(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, opts := range map[string]BuildOptions{
+		"model":   {Model: "m"},
+		"add dir": {AddDirs: []string{"/tmp/x"}},
+		"image":   {ImagePaths: []string{"/tmp/x.png"}},
+	} {
+		if _, err := provider.BuildCommand("x", opts); err == nil {
+			t.Fatalf("%s: expected error for unsupported input", name)
+		}
+	}
+}
+
+func TestDeclarativeProviderPinnedArgTransport(t *testing.T) {
+	spec := geminiTestSpec()
+	spec.PromptTransport = PromptTransportArg
+	provider, err := NewDeclarativeProvider(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	command, err := provider.BuildCommand("hello", BuildOptions{PromptTransport: PromptTransportStdin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := command[len(command)-1]; got != "hello" {
+		t.Fatalf("expected prompt as last arg, got %q", command)
+	}
+	if provider.PromptTransport() != PromptTransportArg {
+		t.Fatalf("PromptTransport=%q", provider.PromptTransport())
+	}
+}
+
+func TestDeclarativeProviderBuildResumeCommand(t *testing.T) {
+	provider, err := NewDeclarativeProvider(geminiTestSpec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	command, err := provider.BuildResumeCommand(BuildOptions{SessionID: "s-1", Model: "m", ProviderArgs: []string{"--debug"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(command, " "); got != "gemini --resume s-1 --yolo -m m --debug" {
+		t.Fatalf("resume command=%q", got)
+	}
+	hint, err := provider.BuildResumeHint(BuildOptions{SessionID: "s-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(hint, " "); got != "gemini --resume s-1" {
+		t.Fatalf("resume hint=%q", got)
+	}
+	if _, err := provider.BuildResumeCommand(BuildOptions{}); err == nil {
+		t.Fatal("expected error without session id")
+	}
+}
+
+func TestDeclarativeProviderParseOutput(t *testing.T) {
+	provider, err := NewDeclarativeProvider(geminiTestSpec())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := provider.ParseOutput(`{"type":"message","role":"assistant","content":"hello","session_id":"s-9"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != EventText || event.Text != "hello" || provider.SessionID(event) != "s-9" {
+		t.Fatalf("text event=%#v", event)
+	}
+
+	event, err = provider.ParseOutput(`{"type":"tool_use","tool_name":"read_file","parameters":{"path":"a.go"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != EventToolCall || len(event.ToolCalls) != 1 || event.ToolCalls[0].Name != "read_file" || event.ToolCalls[0].Input["path"] != "a.go" {
+		t.Fatalf("tool event=%#v", event)
+	}
+
+	event, err = provider.ParseOutput(`{"type":"result","response":"done","is_error":true,"stats":{"duration_ms":1500,"input_tokens":10,"output_tokens":4}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != EventDone || event.DurationMS != 1500 || !event.IsError || event.Usage == nil || event.Usage.InputTokens != 10 || event.Usage.OutputTokens != 4 {
+		t.Fatalf("done event=%#v usage=%#v", event, event.Usage)
+	}
+
+	event, err = provider.ParseOutput(`{"type":"message","role":"user","content":"ignored"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != "" || event.Text != "" {
+		t.Fatalf("unmatched line produced %#v", event)
+	}
+
+	if _, err := provider.ParseOutput("not json"); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
+
+func TestNewProviderLoadsUserSpecsAndRegisteredProviders(t *testing.T) {
+	dir := t.TempDir()
+	original := providerSpecsDir
+	providerSpecsDir = func() (string, error) { return dir, nil }
+	t.Cleanup(func() { providerSpecsDir = original })
+
+	spec := `{"command":["aider","--json"],"events":[{"match":{"type":"text"},"event":"text","text":"text"}]}`
+	if err := os.WriteFile(filepath.Join(dir, "aider.json"), []byte(spec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	provider, err := NewProvider("aider")
+	if err != nil {
+		t.Fatalf("NewProvider(aider): %v", err)
+	}
+	if provider.Name() != "aider" {
+		t.Fatalf("name=%q", provider.Name())
+	}
+
+	if err := RegisterProvider("opencode-test", func() Provider { return provider }); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterProvider("opencode-test", func() Provider { return provider }); err == nil {
+		t.Fatal("expected duplicate registration error")
+	}
+	if err := RegisterProvider("claude", func() Provider { return provider }); err == nil {
+		t.Fatal("expected built-in registration error")
+	}
+	if _, err := NewProvider("opencode-test"); err != nil {
+		t.Fatalf("NewProvider(opencode-test): %v", err)
+	}
+
+	names := strings.Join(ProviderNames(), ",")
+	if !strings.HasPrefix(names, "claude,codex,") || !strings.Contains(names, "aider") || !strings.Contains(names, "opencode-test") {
+		t.Fatalf("ProviderNames=%q", names)
+	}
+
+	if _, err := NewProvider("missing"); err == nil || !strings.Contains(err.Error(), "unsupported provider") {
+		t.Fatalf("expected unsupported provider error, got %v", err)
+	}
+}
+
+func TestLoadProviderSpecsRejectsInvalidSpecs(t *testing.T) {
+	for name, body := range map[string]string{
+		"no command":    `{"events":[{"match":{"type":"x"},"event":"text"}]}`,
+		"no events":     `{"command":["x"]}`,
+		"bad event":     `{"command":["x"],"events":[{"match":{"type":"x"},"event":"bogus"}]}`,
+		"bad transport": `{"command":["x"],"prompt_transport":"pipe","events":[{"match":{"type":"x"}}]}`,
+	} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "p.json"), []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadProviderSpecs(dir); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
@@ -8,17 +8,17 @@ import (
 )
 
 type UsageStats struct {
-	InputTokens              int
-	CacheCreationInputTokens int
-	CacheReadInputTokens     int
-	OutputTokens             int
+	InputTokens              int `json:"input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
 }
 
 type RunSummary struct {
-	UserPrompt string
-	SessionID  string
-	AgentText  string
-	Failed     bool
+	UserPrompt string `json:"user_prompt,omitempty"`
+	SessionID  string `json:"session_id,omitempty"`
+	AgentText  string `json:"agent_text,omitempty"`
+	Failed     bool   `json:"failed"`
 }
 
 type ServiceConfig struct {
@@ -138,6 +138,7 @@ const (
 	ControlAutofeedOn     ControlEventType = "autofeed_on"
 	ControlAutofeedOff    ControlEventType = "autofeed_off"
 	ControlProviderInput  ControlEventType = "provider_input"
+	ControlInjectPrompt   ControlEventType = "inject_prompt"
 	ControlStreamError    ControlEventType = "stream_error"
 	ControlInterrupt      ControlEventType = "interrupt"
 	ControlExitPrompt     ControlEventType = "exit_prompt"
@@ -145,6 +146,10 @@ const (
 	ControlExitCancel     ControlEventType = "exit_cancel"
 	ControlHelp           ControlEventType = "help"
 	ControlUnknownCommand ControlEventType = "unknown_command"
+
+	ControlApprovalRequested ControlEventType = "approval_requested"
+	ControlApprove           ControlEventType = "approve"
+	ControlDeny              ControlEventType = "deny"
 )
 
 type ControlEvent struct {
@@ -173,6 +178,37 @@ type UI interface {
 	HasActiveProgram() bool
 }
 
+type LoopEventType string
+
+const (
+	LoopEventWake       LoopEventType = "wake"
+	LoopEventRunStart   LoopEventType = "run_start"
+	LoopEventRunFinish  LoopEventType = "run_finish"
+	LoopEventConnection LoopEventType = "connection"
+)
+
+// LoopEvent is a structured loop lifecycle event for machine consumers.
+type LoopEvent struct {
+	Type        LoopEventType `json:"type"`
+	Run         int           `json:"run,omitempty"`
+	WakeSource  string        `json:"wake_source,omitempty"`
+	Skip        bool          `json:"skip,omitempty"`
+	WaitSeconds int           `json:"wait_seconds,omitempty"`
+	Prompt      string        `json:"prompt,omitempty"`
+	SessionID   string        `json:"session_id,omitempty"`
+	Summary     *RunSummary   `json:"summary,omitempty"`
+	Usage       *UsageStats   `json:"usage,omitempty"`
+	CostUSD     *float64      `json:"cost_usd,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	Connection  string        `json:"connection,omitempty"`
+}
+
+// LoopObserver is implemented by controls that want LoopEvents in addition
+// to display output. ObserveLoopEvent may be called from any goroutine.
+type LoopObserver interface {
+	ObserveLoopEvent(LoopEvent)
+}
+
 type ServiceSupervisor interface {
 	Start(ctx context.Context, services []ServiceConfig, dir string) error
 	Stop() error
@@ -213,4 +249,6 @@ type LoopOptions struct {
 	ProviderArgs    []string
 	ProviderPTY     bool
 	Services        []ServiceConfig
+	Budget          BudgetConfig
+	LedgerPath      string
 }
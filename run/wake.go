@@ -19,6 +19,18 @@ func NewEventStreamOpener(client *awid.Client) EventStreamOpener {
 	}
 }
 
+// ResumableEventStreamOpener opens a single SSE connection that resumes
+// after lastEventID when it is not empty.
+type ResumableEventStreamOpener func(ctx context.Context, deadline time.Time, lastEventID string) (awid.EventSource, error)
+
+// NewResumableEventStreamOpener returns an opener that connects to the
+// given client's event stream endpoint with Last-Event-ID.
+func NewResumableEventStreamOpener(client *awid.Client) ResumableEventStreamOpener {
+	return func(ctx context.Context, deadline time.Time, lastEventID string) (awid.EventSource, error) {
+		return client.EventStreamFrom(ctx, deadline, lastEventID)
+	}
+}
+
 func nextRetryDelay(delay, maxDelay time.Duration) time.Duration {
 	next := delay * 2
 	if next <= 0 {
@@ -0,0 +1,99 @@
+package aweb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/awebai/aw/awid"
+)
+
+// TaskClaimRequest binds a task to the claiming workspace and branch. With
+// StaleAfterSeconds set, a task whose owner has not been seen for that long
+// is reclaimed instead of refused.
+type TaskClaimRequest struct {
+	WorkspaceID       string `json:"workspace_id,omitempty"`
+	Branch            string `json:"branch,omitempty"`
+	StaleAfterSeconds int    `json:"stale_after_seconds,omitempty"`
+}
+
+type TaskClaimHeartbeatRequest struct {
+	WorkspaceID string `json:"workspace_id,omitempty"`
+}
+
+type TaskReleaseRequest struct {
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+type TaskHandoffRequest struct {
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	ToAlias     string `json:"to_alias"`
+	Note        string `json:"note,omitempty"`
+}
+
+// TaskClaimOwner is a workspace that held a task claim.
+type TaskClaimOwner struct {
+	Alias       string `json:"alias"`
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	LastSeenAt  string `json:"last_seen_at,omitempty"`
+}
+
+type TaskClaimView struct {
+	TaskID          string          `json:"task_id"`
+	TaskRef         string          `json:"task_ref"`
+	Title           string          `json:"title"`
+	Status          string          `json:"status"`
+	OwnerAlias      string          `json:"owner_alias"`
+	WorkspaceID     string          `json:"workspace_id,omitempty"`
+	Branch          string          `json:"branch,omitempty"`
+	ClaimedAt       string          `json:"claimed_at"`
+	LastHeartbeatAt string          `json:"last_heartbeat_at,omitempty"`
+	PreviousOwner   *TaskClaimOwner `json:"previous_owner,omitempty"`
+}
+
+// TaskClaim claims a task for the caller's workspace and moves it to
+// in_progress. If another workspace holds it, a 409 is returned as a
+// *TaskHeldError. PreviousOwner is set when a stale claim was reclaimed.
+func (c *Client) TaskClaim(ctx context.Context, ref string, req *TaskClaimRequest) (*TaskClaimView, error) {
+	var out TaskClaimView
+	if err := c.doTaskHeld(ctx, http.MethodPost, "/v1/tasks/"+urlPathEscape(ref)+"/claim", req, &out); err != nil {
+		return nil, taskClaimCompatibilityError(err)
+	}
+	return &out, nil
+}
+
+// TaskClaimHeartbeat records that the claim's owner is still working on the
+// task, so teammates cannot reclaim it as stale.
+func (c *Client) TaskClaimHeartbeat(ctx context.Context, ref string, req *TaskClaimHeartbeatRequest) (*TaskClaimView, error) {
+	var out TaskClaimView
+	if err := c.doTaskHeld(ctx, http.MethodPost, "/v1/tasks/"+urlPathEscape(ref)+"/claim/heartbeat", req, &out); err != nil {
+		return nil, taskClaimCompatibilityError(err)
+	}
+	return &out, nil
+}
+
+// TaskRelease gives up the caller's claim and returns the task to open.
+func (c *Client) TaskRelease(ctx context.Context, ref string, req *TaskReleaseRequest) error {
+	return taskClaimCompatibilityError(c.doTaskHeld(ctx, http.MethodPost, "/v1/tasks/"+urlPathEscape(ref)+"/claim/release", req, nil))
+}
+
+// TaskHandoff moves the caller's claim to a teammate. The task stays
+// in_progress under its new owner.
+func (c *Client) TaskHandoff(ctx context.Context, ref string, req *TaskHandoffRequest) (*TaskClaimView, error) {
+	var out TaskClaimView
+	if err := c.doTaskHeld(ctx, http.MethodPost, "/v1/tasks/"+urlPathEscape(ref)+"/claim/handoff", req, &out); err != nil {
+		return nil, taskClaimCompatibilityError(err)
+	}
+	return &out, nil
+}
+
+func taskClaimCompatibilityError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if status, ok := awid.HTTPStatusCode(err); ok && status == http.StatusNotFound {
+		return fmt.Errorf("task claims require aweb server 1.28.0 or later: %w", err)
+	}
+	return err
+}
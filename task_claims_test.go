@@ -0,0 +1,177 @@
+package aweb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTaskClaimSendsWorkspaceAndBranch(t *testing.T) {
+	t.Parallel()
+
+	var gotPath string
+	var gotReq TaskClaimRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotReq)
+		_ = json.NewEncoder(w).Encode(TaskClaimView{
+			TaskRef:       "aw-042",
+			Status:        "in_progress",
+			OwnerAlias:    "eve",
+			PreviousOwner: &TaskClaimOwner{Alias: "bob", LastSeenAt: "2026-10-16T08:00:00Z"},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	view, err := c.TaskClaim(context.Background(), "aw-042", &TaskClaimRequest{WorkspaceID: "ws-1", Branch: "fix-build", StaleAfterSeconds: 1800})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/v1/tasks/aw-042/claim" {
+		t.Fatalf("path=%s", gotPath)
+	}
+	if gotReq.WorkspaceID != "ws-1" || gotReq.Branch != "fix-build" || gotReq.StaleAfterSeconds != 1800 {
+		t.Fatalf("req=%+v", gotReq)
+	}
+	if view.PreviousOwner == nil || view.PreviousOwner.Alias != "bob" {
+		t.Fatalf("previous owner=%+v", view.PreviousOwner)
+	}
+}
+
+func TestTaskClaim409ReturnsHeldError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(TaskHeldError{Detail: "task already held", AssigneeAlias: "bob"})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.TaskClaim(context.Background(), "aw-042", &TaskClaimRequest{})
+	var held *TaskHeldError
+	if !errors.As(err, &held) || held.AssigneeAlias != "bob" {
+		t.Fatalf("expected TaskHeldError from bob, got %T: %v", err, err)
+	}
+}
+
+func TestTaskReleaseAndHandoffPaths(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		if r.URL.Path == "/v1/tasks/aw-042/claim/release" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(TaskClaimView{TaskRef: "aw-042", OwnerAlias: "carol"})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := c.TaskClaimHeartbeat(ctx, "aw-042", &TaskClaimHeartbeatRequest{WorkspaceID: "ws-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.TaskRelease(ctx, "aw-042", &TaskReleaseRequest{WorkspaceID: "ws-1"}); err != nil {
+		t.Fatal(err)
+	}
+	view, err := c.TaskHandoff(ctx, "aw-042", &TaskHandoffRequest{ToAlias: "carol"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view.OwnerAlias != "carol" {
+		t.Fatalf("owner=%s", view.OwnerAlias)
+	}
+	want := []string{
+		"POST /v1/tasks/aw-042/claim/heartbeat",
+		"POST /v1/tasks/aw-042/claim/release",
+		"POST /v1/tasks/aw-042/claim/handoff",
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("paths=%v", paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("paths=%v", paths)
+		}
+	}
+}
+
+func TestTaskClaim404RequiresServer1280(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantPath string
+		invoke   func(context.Context, *Client) error
+	}{
+		{
+			name:     "claim",
+			wantPath: "/v1/tasks/aw-042/claim",
+			invoke: func(ctx context.Context, client *Client) error {
+				_, err := client.TaskClaim(ctx, "aw-042", &TaskClaimRequest{})
+				return err
+			},
+		},
+		{
+			name:     "heartbeat",
+			wantPath: "/v1/tasks/aw-042/claim/heartbeat",
+			invoke: func(ctx context.Context, client *Client) error {
+				_, err := client.TaskClaimHeartbeat(ctx, "aw-042", &TaskClaimHeartbeatRequest{})
+				return err
+			},
+		},
+		{
+			name:     "release",
+			wantPath: "/v1/tasks/aw-042/claim/release",
+			invoke: func(ctx context.Context, client *Client) error {
+				return client.TaskRelease(ctx, "aw-042", &TaskReleaseRequest{})
+			},
+		},
+		{
+			name:     "handoff",
+			wantPath: "/v1/tasks/aw-042/claim/handoff",
+			invoke: func(ctx context.Context, client *Client) error {
+				_, err := client.TaskHandoff(ctx, "aw-042", &TaskHandoffRequest{ToAlias: "carol"})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				http.Error(w, "route absent", http.StatusNotFound)
+			}))
+			t.Cleanup(server.Close)
+
+			client, err := New(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = tt.invoke(context.Background(), client)
+			if err == nil || !strings.Contains(err.Error(), "task claims require aweb server 1.28.0 or later") {
+				t.Fatalf("error=%v, want minimum-server diagnostic", err)
+			}
+			if gotPath != tt.wantPath {
+				t.Fatalf("path=%s, want %s", gotPath, tt.wantPath)
+			}
+		})
+	}
+}
@@ -256,34 +256,42 @@ func (c *Client) TaskUpdate(ctx context.Context, ref string, req *TaskUpdateRequ
 }
 
 func (c *Client) taskUpdateOnce(ctx context.Context, ref string, req *TaskUpdateRequest) (*TaskUpdateResponse, error) {
-	resp, err := c.DoRaw(ctx, http.MethodPatch, "/v1/tasks/"+urlPathEscape(ref), "application/json", req)
-	if err != nil {
+	var out TaskUpdateResponse
+	if err := c.doTaskHeld(ctx, http.MethodPatch, "/v1/tasks/"+urlPathEscape(ref), req, &out); err != nil {
 		return nil, err
 	}
+	return &out, nil
+}
+
+// doTaskHeld sends a request that may be refused because another agent
+// holds the task, returning that refusal as a *TaskHeldError.
+func (c *Client) doTaskHeld(ctx context.Context, method, path string, req, out any) error {
+	resp, err := c.DoRaw(ctx, method, path, "application/json", req)
+	if err != nil {
+		return err
+	}
 	defer resp.Body.Close()
 
 	limited := io.LimitReader(resp.Body, awid.MaxResponseSize)
 	data, err := io.ReadAll(limited)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if resp.StatusCode == http.StatusConflict {
 		var held TaskHeldError
 		if err := json.Unmarshal(data, &held); err == nil {
-			return nil, &held
+			return &held
 		}
-		return nil, &awid.APIError{StatusCode: resp.StatusCode, Body: string(data)}
+		return &awid.APIError{StatusCode: resp.StatusCode, Body: string(data)}
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, &awid.APIError{StatusCode: resp.StatusCode, Body: string(data)}
+		return &awid.APIError{StatusCode: resp.StatusCode, Body: string(data)}
 	}
-
-	var out TaskUpdateResponse
-	if err := json.Unmarshal(data, &out); err != nil {
-		return nil, err
+	if out == nil {
+		return nil
 	}
-	return &out, nil
+	return json.Unmarshal(data, out)
 }
 
 func (c *Client) TaskDelete(ctx context.Context, ref string) error {